@@ -14,10 +14,15 @@ import (
 
 	mcpserver "github.com/mark3labs/mcp-go/server"
 
+	"github.com/deliveryhero/asya/asya-gateway/internal/canary"
 	"github.com/deliveryhero/asya/asya-gateway/internal/config"
+	"github.com/deliveryhero/asya/asya-gateway/internal/consumer"
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
 	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
 	"github.com/deliveryhero/asya/asya-gateway/internal/mcp"
 	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/internal/signing"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 func main() {
@@ -68,15 +73,106 @@ func main() {
 		envelopeStore = envelopestore.NewStore()
 	}
 
-	// Initialize queue client (RabbitMQ or SQS)
+	// Initialize queue client (RabbitMQ, SQS, file, postgres, redis or pubsub)
 	var queueClient queue.Client
-	var err error
 
-	// Check which transport is configured (SQS takes precedence if both are set)
+	// Check which transport is configured (explicit ASYA_TRANSPORT=file, postgres, redis or pubsub,
+	// otherwise SQS takes precedence if both SQS and RabbitMQ are set)
+	transportType := getEnv("ASYA_TRANSPORT", "")
 	sqsEndpoint := getEnv("ASYA_SQS_ENDPOINT", "")
 	rabbitmqURL := getEnv("ASYA_RABBITMQ_URL", "")
 
-	if sqsEndpoint != "" || rabbitmqURL == "" {
+	// Queue naming scheme, shared with sidecars and the operator
+	queueNaming, err := naming.New(
+		getEnv(naming.EnvPrefix, naming.DefaultPrefix),
+		getEnv(naming.EnvCluster, ""),
+		getEnv(naming.EnvSuffix, ""),
+	)
+	if err != nil {
+		slog.Error("Invalid queue naming configuration", "error", err)
+		os.Exit(1)
+	}
+	var queueTransport string // Transport name used to validate queue names
+
+	if transportType == "file" {
+		// Use file transport (shared volume)
+		fileRoot := getEnv("ASYA_FILE_ROOT", "/var/lib/asya/queues")
+		namespace := getEnv("ASYA_NAMESPACE", "default")
+		slog.Info("Using file transport", "root", fileRoot, "namespace", namespace)
+
+		queueTransport = "file"
+		queueClient, err = queue.NewFileClient(queue.FileConfig{
+			Root:              fileRoot,
+			Namespace:         namespace,
+			Naming:            queueNaming,
+			VisibilityTimeout: getEnvDuration("ASYA_FILE_VISIBILITY_TIMEOUT", 5*time.Minute),
+			PollInterval:      getEnvDuration("ASYA_FILE_POLL_INTERVAL", 200*time.Millisecond),
+			MaxDeliveries:     getEnvInt("ASYA_FILE_MAX_DELIVERIES", 3),
+		})
+		if err != nil {
+			slog.Error("Failed to create file client", "error", err)
+			os.Exit(1)
+		}
+	} else if transportType == "postgres" {
+		// Use postgres transport (defaults to the envelope store database)
+		postgresURL := getEnv("ASYA_POSTGRES_URL", dbURL)
+		namespace := getEnv("ASYA_NAMESPACE", "default")
+		slog.Info("Using postgres transport", "namespace", namespace)
+
+		queueTransport = "postgres"
+		queueClient, err = queue.NewPostgresClient(ctx, queue.PostgresConfig{
+			URL:               postgresURL,
+			Namespace:         namespace,
+			Naming:            queueNaming,
+			VisibilityTimeout: getEnvDuration("ASYA_POSTGRES_VISIBILITY_TIMEOUT", 5*time.Minute),
+			PollInterval:      getEnvDuration("ASYA_POSTGRES_POLL_INTERVAL", 500*time.Millisecond),
+			MaxDeliveries:     getEnvInt("ASYA_POSTGRES_MAX_DELIVERIES", 3),
+		})
+		if err != nil {
+			slog.Error("Failed to create postgres client", "error", err)
+			os.Exit(1)
+		}
+	} else if transportType == "redis" {
+		// Use Redis Streams transport
+		redisURL := getEnv("ASYA_REDIS_URL", "redis://localhost:6379/0")
+		namespace := getEnv("ASYA_NAMESPACE", "default")
+		slog.Info("Using redis transport", "namespace", namespace)
+
+		queueTransport = "redis"
+		queueClient, err = queue.NewRedisClient(ctx, queue.RedisConfig{
+			URL:               redisURL,
+			Namespace:         namespace,
+			Naming:            queueNaming,
+			Group:             getEnv("ASYA_REDIS_GROUP", "asya"),
+			Consumer:          getEnv("ASYA_REDIS_CONSUMER", "asya-gateway"),
+			VisibilityTimeout: getEnvDuration("ASYA_REDIS_VISIBILITY_TIMEOUT", 5*time.Minute),
+			BlockTimeout:      getEnvDuration("ASYA_REDIS_BLOCK_TIMEOUT", time.Second),
+			MaxDeliveries:     getEnvInt("ASYA_REDIS_MAX_DELIVERIES", 3),
+		})
+		if err != nil {
+			slog.Error("Failed to create redis client", "error", err)
+			os.Exit(1)
+		}
+	} else if transportType == "pubsub" {
+		// Use Google Cloud Pub/Sub transport (Workload Identity or emulator)
+		projectID := getEnv("ASYA_PUBSUB_PROJECT_ID", "")
+		namespace := getEnv("ASYA_NAMESPACE", "default")
+		emulatorHost := getEnv("ASYA_PUBSUB_EMULATOR_HOST", os.Getenv("PUBSUB_EMULATOR_HOST"))
+		slog.Info("Using pubsub transport", "project", projectID, "namespace", namespace, "emulatorHost", emulatorHost)
+
+		queueTransport = "pubsub"
+		queueClient, err = queue.NewPubSubClient(ctx, queue.PubSubConfig{
+			ProjectID:    projectID,
+			Namespace:    namespace,
+			Naming:       queueNaming,
+			EmulatorHost: emulatorHost,
+			Endpoint:     getEnv("ASYA_PUBSUB_ENDPOINT", ""),
+		})
+		if err != nil {
+			slog.Error("Failed to create pubsub client", "error", err)
+			os.Exit(1)
+		}
+	} else if sqsEndpoint != "" || rabbitmqURL == "" {
 		// Use SQS transport
 		sqsRegion := getEnv("ASYA_SQS_REGION", "us-east-1")
 		namespace := getEnv("ASYA_NAMESPACE", "default")
@@ -84,13 +180,29 @@ func main() {
 
 		visibilityTimeout := getEnvInt("ASYA_SQS_VISIBILITY_TIMEOUT", 300)
 		waitTimeSeconds := getEnvInt("ASYA_SQS_WAIT_TIME_SECONDS", 20)
+		fifo := getEnvBool("ASYA_SQS_FIFO", false)
+		messageGroup := getEnv("ASYA_SQS_MESSAGE_GROUP", "")
 
+		// Payloads are encrypted before they are sent, so the client could not read the group field
+		if fifo && strings.HasPrefix(messageGroup, "payload.") && getEnv("ASYA_ENCRYPTION_PROVIDER", "") != "" {
+			slog.Error("ASYA_SQS_MESSAGE_GROUP cannot be read from encrypted payloads, use a headers.<key> field with ASYA_ENCRYPTION_PROVIDER",
+				"messageGroup", messageGroup)
+			os.Exit(1)
+		}
+
+		queueTransport = "sqs"
+		if fifo {
+			queueTransport = naming.TransportSQSFIFO
+		}
 		queueClient, err = queue.NewSQSClient(ctx, queue.SQSConfig{
 			Region:            sqsRegion,
 			Endpoint:          sqsEndpoint,
 			Namespace:         namespace,
+			Naming:            queueNaming,
 			VisibilityTimeout: int32(visibilityTimeout), // #nosec G115 - config values bounded by reasonable defaults
 			WaitTimeSeconds:   int32(waitTimeSeconds),   // #nosec G115 - config values bounded by reasonable defaults
+			FIFO:              fifo,
+			MessageGroup:      messageGroup,
 		})
 		if err != nil {
 			slog.Error("Failed to create SQS client", "error", err)
@@ -102,14 +214,56 @@ func main() {
 		rabbitmqPoolSize := getEnvInt("ASYA_RABBITMQ_POOL_SIZE", 20)
 		slog.Info("Using RabbitMQ transport", "url", rabbitmqURL, "exchange", rabbitmqExchange, "poolSize", rabbitmqPoolSize)
 
-		queueClient, err = queue.NewRabbitMQClientPooled(rabbitmqURL, rabbitmqExchange, rabbitmqPoolSize)
+		rabbitmqClient, err := queue.NewRabbitMQClientPooled(rabbitmqURL, rabbitmqExchange, rabbitmqPoolSize)
 		if err != nil {
 			slog.Error("Failed to create RabbitMQ client", "error", err)
 			os.Exit(1)
 		}
+		rabbitmqClient.SetNamespace(getEnv("ASYA_NAMESPACE", "default"))
+		rabbitmqClient.SetNaming(queueNaming)
+		queueTransport = "rabbitmq"
+		queueClient = rabbitmqClient
 	}
 	defer func() { _ = queueClient.Close() }()
 
+	// Only send to actors in the gateway's namespace or in namespaces that accept envelopes from it,
+	// like sidecars do (ASYA_ALLOWED_TARGET_NAMESPACES lists the namespaces that opted in)
+	queueClient = queue.NewNamespaceGuard(queueClient, getEnv("ASYA_NAMESPACE", "default"), getEnvList("ASYA_ALLOWED_TARGET_NAMESPACES"))
+
+	// Sign envelopes so sidecars can reject injected or tampered ones (optional)
+	var signer *signing.Signer
+	if signingKeyringPath := getEnv("ASYA_SIGNING_KEYRING_PATH", ""); signingKeyringPath != "" {
+		signingKeys, err := encryption.NewKeyringProvider(signingKeyringPath)
+		if err != nil {
+			slog.Error("Failed to load signing keyring", "error", err)
+			os.Exit(1)
+		}
+		signer = signing.NewSigner(signingKeys)
+		queueClient = signing.NewClient(queueClient, signer)
+		slog.Info("Envelope signing enabled", "keyring", signingKeyringPath)
+	}
+
+	// Send logical actor names in the first hop to one of their weighted versions (optional).
+	// Wraps the signing client so the signature covers the chosen version.
+	// The operator's actor versions ConfigMap is reloaded when it changes; a fixed table takes precedence.
+	if raw := getEnv(canary.EnvVersions, ""); raw != "" {
+		actorVersions, err := canary.Parse(raw)
+		if err != nil {
+			slog.Error("Invalid actor versions", "error", err)
+			os.Exit(1)
+		}
+		queueClient = canary.NewClient(queueClient, canary.NewVersions(actorVersions))
+		slog.Info("Actor version routing enabled", "logical_actors", len(actorVersions))
+	} else if path := getEnv(canary.EnvVersionsPath, ""); path != "" {
+		actorVersions, err := canary.LoadVersions(path)
+		if err != nil {
+			slog.Error("Invalid actor versions", "error", err)
+			os.Exit(1)
+		}
+		queueClient = canary.NewClient(queueClient, actorVersions)
+		slog.Info("Actor version routing enabled", "path", path, "logical_actors", len(actorVersions.Table()))
+	}
+
 	// End queue consumers removed - use standalone end actors instead
 	// Deploy happy-end and error-end actors to handle end queue processing
 	slog.Info("Gateway uses standalone end actors for final status reporting",
@@ -124,6 +278,10 @@ func main() {
 			slog.Error("Failed to load config", "error", err)
 			os.Exit(1)
 		}
+		if err := cfg.ValidateQueueNames(queueTransport, getEnv("ASYA_NAMESPACE", "default"), queueNaming); err != nil {
+			slog.Error("Invalid tool route", "error", err)
+			os.Exit(1)
+		}
 		toolConfig = cfg
 		slog.Info("Loaded tools from configuration", "count", len(cfg.Tools))
 	} else {
@@ -136,6 +294,42 @@ func main() {
 	// Create envelope handler for custom endpoints
 	envelopeHandler := mcp.NewHandler(envelopeStore)
 	envelopeHandler.SetServer(mcpServer) // For REST tool calls
+	if signer != nil {
+		envelopeHandler.SetSigner(signer)
+	}
+
+	// Encrypt payloads on submit and decrypt them for status responses (optional)
+	encryptor, err := encryption.New(ctx, encryption.Config{
+		Provider:    getEnv("ASYA_ENCRYPTION_PROVIDER", ""),
+		KeyringPath: getEnv("ASYA_ENCRYPTION_KEYRING_PATH", "/etc/asya/keyring/keyring.json"),
+		KMSKeyID:    getEnv("ASYA_ENCRYPTION_KMS_KEY_ID", ""),
+		KMSRegion:   getEnv("ASYA_AWS_REGION", getEnv("ASYA_SQS_REGION", "us-east-1")),
+		KMSEndpoint: getEnv("ASYA_ENCRYPTION_KMS_ENDPOINT", ""),
+		DataKeyTTL:  getEnvDuration("ASYA_ENCRYPTION_DATA_KEY_TTL", time.Hour),
+	})
+	if err != nil {
+		slog.Error("Failed to initialize payload encryption", "error", err)
+		os.Exit(1)
+	}
+	if encryptor != nil {
+		slog.Info("Payload encryption enabled", "provider", getEnv("ASYA_ENCRYPTION_PROVIDER", ""))
+		mcpServer.SetEncryptor(encryptor)
+		envelopeHandler.SetEncryptor(encryptor)
+	}
+
+	// Consume progress and final status published by sidecars to the status queue (optional)
+	if statusQueue := getEnv("ASYA_STATUS_QUEUE", ""); statusQueue != "" {
+		statusQueueName := queueNaming.QueueName(getEnv("ASYA_NAMESPACE", "default"), statusQueue)
+		if err := naming.ValidateQueueName(queueTransport, statusQueueName); err != nil {
+			slog.Error("Invalid status queue", "error", err)
+			os.Exit(1)
+		}
+		statusConsumer := consumer.NewStatusConsumer(queueClient, statusQueueName, envelopeHandler)
+		if err := statusConsumer.Start(ctx); err != nil {
+			slog.Error("Failed to start status consumer", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Setup routes
 	mux := http.NewServeMux()
@@ -159,11 +353,20 @@ func main() {
 			envelopeHandler.HandleEnvelopeProgress(w, r)
 		} else if strings.HasSuffix(r.URL.Path, "/final") {
 			envelopeHandler.HandleEnvelopeFinal(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/approval") {
+			envelopeHandler.HandleEnvelopeApproval(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/approve") {
+			envelopeHandler.HandleEnvelopeApprove(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/reject") {
+			envelopeHandler.HandleEnvelopeReject(w, r)
 		} else {
 			envelopeHandler.HandleEnvelopeStatus(w, r)
 		}
 	})
 
+	// Batched progress endpoint (for sidecars with asynchronous progress reporting)
+	mux.HandleFunc("/envelopes/progress/batch", envelopeHandler.HandleEnvelopeProgressBatch)
+
 	// Envelope creation endpoint (for fanout child envelopes from sidecar)
 	mux.HandleFunc("/envelopes", envelopeHandler.HandleEnvelopeCreate)
 
@@ -192,6 +395,7 @@ func main() {
 		slog.Info("Envelope stream: GET /envelopes/{id}/stream (SSE)")
 		slog.Info("Envelope active check: GET /envelopes/{id}/active (for actors)")
 		slog.Info("Envelope progress: POST /envelopes/{id}/progress (from sidecar)")
+		slog.Info("Envelope progress batch: POST /envelopes/progress/batch (from sidecar)")
 		slog.Info("Envelope final status: POST /envelopes/{id}/final (for end actors)")
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -221,6 +425,16 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.Atoi(value); err == nil {
@@ -230,3 +444,23 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid boolean value, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid duration value, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
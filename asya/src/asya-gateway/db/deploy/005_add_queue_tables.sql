@@ -0,0 +1,37 @@
+-- Deploy asya-gateway:005_add_queue_tables to pg
+-- Tables backing the postgres queue transport (shared by gateway, sidecars and operator)
+
+BEGIN;
+
+-- Known queues (asya-{namespace}-{actor}), registered by the operator
+CREATE TABLE IF NOT EXISTS asya_queues (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Pending and in-flight messages; visible_at doubles as delay and visibility lease
+CREATE TABLE IF NOT EXISTS asya_queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL DEFAULT 0,
+    visible_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_messages_visible ON asya_queue_messages(queue_name, visible_at, id);
+
+-- Messages that exceeded the delivery limit
+CREATE TABLE IF NOT EXISTS asya_queue_dead_letters (
+    id BIGINT PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    dead_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_dead_letters_queue ON asya_queue_dead_letters(queue_name, dead_at DESC);
+
+COMMIT;
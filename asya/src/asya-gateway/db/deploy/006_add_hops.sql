@@ -0,0 +1,9 @@
+-- Deploy asya-gateway:006_add_hops to pg
+
+BEGIN;
+
+-- Add hops column to envelopes table for per-hop execution history reported by end actors
+ALTER TABLE envelopes
+ADD COLUMN hops JSONB;
+
+COMMIT;
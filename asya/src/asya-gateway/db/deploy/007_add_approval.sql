@@ -0,0 +1,15 @@
+-- Deploy asya-gateway:007_add_approval to pg
+
+BEGIN;
+
+-- Allow envelopes parked at an approval step
+ALTER TABLE envelopes DROP CONSTRAINT IF EXISTS envelopes_status_check;
+ALTER TABLE envelopes ADD CONSTRAINT envelopes_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'unknown', 'awaiting_approval'));
+
+-- Add approval column holding the parked envelope while awaiting approval
+-- (JSON rather than JSONB so the envelope is resumed exactly as the sidecar sent it)
+ALTER TABLE envelopes
+ADD COLUMN approval JSON;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Deploy asya-gateway:008_add_compensating_status to pg
+
+BEGIN;
+
+-- Allow failed envelopes whose completed actors are being compensated
+ALTER TABLE envelopes DROP CONSTRAINT IF EXISTS envelopes_status_check;
+ALTER TABLE envelopes ADD CONSTRAINT envelopes_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'unknown', 'awaiting_approval', 'compensating'));
+
+COMMIT;
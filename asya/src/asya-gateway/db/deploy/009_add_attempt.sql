@@ -0,0 +1,9 @@
+-- Deploy asya-gateway:009_add_attempt to pg
+
+BEGIN;
+
+-- Add attempt column to envelopes table for the current actor's attempt when retrying runtime errors
+ALTER TABLE envelopes
+ADD COLUMN attempt INTEGER;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Revert asya-gateway:005_add_queue_tables from pg
+
+BEGIN;
+
+DROP TABLE IF EXISTS asya_queue_dead_letters;
+DROP TABLE IF EXISTS asya_queue_messages;
+DROP TABLE IF EXISTS asya_queues;
+
+COMMIT;
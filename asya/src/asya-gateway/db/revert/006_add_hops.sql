@@ -0,0 +1,8 @@
+-- Revert asya-gateway:006_add_hops from pg
+
+BEGIN;
+
+-- Drop hops column from envelopes table
+ALTER TABLE envelopes DROP COLUMN IF EXISTS hops;
+
+COMMIT;
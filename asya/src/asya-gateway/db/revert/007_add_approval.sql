@@ -0,0 +1,14 @@
+-- Revert asya-gateway:007_add_approval from pg
+
+BEGIN;
+
+-- Drop approval column from envelopes table
+ALTER TABLE envelopes DROP COLUMN IF EXISTS approval;
+
+-- Fail envelopes still awaiting approval and restore the previous status constraint
+UPDATE envelopes SET status = 'failed', error = 'approval step removed' WHERE status = 'awaiting_approval';
+ALTER TABLE envelopes DROP CONSTRAINT IF EXISTS envelopes_status_check;
+ALTER TABLE envelopes ADD CONSTRAINT envelopes_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'unknown'));
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Revert asya-gateway:008_add_compensating_status from pg
+
+BEGIN;
+
+-- Fail envelopes still compensating and restore the previous status constraint
+UPDATE envelopes SET status = 'failed', error = COALESCE(error, 'compensation interrupted') WHERE status = 'compensating';
+ALTER TABLE envelopes DROP CONSTRAINT IF EXISTS envelopes_status_check;
+ALTER TABLE envelopes ADD CONSTRAINT envelopes_status_check
+    CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'unknown', 'awaiting_approval'));
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Revert asya-gateway:009_add_attempt from pg
+
+BEGIN;
+
+-- Drop attempt column from envelopes table
+ALTER TABLE envelopes DROP COLUMN IF EXISTS attempt;
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Verify asya-gateway:005_add_queue_tables on pg
+
+BEGIN;
+
+-- Verify tables exist
+SELECT name, created_at FROM asya_queues WHERE FALSE;
+
+SELECT id, queue_name, body, deliveries, visible_at, locked_at, created_at
+FROM asya_queue_messages WHERE FALSE;
+
+SELECT id, queue_name, body, deliveries, created_at, dead_at
+FROM asya_queue_dead_letters WHERE FALSE;
+
+-- Verify indexes exist
+SELECT 1/COUNT(*) FROM pg_indexes WHERE tablename = 'asya_queue_messages' AND indexname = 'idx_asya_queue_messages_visible';
+SELECT 1/COUNT(*) FROM pg_indexes WHERE tablename = 'asya_queue_dead_letters' AND indexname = 'idx_asya_queue_dead_letters_queue';
+
+ROLLBACK;
@@ -0,0 +1,10 @@
+-- Verify asya-gateway:006_add_hops on pg
+
+BEGIN;
+
+-- Verify hops column exists
+SELECT hops
+FROM envelopes
+WHERE FALSE;
+
+ROLLBACK;
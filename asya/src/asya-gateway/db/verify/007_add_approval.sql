@@ -0,0 +1,16 @@
+-- Verify asya-gateway:007_add_approval on pg
+
+BEGIN;
+
+-- Verify approval column exists
+SELECT approval
+FROM envelopes
+WHERE FALSE;
+
+-- Verify constraint allows awaiting_approval
+SELECT 1/COUNT(*)
+FROM pg_constraint
+WHERE conname = 'envelopes_status_check'
+AND pg_get_constraintdef(oid) LIKE '%awaiting_approval%';
+
+ROLLBACK;
@@ -0,0 +1,11 @@
+-- Verify asya-gateway:008_add_compensating_status on pg
+
+BEGIN;
+
+-- Verify constraint allows compensating
+SELECT 1/COUNT(*)
+FROM pg_constraint
+WHERE conname = 'envelopes_status_check'
+AND pg_get_constraintdef(oid) LIKE '%compensating%';
+
+ROLLBACK;
@@ -0,0 +1,10 @@
+-- Verify asya-gateway:009_add_attempt on pg
+
+BEGIN;
+
+-- Verify attempt column exists
+SELECT attempt
+FROM envelopes
+WHERE FALSE;
+
+ROLLBACK;
@@ -0,0 +1,156 @@
+// Package canary splits traffic for a logical actor name between weighted actor versions.
+//
+// The version table maps logical actor names used in routes to concrete actors, for example
+// {"classifier": {"versions": [{"actor": "classifier-v1", "weight": 90}, {"actor": "classifier-v2", "weight": 10}]}}.
+// The operator renders it from the spec.logicalActor field of the namespace's AsyncActors into the
+// namespace's asya-actor-versions ConfigMap; the gateway mounts the same ConfigMap as the sidecars
+// to pick the version of the first actor.
+//
+// The sidecar (asya-sidecar/internal/canary) carries a copy of this package and must pick the same
+// versions for sticky sets; keep them in sync.
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+// EnvVersions is the environment variable holding the version table as JSON
+const EnvVersions = "ASYA_ACTOR_VERSIONS"
+
+// Version is a concrete actor receiving a share of a logical actor's envelopes
+type Version struct {
+	Actor  string `json:"actor"`
+	Weight int    `json:"weight"`
+}
+
+// Set is the versions of a logical actor
+type Set struct {
+	Versions []Version `json:"versions"`
+	Sticky   bool      `json:"sticky,omitempty"` // Pick by envelope ID hash instead of at random
+}
+
+// Table maps logical actor names to their versions
+type Table map[string]Set
+
+// Parse parses a JSON version table. An empty string yields an empty table.
+func Parse(raw string) (Table, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var table Table
+	if err := json.Unmarshal([]byte(raw), &table); err != nil {
+		return nil, fmt.Errorf("invalid actor versions: %w", err)
+	}
+
+	logicalNames := make(map[string]string)
+	for name, set := range table {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("logical actor name %q must be a bare actor name", name)
+		}
+		for _, version := range set.Versions {
+			if version.Actor == "" || strings.Contains(version.Actor, "/") {
+				return nil, fmt.Errorf("version %q of logical actor %q must be a bare actor name", version.Actor, name)
+			}
+			if version.Weight < 0 {
+				return nil, fmt.Errorf("version %q of logical actor %q has negative weight %d", version.Actor, name, version.Weight)
+			}
+			if other, ok := logicalNames[version.Actor]; ok {
+				return nil, fmt.Errorf("actor %q is a version of both %q and %q", version.Actor, other, name)
+			}
+			logicalNames[version.Actor] = name
+		}
+		if set.totalWeight() <= 0 {
+			return nil, fmt.Errorf("logical actor %q has no version with a positive weight", name)
+		}
+	}
+	return table, nil
+}
+
+// Resolve returns the version an envelope routed to actor is sent to, or actor itself if it is not
+// a logical actor name. Sticky sets send every delivery of an envelope ID to the same version.
+func (t Table) Resolve(actor, envelopeID string) string {
+	set, ok := t[actor]
+	if !ok {
+		return actor
+	}
+	total := set.totalWeight()
+	if total <= 0 {
+		return actor
+	}
+
+	var n int
+	if set.Sticky {
+		h := fnv.New64a()
+		h.Write([]byte(envelopeID))
+		n = int(h.Sum64() % uint64(total))
+	} else {
+		n = rand.IntN(total)
+	}
+	for _, version := range set.Versions {
+		if n < version.Weight {
+			return version.Actor
+		}
+		n -= version.Weight
+	}
+	return actor
+}
+
+// LogicalName returns the logical actor name that actor is a version of, or "" if it is none
+func (t Table) LogicalName(actor string) string {
+	for name, set := range t {
+		for _, version := range set.Versions {
+			if version.Actor == actor {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func (s Set) totalWeight() int {
+	total := 0
+	for _, version := range s.Versions {
+		total += version.Weight
+	}
+	return total
+}
+
+// Client sends envelopes whose current actor is a logical actor name to one of its versions
+type Client struct {
+	queue.Client
+	versions *Versions
+}
+
+// NewClient wraps a queue client so envelopes are sent to actor versions picked from versions
+func NewClient(client queue.Client, versions *Versions) *Client {
+	return &Client{Client: client, versions: versions}
+}
+
+// SendEnvelope sends a copy of the envelope whose route records the chosen version; the caller's
+// envelope is not modified
+func (c *Client) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	route := envelope.Route
+	if route.Current < 0 || route.Current >= len(route.Actors) {
+		return c.Client.SendEnvelope(ctx, envelope)
+	}
+	actor := route.Actors[route.Current]
+	version := c.versions.Resolve(actor, envelope.ID)
+	if version == actor {
+		return c.Client.SendEnvelope(ctx, envelope)
+	}
+
+	resolved := *envelope
+	resolved.Route.Actors = slices.Clone(route.Actors)
+	resolved.Route.Actors[route.Current] = version
+	return c.Client.SendEnvelope(ctx, &resolved)
+}
@@ -0,0 +1,151 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+// The same cases are tested in the sidecar copy of this package
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{name: "empty", raw: ""},
+		{name: "weighted versions", raw: `{"classifier":{"versions":[{"actor":"classifier-v1","weight":90},{"actor":"classifier-v2","weight":10}]}}`},
+		{name: "version named like the logical actor", raw: `{"classifier":{"versions":[{"actor":"classifier","weight":1},{"actor":"classifier-v2","weight":0}]}}`},
+		{name: "invalid json", raw: `{`, wantErr: "invalid actor versions"},
+		{name: "qualified logical name", raw: `{"team-b/classifier":{"versions":[{"actor":"classifier-v1","weight":1}]}}`, wantErr: "must be a bare actor name"},
+		{name: "qualified version", raw: `{"classifier":{"versions":[{"actor":"team-b/classifier-v1","weight":1}]}}`, wantErr: "must be a bare actor name"},
+		{name: "negative weight", raw: `{"classifier":{"versions":[{"actor":"classifier-v1","weight":-1}]}}`, wantErr: "negative weight"},
+		{name: "no positive weight", raw: `{"classifier":{"versions":[{"actor":"classifier-v1","weight":0}]}}`, wantErr: "no version with a positive weight"},
+		{name: "no versions", raw: `{"classifier":{"versions":[]}}`, wantErr: "no version with a positive weight"},
+		{
+			name:    "version of two logical actors",
+			raw:     `{"a":{"versions":[{"actor":"shared","weight":1}]},"b":{"versions":[{"actor":"shared","weight":1}]}}`,
+			wantErr: "is a version of both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.raw)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTable_Resolve(t *testing.T) {
+	table := Table{
+		"classifier": {Versions: []Version{{Actor: "classifier-v1", Weight: 90}, {Actor: "classifier-v2", Weight: 10}}},
+		"scorer":     {Versions: []Version{{Actor: "scorer-v1", Weight: 1}, {Actor: "scorer-v2", Weight: 1}}, Sticky: true},
+		"drained":    {Versions: []Version{{Actor: "drained-v1", Weight: 0}, {Actor: "drained-v2", Weight: 5}}},
+	}
+
+	if got := table.Resolve("ocr", "id"); got != "ocr" {
+		t.Errorf("Resolve(ocr) = %q, want ocr", got)
+	}
+	if got := table.Resolve("team-b/classifier", "id"); got != "team-b/classifier" {
+		t.Errorf("Resolve(team-b/classifier) = %q, want it unchanged", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		if got := table.Resolve("drained", fmt.Sprint(i)); got != "drained-v2" {
+			t.Fatalf("Resolve(drained) = %q, want drained-v2", got)
+		}
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[table.Resolve("classifier", "id")]++
+	}
+	if counts["classifier-v1"]+counts["classifier-v2"] != 10000 {
+		t.Fatalf("unexpected versions: %v", counts)
+	}
+	if counts["classifier-v2"] < 700 || counts["classifier-v2"] > 1300 {
+		t.Errorf("expected about 10%% of envelopes on classifier-v2, got %d of 10000", counts["classifier-v2"])
+	}
+
+	sticky := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("envelope-%d", i)
+		version := table.Resolve("scorer", id)
+		for j := 0; j < 3; j++ {
+			if again := table.Resolve("scorer", id); again != version {
+				t.Fatalf("sticky Resolve(scorer, %s) changed from %q to %q", id, version, again)
+			}
+		}
+		sticky[version]++
+	}
+	if sticky["scorer-v1"] == 0 || sticky["scorer-v2"] == 0 {
+		t.Errorf("expected sticky envelopes on both versions, got %v", sticky)
+	}
+	if got := table.Resolve("scorer", "envelope-1"); got != "scorer-v2" {
+		t.Errorf("sticky Resolve(scorer, envelope-1) = %q, want scorer-v2 (must match the sidecar)", got)
+	}
+}
+
+func TestTable_LogicalName(t *testing.T) {
+	table := Table{"classifier": {Versions: []Version{{Actor: "classifier-v1", Weight: 1}}}}
+
+	if got := table.LogicalName("classifier-v1"); got != "classifier" {
+		t.Errorf("LogicalName(classifier-v1) = %q, want classifier", got)
+	}
+	if got := table.LogicalName("ocr"); got != "" {
+		t.Errorf("LogicalName(ocr) = %q, want empty", got)
+	}
+}
+
+type recordingClient struct {
+	queue.Client
+	sent []*types.Envelope
+}
+
+func (c *recordingClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	c.sent = append(c.sent, envelope)
+	return nil
+}
+
+func TestClient_SendEnvelope(t *testing.T) {
+	inner := &recordingClient{}
+	client := NewClient(inner, NewVersions(Table{
+		"classifier": {Versions: []Version{{Actor: "classifier-v1", Weight: 0}, {Actor: "classifier-v2", Weight: 1}}},
+	}))
+
+	envelope := &types.Envelope{ID: "env-1", Route: types.Route{Actors: []string{"classifier", "next"}, Current: 0}}
+	if err := client.SendEnvelope(context.Background(), envelope); err != nil {
+		t.Fatalf("SendEnvelope failed: %v", err)
+	}
+	plain := &types.Envelope{ID: "env-2", Route: types.Route{Actors: []string{"ocr"}, Current: 0}}
+	if err := client.SendEnvelope(context.Background(), plain); err != nil {
+		t.Fatalf("SendEnvelope failed: %v", err)
+	}
+
+	if len(inner.sent) != 2 {
+		t.Fatalf("Expected 2 sent envelopes, got %d", len(inner.sent))
+	}
+	if want := []string{"classifier-v2", "next"}; !reflect.DeepEqual(inner.sent[0].Route.Actors, want) {
+		t.Errorf("Expected route %v, got %v", want, inner.sent[0].Route.Actors)
+	}
+	if envelope.Route.Actors[0] != "classifier" {
+		t.Error("SendEnvelope modified the caller's envelope")
+	}
+	if inner.sent[1] != plain {
+		t.Error("Expected envelopes for other actors to be sent unchanged")
+	}
+}
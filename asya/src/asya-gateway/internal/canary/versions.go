@@ -0,0 +1,111 @@
+package canary
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// EnvVersionsPath is the environment variable naming a file holding the version table, typically
+// the actor versions ConfigMap the operator maintains, mounted as a volume
+const EnvVersionsPath = "ASYA_ACTOR_VERSIONS_PATH"
+
+// Versions serves a version table, either fixed or read from a file. The file is reloaded when it
+// changes, so weights can be shifted without restarting. A nil Versions has no logical actors.
+type Versions struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	table   Table
+}
+
+// NewVersions serves a fixed version table
+func NewVersions(table Table) *Versions {
+	return &Versions{table: table}
+}
+
+// LoadVersions loads the version table at path. A missing file yields an empty table until it
+// appears, since the operator creates the ConfigMap once the namespace has AsyncActors.
+func LoadVersions(path string) (*Versions, error) {
+	if path == "" {
+		return nil, fmt.Errorf("actor versions path is required")
+	}
+	v := &Versions{path: path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return v, nil
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Table returns the current version table
+func (v *Versions) Table() Table {
+	if v == nil {
+		return nil
+	}
+	v.reloadIfChanged()
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.table
+}
+
+// Resolve resolves actor against the current version table, see Table.Resolve
+func (v *Versions) Resolve(actor, envelopeID string) string {
+	return v.Table().Resolve(actor, envelopeID)
+}
+
+// LogicalName looks actor up in the current version table, see Table.LogicalName
+func (v *Versions) LogicalName(actor string) string {
+	return v.Table().LogicalName(actor)
+}
+
+// reloadIfChanged reloads the file when it was modified, keeping the current table on error
+func (v *Versions) reloadIfChanged() {
+	if v.path == "" {
+		return
+	}
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return
+	}
+
+	v.mu.RLock()
+	unchanged := info.ModTime().Equal(v.modTime)
+	v.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	if err := v.load(); err != nil {
+		slog.Warn("Failed to reload actor versions, keeping previous versions", "path", v.path, "error", err)
+	}
+}
+
+func (v *Versions) load() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read actor versions: %w", err)
+	}
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read actor versions: %w", err)
+	}
+	table, err := Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse actor versions %s: %w", v.path, err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.modTime = info.ModTime()
+	v.table = table
+
+	slog.Info("Loaded actor versions", "path", v.path, "logicalActors", len(table))
+	return nil
+}
@@ -3,8 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 func TestLoad(t *testing.T) {
@@ -105,6 +109,30 @@ tools:
       input:
         type: string
     route: unknown-template
+`,
+			wantErr: true,
+		},
+		{
+			name: "cross-namespace actor reference",
+			yaml: `
+tools:
+  - name: test
+    parameters:
+      input:
+        type: string
+    route: [preprocess, team-b/ocr]
+`,
+			wantErr: false,
+		},
+		{
+			name: "invalid - malformed actor reference",
+			yaml: `
+tools:
+  - name: test
+    parameters:
+      input:
+        type: string
+    route: [preprocess, team-b/]
 `,
 			wantErr: true,
 		},
@@ -367,10 +395,12 @@ func TestToolGetOptions(t *testing.T) {
 			defaults: &ToolDefaults{
 				Progress: boolPtr(true),
 				Timeout:  intPtr(600),
+				MaxHops:  intPtr(50),
 			},
 			want: ToolOptions{
 				Progress: true,
 				Timeout:  600000000000, // 10 minutes in nanoseconds
+				MaxHops:  50,
 			},
 		},
 		{
@@ -383,14 +413,17 @@ func TestToolGetOptions(t *testing.T) {
 				Route:    RouteSpec{Actors: []string{"actor"}},
 				Progress: boolPtr(false),
 				Timeout:  intPtr(30),
+				MaxHops:  intPtr(8),
 			},
 			defaults: &ToolDefaults{
 				Progress: boolPtr(true),
 				Timeout:  intPtr(600),
+				MaxHops:  intPtr(50),
 			},
 			want: ToolOptions{
 				Progress: false,
 				Timeout:  30000000000, // 30 seconds in nanoseconds
+				MaxHops:  8,
 			},
 		},
 	}
@@ -404,6 +437,9 @@ func TestToolGetOptions(t *testing.T) {
 			if got.Timeout != tt.want.Timeout {
 				t.Errorf("Timeout = %v, want %v", got.Timeout, tt.want.Timeout)
 			}
+			if got.MaxHops != tt.want.MaxHops {
+				t.Errorf("MaxHops = %v, want %v", got.MaxHops, tt.want.MaxHops)
+			}
 		})
 	}
 }
@@ -445,6 +481,43 @@ tools:
 				return r.Template == "my-template" && len(r.Actors) == 0
 			},
 		},
+		{
+			name: "array with per-actor timeouts",
+			yaml: `
+tools:
+  - name: test
+    parameters:
+      input:
+        type: string
+    route:
+      - {actor: ocr, timeout: 30s}
+      - prep
+      - {actor: llm, timeout: 10m}
+`,
+			check: func(r *RouteSpec) bool {
+				return reflect.DeepEqual(r.Actors, []string{"ocr", "prep", "llm"}) &&
+					len(r.Timeouts) == 2 && r.Timeouts["ocr"] == 30*time.Second && r.Timeouts["llm"] == 10*time.Minute
+			},
+		},
+		{
+			name: "array with compensating actors",
+			yaml: `
+tools:
+  - name: test
+    parameters:
+      input:
+        type: string
+    route:
+      - {actor: reserve-stock, compensate: release-stock}
+      - {actor: charge-card, timeout: 30s, compensate: refund-card}
+      - send-email
+`,
+			check: func(r *RouteSpec) bool {
+				return reflect.DeepEqual(r.Actors, []string{"reserve-stock", "charge-card", "send-email"}) &&
+					reflect.DeepEqual(r.Compensations, map[string]string{"reserve-stock": "release-stock", "charge-card": "refund-card"}) &&
+					r.Timeouts["charge-card"] == 30*time.Second
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -463,6 +536,55 @@ tools:
 	}
 }
 
+func TestRouteSpecUnmarshalYAML_InvalidTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		wantErr string
+	}{
+		{name: "unparseable timeout", route: `[{actor: ocr, timeout: soon}]`, wantErr: "invalid timeout"},
+		{name: "non-positive timeout", route: `[{actor: ocr, timeout: 0s}]`, wantErr: "must be positive"},
+		{name: "missing actor", route: `[{timeout: 30s}]`, wantErr: "has no actor"},
+		{name: "conflicting timeouts", route: `[{actor: ocr, timeout: 30s}, {actor: ocr, timeout: 1m}]`, wantErr: "conflicting timeouts"},
+		{name: "invalid compensate", route: `[{actor: charge, compensate: a/b/c}]`, wantErr: "invalid compensate"},
+		{name: "conflicting compensations", route: `[{actor: charge, compensate: refund}, {actor: charge, compensate: void}]`, wantErr: "conflicting compensations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlStr := "tools:\n  - name: test\n    route: " + tt.route + "\n"
+			_, err := Load(strings.NewReader(yamlStr))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateQueueNames(t *testing.T) {
+	cfg := &Config{
+		Tools: []Tool{
+			{Name: "short", Route: RouteSpec{Actors: []string{"ocr", "team-b/llm"}}},
+			{Name: "long", Route: RouteSpec{Template: "long-route"}},
+		},
+		Routes: map[string][]string{"long-route": {"an-actor-with-a-rather-long-descriptive-name"}},
+	}
+
+	if err := cfg.ValidateQueueNames("sqs", "default", naming.Scheme{}); err != nil {
+		t.Errorf("ValidateQueueNames() error = %v, want nil", err)
+	}
+
+	scheme := naming.Scheme{Cluster: "eu-central-1", Suffix: "production"}
+	err := cfg.ValidateQueueNames("sqs", "default", scheme)
+	if err == nil || !strings.Contains(err.Error(), `tool "long"`) || !strings.Contains(err.Error(), "limit of 80") {
+		t.Errorf("ValidateQueueNames() error = %v, want SQS length error for tool long", err)
+	}
+
+	if err := cfg.ValidateQueueNames("postgres", "default", scheme); err != nil {
+		t.Errorf("ValidateQueueNames() error = %v, want nil for postgres", err)
+	}
+}
+
 // Helper functions
 func boolPtr(b bool) *bool {
 	return &b
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 // Config represents the complete tool routes configuration
@@ -19,7 +23,8 @@ type Tool struct {
 	Parameters  map[string]Parameter `yaml:"parameters"`
 	Route       RouteSpec            `yaml:"route"` // Can be array or string (template)
 	Progress    *bool                `yaml:"progress,omitempty"`
-	Timeout     *int                 `yaml:"timeout,omitempty"` // seconds
+	Timeout     *int                 `yaml:"timeout,omitempty"`  // seconds
+	MaxHops     *int                 `yaml:"max_hops,omitempty"` // max actors that may process an envelope
 	Metadata    map[string]string    `yaml:"metadata,omitempty"`
 }
 
@@ -34,16 +39,40 @@ type Parameter struct {
 	Items       *Parameter           `yaml:"items,omitempty"`      // for array type
 }
 
-// RouteSpec can be either a string (template reference) or array of strings (explicit actors)
+// RouteSpec can be either a string (template reference) or array of strings (explicit actors).
+// Entries of an explicit route may also be {actor, timeout, compensate} to override the actor's
+// runtime timeout or to name the actor that undoes its side effects if the envelope fails later.
 type RouteSpec struct {
-	Actors   []string // Resolved route actors
-	Template string   // Template name (if used)
+	Actors        []string                 // Resolved route actors
+	Template      string                   // Template name (if used)
+	Timeouts      map[string]time.Duration // Per-actor runtime timeouts (explicit routes only)
+	Compensations map[string]string        // Per-actor compensating actors (explicit routes only)
+}
+
+// routeStep is an entry of an explicit route: an actor name or {actor, timeout, compensate}
+type routeStep struct {
+	Actor      string `yaml:"actor"`
+	Timeout    string `yaml:"timeout,omitempty"`    // Go duration, e.g. 30s or 10m
+	Compensate string `yaml:"compensate,omitempty"` // Actor run if the envelope fails after this actor
+}
+
+// UnmarshalYAML implements custom unmarshaling for routeStep
+func (s *routeStep) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var actor string
+	if err := unmarshal(&actor); err == nil {
+		s.Actor = actor
+		return nil
+	}
+
+	type plain routeStep
+	return unmarshal((*plain)(s))
 }
 
 // ToolDefaults represents global default settings
 type ToolDefaults struct {
 	Progress *bool `yaml:"progress,omitempty"`
-	Timeout  *int  `yaml:"timeout,omitempty"` // seconds
+	Timeout  *int  `yaml:"timeout,omitempty"`  // seconds
+	MaxHops  *int  `yaml:"max_hops,omitempty"` // max actors that may process an envelope
 }
 
 // UnmarshalYAML implements custom unmarshaling for RouteSpec
@@ -56,6 +85,12 @@ func (r *RouteSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return nil
 	}
 
+	// Try array with per-actor timeouts
+	var steps []routeStep
+	if err := unmarshal(&steps); err == nil {
+		return r.setSteps(steps)
+	}
+
 	// Try string (template reference)
 	var template string
 	if err := unmarshal(&template); err == nil {
@@ -63,7 +98,58 @@ func (r *RouteSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return nil
 	}
 
-	return fmt.Errorf("route must be either an array of strings or a template name")
+	return fmt.Errorf("route must be either an array of actors or a template name")
+}
+
+// setSteps resolves route steps into actors, per-actor timeouts and compensating actors
+func (r *RouteSpec) setSteps(steps []routeStep) error {
+	r.Actors = make([]string, 0, len(steps))
+	for i, step := range steps {
+		if step.Actor == "" {
+			return fmt.Errorf("route entry %d has no actor", i)
+		}
+		r.Actors = append(r.Actors, step.Actor)
+		if err := r.setCompensation(step); err != nil {
+			return err
+		}
+		if step.Timeout == "" {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(step.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout for actor %q: %w", step.Actor, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout for actor %q must be positive", step.Actor)
+		}
+		if existing, ok := r.Timeouts[step.Actor]; ok && existing != timeout {
+			return fmt.Errorf("conflicting timeouts for actor %q: %s and %s", step.Actor, existing, timeout)
+		}
+		if r.Timeouts == nil {
+			r.Timeouts = make(map[string]time.Duration)
+		}
+		r.Timeouts[step.Actor] = timeout
+	}
+	return nil
+}
+
+// setCompensation records the compensating actor of a route step, if any
+func (r *RouteSpec) setCompensation(step routeStep) error {
+	if step.Compensate == "" {
+		return nil
+	}
+	if err := validateActorRef(step.Compensate); err != nil {
+		return fmt.Errorf("invalid compensate for actor %q: %w", step.Actor, err)
+	}
+	if existing, ok := r.Compensations[step.Actor]; ok && existing != step.Compensate {
+		return fmt.Errorf("conflicting compensations for actor %q: %s and %s", step.Actor, existing, step.Compensate)
+	}
+	if r.Compensations == nil {
+		r.Compensations = make(map[string]string)
+	}
+	r.Compensations[step.Actor] = step.Compensate
+	return nil
 }
 
 // MarshalYAML implements custom marshaling for RouteSpec
@@ -71,7 +157,51 @@ func (r RouteSpec) MarshalYAML() (interface{}, error) {
 	if r.Template != "" {
 		return r.Template, nil
 	}
-	return r.Actors, nil
+	if len(r.Timeouts) == 0 && len(r.Compensations) == 0 {
+		return r.Actors, nil
+	}
+
+	steps := make([]interface{}, 0, len(r.Actors))
+	for _, actor := range r.Actors {
+		timeout, hasTimeout := r.Timeouts[actor]
+		compensate := r.Compensations[actor]
+		if !hasTimeout && compensate == "" {
+			steps = append(steps, actor)
+			continue
+		}
+		step := routeStep{Actor: actor, Compensate: compensate}
+		if hasTimeout {
+			step.Timeout = timeout.String()
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// TimeoutsMetadata returns the per-actor timeouts in the form carried in the envelope's route metadata
+// under TimeoutsMetadataKey (actor name to Go duration string), or nil if the route sets none
+func (r *RouteSpec) TimeoutsMetadata() map[string]interface{} {
+	if len(r.Timeouts) == 0 {
+		return nil
+	}
+	timeouts := make(map[string]interface{}, len(r.Timeouts))
+	for actor, timeout := range r.Timeouts {
+		timeouts[actor] = timeout.String()
+	}
+	return timeouts
+}
+
+// CompensationsMetadata returns the compensating actors in the form carried in the envelope's route metadata
+// under CompensationsMetadataKey (actor name to compensating actor), or nil if the route sets none
+func (r *RouteSpec) CompensationsMetadata() map[string]interface{} {
+	if len(r.Compensations) == 0 {
+		return nil
+	}
+	compensations := make(map[string]interface{}, len(r.Compensations))
+	for actor, compensator := range r.Compensations {
+		compensations[actor] = compensator
+	}
+	return compensations
 }
 
 // GetActors resolves the route actors, using templates if specified
@@ -91,10 +221,19 @@ func (r *RouteSpec) GetActors(templates map[string][]string) ([]string, error) {
 	return nil, fmt.Errorf("route has no actors or template")
 }
 
+// TimeoutsMetadataKey is the route metadata key read by sidecars for per-actor runtime timeouts.
+// Mirrors envelopes.TimeoutsMetadataKey in asya-sidecar.
+const TimeoutsMetadataKey = "timeouts"
+
+// CompensationsMetadataKey is the route metadata key read by sidecars for per-actor compensating actors.
+// Mirrors envelopes.CompensationsMetadataKey in asya-sidecar.
+const CompensationsMetadataKey = "compensations"
+
 // ToolOptions represents runtime options for a tool
 type ToolOptions struct {
 	Progress bool
 	Timeout  time.Duration
+	MaxHops  int // 0 leaves the limit to the sidecar's ASYA_MAX_HOPS
 	Metadata map[string]string
 }
 
@@ -114,6 +253,9 @@ func (t *Tool) GetOptions(defaults *ToolDefaults) ToolOptions {
 		if defaults.Timeout != nil {
 			opts.Timeout = time.Duration(*defaults.Timeout) * time.Second
 		}
+		if defaults.MaxHops != nil {
+			opts.MaxHops = *defaults.MaxHops
+		}
 	}
 
 	// Apply tool-specific overrides
@@ -123,6 +265,9 @@ func (t *Tool) GetOptions(defaults *ToolDefaults) ToolOptions {
 	if t.Timeout != nil {
 		opts.Timeout = time.Duration(*t.Timeout) * time.Second
 	}
+	if t.MaxHops != nil {
+		opts.MaxHops = *t.MaxHops
+	}
 
 	return opts
 }
@@ -167,6 +312,11 @@ func (t *Tool) Validate(templates map[string][]string) error {
 	if len(actors) == 0 {
 		return fmt.Errorf("route cannot be empty")
 	}
+	for _, actor := range actors {
+		if err := validateActorRef(actor); err != nil {
+			return fmt.Errorf("invalid route: %w", err)
+		}
+	}
 
 	// Validate parameters
 	for name, param := range t.Parameters {
@@ -180,6 +330,37 @@ func (t *Tool) Validate(templates map[string][]string) error {
 		return fmt.Errorf("timeout cannot be negative")
 	}
 
+	// Validate max hops
+	if t.MaxHops != nil && *t.MaxHops < 0 {
+		return fmt.Errorf("max_hops cannot be negative")
+	}
+
+	return nil
+}
+
+// validateActorRef checks a route entry is an actor name or a qualified "namespace/actor" reference
+func validateActorRef(ref string) error {
+	namespace, actor, qualified := strings.Cut(ref, "/")
+	if qualified && (namespace == "" || actor == "" || strings.Contains(actor, "/")) {
+		return fmt.Errorf("actor reference %q must be an actor name or namespace/actor", ref)
+	}
+	return nil
+}
+
+// ValidateQueueNames checks that the queue of every actor in tool routes fits the transport's queue name limits
+func (c *Config) ValidateQueueNames(transport, namespace string, scheme naming.Scheme) error {
+	for i := range c.Tools {
+		actors, err := c.Tools[i].Route.GetActors(c.Routes)
+		if err != nil {
+			return fmt.Errorf("tool %q: %w", c.Tools[i].Name, err)
+		}
+		for _, actorRef := range actors {
+			actorNamespace, actor := types.SplitActorRef(actorRef, namespace)
+			if err := naming.ValidateQueueName(transport, scheme.QueueName(actorNamespace, actor)); err != nil {
+				return fmt.Errorf("tool %q: %w", c.Tools[i].Name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,139 @@
+package consumer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+// receiveRetryDelay is the pause after a failed receive or a redelivered event before polling the status queue again
+const receiveRetryDelay = time.Second
+
+// maxTooEarlyAttempts bounds how often an event for an unknown envelope is redelivered before it is dropped,
+// giving the event creating the envelope about maxTooEarlyAttempts * receiveRetryDelay to arrive
+const maxTooEarlyAttempts = 10
+
+// StatusApplier applies status events to the envelope store (implemented by mcp.Handler)
+type StatusApplier interface {
+	ApplyStatusEvent(event types.StatusEvent) error
+}
+
+// StatusConsumer consumes progress, final and create events that sidecars publish to the
+// status queue (ASYA_STATUS_QUEUE) instead of calling the gateway over HTTP
+type StatusConsumer struct {
+	queueClient queue.Client
+	queueName   string
+	applier     StatusApplier
+
+	// tooEarly counts the attempts of events for unknown envelopes, by event body. Events are processed
+	// one at a time, so it needs no lock.
+	tooEarly map[[sha256.Size]byte]int
+}
+
+// NewStatusConsumer creates a new status queue consumer
+func NewStatusConsumer(queueClient queue.Client, queueName string, applier StatusApplier) *StatusConsumer {
+	return &StatusConsumer{
+		queueClient: queueClient,
+		queueName:   queueName,
+		applier:     applier,
+		tooEarly:    make(map[[sha256.Size]byte]int),
+	}
+}
+
+// Start starts consuming the status queue until ctx is cancelled
+func (c *StatusConsumer) Start(ctx context.Context) error {
+	slog.Info("Starting status consumer", "queue", c.queueName)
+
+	go c.consume(ctx)
+
+	return nil
+}
+
+// consume receives events one at a time so they are applied in queue order
+func (c *StatusConsumer) consume(ctx context.Context) {
+	for {
+		msg, err := c.queueClient.Receive(ctx, c.queueName)
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.Info("Stopping status consumer", "queue", c.queueName)
+				return
+			}
+			slog.Error("Error receiving from status queue", "queue", c.queueName, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveRetryDelay):
+			}
+			continue
+		}
+
+		if !c.processMessage(ctx, msg) {
+			// Give the envelope store time to recover before the event is redelivered
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveRetryDelay):
+			}
+		}
+	}
+}
+
+// processMessage applies one status event and reports whether it was acked. Malformed events and
+// events that can never be applied (types.ErrInvalidStatusEvent) are acked, since redelivering them
+// would not change the outcome; stale events are skipped by the applier and acked too. Events for
+// unknown envelopes (types.ErrStatusEventTooEarly) may have overtaken the event creating the envelope,
+// so they are nacked for redelivery up to maxTooEarlyAttempts times, then dropped. Events that failed
+// for other reasons, e.g. an unavailable envelope store, are nacked for redelivery.
+func (c *StatusConsumer) processMessage(ctx context.Context, msg queue.QueueMessage) bool {
+	var event types.StatusEvent
+	if err := json.Unmarshal(msg.Body(), &event); err != nil {
+		slog.Error("Failed to parse status event", "error", err, "body", string(msg.Body()[:min(len(msg.Body()), 200)]))
+		c.ack(ctx, msg)
+		return true
+	}
+
+	key := sha256.Sum256(msg.Body())
+	err := c.applier.ApplyStatusEvent(event)
+	if !errors.Is(err, types.ErrStatusEventTooEarly) {
+		delete(c.tooEarly, key)
+	}
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidStatusEvent) {
+			slog.Warn("Dropping status event", "id", event.ID, "type", event.Type, "actor", event.Actor, "error", err)
+			c.ack(ctx, msg)
+			return true
+		}
+		if errors.Is(err, types.ErrStatusEventTooEarly) {
+			c.tooEarly[key]++
+			if attempts := c.tooEarly[key]; attempts >= maxTooEarlyAttempts {
+				slog.Warn("Dropping status event for unknown envelope", "id", event.ID, "type", event.Type, "actor", event.Actor, "attempts", attempts)
+				delete(c.tooEarly, key)
+				c.ack(ctx, msg)
+				return true
+			}
+			slog.Info("Status event for unknown envelope, redelivering", "id", event.ID, "type", event.Type, "actor", event.Actor, "attempts", c.tooEarly[key])
+		} else {
+			slog.Error("Failed to apply status event, redelivering", "id", event.ID, "type", event.Type, "actor", event.Actor, "error", err)
+		}
+		if err := c.queueClient.Nack(ctx, msg); err != nil {
+			slog.Error("Failed to nack status event", "id", event.ID, "error", err)
+		}
+		return false
+	}
+
+	slog.Debug("Status event applied", "id", event.ID, "type", event.Type, "actor", event.Actor)
+	c.ack(ctx, msg)
+	return true
+}
+
+func (c *StatusConsumer) ack(ctx context.Context, msg queue.QueueMessage) {
+	if err := c.queueClient.Ack(ctx, msg); err != nil {
+		slog.Error("Failed to ack status event", "error", err)
+	}
+}
@@ -0,0 +1,236 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+type fakeMessage struct {
+	body []byte
+	tag  uint64
+}
+
+func (m *fakeMessage) Body() []byte        { return m.body }
+func (m *fakeMessage) DeliveryTag() uint64 { return m.tag }
+
+// fakeQueueClient serves queued bodies from one queue and records acks
+type fakeQueueClient struct {
+	mu       sync.Mutex
+	queue    string
+	messages chan queue.QueueMessage
+	acked    []uint64
+	nacked   []uint64
+}
+
+func (c *fakeQueueClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	return nil
+}
+
+func (c *fakeQueueClient) Receive(ctx context.Context, queueName string) (queue.QueueMessage, error) {
+	if queueName != c.queue {
+		return nil, errors.New("unexpected queue " + queueName)
+	}
+	select {
+	case msg := <-c.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeQueueClient) Ack(ctx context.Context, msg queue.QueueMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acked = append(c.acked, msg.DeliveryTag())
+	return nil
+}
+
+func (c *fakeQueueClient) Nack(ctx context.Context, msg queue.QueueMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nacked = append(c.nacked, msg.DeliveryTag())
+	return nil
+}
+
+func (c *fakeQueueClient) Close() error { return nil }
+
+func (c *fakeQueueClient) ackedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.acked)
+}
+
+type fakeApplier struct {
+	mu     sync.Mutex
+	events []types.StatusEvent
+	known  map[string]bool // envelopes created by create events
+}
+
+func (a *fakeApplier) ApplyStatusEvent(event types.StatusEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+	switch event.Type {
+	case "bogus":
+		return fmt.Errorf("%w: unknown status event type", types.ErrInvalidStatusEvent)
+	case "unavailable":
+		return errors.New("failed to update envelope: connection refused")
+	case types.StatusEventCreate:
+		if a.known == nil {
+			a.known = make(map[string]bool)
+		}
+		a.known[event.ID] = true
+	case types.StatusEventFinal:
+		if !a.known[event.ID] {
+			return fmt.Errorf("%w: envelope %s not found", types.ErrStatusEventTooEarly, event.ID)
+		}
+	}
+	return nil
+}
+
+func TestStatusConsumer_AppliesAndAcksEvents(t *testing.T) {
+	client := &fakeQueueClient{queue: "asya-default-gateway-status", messages: make(chan queue.QueueMessage, 3)}
+	applier := &fakeApplier{}
+
+	progress, _ := json.Marshal(types.StatusEvent{
+		Type:     types.StatusEventProgress,
+		ID:       "env-1",
+		Progress: &types.ProgressUpdate{Actors: []string{"a"}, Status: "received"},
+	})
+	bogus, _ := json.Marshal(types.StatusEvent{Type: "bogus", ID: "env-1"})
+	client.messages <- &fakeMessage{body: progress, tag: 1}
+	client.messages <- &fakeMessage{body: []byte("not json"), tag: 2}
+	client.messages <- &fakeMessage{body: bogus, tag: 3}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewStatusConsumer(client, "asya-default-gateway-status", applier)
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.ackedCount() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 3 acked events, got %d", client.ackedCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	applier.mu.Lock()
+	defer applier.mu.Unlock()
+	if len(applier.events) != 2 {
+		t.Fatalf("Expected 2 applied events (malformed one skipped), got %d", len(applier.events))
+	}
+	if applier.events[0].ID != "env-1" || applier.events[0].Progress == nil || applier.events[0].Progress.Status != "received" {
+		t.Errorf("First event = %+v", applier.events[0])
+	}
+}
+
+func TestStatusConsumer_NacksEventsOnStoreErrors(t *testing.T) {
+	client := &fakeQueueClient{queue: "asya-default-gateway-status", messages: make(chan queue.QueueMessage, 2)}
+	applier := &fakeApplier{}
+
+	unavailable, _ := json.Marshal(types.StatusEvent{Type: "unavailable", ID: "env-1"})
+	progress, _ := json.Marshal(types.StatusEvent{
+		Type:     types.StatusEventProgress,
+		ID:       "env-1",
+		Progress: &types.ProgressUpdate{Actors: []string{"a"}, Status: "received"},
+	})
+	client.messages <- &fakeMessage{body: unavailable, tag: 1}
+	client.messages <- &fakeMessage{body: progress, tag: 2}
+
+	c := NewStatusConsumer(client, "asya-default-gateway-status", applier)
+	if acked := c.processMessage(context.Background(), <-client.messages); acked {
+		t.Error("processMessage() = true, want the event not acked on a store error")
+	}
+	if acked := c.processMessage(context.Background(), <-client.messages); !acked {
+		t.Error("processMessage() = false, want the applied event acked")
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.nacked) != 1 || client.nacked[0] != 1 {
+		t.Errorf("Nacked = %v, want [1]", client.nacked)
+	}
+	if len(client.acked) != 1 || client.acked[0] != 2 {
+		t.Errorf("Acked = %v, want [2]", client.acked)
+	}
+}
+
+func TestStatusConsumer_RetriesEventsBeforeTheirEnvelopeExists(t *testing.T) {
+	client := &fakeQueueClient{queue: "asya-default-gateway-status", messages: make(chan queue.QueueMessage, 1)}
+	applier := &fakeApplier{}
+	c := NewStatusConsumer(client, "asya-default-gateway-status", applier)
+
+	final, _ := json.Marshal(types.StatusEvent{
+		Type:  types.StatusEventFinal,
+		ID:    "child-1",
+		Final: &types.FinalStatusUpdate{Status: "succeeded"},
+	})
+	create, _ := json.Marshal(types.StatusEvent{
+		Type:   types.StatusEventCreate,
+		ID:     "child-1",
+		Create: &types.EnvelopeCreateRequest{},
+	})
+
+	// The final event overtakes the create event of its fan-out child and is redelivered
+	if acked := c.processMessage(context.Background(), &fakeMessage{body: final, tag: 1}); acked {
+		t.Fatal("processMessage() = true, want the early event nacked")
+	}
+	if acked := c.processMessage(context.Background(), &fakeMessage{body: create, tag: 2}); !acked {
+		t.Fatal("processMessage() = false, want the create event acked")
+	}
+	if acked := c.processMessage(context.Background(), &fakeMessage{body: final, tag: 3}); !acked {
+		t.Fatal("processMessage() = false, want the redelivered event applied once its envelope exists")
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.nacked) != 1 || client.nacked[0] != 1 {
+		t.Errorf("Nacked = %v, want [1]", client.nacked)
+	}
+	if len(client.acked) != 2 || client.acked[0] != 2 || client.acked[1] != 3 {
+		t.Errorf("Acked = %v, want [2 3]", client.acked)
+	}
+	if len(c.tooEarly) != 0 {
+		t.Errorf("Attempts of applied events are still tracked: %v", c.tooEarly)
+	}
+}
+
+func TestStatusConsumer_DropsEventsForUnknownEnvelopesAfterMaxAttempts(t *testing.T) {
+	client := &fakeQueueClient{queue: "asya-default-gateway-status", messages: make(chan queue.QueueMessage, 1)}
+	c := NewStatusConsumer(client, "asya-default-gateway-status", &fakeApplier{})
+
+	final, _ := json.Marshal(types.StatusEvent{
+		Type:  types.StatusEventFinal,
+		ID:    "never-created",
+		Final: &types.FinalStatusUpdate{Status: "succeeded"},
+	})
+	for attempt := 1; attempt < maxTooEarlyAttempts; attempt++ {
+		if acked := c.processMessage(context.Background(), &fakeMessage{body: final, tag: uint64(attempt)}); acked {
+			t.Fatalf("attempt %d: processMessage() = true, want the event nacked", attempt)
+		}
+	}
+	if acked := c.processMessage(context.Background(), &fakeMessage{body: final, tag: maxTooEarlyAttempts}); !acked {
+		t.Fatal("processMessage() = false, want the event dropped after maxTooEarlyAttempts")
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.nacked) != maxTooEarlyAttempts-1 || len(client.acked) != 1 {
+		t.Errorf("Nacked %d and acked %d events, want %d and 1", len(client.nacked), len(client.acked), maxTooEarlyAttempts-1)
+	}
+	if len(c.tooEarly) != 0 {
+		t.Errorf("Attempts of dropped events are still tracked: %v", c.tooEarly)
+	}
+}
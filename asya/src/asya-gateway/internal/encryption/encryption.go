@@ -0,0 +1,223 @@
+// Package encryption encrypts envelope payloads so they are never stored in plaintext in queues
+// or in the gateway database.
+//
+// An encrypted payload is a JSON string token "asya-enc:v1:{key-id}:{base64(nonce|ciphertext)}"
+// sealed with AES-256-GCM. Envelopes carrying one also set the KeyIDHeader header. The token names
+// its key, so payloads encrypted before a key rotation stay readable as long as the provider still
+// knows the old key.
+//
+// Keys come from a KeyProvider: a keyring file (for example a mounted Kubernetes secret) or
+// data keys generated by AWS KMS.
+//
+// The token format and providers mirror the sidecar's internal/encryption package; keep them in sync.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// KeyIDHeader is the envelope header naming the key the payload is encrypted with
+	KeyIDHeader = "asya_key_id"
+
+	// KeySize is the size of AES-256 keys
+	KeySize = 32
+
+	tokenPrefix = "asya-enc:v1:"
+)
+
+// Provider names accepted by Config.Provider
+const (
+	ProviderKeyring = "keyring"
+	ProviderKMS     = "kms"
+)
+
+// ErrUnknownKey is returned when a payload was encrypted with a key the provider does not have
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// KeyProvider supplies encryption keys
+type KeyProvider interface {
+	// CurrentKey returns the key new payloads are encrypted with
+	CurrentKey(ctx context.Context) (keyID string, key []byte, err error)
+	// Key returns the key with the given ID
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// Config selects and configures the key provider
+type Config struct {
+	Provider    string        // "" (disabled), "keyring" or "kms"
+	KeyringPath string        // keyring: path of the keyring file
+	KMSKeyID    string        // kms: ID, ARN or alias of the KMS key
+	KMSRegion   string        // kms: AWS region
+	KMSEndpoint string        // kms: endpoint override, e.g. a LocalStack or local-kms stand-in
+	DataKeyTTL  time.Duration // kms: how long a generated data key encrypts new payloads
+}
+
+// New creates an Encryptor from configuration. It returns nil when encryption is disabled.
+func New(ctx context.Context, cfg Config) (*Encryptor, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderKeyring:
+		provider, err := NewKeyringProvider(cfg.KeyringPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewEncryptor(provider), nil
+	case ProviderKMS:
+		client, err := NewKMSClient(ctx, cfg.KMSRegion, cfg.KMSEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		return NewEncryptor(NewKMSProvider(client, cfg.KMSKeyID, cfg.DataKeyTTL)), nil
+	default:
+		return nil, fmt.Errorf("unsupported encryption provider: %s", cfg.Provider)
+	}
+}
+
+// Encryptor encrypts and decrypts envelope payloads
+type Encryptor struct {
+	provider KeyProvider
+}
+
+// NewEncryptor creates an encryptor using keys from provider
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{provider: provider}
+}
+
+// EncryptPayload seals a JSON payload and returns it as a JSON string token together with the key ID
+func (e *Encryptor) EncryptPayload(ctx context.Context, payload json.RawMessage) (json.RawMessage, string, error) {
+	keyID, key, err := e.provider.CurrentKey(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, payload, []byte(keyID))
+
+	token, err := json.Marshal(tokenPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal encrypted payload: %w", err)
+	}
+	return token, keyID, nil
+}
+
+// DecryptPayload opens a payload produced by EncryptPayload
+func (e *Encryptor) DecryptPayload(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+	keyID, sealed, err := parseToken(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := e.provider.Key(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %q: %w", keyID, err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted payload is truncated")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload with key %q: %w", keyID, err)
+	}
+	return plaintext, nil
+}
+
+// EncryptValue seals a decoded JSON value (e.g. tool arguments) and returns the token string and key ID
+func (e *Encryptor) EncryptValue(ctx context.Context, value any) (string, string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	token, keyID, err := e.EncryptPayload(ctx, plaintext)
+	if err != nil {
+		return "", "", err
+	}
+	var tokenString string
+	if err := json.Unmarshal(token, &tokenString); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal encrypted payload: %w", err)
+	}
+	return tokenString, keyID, nil
+}
+
+// DecryptValue opens a token string produced by EncryptValue or by a sidecar.
+// Values that are not encrypted tokens are returned unchanged.
+func (e *Encryptor) DecryptValue(ctx context.Context, value any) (any, error) {
+	token, ok := value.(string)
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return value, nil
+	}
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal encrypted payload: %w", err)
+	}
+	plaintext, err := e.DecryptPayload(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(plaintext, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decrypted payload: %w", err)
+	}
+	return decoded, nil
+}
+
+// IsEncrypted reports whether payload is an encrypted payload token
+func IsEncrypted(payload json.RawMessage) bool {
+	_, _, err := parseToken(payload)
+	return err == nil
+}
+
+// parseToken extracts the key ID and sealed bytes from an encrypted payload token
+func parseToken(payload json.RawMessage) (string, []byte, error) {
+	var token string
+	if err := json.Unmarshal(bytes.TrimSpace(payload), &token); err != nil || !strings.HasPrefix(token, tokenPrefix) {
+		return "", nil, errors.New("payload is not encrypted")
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ":")
+	if !ok || keyID == "" {
+		return "", nil, errors.New("malformed encrypted payload")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed encrypted payload: %w", err)
+	}
+	return keyID, sealed, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d, want %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,138 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// fakeKMS is a local stand-in for the KMS JSON API. Data keys are "wrapped" by prefixing the KMS key ID.
+type fakeKMS struct {
+	generated atomic.Int32
+	decrypted atomic.Int32
+}
+
+func (f *fakeKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "TrentService.GenerateDataKey":
+		var in struct{ KeyId, KeySpec string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		n := byte(f.generated.Add(1))
+		key := bytes.Repeat([]byte{n}, KeySize)
+		_ = json.NewEncoder(w).Encode(map[string][]byte{
+			"Plaintext":      key,
+			"CiphertextBlob": append([]byte(in.KeyId+"/"), key...),
+		})
+	case "TrentService.Decrypt":
+		f.decrypted.Add(1)
+		var in struct{ CiphertextBlob []byte }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if len(in.CiphertextBlob) <= KeySize {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"InvalidCiphertextException","message":"bad blob"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": in.CiphertextBlob[len(in.CiphertextBlob)-KeySize:]})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestKMSClient(url string) KMSClient {
+	return &kmsHTTPClient{
+		httpClient: http.DefaultClient,
+		endpoint:   url,
+		region:     "us-east-1",
+		credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		signer: v4.NewSigner(),
+	}
+}
+
+func TestKMSProvider_RoundTrip(t *testing.T) {
+	kms := &fakeKMS{}
+	server := httptest.NewServer(kms)
+	defer server.Close()
+
+	ctx := context.Background()
+	enc := NewEncryptor(NewKMSProvider(newTestKMSClient(server.URL), "alias/asya", time.Hour))
+
+	token, keyID, err := enc.EncryptPayload(ctx, json.RawMessage(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	if !strings.HasPrefix(keyID, kmsKeyIDPrefix) {
+		t.Errorf("keyID = %q, want %s prefix", keyID, kmsKeyIDPrefix)
+	}
+	if _, _, err := enc.EncryptPayload(ctx, json.RawMessage(`{"a":2}`)); err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	if n := kms.generated.Load(); n != 1 {
+		t.Errorf("GenerateDataKey called %d times, want 1 (data key reused within TTL)", n)
+	}
+
+	// Another actor has no cached data key and decrypts it through KMS
+	reader := NewEncryptor(NewKMSProvider(newTestKMSClient(server.URL), "alias/asya", time.Hour))
+	for i := 0; i < 2; i++ {
+		plaintext, err := reader.DecryptPayload(ctx, token)
+		if err != nil {
+			t.Fatalf("DecryptPayload failed: %v", err)
+		}
+		if string(plaintext) != `{"a":1}` {
+			t.Errorf("DecryptPayload = %s", plaintext)
+		}
+	}
+	if n := kms.decrypted.Load(); n != 1 {
+		t.Errorf("Decrypt called %d times, want 1 (data key cached)", n)
+	}
+}
+
+func TestKMSProvider_DataKeyRotation(t *testing.T) {
+	kms := &fakeKMS{}
+	server := httptest.NewServer(kms)
+	defer server.Close()
+
+	ctx := context.Background()
+	provider := NewKMSProvider(newTestKMSClient(server.URL), "alias/asya", time.Nanosecond)
+
+	first, _, err := provider.CurrentKey(ctx)
+	if err != nil {
+		t.Fatalf("CurrentKey failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	second, _, err := provider.CurrentKey(ctx)
+	if err != nil {
+		t.Fatalf("CurrentKey failed: %v", err)
+	}
+	if first == second {
+		t.Error("Expected a new data key after the TTL")
+	}
+}
+
+func TestKMSProvider_Errors(t *testing.T) {
+	server := httptest.NewServer(&fakeKMS{})
+	defer server.Close()
+
+	provider := NewKMSProvider(newTestKMSClient(server.URL), "alias/asya", time.Hour)
+	if _, err := provider.Key(context.Background(), "k1"); err != ErrUnknownKey {
+		t.Errorf("Key(non-KMS id) error = %v, want ErrUnknownKey", err)
+	}
+	if _, err := provider.Key(context.Background(), kmsKeyIDPrefix+"bm9zbGFzaA"); err == nil || !strings.Contains(err.Error(), "InvalidCiphertextException") {
+		t.Errorf("Key(invalid blob) error = %v, want KMS error", err)
+	}
+}
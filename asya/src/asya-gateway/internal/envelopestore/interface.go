@@ -1,11 +1,15 @@
 package envelopestore
 
 import (
+	"errors"
 	"time"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
 )
 
+// ErrNotFound is returned for envelopes that are not in the store
+var ErrNotFound = errors.New("not found")
+
 // EnvelopeStore defines the interface for envelope storage
 type EnvelopeStore interface {
 	// Create creates a new envelope
@@ -17,6 +21,9 @@ type EnvelopeStore interface {
 	// Update updates a envelope's status
 	Update(update types.EnvelopeUpdate) error
 
+	// UpdateIf updates a envelope's status only while it is in the from status, and reports whether it did
+	UpdateIf(from types.EnvelopeStatus, update types.EnvelopeUpdate) (bool, error)
+
 	// UpdateProgress updates envelope progress (lighter weight than Update)
 	UpdateProgress(update types.EnvelopeUpdate) error
 
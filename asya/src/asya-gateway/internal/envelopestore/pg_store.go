@@ -172,16 +172,16 @@ func (s *PgStore) Create(envelope *types.Envelope) error {
 func (s *PgStore) Get(id string) (*types.Envelope, error) {
 	query := `
 		SELECT id, parent_id, status, route_actors, route_current, payload, result, error, message, timeout_sec, deadline,
-		       progress_percent, current_actor_idx, current_actor_name, actors_completed, total_actors, created_at, updated_at
+		       progress_percent, current_actor_idx, current_actor_name, actors_completed, total_actors, hops, approval, attempt, created_at, updated_at
 		FROM envelopes
 		WHERE id = $1
 	`
 
 	var envelope types.Envelope
-	var payloadJSON, resultJSON []byte
+	var payloadJSON, resultJSON, hopsJSON, approvalJSON []byte
 	var deadline *time.Time
 	var errorStr, messageStr, currentActorName *string
-	var timeoutSec *int
+	var timeoutSec, attempt *int
 
 	err := s.pool.QueryRow(s.ctx, query, id).Scan(
 		&envelope.ID,
@@ -200,12 +200,15 @@ func (s *PgStore) Get(id string) (*types.Envelope, error) {
 		&currentActorName,
 		&envelope.ActorsCompleted,
 		&envelope.TotalActors,
+		&hopsJSON,
+		&approvalJSON,
+		&attempt,
 		&envelope.CreatedAt,
 		&envelope.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("envelope %s not found", id)
+		return nil, fmt.Errorf("envelope %s %w", id, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get envelope: %w", err)
@@ -232,6 +235,10 @@ func (s *PgStore) Get(id string) (*types.Envelope, error) {
 		envelope.CurrentActorName = *currentActorName
 	}
 
+	if attempt != nil {
+		envelope.Attempt = *attempt
+	}
+
 	if payloadJSON != nil {
 		if err := json.Unmarshal(payloadJSON, &envelope.Payload); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
@@ -246,14 +253,37 @@ func (s *PgStore) Get(id string) (*types.Envelope, error) {
 		envelope.Result = map[string]interface{}{}
 	}
 
+	if hopsJSON != nil {
+		if err := json.Unmarshal(hopsJSON, &envelope.Hops); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal hops: %w", err)
+		}
+	}
+
+	if approvalJSON != nil {
+		if err := json.Unmarshal(approvalJSON, &envelope.Approval); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval: %w", err)
+		}
+	}
+
 	return &envelope, nil
 }
 
 // Update updates a envelope's status
 func (s *PgStore) Update(update types.EnvelopeUpdate) error {
+	_, err := s.update("", update)
+	return err
+}
+
+// UpdateIf updates a envelope's status only while it is in the from status, and reports whether it did
+func (s *PgStore) UpdateIf(from types.EnvelopeStatus, update types.EnvelopeUpdate) (bool, error) {
+	return s.update(from, update)
+}
+
+// update updates a envelope's status, only while it is in the from status unless from is empty
+func (s *PgStore) update(from types.EnvelopeStatus, update types.EnvelopeUpdate) (bool, error) {
 	tx, err := s.pool.Begin(s.ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() { _ = tx.Rollback(s.ctx) }()
 
@@ -262,7 +292,24 @@ func (s *PgStore) Update(update types.EnvelopeUpdate) error {
 	if update.Result != nil {
 		resultJSON, err = json.Marshal(update.Result)
 		if err != nil {
-			return fmt.Errorf("failed to marshal result: %w", err)
+			return false, fmt.Errorf("failed to marshal result: %w", err)
+		}
+	}
+
+	var hopsJSON []byte
+	if len(update.Hops) > 0 {
+		hopsJSON, err = json.Marshal(update.Hops)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal hops: %w", err)
+		}
+	}
+
+	// The parked envelope is only kept while awaiting approval
+	var approvalJSON []byte
+	if update.Approval != nil {
+		approvalJSON, err = json.Marshal(update.Approval)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal approval: %w", err)
 		}
 	}
 
@@ -273,8 +320,10 @@ func (s *PgStore) Update(update types.EnvelopeUpdate) error {
 		    error = COALESCE($3, error),
 		    message = COALESCE(NULLIF($4, ''), message),
 		    progress_percent = COALESCE($5, progress_percent),
-		    updated_at = $6
-		WHERE id = $7
+		    hops = COALESCE($6, hops),
+		    approval = $7,
+		    updated_at = $8
+		WHERE id = $9 AND ($10 = '' OR status = $10)
 	`
 
 	result, err := tx.Exec(s.ctx, updateQuery,
@@ -283,16 +332,28 @@ func (s *PgStore) Update(update types.EnvelopeUpdate) error {
 		update.Error,
 		update.Message,
 		update.ProgressPercent,
+		hopsJSON,
+		approvalJSON,
 		update.Timestamp,
 		update.ID,
+		string(from),
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update envelope: %w", err)
+		return false, fmt.Errorf("failed to update envelope: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("envelope %s not found", update.ID)
+		if from != "" {
+			var exists bool
+			if err := tx.QueryRow(s.ctx, `SELECT EXISTS(SELECT 1 FROM envelopes WHERE id = $1)`, update.ID).Scan(&exists); err != nil {
+				return false, fmt.Errorf("failed to check envelope: %w", err)
+			}
+			if exists {
+				return false, nil
+			}
+		}
+		return false, fmt.Errorf("envelope %s %w", update.ID, ErrNotFound)
 	}
 
 	// Insert update record for SSE streaming
@@ -327,11 +388,11 @@ func (s *PgStore) Update(update types.EnvelopeUpdate) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert envelope update: %w", err)
+		return false, fmt.Errorf("failed to insert envelope update: %w", err)
 	}
 
 	if err := tx.Commit(s.ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// Cancel timeout timer if envelope reaches final state
@@ -346,7 +407,7 @@ func (s *PgStore) Update(update types.EnvelopeUpdate) error {
 	s.notifyListeners(update)
 	s.mu.RUnlock()
 
-	return nil
+	return true, nil
 }
 
 // UpdateProgress updates envelope progress (more frequent, lighter update)
@@ -380,9 +441,10 @@ func (s *PgStore) UpdateProgress(update types.EnvelopeUpdate) error {
 		    message = COALESCE(NULLIF($4, ''), message),
 		    route_actors = COALESCE($5, route_actors),
 		    total_actors = COALESCE($6, total_actors),
-		    status = $7,
-		    updated_at = $8
-		WHERE id = $9
+		    status = CASE WHEN status IN ('awaiting_approval', 'compensating') THEN status ELSE $7 END,
+		    attempt = COALESCE($8, attempt),
+		    updated_at = $9
+		WHERE id = $10
 	`
 
 	_, err = tx.Exec(s.ctx, updateQuery,
@@ -393,6 +455,7 @@ func (s *PgStore) UpdateProgress(update types.EnvelopeUpdate) error {
 		update.Actors,
 		totalActors,
 		update.Status,
+		update.Attempt,
 		update.Timestamp,
 		update.ID,
 	)
@@ -67,7 +67,7 @@ func (s *Store) Get(id string) (*types.Envelope, error) {
 
 	envelope, exists := s.envelopes[id]
 	if !exists {
-		return nil, fmt.Errorf("envelope %s not found", id)
+		return nil, fmt.Errorf("envelope %s %w", id, ErrNotFound)
 	}
 
 	return envelope, nil
@@ -80,9 +80,32 @@ func (s *Store) Update(update types.EnvelopeUpdate) error {
 
 	envelope, exists := s.envelopes[update.ID]
 	if !exists {
-		return fmt.Errorf("envelope %s not found", update.ID)
+		return fmt.Errorf("envelope %s %w", update.ID, ErrNotFound)
 	}
 
+	s.applyUpdate(envelope, update)
+	return nil
+}
+
+// UpdateIf updates a envelope's status only while it is in the from status, and reports whether it did
+func (s *Store) UpdateIf(from types.EnvelopeStatus, update types.EnvelopeUpdate) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envelope, exists := s.envelopes[update.ID]
+	if !exists {
+		return false, fmt.Errorf("envelope %s %w", update.ID, ErrNotFound)
+	}
+	if envelope.Status != from {
+		return false, nil
+	}
+
+	s.applyUpdate(envelope, update)
+	return true, nil
+}
+
+// applyUpdate applies an update to an envelope (must be called with lock held)
+func (s *Store) applyUpdate(envelope *types.Envelope, update types.EnvelopeUpdate) {
 	envelope.Status = update.Status
 	envelope.UpdatedAt = update.Timestamp
 
@@ -94,6 +117,10 @@ func (s *Store) Update(update types.EnvelopeUpdate) error {
 		envelope.Error = update.Error
 	}
 
+	if update.Message != "" {
+		envelope.Message = update.Message
+	}
+
 	if update.ProgressPercent != nil {
 		envelope.ProgressPercent = *update.ProgressPercent
 	}
@@ -110,6 +137,13 @@ func (s *Store) Update(update types.EnvelopeUpdate) error {
 		envelope.TotalActors = len(update.Actors)
 	}
 
+	if len(update.Hops) > 0 {
+		envelope.Hops = update.Hops
+	}
+
+	// The parked envelope is only kept while awaiting approval
+	envelope.Approval = update.Approval
+
 	// Cancel timeout timer if envelope reaches final state
 	if s.isFinal(update.Status) {
 		s.cancelTimer(update.ID)
@@ -120,8 +154,6 @@ func (s *Store) Update(update types.EnvelopeUpdate) error {
 
 	// Notify listeners
 	s.notifyListeners(update)
-
-	return nil
 }
 
 // UpdateProgress updates envelope progress (lighter weight update for frequent progress reports)
@@ -131,10 +163,14 @@ func (s *Store) UpdateProgress(update types.EnvelopeUpdate) error {
 
 	envelope, exists := s.envelopes[update.ID]
 	if !exists {
-		return fmt.Errorf("envelope %s not found", update.ID)
+		return fmt.Errorf("envelope %s %w", update.ID, ErrNotFound)
 	}
 
-	envelope.Status = update.Status
+	// A late progress report from the actor before an approval step must not resume the parked envelope,
+	// nor one from the failed actor move a compensating envelope back to running
+	if envelope.Status != types.EnvelopeStatusAwaitingApproval && envelope.Status != types.EnvelopeStatusCompensating {
+		envelope.Status = update.Status
+	}
 	envelope.UpdatedAt = update.Timestamp
 
 	if update.ProgressPercent != nil {
@@ -152,6 +188,10 @@ func (s *Store) UpdateProgress(update types.EnvelopeUpdate) error {
 		envelope.Message = update.Message
 	}
 
+	if update.Attempt != nil {
+		envelope.Attempt = *update.Attempt
+	}
+
 	if len(update.Actors) > 0 {
 		envelope.Route.Actors = update.Actors
 		envelope.TotalActors = len(update.Actors)
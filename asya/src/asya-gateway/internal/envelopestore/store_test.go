@@ -654,6 +654,76 @@ func TestHandleTimeout(t *testing.T) {
 	}
 }
 
+// TestUpdate_AwaitingApproval tests that the parked envelope is kept only while awaiting approval
+// and that late progress reports do not resume it
+func TestUpdate_AwaitingApproval(t *testing.T) {
+	store := NewStore()
+	if err := store.Create(&types.Envelope{ID: "test-approval", Route: types.Route{Actors: []string{"review", "approval", "publish"}}}); err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+
+	parked := &types.ApprovalRequest{ID: "test-approval", Step: "approval", Route: types.Route{Actors: []string{"review", "approval", "publish"}, Current: 2}}
+	if err := store.Update(types.EnvelopeUpdate{ID: "test-approval", Status: types.EnvelopeStatusAwaitingApproval, Approval: parked, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	idx := 0
+	if err := store.UpdateProgress(types.EnvelopeUpdate{ID: "test-approval", Status: types.EnvelopeStatusRunning, CurrentActorIdx: &idx, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("UpdateProgress failed: %v", err)
+	}
+
+	env, _ := store.Get("test-approval")
+	if env.Status != types.EnvelopeStatusAwaitingApproval {
+		t.Errorf("Status after late progress = %v, want awaiting_approval", env.Status)
+	}
+	if env.Approval != parked {
+		t.Errorf("Approval = %+v, want parked envelope", env.Approval)
+	}
+
+	if err := store.Update(types.EnvelopeUpdate{ID: "test-approval", Status: types.EnvelopeStatusRunning, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	env, _ = store.Get("test-approval")
+	if env.Status != types.EnvelopeStatusRunning || env.Approval != nil {
+		t.Errorf("After approval: status = %v, approval = %+v, want running without parked envelope", env.Status, env.Approval)
+	}
+}
+
+func TestUpdateIf(t *testing.T) {
+	store := NewStore()
+	if err := store.Create(&types.Envelope{ID: "test-update-if", Route: types.Route{Actors: []string{"approval", "publish"}}}); err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+
+	running := types.EnvelopeUpdate{ID: "test-update-if", Status: types.EnvelopeStatusRunning, Timestamp: time.Now()}
+	updated, err := store.UpdateIf(types.EnvelopeStatusAwaitingApproval, running)
+	if err != nil || updated {
+		t.Fatalf("UpdateIf() from pending = %v, %v, want false without error", updated, err)
+	}
+	if env, _ := store.Get("test-update-if"); env.Status != types.EnvelopeStatusPending {
+		t.Errorf("Status = %v, want pending", env.Status)
+	}
+
+	parked := types.EnvelopeUpdate{ID: "test-update-if", Status: types.EnvelopeStatusAwaitingApproval, Approval: &types.ApprovalRequest{ID: "test-update-if"}, Timestamp: time.Now()}
+	if err := store.Update(parked); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, err = store.UpdateIf(types.EnvelopeStatusAwaitingApproval, running)
+	if err != nil || !updated {
+		t.Fatalf("UpdateIf() from awaiting_approval = %v, %v, want true without error", updated, err)
+	}
+	if env, _ := store.Get("test-update-if"); env.Status != types.EnvelopeStatusRunning || env.Approval != nil {
+		t.Errorf("Status = %v, approval = %+v, want running without parked envelope", env.Status, env.Approval)
+	}
+
+	if updated, _ := store.UpdateIf(types.EnvelopeStatusAwaitingApproval, running); updated {
+		t.Error("UpdateIf() applied twice")
+	}
+	if _, err := store.UpdateIf(types.EnvelopeStatusAwaitingApproval, types.EnvelopeUpdate{ID: "missing"}); err == nil {
+		t.Error("Expected error for unknown envelope")
+	}
+}
+
 // TestCancelTimer tests timer cancellation on final status
 func TestCancelTimer(t *testing.T) {
 	store := NewStore()
@@ -0,0 +1,295 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+var (
+	envelopeApprovalPathRegex = regexp.MustCompile(`^/envelopes/([^/]+)/approval$`)
+	envelopeApprovePathRegex  = regexp.MustCompile(`^/envelopes/([^/]+)/approve$`)
+	envelopeRejectPathRegex   = regexp.MustCompile(`^/envelopes/([^/]+)/reject$`)
+)
+
+// errNotAwaitingApproval is returned when approving or rejecting an envelope that is not parked at an approval step
+var errNotAwaitingApproval = errors.New("envelope is not awaiting approval")
+
+// errInvalidSignature is returned when a parked envelope fails signature verification
+var errInvalidSignature = errors.New("invalid envelope signature")
+
+// HandleEnvelopeApproval handles POST /envelopes/{id}/approval (for sidecars to park an envelope at an approval step)
+func (h *Handler) HandleEnvelopeApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matches := envelopeApprovalPathRegex.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		http.Error(w, "Invalid envelope approval path", http.StatusBadRequest)
+		return
+	}
+	envelopeID := matches[1]
+
+	var request types.ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.ID = envelopeID
+
+	if err := h.applyApprovalRequest(r.Context(), request); err != nil {
+		if errors.Is(err, errInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// applyApprovalRequest parks an envelope until it is approved or rejected.
+// Requests for envelopes that already succeeded or failed (e.g. timed out) are ignored.
+func (h *Handler) applyApprovalRequest(ctx context.Context, request types.ApprovalRequest) error {
+	if err := h.verifyParked(ctx, &request); err != nil {
+		slog.Warn("Rejecting approval request", "id", request.ID, "step", request.Step, "error", err)
+		return err
+	}
+
+	envelope, err := h.jobStore.Get(request.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get envelope: %w", err)
+	}
+	if isFinalStatus(envelope.Status) {
+		slog.Debug("Ignoring approval request for finished envelope", "id", request.ID, "status", envelope.Status)
+		return nil
+	}
+
+	slog.Info("Envelope awaiting approval", "id", request.ID, "step", request.Step, "next_actor", currentActor(request.Route))
+
+	update := types.EnvelopeUpdate{
+		ID:        request.ID,
+		Status:    types.EnvelopeStatusAwaitingApproval,
+		Message:   fmt.Sprintf("Awaiting approval at step '%s'", request.Step),
+		Actor:     request.Step,
+		Approval:  &request,
+		Timestamp: time.Now(),
+	}
+	if err := h.jobStore.Update(update); err != nil {
+		slog.Error("Failed to park envelope for approval", "id", request.ID, "error", err)
+		return fmt.Errorf("failed to update envelope: %w", err)
+	}
+	return nil
+}
+
+// HandleEnvelopeApprove handles POST /envelopes/{id}/approve (for reviewers to resume a parked envelope).
+// The optional body may replace the payload sent to the next actor.
+func (h *Handler) HandleEnvelopeApprove(w http.ResponseWriter, r *http.Request) {
+	h.handleApprovalDecision(w, r, envelopeApprovePathRegex, h.approveEnvelope)
+}
+
+// HandleEnvelopeReject handles POST /envelopes/{id}/reject (for reviewers to fail a parked envelope)
+func (h *Handler) HandleEnvelopeReject(w http.ResponseWriter, r *http.Request) {
+	h.handleApprovalDecision(w, r, envelopeRejectPathRegex, h.rejectEnvelope)
+}
+
+// handleApprovalDecision parses an approve or reject request and applies it with decide
+func (h *Handler) handleApprovalDecision(w http.ResponseWriter, r *http.Request, pathRegex *regexp.Regexp, decide func(context.Context, string, types.ApprovalDecision) error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matches := pathRegex.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		http.Error(w, "Invalid envelope approval path", http.StatusBadRequest)
+		return
+	}
+	envelopeID := matches[1]
+
+	// The body is optional
+	var decision types.ApprovalDecision
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := h.jobStore.Get(envelopeID); err != nil {
+		http.Error(w, "Envelope not found", http.StatusNotFound)
+		return
+	}
+
+	if err := decide(r.Context(), envelopeID, decision); err != nil {
+		if errors.Is(err, errNotAwaitingApproval) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, errInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// approveEnvelope sends a parked envelope to the actor after its approval step
+func (h *Handler) approveEnvelope(ctx context.Context, envelopeID string, decision types.ApprovalDecision) error {
+	envelope, err := h.jobStore.Get(envelopeID)
+	if err != nil {
+		return fmt.Errorf("failed to get envelope: %w", err)
+	}
+	parked := envelope.Approval
+	if envelope.Status != types.EnvelopeStatusAwaitingApproval || parked == nil {
+		return errNotAwaitingApproval
+	}
+	if h.server == nil || h.server.queueClient == nil {
+		return fmt.Errorf("queue client not configured")
+	}
+	if err := h.verifyParked(ctx, parked); err != nil {
+		slog.Error("Refusing to resume parked envelope", "id", envelopeID, "step", parked.Step, "error", err)
+		return err
+	}
+
+	resumed := parkedEnvelope(parked)
+	resumed.ID = envelopeID
+	resumed.Deadline = envelope.Deadline // Time spent awaiting approval counts against the timeout
+
+	if decision.Payload != nil {
+		var payload any
+		if err := json.Unmarshal(decision.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		resumed.Payload = payload
+
+		headers := make(map[string]interface{}, len(parked.Headers))
+		for k, v := range parked.Headers {
+			if k != encryption.KeyIDHeader {
+				headers[k] = v
+			}
+		}
+		resumed.Headers = headers
+		if err := sealEnvelope(ctx, h.encryptor, resumed); err != nil {
+			return fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+	}
+
+	message := fmt.Sprintf("Approved at step '%s'", parked.Step)
+	if decision.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, decision.Reason)
+	}
+
+	// Leave the approval state before sending; only the approve that does so sends the envelope
+	approved, err := h.jobStore.UpdateIf(types.EnvelopeStatusAwaitingApproval, types.EnvelopeUpdate{
+		ID:        envelopeID,
+		Status:    types.EnvelopeStatusRunning,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update envelope: %w", err)
+	}
+	if !approved {
+		return errNotAwaitingApproval
+	}
+
+	slog.Info("Envelope approved", "id", envelopeID, "step", parked.Step, "next_actor", currentActor(parked.Route), "edited", decision.Payload != nil)
+
+	if err := h.server.queueClient.SendEnvelope(ctx, resumed); err != nil {
+		slog.Error("Failed to send approved envelope to queue", "id", envelopeID, "error", err)
+		_ = h.jobStore.Update(types.EnvelopeUpdate{
+			ID:        envelopeID,
+			Status:    types.EnvelopeStatusFailed,
+			Error:     fmt.Sprintf("failed to send envelope: %v", err),
+			Timestamp: time.Now(),
+		})
+		return fmt.Errorf("failed to send envelope: %w", err)
+	}
+	return nil
+}
+
+// rejectEnvelope fails a parked envelope
+func (h *Handler) rejectEnvelope(_ context.Context, envelopeID string, decision types.ApprovalDecision) error {
+	envelope, err := h.jobStore.Get(envelopeID)
+	if err != nil {
+		return fmt.Errorf("failed to get envelope: %w", err)
+	}
+	parked := envelope.Approval
+	if envelope.Status != types.EnvelopeStatusAwaitingApproval || parked == nil {
+		return errNotAwaitingApproval
+	}
+
+	errorMsg := fmt.Sprintf("Rejected at step '%s'", parked.Step)
+	if decision.Reason != "" {
+		errorMsg = fmt.Sprintf("%s: %s", errorMsg, decision.Reason)
+	}
+
+	rejected, err := h.jobStore.UpdateIf(types.EnvelopeStatusAwaitingApproval, types.EnvelopeUpdate{
+		ID:        envelopeID,
+		Status:    types.EnvelopeStatusFailed,
+		Message:   errorMsg,
+		Error:     errorMsg,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update envelope: %w", err)
+	}
+	if !rejected {
+		return errNotAwaitingApproval
+	}
+
+	slog.Info("Envelope rejected", "id", envelopeID, "step", parked.Step, "reason", decision.Reason)
+	return nil
+}
+
+// parkedEnvelope returns the envelope to send once a parked envelope is approved
+func parkedEnvelope(parked *types.ApprovalRequest) *types.Envelope {
+	return &types.Envelope{
+		ID:        parked.ID,
+		ParentID:  parked.ParentID,
+		Route:     parked.Route,
+		Headers:   parked.Headers,
+		Payload:   parked.Payload,
+		MaxHops:   parked.MaxHops,
+		HopCount:  parked.HopCount,
+		Visited:   parked.Visited,
+		Hops:      parked.Hops,
+		CallStack: parked.CallStack,
+	}
+}
+
+// verifyParked checks the signature the sidecar set on a parked envelope when signing is enabled
+func (h *Handler) verifyParked(ctx context.Context, parked *types.ApprovalRequest) error {
+	if h.signer == nil {
+		return nil
+	}
+	if err := h.signer.Verify(ctx, *parkedEnvelope(parked)); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+	return nil
+}
+
+// currentActor returns the current actor of a route, or "" when the route is exhausted
+func currentActor(route types.Route) string {
+	if route.Current >= 0 && route.Current < len(route.Actors) {
+		return route.Actors[route.Current]
+	}
+	return ""
+}
@@ -7,9 +7,12 @@ import (
 	"log/slog"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
 	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
+	"github.com/deliveryhero/asya/asya-gateway/internal/signing"
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -25,8 +28,10 @@ var (
 // Handler provides HTTP endpoints for envelope management
 // MCP endpoints are now handled directly by mark3labs/mcp-go server
 type Handler struct {
-	jobStore envelopestore.EnvelopeStore
-	server   *Server // For direct tool calls
+	jobStore  envelopestore.EnvelopeStore
+	server    *Server               // For direct tool calls
+	encryptor *encryption.Encryptor // Decrypts stored payloads and results for clients (optional)
+	signer    *signing.Signer       // Verifies envelopes parked at approval steps (optional)
 }
 
 // NewHandler creates a new HTTP handler for envelope management
@@ -41,6 +46,77 @@ func (h *Handler) SetServer(server *Server) {
 	h.server = server
 }
 
+// SetEncryptor enables decryption of stored payloads and results in status responses
+func (h *Handler) SetEncryptor(encryptor *encryption.Encryptor) {
+	h.encryptor = encryptor
+}
+
+// SetSigner enables signature verification of envelopes parked at approval steps. Without a valid
+// signature an envelope is neither parked nor resumed, so the gateway never signs a route it has not verified.
+func (h *Handler) SetSigner(signer *signing.Signer) {
+	h.signer = signer
+}
+
+// openEnvelope returns a copy of the envelope with its payload and result decrypted
+func (h *Handler) openEnvelope(ctx context.Context, envelope *types.Envelope) (*types.Envelope, error) {
+	if h.encryptor == nil {
+		return envelope, nil
+	}
+
+	opened := *envelope
+	var err error
+	if opened.Payload, err = h.encryptor.DecryptValue(ctx, envelope.Payload); err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	if opened.Result, err = h.encryptor.DecryptValue(ctx, envelope.Result); err != nil {
+		return nil, fmt.Errorf("failed to decrypt result: %w", err)
+	}
+	if envelope.Approval != nil {
+		if opened.Approval, err = h.openApproval(ctx, envelope.Approval); err != nil {
+			return nil, err
+		}
+	}
+	return &opened, nil
+}
+
+// openApproval returns a copy of a parked envelope with its payload decrypted for reviewers
+func (h *Handler) openApproval(ctx context.Context, approval *types.ApprovalRequest) (*types.ApprovalRequest, error) {
+	if !encryption.IsEncrypted(approval.Payload) {
+		return approval, nil
+	}
+	opened := *approval
+	var err error
+	if opened.Payload, err = h.encryptor.DecryptPayload(ctx, approval.Payload); err != nil {
+		return nil, fmt.Errorf("failed to decrypt approval payload: %w", err)
+	}
+	return &opened, nil
+}
+
+// openUpdate decrypts the result and parked payload of a streamed update; on failure they are left encrypted
+func (h *Handler) openUpdate(ctx context.Context, update types.EnvelopeUpdate) types.EnvelopeUpdate {
+	if h.encryptor == nil {
+		return update
+	}
+
+	if update.Result != nil {
+		result, err := h.encryptor.DecryptValue(ctx, update.Result)
+		if err != nil {
+			slog.Error("Failed to decrypt envelope result", "id", update.ID, "error", err)
+		} else {
+			update.Result = result
+		}
+	}
+	if update.Approval != nil {
+		approval, err := h.openApproval(ctx, update.Approval)
+		if err != nil {
+			slog.Error("Failed to decrypt approval payload", "id", update.ID, "error", err)
+		} else {
+			update.Approval = approval
+		}
+	}
+	return update
+}
+
 // HandleToolCall handles POST /tools/call (REST endpoint for MCP tool calls)
 // This provides a simpler REST interface without requiring SSE session management
 func (h *Handler) HandleToolCall(w http.ResponseWriter, r *http.Request) {
@@ -108,13 +184,7 @@ func (h *Handler) HandleEnvelopeCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse create request
-	var createReq struct {
-		ID       string   `json:"id"`
-		ParentID string   `json:"parent_id"`
-		Actors   []string `json:"actors"`
-		Current  int      `json:"current"`
-	}
-
+	var createReq types.EnvelopeCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
@@ -125,6 +195,17 @@ func (h *Handler) HandleEnvelopeCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.createFanoutEnvelope(createReq); err != nil {
+		http.Error(w, "Failed to create envelope", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "id": createReq.ID})
+}
+
+// createFanoutEnvelope stores a fanout child envelope and sends it to its current actor
+func (h *Handler) createFanoutEnvelope(createReq types.EnvelopeCreateRequest) error {
 	slog.Info("Creating fanout envelope", "id", createReq.ID, "parent_id", createReq.ParentID)
 
 	// Create minimal envelope for fanout child
@@ -140,8 +221,7 @@ func (h *Handler) HandleEnvelopeCreate(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.jobStore.Create(envelope); err != nil {
 		slog.Error("Failed to create fanout envelope", "id", createReq.ID, "error", err)
-		http.Error(w, "Failed to create envelope", http.StatusInternalServerError)
-		return
+		return fmt.Errorf("failed to create envelope: %w", err)
 	}
 
 	slog.Info("Fanout envelope created successfully", "id", createReq.ID)
@@ -177,8 +257,7 @@ func (h *Handler) HandleEnvelopeCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "id": createReq.ID})
+	return nil
 }
 
 // HandleJobStatus handles GET /envelopes/{id}
@@ -201,6 +280,13 @@ func (h *Handler) HandleEnvelopeStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	envelope, err = h.openEnvelope(r.Context(), envelope)
+	if err != nil {
+		slog.Error("Failed to decrypt envelope", "id", envelopeID, "error", err)
+		http.Error(w, "Failed to decrypt envelope", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(envelope); err != nil {
 		slog.Error("Failed to encode envelope", "error", err)
@@ -245,7 +331,7 @@ func (h *Handler) HandleEnvelopeStream(w http.ResponseWriter, r *http.Request) {
 		slog.Warn("Failed to get historical updates", "error", err, "envelope_id", envelopeID)
 	} else {
 		for _, update := range historicalUpdates {
-			data, err := json.Marshal(update)
+			data, err := json.Marshal(h.openUpdate(r.Context(), update))
 			if err != nil {
 				slog.Error("Failed to marshal historical update", "error", err)
 				continue
@@ -278,7 +364,7 @@ func (h *Handler) HandleEnvelopeStream(w http.ResponseWriter, r *http.Request) {
 			flusher.Flush()
 		case update := <-updateChan:
 			// Send update
-			data, err := json.Marshal(update)
+			data, err := json.Marshal(h.openUpdate(r.Context(), update))
 			if err != nil {
 				slog.Error("Failed to marshal update", "error", err)
 				continue
@@ -354,6 +440,79 @@ func (h *Handler) HandleEnvelopeProgress(w http.ResponseWriter, r *http.Request)
 
 	progress.ID = envelopeID
 
+	percent, err := h.applyProgress(progress)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":           "ok",
+		"progress_percent": percent,
+	})
+}
+
+// HandleEnvelopeProgressBatch handles POST /envelopes/progress/batch (for sidecars reporting buffered progress).
+// Each update is applied independently; per-update failures are reported in the response.
+func (h *Handler) HandleEnvelopeProgressBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var batch types.ProgressBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]types.ProgressBatchResult, 0, len(batch.Updates))
+	failed := 0
+	for _, progress := range batch.Updates {
+		if progress.ID == "" {
+			results = append(results, types.ProgressBatchResult{Error: "missing envelope id"})
+			failed++
+			continue
+		}
+
+		percent, err := h.applyProgress(progress)
+		if err != nil {
+			results = append(results, types.ProgressBatchResult{ID: progress.ID, Error: err.Error()})
+			failed++
+			continue
+		}
+		results = append(results, types.ProgressBatchResult{ID: progress.ID, ProgressPercent: percent})
+	}
+
+	slog.Debug("Progress batch applied", "updates", len(batch.Updates), "failed", failed)
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "ok",
+		"results": results,
+	})
+}
+
+// progressWeight returns the progress contribution of an actor status: received=10, processing=50, completed=100
+func progressWeight(status string) float64 {
+	switch status {
+	case "received":
+		return 10
+	case "processing":
+		return 50
+	case "completed":
+		return 100
+	default:
+		return 0
+	}
+}
+
+// applyProgress calculates progress percentage for an actor update and stores it.
+// Returns the stored percentage; updates for envelopes already in a final state are ignored.
+func (h *Handler) applyProgress(progress types.ProgressUpdate) (float64, error) {
+	envelopeID := progress.ID
+
 	slog.Debug("Received progress update from actor",
 		"envelope_id", envelopeID,
 		"status", progress.Status,
@@ -362,26 +521,24 @@ func (h *Handler) HandleEnvelopeProgress(w http.ResponseWriter, r *http.Request)
 
 	// Calculate progress percentage
 	// Formula: (actorIndex * 100 + statusWeight) / totalActors
-	// statusWeight: received=10, processing=50, completed=100
-	var statusWeight float64
-	switch progress.Status {
-	case "received":
-		statusWeight = 10
-	case "processing":
-		statusWeight = 50
-	case "completed":
-		statusWeight = 100
-	default:
-		statusWeight = 0
-	}
+	statusWeight := progressWeight(progress.Status)
 
 	// Always fetch envelope to get authoritative actors list and total_actors
 	// This ensures progress calculation is consistent even if sidecar sends partial/empty actors list
 	envelope, err := h.jobStore.Get(envelopeID)
 	if err != nil {
 		slog.Error("Failed to get envelope for progress calculation", "id", envelopeID, "error", err)
-		http.Error(w, "Failed to get envelope", http.StatusInternalServerError)
-		return
+		return 0, fmt.Errorf("failed to get envelope")
+	}
+
+	// Buffered updates can arrive after the envelope finished; never reopen it
+	if envelope.Status == types.EnvelopeStatusSucceeded || envelope.Status == types.EnvelopeStatusFailed {
+		slog.Debug("Ignoring progress update for finished envelope", "id", envelopeID, "status", envelope.Status)
+		return envelope.ProgressPercent, nil
+	}
+
+	if progress.Compensating {
+		return h.applyCompensationProgress(envelope, progress)
 	}
 
 	// Use envelope's actors list as the source of truth
@@ -435,14 +592,17 @@ func (h *Handler) HandleEnvelopeProgress(w http.ResponseWriter, r *http.Request)
 		Actors:          progress.Actors,
 		CurrentActorIdx: &progress.CurrentActorIdx,
 		EnvelopeState:   &envelopeState,
+		HopCount:        &progress.HopCount,
 		Timestamp:       time.Now(),
 	}
+	if progress.Attempt > 0 {
+		update.Attempt = &progress.Attempt
+	}
 
 	// Update envelope store (using UpdateProgress for lighter weight update)
 	if err := h.jobStore.UpdateProgress(update); err != nil {
 		slog.Error("Failed to update envelope progress", "error", err)
-		http.Error(w, "Failed to update progress", http.StatusInternalServerError)
-		return
+		return 0, fmt.Errorf("failed to update progress")
 	}
 
 	slog.Debug("Progress update stored in postgres",
@@ -451,11 +611,41 @@ func (h *Handler) HandleEnvelopeProgress(w http.ResponseWriter, r *http.Request)
 		"current_actor_idx", progress.CurrentActorIdx,
 		"progress_percent", progress.ProgressPercent)
 
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":           "ok",
-		"progress_percent": progress.ProgressPercent,
-	})
+	return progress.ProgressPercent, nil
+}
+
+// applyCompensationProgress stores progress of a compensating actor. The envelope already failed, so its
+// route and progress percentage are left as they were at the failure; only the status and message change.
+func (h *Handler) applyCompensationProgress(envelope *types.Envelope, progress types.ProgressUpdate) (float64, error) {
+	actor := ""
+	if progress.CurrentActorIdx >= 0 && progress.CurrentActorIdx < len(progress.Actors) {
+		actor = progress.Actors[progress.CurrentActorIdx]
+	}
+	message := progress.Message
+	if message == "" {
+		message = fmt.Sprintf("Compensating at actor '%s'", actor)
+	}
+
+	envelopeState := progress.Status
+	update := types.EnvelopeUpdate{
+		ID:            envelope.ID,
+		Status:        types.EnvelopeStatusCompensating,
+		Message:       message,
+		Actor:         actor,
+		EnvelopeState: &envelopeState,
+		Timestamp:     time.Now(),
+	}
+	if err := h.jobStore.UpdateProgress(update); err != nil {
+		slog.Error("Failed to update envelope compensation progress", "error", err)
+		return 0, fmt.Errorf("failed to update progress")
+	}
+
+	slog.Debug("Compensation progress stored",
+		"envelope_id", envelope.ID,
+		"actor", actor,
+		"status", progress.Status)
+
+	return envelope.ProgressPercent, nil
 }
 
 // HandleJobFinal handles POST /envelopes/{id}/final (for end actors to report final status)
@@ -474,38 +664,42 @@ func (h *Handler) HandleEnvelopeFinal(w http.ResponseWriter, r *http.Request) {
 	envelopeID := matches[1]
 
 	// Parse final status update
-	var finalUpdate struct {
-		ID               string                 `json:"id"`
-		Status           string                 `json:"status"`
-		Progress         *float64               `json:"progress"`
-		Result           interface{}            `json:"result"`
-		Error            string                 `json:"error"`
-		ErrorDetails     interface{}            `json:"error_details"`
-		Metadata         map[string]interface{} `json:"metadata"`
-		Actors           []string               `json:"actors"`
-		CurrentActorIdx  *int                   `json:"current_actor_idx"`
-		CurrentActorName string                 `json:"current_actor_name"`
-		Timestamp        string                 `json:"timestamp"`
-	}
-
+	var finalUpdate types.FinalStatusUpdate
 	if err := json.NewDecoder(r.Body).Decode(&finalUpdate); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Determine envelope status from final update
-	var envelopeStatus types.EnvelopeStatus
-	switch finalUpdate.Status {
-	case "succeeded":
-		envelopeStatus = types.EnvelopeStatusSucceeded
-	case "failed":
-		envelopeStatus = types.EnvelopeStatusFailed
-	default:
+	envelopeStatus, ok := finalEnvelopeStatus(finalUpdate.Status)
+	if !ok {
 		slog.Error("Invalid final status", "id", envelopeID, "status", finalUpdate.Status)
 		http.Error(w, "Invalid status: must be 'succeeded' or 'failed'", http.StatusBadRequest)
 		return
 	}
 
+	if err := h.applyFinal(envelopeID, envelopeStatus, finalUpdate); err != nil {
+		http.Error(w, "Failed to update envelope", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// finalEnvelopeStatus maps the status reported by end actors to an envelope status
+func finalEnvelopeStatus(status string) (types.EnvelopeStatus, bool) {
+	switch status {
+	case "succeeded":
+		return types.EnvelopeStatusSucceeded, true
+	case "failed":
+		return types.EnvelopeStatusFailed, true
+	default:
+		return "", false
+	}
+}
+
+// applyFinal stores the final status reported by an end actor
+func (h *Handler) applyFinal(envelopeID string, envelopeStatus types.EnvelopeStatus, finalUpdate types.FinalStatusUpdate) error {
 	slog.Info("Received final status from end actor",
 		"id", envelopeID,
 		"status", envelopeStatus,
@@ -533,6 +727,7 @@ func (h *Handler) HandleEnvelopeFinal(w http.ResponseWriter, r *http.Request) {
 	if len(finalUpdate.Actors) > 0 {
 		update.Actors = finalUpdate.Actors
 	}
+	update.Hops = finalUpdate.Hops
 
 	// Set message and error based on status
 	if envelopeStatus == types.EnvelopeStatusSucceeded {
@@ -552,6 +747,9 @@ func (h *Handler) HandleEnvelopeFinal(w http.ResponseWriter, r *http.Request) {
 				update.Message = fmt.Sprintf("Envelope failed: %s", finalUpdate.Error)
 			}
 		}
+		if len(finalUpdate.CompensationFailed) > 0 {
+			update.Message = fmt.Sprintf("%s (compensation failed at %s)", update.Message, strings.Join(finalUpdate.CompensationFailed, ", "))
+		}
 		// Include error details in the result field for queryability
 		if finalUpdate.ErrorDetails != nil {
 			errorInfo := map[string]interface{}{
@@ -567,6 +765,9 @@ func (h *Handler) HandleEnvelopeFinal(w http.ResponseWriter, r *http.Request) {
 			if len(finalUpdate.Actors) > 0 {
 				errorInfo["route"] = finalUpdate.Actors
 			}
+			if len(finalUpdate.CompensationFailed) > 0 {
+				errorInfo["compensation_failed"] = finalUpdate.CompensationFailed
+			}
 			update.Result = errorInfo
 		}
 	}
@@ -579,14 +780,11 @@ func (h *Handler) HandleEnvelopeFinal(w http.ResponseWriter, r *http.Request) {
 	// Update envelope store
 	if err := h.jobStore.Update(update); err != nil {
 		slog.Error("Failed to update envelope with final status", "id", envelopeID, "error", err)
-		http.Error(w, "Failed to update envelope", http.StatusInternalServerError)
-		return
+		return fmt.Errorf("failed to update envelope: %w", err)
 	}
 
 	slog.Info("Envelope final status updated successfully",
 		"id", envelopeID,
 		"status", envelopeStatus)
-
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	return nil
 }
@@ -2,17 +2,24 @@ package mcp
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/deliveryhero/asya/asya-gateway/internal/config"
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
 	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
+	"github.com/deliveryhero/asya/asya-gateway/internal/signing"
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
 )
 
@@ -282,6 +289,7 @@ func TestHandleEnvelopeProgress_SSENotification(t *testing.T) {
 		CurrentActorIdx: 0,
 		Status:          "processing",
 		Message:         "Processing actor 1",
+		HopCount:        3,
 	}
 
 	body, _ := json.Marshal(progressUpdate)
@@ -313,12 +321,107 @@ func TestHandleEnvelopeProgress_SSENotification(t *testing.T) {
 		if update.ProgressPercent == nil || *update.ProgressPercent < 24.5 || *update.ProgressPercent > 25.5 {
 			t.Errorf("Update progress = %v, want ~25.0", update.ProgressPercent)
 		}
+		if update.HopCount == nil || *update.HopCount != 3 {
+			t.Errorf("Update hop count = %v, want 3", update.HopCount)
+		}
 	case <-time.After(1 * time.Second):
 		t.Fatal("Did not receive SSE notification within timeout")
 	}
 }
 
 // TestHandleToolCall tests the REST API endpoint for calling MCP tools
+func TestHandleEnvelopeProgressBatch(t *testing.T) {
+	store := envelopestore.NewStore()
+	for _, id := range []string{"batch-1", "batch-2", "batch-done"} {
+		envelope := &types.Envelope{
+			ID:     id,
+			Route:  types.Route{Actors: []string{"parser", "processor"}},
+			Status: types.EnvelopeStatusPending,
+		}
+		if err := store.Create(envelope); err != nil {
+			t.Fatalf("Failed to create test envelope: %v", err)
+		}
+	}
+	if err := store.Update(types.EnvelopeUpdate{ID: "batch-done", Status: types.EnvelopeStatusSucceeded, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Failed to finish envelope: %v", err)
+	}
+
+	handler := NewHandler(store)
+
+	body, _ := json.Marshal(types.ProgressBatch{Updates: []types.ProgressUpdate{
+		{ID: "batch-1", Actors: []string{"parser", "processor"}, CurrentActorIdx: 0, Status: "completed"},
+		{ID: "batch-2", Actors: []string{"parser", "processor"}, CurrentActorIdx: 1, Status: "processing"},
+		{ID: "batch-missing", Actors: []string{"parser"}, Status: "received"},
+		{ID: "batch-done", Actors: []string{"parser", "processor"}, CurrentActorIdx: 0, Status: "received"},
+		{Status: "received"},
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/envelopes/progress/batch", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.HandleEnvelopeProgressBatch(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("HandleEnvelopeProgressBatch() status = %v, want %v", rr.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Status  string                      `json:"status"`
+		Results []types.ProgressBatchResult `json:"results"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(response.Results))
+	}
+
+	if response.Results[0].ProgressPercent != 50 || response.Results[0].Error != "" {
+		t.Errorf("batch-1 result = %+v, want 50%%", response.Results[0])
+	}
+	if response.Results[1].ProgressPercent != 75 || response.Results[1].Error != "" {
+		t.Errorf("batch-2 result = %+v, want 75%%", response.Results[1])
+	}
+	if response.Results[2].Error == "" {
+		t.Errorf("Expected error for missing envelope, got %+v", response.Results[2])
+	}
+	if response.Results[4].Error != "missing envelope id" {
+		t.Errorf("Expected missing id error, got %+v", response.Results[4])
+	}
+
+	// Late update must not reopen a finished envelope
+	done, err := store.Get("batch-done")
+	if err != nil {
+		t.Fatalf("Failed to get envelope: %v", err)
+	}
+	if done.Status != types.EnvelopeStatusSucceeded {
+		t.Errorf("Finished envelope status = %v, want %v", done.Status, types.EnvelopeStatusSucceeded)
+	}
+
+	stored, err := store.Get("batch-2")
+	if err != nil {
+		t.Fatalf("Failed to get envelope: %v", err)
+	}
+	if stored.CurrentActorName != "processor" || stored.Status != types.EnvelopeStatusRunning {
+		t.Errorf("batch-2 = %s/%s, want processor/running", stored.CurrentActorName, stored.Status)
+	}
+}
+
+func TestHandleEnvelopeProgressBatch_InvalidRequest(t *testing.T) {
+	handler := NewHandler(envelopestore.NewStore())
+
+	rr := httptest.NewRecorder()
+	handler.HandleEnvelopeProgressBatch(rr, httptest.NewRequest(http.MethodGet, "/envelopes/progress/batch", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %v, want %v", rr.Code, http.StatusMethodNotAllowed)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleEnvelopeProgressBatch(rr, httptest.NewRequest(http.MethodPost, "/envelopes/progress/batch", strings.NewReader("{")))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid body status = %v, want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
 func TestHandleToolCall(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -593,6 +696,93 @@ func TestHandleEnvelopeStatus(t *testing.T) {
 }
 
 // TestHandleEnvelopeActive tests the GET /envelopes/{id}/active endpoint
+func TestHandleEnvelopeStatus_Encrypted(t *testing.T) {
+	keyringPath := filepath.Join(t.TempDir(), "keyring.json")
+	keyring := fmt.Sprintf(`{"active":"k1","keys":{"k1":%q}}`, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, encryption.KeySize)))
+	if err := os.WriteFile(keyringPath, []byte(keyring), 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	enc, err := encryption.New(context.Background(), encryption.Config{Provider: encryption.ProviderKeyring, KeyringPath: keyringPath})
+	if err != nil {
+		t.Fatalf("Failed to create encryptor: %v", err)
+	}
+
+	cfg := &config.Config{Tools: []config.Tool{{
+		Name:       "echo",
+		Parameters: map[string]config.Parameter{"text": {Type: "string", Required: true}},
+		Route:      config.RouteSpec{Actors: []string{"echo"}},
+	}}}
+	store := envelopestore.NewStore()
+	server := NewServer(store, &MockQueueClient{}, cfg)
+	server.SetEncryptor(enc)
+	handler := NewHandler(store)
+	handler.SetServer(server)
+	handler.SetEncryptor(enc)
+
+	body, _ := json.Marshal(map[string]any{"name": "echo", "arguments": map[string]any{"text": "top secret"}})
+	w := httptest.NewRecorder()
+	handler.HandleToolCall(w, httptest.NewRequest(http.MethodPost, "/tools/call", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Tool call failed: %d %s", w.Code, w.Body.String())
+	}
+	var toolResult struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &toolResult); err != nil || len(toolResult.Content) == 0 {
+		t.Fatalf("Failed to parse tool result: %v", err)
+	}
+	var created map[string]any
+	if err := json.Unmarshal([]byte(toolResult.Content[0].Text), &created); err != nil {
+		t.Fatalf("Failed to parse tool response: %v", err)
+	}
+	envelopeID := created["envelope_id"].(string)
+
+	// Store keeps ciphertext
+	stored, err := store.Get(envelopeID)
+	if err != nil {
+		t.Fatalf("Envelope not stored: %v", err)
+	}
+	token, ok := stored.Payload.(string)
+	if !ok || strings.Contains(token, "top secret") {
+		t.Fatalf("Stored payload = %v, want encrypted token", stored.Payload)
+	}
+	if stored.Headers[encryption.KeyIDHeader] != "k1" {
+		t.Errorf("Headers = %v, want key ID k1", stored.Headers)
+	}
+
+	// Final result arrives encrypted from the end actor
+	sealedResult, _, err := enc.EncryptValue(context.Background(), map[string]any{"echo": "top secret"})
+	if err != nil {
+		t.Fatalf("EncryptValue failed: %v", err)
+	}
+	if err := store.Update(types.EnvelopeUpdate{ID: envelopeID, Status: types.EnvelopeStatusSucceeded, Result: sealedResult, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeStatus(w, httptest.NewRequest(http.MethodGet, "/envelopes/"+envelopeID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status request failed: %d %s", w.Code, w.Body.String())
+	}
+	var resp types.Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse status response: %v", err)
+	}
+	if payload, _ := resp.Payload.(map[string]any); payload["text"] != "top secret" {
+		t.Errorf("Payload = %v, want decrypted arguments", resp.Payload)
+	}
+	if result, _ := resp.Result.(map[string]any); result["echo"] != "top secret" {
+		t.Errorf("Result = %v, want decrypted result", resp.Result)
+	}
+
+	// Stored envelope is not modified by decryption
+	if stored, _ := store.Get(envelopeID); stored.Payload != token {
+		t.Errorf("Stored payload was modified: %v", stored.Payload)
+	}
+}
+
 func TestHandleEnvelopeActive(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -927,6 +1117,56 @@ func TestHandleEnvelopeFinal(t *testing.T) {
 	}
 }
 
+func TestHandleEnvelopeFinal_Hops(t *testing.T) {
+	store := envelopestore.NewStore()
+	handler := NewHandler(store)
+
+	env := &types.Envelope{
+		ID:     "test-final-hops",
+		Route:  types.Route{Actors: []string{"actor1", "actor2"}},
+		Status: types.EnvelopeStatusRunning,
+	}
+	if err := store.Create(env); err != nil {
+		t.Fatalf("Failed to create test envelope: %v", err)
+	}
+
+	finalUpdate := map[string]interface{}{
+		"id":     "test-final-hops",
+		"status": "failed",
+		"error":  "processing_error",
+		"hops": []map[string]interface{}{
+			{"actor": "actor1", "pod": "actor1-0", "received_at": "2025-11-24T10:00:00Z", "finished_at": "2025-11-24T10:00:01Z", "runtime_duration_ms": 800, "attempt": 1, "outcome": "succeeded"},
+			{"actor": "actor2", "pod": "actor2-0", "received_at": "2025-11-24T10:00:02Z", "finished_at": "2025-11-24T10:00:03Z", "runtime_duration_ms": 900, "attempt": 3, "outcome": "failed"},
+		},
+	}
+	body, _ := json.Marshal(finalUpdate)
+	req := httptest.NewRequest(http.MethodPost, "/envelopes/test-final-hops/final", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.HandleEnvelopeFinal(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("HandleEnvelopeFinal() status = %v, body = %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/envelopes/test-final-hops", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleEnvelopeStatus(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("HandleEnvelopeStatus() status = %v, body = %s", rr.Code, rr.Body.String())
+	}
+
+	var got types.Envelope
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode envelope: %v", err)
+	}
+	if len(got.Hops) != 2 {
+		t.Fatalf("Hops length = %d, want 2", len(got.Hops))
+	}
+	last := got.Hops[1]
+	if last.Actor != "actor2" || last.Attempt != 3 || last.Outcome != "failed" || last.RuntimeDurationMs != 900 {
+		t.Errorf("Last hop = %+v, want actor2 attempt 3 failed after 900ms", last)
+	}
+}
+
 func TestEnvelopePathRegex(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1291,3 +1531,315 @@ func TestHandleEnvelopeCreate_DuplicateID(t *testing.T) {
 		t.Error("Should not allow duplicate envelope ID")
 	}
 }
+
+// recordingQueueClient records sent envelopes
+type recordingQueueClient struct {
+	MockQueueClient
+	mu   sync.Mutex
+	sent []*types.Envelope
+}
+
+func (c *recordingQueueClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, envelope)
+	return nil
+}
+
+// newApprovalTestHandler creates an envelope parked at an approval step between review and publish
+func newApprovalTestHandler(t *testing.T) (*Handler, *envelopestore.Store, *recordingQueueClient) {
+	t.Helper()
+	store := envelopestore.NewStore()
+	if err := store.Create(&types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"review", "approval", "publish"}},
+	}); err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+	queueClient := &recordingQueueClient{}
+	handler := NewHandler(store)
+	handler.SetServer(NewServer(store, queueClient, nil))
+
+	request := types.ApprovalRequest{
+		Step:      "approval",
+		Route:     types.Route{Actors: []string{"review", "approval", "publish"}, Current: 2},
+		Headers:   map[string]interface{}{"trace_id": "abc"},
+		Payload:   json.RawMessage(`{"text":"draft"}`),
+		HopCount:  1,
+		Visited:   []string{"review"},
+		CallStack: json.RawMessage(`[{"caller":"order","route":{"actors":["order","ship"],"current":1}}]`),
+	}
+	body, _ := json.Marshal(request)
+	w := httptest.NewRecorder()
+	handler.HandleEnvelopeApproval(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approval", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Approval request failed: %d %s", w.Code, w.Body.String())
+	}
+	return handler, store, queueClient
+}
+
+func TestHandleEnvelopeApproval(t *testing.T) {
+	store := envelopestore.NewStore()
+	if err := store.Create(&types.Envelope{ID: "env-1", Route: types.Route{Actors: []string{"review", "approval", "publish"}}}); err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+	handler := NewHandler(store)
+	updates := store.Subscribe("env-1")
+	defer store.Unsubscribe("env-1", updates)
+
+	body := `{"step":"approval","route":{"actors":["review","approval","publish"],"current":2},"payload":{"text":"draft"}}`
+	w := httptest.NewRecorder()
+	handler.HandleEnvelopeApproval(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approval", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusAwaitingApproval {
+		t.Errorf("Status = %v, want awaiting_approval", envelope.Status)
+	}
+	if envelope.Approval == nil || envelope.Approval.ID != "env-1" || envelope.Approval.Route.Current != 2 {
+		t.Errorf("Approval = %+v, want parked envelope resuming at index 2", envelope.Approval)
+	}
+
+	// SSE subscribers see the waiting state with the parked envelope
+	select {
+	case update := <-updates:
+		if update.Status != types.EnvelopeStatusAwaitingApproval || update.Approval == nil {
+			t.Errorf("Update = %+v, want awaiting_approval with parked envelope", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No update sent to subscribers")
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeApproval(w, httptest.NewRequest(http.MethodGet, "/envelopes/env-1/approval", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", w.Code)
+	}
+}
+
+func TestHandleEnvelopeApprove(t *testing.T) {
+	handler, store, queueClient := newApprovalTestHandler(t)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", strings.NewReader(`{"payload":{"text":"edited"},"reason":"typo fixed"}`))
+	handler.HandleEnvelopeApprove(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	if len(queueClient.sent) != 1 {
+		t.Fatalf("Expected 1 sent envelope, got %d", len(queueClient.sent))
+	}
+	sent := queueClient.sent[0]
+	if sent.ID != "env-1" || sent.Route.Current != 2 || sent.HopCount != 1 || len(sent.Visited) != 1 {
+		t.Errorf("Sent envelope = %+v, want parked envelope resuming at publish", sent)
+	}
+	if string(sent.CallStack) != `[{"caller":"order","route":{"actors":["order","ship"],"current":1}}]` {
+		t.Errorf("CallStack = %s, want call stack passed through unchanged", sent.CallStack)
+	}
+	if payload, _ := sent.Payload.(map[string]any); payload["text"] != "edited" {
+		t.Errorf("Payload = %v, want edited payload", sent.Payload)
+	}
+	if sent.Headers["trace_id"] != "abc" {
+		t.Errorf("Headers = %v, want parked headers kept", sent.Headers)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusRunning {
+		t.Errorf("Status = %v, want running", envelope.Status)
+	}
+	if envelope.Approval != nil {
+		t.Error("Parked envelope kept after approval")
+	}
+	if envelope.Message != "Approved at step 'approval': typo fixed" {
+		t.Errorf("Message = %q", envelope.Message)
+	}
+
+	// A second approval does not send the envelope again
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Second approve status = %d, want 409", w.Code)
+	}
+	if len(queueClient.sent) != 1 {
+		t.Errorf("Expected 1 sent envelope after second approve, got %d", len(queueClient.sent))
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/missing/approve", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Unknown envelope status = %d, want 404", w.Code)
+	}
+}
+
+func TestHandleEnvelopeApprove_Concurrent(t *testing.T) {
+	handler, store, queueClient := newApprovalTestHandler(t)
+
+	const approvers = 10
+	codes := make(chan int, approvers)
+	var wg sync.WaitGroup
+	for i := 0; i < approvers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	approved := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			approved++
+		case http.StatusConflict:
+		default:
+			t.Errorf("Approve status = %d, want 200 or 409", code)
+		}
+	}
+	if approved != 1 {
+		t.Errorf("Expected exactly 1 successful approve, got %d", approved)
+	}
+	if len(queueClient.sent) != 1 {
+		t.Errorf("Expected 1 sent envelope, got %d", len(queueClient.sent))
+	}
+	if envelope, _ := store.Get("env-1"); envelope.Status != types.EnvelopeStatusRunning {
+		t.Errorf("Status = %v, want running", envelope.Status)
+	}
+}
+
+func TestHandleEnvelopeApprove_KeepsPayload(t *testing.T) {
+	handler, _, queueClient := newApprovalTestHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if payload, _ := queueClient.sent[0].Payload.(json.RawMessage); string(payload) != `{"text":"draft"}` {
+		t.Errorf("Payload = %v, want parked payload", queueClient.sent[0].Payload)
+	}
+}
+
+// newTestSigner creates a signer with a single test key
+func newTestSigner(t *testing.T) *signing.Signer {
+	t.Helper()
+	keyringPath := filepath.Join(t.TempDir(), "keyring.json")
+	keyring := fmt.Sprintf(`{"active":"k1","keys":{"k1":%q}}`, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{5}, encryption.KeySize)))
+	if err := os.WriteFile(keyringPath, []byte(keyring), 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	keys, err := encryption.NewKeyringProvider(keyringPath)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return signing.NewSigner(keys)
+}
+
+func TestHandleEnvelopeApproval_VerifiesSignature(t *testing.T) {
+	store := envelopestore.NewStore()
+	if err := store.Create(&types.Envelope{ID: "env-1", Route: types.Route{Actors: []string{"review", "approval", "publish"}}}); err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+	queueClient := &recordingQueueClient{}
+	handler := NewHandler(store)
+	handler.SetServer(NewServer(store, queueClient, nil))
+
+	signer := newTestSigner(t)
+	handler.SetSigner(signer)
+
+	request := types.ApprovalRequest{
+		ID:      "env-1",
+		Step:    "approval",
+		Route:   types.Route{Actors: []string{"review", "approval", "publish"}, Current: 2},
+		Payload: json.RawMessage(`{"text":"draft","amount":10}`),
+	}
+	park := func(request types.ApprovalRequest) int {
+		body, _ := json.Marshal(request)
+		w := httptest.NewRecorder()
+		handler.HandleEnvelopeApproval(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approval", bytes.NewReader(body)))
+		return w.Code
+	}
+
+	// Unsigned envelopes are not parked
+	if code := park(request); code != http.StatusForbidden {
+		t.Errorf("Unsigned approval request status = %d, want 403", code)
+	}
+	if envelope, _ := store.Get("env-1"); envelope.Status != types.EnvelopeStatusPending {
+		t.Errorf("Status after unsigned request = %v, want pending", envelope.Status)
+	}
+
+	// Signed as the sidecar does, payload keys in the order it sent them
+	signed := types.Envelope{ID: request.ID, Route: request.Route, Payload: request.Payload}
+	if err := signer.Sign(context.Background(), &signed); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	request.Headers = signed.Headers
+
+	tampered := request
+	tampered.Route = types.Route{Actors: []string{"review", "approval", "exfiltrate"}, Current: 2}
+	if code := park(tampered); code != http.StatusForbidden {
+		t.Errorf("Tampered approval request status = %d, want 403", code)
+	}
+
+	if code := park(request); code != http.StatusOK {
+		t.Fatalf("Signed approval request status = %d, want 200", code)
+	}
+
+	// A parked envelope changed in the store is not resumed
+	envelope, _ := store.Get("env-1")
+	envelope.Approval.Route.Actors = []string{"review", "approval", "exfiltrate"}
+	w := httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Approve of tampered parked envelope status = %d, want 403", w.Code)
+	}
+	if len(queueClient.sent) != 0 {
+		t.Errorf("Tampered parked envelope was sent to %d actors", len(queueClient.sent))
+	}
+	if envelope, _ := store.Get("env-1"); envelope.Status != types.EnvelopeStatusAwaitingApproval {
+		t.Errorf("Status after refused approve = %v, want awaiting_approval", envelope.Status)
+	}
+
+	envelope.Approval.Route.Actors = []string{"review", "approval", "publish"}
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Approve status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if len(queueClient.sent) != 1 {
+		t.Errorf("Expected 1 sent envelope, got %d", len(queueClient.sent))
+	}
+}
+
+func TestHandleEnvelopeReject(t *testing.T) {
+	handler, store, queueClient := newApprovalTestHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.HandleEnvelopeReject(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/reject", strings.NewReader(`{"reason":"off-topic"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	if len(queueClient.sent) != 0 {
+		t.Errorf("Rejected envelope was sent to %d actors", len(queueClient.sent))
+	}
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusFailed {
+		t.Errorf("Status = %v, want failed", envelope.Status)
+	}
+	if envelope.Error != "Rejected at step 'approval': off-topic" {
+		t.Errorf("Error = %q", envelope.Error)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleEnvelopeApprove(w, httptest.NewRequest(http.MethodPost, "/envelopes/env-1/approve", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Approve after reject status = %d, want 409", w.Code)
+	}
+}
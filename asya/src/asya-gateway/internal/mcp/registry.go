@@ -12,6 +12,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/deliveryhero/asya/asya-gateway/internal/config"
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
 	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
 	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
@@ -27,6 +28,7 @@ type Registry struct {
 	queueClient queue.Client
 	mcpServer   *server.MCPServer
 	handlers    map[string]ToolHandler // Map of tool name -> handler
+	encryptor   *encryption.Encryptor  // Encrypts payloads before they are stored (optional)
 }
 
 // NewRegistry creates a new tool registry
@@ -180,6 +182,17 @@ func (r *Registry) createToolHandler(toolDef config.Tool) func(context.Context,
 			},
 			Payload:    arguments,
 			TimeoutSec: int(opts.Timeout.Seconds()),
+			MaxHops:    opts.MaxHops,
+		}
+
+		// Per-actor runtime timeouts from the tool's route, honored by sidecars
+		if timeouts := toolDef.Route.TimeoutsMetadata(); timeouts != nil {
+			envelope.Route.Metadata[config.TimeoutsMetadataKey] = timeouts
+		}
+
+		// Compensating actors from the tool's route, run by sidecars if the envelope fails
+		if compensations := toolDef.Route.CompensationsMetadata(); compensations != nil {
+			envelope.Route.Metadata[config.CompensationsMetadataKey] = compensations
 		}
 
 		// Set deadline if timeout is configured
@@ -187,6 +200,11 @@ func (r *Registry) createToolHandler(toolDef config.Tool) func(context.Context,
 			envelope.Deadline = time.Now().Add(opts.Timeout)
 		}
 
+		if err := sealEnvelope(ctx, r.encryptor, envelope); err != nil {
+			log.Printf("Failed to encrypt envelope payload: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to encrypt payload: %v", err)), nil
+		}
+
 		// Store envelope
 		if err := r.jobStore.Create(envelope); err != nil {
 			log.Printf("Failed to create envelope: %v", err)
@@ -245,6 +263,26 @@ func (r *Registry) createToolHandler(toolDef config.Tool) func(context.Context,
 	}
 }
 
+// sealEnvelope replaces the envelope payload with an encrypted token and records the key ID header,
+// so the envelope store and queues never see the plaintext. No-op when encryption is disabled.
+func sealEnvelope(ctx context.Context, encryptor *encryption.Encryptor, envelope *types.Envelope) error {
+	if encryptor == nil {
+		return nil
+	}
+
+	token, keyID, err := encryptor.EncryptValue(ctx, envelope.Payload)
+	if err != nil {
+		return err
+	}
+
+	if envelope.Headers == nil {
+		envelope.Headers = make(map[string]interface{})
+	}
+	envelope.Headers[encryption.KeyIDHeader] = keyID
+	envelope.Payload = token
+	return nil
+}
+
 // GetToolOptions returns the options for a specific tool by name
 func (r *Registry) GetToolOptions(toolName string) (*config.ToolOptions, error) {
 	for _, tool := range r.config.Tools {
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -35,6 +36,10 @@ func (m *MockQueueClientWithError) Ack(ctx context.Context, msg queue.QueueMessa
 	return nil
 }
 
+func (m *MockQueueClientWithError) Nack(ctx context.Context, msg queue.QueueMessage) error {
+	return nil
+}
+
 func (m *MockQueueClientWithError) Close() error {
 	return nil
 }
@@ -71,6 +76,22 @@ func (m *MockJobStore) Update(update types.EnvelopeUpdate) error {
 	return nil
 }
 
+func (m *MockJobStore) UpdateIf(from types.EnvelopeStatus, update types.EnvelopeUpdate) (bool, error) {
+	if m.updateErr != nil {
+		return false, m.updateErr
+	}
+	env, ok := m.envelopes[update.ID]
+	if !ok {
+		return false, fmt.Errorf("envelope not found")
+	}
+	if env.Status != from {
+		return false, nil
+	}
+	env.Status = update.Status
+	env.Error = update.Error
+	return true, nil
+}
+
 func (m *MockJobStore) Get(id string) (*types.Envelope, error) {
 	if env, ok := m.envelopes[id]; ok {
 		return env, nil
@@ -758,6 +779,76 @@ func TestEnvelopeCreation(t *testing.T) {
 	}
 }
 
+// TestEnvelopeCreation_RouteTimeouts tests that per-actor route timeouts are carried in route metadata
+func TestEnvelopeCreation_RouteTimeouts(t *testing.T) {
+	toolDef := config.Tool{
+		Name: "timed_tool",
+		Route: config.RouteSpec{
+			Actors:   []string{"ocr", "llm"},
+			Timeouts: map[string]time.Duration{"ocr": 30 * time.Second, "llm": 10 * time.Minute},
+		},
+	}
+	cfg := &config.Config{Tools: []config.Tool{toolDef}}
+
+	jobStore := NewMockJobStore()
+	registry := NewRegistry(cfg, jobStore, &MockQueueClient{})
+
+	handler := registry.createToolHandler(toolDef)
+	if _, err := handler(context.Background(), createCallToolRequest(map[string]interface{}{})); err != nil {
+		t.Fatalf("Handler error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if len(jobStore.envelopes) != 1 {
+		t.Fatalf("Expected 1 envelope, got %d", len(jobStore.envelopes))
+	}
+	for _, env := range jobStore.envelopes {
+		want := map[string]interface{}{"ocr": "30s", "llm": "10m0s"}
+		if got := env.Route.Metadata[config.TimeoutsMetadataKey]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Route metadata timeouts = %v, want %v", got, want)
+		}
+		if env.Route.Metadata["job_id"] != env.ID {
+			t.Errorf("Expected job_id to be kept in route metadata, got %v", env.Route.Metadata["job_id"])
+		}
+	}
+}
+
+// TestEnvelopeCreation_RouteCompensations tests that compensating actors are carried in route metadata
+func TestEnvelopeCreation_RouteCompensations(t *testing.T) {
+	toolDef := config.Tool{
+		Name: "saga_tool",
+		Route: config.RouteSpec{
+			Actors:        []string{"reserve-stock", "charge-card", "send-email"},
+			Compensations: map[string]string{"reserve-stock": "release-stock", "charge-card": "refund-card"},
+		},
+	}
+	cfg := &config.Config{Tools: []config.Tool{toolDef}}
+
+	jobStore := NewMockJobStore()
+	registry := NewRegistry(cfg, jobStore, &MockQueueClient{})
+
+	handler := registry.createToolHandler(toolDef)
+	if _, err := handler(context.Background(), createCallToolRequest(map[string]interface{}{})); err != nil {
+		t.Fatalf("Handler error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if len(jobStore.envelopes) != 1 {
+		t.Fatalf("Expected 1 envelope, got %d", len(jobStore.envelopes))
+	}
+	for _, env := range jobStore.envelopes {
+		want := map[string]interface{}{"reserve-stock": "release-stock", "charge-card": "refund-card"}
+		if got := env.Route.Metadata[config.CompensationsMetadataKey]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Route metadata compensations = %v, want %v", got, want)
+		}
+		if _, ok := env.Route.Metadata[config.TimeoutsMetadataKey]; ok {
+			t.Error("Expected no timeouts in route metadata")
+		}
+	}
+}
+
 // TestJobStoreFailure tests handling of job store failures
 func TestJobStoreFailure(t *testing.T) {
 	toolDef := config.Tool{
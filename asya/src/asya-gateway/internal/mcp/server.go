@@ -12,6 +12,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/deliveryhero/asya/asya-gateway/internal/config"
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
 	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
 	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
@@ -23,6 +24,7 @@ type Server struct {
 	jobStore    envelopestore.EnvelopeStore
 	queueClient queue.Client
 	registry    *Registry
+	encryptor   *encryption.Encryptor
 }
 
 // NewServer creates a new MCP server using mark3labs/mcp-go
@@ -59,6 +61,12 @@ func NewServer(jobStore envelopestore.EnvelopeStore, queueClient queue.Client, c
 	return s
 }
 
+// SetEncryptor enables payload encryption for envelopes created by tool calls
+func (s *Server) SetEncryptor(encryptor *encryption.Encryptor) {
+	s.encryptor = encryptor
+	s.registry.encryptor = encryptor
+}
+
 func (s *Server) registerToolsWithRegistry() {
 	// Define the processImageWorkflow tool with clean fluent API
 	tool := mcp.NewTool(
@@ -123,6 +131,11 @@ func (s *Server) handleProcessImageWorkflow(ctx context.Context, request mcp.Cal
 		TimeoutSec: int(timeout),
 	}
 
+	if err := sealEnvelope(ctx, s.encryptor, envelope); err != nil {
+		log.Printf("Failed to encrypt envelope payload: %v", err)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encrypt payload: %v", err)), nil
+	}
+
 	// Store envelope
 	if err := s.jobStore.Create(envelope); err != nil {
 		log.Printf("Failed to create envelope: %v", err)
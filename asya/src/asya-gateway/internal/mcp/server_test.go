@@ -25,6 +25,10 @@ func (m *MockQueueClient) Ack(ctx context.Context, msg queue.QueueMessage) error
 	return nil
 }
 
+func (m *MockQueueClient) Nack(ctx context.Context, msg queue.QueueMessage) error {
+	return nil
+}
+
 func (m *MockQueueClient) Close() error {
 	return nil
 }
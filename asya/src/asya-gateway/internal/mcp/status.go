@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+// ApplyStatusEvent applies an event consumed from the status queue, the message bus equivalent of the
+// progress, final, create and approval endpoints. Queues redeliver and may reorder events, so stale ones are
+// skipped rather than applied:
+// - anything for an envelope that already succeeded or failed
+// - progress that would move the envelope back to an earlier actor or state (compensation progress excepted)
+// - create for an envelope that already exists
+//
+// Events that can never be applied fail with types.ErrInvalidStatusEvent, and events for envelopes that
+// do not exist (yet) with types.ErrStatusEventTooEarly; other errors (e.g. from the envelope store) are
+// worth retrying.
+func (h *Handler) ApplyStatusEvent(event types.StatusEvent) error {
+	err := h.applyStatusEvent(event)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errInvalidSignature):
+		return fmt.Errorf("%w: %w", types.ErrInvalidStatusEvent, err)
+	case errors.Is(err, envelopestore.ErrNotFound):
+		return fmt.Errorf("%w: %w", types.ErrStatusEventTooEarly, err)
+	}
+	return err
+}
+
+// applyStatusEvent applies an event by type
+func (h *Handler) applyStatusEvent(event types.StatusEvent) error {
+	if event.ID == "" {
+		return fmt.Errorf("%w: missing envelope id", types.ErrInvalidStatusEvent)
+	}
+
+	switch event.Type {
+	case types.StatusEventProgress:
+		if event.Progress == nil {
+			return fmt.Errorf("%w: progress event %s has no progress", types.ErrInvalidStatusEvent, event.ID)
+		}
+		progress := *event.Progress
+		progress.ID = event.ID
+		return h.applyProgressEvent(progress)
+
+	case types.StatusEventFinal:
+		if event.Final == nil {
+			return fmt.Errorf("%w: final event %s has no final status", types.ErrInvalidStatusEvent, event.ID)
+		}
+		envelopeStatus, ok := finalEnvelopeStatus(event.Final.Status)
+		if !ok {
+			return fmt.Errorf("%w: invalid final status %q for envelope %s", types.ErrInvalidStatusEvent, event.Final.Status, event.ID)
+		}
+		envelope, err := h.jobStore.Get(event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get envelope: %w", err)
+		}
+		if isFinalStatus(envelope.Status) {
+			slog.Debug("Ignoring duplicate final status", "id", event.ID, "status", envelope.Status)
+			return nil
+		}
+		return h.applyFinal(event.ID, envelopeStatus, *event.Final)
+
+	case types.StatusEventCreate:
+		if event.Create == nil {
+			return fmt.Errorf("%w: create event %s has no envelope", types.ErrInvalidStatusEvent, event.ID)
+		}
+		if _, err := h.jobStore.Get(event.ID); err == nil {
+			slog.Debug("Ignoring create for existing envelope", "id", event.ID)
+			return nil
+		}
+		createReq := *event.Create
+		createReq.ID = event.ID
+		return h.createFanoutEnvelope(createReq)
+
+	case types.StatusEventApproval:
+		if event.Approval == nil {
+			return fmt.Errorf("%w: approval event %s has no envelope", types.ErrInvalidStatusEvent, event.ID)
+		}
+		request := *event.Approval
+		request.ID = event.ID
+		return h.applyApprovalRequest(context.Background(), request)
+
+	default:
+		return fmt.Errorf("%w: unknown status event type %q", types.ErrInvalidStatusEvent, event.Type)
+	}
+}
+
+// applyProgressEvent stores a progress update unless a later one was already applied
+func (h *Handler) applyProgressEvent(progress types.ProgressUpdate) error {
+	envelope, err := h.jobStore.Get(progress.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get envelope: %w", err)
+	}
+
+	totalActors := max(len(envelope.Route.Actors), len(progress.Actors))
+	// A retry restarts the actor, so its progress may repeat that of the failed attempt
+	if totalActors > 0 && !progress.Compensating && progress.Attempt <= envelope.Attempt {
+		newProgress := (float64(progress.CurrentActorIdx)*100 + progressWeight(progress.Status)) / float64(totalActors)
+		if newProgress < envelope.ProgressPercent {
+			slog.Debug("Ignoring out-of-order progress event",
+				"id", progress.ID,
+				"current", envelope.ProgressPercent,
+				"new", newProgress,
+				"actor_idx", progress.CurrentActorIdx,
+				"status", progress.Status)
+			return nil
+		}
+	}
+
+	_, err = h.applyProgress(progress)
+	return err
+}
@@ -0,0 +1,306 @@
+package mcp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/envelopestore"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+func newStatusTestHandler(t *testing.T, ids ...string) (*Handler, *envelopestore.Store) {
+	t.Helper()
+	store := envelopestore.NewStore()
+	for _, id := range ids {
+		envelope := &types.Envelope{
+			ID:     id,
+			Route:  types.Route{Actors: []string{"parser", "processor"}},
+			Status: types.EnvelopeStatusPending,
+		}
+		if err := store.Create(envelope); err != nil {
+			t.Fatalf("Failed to create test envelope: %v", err)
+		}
+	}
+	return NewHandler(store), store
+}
+
+func progressEvent(id string, idx int, status string) types.StatusEvent {
+	return types.StatusEvent{
+		Type:      types.StatusEventProgress,
+		ID:        id,
+		Timestamp: time.Now(),
+		Progress:  &types.ProgressUpdate{Actors: []string{"parser", "processor"}, CurrentActorIdx: idx, Status: status},
+	}
+}
+
+func TestApplyStatusEvent_Progress(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+
+	if err := handler.ApplyStatusEvent(progressEvent("env-1", 1, "processing")); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	// Reordered event from the previous actor is skipped
+	if err := handler.ApplyStatusEvent(progressEvent("env-1", 0, "completed")); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.ProgressPercent != 75 {
+		t.Errorf("ProgressPercent = %v, want 75", envelope.ProgressPercent)
+	}
+	if envelope.CurrentActorIdx != 1 {
+		t.Errorf("CurrentActorIdx = %d, want 1", envelope.CurrentActorIdx)
+	}
+	updates, _ := store.GetUpdates("env-1", nil)
+	if len(updates) != 1 {
+		t.Errorf("Expected out-of-order event not to be recorded, got %d updates", len(updates))
+	}
+}
+
+func TestApplyStatusEvent_Final(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+
+	final := types.StatusEvent{
+		Type:  types.StatusEventFinal,
+		ID:    "env-1",
+		Final: &types.FinalStatusUpdate{Status: "succeeded", Result: map[string]any{"value": 42}},
+	}
+	if err := handler.ApplyStatusEvent(final); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	// Progress delivered after the final status never reopens the envelope
+	if err := handler.ApplyStatusEvent(progressEvent("env-1", 1, "completed")); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	// Redelivered final status is a no-op
+	failed := types.StatusEvent{Type: types.StatusEventFinal, ID: "env-1", Final: &types.FinalStatusUpdate{Status: "failed", Error: "late"}}
+	if err := handler.ApplyStatusEvent(failed); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusSucceeded {
+		t.Errorf("Status = %s, want succeeded", envelope.Status)
+	}
+	if envelope.Error != "" {
+		t.Errorf("Error = %q, want empty", envelope.Error)
+	}
+}
+
+func TestApplyStatusEvent_Create(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "parent")
+
+	create := types.StatusEvent{
+		Type:   types.StatusEventCreate,
+		ID:     "parent-1",
+		Create: &types.EnvelopeCreateRequest{ParentID: "parent", Actors: []string{"parser", "processor"}, Current: 1},
+	}
+	if err := handler.ApplyStatusEvent(create); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	// Redelivered create is a no-op
+	if err := handler.ApplyStatusEvent(create); err != nil {
+		t.Fatalf("Duplicate create failed: %v", err)
+	}
+
+	envelope, err := store.Get("parent-1")
+	if err != nil {
+		t.Fatalf("Fanout envelope not created: %v", err)
+	}
+	if envelope.ParentID == nil || *envelope.ParentID != "parent" {
+		t.Errorf("ParentID = %v, want parent", envelope.ParentID)
+	}
+}
+
+func TestApplyStatusEvent_Invalid(t *testing.T) {
+	handler, _ := newStatusTestHandler(t, "env-1")
+
+	tests := []struct {
+		name  string
+		event types.StatusEvent
+	}{
+		{"missing id", types.StatusEvent{Type: types.StatusEventProgress, Progress: &types.ProgressUpdate{}}},
+		{"unknown type", types.StatusEvent{Type: "bogus", ID: "env-1"}},
+		{"missing payload", types.StatusEvent{Type: types.StatusEventFinal, ID: "env-1"}},
+		{"invalid final status", types.StatusEvent{Type: types.StatusEventFinal, ID: "env-1", Final: &types.FinalStatusUpdate{Status: "done"}}},
+		{"unknown envelope", progressEvent("env-missing", 0, "received")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := handler.ApplyStatusEvent(tt.event); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestApplyStatusEvent_Approval(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+
+	event := types.StatusEvent{
+		Type:      types.StatusEventApproval,
+		ID:        "env-1",
+		Timestamp: time.Now(),
+		Approval:  &types.ApprovalRequest{Step: "approval", Route: types.Route{Actors: []string{"parser", "approval", "processor"}, Current: 2}},
+	}
+	if err := handler.ApplyStatusEvent(event); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusAwaitingApproval {
+		t.Errorf("Status = %v, want awaiting_approval", envelope.Status)
+	}
+	if envelope.Approval == nil || envelope.Approval.ID != "env-1" {
+		t.Errorf("Approval = %+v, want parked envelope", envelope.Approval)
+	}
+
+	if err := handler.ApplyStatusEvent(types.StatusEvent{Type: types.StatusEventApproval, ID: "env-1"}); err == nil {
+		t.Error("Expected error for approval event without envelope")
+	}
+}
+
+func TestApplyStatusEvent_InvalidEvents(t *testing.T) {
+	handler, _ := newStatusTestHandler(t, "env-1")
+
+	// Malformed events are never retried
+	events := []types.StatusEvent{
+		{Type: types.StatusEventFinal, ID: "env-1"},
+		{Type: "bogus", ID: "env-1"},
+	}
+	for _, event := range events {
+		if err := handler.ApplyStatusEvent(event); !errors.Is(err, types.ErrInvalidStatusEvent) {
+			t.Errorf("ApplyStatusEvent(%s %s) error = %v, want ErrInvalidStatusEvent", event.Type, event.ID, err)
+		}
+	}
+}
+
+func TestApplyStatusEvent_UnknownEnvelopeIsTooEarly(t *testing.T) {
+	handler, _ := newStatusTestHandler(t, "env-1")
+
+	// Events may overtake the one creating their envelope, so they are retried
+	events := []types.StatusEvent{
+		progressEvent("missing", 0, "received"),
+		{Type: types.StatusEventFinal, ID: "missing", Final: &types.FinalStatusUpdate{Status: "succeeded"}},
+	}
+	for _, event := range events {
+		err := handler.ApplyStatusEvent(event)
+		if !errors.Is(err, types.ErrStatusEventTooEarly) || errors.Is(err, types.ErrInvalidStatusEvent) {
+			t.Errorf("ApplyStatusEvent(%s %s) error = %v, want ErrStatusEventTooEarly", event.Type, event.ID, err)
+		}
+	}
+}
+
+func TestApplyStatusEvent_ApprovalVerifiesSignature(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+	handler.SetSigner(newTestSigner(t))
+
+	event := types.StatusEvent{
+		Type:      types.StatusEventApproval,
+		ID:        "env-1",
+		Timestamp: time.Now(),
+		Approval:  &types.ApprovalRequest{Step: "approval", Route: types.Route{Actors: []string{"parser", "approval", "processor"}, Current: 2}},
+	}
+	if err := handler.ApplyStatusEvent(event); !errors.Is(err, errInvalidSignature) || !errors.Is(err, types.ErrInvalidStatusEvent) {
+		t.Errorf("ApplyStatusEvent() error = %v, want invalid signature", err)
+	}
+	if envelope, _ := store.Get("env-1"); envelope.Status != types.EnvelopeStatusPending || envelope.Approval != nil {
+		t.Errorf("Status = %v, approval = %+v, want unsigned envelope not parked", envelope.Status, envelope.Approval)
+	}
+}
+
+func TestApplyStatusEvent_Compensation(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+
+	if err := handler.ApplyStatusEvent(progressEvent("env-1", 1, "processing")); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	// Compensation progress indexes the compensation route, so it is not checked for order
+	compensation := types.StatusEvent{
+		Type:     types.StatusEventProgress,
+		ID:       "env-1",
+		Progress: &types.ProgressUpdate{Actors: []string{"unparse"}, CurrentActorIdx: 0, Status: "processing", Compensating: true},
+	}
+	if err := handler.ApplyStatusEvent(compensation); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusCompensating {
+		t.Errorf("Status = %v, want compensating", envelope.Status)
+	}
+	if envelope.Message != "Compensating at actor 'unparse'" {
+		t.Errorf("Message = %q, want compensating message", envelope.Message)
+	}
+	if envelope.ProgressPercent != 75 || envelope.CurrentActorIdx != 1 || len(envelope.Route.Actors) != 2 {
+		t.Errorf("Progress = %v at actor %d of %v, want the route at failure kept", envelope.ProgressPercent, envelope.CurrentActorIdx, envelope.Route.Actors)
+	}
+
+	// A late update from the failed actor does not move the envelope back to running
+	if err := handler.ApplyStatusEvent(progressEvent("env-1", 1, "completed")); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	envelope, _ = store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusCompensating {
+		t.Errorf("Status after late progress = %v, want compensating", envelope.Status)
+	}
+
+	final := types.StatusEvent{
+		Type:  types.StatusEventFinal,
+		ID:    "env-1",
+		Final: &types.FinalStatusUpdate{Status: "failed", Error: "boom", CurrentActorName: "processor", CompensationFailed: []string{"unparse"}},
+	}
+	if err := handler.ApplyStatusEvent(final); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	envelope, _ = store.Get("env-1")
+	if envelope.Status != types.EnvelopeStatusFailed {
+		t.Errorf("Status = %v, want failed", envelope.Status)
+	}
+	if want := "Envelope failed at actor 'processor': boom (compensation failed at unparse)"; envelope.Message != want {
+		t.Errorf("Message = %q, want %q", envelope.Message, want)
+	}
+}
+
+func TestApplyStatusEvent_Retry(t *testing.T) {
+	handler, store := newStatusTestHandler(t, "env-1")
+
+	attemptEvent := func(status string, attempt int) types.StatusEvent {
+		event := progressEvent("env-1", 1, status)
+		event.Progress.Attempt = attempt
+		return event
+	}
+
+	if err := handler.ApplyStatusEvent(attemptEvent("processing", 1)); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	// A retried actor reports from the start again
+	if err := handler.ApplyStatusEvent(attemptEvent("received", 2)); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+
+	envelope, _ := store.Get("env-1")
+	if envelope.Attempt != 2 {
+		t.Errorf("Attempt = %d, want 2", envelope.Attempt)
+	}
+	if envelope.ProgressPercent != 75 {
+		t.Errorf("ProgressPercent = %v, want 75 kept from the failed attempt", envelope.ProgressPercent)
+	}
+
+	// Reordered event from the failed attempt is skipped
+	if err := handler.ApplyStatusEvent(attemptEvent("received", 1)); err != nil {
+		t.Fatalf("ApplyStatusEvent failed: %v", err)
+	}
+	envelope, _ = store.Get("env-1")
+	if envelope.Attempt != 2 {
+		t.Errorf("Attempt after reordered event = %d, want 2", envelope.Attempt)
+	}
+	updates, _ := store.GetUpdates("env-1", nil)
+	if len(updates) != 2 {
+		t.Errorf("Expected out-of-order event not to be recorded, got %d updates", len(updates))
+	}
+}
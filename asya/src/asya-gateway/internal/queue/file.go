@@ -0,0 +1,376 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
+)
+
+// File Queue Layout:
+//
+// The file transport shares its on-disk format with the sidecar (asya-sidecar/internal/transport/file.go).
+// Each queue "asya-{namespace}-{actor}" is a directory under the transport root:
+//
+//	{root}/{queue}/tmp/         - messages being written
+//	{root}/{queue}/ready/       - messages waiting for a consumer ("{unix_nanos}-{random}.{deliveries}.msg")
+//	{root}/{queue}/processing/  - claimed messages and their .lease files
+//	{root}/{queue}/dead/        - messages that exceeded the maximum delivery count
+//
+// Messages are published by renaming from tmp/ into ready/ and claimed by renaming from
+// ready/ into processing/, so producers and consumers never observe partial files.
+
+const (
+	fileDirTmp        = "tmp"
+	fileDirReady      = "ready"
+	fileDirProcessing = "processing"
+	fileDirDead       = "dead"
+
+	fileMessageExt = ".msg"
+	fileLeaseExt   = ".lease"
+)
+
+// FileClient implements the Client interface on top of a shared directory tree
+type FileClient struct {
+	root              string
+	namespace         string
+	naming            naming.Scheme
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	maxDeliveries     int
+}
+
+// FileConfig holds file transport configuration
+type FileConfig struct {
+	Root              string
+	Namespace         string
+	Naming            naming.Scheme // Queue naming scheme shared with sidecars (zero value is the default)
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+	MaxDeliveries     int
+}
+
+// fileLease is the content of a lease file
+type fileLease struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFileClient creates a new file queue client
+func NewFileClient(cfg FileConfig) (*FileClient, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("file transport root directory is required")
+	}
+	if err := os.MkdirAll(cfg.Root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create file transport root %s: %w", cfg.Root, err)
+	}
+
+	// Set defaults
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = 5 * time.Minute
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 200 * time.Millisecond
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = 3
+	}
+
+	return &FileClient{
+		root:              cfg.Root,
+		namespace:         cfg.Namespace,
+		naming:            cfg.Naming,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+		maxDeliveries:     maxDeliveries,
+	}, nil
+}
+
+// fileMessage wraps a claimed file for the QueueMessage interface
+type fileMessage struct {
+	body        []byte
+	deliveryTag uint64
+	path        string
+}
+
+func (m *fileMessage) Body() []byte {
+	return m.body
+}
+
+func (m *fileMessage) DeliveryTag() uint64 {
+	return m.deliveryTag
+}
+
+// queueDir returns a queue subdirectory, rejecting names that escape the root
+func (c *FileClient) queueDir(queueName, sub string) (string, error) {
+	if queueName == "" || strings.ContainsAny(queueName, `/\`) || queueName == "." || queueName == ".." {
+		return "", fmt.Errorf("invalid queue name %q", queueName)
+	}
+	return filepath.Join(c.root, queueName, sub), nil
+}
+
+// ensureQueue creates the queue directory layout if it does not exist
+func (c *FileClient) ensureQueue(queueName string) error {
+	for _, sub := range []string{fileDirTmp, fileDirReady, fileDirProcessing, fileDirDead} {
+		dir, err := c.queueDir(queueName, sub)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create queue directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// SendEnvelope writes an envelope to the current actor's queue directory
+func (c *FileClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	if len(envelope.Route.Actors) == 0 {
+		return fmt.Errorf("route has no actors")
+	}
+	if envelope.Route.Current < 0 || envelope.Route.Current >= len(envelope.Route.Actors) {
+		return fmt.Errorf("invalid route.current=%d for actors length %d", envelope.Route.Current, len(envelope.Route.Actors))
+	}
+
+	// Create actor envelope
+	msg := newActorEnvelope(envelope)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	// Convert actor reference to its queue name (qualified references name the namespace)
+	actorName := envelope.Route.Actors[envelope.Route.Current]
+	queueName := actorQueueName(c.naming, c.namespace, actorName)
+
+	if err := c.publish(queueName, body); err != nil {
+		slog.Error("Failed to write envelope to file queue", "envelopeID", envelope.ID, "queue", queueName, "error", err)
+		return err
+	}
+
+	slog.Info("Successfully sent envelope to file queue", "envelopeID", envelope.ID, "queue", queueName)
+	return nil
+}
+
+// publish writes body to tmp/ and renames it into ready/
+func (c *FileClient) publish(queueName string, body []byte) error {
+	if err := c.ensureQueue(queueName); err != nil {
+		return err
+	}
+	tmpDir, _ := c.queueDir(queueName, fileDirTmp)
+	readyDir, _ := c.queueDir(queueName, fileDirReady)
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to generate message name: %w", err)
+	}
+	name := fmt.Sprintf("%020d-%s.0%s", time.Now().UnixNano(), hex.EncodeToString(suffix), fileMessageExt)
+	tmpPath := filepath.Join(tmpDir, name)
+
+	if err := os.WriteFile(tmpPath, body, 0o644); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(readyDir, name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to publish message to queue %s: %w", queueName, err)
+	}
+	return nil
+}
+
+// Receive claims the oldest message from the specified queue, polling until one arrives
+func (c *FileClient) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	if err := c.ensureQueue(queueName); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.reclaimExpired(queueName)
+
+		msg, err := c.claimNext(queueName)
+		if err != nil {
+			return nil, err
+		}
+		if msg != nil {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimNext renames the first claimable ready message into processing/; nil if the queue is empty
+func (c *FileClient) claimNext(queueName string) (*fileMessage, error) {
+	readyDir, _ := c.queueDir(queueName, fileDirReady)
+	processingDir, _ := c.queueDir(queueName, fileDirProcessing)
+	deadDir, _ := c.queueDir(queueName, fileDirDead)
+
+	names, err := listMessageFiles(readyDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue %s: %w", queueName, err)
+	}
+
+	for _, name := range names {
+		base, deliveries := parseMessageName(name)
+		deliveries++
+		claimedPath := filepath.Join(processingDir, fmt.Sprintf("%s.%d%s", base, deliveries, fileMessageExt))
+
+		now := time.Now()
+		_ = os.Chtimes(filepath.Join(readyDir, name), now, now)
+		if err := os.Rename(filepath.Join(readyDir, name), claimedPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // Claimed by another consumer
+			}
+			return nil, fmt.Errorf("failed to claim message %s: %w", name, err)
+		}
+
+		if deliveries > c.maxDeliveries {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter directory", "queue", queueName, "message", base)
+			_ = os.Rename(claimedPath, filepath.Join(deadDir, name))
+			continue
+		}
+
+		lease, err := json.Marshal(fileLease{ExpiresAt: now.Add(c.visibilityTimeout)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal lease: %w", err)
+		}
+		if err := os.WriteFile(claimedPath+fileLeaseExt, lease, 0o644); err != nil {
+			_ = os.Rename(claimedPath, filepath.Join(readyDir, name))
+			return nil, fmt.Errorf("failed to write lease: %w", err)
+		}
+
+		body, err := os.ReadFile(claimedPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message %s: %w", base, err)
+		}
+
+		return &fileMessage{
+			body:        body,
+			deliveryTag: uint64(deliveries), // #nosec G115 - delivery count is positive
+			path:        claimedPath,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// reclaimExpired returns claimed messages with expired or missing leases to ready/
+func (c *FileClient) reclaimExpired(queueName string) {
+	readyDir, _ := c.queueDir(queueName, fileDirReady)
+	processingDir, _ := c.queueDir(queueName, fileDirProcessing)
+
+	names, err := listMessageFiles(processingDir)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		claimedPath := filepath.Join(processingDir, name)
+
+		expired := false
+		data, err := os.ReadFile(claimedPath + fileLeaseExt)
+		switch {
+		case err == nil:
+			var lease fileLease
+			expired = json.Unmarshal(data, &lease) != nil || now.After(lease.ExpiresAt)
+		case errors.Is(err, os.ErrNotExist):
+			if info, statErr := os.Stat(claimedPath); statErr == nil {
+				expired = now.Sub(info.ModTime()) > c.visibilityTimeout
+			}
+		}
+		if !expired {
+			continue
+		}
+
+		if err := os.Rename(claimedPath, filepath.Join(readyDir, name)); err == nil {
+			_ = os.Remove(claimedPath + fileLeaseExt)
+		}
+	}
+}
+
+// Ack acknowledges a message by deleting its file and lease
+func (c *FileClient) Ack(ctx context.Context, msg QueueMessage) error {
+	fileMsg, ok := msg.(*fileMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *fileMessage")
+	}
+
+	if err := os.Remove(fileMsg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	_ = os.Remove(fileMsg.path + fileLeaseExt)
+	return nil
+}
+
+// Nack returns a claimed message to the ready directory for immediate redelivery
+func (c *FileClient) Nack(ctx context.Context, msg QueueMessage) error {
+	fileMsg, ok := msg.(*fileMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *fileMessage")
+	}
+
+	readyPath := filepath.Join(filepath.Dir(filepath.Dir(fileMsg.path)), fileDirReady, filepath.Base(fileMsg.path))
+	if err := os.Rename(fileMsg.path, readyPath); err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	_ = os.Remove(fileMsg.path + fileLeaseExt)
+	return nil
+}
+
+// Close closes the file client (no-op for files)
+func (c *FileClient) Close() error {
+	return nil
+}
+
+// listMessageFiles returns sorted message file names in dir
+func listMessageFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileMessageExt) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseMessageName splits "{base}.{deliveries}.msg" into base and delivery count
+func parseMessageName(name string) (string, int) {
+	trimmed := strings.TrimSuffix(name, fileMessageExt)
+	idx := strings.LastIndex(trimmed, ".")
+	if idx < 0 {
+		return trimmed, 0
+	}
+	deliveries, err := strconv.Atoi(trimmed[idx+1:])
+	if err != nil {
+		return trimmed, 0
+	}
+	return trimmed[:idx], deliveries
+}
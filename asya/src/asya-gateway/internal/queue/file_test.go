@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFileClient(t *testing.T, visibility time.Duration) *FileClient {
+	t.Helper()
+	client, err := NewFileClient(FileConfig{
+		Root:              t.TempDir(),
+		Namespace:         "default",
+		VisibilityTimeout: visibility,
+		PollInterval:      5 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestFileClient_SendEnvelope(t *testing.T) {
+	client := newTestFileClient(t, 0)
+
+	deadline := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:       "env-1",
+		Route:    types.Route{Actors: []string{"parser", "writer"}, Current: 1},
+		Payload:  map[string]any{"k": "v"},
+		Deadline: deadline,
+		MaxHops:  12,
+	})
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(filepath.Join(client.root, "asya-default-writer", fileDirReady))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	data, err := os.ReadFile(filepath.Join(client.root, "asya-default-writer", fileDirReady, entries[0].Name()))
+	require.NoError(t, err)
+
+	var msg ActorEnvelope
+	require.NoError(t, json.Unmarshal(data, &msg))
+	assert.Equal(t, "env-1", msg.ID)
+	assert.Equal(t, 1, msg.Route.Current)
+	assert.Equal(t, "2025-01-02T03:04:05Z", msg.Deadline)
+	assert.Equal(t, 12, msg.MaxHops)
+}
+
+func TestFileClient_SendEnvelope_InvalidRoute(t *testing.T) {
+	client := newTestFileClient(t, 0)
+
+	err := client.SendEnvelope(context.Background(), &types.Envelope{ID: "env-1"})
+	assert.ErrorContains(t, err, "route has no actors")
+
+	err = client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-2",
+		Route: types.Route{Actors: []string{"a"}, Current: 1},
+	})
+	assert.ErrorContains(t, err, "invalid route.current")
+}
+
+func TestFileClient_ReceiveAck(t *testing.T) {
+	client := newTestFileClient(t, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, client.SendEnvelope(ctx, &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"happy-end"}},
+	}))
+
+	msg, err := client.Receive(ctx, "asya-default-happy-end")
+	require.NoError(t, err)
+	assert.Contains(t, string(msg.Body()), `"id":"env-1"`)
+	assert.Equal(t, uint64(1), msg.DeliveryTag())
+
+	require.NoError(t, client.Ack(ctx, msg))
+	entries, err := os.ReadDir(filepath.Join(client.root, "asya-default-happy-end", fileDirProcessing))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFileClient_NackRedelivers(t *testing.T) {
+	client := newTestFileClient(t, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, client.publish("asya-default-status", []byte("x")))
+
+	first, err := client.Receive(ctx, "asya-default-status")
+	require.NoError(t, err)
+	require.NoError(t, client.Nack(ctx, first))
+
+	second, err := client.Receive(ctx, "asya-default-status")
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(second.Body()))
+	assert.Equal(t, uint64(2), second.DeliveryTag())
+}
+
+func TestFileClient_LeaseExpiryRedelivers(t *testing.T) {
+	client := newTestFileClient(t, 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, client.publish("asya-default-slow", []byte("x")))
+
+	first, err := client.Receive(ctx, "asya-default-slow")
+	require.NoError(t, err)
+
+	second, err := client.Receive(ctx, "asya-default-slow")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), first.DeliveryTag())
+	assert.Equal(t, uint64(2), second.DeliveryTag())
+}
+
+func TestFileClient_ReceiveContextCancelled(t *testing.T) {
+	client := newTestFileClient(t, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Receive(ctx, "asya-default-empty")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
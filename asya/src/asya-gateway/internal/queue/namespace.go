@@ -0,0 +1,36 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+// NamespaceGuard rejects envelopes whose current actor is in another namespace that does not accept
+// envelopes from the gateway's namespace, matching the sidecars' ASYA_ALLOWED_TARGET_NAMESPACES check,
+// so first hops and resumed envelopes cannot reach actors the sidecars would refuse to route to
+type NamespaceGuard struct {
+	Client
+	namespace string
+	allowed   []string
+}
+
+// NewNamespaceGuard wraps a queue client so envelopes are only sent to actors in namespace or in allowed
+func NewNamespaceGuard(client Client, namespace string, allowed []string) *NamespaceGuard {
+	return &NamespaceGuard{Client: client, namespace: namespace, allowed: allowed}
+}
+
+// SendEnvelope sends the envelope if the namespace of its current actor accepts it
+func (g *NamespaceGuard) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	route := envelope.Route
+	if route.Current >= 0 && route.Current < len(route.Actors) {
+		actorRef := route.Actors[route.Current]
+		namespace, _ := types.SplitActorRef(actorRef, g.namespace)
+		if namespace != g.namespace && !slices.Contains(g.allowed, namespace) {
+			return fmt.Errorf("namespace %q of actor %q does not accept envelopes from namespace %q", namespace, actorRef, g.namespace)
+		}
+	}
+	return g.Client.SendEnvelope(ctx, envelope)
+}
@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+type recordingClient struct {
+	Client
+	sent []*types.Envelope
+}
+
+func (c *recordingClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	c.sent = append(c.sent, envelope)
+	return nil
+}
+
+func TestNamespaceGuard_SendEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		actors  []string
+		current int
+		wantErr bool
+	}{
+		{name: "bare actor in the gateway namespace", actors: []string{"ocr"}},
+		{name: "qualified actor in the gateway namespace", actors: []string{"default/ocr"}},
+		{name: "allowed target namespace", actors: []string{"team-c/ocr"}},
+		{name: "target namespace not opted in", actors: []string{"team-b/ocr"}, wantErr: true},
+		{name: "resumed envelope checks its current actor", actors: []string{"ocr", "team-b/publish"}, current: 1, wantErr: true},
+		{name: "exhausted route", actors: []string{"team-b/ocr"}, current: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingClient{}
+			guard := NewNamespaceGuard(inner, "default", []string{"team-c"})
+
+			envelope := &types.Envelope{ID: "env-1", Route: types.Route{Actors: tt.actors, Current: tt.current}}
+			err := guard.SendEnvelope(context.Background(), envelope)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "does not accept envelopes") {
+					t.Errorf("Expected a namespace error, got %v", err)
+				}
+				if len(inner.sent) != 0 {
+					t.Errorf("Expected nothing sent, got %d envelopes", len(inner.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendEnvelope failed: %v", err)
+			}
+			if len(inner.sent) != 1 {
+				t.Errorf("Expected the envelope to be sent, got %d envelopes", len(inner.sent))
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres Queue Schema:
+//
+// The postgres transport shares its tables with the sidecar (asya-sidecar/internal/transport/postgres.go).
+// Tables are created by migration db/deploy/005_add_queue_tables.sql. All queues share
+// asya_queue_messages keyed by queue_name ("asya-{namespace}-{actor}"); visible_at acts as
+// both the delivery delay and the visibility lease, and claims use FOR UPDATE SKIP LOCKED.
+
+const (
+	pgQueueClaimSQL = `
+WITH next AS (
+    SELECT id FROM asya_queue_messages
+    WHERE queue_name = $1 AND visible_at <= NOW()
+    ORDER BY visible_at, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE asya_queue_messages m
+SET deliveries = m.deliveries + 1,
+    visible_at = NOW() + make_interval(secs => $2),
+    locked_at = NOW()
+FROM next
+WHERE m.id = next.id
+RETURNING m.id, m.body, m.deliveries`
+
+	pgQueueDeadLetterSQL = `
+WITH dead AS (
+    DELETE FROM asya_queue_messages WHERE id = $1
+    RETURNING id, queue_name, body, deliveries, created_at
+)
+INSERT INTO asya_queue_dead_letters (id, queue_name, body, deliveries, created_at)
+SELECT id, queue_name, body, deliveries - 1, created_at FROM dead`
+
+	pgQueueSendSQL = `INSERT INTO asya_queue_messages (queue_name, body) VALUES ($1, $2)`
+	pgQueueAckSQL  = `DELETE FROM asya_queue_messages WHERE id = $1 AND deliveries = $2`
+	pgQueueNackSQL = `UPDATE asya_queue_messages SET visible_at = NOW(), locked_at = NULL WHERE id = $1 AND deliveries = $2`
+)
+
+// pgQuerier is the subset of pgxpool.Pool used by the client
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Close()
+}
+
+// PostgresClient implements the Client interface using PostgreSQL tables as queues
+type PostgresClient struct {
+	pool              pgQuerier
+	namespace         string
+	naming            naming.Scheme
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	maxDeliveries     int
+}
+
+// PostgresConfig holds postgres transport configuration
+type PostgresConfig struct {
+	URL               string
+	Namespace         string
+	Naming            naming.Scheme // Queue naming scheme shared with sidecars (zero value is the default)
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+	MaxDeliveries     int
+}
+
+// NewPostgresClient creates a new postgres queue client
+func NewPostgresClient(ctx context.Context, cfg PostgresConfig) (*PostgresClient, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("postgres transport URL is required")
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return newPostgresClient(pool, cfg), nil
+}
+
+func newPostgresClient(pool pgQuerier, cfg PostgresConfig) *PostgresClient {
+	// Set defaults
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = 5 * time.Minute
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 500 * time.Millisecond
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = 3
+	}
+
+	return &PostgresClient{
+		pool:              pool,
+		namespace:         cfg.Namespace,
+		naming:            cfg.Naming,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+		maxDeliveries:     maxDeliveries,
+	}
+}
+
+// postgresMessage wraps a claimed row for the QueueMessage interface
+type postgresMessage struct {
+	id         int64
+	body       []byte
+	deliveries int
+}
+
+func (m *postgresMessage) Body() []byte {
+	return m.body
+}
+
+func (m *postgresMessage) DeliveryTag() uint64 {
+	return uint64(m.id) // #nosec G115 - BIGSERIAL ids are positive
+}
+
+// SendEnvelope inserts an envelope into the current actor's queue
+func (c *PostgresClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	if len(envelope.Route.Actors) == 0 {
+		return fmt.Errorf("route has no actors")
+	}
+	if envelope.Route.Current < 0 || envelope.Route.Current >= len(envelope.Route.Actors) {
+		return fmt.Errorf("invalid route.current=%d for actors length %d", envelope.Route.Current, len(envelope.Route.Actors))
+	}
+
+	// Create actor envelope
+	msg := newActorEnvelope(envelope)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	// Convert actor reference to its queue name (qualified references name the namespace)
+	actorName := envelope.Route.Actors[envelope.Route.Current]
+	queueName := actorQueueName(c.naming, c.namespace, actorName)
+
+	if _, err := c.pool.Exec(ctx, pgQueueSendSQL, queueName, body); err != nil {
+		slog.Error("Failed to insert envelope into postgres queue", "envelopeID", envelope.ID, "queue", queueName, "error", err)
+		return fmt.Errorf("failed to send message to %s: %w", queueName, err)
+	}
+
+	slog.Info("Successfully sent envelope to postgres queue", "envelopeID", envelope.ID, "queue", queueName)
+	return nil
+}
+
+// Receive claims the next visible message from the specified queue, polling until one arrives
+func (c *PostgresClient) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		msg, err := c.claimNext(ctx, queueName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if msg != nil {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimNext leases the next visible message; nil if the queue is empty
+func (c *PostgresClient) claimNext(ctx context.Context, queueName string) (*postgresMessage, error) {
+	for {
+		msg := &postgresMessage{}
+		err := c.pool.QueryRow(ctx, pgQueueClaimSQL, queueName, c.visibilityTimeout.Seconds()).Scan(&msg.id, &msg.body, &msg.deliveries)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim message from %s: %w", queueName, err)
+		}
+
+		if msg.deliveries > c.maxDeliveries {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter table", "queue", queueName, "id", msg.id)
+			if _, err := c.pool.Exec(ctx, pgQueueDeadLetterSQL, msg.id); err != nil {
+				return nil, fmt.Errorf("failed to dead-letter message %d: %w", msg.id, err)
+			}
+			continue
+		}
+
+		return msg, nil
+	}
+}
+
+// Ack acknowledges a message by deleting its row
+func (c *PostgresClient) Ack(ctx context.Context, msg QueueMessage) error {
+	pgMsg, ok := msg.(*postgresMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *postgresMessage")
+	}
+
+	if _, err := c.pool.Exec(ctx, pgQueueAckSQL, pgMsg.id, pgMsg.deliveries); err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack makes a claimed message visible again immediately
+func (c *PostgresClient) Nack(ctx context.Context, msg QueueMessage) error {
+	pgMsg, ok := msg.(*postgresMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *postgresMessage")
+	}
+
+	if _, err := c.pool.Exec(ctx, pgQueueNackSQL, pgMsg.id, pgMsg.deliveries); err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (c *PostgresClient) Close() error {
+	c.pool.Close()
+	return nil
+}
@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePgRow implements pgx.Row for a claimed message
+type fakePgRow struct {
+	id         int64
+	body       []byte
+	deliveries int
+	err        error
+}
+
+func (r fakePgRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.id
+	*dest[1].(*[]byte) = r.body
+	*dest[2].(*int) = r.deliveries
+	return nil
+}
+
+// fakePgQuerier records statements and returns queued claim rows
+type fakePgQuerier struct {
+	rows     []fakePgRow
+	execs    []string
+	execArgs [][]any
+	execErr  error
+	closed   bool
+}
+
+func (q *fakePgQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.execs = append(q.execs, sql)
+	q.execArgs = append(q.execArgs, args)
+	return pgconn.NewCommandTag("INSERT 0 1"), q.execErr
+}
+
+func (q *fakePgQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if len(q.rows) == 0 {
+		return fakePgRow{err: pgx.ErrNoRows}
+	}
+	row := q.rows[0]
+	q.rows = q.rows[1:]
+	return row
+}
+
+func (q *fakePgQuerier) Close() {
+	q.closed = true
+}
+
+func TestPostgresClient_SendEnvelope(t *testing.T) {
+	q := &fakePgQuerier{}
+	client := newPostgresClient(q, PostgresConfig{Namespace: "default"})
+
+	deadline := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:       "env-1",
+		Route:    types.Route{Actors: []string{"parser", "writer"}, Current: 1},
+		Payload:  map[string]any{"k": "v"},
+		Deadline: deadline,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, q.execs, 1)
+	assert.Equal(t, pgQueueSendSQL, q.execs[0])
+	assert.Equal(t, "asya-default-writer", q.execArgs[0][0])
+
+	var msg ActorEnvelope
+	require.NoError(t, json.Unmarshal(q.execArgs[0][1].([]byte), &msg))
+	assert.Equal(t, "env-1", msg.ID)
+	assert.Equal(t, 1, msg.Route.Current)
+	assert.Equal(t, "2025-01-02T03:04:05Z", msg.Deadline)
+}
+
+func TestPostgresClient_SendEnvelope_Errors(t *testing.T) {
+	client := newPostgresClient(&fakePgQuerier{}, PostgresConfig{Namespace: "default"})
+
+	err := client.SendEnvelope(context.Background(), &types.Envelope{ID: "env-1"})
+	assert.ErrorContains(t, err, "route has no actors")
+
+	err = client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"a"}, Current: 2},
+	})
+	assert.ErrorContains(t, err, "invalid route.current")
+
+	failing := newPostgresClient(&fakePgQuerier{execErr: errors.New("connection refused")}, PostgresConfig{Namespace: "default"})
+	err = failing.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"a"}},
+	})
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestPostgresClient_ReceiveAck(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakePgRow{{id: 7, body: []byte(`{"id":"env-1"}`), deliveries: 1}}}
+	client := newPostgresClient(q, PostgresConfig{PollInterval: time.Millisecond})
+
+	msg, err := client.Receive(context.Background(), "asya-default-writer")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"env-1"}`, string(msg.Body()))
+	assert.Equal(t, uint64(7), msg.DeliveryTag())
+
+	require.NoError(t, client.Ack(context.Background(), msg))
+	require.Len(t, q.execs, 1)
+	assert.Equal(t, pgQueueAckSQL, q.execs[0])
+	assert.Equal(t, []any{int64(7), 1}, q.execArgs[0])
+}
+
+func TestPostgresClient_Nack(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakePgRow{{id: 7, body: []byte(`{"id":"env-1"}`), deliveries: 2}}}
+	client := newPostgresClient(q, PostgresConfig{PollInterval: time.Millisecond})
+
+	msg, err := client.Receive(context.Background(), "asya-default-gateway-status")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Nack(context.Background(), msg))
+	require.Len(t, q.execs, 1)
+	assert.Equal(t, pgQueueNackSQL, q.execs[0])
+	assert.Equal(t, []any{int64(7), 2}, q.execArgs[0])
+}
+
+func TestPostgresClient_ReceiveDeadLettersExceededMessages(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakePgRow{
+		{id: 1, body: []byte("poison"), deliveries: 4},
+		{id: 2, body: []byte("ok"), deliveries: 1},
+	}}
+	client := newPostgresClient(q, PostgresConfig{MaxDeliveries: 3, PollInterval: time.Millisecond})
+
+	msg, err := client.Receive(context.Background(), "asya-default-writer")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(msg.Body()))
+
+	require.Len(t, q.execs, 1)
+	assert.Equal(t, pgQueueDeadLetterSQL, q.execs[0])
+	assert.Equal(t, []any{int64(1)}, q.execArgs[0])
+}
+
+func TestPostgresClient_ReceiveContextCancelled(t *testing.T) {
+	client := newPostgresClient(&fakePgQuerier{}, PostgresConfig{PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Receive(ctx, "asya-default-writer")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPostgresClient_AckInvalidMessage(t *testing.T) {
+	client := newPostgresClient(&fakePgQuerier{}, PostgresConfig{})
+	err := client.Ack(context.Background(), &fileMessage{})
+	assert.ErrorContains(t, err, "invalid message type")
+}
+
+func TestPostgresClient_Close(t *testing.T) {
+	q := &fakePgQuerier{}
+	client := newPostgresClient(q, PostgresConfig{})
+	require.NoError(t, client.Close())
+	assert.True(t, q.closed)
+}
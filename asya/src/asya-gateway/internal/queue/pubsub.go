@@ -0,0 +1,226 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/oauth"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
+)
+
+// Pub/Sub Layout:
+//
+// The pubsub transport shares its layout with the sidecar (asya-sidecar/internal/transport/pubsub.go).
+// Each queue "asya-{namespace}-{actor}" is a topic with a subscription of the same name.
+// The gateway publishes envelopes to the topic and consumes with unary Pull; messages it
+// does not ack are redelivered after the subscription's ack deadline.
+
+const (
+	defaultPubSubEndpoint = "pubsub.googleapis.com:443"
+	pubsubScope           = "https://www.googleapis.com/auth/pubsub"
+)
+
+// PubSubClient implements the Client interface for Google Cloud Pub/Sub
+type PubSubClient struct {
+	conn       *grpc.ClientConn
+	publisher  pubsubpb.PublisherClient
+	subscriber pubsubpb.SubscriberClient
+	projectID  string
+	namespace  string
+	naming     naming.Scheme
+}
+
+// PubSubConfig holds Pub/Sub transport configuration
+type PubSubConfig struct {
+	ProjectID    string
+	Namespace    string
+	Naming       naming.Scheme // Queue naming scheme shared with sidecars (zero value is the default)
+	EmulatorHost string        // host:port of the Pub/Sub emulator; disables TLS and credentials
+	Endpoint     string        // gRPC endpoint (default pubsub.googleapis.com:443)
+}
+
+// NewPubSubClient creates a new Pub/Sub queue client.
+// Outside the emulator, credentials come from Application Default Credentials
+// (GKE Workload Identity in cluster).
+func NewPubSubClient(ctx context.Context, cfg PubSubConfig) (*PubSubClient, error) {
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("pubsub project ID is required")
+	}
+
+	var conn *grpc.ClientConn
+	var err error
+	if cfg.EmulatorHost != "" {
+		conn, err = grpc.NewClient(cfg.EmulatorHost, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = defaultPubSubEndpoint
+		}
+		tokenSource, tsErr := google.DefaultTokenSource(ctx, pubsubScope)
+		if tsErr != nil {
+			return nil, fmt.Errorf("failed to load Google credentials: %w", tsErr)
+		}
+		conn, err = grpc.NewClient(endpoint,
+			grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")),
+			grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokenSource}),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Pub/Sub: %w", err)
+	}
+
+	return newPubSubClient(conn, cfg), nil
+}
+
+func newPubSubClient(conn *grpc.ClientConn, cfg PubSubConfig) *PubSubClient {
+	return &PubSubClient{
+		conn:       conn,
+		publisher:  pubsubpb.NewPublisherClient(conn),
+		subscriber: pubsubpb.NewSubscriberClient(conn),
+		projectID:  cfg.ProjectID,
+		namespace:  cfg.Namespace,
+		naming:     cfg.Naming,
+	}
+}
+
+func (c *PubSubClient) topicPath(queueName string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", c.projectID, queueName)
+}
+
+func (c *PubSubClient) subscriptionPath(queueName string) string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", c.projectID, queueName)
+}
+
+// pubsubMessage wraps a pulled message for the QueueMessage interface
+type pubsubMessage struct {
+	subscription string
+	ackID        string
+	body         []byte
+	deliveries   int32
+}
+
+func (m *pubsubMessage) Body() []byte {
+	return m.body
+}
+
+func (m *pubsubMessage) DeliveryTag() uint64 {
+	return uint64(m.deliveries) // #nosec G115 - delivery attempt is non-negative
+}
+
+// SendEnvelope publishes an envelope to the current actor's topic
+func (c *PubSubClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	if len(envelope.Route.Actors) == 0 {
+		return fmt.Errorf("route has no actors")
+	}
+	if envelope.Route.Current < 0 || envelope.Route.Current >= len(envelope.Route.Actors) {
+		return fmt.Errorf("invalid route.current=%d for actors length %d", envelope.Route.Current, len(envelope.Route.Actors))
+	}
+
+	// Create actor envelope
+	msg := newActorEnvelope(envelope)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	// Convert actor reference to its queue name (qualified references name the namespace)
+	actorName := envelope.Route.Actors[envelope.Route.Current]
+	queueName := actorQueueName(c.naming, c.namespace, actorName)
+
+	_, err = c.publisher.Publish(ctx, &pubsubpb.PublishRequest{
+		Topic:    c.topicPath(queueName),
+		Messages: []*pubsubpb.PubsubMessage{{Data: body}},
+	})
+	if err != nil {
+		slog.Error("Failed to publish envelope to Pub/Sub", "envelopeID", envelope.ID, "queue", queueName, "error", err)
+		return fmt.Errorf("failed to send message to %s: %w", queueName, err)
+	}
+
+	slog.Info("Successfully published envelope to Pub/Sub", "envelopeID", envelope.ID, "queue", queueName)
+	return nil
+}
+
+// Receive pulls the next message from the queue's subscription
+func (c *PubSubClient) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	subscription := c.subscriptionPath(queueName)
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		resp, err := c.subscriber.Pull(ctx, &pubsubpb.PullRequest{
+			Subscription: subscription,
+			MaxMessages:  1,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to pull from subscription %s: %w", queueName, err)
+		}
+
+		if len(resp.ReceivedMessages) == 0 {
+			continue
+		}
+
+		rm := resp.ReceivedMessages[0]
+		return &pubsubMessage{
+			subscription: subscription,
+			ackID:        rm.AckId,
+			body:         rm.GetMessage().GetData(),
+			deliveries:   rm.DeliveryAttempt,
+		}, nil
+	}
+}
+
+// Ack acknowledges a pulled message
+func (c *PubSubClient) Ack(ctx context.Context, msg QueueMessage) error {
+	pubsubMsg, ok := msg.(*pubsubMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *pubsubMessage")
+	}
+
+	_, err := c.subscriber.Acknowledge(ctx, &pubsubpb.AcknowledgeRequest{
+		Subscription: pubsubMsg.subscription,
+		AckIds:       []string{pubsubMsg.ackID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack negatively acknowledges a pulled message by setting its ack deadline to 0,
+// making it immediately available for redelivery
+func (c *PubSubClient) Nack(ctx context.Context, msg QueueMessage) error {
+	pubsubMsg, ok := msg.(*pubsubMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *pubsubMessage")
+	}
+
+	_, err := c.subscriber.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+		Subscription:       pubsubMsg.subscription,
+		AckIds:             []string{pubsubMsg.ackID},
+		AckDeadlineSeconds: 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the gRPC connection
+func (c *PubSubClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestPubSubClient connects to PUBSUB_EMULATOR_HOST when set, otherwise to an in-process fake server
+func newTestPubSubClient(t *testing.T) *PubSubClient {
+	t.Helper()
+
+	addr := os.Getenv("PUBSUB_EMULATOR_HOST")
+	if addr == "" {
+		srv := pstest.NewServer()
+		t.Cleanup(func() { _ = srv.Close() })
+		addr = srv.Addr
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	client := newPubSubClient(conn, PubSubConfig{ProjectID: "asya-test", Namespace: "pubsubtest"})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// createTestPubSubQueue creates a topic and subscription the way the operator does
+func createTestPubSubQueue(t *testing.T, client *PubSubClient, queue string) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := client.publisher.CreateTopic(ctx, &pubsubpb.Topic{Name: client.topicPath(queue)})
+	require.NoError(t, err)
+	_, err = client.subscriber.CreateSubscription(ctx, &pubsubpb.Subscription{
+		Name:               client.subscriptionPath(queue),
+		Topic:              client.topicPath(queue),
+		AckDeadlineSeconds: 10,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = client.subscriber.DeleteSubscription(context.Background(), &pubsubpb.DeleteSubscriptionRequest{Subscription: client.subscriptionPath(queue)})
+		_, _ = client.publisher.DeleteTopic(context.Background(), &pubsubpb.DeleteTopicRequest{Topic: client.topicPath(queue)})
+	})
+}
+
+func TestPubSubClient_SendEnvelopeAndReceive(t *testing.T) {
+	client := newTestPubSubClient(t)
+	ctx := context.Background()
+	queue := "asya-pubsubtest-writer"
+	createTestPubSubQueue(t, client, queue)
+
+	deadline := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := client.SendEnvelope(ctx, &types.Envelope{
+		ID:       "env-1",
+		Route:    types.Route{Actors: []string{"parser", "writer"}, Current: 1},
+		Payload:  map[string]any{"k": "v"},
+		Deadline: deadline,
+	})
+	require.NoError(t, err)
+
+	recvCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	msg, err := client.Receive(recvCtx, queue)
+	require.NoError(t, err)
+
+	var env ActorEnvelope
+	require.NoError(t, json.Unmarshal(msg.Body(), &env))
+	assert.Equal(t, "env-1", env.ID)
+	assert.Equal(t, "2025-01-02T03:04:05Z", env.Deadline)
+
+	require.NoError(t, client.Ack(ctx, msg))
+}
+
+func TestPubSubClient_SendEnvelope_InvalidRoute(t *testing.T) {
+	client := newTestPubSubClient(t)
+
+	err := client.SendEnvelope(context.Background(), &types.Envelope{ID: "env-1"})
+	assert.ErrorContains(t, err, "route has no actors")
+
+	err = client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"a"}, Current: 2},
+	})
+	assert.ErrorContains(t, err, "invalid route.current")
+}
+
+func TestPubSubClient_SendEnvelope_MissingTopic(t *testing.T) {
+	client := newTestPubSubClient(t)
+
+	err := client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"missing"}},
+	})
+	assert.ErrorContains(t, err, "failed to send message to asya-pubsubtest-missing")
+}
+
+func TestPubSubClient_ReceiveCancelled(t *testing.T) {
+	client := newTestPubSubClient(t)
+	queue := "asya-pubsubtest-idle"
+	createTestPubSubQueue(t, client, queue)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := client.Receive(ctx, queue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPubSubClient_AckInvalidMessage(t *testing.T) {
+	client := newTestPubSubClient(t)
+	err := client.Ack(context.Background(), &fileMessage{})
+	assert.ErrorContains(t, err, "invalid message type")
+}
@@ -2,16 +2,48 @@ package queue
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 // ActorEnvelope represents the envelope format sent to actors
 type ActorEnvelope struct {
-	ID       string      `json:"id"`
-	Route    types.Route `json:"route"`
-	Payload  any         `json:"payload"`
-	Deadline string      `json:"deadline,omitempty"` // ISO8601 timestamp
+	ID        string                 `json:"id"`
+	ParentID  *string                `json:"parent_id,omitempty"`
+	Route     types.Route            `json:"route"`
+	Headers   map[string]interface{} `json:"headers,omitempty"`
+	Payload   any                    `json:"payload"`
+	Deadline  string                 `json:"deadline,omitempty"` // ISO8601 timestamp
+	MaxHops   int                    `json:"max_hops,omitempty"`
+	HopCount  int                    `json:"hop_count,omitempty"`
+	Visited   []string               `json:"visited,omitempty"`
+	Hops      []types.Hop            `json:"hops,omitempty"`
+	CallStack json.RawMessage        `json:"call_stack,omitempty"`
+}
+
+// newActorEnvelope builds the message sent to the current actor of an envelope's route.
+// Hop history and call stack are only set on envelopes resumed after an approval step.
+func newActorEnvelope(envelope *types.Envelope) ActorEnvelope {
+	msg := ActorEnvelope{
+		ID:        envelope.ID,
+		ParentID:  envelope.ParentID,
+		Route:     envelope.Route,
+		Headers:   envelope.Headers,
+		Payload:   envelope.Payload,
+		MaxHops:   envelope.MaxHops,
+		HopCount:  envelope.HopCount,
+		Visited:   envelope.Visited,
+		Hops:      envelope.Hops,
+		CallStack: envelope.CallStack,
+	}
+
+	// Add deadline if envelope has timeout
+	if !envelope.Deadline.IsZero() {
+		msg.Deadline = envelope.Deadline.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return msg
 }
 
 // QueueMessage represents a envelope received from a queue
@@ -25,5 +57,13 @@ type Client interface {
 	SendEnvelope(ctx context.Context, envelope *types.Envelope) error
 	Receive(ctx context.Context, queueName string) (QueueMessage, error)
 	Ack(ctx context.Context, msg QueueMessage) error
+	Nack(ctx context.Context, msg QueueMessage) error // Makes a received message available for redelivery
 	Close() error
 }
+
+// actorQueueName resolves an actor reference from a route to its queue name under scheme
+// (asya-{namespace}-{actor} by default). Qualified references ("namespace/actor") resolve to the queue in that namespace.
+func actorQueueName(scheme naming.Scheme, namespace, actorRef string) string {
+	namespace, actorName := types.SplitActorRef(actorRef, namespace)
+	return scheme.QueueName(namespace, actorName)
+}
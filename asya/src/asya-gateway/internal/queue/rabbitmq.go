@@ -9,6 +9,7 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 // Queue Naming Convention (All Transports):
@@ -25,14 +26,17 @@ import (
 // - Gateway queue clients (this file and sqs.go) when sending envelopes
 // - Sidecar (router.go) when creating/consuming from queues
 //
-// This maintains consistent queue naming across all transport implementations.
+// This maintains consistent queue naming across all transport implementations. The prefix, an optional
+// cluster name and an optional suffix are configurable through naming.Scheme (see the asya-naming module).
 
 // RabbitMQClient sends envelopes to RabbitMQ
 type RabbitMQClient struct {
-	conn     *amqp.Connection
-	ch       *amqp.Channel
-	exchange string
-	mu       sync.Mutex // Protects channel access for thread-safety
+	conn      *amqp.Connection
+	ch        *amqp.Channel
+	exchange  string
+	namespace string
+	naming    naming.Scheme
+	mu        sync.Mutex // Protects channel access for thread-safety
 }
 
 // NewRabbitMQClient creates a new RabbitMQ client
@@ -71,6 +75,16 @@ func NewRabbitMQClient(url, exchange string) (*RabbitMQClient, error) {
 	}, nil
 }
 
+// SetNamespace sets the namespace of actors referenced without one
+func (c *RabbitMQClient) SetNamespace(namespace string) {
+	c.namespace = namespace
+}
+
+// SetNaming sets the queue naming scheme shared with sidecars (the zero value is the default scheme)
+func (c *RabbitMQClient) SetNaming(scheme naming.Scheme) {
+	c.naming = scheme
+}
+
 // SendEnvelope sends an envelope to the current actor's queue in the route
 func (c *RabbitMQClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
 	if len(envelope.Route.Actors) == 0 {
@@ -81,16 +95,7 @@ func (c *RabbitMQClient) SendEnvelope(ctx context.Context, envelope *types.Envel
 	}
 
 	// Create actor envelope
-	msg := ActorEnvelope{
-		ID:      envelope.ID,
-		Route:   envelope.Route,
-		Payload: envelope.Payload,
-	}
-
-	// Add deadline if envelope has timeout
-	if !envelope.Deadline.IsZero() {
-		msg.Deadline = envelope.Deadline.Format("2006-01-02T15:04:05Z07:00")
-	}
+	msg := newActorEnvelope(envelope)
 
 	// Marshal to JSON
 	body, err := json.Marshal(msg)
@@ -99,9 +104,9 @@ func (c *RabbitMQClient) SendEnvelope(ctx context.Context, envelope *types.Envel
 	}
 
 	// Send envelope to current actor's queue
-	// Use actor name as routing key (sidecar binds queue with actor name, not "asya-" prefixed name)
+	// The routing key is the full queue name, which the operator binds for each queue
 	actorName := envelope.Route.Actors[envelope.Route.Current]
-	routingKey := actorName
+	routingKey := actorQueueName(c.naming, c.namespace, actorName)
 
 	// Protect channel access with mutex for thread-safety
 	c.mu.Lock()
@@ -194,6 +199,19 @@ func (c *RabbitMQClient) Ack(ctx context.Context, msg QueueMessage) error {
 	return c.ch.Ack(rmqMsg.delivery.DeliveryTag, false)
 }
 
+// Nack negatively acknowledges a envelope (requeue)
+func (c *RabbitMQClient) Nack(ctx context.Context, msg QueueMessage) error {
+	rmqMsg, ok := msg.(*rabbitMQMessage)
+	if !ok {
+		return fmt.Errorf("invalid envelope type")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ch.Nack(rmqMsg.delivery.DeliveryTag, false, true)
+}
+
 // Close closes the RabbitMQ connection
 func (c *RabbitMQClient) Close() error {
 	c.mu.Lock()
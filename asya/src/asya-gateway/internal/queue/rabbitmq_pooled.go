@@ -9,6 +9,7 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 // consumerInfo holds a persistent consumer channel and its deliveries
@@ -23,6 +24,8 @@ type RabbitMQClientPooled struct {
 	pool        *ChannelPool
 	consumers   map[string]*consumerInfo
 	consumersMu sync.Mutex
+	namespace   string
+	naming      naming.Scheme
 }
 
 // NewRabbitMQClientPooled creates a new RabbitMQ client with channel pooling
@@ -38,6 +41,16 @@ func NewRabbitMQClientPooled(url, exchange string, poolSize int) (*RabbitMQClien
 	}, nil
 }
 
+// SetNamespace sets the namespace of actors referenced without one
+func (c *RabbitMQClientPooled) SetNamespace(namespace string) {
+	c.namespace = namespace
+}
+
+// SetNaming sets the queue naming scheme shared with sidecars (the zero value is the default scheme)
+func (c *RabbitMQClientPooled) SetNaming(scheme naming.Scheme) {
+	c.naming = scheme
+}
+
 // SendEnvelope sends an envelope to the current actor's queue in the route
 func (c *RabbitMQClientPooled) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
 	if len(envelope.Route.Actors) == 0 {
@@ -48,16 +61,7 @@ func (c *RabbitMQClientPooled) SendEnvelope(ctx context.Context, envelope *types
 	}
 
 	// Create actor envelope
-	msg := ActorEnvelope{
-		ID:      envelope.ID,
-		Route:   envelope.Route,
-		Payload: envelope.Payload,
-	}
-
-	// Add deadline if envelope has timeout
-	if !envelope.Deadline.IsZero() {
-		msg.Deadline = envelope.Deadline.Format("2006-01-02T15:04:05Z07:00")
-	}
+	msg := newActorEnvelope(envelope)
 
 	// Marshal to JSON
 	body, err := json.Marshal(msg)
@@ -73,9 +77,9 @@ func (c *RabbitMQClientPooled) SendEnvelope(ctx context.Context, envelope *types
 	defer c.pool.Return(ch)
 
 	// Send envelope to current actor's queue
-	// Use actor name as routing key (sidecar binds queue with actor name, not "asya-" prefixed name)
+	// The routing key is the full queue name, which the operator binds for each queue
 	actorName := envelope.Route.Actors[envelope.Route.Current]
-	routingKey := actorName
+	routingKey := actorQueueName(c.naming, c.namespace, actorName)
 	err = ch.PublishWithContext(ctx,
 		c.pool.exchange, // exchange
 		routingKey,      // routing key (queue name)
@@ -226,6 +230,20 @@ func (c *RabbitMQClientPooled) Ack(ctx context.Context, msg QueueMessage) error
 	return nil
 }
 
+// Nack negatively acknowledges a envelope (requeue) on the channel that received it
+func (c *RabbitMQClientPooled) Nack(ctx context.Context, msg QueueMessage) error {
+	pooledMsg, ok := msg.(*pooledRabbitMQMessage)
+	if !ok {
+		return fmt.Errorf("invalid envelope type: expected *pooledRabbitMQMessage")
+	}
+
+	if err := pooledMsg.channel.Nack(pooledMsg.delivery.DeliveryTag, false, true); err != nil {
+		return fmt.Errorf("failed to nack envelope: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes all persistent consumers and the channel pool
 func (c *RabbitMQClientPooled) Close() error {
 	c.consumersMu.Lock()
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,29 +23,47 @@ func (m *mockAMQPChannel) PublishWithContext(ctx context.Context, exchange, key
 	return args.Error(0)
 }
 
-// TestRabbitMQQueueNaming tests that actor names are used as-is for RabbitMQ routing keys
-// The sidecar binds queues with routing key = actor name (without "asya-" prefix)
-// Example: actor "data-processor" -> queue "asya-data-processor" -> routing key "data-processor"
+// TestRabbitMQQueueNaming tests that RabbitMQ routing keys are full queue names
+// The operator binds each queue with its name as routing key, and sidecars publish with it
+// Example: actor "data-processor" in namespace "default" -> routing key "asya-default-data-processor"
 func TestRabbitMQQueueNaming(t *testing.T) {
 	tests := []struct {
 		name               string
+		scheme             naming.Scheme
 		actorName          string
 		expectedRoutingKey string
 	}{
 		{
 			name:               "simple actor name",
 			actorName:          "data-processor",
-			expectedRoutingKey: "data-processor",
+			expectedRoutingKey: "asya-default-data-processor",
 		},
 		{
 			name:               "test actor name",
 			actorName:          "test-echo",
-			expectedRoutingKey: "test-echo",
+			expectedRoutingKey: "asya-default-test-echo",
 		},
 		{
 			name:               "crew actor name",
 			actorName:          "happy-end",
-			expectedRoutingKey: "happy-end",
+			expectedRoutingKey: "asya-default-happy-end",
+		},
+		{
+			name:               "qualified actor in another namespace",
+			actorName:          "team-b/ocr",
+			expectedRoutingKey: "asya-team-b-ocr",
+		},
+		{
+			name:               "custom scheme keeps the suffix",
+			scheme:             naming.Scheme{Prefix: "acme", Cluster: "eu1", Suffix: "q"},
+			actorName:          "ocr",
+			expectedRoutingKey: "acme-eu1-default-ocr-q",
+		},
+		{
+			name:               "custom scheme for qualified actor",
+			scheme:             naming.Scheme{Prefix: "acme", Cluster: "eu1", Suffix: "q"},
+			actorName:          "team-b/ocr",
+			expectedRoutingKey: "acme-eu1-team-b-ocr-q",
 		},
 	}
 
@@ -93,8 +112,7 @@ func TestRabbitMQQueueNaming(t *testing.T) {
 
 			// Since we can't easily inject the mock channel without refactoring,
 			// let's at least verify the routing key construction logic
-			actorName := envelope.Route.Actors[0]
-			actualRoutingKey := actorName
+			actualRoutingKey := actorQueueName(tt.scheme, "default", envelope.Route.Actors[0])
 
 			assert.Equal(t, tt.expectedRoutingKey, actualRoutingKey,
 				"Actor %s should map to routing key %s", tt.actorName, tt.expectedRoutingKey)
@@ -0,0 +1,326 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Streams Layout:
+//
+// The redis transport shares its stream layout with the sidecar (asya-sidecar/internal/transport/redis.go).
+// Each queue "asya-{namespace}-{actor}" is a stream read through one consumer group; the
+// envelope is stored in the "body" field. Entries pending longer than the visibility timeout
+// are reclaimed with XAUTOCLAIM, and entries over the delivery limit move to "{queue}:dead".
+
+const (
+	redisBodyField  = "body"
+	redisDeadSuffix = ":dead"
+)
+
+// RedisClient implements the Client interface on top of Redis Streams
+type RedisClient struct {
+	client            redis.UniversalClient
+	namespace         string
+	naming            naming.Scheme
+	group             string
+	consumer          string
+	visibilityTimeout time.Duration
+	blockTimeout      time.Duration
+	maxDeliveries     int
+
+	mu      sync.Mutex
+	ensured map[string]bool
+}
+
+// RedisConfig holds redis transport configuration
+type RedisConfig struct {
+	URL               string
+	Namespace         string
+	Naming            naming.Scheme // Queue naming scheme shared with sidecars (zero value is the default)
+	Group             string
+	Consumer          string
+	VisibilityTimeout time.Duration
+	BlockTimeout      time.Duration
+	MaxDeliveries     int
+}
+
+// NewRedisClient creates a new Redis Streams queue client
+func NewRedisClient(ctx context.Context, cfg RedisConfig) (*RedisClient, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return newRedisClient(client, cfg), nil
+}
+
+func newRedisClient(client redis.UniversalClient, cfg RedisConfig) *RedisClient {
+	// Set defaults
+	group := cfg.Group
+	if group == "" {
+		group = "asya"
+	}
+	consumer := cfg.Consumer
+	if consumer == "" {
+		consumer = "asya-gateway"
+	}
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = 5 * time.Minute
+	}
+	blockTimeout := cfg.BlockTimeout
+	if blockTimeout == 0 {
+		blockTimeout = time.Second
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = 3
+	}
+
+	return &RedisClient{
+		client:            client,
+		namespace:         cfg.Namespace,
+		naming:            cfg.Naming,
+		group:             group,
+		consumer:          consumer,
+		visibilityTimeout: visibilityTimeout,
+		blockTimeout:      blockTimeout,
+		maxDeliveries:     maxDeliveries,
+		ensured:           make(map[string]bool),
+	}
+}
+
+// redisMessage wraps a stream entry for the QueueMessage interface
+type redisMessage struct {
+	stream     string
+	id         string
+	body       []byte
+	deliveries int64
+}
+
+func (m *redisMessage) Body() []byte {
+	return m.body
+}
+
+func (m *redisMessage) DeliveryTag() uint64 {
+	return uint64(m.deliveries) // #nosec G115 - delivery count is positive
+}
+
+// SendEnvelope appends an envelope to the current actor's stream
+func (c *RedisClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	if len(envelope.Route.Actors) == 0 {
+		return fmt.Errorf("route has no actors")
+	}
+	if envelope.Route.Current < 0 || envelope.Route.Current >= len(envelope.Route.Actors) {
+		return fmt.Errorf("invalid route.current=%d for actors length %d", envelope.Route.Current, len(envelope.Route.Actors))
+	}
+
+	// Create actor envelope
+	msg := newActorEnvelope(envelope)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	// Convert actor reference to its queue name (qualified references name the namespace)
+	actorName := envelope.Route.Actors[envelope.Route.Current]
+	queueName := actorQueueName(c.naming, c.namespace, actorName)
+
+	err = c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: queueName,
+		Values: map[string]interface{}{redisBodyField: body},
+	}).Err()
+	if err != nil {
+		slog.Error("Failed to add envelope to redis stream", "envelopeID", envelope.ID, "queue", queueName, "error", err)
+		return fmt.Errorf("failed to send message to %s: %w", queueName, err)
+	}
+
+	slog.Info("Successfully sent envelope to redis stream", "envelopeID", envelope.ID, "queue", queueName)
+	return nil
+}
+
+// ensureGroup creates the stream and consumer group once per queue
+func (c *RedisClient) ensureGroup(ctx context.Context, stream string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ensured[stream] {
+		return nil
+	}
+
+	err := c.client.XGroupCreateMkStream(ctx, stream, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on %s: %w", c.group, stream, err)
+	}
+	c.ensured[stream] = true
+	return nil
+}
+
+// Receive returns the next entry from the specified stream, reclaiming stalled entries first
+func (c *RedisClient) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	if err := c.ensureGroup(ctx, queueName); err != nil {
+		return nil, err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		msg, err := c.reclaim(ctx, queueName)
+		if err != nil {
+			return nil, err
+		}
+		if msg != nil {
+			return msg, nil
+		}
+
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.consumer,
+			Streams:  []string{queueName, ">"},
+			Count:    1,
+			Block:    c.blockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to read from stream %s: %w", queueName, err)
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				return newRedisMessage(queueName, entry, 1), nil
+			}
+		}
+	}
+}
+
+// reclaim takes over one stalled entry; nil if none is available
+func (c *RedisClient) reclaim(ctx context.Context, queueName string) (*redisMessage, error) {
+	for {
+		entries, _, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   queueName,
+			Group:    c.group,
+			Consumer: c.consumer,
+			MinIdle:  c.visibilityTimeout,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to reclaim from stream %s: %w", queueName, err)
+		}
+		if len(entries) == 0 {
+			return nil, nil
+		}
+		entry := entries[0]
+
+		deliveries := int64(1)
+		pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: queueName,
+			Group:  c.group,
+			Start:  entry.ID,
+			End:    entry.ID,
+			Count:  1,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect pending entry %s: %w", entry.ID, err)
+		}
+		if len(pending) > 0 {
+			deliveries = pending[0].RetryCount
+		}
+
+		body, ok := entry.Values[redisBodyField]
+		if !ok || deliveries > int64(c.maxDeliveries) {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter stream", "queue", queueName, "id", entry.ID)
+			pipe := c.client.TxPipeline()
+			if ok {
+				pipe.XAdd(ctx, &redis.XAddArgs{
+					Stream: queueName + redisDeadSuffix,
+					Values: map[string]interface{}{redisBodyField: body, "source_id": entry.ID},
+				})
+			}
+			pipe.XAck(ctx, queueName, c.group, entry.ID)
+			pipe.XDel(ctx, queueName, entry.ID)
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, fmt.Errorf("failed to dead-letter entry %s: %w", entry.ID, err)
+			}
+			continue
+		}
+
+		return newRedisMessage(queueName, entry, deliveries), nil
+	}
+}
+
+func newRedisMessage(stream string, entry redis.XMessage, deliveries int64) *redisMessage {
+	var body []byte
+	switch v := entry.Values[redisBodyField].(type) {
+	case string:
+		body = []byte(v)
+	case []byte:
+		body = v
+	}
+	return &redisMessage{stream: stream, id: entry.ID, body: body, deliveries: deliveries}
+}
+
+// Ack acknowledges and deletes a stream entry
+func (c *RedisClient) Ack(ctx context.Context, msg QueueMessage) error {
+	redisMsg, ok := msg.(*redisMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *redisMessage")
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.XAck(ctx, redisMsg.stream, c.group, redisMsg.id)
+	pipe.XDel(ctx, redisMsg.stream, redisMsg.id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack marks a delivered entry as idle for the full visibility timeout so the next
+// XAUTOCLAIM picks it up immediately. Redis Streams have no native negative ack.
+func (c *RedisClient) Nack(ctx context.Context, msg QueueMessage) error {
+	redisMsg, ok := msg.(*redisMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *redisMessage")
+	}
+
+	err := c.client.Do(ctx, "XCLAIM", redisMsg.stream, c.group, c.consumer, 0, redisMsg.id,
+		"IDLE", c.visibilityTimeout.Milliseconds(),
+		"RETRYCOUNT", redisMsg.deliveries,
+		"JUSTID").Err()
+	if err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis client
+func (c *RedisClient) Close() error {
+	return c.client.Close()
+}
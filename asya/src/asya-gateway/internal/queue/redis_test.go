@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedisClient connects to ASYA_TEST_REDIS_URL when set, otherwise to an in-process miniredis
+func newTestRedisClient(t *testing.T, visibility time.Duration) *RedisClient {
+	t.Helper()
+
+	url := os.Getenv("ASYA_TEST_REDIS_URL")
+	if url == "" {
+		mr := miniredis.RunT(t)
+		url = "redis://" + mr.Addr() + "/0"
+	}
+
+	client, err := NewRedisClient(context.Background(), RedisConfig{
+		URL:               url,
+		Namespace:         "redistest",
+		Group:             "asya-test",
+		VisibilityTimeout: visibility,
+		BlockTimeout:      20 * time.Millisecond,
+		MaxDeliveries:     1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestRedisClient_SendEnvelopeAndReceive(t *testing.T) {
+	client := newTestRedisClient(t, time.Minute)
+	ctx := context.Background()
+	queue := "asya-redistest-writer"
+	t.Cleanup(func() { _ = client.client.Del(context.Background(), queue).Err() })
+
+	deadline := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := client.SendEnvelope(ctx, &types.Envelope{
+		ID:       "env-1",
+		Route:    types.Route{Actors: []string{"parser", "writer"}, Current: 1},
+		Payload:  map[string]any{"k": "v"},
+		Deadline: deadline,
+	})
+	require.NoError(t, err)
+
+	recvCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	msg, err := client.Receive(recvCtx, queue)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), msg.DeliveryTag())
+
+	var env ActorEnvelope
+	require.NoError(t, json.Unmarshal(msg.Body(), &env))
+	assert.Equal(t, "env-1", env.ID)
+	assert.Equal(t, "2025-01-02T03:04:05Z", env.Deadline)
+
+	require.NoError(t, client.Ack(ctx, msg))
+	assert.Equal(t, int64(0), client.client.XLen(ctx, queue).Val())
+}
+
+func TestRedisClient_SendEnvelope_InvalidRoute(t *testing.T) {
+	client := newTestRedisClient(t, time.Minute)
+
+	err := client.SendEnvelope(context.Background(), &types.Envelope{ID: "env-1"})
+	assert.ErrorContains(t, err, "route has no actors")
+
+	err = client.SendEnvelope(context.Background(), &types.Envelope{
+		ID:    "env-1",
+		Route: types.Route{Actors: []string{"a"}, Current: 2},
+	})
+	assert.ErrorContains(t, err, "invalid route.current")
+}
+
+func TestRedisClient_ReclaimAndDeadLetter(t *testing.T) {
+	visibility := 50 * time.Millisecond
+	client := newTestRedisClient(t, visibility)
+	ctx := context.Background()
+	queue := "asya-redistest-reclaim"
+	t.Cleanup(func() { _ = client.client.Del(context.Background(), queue, queue+redisDeadSuffix).Err() })
+
+	require.NoError(t, client.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: queue,
+		Values: map[string]interface{}{redisBodyField: "stalled"},
+	}).Err())
+
+	recvCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err := client.Receive(recvCtx, queue)
+	require.NoError(t, err)
+
+	// Not acked: after the visibility timeout the second delivery exceeds MaxDeliveries=1
+	time.Sleep(2 * visibility)
+	shortCtx, cancelShort := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelShort()
+	_, err = client.Receive(shortCtx, queue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	dead, err := client.client.XRange(ctx, queue+redisDeadSuffix, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "stalled", dead[0].Values[redisBodyField])
+}
+
+func TestRedisClient_AckInvalidMessage(t *testing.T) {
+	client := newTestRedisClient(t, time.Minute)
+	err := client.Ack(context.Background(), &fileMessage{})
+	assert.ErrorContains(t, err, "invalid message type")
+}
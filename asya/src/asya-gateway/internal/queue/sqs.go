@@ -2,9 +2,14 @@ package queue
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
 	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+	"github.com/deliveryhero/asya/asya-naming"
 )
 
 // Queue Naming Convention (All Transports):
@@ -31,11 +37,15 @@ import (
 // This maintains consistent queue naming across all transport implementations and
 // provides namespace isolation for multi-tenant deployments.
 
+// sqsMessageIDPattern matches values SQS accepts as FIFO message group and deduplication IDs
+var sqsMessageIDPattern = regexp.MustCompile(`^[!-~]{1,128}$`)
+
 // sqsClient defines the interface for SQS operations
 type sqsClient interface {
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
 }
 
@@ -44,9 +54,12 @@ type SQSClient struct {
 	client            sqsClient
 	region            string
 	namespace         string
+	naming            naming.Scheme
 	baseURL           string
 	visibilityTimeout int32
 	waitTimeSeconds   int32
+	fifo              bool
+	messageGroup      string
 	queueURLCache     map[string]string
 }
 
@@ -55,12 +68,19 @@ type SQSConfig struct {
 	Region            string
 	Endpoint          string
 	Namespace         string
+	Naming            naming.Scheme // Queue naming scheme shared with sidecars (zero value is the default)
 	VisibilityTimeout int32
 	WaitTimeSeconds   int32
+	FIFO              bool   // Use FIFO queues (queue names end in ".fifo")
+	MessageGroup      string // Envelope field FIFO messages are grouped by: "headers.<key>" or "payload.<path>" (empty groups by envelope ID)
 }
 
 // NewSQSClient creates a new SQS client
 func NewSQSClient(ctx context.Context, cfg SQSConfig) (*SQSClient, error) {
+	if err := ValidateMessageGroup(cfg.MessageGroup); err != nil {
+		return nil, err
+	}
+
 	// Load AWS config with IRSA support (pod identity)
 	loadOptions := []func(*config.LoadOptions) error{
 		config.WithRegion(cfg.Region),
@@ -96,13 +116,29 @@ func NewSQSClient(ctx context.Context, cfg SQSConfig) (*SQSClient, error) {
 		client:            client,
 		region:            cfg.Region,
 		namespace:         cfg.Namespace,
+		naming:            cfg.Naming,
 		baseURL:           cfg.Endpoint,
 		visibilityTimeout: visibilityTimeout,
 		waitTimeSeconds:   waitTimeSeconds,
+		fifo:              cfg.FIFO,
+		messageGroup:      cfg.MessageGroup,
 		queueURLCache:     make(map[string]string),
 	}, nil
 }
 
+// ValidateMessageGroup checks that a FIFO message group field is empty, "headers.<key>" or "payload.<path>".
+// Mirrors transport.ValidateMessageGroup in asya-sidecar.
+func ValidateMessageGroup(field string) error {
+	if field == "" {
+		return nil
+	}
+	source, path, _ := strings.Cut(field, ".")
+	if (source != "headers" && source != "payload") || path == "" || slices.Contains(strings.Split(path, "."), "") {
+		return fmt.Errorf("invalid message group %q: must be headers.<key> or payload.<path>", field)
+	}
+	return nil
+}
+
 // resolveQueueURL resolves the full queue URL from queue name using GetQueueUrl API
 func (c *SQSClient) resolveQueueURL(ctx context.Context, queueName string) (string, error) {
 	// Check cache first
@@ -113,9 +149,14 @@ func (c *SQSClient) resolveQueueURL(ctx context.Context, queueName string) (stri
 
 	slog.Debug("Resolving SQS queue URL", "queue", queueName, "baseURL", c.baseURL)
 
+	sqsQueueName := queueName
+	if c.fifo {
+		sqsQueueName = naming.FIFOQueueName(queueName)
+	}
+
 	// Use GetQueueUrl API for dynamic resolution
 	result, err := c.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName),
+		QueueName: aws.String(sqsQueueName),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve queue URL for %s: %w", queueName, err)
@@ -176,16 +217,7 @@ func (c *SQSClient) SendEnvelope(ctx context.Context, envelope *types.Envelope)
 	}
 
 	// Create actor envelope
-	msg := ActorEnvelope{
-		ID:      envelope.ID,
-		Route:   envelope.Route,
-		Payload: envelope.Payload,
-	}
-
-	// Add deadline if envelope has timeout
-	if !envelope.Deadline.IsZero() {
-		msg.Deadline = envelope.Deadline.Format("2006-01-02T15:04:05Z07:00")
-	}
+	msg := newActorEnvelope(envelope)
 
 	// Marshal to JSON
 	body, err := json.Marshal(msg)
@@ -194,9 +226,9 @@ func (c *SQSClient) SendEnvelope(ctx context.Context, envelope *types.Envelope)
 	}
 
 	// Get queue URL for current actor
-	// Add "asya-{namespace}-" prefix to convert actor name to queue name
+	// Convert actor reference to its queue name (qualified references name the namespace)
 	actorName := envelope.Route.Actors[envelope.Route.Current]
-	queueName := fmt.Sprintf("asya-%s-%s", c.namespace, actorName)
+	queueName := actorQueueName(c.naming, c.namespace, actorName)
 	queueURL, err := c.resolveQueueURL(ctx, queueName)
 	if err != nil {
 		return fmt.Errorf("failed to resolve queue URL: %w", err)
@@ -205,10 +237,16 @@ func (c *SQSClient) SendEnvelope(ctx context.Context, envelope *types.Envelope)
 	slog.Info("Sending envelope to SQS", "envelopeID", envelope.ID, "queue", queueName, "queueURL", queueURL)
 
 	// Send message to SQS
-	_, err = c.client.SendMessage(ctx, &sqs.SendMessageInput{
+	input := &sqs.SendMessageInput{
 		QueueUrl:    aws.String(queueURL),
 		MessageBody: aws.String(string(body)),
-	})
+	}
+	if c.fifo {
+		groupID, deduplicationID := c.fifoMessageIDs(envelope)
+		input.MessageGroupId = aws.String(groupID)
+		input.MessageDeduplicationId = aws.String(deduplicationID)
+	}
+	_, err = c.client.SendMessage(ctx, input)
 	if err != nil {
 		slog.Error("Failed to send to SQS", "envelopeID", envelope.ID, "queue", queueName, "queueURL", queueURL, "error", err)
 		return fmt.Errorf("failed to send to SQS: %w", err)
@@ -218,6 +256,70 @@ func (c *SQSClient) SendEnvelope(ctx context.Context, envelope *types.Envelope)
 	return nil
 }
 
+// fifoMessageIDs returns the message group and deduplication IDs of an envelope sent to a FIFO queue.
+// Envelopes are grouped by the message group field, falling back to the envelope ID, and deduplicated
+// by envelope ID and hop count like sidecars do (envelopes sent by the gateway are on their first attempt).
+func (c *SQSClient) fifoMessageIDs(envelope *types.Envelope) (groupID, deduplicationID string) {
+	groupID = envelope.ID
+	if value, ok := envelopeField(envelope, c.messageGroup); ok {
+		groupID = value
+	} else if c.messageGroup != "" {
+		slog.Warn("FIFO message group field not found, grouping by envelope ID", "envelopeID", envelope.ID, "field", c.messageGroup)
+	}
+	if !sqsMessageIDPattern.MatchString(groupID) {
+		groupID = hashMessageID(groupID)
+	}
+	return groupID, hashMessageID(fmt.Sprintf("%s/%d/%d", envelope.ID, envelope.HopCount, 1))
+}
+
+// envelopeField returns the scalar value at a "headers.<key>" or "payload.<path>" field of an envelope
+func envelopeField(envelope *types.Envelope, field string) (string, bool) {
+	source, path, ok := strings.Cut(field, ".")
+	if !ok {
+		return "", false
+	}
+
+	var value any
+	switch source {
+	case "headers":
+		value = envelope.Headers
+	case "payload":
+		value = envelope.Payload
+	default:
+		return "", false
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// hashMessageID returns a hex SHA-256 digest of value, valid as a FIFO message group or deduplication ID
+func hashMessageID(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 // Receive receives a message from the specified queue
 func (c *SQSClient) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
 	queueURL, err := c.resolveQueueURL(ctx, queueName)
@@ -277,6 +379,26 @@ func (c *SQSClient) Ack(ctx context.Context, msg QueueMessage) error {
 	return nil
 }
 
+// Nack negatively acknowledges a message by setting its visibility timeout to 0,
+// making it immediately available for redelivery
+func (c *SQSClient) Nack(ctx context.Context, msg QueueMessage) error {
+	sqsMsg, ok := msg.(*sqsMessage)
+	if !ok {
+		return fmt.Errorf("invalid message type: expected *sqsMessage")
+	}
+
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(sqsMsg.queueURL),
+		ReceiptHandle:     aws.String(sqsMsg.receiptHandle),
+		VisibilityTimeout: 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the SQS client (no-op for SQS)
 func (c *SQSClient) Close() error {
 	return nil
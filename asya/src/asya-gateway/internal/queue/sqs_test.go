@@ -40,6 +40,14 @@ func (m *mockSQSClient) DeleteMessage(ctx context.Context, params *sqs.DeleteMes
 	return args.Get(0).(*sqs.DeleteMessageOutput), args.Error(1)
 }
 
+func (m *mockSQSClient) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sqs.ChangeMessageVisibilityOutput), args.Error(1)
+}
+
 func (m *mockSQSClient) GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
@@ -71,6 +79,11 @@ func TestSQSQueueNaming(t *testing.T) {
 			actorName:     "happy-end",
 			expectedQueue: "asya-default-happy-end",
 		},
+		{
+			name:          "qualified actor in another namespace",
+			actorName:     "team-b/ocr",
+			expectedQueue: "asya-team-b-ocr",
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,6 +127,90 @@ func TestSQSQueueNaming(t *testing.T) {
 	}
 }
 
+// TestSQSFIFO tests that FIFO envelopes go to ".fifo" queues with a message group and deduplication ID
+func TestSQSFIFO(t *testing.T) {
+	tests := []struct {
+		name         string
+		messageGroup string
+		envelope     *types.Envelope
+		wantGroup    string
+	}{
+		{
+			name:         "grouped by header",
+			messageGroup: "headers.customer_id",
+			envelope: &types.Envelope{
+				ID:      "env-1",
+				Route:   types.Route{Actors: []string{"billing"}},
+				Headers: map[string]interface{}{"customer_id": "c-42"},
+				Payload: map[string]interface{}{},
+			},
+			wantGroup: "c-42",
+		},
+		{
+			name:         "grouped by payload path",
+			messageGroup: "payload.customer.id",
+			envelope: &types.Envelope{
+				ID:      "env-1",
+				Route:   types.Route{Actors: []string{"billing"}},
+				Payload: map[string]interface{}{"customer": map[string]interface{}{"id": float64(42)}},
+			},
+			wantGroup: "42",
+		},
+		{
+			name:         "missing field groups by envelope ID",
+			messageGroup: "headers.customer_id",
+			envelope: &types.Envelope{
+				ID:      "env-1",
+				Route:   types.Route{Actors: []string{"billing"}},
+				Payload: map[string]interface{}{},
+			},
+			wantGroup: "env-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(mockSQSClient)
+			mockClient.On("GetQueueUrl", mock.Anything, mock.MatchedBy(func(params *sqs.GetQueueUrlInput) bool {
+				return *params.QueueName == "asya-default-billing.fifo"
+			})).Return(&sqs.GetQueueUrlOutput{
+				QueueUrl: stringPtr("https://sqs.us-east-1.amazonaws.com/000000000000/asya-default-billing.fifo"),
+			}, nil)
+
+			var sent *sqs.SendMessageInput
+			mockClient.On("SendMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				sent = args.Get(1).(*sqs.SendMessageInput)
+			}).Return(&sqs.SendMessageOutput{}, nil)
+
+			sqsClient := &SQSClient{
+				client:        mockClient,
+				region:        "us-east-1",
+				namespace:     "default",
+				fifo:          true,
+				messageGroup:  tt.messageGroup,
+				queueURLCache: make(map[string]string),
+			}
+
+			assert.NoError(t, sqsClient.SendEnvelope(context.Background(), tt.envelope))
+			mockClient.AssertExpectations(t)
+
+			if assert.NotNil(t, sent) {
+				assert.Equal(t, tt.wantGroup, *sent.MessageGroupId)
+				assert.Len(t, *sent.MessageDeduplicationId, 64)
+			}
+		})
+	}
+}
+
+func TestValidateMessageGroup(t *testing.T) {
+	for _, field := range []string{"", "headers.customer_id", "payload.customer.id"} {
+		assert.NoError(t, ValidateMessageGroup(field), field)
+	}
+	for _, field := range []string{"customer_id", "headers.", "payload.customer..id", "route.current"} {
+		assert.Error(t, ValidateMessageGroup(field), field)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
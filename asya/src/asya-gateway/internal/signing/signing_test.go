@@ -0,0 +1,220 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deliveryhero/asya/asya-gateway/internal/config"
+	"github.com/deliveryhero/asya/asya-gateway/internal/encryption"
+	"github.com/deliveryhero/asya/asya-gateway/internal/queue"
+	"github.com/deliveryhero/asya/asya-gateway/pkg/types"
+)
+
+func newTestSigner(t *testing.T, keyByte byte) *Signer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	data, _ := json.Marshal(map[string]any{
+		"active": "k1",
+		"keys":   map[string]string{"k1": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{keyByte}, encryption.KeySize))},
+	})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	provider, err := encryption.NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return NewSigner(provider)
+}
+
+func testEnvelope() *types.Envelope {
+	return &types.Envelope{
+		ID:      "env-1",
+		Route:   types.Route{Actors: []string{"validate", "charge"}, Current: 1},
+		Headers: map[string]interface{}{"trace_id": "abc"},
+		Payload: map[string]any{"amount": 10},
+	}
+}
+
+// TestSigner_KnownSignature uses the same vector as the sidecar's signing tests,
+// so envelopes signed by the gateway verify in sidecars
+func TestSigner_KnownSignature(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	envelope := testEnvelope()
+	if err := signer.Sign(context.Background(), envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:L27gWnOpjLx8xI/5zUghIZ7Y/ZPcu5A/tnRbwz5dFEc="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+// TestSigner_KnownSignatureWithCallStack signs an envelope resumed after an approval step, with the
+// call stack as the sidecar sent it. Uses the same vector as the sidecar's signing tests.
+func TestSigner_KnownSignatureWithCallStack(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	envelope := testEnvelope()
+	envelope.HopCount = 2
+	envelope.Visited = []string{"validate", "review"}
+	envelope.CallStack = json.RawMessage(`[{"caller":"order","route":{"actors":["order","ship"],"current":1}}]`)
+	if err := signer.Sign(context.Background(), envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:1SYgNgRiE95qyKnokQihiIxcuYVRDj5/q6rt+KKB0Og="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+// TestSigner_KnownSignatureWithMetadata signs an envelope with the route metadata set from a tool's route.
+// Uses the same vector as the sidecar's signing tests.
+func TestSigner_KnownSignatureWithMetadata(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	envelope := testEnvelope()
+	envelope.Route.Metadata = map[string]interface{}{
+		"job_id":                        "env-1",
+		config.TimeoutsMetadataKey:      map[string]interface{}{"charge": "30s"},
+		config.CompensationsMetadataKey: map[string]interface{}{"charge": "refund"},
+	}
+	if err := signer.Sign(context.Background(), envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:Sin5KeJP07ndrDBNaO41dU+qeKP7QE1kKgUsBtNc9Mw="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+
+	tampered := *envelope
+	tampered.Route.Metadata = map[string]interface{}{
+		"job_id":                        "env-1",
+		config.TimeoutsMetadataKey:      map[string]interface{}{"charge": "30s"},
+		config.CompensationsMetadataKey: map[string]interface{}{"charge": "payout"},
+	}
+	if err := signer.Verify(context.Background(), tampered); err == nil {
+		t.Error("Verify accepted envelope with tampered compensations")
+	}
+
+	tampered.Route.Metadata = map[string]interface{}{
+		"job_id":                        "env-1",
+		config.CompensationsMetadataKey: map[string]interface{}{"charge": "refund"},
+	}
+	if err := signer.Verify(context.Background(), tampered); err == nil {
+		t.Error("Verify accepted envelope with removed timeouts")
+	}
+}
+
+// TestSigner_KnownSignatureWithParentAndHeaders signs a fan-out child carrying the signed headers.
+// Uses the same vector as the sidecar's signing tests.
+func TestSigner_KnownSignatureWithParentAndHeaders(t *testing.T) {
+	signer := newTestSigner(t, 1)
+
+	envelope := testEnvelope()
+	parent := "env-0"
+	envelope.ParentID = &parent
+	envelope.Headers = map[string]interface{}{"trace_id": "abc", "asya_attempt": 3, encryption.KeyIDHeader: "k1"}
+	if err := signer.Sign(context.Background(), envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:o4JbBf/v3DPHJq67bfco0n1N5n9KLcu2rpvszMRwBwA="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+
+	tampered := *envelope
+	other := "env-9"
+	tampered.ParentID = &other
+	if err := signer.Verify(context.Background(), tampered); err == nil {
+		t.Error("Verify accepted envelope with tampered parent ID")
+	}
+
+	tampered = *envelope
+	tampered.Headers = map[string]interface{}{"trace_id": "abc", "asya_attempt": 3, encryption.KeyIDHeader: "k2", SignatureHeader: envelope.Headers[SignatureHeader]}
+	if err := signer.Verify(context.Background(), tampered); err == nil {
+		t.Error("Verify accepted envelope with swapped key ID")
+	}
+}
+
+func TestSigner_Verify(t *testing.T) {
+	signer := newTestSigner(t, 1)
+	ctx := context.Background()
+
+	envelope := testEnvelope()
+	if err := signer.Verify(ctx, *envelope); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Verify(unsigned) = %v, want ErrUnsigned", err)
+	}
+
+	if err := signer.Sign(ctx, envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := signer.Verify(ctx, *envelope); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	tampered := *envelope
+	tampered.Route = types.Route{Actors: []string{"charge"}, Current: 0}
+	if err := signer.Verify(ctx, tampered); err == nil {
+		t.Error("Verify accepted envelope with tampered route")
+	}
+
+	if err := newTestSigner(t, 2).Verify(ctx, *envelope); err == nil {
+		t.Error("Verify accepted envelope signed with a different key")
+	}
+}
+
+type recordingClient struct {
+	queue.Client
+	sent []*types.Envelope
+}
+
+func (c *recordingClient) SendEnvelope(ctx context.Context, envelope *types.Envelope) error {
+	c.sent = append(c.sent, envelope)
+	return nil
+}
+
+func TestClient_SendEnvelope(t *testing.T) {
+	signer := newTestSigner(t, 1)
+	inner := &recordingClient{}
+	client := NewClient(inner, signer)
+
+	envelope := testEnvelope()
+	envelope.Payload = map[string]any{"html": "<b>", "amount": 10}
+	if err := client.SendEnvelope(context.Background(), envelope); err != nil {
+		t.Fatalf("SendEnvelope failed: %v", err)
+	}
+
+	if _, ok := envelope.Headers[SignatureHeader]; ok {
+		t.Error("SendEnvelope modified the caller's envelope")
+	}
+	if len(inner.sent) != 1 {
+		t.Fatalf("Expected 1 sent envelope, got %d", len(inner.sent))
+	}
+
+	// Verify the envelope as a sidecar receives it: marshaled in the actor envelope format
+	body, _ := json.Marshal(queue.ActorEnvelope{
+		ID:      inner.sent[0].ID,
+		Route:   inner.sent[0].Route,
+		Headers: inner.sent[0].Headers,
+		Payload: inner.sent[0].Payload,
+	})
+	var received types.Envelope
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := signer.Verify(context.Background(), received); err != nil {
+		t.Errorf("Sent envelope signature invalid: %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
 
 // EnvelopeStatus represents the current state of an envelope (MCP-style lowercase)
 type EnvelopeStatus string
@@ -11,6 +16,13 @@ const (
 	EnvelopeStatusSucceeded EnvelopeStatus = "succeeded"
 	EnvelopeStatusFailed    EnvelopeStatus = "failed"
 	EnvelopeStatusUnknown   EnvelopeStatus = "unknown"
+
+	// EnvelopeStatusAwaitingApproval marks an envelope parked at an approval step until it is approved or rejected
+	EnvelopeStatusAwaitingApproval EnvelopeStatus = "awaiting_approval"
+
+	// EnvelopeStatusCompensating marks a failed envelope whose completed actors are being compensated
+	// before error-end reports the failure
+	EnvelopeStatusCompensating EnvelopeStatus = "compensating"
 )
 
 // Envelope represents an envelope in the system.
@@ -42,12 +54,19 @@ type Envelope struct {
 	Error            string                 `json:"error,omitempty"`
 	TimeoutSec       int                    `json:"timeout_seconds,omitempty"` // Total timeout in seconds
 	Deadline         time.Time              `json:"deadline,omitempty"`        // Absolute deadline
+	MaxHops          int                    `json:"max_hops,omitempty"`        // Maximum number of actors that may process the envelope (0 uses the sidecar default)
 	ProgressPercent  float64                `json:"progress_percent"`
 	CurrentActorIdx  int                    `json:"current_actor_idx"`
 	CurrentActorName string                 `json:"current_actor_name,omitempty"`
 	Message          string                 `json:"message,omitempty"` // Current progress message
 	ActorsCompleted  int                    `json:"actors_completed"`
 	TotalActors      int                    `json:"total_actors"`
+	Hops             []Hop                  `json:"hops,omitempty"`       // Per-hop execution history reported by the end actor
+	Attempt          int                    `json:"attempt,omitempty"`    // Attempt of the current actor, above 1 while retrying runtime errors
+	HopCount         int                    `json:"hop_count,omitempty"`  // Actors that processed the envelope (set when resuming after approval)
+	Visited          []string               `json:"visited,omitempty"`    // Actors that processed the envelope (set when resuming after approval)
+	CallStack        json.RawMessage        `json:"call_stack,omitempty"` // Sub-route continuations (set when resuming after approval)
+	Approval         *ApprovalRequest       `json:"approval,omitempty"`   // Parked envelope while awaiting approval
 	CreatedAt        time.Time              `json:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at"`
 }
@@ -59,6 +78,29 @@ type Route struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// SplitActorRef splits an actor reference from a route into namespace and actor name.
+// References are either a bare actor name, resolved in defaultNamespace, or qualified as "namespace/actor".
+// Mirrors envelopes.SplitActorRef in asya-sidecar.
+func SplitActorRef(ref, defaultNamespace string) (namespace, actor string) {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return ns, name
+	}
+	return defaultNamespace, ref
+}
+
+// Hop is one actor's execution record, appended by the sidecar as the envelope leaves the actor.
+// Mirrors envelopes.Hop in asya-sidecar.
+type Hop struct {
+	Actor             string     `json:"actor"`
+	Pod               string     `json:"pod,omitempty"`
+	ReceivedAt        time.Time  `json:"received_at"`
+	StartedAt         *time.Time `json:"started_at,omitempty"` // Nil when the runtime was never called
+	FinishedAt        time.Time  `json:"finished_at"`
+	RuntimeDurationMs int64      `json:"runtime_duration_ms"`
+	Attempt           int        `json:"attempt"`
+	Outcome           string     `json:"outcome"` // "succeeded" | "failed"
+}
+
 // EnvelopeUpdate represents an internal state change event for an envelope.
 //
 // INTERNAL USE: This type is used within the gateway for:
@@ -73,17 +115,21 @@ type Route struct {
 // Created by: Gateway handlers when processing ProgressUpdate (from sidecars) or
 // final status updates (from end actors like happy-end/error-end).
 type EnvelopeUpdate struct {
-	ID              string         `json:"id"`
-	Status          EnvelopeStatus `json:"status"`                      // Envelope status (pending/running/succeeded/failed)
-	Message         string         `json:"message,omitempty"`           // Human-readable status message
-	Result          any            `json:"result,omitempty"`            // Final result (only for final states)
-	Error           string         `json:"error,omitempty"`             // Error message (only for failed status)
-	ProgressPercent *float64       `json:"progress_percent,omitempty"`  // Progress 0-100 (nil if not a progress update)
-	Actor           string         `json:"actor,omitempty"`             // Current actor name (for progress updates)
-	Actors          []string       `json:"actors,omitempty"`            // Full route (may be modified by envelope-mode actors)
-	CurrentActorIdx *int           `json:"current_actor_idx,omitempty"` // Index of current actor (0-based, nil for non-progress updates)
-	EnvelopeState   *string        `json:"envelope_state,omitempty"`    // Envelope processing state at current actor: "received" | "processing" | "completed"
-	Timestamp       time.Time      `json:"timestamp"`                   // When this update occurred
+	ID              string           `json:"id"`
+	Status          EnvelopeStatus   `json:"status"`                      // Envelope status (pending/running/succeeded/failed)
+	Message         string           `json:"message,omitempty"`           // Human-readable status message
+	Result          any              `json:"result,omitempty"`            // Final result (only for final states)
+	Error           string           `json:"error,omitempty"`             // Error message (only for failed status)
+	ProgressPercent *float64         `json:"progress_percent,omitempty"`  // Progress 0-100 (nil if not a progress update)
+	Actor           string           `json:"actor,omitempty"`             // Current actor name (for progress updates)
+	Actors          []string         `json:"actors,omitempty"`            // Full route (may be modified by envelope-mode actors)
+	CurrentActorIdx *int             `json:"current_actor_idx,omitempty"` // Index of current actor (0-based, nil for non-progress updates)
+	EnvelopeState   *string          `json:"envelope_state,omitempty"`    // Envelope processing state at current actor: "received" | "processing" | "completed"
+	HopCount        *int             `json:"hop_count,omitempty"`         // Actors that processed the envelope before the current one (nil for non-progress updates)
+	Attempt         *int             `json:"attempt,omitempty"`           // Attempt of the current actor (nil when not reported)
+	Hops            []Hop            `json:"hops,omitempty"`              // Per-hop execution history (only for final states)
+	Approval        *ApprovalRequest `json:"approval,omitempty"`          // Parked envelope (only for awaiting_approval)
+	Timestamp       time.Time        `json:"timestamp"`                   // When this update occurred
 }
 
 // ProgressUpdate represents a progress report sent FROM sidecars TO the gateway.
@@ -114,9 +160,104 @@ type EnvelopeUpdate struct {
 // 3. "completed" - Runtime returned successful response
 type ProgressUpdate struct {
 	ID              string   `json:"id"`
-	Actors          []string `json:"actors"`            // Full route (may differ from original if actor modified it)
-	CurrentActorIdx int      `json:"current_actor_idx"` // Index of current actor being processed (0-based)
-	Status          string   `json:"status"`            // Actor status: "received" | "processing" | "completed"
-	Message         string   `json:"message,omitempty"` // Optional progress message
-	ProgressPercent float64  `json:"progress_percent"`  // Calculated by gateway based on actor progress
+	Actors          []string `json:"actors"`                 // Full route (may differ from original if actor modified it)
+	CurrentActorIdx int      `json:"current_actor_idx"`      // Index of current actor being processed (0-based)
+	Status          string   `json:"status"`                 // Actor status: "received" | "processing" | "completed"
+	Message         string   `json:"message,omitempty"`      // Optional progress message
+	ProgressPercent float64  `json:"progress_percent"`       // Calculated by gateway based on actor progress
+	HopCount        int      `json:"hop_count"`              // Actors that processed the envelope before the current one
+	Compensating    bool     `json:"compensating,omitempty"` // Reported by a compensating actor; Actors is the compensation route
+	Attempt         int      `json:"attempt,omitempty"`      // Attempt of the current actor when its retry policy retries runtime errors (1 for the first)
+}
+
+// ProgressBatch is the payload of POST /envelopes/progress/batch.
+// Sent by the sidecar's asynchronous progress reporter with the latest update per envelope.
+type ProgressBatch struct {
+	Updates []ProgressUpdate `json:"updates"`
+}
+
+// ProgressBatchResult reports the outcome of one update in a ProgressBatch
+type ProgressBatchResult struct {
+	ID              string  `json:"id"`
+	ProgressPercent float64 `json:"progress_percent"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// EnvelopeCreateRequest is the payload of POST /envelopes, sent by sidecars to register fanout children
+type EnvelopeCreateRequest struct {
+	ID       string   `json:"id"`
+	ParentID string   `json:"parent_id"`
+	Actors   []string `json:"actors"`
+	Current  int      `json:"current"`
+}
+
+// FinalStatusUpdate is the payload of POST /envelopes/{id}/final, sent by end actors (happy-end/error-end)
+type FinalStatusUpdate struct {
+	ID               string         `json:"id"`
+	Status           string         `json:"status"` // "succeeded" | "failed"
+	Progress         *float64       `json:"progress"`
+	Result           any            `json:"result"`
+	Error            string         `json:"error"`
+	ErrorDetails     any            `json:"error_details"`
+	Metadata         map[string]any `json:"metadata"`
+	Actors           []string       `json:"actors"`
+	CurrentActorIdx  *int           `json:"current_actor_idx"`
+	CurrentActorName string         `json:"current_actor_name"`
+	Hops             []Hop          `json:"hops,omitempty"`
+	Timestamp        string         `json:"timestamp"`
+
+	CompensationFailed []string `json:"compensation_failed,omitempty"` // Compensating actors that failed before error-end
+}
+
+// ApprovalRequest is the payload of POST /envelopes/{id}/approval, sent by the sidecar when an envelope
+// reaches an approval step. It holds the envelope to send once approved: Route already points at the
+// actor after the approval step. Mirrors envelopes.Envelope in asya-sidecar.
+type ApprovalRequest struct {
+	ID        string                 `json:"id"`
+	ParentID  *string                `json:"parent_id,omitempty"`
+	Step      string                 `json:"step"` // Approval step the envelope is parked at
+	Route     Route                  `json:"route"`
+	Headers   map[string]interface{} `json:"headers,omitempty"`
+	Payload   json.RawMessage        `json:"payload"` // Kept as sent, so its signature can be verified
+	HopCount  int                    `json:"hop_count,omitempty"`
+	Visited   []string               `json:"visited,omitempty"`
+	MaxHops   int                    `json:"max_hops,omitempty"`
+	Hops      []Hop                  `json:"hops,omitempty"`
+	CallStack json.RawMessage        `json:"call_stack,omitempty"` // Passed through unchanged
+}
+
+// ApprovalDecision is the optional payload of POST /envelopes/{id}/approve and /envelopes/{id}/reject
+type ApprovalDecision struct {
+	Payload json.RawMessage `json:"payload,omitempty"` // Approve only: replaces the parked payload
+	Reason  string          `json:"reason,omitempty"`  // Recorded in the envelope message (approve) or error (reject)
+}
+
+// Status event types
+const (
+	StatusEventProgress = "progress"
+	StatusEventFinal    = "final"
+	StatusEventCreate   = "create"
+	StatusEventApproval = "approval"
+)
+
+// ErrInvalidStatusEvent marks status events that cannot be applied however often they are redelivered,
+// e.g. malformed events or events with an invalid signature
+var ErrInvalidStatusEvent = errors.New("invalid status event")
+
+// ErrStatusEventTooEarly marks status events for envelopes the gateway does not know yet. The event may
+// have overtaken the one creating its envelope, so it is worth retrying a few times.
+var ErrStatusEventTooEarly = errors.New("status event for unknown envelope")
+
+// StatusEvent is consumed by the gateway from the status queue (ASYA_STATUS_QUEUE).
+// Sidecars publish these instead of calling the progress, final and create endpoints over HTTP,
+// so actors do not need to reach the gateway. The field matching Type carries the payload.
+type StatusEvent struct {
+	Type      string                 `json:"type"` // "progress" | "final" | "create" | "approval"
+	ID        string                 `json:"id"`
+	Actor     string                 `json:"actor,omitempty"` // Reporting actor
+	Timestamp time.Time              `json:"timestamp"`
+	Progress  *ProgressUpdate        `json:"progress,omitempty"`
+	Final     *FinalStatusUpdate     `json:"final,omitempty"`
+	Create    *EnvelopeCreateRequest `json:"create,omitempty"`
+	Approval  *ApprovalRequest       `json:"approval,omitempty"`
 }
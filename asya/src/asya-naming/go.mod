@@ -0,0 +1,3 @@
+module github.com/deliveryhero/asya/asya-naming
+
+go 1.24.0
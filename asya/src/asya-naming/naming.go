@@ -0,0 +1,130 @@
+// Package naming builds transport queue names for actors.
+//
+// Queue names follow {prefix}-{cluster}-{namespace}-{actor}-{suffix}. The cluster and suffix
+// segments are omitted when empty, so the default scheme yields asya-{namespace}-{actor}.
+//
+// The sidecar, gateway and operator all import this module, so they produce identical names. It has
+// no dependencies and is resolved through a replace directive in each of their go.mod files.
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultPrefix is the prefix of queue names when none is configured
+const DefaultPrefix = "asya"
+
+// Environment variables configuring the scheme, rendered into sidecars by the operator
+const (
+	EnvPrefix  = "ASYA_QUEUE_PREFIX"
+	EnvCluster = "ASYA_CLUSTER_NAME"
+	EnvSuffix  = "ASYA_QUEUE_SUFFIX"
+)
+
+// FIFOSuffix ends the names of SQS FIFO queues. SQS clients append it to queue names when FIFO queues are enabled.
+const FIFOSuffix = ".fifo"
+
+// TransportSQSFIFO validates queue names as SQS FIFO queues, whose names carry FIFOSuffix
+const TransportSQSFIFO = "sqs-fifo"
+
+// segmentPattern allows only characters valid in queue names of every transport
+var segmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// Scheme configures queue names. The zero value is the default scheme.
+type Scheme struct {
+	Prefix  string // Defaults to DefaultPrefix
+	Cluster string // Optional, keeps clusters sharing a broker apart
+	Suffix  string // Optional
+}
+
+// New returns a scheme after checking that each segment is valid in queue names of every transport
+func New(prefix, cluster, suffix string) (Scheme, error) {
+	segments := []struct{ name, value string }{
+		{EnvPrefix, prefix},
+		{EnvCluster, cluster},
+		{EnvSuffix, suffix},
+	}
+	for _, segment := range segments {
+		if !segmentPattern.MatchString(segment.value) {
+			return Scheme{}, fmt.Errorf("%s %q may only contain letters, digits, '-' and '_'", segment.name, segment.value)
+		}
+	}
+	return Scheme{Prefix: prefix, Cluster: cluster, Suffix: suffix}, nil
+}
+
+// QueueName returns the queue name of actor in namespace
+func (s Scheme) QueueName(namespace, actor string) string {
+	return s.NamespacePrefix(namespace) + actor + s.suffix()
+}
+
+// NamespacePrefix returns the part of the namespace's queue names before the actor name
+func (s Scheme) NamespacePrefix(namespace string) string {
+	return s.clusterPrefix() + namespace + "-"
+}
+
+// SharedQueueName returns the name of a queue shared by all namespaces, such as a dead-letter queue
+func (s Scheme) SharedQueueName(name string) string {
+	return s.clusterPrefix() + name + s.suffix()
+}
+
+func (s Scheme) clusterPrefix() string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	if s.Cluster != "" {
+		return prefix + "-" + s.Cluster + "-"
+	}
+	return prefix + "-"
+}
+
+func (s Scheme) suffix() string {
+	if s.Suffix != "" {
+		return "-" + s.Suffix
+	}
+	return ""
+}
+
+// FIFOQueueName returns the SQS FIFO queue name of a queue
+func FIFOQueueName(name string) string {
+	if strings.HasSuffix(name, FIFOSuffix) {
+		return name
+	}
+	return name + FIFOSuffix
+}
+
+// transportLimits are the queue name limits of transports that have them
+var transportLimits = map[string]struct {
+	maxLength int
+	pattern   *regexp.Regexp
+	rule      string
+}{
+	"sqs":      {maxLength: 80, pattern: regexp.MustCompile(`^[A-Za-z0-9_-]+(\.fifo)?$`), rule: "letters, digits, '-' and '_', optionally ending in '.fifo'"},
+	"rabbitmq": {maxLength: 255},
+	"pubsub":   {maxLength: 255, pattern: regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._~+%-]{2,}$`), rule: "at least 3 characters starting with a letter"},
+	"file":     {maxLength: 255, pattern: regexp.MustCompile(`^[^/]+$`), rule: "no '/'"},
+}
+
+// ValidateQueueName checks name against the queue name limits of transport.
+// Transports without limits (postgres, redis) accept any name.
+func ValidateQueueName(transport, name string) error {
+	if transport == TransportSQSFIFO {
+		transport, name = "sqs", FIFOQueueName(name)
+	}
+	limits, ok := transportLimits[transport]
+	if !ok {
+		return nil
+	}
+	if len(name) > limits.maxLength {
+		return fmt.Errorf("%s queue name %q is %d characters, exceeding the limit of %d", transport, name, len(name), limits.maxLength)
+	}
+	if limits.pattern != nil && !limits.pattern.MatchString(name) {
+		return fmt.Errorf("%s queue name %q is invalid: must be %s", transport, name, limits.rule)
+	}
+	if transport == "pubsub" && strings.HasPrefix(name, "goog") {
+		return fmt.Errorf("pubsub queue name %q must not start with \"goog\"", name)
+	}
+	return nil
+}
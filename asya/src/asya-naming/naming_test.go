@@ -0,0 +1,120 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheme_QueueName(t *testing.T) {
+	tests := []struct {
+		name            string
+		scheme          Scheme
+		namespace       string
+		actor           string
+		expectedQueue   string
+		expectedPrefix  string
+		expectedDLQName string
+	}{
+		{
+			name:            "zero value is the default scheme",
+			namespace:       "default",
+			actor:           "ocr",
+			expectedQueue:   "asya-default-ocr",
+			expectedPrefix:  "asya-default-",
+			expectedDLQName: "asya-dlq",
+		},
+		{
+			name:            "custom prefix",
+			scheme:          Scheme{Prefix: "acme"},
+			namespace:       "team-a",
+			actor:           "ocr",
+			expectedQueue:   "acme-team-a-ocr",
+			expectedPrefix:  "acme-team-a-",
+			expectedDLQName: "acme-dlq",
+		},
+		{
+			name:            "cluster and suffix",
+			scheme:          Scheme{Prefix: "acme", Cluster: "eu1", Suffix: "q"},
+			namespace:       "team-a",
+			actor:           "ocr",
+			expectedQueue:   "acme-eu1-team-a-ocr-q",
+			expectedPrefix:  "acme-eu1-team-a-",
+			expectedDLQName: "acme-eu1-dlq-q",
+		},
+		{
+			name:            "suffix only",
+			scheme:          Scheme{Suffix: "prod"},
+			namespace:       "default",
+			actor:           "happy-end",
+			expectedQueue:   "asya-default-happy-end-prod",
+			expectedPrefix:  "asya-default-",
+			expectedDLQName: "asya-dlq-prod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scheme.QueueName(tt.namespace, tt.actor); got != tt.expectedQueue {
+				t.Errorf("QueueName() = %q, want %q", got, tt.expectedQueue)
+			}
+			if got := tt.scheme.NamespacePrefix(tt.namespace); got != tt.expectedPrefix {
+				t.Errorf("NamespacePrefix() = %q, want %q", got, tt.expectedPrefix)
+			}
+			if got := tt.scheme.SharedQueueName("dlq"); got != tt.expectedDLQName {
+				t.Errorf("SharedQueueName() = %q, want %q", got, tt.expectedDLQName)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("acme", "eu_1", "q-1"); err != nil {
+		t.Errorf("Expected valid scheme, got %v", err)
+	}
+	if _, err := New("acme.corp", "", ""); err == nil || !strings.Contains(err.Error(), EnvPrefix) {
+		t.Errorf("Expected invalid prefix error, got %v", err)
+	}
+	if _, err := New("acme", "eu/1", ""); err == nil || !strings.Contains(err.Error(), EnvCluster) {
+		t.Errorf("Expected invalid cluster error, got %v", err)
+	}
+}
+
+func TestValidateQueueName(t *testing.T) {
+	tests := []struct {
+		transport string
+		queue     string
+		wantErr   string
+	}{
+		{transport: "sqs", queue: "asya-default-ocr"},
+		{transport: "sqs", queue: strings.Repeat("a", 80)},
+		{transport: "sqs", queue: strings.Repeat("a", 81), wantErr: "exceeding the limit of 80"},
+		{transport: "sqs", queue: "asya-default-ocr.v2", wantErr: "is invalid"},
+		{transport: "sqs", queue: "asya-default-ocr.fifo"},
+		{transport: TransportSQSFIFO, queue: "asya-default-ocr"},
+		{transport: TransportSQSFIFO, queue: strings.Repeat("a", 75)},
+		{transport: TransportSQSFIFO, queue: strings.Repeat("a", 76), wantErr: "exceeding the limit of 80"},
+		{transport: "rabbitmq", queue: strings.Repeat("a", 255)},
+		{transport: "rabbitmq", queue: strings.Repeat("a", 256), wantErr: "exceeding the limit of 255"},
+		{transport: "pubsub", queue: "asya-default-ocr"},
+		{transport: "pubsub", queue: "1-default-ocr", wantErr: "is invalid"},
+		{transport: "pubsub", queue: "goog-default-ocr", wantErr: "must not start with"},
+		{transport: "file", queue: "asya-default-ocr"},
+		{transport: "postgres", queue: strings.Repeat("a", 300)},
+		{transport: "redis", queue: strings.Repeat("a", 300)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transport+"/"+tt.queue[:min(len(tt.queue), 20)], func(t *testing.T) {
+			err := ValidateQueueName(tt.transport, tt.queue)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -20,6 +20,22 @@ type AsyncActorSpec struct {
 	// +optional
 	Timeout TimeoutConfig `json:"timeout,omitempty"`
 
+	// Route policy enforced by the sidecar on routes returned by the runtime
+	// +optional
+	RoutePolicy *RoutePolicyConfig `json:"routePolicy,omitempty"`
+
+	// Retry policy applied by the sidecar to runtime errors before sending them to error-end
+	// +optional
+	RetryPolicy *RetryPolicyConfig `json:"retryPolicy,omitempty"`
+
+	// Logical actor this actor is a weighted version of
+	// +optional
+	LogicalActor *LogicalActorConfig `json:"logicalActor,omitempty"`
+
+	// Shadow traffic mirrored to a candidate actor after each runtime call
+	// +optional
+	Shadow *ShadowConfig `json:"shadow,omitempty"`
+
 	// KEDA autoscaling configuration
 	// +optional
 	Scaling ScalingConfig `json:"scaling,omitempty"`
@@ -63,6 +79,95 @@ type TimeoutConfig struct {
 	GracefulShutdown int `json:"gracefulShutdown,omitempty"`
 }
 
+// RoutePolicyConfig restricts how envelope-mode handlers may rewrite the route.
+// Violating envelopes are sent to error-end instead of the next actor.
+type RoutePolicyConfig struct {
+	// Actors the envelope may be routed to next (empty allows any).
+	// Reaching the end of the route (happy-end) is always allowed.
+	// +optional
+	AllowedNext []string `json:"allowedNext,omitempty"`
+
+	// Maximum number of actors in the route (0 means unlimited)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRouteLength int `json:"maxRouteLength,omitempty"`
+
+	// Actors the handler may not add to the route ("*" forbids any insertion)
+	// +optional
+	ForbiddenInsertions []string `json:"forbiddenInsertions,omitempty"`
+}
+
+// RetryPolicyConfig retries runtime errors by exception type. The sidecar requeues the envelope to the
+// actor's queue with exponential backoff; exhausted and permanent errors are sent to error-end.
+type RetryPolicyConfig struct {
+	// Maximum runtime attempts per envelope, including the first
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	// +optional
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// Exception types to retry (empty retries every type not listed as permanent)
+	// +optional
+	RetryOn []string `json:"retryOn,omitempty"`
+
+	// Exception types sent to error-end without retrying
+	// +optional
+	Permanent []string `json:"permanent,omitempty"`
+
+	// Delay before the first retry in seconds
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	InitialBackoffSeconds int `json:"initialBackoffSeconds,omitempty"`
+
+	// Upper bound on the delay between retries in seconds
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=300
+	// +optional
+	MaxBackoffSeconds int `json:"maxBackoffSeconds,omitempty"`
+
+	// Factor the delay grows by after each retry
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=2
+	// +optional
+	BackoffMultiplier int `json:"backoffMultiplier,omitempty"`
+}
+
+// LogicalActorConfig makes the actor a version of a logical actor. Envelopes routed to the logical
+// actor name are split between the versions in the namespace by weight.
+type LogicalActorConfig struct {
+	// Logical actor name used in routes
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Relative share of the logical actor's envelopes (0 drains this version)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+
+	// Send all deliveries of an envelope to the same version by hashing the envelope ID.
+	// Applies to the logical actor when any of its versions sets it.
+	// +optional
+	Sticky bool `json:"sticky,omitempty"`
+}
+
+// ShadowConfig mirrors a share of processed envelopes to a shadow actor.
+// The shadow actor's results are compared with the primary's and recorded as
+// metrics; they are never routed onward or reported to the gateway.
+type ShadowConfig struct {
+	// Name of the shadow actor receiving mirrored envelopes
+	// +kubebuilder:validation:MinLength=1
+	Actor string `json:"actor"`
+
+	// Percentage of envelopes to mirror (0 pauses mirroring)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=100
+	// +optional
+	Percent *int32 `json:"percent,omitempty"`
+}
+
 // ScalingConfig defines KEDA autoscaling configuration
 type ScalingConfig struct {
 	// Enable KEDA autoscaling
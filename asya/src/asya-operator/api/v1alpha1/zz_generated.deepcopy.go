@@ -105,6 +105,26 @@ func (in *AsyncActorSpec) DeepCopyInto(out *AsyncActorSpec) {
 	*out = *in
 	in.Sidecar.DeepCopyInto(&out.Sidecar)
 	out.Timeout = in.Timeout
+	if in.RoutePolicy != nil {
+		in, out := &in.RoutePolicy, &out.RoutePolicy
+		*out = new(RoutePolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogicalActor != nil {
+		in, out := &in.LogicalActor, &out.LogicalActor
+		*out = new(LogicalActorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(ShadowConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Scaling.DeepCopyInto(&out.Scaling)
 	in.Workload.DeepCopyInto(&out.Workload)
 }
@@ -195,6 +215,26 @@ func (in *AsyncActorStatus) DeepCopy() *AsyncActorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalActorConfig) DeepCopyInto(out *LogicalActorConfig) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalActorConfig.
+func (in *LogicalActorConfig) DeepCopy() *LogicalActorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalActorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
 	*out = *in
@@ -227,6 +267,56 @@ func (in *PodTemplateSpec) DeepCopy() *PodTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicyConfig) DeepCopyInto(out *RetryPolicyConfig) {
+	*out = *in
+	if in.RetryOn != nil {
+		in, out := &in.RetryOn, &out.RetryOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permanent != nil {
+		in, out := &in.Permanent, &out.Permanent
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicyConfig.
+func (in *RetryPolicyConfig) DeepCopy() *RetryPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutePolicyConfig) DeepCopyInto(out *RoutePolicyConfig) {
+	*out = *in
+	if in.AllowedNext != nil {
+		in, out := &in.AllowedNext, &out.AllowedNext
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenInsertions != nil {
+		in, out := &in.ForbiddenInsertions, &out.ForbiddenInsertions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutePolicyConfig.
+func (in *RoutePolicyConfig) DeepCopy() *RoutePolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutePolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingConfig) DeepCopyInto(out *ScalingConfig) {
 	*out = *in
@@ -257,6 +347,26 @@ func (in *ScalingConfig) DeepCopy() *ScalingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowConfig) DeepCopyInto(out *ShadowConfig) {
+	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowConfig.
+func (in *ShadowConfig) DeepCopy() *ShadowConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarConfig) DeepCopyInto(out *SidecarConfig) {
 	*out = *in
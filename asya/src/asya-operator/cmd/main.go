@@ -107,6 +107,7 @@ func main() {
 		MaxConcurrentReconciles: maxConcurrentReconciles,
 		GatewayURL:              gatewayURL,
 		OperatorNamespace:       operatorNamespace,
+		SigningSecretName:       os.Getenv("ASYA_SIGNING_SECRET"),
 	}
 	if err = asyncActorReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AsyncActor")
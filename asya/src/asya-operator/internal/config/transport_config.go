@@ -3,11 +3,15 @@ package config
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +20,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -41,6 +49,9 @@ type TransportSpecificConfig interface {
 // TransportRegistry holds all configured transports
 type TransportRegistry struct {
 	Transports map[string]*TransportConfig
+
+	// QueueNaming names actor queues; sidecars and the gateway must use the same scheme
+	QueueNaming naming.Scheme
 }
 
 // TransportConfig defines a configured transport
@@ -103,6 +114,9 @@ type SQSConfig struct {
 	Endpoint          string                `json:"endpoint,omitempty"`
 	VisibilityTimeout int                   `json:"visibilityTimeout"`
 	WaitTimeSeconds   int                   `json:"waitTimeSeconds"`
+	MaxMessages       int                   `json:"maxMessages,omitempty"`
+	FIFO              bool                  `json:"fifo,omitempty"`
+	MessageGroup      string                `json:"messageGroup,omitempty"`
 	Credentials       *SQSCredentialsConfig `json:"credentials,omitempty"`
 	Queues            QueueManagementConfig `json:"queues"`
 	Tags              map[string]string     `json:"tags,omitempty"`
@@ -116,11 +130,159 @@ type SQSCredentialsConfig struct {
 
 func (s *SQSConfig) isTransportConfig() {}
 
+// ActorRoleArnFor returns the actor IAM role for namespace. actorRoleArn may contain "{namespace}"
+// to give each namespace its own role, e.g. arn:aws:iam::123456789012:role/asya-{namespace}-actors.
+func (s *SQSConfig) ActorRoleArnFor(namespace string) string {
+	return strings.ReplaceAll(s.ActorRoleArn, "{namespace}", namespace)
+}
+
+// HasPerNamespaceRoles reports whether actorRoleArn gives each namespace its own role
+func (s *SQSConfig) HasPerNamespaceRoles() bool {
+	return strings.Contains(s.ActorRoleArn, "{namespace}")
+}
+
+// QueueName returns the SQS name of a queue, with the ".fifo" suffix when FIFO queues are enabled
+func (s *SQSConfig) QueueName(name string) string {
+	if s.FIFO {
+		return naming.FIFOQueueName(name)
+	}
+	return name
+}
+
+// validateMessageGroup checks that messageGroup is empty or "headers.<key>" / "payload.<path>".
+// Mirrors transport.ValidateMessageGroup in asya-sidecar.
+func validateMessageGroup(messageGroup string) error {
+	if messageGroup == "" {
+		return nil
+	}
+	source, path, ok := strings.Cut(messageGroup, ".")
+	if !ok || (source != "headers" && source != "payload") {
+		return fmt.Errorf("sqs messageGroup must start with 'headers.' or 'payload.', got %q", messageGroup)
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return fmt.Errorf("sqs messageGroup has an empty path segment: %q", messageGroup)
+		}
+	}
+	return nil
+}
+
+// FileConfig defines file transport configuration.
+// Queues are directories on a volume shared by all actor pods, either a hostPath
+// (single-node clusters, e.g. kind or edge nodes) or a ReadWriteMany PersistentVolumeClaim.
+type FileConfig struct {
+	Root              string `json:"root,omitempty"`              // Mount path inside pods (default /var/lib/asya/queues)
+	HostPath          string `json:"hostPath,omitempty"`          // Node directory backing the queues
+	ClaimName         string `json:"claimName,omitempty"`         // PVC backing the queues (must be ReadWriteMany for multi-node)
+	VisibilityTimeout int    `json:"visibilityTimeout,omitempty"` // Lease duration in seconds
+	PollIntervalMs    int    `json:"pollIntervalMs,omitempty"`
+	MaxDeliveries     int    `json:"maxDeliveries,omitempty"` // Deliveries before a message moves to dead/
+}
+
+func (f *FileConfig) isTransportConfig() {}
+
+// DefaultFileRoot is the default mount path of the file transport volume
+const DefaultFileRoot = "/var/lib/asya/queues"
+
+// MountPath returns the directory the queue volume is mounted at in pods
+func (f *FileConfig) MountPath() string {
+	if f.Root != "" {
+		return f.Root
+	}
+	return DefaultFileRoot
+}
+
+// PostgresConfig defines postgres transport configuration.
+// Queues are rows in shared tables (asya_queue_messages), so Asya can run with only Postgres.
+type PostgresConfig struct {
+	Host              string                    `json:"host"`
+	Port              int                       `json:"port,omitempty"`
+	Database          string                    `json:"database,omitempty"`
+	Username          string                    `json:"username,omitempty"`
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	Password          string                    `json:"-"` // For testing only, not marshaled
+	SSLMode           string                    `json:"sslMode,omitempty"`
+	VisibilityTimeout int                       `json:"visibilityTimeout,omitempty"` // Lease duration in seconds
+	PollIntervalMs    int                       `json:"pollIntervalMs,omitempty"`
+	MaxDeliveries     int                       `json:"maxDeliveries,omitempty"` // Deliveries before a message moves to the dead-letter table
+	Queues            QueueManagementConfig     `json:"queues"`
+}
+
+func (p *PostgresConfig) isTransportConfig() {}
+
+// ConnString builds a postgres connection URL with the given password
+func (p *PostgresConfig) ConnString(password string) string {
+	u := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.Username, password),
+		Host:     fmt.Sprintf("%s:%d", p.Host, p.Port),
+		Path:     "/" + p.Database,
+		RawQuery: url.Values{"sslmode": []string{p.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// RedisConfig defines Redis Streams transport configuration.
+// Each queue is a stream read by one consumer group shared by all actor replicas.
+type RedisConfig struct {
+	Host              string                    `json:"host"`
+	Port              int                       `json:"port,omitempty"`
+	DB                int                       `json:"db,omitempty"`
+	Username          string                    `json:"username,omitempty"`
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	Password          string                    `json:"-"` // For testing only, not marshaled
+	TLS               bool                      `json:"tls,omitempty"`
+	Group             string                    `json:"group,omitempty"`             // Consumer group name
+	VisibilityTimeout int                       `json:"visibilityTimeout,omitempty"` // Idle seconds before a pending entry is reclaimed
+	MaxDeliveries     int                       `json:"maxDeliveries,omitempty"`     // Deliveries before an entry moves to "{queue}:dead"
+	Queues            QueueManagementConfig     `json:"queues"`
+}
+
+func (r *RedisConfig) isTransportConfig() {}
+
+// Address returns host:port of the Redis server
+func (r *RedisConfig) Address() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+// Options builds go-redis client options with the given password
+func (r *RedisConfig) Options(password string) *redis.Options {
+	opts := &redis.Options{
+		Addr:     r.Address(),
+		Username: r.Username,
+		Password: password,
+		DB:       r.DB,
+	}
+	if r.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return opts
+}
+
+// PubSubConfig defines Google Cloud Pub/Sub transport configuration.
+// Each queue is a topic with a subscription of the same name. Credentials come from
+// GKE Workload Identity: actor pods run as a Kubernetes ServiceAccount bound to ActorServiceAccount.
+type PubSubConfig struct {
+	ProjectID           string                `json:"projectId"`
+	EmulatorHost        string                `json:"emulatorHost,omitempty"`        // host:port of the Pub/Sub emulator (testing only)
+	ActorServiceAccount string                `json:"actorServiceAccount,omitempty"` // Google service account email for Workload Identity
+	AckDeadline         int                   `json:"ackDeadline,omitempty"`         // Subscription ack deadline in seconds
+	VisibilityTimeout   int                   `json:"visibilityTimeout,omitempty"`   // Max seconds the sidecar extends the ack deadline of a held message
+	Queues              QueueManagementConfig `json:"queues"`
+}
+
+func (p *PubSubConfig) isTransportConfig() {}
+
 // LoadTransportRegistry loads transport configurations from environment
 func LoadTransportRegistry() (*TransportRegistry, error) {
+	queueNaming, err := loadQueueNaming()
+	if err != nil {
+		return nil, err
+	}
+
 	configJSON := os.Getenv("ASYA_TRANSPORT_CONFIG")
 	if configJSON == "" {
-		return &TransportRegistry{Transports: make(map[string]*TransportConfig)}, nil
+		return &TransportRegistry{Transports: make(map[string]*TransportConfig), QueueNaming: queueNaming}, nil
 	}
 
 	rawRegistry := &rawTransportRegistry{}
@@ -131,7 +293,8 @@ func LoadTransportRegistry() (*TransportRegistry, error) {
 	}
 
 	registry := &TransportRegistry{
-		Transports: make(map[string]*TransportConfig),
+		Transports:  make(map[string]*TransportConfig),
+		QueueNaming: queueNaming,
 	}
 
 	for name, rawConfig := range rawRegistry.Transports {
@@ -145,6 +308,12 @@ func LoadTransportRegistry() (*TransportRegistry, error) {
 	return registry, nil
 }
 
+// loadQueueNaming reads the queue naming scheme from the environment variables sidecars also read.
+// An unset prefix stays empty so that it is not rendered into sidecars; the scheme defaults it.
+func loadQueueNaming() (naming.Scheme, error) {
+	return naming.New(os.Getenv(naming.EnvPrefix), os.Getenv(naming.EnvCluster), os.Getenv(naming.EnvSuffix))
+}
+
 // parseTransportConfig parses raw config into typed transport config
 func parseTransportConfig(raw *rawTransportConfig) (*TransportConfig, error) {
 	var typedConfig TransportSpecificConfig
@@ -180,6 +349,18 @@ func parseTransportConfig(raw *rawTransportConfig) (*TransportConfig, error) {
 		if err := decoder.Decode(config); err != nil {
 			return nil, fmt.Errorf("failed to parse SQS config: %w", err)
 		}
+		if config.MaxMessages < 0 || config.MaxMessages > 10 {
+			return nil, fmt.Errorf("sqs maxMessages must be between 1 and 10, got %d", config.MaxMessages)
+		}
+		if config.FIFO && config.MaxMessages > 1 {
+			return nil, fmt.Errorf("sqs maxMessages must be 1 with fifo, got %d", config.MaxMessages)
+		}
+		if config.MessageGroup != "" && !config.FIFO {
+			return nil, fmt.Errorf("sqs messageGroup requires fifo")
+		}
+		if err := validateMessageGroup(config.MessageGroup); err != nil {
+			return nil, err
+		}
 		// Set defaults for queue management if not specified
 		if !raw.hasQueuesConfig() {
 			config.Queues.AutoCreate = true
@@ -194,6 +375,118 @@ func parseTransportConfig(raw *rawTransportConfig) (*TransportConfig, error) {
 		}
 		typedConfig = config
 
+	case "file":
+		config := &FileConfig{}
+		decoder := json.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse file config: %w", err)
+		}
+		if (config.HostPath == "") == (config.ClaimName == "") {
+			return nil, fmt.Errorf("file transport requires exactly one of hostPath or claimName")
+		}
+		if config.MaxDeliveries == 0 {
+			config.MaxDeliveries = 3
+		}
+		typedConfig = config
+
+	case "postgres":
+		config := &PostgresConfig{}
+		decoder := json.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse postgres config: %w", err)
+		}
+		if config.Host == "" {
+			return nil, fmt.Errorf("postgres transport requires host")
+		}
+		// Set connection defaults
+		if config.Port == 0 {
+			config.Port = 5432
+		}
+		if config.Database == "" {
+			config.Database = "asya"
+		}
+		if config.Username == "" {
+			config.Username = "postgres"
+		}
+		if config.SSLMode == "" {
+			config.SSLMode = "disable"
+		}
+		// Set defaults for queue management if not specified
+		if !raw.hasQueuesConfig() {
+			config.Queues.AutoCreate = true
+			config.Queues.ForceRecreate = false
+		}
+		if config.MaxDeliveries == 0 {
+			config.MaxDeliveries = 3
+		}
+		typedConfig = config
+
+	case "redis":
+		config := &RedisConfig{}
+		decoder := json.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse redis config: %w", err)
+		}
+		if config.Host == "" {
+			return nil, fmt.Errorf("redis transport requires host")
+		}
+		// Set connection defaults
+		if config.Port == 0 {
+			config.Port = 6379
+		}
+		if config.Group == "" {
+			config.Group = "asya"
+		}
+		// Set defaults for queue management if not specified
+		if !raw.hasQueuesConfig() {
+			config.Queues.AutoCreate = true
+			config.Queues.ForceRecreate = false
+		}
+		if config.MaxDeliveries == 0 {
+			config.MaxDeliveries = 3
+		}
+		typedConfig = config
+
+	case "pubsub":
+		config := &PubSubConfig{}
+		decoder := json.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse pubsub config: %w", err)
+		}
+		if config.ProjectID == "" {
+			return nil, fmt.Errorf("pubsub transport requires projectId")
+		}
+		if config.AckDeadline == 0 {
+			config.AckDeadline = 60
+		}
+		if config.AckDeadline < 10 || config.AckDeadline > 600 {
+			return nil, fmt.Errorf("pubsub ackDeadline must be between 10 and 600 seconds, got %d", config.AckDeadline)
+		}
+		// Set defaults for queue management if not specified
+		if !raw.hasQueuesConfig() {
+			config.Queues.AutoCreate = true
+			config.Queues.ForceRecreate = false
+		}
+		// Pub/Sub dead-letter policies accept 5-100 delivery attempts
+		if config.Queues.DLQ.MaxRetryCount == 0 {
+			config.Queues.DLQ.MaxRetryCount = 5
+		}
+		if config.Queues.DLQ.MaxRetryCount < 5 || config.Queues.DLQ.MaxRetryCount > 100 {
+			return nil, fmt.Errorf("pubsub dlq.maxRetryCount must be between 5 and 100, got %d", config.Queues.DLQ.MaxRetryCount)
+		}
+		if config.Queues.DLQ.RetentionDays == 0 {
+			config.Queues.DLQ.RetentionDays = 7
+		}
+		// Subscriptions retain unacked messages for at most 7 days
+		if config.Queues.DLQ.RetentionDays > 7 {
+			return nil, fmt.Errorf("pubsub dlq.retentionDays must be at most 7, got %d", config.Queues.DLQ.RetentionDays)
+		}
+		typedConfig = config
+
 	default:
 		return nil, fmt.Errorf("unsupported transport type: %s", raw.Type)
 	}
@@ -205,6 +498,15 @@ func parseTransportConfig(raw *rawTransportConfig) (*TransportConfig, error) {
 	}, nil
 }
 
+// QueueName returns the queue name of an actor under the configured naming scheme
+// (the default scheme for a nil registry)
+func (r *TransportRegistry) QueueName(namespace, actor string) string {
+	if r == nil {
+		return naming.Scheme{}.QueueName(namespace, actor)
+	}
+	return r.QueueNaming.QueueName(namespace, actor)
+}
+
 // GetTransport retrieves a transport config by name
 func (r *TransportRegistry) GetTransport(name string) (*TransportConfig, error) {
 	transport, ok := r.Transports[name]
@@ -283,6 +585,15 @@ func (t *TransportConfig) BuildEnvVars() ([]corev1.EnvVar, error) {
 		if config.WaitTimeSeconds > 0 {
 			env = append(env, corev1.EnvVar{Name: "ASYA_SQS_WAIT_TIME_SECONDS", Value: fmt.Sprintf("%d", config.WaitTimeSeconds)})
 		}
+		if config.MaxMessages > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_SQS_MAX_MESSAGES", Value: fmt.Sprintf("%d", config.MaxMessages)})
+		}
+		if config.FIFO {
+			env = append(env, corev1.EnvVar{Name: "ASYA_SQS_FIFO", Value: "true"})
+		}
+		if config.MessageGroup != "" {
+			env = append(env, corev1.EnvVar{Name: "ASYA_SQS_MESSAGE_GROUP", Value: config.MessageGroup})
+		}
 
 		if config.Credentials != nil {
 			if config.Credentials.AccessKeyIdSecretRef != nil {
@@ -302,6 +613,130 @@ func (t *TransportConfig) BuildEnvVars() ([]corev1.EnvVar, error) {
 				})
 			}
 		}
+
+	case "file":
+		config, ok := t.Config.(*FileConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid config type for file transport")
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_FILE_ROOT", Value: config.MountPath()})
+		if config.VisibilityTimeout > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_FILE_VISIBILITY_TIMEOUT", Value: fmt.Sprintf("%ds", config.VisibilityTimeout)})
+		}
+		if config.PollIntervalMs > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_FILE_POLL_INTERVAL", Value: fmt.Sprintf("%dms", config.PollIntervalMs)})
+		}
+		if config.MaxDeliveries > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_FILE_MAX_DELIVERIES", Value: fmt.Sprintf("%d", config.MaxDeliveries)})
+		}
+
+	case "postgres":
+		config, ok := t.Config.(*PostgresConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid config type for postgres transport")
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_HOST", Value: config.Host})
+		env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_PORT", Value: fmt.Sprintf("%d", config.Port)})
+		env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_DATABASE", Value: config.Database})
+		env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_USERNAME", Value: config.Username})
+		env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_SSLMODE", Value: config.SSLMode})
+
+		if config.PasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{
+				Name: "ASYA_POSTGRES_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: config.PasswordSecretRef,
+				},
+			})
+		} else if config.Password != "" {
+			env = append(env, corev1.EnvVar{
+				Name:  "ASYA_POSTGRES_PASSWORD",
+				Value: config.Password,
+			})
+		}
+
+		if config.VisibilityTimeout > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_VISIBILITY_TIMEOUT", Value: fmt.Sprintf("%ds", config.VisibilityTimeout)})
+		}
+		if config.PollIntervalMs > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_POLL_INTERVAL", Value: fmt.Sprintf("%dms", config.PollIntervalMs)})
+		}
+		if config.MaxDeliveries > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_POSTGRES_MAX_DELIVERIES", Value: fmt.Sprintf("%d", config.MaxDeliveries)})
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_QUEUE_AUTO_CREATE", Value: fmt.Sprintf("%t", config.Queues.AutoCreate)})
+
+	case "redis":
+		config, ok := t.Config.(*RedisConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid config type for redis transport")
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_HOST", Value: config.Host})
+		env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_PORT", Value: fmt.Sprintf("%d", config.Port)})
+		env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_DB", Value: fmt.Sprintf("%d", config.DB)})
+		if config.Username != "" {
+			env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_USERNAME", Value: config.Username})
+		}
+
+		if config.PasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{
+				Name: "ASYA_REDIS_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: config.PasswordSecretRef,
+				},
+			})
+		} else if config.Password != "" {
+			env = append(env, corev1.EnvVar{
+				Name:  "ASYA_REDIS_PASSWORD",
+				Value: config.Password,
+			})
+		}
+
+		if config.TLS {
+			env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_TLS", Value: "true"})
+		}
+		env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_GROUP", Value: config.Group})
+		// Consumer name must be unique per replica
+		env = append(env, corev1.EnvVar{
+			Name: "ASYA_REDIS_CONSUMER",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		})
+		if config.VisibilityTimeout > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_VISIBILITY_TIMEOUT", Value: fmt.Sprintf("%ds", config.VisibilityTimeout)})
+		}
+		if config.MaxDeliveries > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_REDIS_MAX_DELIVERIES", Value: fmt.Sprintf("%d", config.MaxDeliveries)})
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_QUEUE_AUTO_CREATE", Value: fmt.Sprintf("%t", config.Queues.AutoCreate)})
+
+	case "pubsub":
+		config, ok := t.Config.(*PubSubConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid config type for pubsub transport")
+		}
+
+		env = append(env, corev1.EnvVar{Name: "ASYA_PUBSUB_PROJECT_ID", Value: config.ProjectID})
+		if config.EmulatorHost != "" {
+			env = append(env, corev1.EnvVar{Name: "ASYA_PUBSUB_EMULATOR_HOST", Value: config.EmulatorHost})
+		}
+		// Client ID lets Pub/Sub balance streaming pulls across replicas
+		env = append(env, corev1.EnvVar{
+			Name: "ASYA_PUBSUB_CLIENT_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		})
+		env = append(env, corev1.EnvVar{Name: "ASYA_PUBSUB_ACK_DEADLINE", Value: fmt.Sprintf("%ds", config.AckDeadline)})
+		if config.VisibilityTimeout > 0 {
+			env = append(env, corev1.EnvVar{Name: "ASYA_PUBSUB_VISIBILITY_TIMEOUT", Value: fmt.Sprintf("%ds", config.VisibilityTimeout)})
+		}
 	}
 
 	return env, nil
@@ -409,7 +844,7 @@ func (s *SQSConfig) GetQueueMetrics(ctx context.Context, queueName string, names
 
 	// Get queue URL
 	urlResult, err := sqsClient.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName),
+		QueueName: aws.String(s.QueueName(queueName)),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get queue URL: %w", err)
@@ -448,3 +883,140 @@ func (s *SQSConfig) GetQueueMetrics(ctx context.Context, queueName string, names
 		Processing: &processing,
 	}, nil
 }
+
+// GetQueueMetrics for file transport counts message files in the queue directory.
+// The operator only sees queues when the volume is also mounted into the operator pod;
+// otherwise zeros are returned.
+func (f *FileConfig) GetQueueMetrics(ctx context.Context, queueName string, namespace string, passwordResolver PasswordResolver) (*QueueMetrics, error) {
+	queued, err := countMessageFiles(filepath.Join(f.MountPath(), queueName, "ready"))
+	if err != nil {
+		return nil, err
+	}
+	processing, err := countMessageFiles(filepath.Join(f.MountPath(), queueName, "processing"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueueMetrics{
+		Queued:     queued,
+		Processing: &processing,
+	}, nil
+}
+
+// countMessageFiles counts *.msg files in dir, treating a missing dir as empty
+func countMessageFiles(dir string) (int32, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read queue directory %s: %w", dir, err)
+	}
+
+	count := int32(0)
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".msg") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// postgresQueueMetricsSQL counts visible (queued) and leased (processing) messages in a queue
+const postgresQueueMetricsSQL = `
+SELECT
+    COUNT(*) FILTER (WHERE visible_at <= NOW()),
+    COUNT(*) FILTER (WHERE visible_at > NOW() AND locked_at IS NOT NULL)
+FROM asya_queue_messages
+WHERE queue_name = $1`
+
+// GetQueueMetrics for postgres counts rows in the queue table
+func (p *PostgresConfig) GetQueueMetrics(ctx context.Context, queueName string, namespace string, passwordResolver PasswordResolver) (*QueueMetrics, error) {
+	// Resolve password from secret or use plain password field
+	password := p.Password
+	if p.PasswordSecretRef != nil && passwordResolver != nil {
+		resolvedPassword, err := passwordResolver(ctx, p.PasswordSecretRef, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve password: %w", err)
+		}
+		password = resolvedPassword
+	}
+
+	connCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(connCtx, p.ConnString(password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	var queued, processing int32
+	if err := conn.QueryRow(connCtx, postgresQueueMetricsSQL, queueName).Scan(&queued, &processing); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+			// Queue tables don't exist yet - return zeros
+			return &QueueMetrics{Queued: 0, Processing: &processing}, nil
+		}
+		return nil, fmt.Errorf("failed to query queue metrics: %w", err)
+	}
+
+	return &QueueMetrics{
+		Queued:     queued,
+		Processing: &processing,
+	}, nil
+}
+
+// GetQueueMetrics for redis reads stream length and the consumer group's pending entries.
+// Acked entries are deleted, so queued = length - pending.
+func (r *RedisConfig) GetQueueMetrics(ctx context.Context, queueName string, namespace string, passwordResolver PasswordResolver) (*QueueMetrics, error) {
+	// Resolve password from secret or use plain password field
+	password := r.Password
+	if r.PasswordSecretRef != nil && passwordResolver != nil {
+		resolvedPassword, err := passwordResolver(ctx, r.PasswordSecretRef, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve password: %w", err)
+		}
+		password = resolvedPassword
+	}
+
+	client := redis.NewClient(r.Options(password))
+	defer func() {
+		_ = client.Close()
+	}()
+
+	queryCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	length, err := client.XLen(queryCtx, queueName).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stream length: %w", err)
+	}
+
+	processing := int32(0)
+	if length > 0 {
+		pending, err := client.XPending(queryCtx, queueName, r.Group).Result()
+		if err != nil && !strings.HasPrefix(err.Error(), "NOGROUP") {
+			return nil, fmt.Errorf("failed to query pending entries: %w", err)
+		}
+		if pending != nil {
+			processing = int32(pending.Count) // #nosec G115 - queue sizes fit in int32
+		}
+	}
+
+	return &QueueMetrics{
+		Queued:     int32(length) - processing, // #nosec G115 - queue sizes fit in int32
+		Processing: &processing,
+	}, nil
+}
+
+// GetQueueMetrics for pubsub is not supported: subscription backlog is only exposed
+// through Cloud Monitoring, which KEDA's gcp-pubsub scaler queries directly.
+func (p *PubSubConfig) GetQueueMetrics(ctx context.Context, queueName string, namespace string, passwordResolver PasswordResolver) (*QueueMetrics, error) {
+	return &QueueMetrics{
+		Queued:     0,
+		Processing: nil,
+	}, nil
+}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -37,6 +42,25 @@ func TestLoadTransportRegistry_EmptyConfig(t *testing.T) {
 	}
 }
 
+func TestLoadTransportRegistry_QueueNaming(t *testing.T) {
+	t.Setenv("ASYA_TRANSPORT_CONFIG", "")
+	t.Setenv("ASYA_QUEUE_PREFIX", "acme")
+	t.Setenv("ASYA_CLUSTER_NAME", "eu1")
+
+	registry, err := LoadTransportRegistry()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := registry.QueueName("default", "ocr"); got != "acme-eu1-default-ocr" {
+		t.Errorf("Expected queue name 'acme-eu1-default-ocr', got %q", got)
+	}
+
+	t.Setenv("ASYA_QUEUE_SUFFIX", "q.fifo")
+	if _, err := LoadTransportRegistry(); err == nil {
+		t.Error("Expected error for invalid queue suffix")
+	}
+}
+
 func TestLoadTransportRegistry_ValidRabbitMQConfig(t *testing.T) {
 	configJSON := `{
 		"transports": {
@@ -674,6 +698,109 @@ func TestBuildEnvVars_SQS(t *testing.T) {
 	}
 }
 
+func TestBuildEnvVars_SQSOptions(t *testing.T) {
+	config := &TransportConfig{
+		Type:    "sqs",
+		Enabled: true,
+		Config: &SQSConfig{
+			Region:       "us-west-2",
+			MaxMessages:  1,
+			FIFO:         true,
+			MessageGroup: "headers.customer_id",
+		},
+	}
+
+	env, err := config.BuildEnvVars()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnv := map[string]string{
+		"ASYA_SQS_MAX_MESSAGES":  "1",
+		"ASYA_SQS_FIFO":          "true",
+		"ASYA_SQS_MESSAGE_GROUP": "headers.customer_id",
+	}
+
+	for key, expectedValue := range expectedEnv {
+		found := false
+		for _, e := range env {
+			if e.Name == key && e.Value == expectedValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected env var %s=%s not found", key, expectedValue)
+		}
+	}
+}
+
+func TestParseTransportConfig_SQSFIFO(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		expectError string
+	}{
+		{
+			name:   "fifo with header message group",
+			config: map[string]interface{}{"fifo": true, "messageGroup": "headers.customer_id"},
+		},
+		{
+			name:   "fifo without message group",
+			config: map[string]interface{}{"fifo": true},
+		},
+		{
+			name:        "max messages above the SQS limit",
+			config:      map[string]interface{}{"fifo": true, "maxMessages": float64(11)},
+			expectError: "maxMessages",
+		},
+		{
+			name:        "max messages above 1 with fifo",
+			config:      map[string]interface{}{"fifo": true, "maxMessages": float64(2)},
+			expectError: "must be 1 with fifo",
+		},
+		{
+			name:        "message group without fifo",
+			config:      map[string]interface{}{"messageGroup": "headers.customer_id"},
+			expectError: "requires fifo",
+		},
+		{
+			name:        "unknown message group source",
+			config:      map[string]interface{}{"fifo": true, "messageGroup": "customer_id"},
+			expectError: "must start with",
+		},
+		{
+			name:        "empty message group path segment",
+			config:      map[string]interface{}{"fifo": true, "messageGroup": "payload.customer..id"},
+			expectError: "empty path segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["region"] = "eu-west-1"
+			config, err := parseTransportConfig(&rawTransportConfig{Type: "sqs", Enabled: true, Config: tt.config})
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			sqsConfig := config.Config.(*SQSConfig)
+			if got := sqsConfig.QueueName("asya-default-billing"); got != "asya-default-billing.fifo" {
+				t.Errorf("QueueName() = %q, want %q", got, "asya-default-billing.fifo")
+			}
+			if got := sqsConfig.QueueName("asya-default-billing.fifo"); got != "asya-default-billing.fifo" {
+				t.Errorf("QueueName() should not append the suffix twice, got %q", got)
+			}
+		})
+	}
+}
+
 func TestBuildEnvVars_InvalidConfigType(t *testing.T) {
 	config := &TransportConfig{
 		Type:    "rabbitmq",
@@ -1112,3 +1239,535 @@ func TestLoadTransportRegistry_SQSDLQExplicit(t *testing.T) {
 		t.Errorf("Expected DLQ RetentionDays to be 7, got %d", config.Queues.DLQ.RetentionDays)
 	}
 }
+
+func TestParseTransportConfig_File(t *testing.T) {
+	raw := &rawTransportConfig{
+		Type:    "file",
+		Enabled: true,
+		Config: map[string]interface{}{
+			"hostPath":          "/mnt/asya-queues",
+			"visibilityTimeout": float64(60),
+		},
+	}
+
+	config, err := parseTransportConfig(raw)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fileConfig, ok := config.Config.(*FileConfig)
+	if !ok {
+		t.Fatalf("Expected FileConfig, got %T", config.Config)
+	}
+
+	if fileConfig.HostPath != "/mnt/asya-queues" {
+		t.Errorf("Expected hostPath '/mnt/asya-queues', got %s", fileConfig.HostPath)
+	}
+
+	if fileConfig.MountPath() != DefaultFileRoot {
+		t.Errorf("Expected mount path %s, got %s", DefaultFileRoot, fileConfig.MountPath())
+	}
+
+	if fileConfig.MaxDeliveries != 3 {
+		t.Errorf("Expected default maxDeliveries 3, got %d", fileConfig.MaxDeliveries)
+	}
+}
+
+func TestParseTransportConfig_FileRequiresOneVolumeSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{name: "neither", config: map[string]interface{}{}},
+		{name: "both", config: map[string]interface{}{"hostPath": "/mnt/q", "claimName": "asya-queues"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTransportConfig(&rawTransportConfig{Type: "file", Enabled: true, Config: tt.config})
+			if err == nil || !strings.Contains(err.Error(), "exactly one of hostPath or claimName") {
+				t.Fatalf("Expected volume source error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildEnvVars_File(t *testing.T) {
+	config := &TransportConfig{
+		Type:    "file",
+		Enabled: true,
+		Config: &FileConfig{
+			Root:              "/queues",
+			ClaimName:         "asya-queues",
+			VisibilityTimeout: 120,
+			PollIntervalMs:    100,
+			MaxDeliveries:     5,
+		},
+	}
+
+	env, err := config.BuildEnvVars()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnv := map[string]string{
+		"ASYA_TRANSPORT":               "file",
+		"ASYA_FILE_ROOT":               "/queues",
+		"ASYA_FILE_VISIBILITY_TIMEOUT": "120s",
+		"ASYA_FILE_POLL_INTERVAL":      "100ms",
+		"ASYA_FILE_MAX_DELIVERIES":     "5",
+	}
+
+	for key, expectedValue := range expectedEnv {
+		found := false
+		for _, e := range env {
+			if e.Name == key && e.Value == expectedValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected env var %s=%s not found", key, expectedValue)
+		}
+	}
+}
+
+func TestFileConfig_GetQueueMetrics(t *testing.T) {
+	root := t.TempDir()
+	config := &FileConfig{Root: root, HostPath: root}
+
+	metrics, err := config.GetQueueMetrics(context.Background(), "asya-default-missing", "default", nil)
+	if err != nil {
+		t.Fatalf("Expected no error for missing queue, got %v", err)
+	}
+	if metrics.Queued != 0 || metrics.Processing == nil || *metrics.Processing != 0 {
+		t.Errorf("Expected zero metrics for missing queue, got queued=%d processing=%v", metrics.Queued, metrics.Processing)
+	}
+
+	for dir, names := range map[string][]string{
+		"ready":      {"1-a.0.msg", "2-b.0.msg"},
+		"processing": {"0-c.1.msg", "0-c.1.msg.lease"},
+	} {
+		path := filepath.Join(root, "asya-default-actor", dir)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(path, name), []byte("{}"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	metrics, err = config.GetQueueMetrics(context.Background(), "asya-default-actor", "default", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if metrics.Queued != 2 {
+		t.Errorf("Expected 2 queued, got %d", metrics.Queued)
+	}
+	if metrics.Processing == nil || *metrics.Processing != 1 {
+		t.Errorf("Expected 1 processing, got %v", metrics.Processing)
+	}
+}
+
+func TestFileConfig_ImplementsInterface(t *testing.T) {
+	var _ TransportSpecificConfig = (*FileConfig)(nil)
+}
+
+func TestParseTransportConfig_Postgres(t *testing.T) {
+	raw := &rawTransportConfig{
+		Type:    "postgres",
+		Enabled: true,
+		Config: map[string]interface{}{
+			"host": "postgres.default.svc",
+			"passwordSecretRef": map[string]interface{}{
+				"name": "postgres-secret",
+				"key":  "password",
+			},
+		},
+	}
+
+	config, err := parseTransportConfig(raw)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pgConfig, ok := config.Config.(*PostgresConfig)
+	if !ok {
+		t.Fatalf("Expected PostgresConfig, got %T", config.Config)
+	}
+
+	if pgConfig.Port != 5432 || pgConfig.Database != "asya" || pgConfig.Username != "postgres" || pgConfig.SSLMode != "disable" {
+		t.Errorf("Expected connection defaults, got %+v", pgConfig)
+	}
+	if !pgConfig.Queues.AutoCreate {
+		t.Error("Expected autoCreate to default to true")
+	}
+	if pgConfig.MaxDeliveries != 3 {
+		t.Errorf("Expected default maxDeliveries 3, got %d", pgConfig.MaxDeliveries)
+	}
+	if pgConfig.PasswordSecretRef == nil || pgConfig.PasswordSecretRef.Name != "postgres-secret" {
+		t.Errorf("Expected password secret ref, got %+v", pgConfig.PasswordSecretRef)
+	}
+}
+
+func TestParseTransportConfig_PostgresRequiresHost(t *testing.T) {
+	_, err := parseTransportConfig(&rawTransportConfig{Type: "postgres", Enabled: true, Config: map[string]interface{}{}})
+	if err == nil || !strings.Contains(err.Error(), "requires host") {
+		t.Fatalf("Expected host error, got %v", err)
+	}
+}
+
+func TestPostgresConfig_ConnString(t *testing.T) {
+	config := &PostgresConfig{Host: "db", Port: 5433, Database: "queues", Username: "asya", SSLMode: "require"}
+
+	got := config.ConnString("p@ss/word")
+	expected := "postgres://asya:p%40ss%2Fword@db:5433/queues?sslmode=require"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestBuildEnvVars_Postgres(t *testing.T) {
+	config := &TransportConfig{
+		Type:    "postgres",
+		Enabled: true,
+		Config: &PostgresConfig{
+			Host:     "postgres.default.svc",
+			Port:     5432,
+			Database: "asya",
+			Username: "asya",
+			SSLMode:  "disable",
+			PasswordSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "postgres-secret"},
+				Key:                  "password",
+			},
+			VisibilityTimeout: 90,
+			PollIntervalMs:    250,
+			MaxDeliveries:     4,
+			Queues:            QueueManagementConfig{AutoCreate: true},
+		},
+	}
+
+	env, err := config.BuildEnvVars()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnv := map[string]string{
+		"ASYA_TRANSPORT":                   "postgres",
+		"ASYA_POSTGRES_HOST":               "postgres.default.svc",
+		"ASYA_POSTGRES_PORT":               "5432",
+		"ASYA_POSTGRES_DATABASE":           "asya",
+		"ASYA_POSTGRES_USERNAME":           "asya",
+		"ASYA_POSTGRES_SSLMODE":            "disable",
+		"ASYA_POSTGRES_VISIBILITY_TIMEOUT": "90s",
+		"ASYA_POSTGRES_POLL_INTERVAL":      "250ms",
+		"ASYA_POSTGRES_MAX_DELIVERIES":     "4",
+		"ASYA_QUEUE_AUTO_CREATE":           "true",
+	}
+
+	for key, expectedValue := range expectedEnv {
+		found := false
+		for _, e := range env {
+			if e.Name == key && e.Value == expectedValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected env var %s=%s not found", key, expectedValue)
+		}
+	}
+
+	var passwordVar *corev1.EnvVar
+	for i := range env {
+		if env[i].Name == "ASYA_POSTGRES_PASSWORD" {
+			passwordVar = &env[i]
+		}
+	}
+	if passwordVar == nil || passwordVar.ValueFrom == nil || passwordVar.ValueFrom.SecretKeyRef.Name != "postgres-secret" {
+		t.Errorf("Expected ASYA_POSTGRES_PASSWORD from secret, got %+v", passwordVar)
+	}
+}
+
+func TestPostgresConfig_GetQueueMetrics_ConnectionError(t *testing.T) {
+	config := &PostgresConfig{Host: "127.0.0.1", Port: 1, Database: "asya", Username: "postgres", SSLMode: "disable"}
+
+	_, err := config.GetQueueMetrics(context.Background(), "asya-default-actor", "default", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to postgres") {
+		t.Errorf("Expected connection error, got %v", err)
+	}
+}
+
+func TestPostgresConfig_ImplementsInterface(t *testing.T) {
+	var _ TransportSpecificConfig = (*PostgresConfig)(nil)
+}
+
+func TestParseTransportConfig_Redis(t *testing.T) {
+	raw := &rawTransportConfig{
+		Type:    "redis",
+		Enabled: true,
+		Config: map[string]interface{}{
+			"host": "redis.default.svc",
+		},
+	}
+
+	config, err := parseTransportConfig(raw)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	redisConfig, ok := config.Config.(*RedisConfig)
+	if !ok {
+		t.Fatalf("Expected RedisConfig, got %T", config.Config)
+	}
+
+	if redisConfig.Port != 6379 || redisConfig.Group != "asya" || redisConfig.MaxDeliveries != 3 {
+		t.Errorf("Expected defaults port=6379 group=asya maxDeliveries=3, got %+v", redisConfig)
+	}
+	if !redisConfig.Queues.AutoCreate {
+		t.Error("Expected autoCreate to default to true")
+	}
+	if redisConfig.Address() != "redis.default.svc:6379" {
+		t.Errorf("Expected address redis.default.svc:6379, got %s", redisConfig.Address())
+	}
+}
+
+func TestParseTransportConfig_RedisRequiresHost(t *testing.T) {
+	_, err := parseTransportConfig(&rawTransportConfig{Type: "redis", Enabled: true, Config: map[string]interface{}{}})
+	if err == nil || !strings.Contains(err.Error(), "requires host") {
+		t.Fatalf("Expected host error, got %v", err)
+	}
+}
+
+func TestBuildEnvVars_Redis(t *testing.T) {
+	config := &TransportConfig{
+		Type:    "redis",
+		Enabled: true,
+		Config: &RedisConfig{
+			Host:  "redis.default.svc",
+			Port:  6379,
+			DB:    2,
+			TLS:   true,
+			Group: "workers",
+			PasswordSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "redis-secret"},
+				Key:                  "password",
+			},
+			VisibilityTimeout: 60,
+			MaxDeliveries:     5,
+		},
+	}
+
+	env, err := config.BuildEnvVars()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnv := map[string]string{
+		"ASYA_TRANSPORT":                "redis",
+		"ASYA_REDIS_HOST":               "redis.default.svc",
+		"ASYA_REDIS_PORT":               "6379",
+		"ASYA_REDIS_DB":                 "2",
+		"ASYA_REDIS_TLS":                "true",
+		"ASYA_REDIS_GROUP":              "workers",
+		"ASYA_REDIS_VISIBILITY_TIMEOUT": "60s",
+		"ASYA_REDIS_MAX_DELIVERIES":     "5",
+		"ASYA_QUEUE_AUTO_CREATE":        "false",
+	}
+
+	envByName := make(map[string]corev1.EnvVar)
+	for _, e := range env {
+		envByName[e.Name] = e
+	}
+	for key, expectedValue := range expectedEnv {
+		if envByName[key].Value != expectedValue {
+			t.Errorf("Expected env var %s=%s, got %q", key, expectedValue, envByName[key].Value)
+		}
+	}
+
+	if ref := envByName["ASYA_REDIS_PASSWORD"].ValueFrom; ref == nil || ref.SecretKeyRef.Name != "redis-secret" {
+		t.Errorf("Expected ASYA_REDIS_PASSWORD from secret, got %+v", envByName["ASYA_REDIS_PASSWORD"])
+	}
+	if ref := envByName["ASYA_REDIS_CONSUMER"].ValueFrom; ref == nil || ref.FieldRef.FieldPath != "metadata.name" {
+		t.Errorf("Expected ASYA_REDIS_CONSUMER from pod name, got %+v", envByName["ASYA_REDIS_CONSUMER"])
+	}
+}
+
+func TestRedisConfig_GetQueueMetrics(t *testing.T) {
+	mr := miniredis.RunT(t)
+	port, _ := strconv.Atoi(mr.Port())
+	config := &RedisConfig{Host: mr.Host(), Port: port, Group: "asya"}
+	ctx := context.Background()
+
+	metrics, err := config.GetQueueMetrics(ctx, "asya-default-missing", "default", nil)
+	if err != nil {
+		t.Fatalf("Expected no error for missing stream, got %v", err)
+	}
+	if metrics.Queued != 0 || metrics.Processing == nil || *metrics.Processing != 0 {
+		t.Errorf("Expected zero metrics for missing stream, got queued=%d processing=%v", metrics.Queued, metrics.Processing)
+	}
+
+	rdb := redis.NewClient(config.Options(""))
+	defer func() { _ = rdb.Close() }()
+	queue := "asya-default-actor"
+	if err := rdb.XGroupCreateMkStream(ctx, queue, "asya", "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := rdb.XAdd(ctx, &redis.XAddArgs{Stream: queue, Values: map[string]interface{}{"body": "{}"}}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "asya", Consumer: "c", Streams: []string{queue, ">"}, Count: 1}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err = config.GetQueueMetrics(ctx, queue, "default", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if metrics.Queued != 2 {
+		t.Errorf("Expected 2 queued, got %d", metrics.Queued)
+	}
+	if metrics.Processing == nil || *metrics.Processing != 1 {
+		t.Errorf("Expected 1 processing, got %v", metrics.Processing)
+	}
+}
+
+func TestRedisConfig_ImplementsInterface(t *testing.T) {
+	var _ TransportSpecificConfig = (*RedisConfig)(nil)
+}
+
+func TestParseTransportConfig_PubSub(t *testing.T) {
+	raw := &rawTransportConfig{
+		Type:    "pubsub",
+		Enabled: true,
+		Config: map[string]interface{}{
+			"projectId":           "my-project",
+			"actorServiceAccount": "asya-actors@my-project.iam.gserviceaccount.com",
+		},
+	}
+
+	config, err := parseTransportConfig(raw)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pubsubConfig, ok := config.Config.(*PubSubConfig)
+	if !ok {
+		t.Fatalf("Expected PubSubConfig, got %T", config.Config)
+	}
+
+	if pubsubConfig.AckDeadline != 60 {
+		t.Errorf("Expected ackDeadline default 60, got %d", pubsubConfig.AckDeadline)
+	}
+	if !pubsubConfig.Queues.AutoCreate {
+		t.Error("Expected autoCreate to default to true")
+	}
+	if pubsubConfig.Queues.DLQ.MaxRetryCount != 5 || pubsubConfig.Queues.DLQ.RetentionDays != 7 {
+		t.Errorf("Expected DLQ defaults maxRetryCount=5 retentionDays=7, got %+v", pubsubConfig.Queues.DLQ)
+	}
+}
+
+func TestParseTransportConfig_PubSubValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "missing project",
+			config:  map[string]interface{}{},
+			wantErr: "requires projectId",
+		},
+		{
+			name:    "ack deadline too long",
+			config:  map[string]interface{}{"projectId": "p", "ackDeadline": 601},
+			wantErr: "ackDeadline must be between 10 and 600",
+		},
+		{
+			name: "max retry below Pub/Sub minimum",
+			config: map[string]interface{}{
+				"projectId": "p",
+				"queues":    map[string]interface{}{"dlq": map[string]interface{}{"enabled": true, "maxRetryCount": 3}},
+			},
+			wantErr: "maxRetryCount must be between 5 and 100",
+		},
+		{
+			name: "retention beyond subscription limit",
+			config: map[string]interface{}{
+				"projectId": "p",
+				"queues":    map[string]interface{}{"dlq": map[string]interface{}{"enabled": true, "retentionDays": 14}},
+			},
+			wantErr: "retentionDays must be at most 7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTransportConfig(&rawTransportConfig{Type: "pubsub", Enabled: true, Config: tt.config})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildEnvVars_PubSub(t *testing.T) {
+	config := &TransportConfig{
+		Type:    "pubsub",
+		Enabled: true,
+		Config: &PubSubConfig{
+			ProjectID:         "my-project",
+			EmulatorHost:      "pubsub-emulator:8085",
+			AckDeadline:       30,
+			VisibilityTimeout: 600,
+		},
+	}
+
+	env, err := config.BuildEnvVars()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnv := map[string]string{
+		"ASYA_TRANSPORT":                 "pubsub",
+		"ASYA_PUBSUB_PROJECT_ID":         "my-project",
+		"ASYA_PUBSUB_EMULATOR_HOST":      "pubsub-emulator:8085",
+		"ASYA_PUBSUB_ACK_DEADLINE":       "30s",
+		"ASYA_PUBSUB_VISIBILITY_TIMEOUT": "600s",
+	}
+
+	envByName := make(map[string]corev1.EnvVar)
+	for _, e := range env {
+		envByName[e.Name] = e
+	}
+	for key, expectedValue := range expectedEnv {
+		if envByName[key].Value != expectedValue {
+			t.Errorf("Expected env var %s=%s, got %q", key, expectedValue, envByName[key].Value)
+		}
+	}
+
+	if ref := envByName["ASYA_PUBSUB_CLIENT_ID"].ValueFrom; ref == nil || ref.FieldRef.FieldPath != "metadata.name" {
+		t.Errorf("Expected ASYA_PUBSUB_CLIENT_ID from pod name, got %+v", envByName["ASYA_PUBSUB_CLIENT_ID"])
+	}
+}
+
+func TestPubSubConfig_GetQueueMetrics(t *testing.T) {
+	config := &PubSubConfig{ProjectID: "my-project"}
+
+	metrics, err := config.GetQueueMetrics(context.Background(), "asya-default-actor", "default", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if metrics.Queued != 0 || metrics.Processing != nil {
+		t.Errorf("Expected unsupported metrics (0, nil), got queued=%d processing=%v", metrics.Queued, metrics.Processing)
+	}
+}
+
+func TestPubSubConfig_ImplementsInterface(t *testing.T) {
+	var _ TransportSpecificConfig = (*PubSubConfig)(nil)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/deliveryhero/asya/asya-naming"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -26,8 +28,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
 	asyaconfig "github.com/asya/operator/internal/config"
@@ -43,14 +47,25 @@ const (
 	runtimeVolume         = "asya-runtime"
 	runtimeConfigMap      = "asya-runtime"
 	runtimeMountPath      = "/opt/asya/asya_runtime.py"
+	fileQueueVolume       = "asya-queues"
 	transportTypeRabbitMQ = "rabbitmq"
 	transportTypeSQS      = "sqs"
+	transportTypeFile     = "file"
+	transportTypePostgres = "postgres"
+	transportTypeRedis    = "redis"
+	transportTypePubSub   = "pubsub"
 
 	actorNameHappyEnd = "happy-end"
 	actorNameErrorEnd = "error-end"
 
 	transportCredentialsSecretSuffix = "-transport-creds"
 
+	// Envelope signing keyring, copied from the operator's signing secret and mounted into sidecars
+	signingKeysSecretSuffix = "-signing-keys"
+	signingKeysVolume       = "asya-signing-keys"
+	signingKeysMountPath    = "/etc/asya/signing"
+	signingKeyringKey       = "keyring.json"
+
 	defaultQueueHealthCheckInterval = 5 * time.Minute
 
 	podReasonCrashLoopBackOff           = "CrashLoopBackOff"
@@ -161,6 +176,7 @@ type AsyncActorReconciler struct {
 	MaxConcurrentReconciles int
 	GatewayURL              string
 	OperatorNamespace       string // Namespace where operator and transport credentials are located
+	SigningSecretName       string // Secret in OperatorNamespace with the envelope signing keyring (optional)
 }
 
 // +kubebuilder:rbac:groups=asya.sh,resources=asyncactors,verbs=get;list;watch;create;update;patch;delete
@@ -174,6 +190,7 @@ type AsyncActorReconciler struct {
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
 
 // isQueueManagementEnabled checks if queue management is enabled via environment variable
@@ -191,12 +208,23 @@ func (r *AsyncActorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, asya); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("AsyncActor resource not found. Ignoring since object must be deleted")
+			// A deleted version no longer receives envelopes routed to its logical actor
+			if err := r.reconcileActorVersions(ctx, req.Namespace); err != nil {
+				logger.Error(err, "Failed to reconcile actor versions")
+				return ctrl.Result{}, err
+			}
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get AsyncActor")
 		return ctrl.Result{}, err
 	}
 
+	// Keep the namespace's logical actor versions current, including when this actor is being deleted
+	if err := r.reconcileActorVersions(ctx, req.Namespace); err != nil {
+		logger.Error(err, "Failed to reconcile actor versions")
+		return ctrl.Result{}, err
+	}
+
 	// Add finalizer if not present
 	if !controllerutil.ContainsFinalizer(asya, actorFinalizer) {
 		controllerutil.AddFinalizer(asya, actorFinalizer)
@@ -223,7 +251,8 @@ func (r *AsyncActorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Validate transport exists and is enabled
-	if _, err := r.TransportRegistry.GetTransport(asya.Spec.Transport); err != nil {
+	transportConfig, err := r.TransportRegistry.GetTransport(asya.Spec.Transport)
+	if err != nil {
 		logger.Error(err, "Invalid transport configuration", "transport", asya.Spec.Transport)
 		r.setCondition(asya, "TransportReady", metav1.ConditionFalse, "InvalidTransport", err.Error())
 		asya.Status.ObservedGeneration = asya.Generation
@@ -233,6 +262,23 @@ func (r *AsyncActorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 		return ctrl.Result{}, err
 	}
+
+	// Validate the actor's queue name against the transport's limits (e.g. 80 characters on SQS)
+	queueName := r.TransportRegistry.QueueName(asya.Namespace, asya.Name)
+	queueTransport := transportConfig.Type
+	if sqsConfig, ok := transportConfig.Config.(*asyaconfig.SQSConfig); ok && sqsConfig.FIFO {
+		queueTransport = naming.TransportSQSFIFO
+	}
+	if err := naming.ValidateQueueName(queueTransport, queueName); err != nil {
+		logger.Error(err, "Invalid queue name", "queue", queueName)
+		r.setCondition(asya, "TransportReady", metav1.ConditionFalse, "InvalidQueueName", err.Error())
+		asya.Status.ObservedGeneration = asya.Generation
+		r.updateDisplayFields(asya)
+		if updateErr := r.Status().Update(ctx, asya); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{}, err
+	}
 	r.setCondition(asya, "TransportReady", metav1.ConditionTrue, "TransportValidated", "Transport configuration validated")
 
 	// Ensure runtime ConfigMap exists in actor's namespace
@@ -271,12 +317,26 @@ func (r *AsyncActorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Only reconcile ServiceAccount if using Pub/Sub with Workload Identity (actorServiceAccount configured)
+	if asya.Spec.Transport == transportTypePubSub {
+		if err := r.reconcilePubSubServiceAccount(ctx, asya); err != nil {
+			logger.Error(err, "Failed to reconcile ServiceAccount")
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Ensure transport credentials exist in actor's namespace
 	if err := r.reconcileTransportCredentials(ctx, asya); err != nil {
 		logger.Error(err, "Failed to reconcile transport credentials")
 		return ctrl.Result{}, err
 	}
 
+	// Ensure envelope signing keys exist in actor's namespace
+	if err := r.reconcileSigningKeys(ctx, asya); err != nil {
+		logger.Error(err, "Failed to reconcile signing keys")
+		return ctrl.Result{}, err
+	}
+
 	// Reconcile the workload
 	if err := r.reconcileWorkload(ctx, asya); err != nil {
 		logger.Error(err, "Failed to reconcile workload")
@@ -566,6 +626,38 @@ func (r *AsyncActorReconciler) reconcileServiceAccountIfNeeded(ctx context.Conte
 	}
 
 	saName := fmt.Sprintf("asya-%s", asya.Name)
+	return r.reconcileActorServiceAccount(ctx, asya, saName, "eks.amazonaws.com/role-arn", sqsConfig.ActorRoleArnFor(asya.Namespace))
+}
+
+// reconcilePubSubServiceAccount creates or updates the ServiceAccount with the GKE Workload Identity annotation
+// Only creates ServiceAccount if actorServiceAccount is configured
+// Skips for the emulator or clusters relying on node credentials
+func (r *AsyncActorReconciler) reconcilePubSubServiceAccount(ctx context.Context, asya *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	transport, err := r.TransportRegistry.GetTransport(transportTypePubSub)
+	if err != nil {
+		return err
+	}
+
+	pubsubConfig, ok := transport.Config.(*asyaconfig.PubSubConfig)
+	if !ok {
+		return fmt.Errorf("invalid pubsub config type")
+	}
+
+	if pubsubConfig.ActorServiceAccount == "" {
+		logger.Info("Skipping ServiceAccount reconciliation (actorServiceAccount not configured)")
+		return nil
+	}
+
+	// Name must match the pod template so the Workload Identity binding applies
+	saName := fmt.Sprintf("asya-%s-%s", asya.Namespace, asya.Name)
+	return r.reconcileActorServiceAccount(ctx, asya, saName, "iam.gke.io/gcp-service-account", pubsubConfig.ActorServiceAccount)
+}
+
+// reconcileActorServiceAccount creates or updates an actor-owned ServiceAccount carrying a cloud identity annotation
+func (r *AsyncActorReconciler) reconcileActorServiceAccount(ctx context.Context, asya *asyav1alpha1.AsyncActor, saName, annotationKey, annotationValue string) error {
+	logger := log.FromContext(ctx)
 
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -591,7 +683,7 @@ func (r *AsyncActorReconciler) reconcileServiceAccountIfNeeded(ctx context.Conte
 		if sa.Annotations == nil {
 			sa.Annotations = make(map[string]string)
 		}
-		sa.Annotations["eks.amazonaws.com/role-arn"] = sqsConfig.ActorRoleArn
+		sa.Annotations[annotationKey] = annotationValue
 
 		return nil
 	})
@@ -714,6 +806,36 @@ func (r *AsyncActorReconciler) reconcileTransportCredentials(ctx context.Context
 				key:  rabbitConfig.PasswordSecretRef.Key,
 			})
 		}
+
+	case transportTypePostgres:
+		postgresConfig, ok := transportConfig.Config.(*asyaconfig.PostgresConfig)
+		if !ok {
+			return fmt.Errorf("invalid postgres config type")
+		}
+		if postgresConfig.PasswordSecretRef != nil {
+			sourceSecretRefs = append(sourceSecretRefs, struct {
+				name string
+				key  string
+			}{
+				name: postgresConfig.PasswordSecretRef.Name,
+				key:  postgresConfig.PasswordSecretRef.Key,
+			})
+		}
+
+	case transportTypeRedis:
+		redisConfig, ok := transportConfig.Config.(*asyaconfig.RedisConfig)
+		if !ok {
+			return fmt.Errorf("invalid redis config type")
+		}
+		if redisConfig.PasswordSecretRef != nil {
+			sourceSecretRefs = append(sourceSecretRefs, struct {
+				name string
+				key  string
+			}{
+				name: redisConfig.PasswordSecretRef.Name,
+				key:  redisConfig.PasswordSecretRef.Key,
+			})
+		}
 	}
 
 	// If no credentials configured, skip secret creation (e.g., IRSA for SQS)
@@ -788,6 +910,59 @@ func (r *AsyncActorReconciler) reconcileTransportCredentials(ctx context.Context
 	return nil
 }
 
+// reconcileSigningKeys copies the envelope signing keyring from the operator's signing secret
+// into an actor-specific secret in the actor's namespace, owned by the AsyncActor
+func (r *AsyncActorReconciler) reconcileSigningKeys(ctx context.Context, asya *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	if r.SigningSecretName == "" {
+		return nil
+	}
+
+	sourceSecret := &corev1.Secret{}
+	key := client.ObjectKey{Name: r.SigningSecretName, Namespace: r.OperatorNamespace}
+	if err := r.Client.Get(ctx, key, sourceSecret); err != nil {
+		return fmt.Errorf("failed to get signing secret %s/%s: %w", r.OperatorNamespace, r.SigningSecretName, err)
+	}
+	keyring, ok := sourceSecret.Data[signingKeyringKey]
+	if !ok {
+		return fmt.Errorf("key %s not found in signing secret %s/%s", signingKeyringKey, r.OperatorNamespace, r.SigningSecretName)
+	}
+
+	actorSecretName := asya.Name + signingKeysSecretSuffix
+	actorSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      actorSecretName,
+			Namespace: asya.Namespace,
+		},
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, actorSecret, func() error {
+		if err := controllerutil.SetControllerReference(asya, actorSecret, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set owner reference: %w", err)
+		}
+
+		operatorLabels := map[string]string{
+			"app.kubernetes.io/name":       asya.Name,
+			"app.kubernetes.io/component":  "signing-keys",
+			"app.kubernetes.io/part-of":    "asya",
+			"app.kubernetes.io/managed-by": "asya-operator",
+		}
+		actorSecret.Labels = propagateLabels(asya, operatorLabels)
+
+		actorSecret.Type = corev1.SecretTypeOpaque
+		actorSecret.Data = map[string][]byte{signingKeyringKey: keyring}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile signing keys secret: %w", err)
+	}
+
+	logger.Info("Signing keys secret reconciled", "result", result, "secret", actorSecretName, "namespace", asya.Namespace)
+	return nil
+}
+
 // reconcileWorkload creates or updates the workload (Deployment or StatefulSet)
 func (r *AsyncActorReconciler) reconcileWorkload(ctx context.Context, asya *asyav1alpha1.AsyncActor) error {
 	_ = log.FromContext(ctx)
@@ -795,6 +970,11 @@ func (r *AsyncActorReconciler) reconcileWorkload(ctx context.Context, asya *asya
 	// Inject sidecar into pod template
 	podTemplate := r.injectSidecar(asya)
 
+	// Let the sidecar route to namespaces that accept envelopes from this one
+	if err := r.setAllowedTargetNamespaces(ctx, asya, &podTemplate); err != nil {
+		return err
+	}
+
 	switch asya.Spec.Workload.Kind {
 	case "Deployment", "":
 		return r.reconcileDeployment(ctx, asya, podTemplate)
@@ -805,6 +985,37 @@ func (r *AsyncActorReconciler) reconcileWorkload(ctx context.Context, asya *asya
 	}
 }
 
+// setAllowedTargetNamespaces sets ASYA_ALLOWED_TARGET_NAMESPACES on the sidecar to the namespaces whose
+// asya.sh/allowed-source-namespaces annotation accepts envelopes from the actor's namespace.
+// The sidecar rejects "namespace/actor" routes to any other namespace.
+func (r *AsyncActorReconciler) setAllowedTargetNamespaces(ctx context.Context, asya *asyav1alpha1.AsyncActor, podTemplate *corev1.PodTemplateSpec) error {
+	targets, err := transports.AllowedTargetNamespaces(ctx, r.Client, asya.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to resolve cross-namespace route targets: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	for i := range podTemplate.Spec.Containers {
+		container := &podTemplate.Spec.Containers[i]
+		if container.Name != sidecarName {
+			continue
+		}
+		// Values from spec.sidecar.env take precedence
+		for _, e := range container.Env {
+			if e.Name == "ASYA_ALLOWED_TARGET_NAMESPACES" {
+				return nil
+			}
+		}
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "ASYA_ALLOWED_TARGET_NAMESPACES",
+			Value: strings.Join(targets, ","),
+		})
+	}
+	return nil
+}
+
 // injectSidecar injects the sidecar container into the pod template
 func (r *AsyncActorReconciler) injectSidecar(asya *asyav1alpha1.AsyncActor) corev1.PodTemplateSpec {
 	template := corev1.PodTemplateSpec{
@@ -856,6 +1067,35 @@ func (r *AsyncActorReconciler) injectSidecar(asya *asyav1alpha1.AsyncActor) core
 		},
 	}
 
+	// File transport queues live on a shared volume mounted into the sidecar only
+	if volume, mountPath, ok := r.buildFileQueueVolume(asya); ok {
+		sidecarContainer.VolumeMounts = append(sidecarContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      fileQueueVolume,
+			MountPath: mountPath,
+		})
+		template.Spec.Volumes = append(template.Spec.Volumes, volume)
+	}
+
+	// Signing keyring is mounted as a volume so key rotations reach running sidecars
+	if r.SigningSecretName != "" {
+		sidecarContainer.VolumeMounts = append(sidecarContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      signingKeysVolume,
+			MountPath: signingKeysMountPath,
+			ReadOnly:  true,
+		})
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: signingKeysVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: asya.Name + signingKeysSecretSuffix,
+				},
+			},
+		})
+	}
+
+	// Logical actor versions are mounted as a volume so weight changes reach running sidecars
+	mountActorVersions(&sidecarContainer, &template.Spec)
+
 	// Add sidecar to containers (append at end to preserve container ordering)
 	template.Spec.Containers = append(template.Spec.Containers, sidecarContainer)
 
@@ -991,6 +1231,37 @@ func (r *AsyncActorReconciler) injectSidecar(asya *asyav1alpha1.AsyncActor) core
 	return template
 }
 
+// buildFileQueueVolume returns the queue volume and its mount path for file transport actors
+func (r *AsyncActorReconciler) buildFileQueueVolume(asya *asyav1alpha1.AsyncActor) (corev1.Volume, string, bool) {
+	if asya.Spec.Transport != transportTypeFile {
+		return corev1.Volume{}, "", false
+	}
+
+	transport, err := r.TransportRegistry.GetTransport(transportTypeFile)
+	if err != nil {
+		return corev1.Volume{}, "", false
+	}
+	fileConfig, ok := transport.Config.(*asyaconfig.FileConfig)
+	if !ok {
+		return corev1.Volume{}, "", false
+	}
+
+	volume := corev1.Volume{Name: fileQueueVolume}
+	if fileConfig.ClaimName != "" {
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: fileConfig.ClaimName,
+		}
+	} else {
+		hostPathType := corev1.HostPathDirectoryOrCreate
+		volume.HostPath = &corev1.HostPathVolumeSource{
+			Path: fileConfig.HostPath,
+			Type: &hostPathType,
+		}
+	}
+
+	return volume, fileConfig.MountPath(), true
+}
+
 // extractGatewayURLFromRuntime extracts ASYA_GATEWAY_URL from runtime container env vars
 func (r *AsyncActorReconciler) extractGatewayURLFromRuntime(asya *asyav1alpha1.AsyncActor) string {
 	if asya.Spec.Workload.Template.Spec.Containers == nil {
@@ -1035,6 +1306,14 @@ func (r *AsyncActorReconciler) buildSidecarEnv(asya *asyav1alpha1.AsyncActor) []
 		})
 	}
 
+	// Verify and sign envelopes with the keyring mounted by injectSidecar
+	if r.SigningSecretName != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "ASYA_SIGNING_KEYRING_PATH",
+			Value: signingKeysMountPath + "/" + signingKeyringKey,
+		})
+	}
+
 	// Add processing timeout
 	if asya.Spec.Timeout.Processing > 0 {
 		env = append(env, corev1.EnvVar{
@@ -1043,6 +1322,52 @@ func (r *AsyncActorReconciler) buildSidecarEnv(asya *asyav1alpha1.AsyncActor) []
 		})
 	}
 
+	// Add route policy enforced on routes returned by the runtime
+	if asya.Spec.RoutePolicy != nil {
+		if routePolicy, err := json.Marshal(asya.Spec.RoutePolicy); err == nil {
+			env = append(env, corev1.EnvVar{
+				Name:  "ASYA_ROUTE_POLICY",
+				Value: string(routePolicy),
+			})
+		}
+	}
+
+	// Add retry policy applied to runtime errors
+	if asya.Spec.RetryPolicy != nil {
+		if retryPolicy, err := json.Marshal(asya.Spec.RetryPolicy); err == nil {
+			env = append(env, corev1.EnvVar{
+				Name:  "ASYA_RETRY_POLICY",
+				Value: string(retryPolicy),
+			})
+		}
+	}
+
+	// Add shadow traffic mirroring
+	if asya.Spec.Shadow != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "ASYA_SHADOW_ACTOR",
+			Value: asya.Spec.Shadow.Actor,
+		})
+		if asya.Spec.Shadow.Percent != nil {
+			env = append(env, corev1.EnvVar{
+				Name:  "ASYA_SHADOW_PERCENT",
+				Value: fmt.Sprintf("%d", *asya.Spec.Shadow.Percent),
+			})
+		}
+	}
+
+	// Name queues with the operator's naming scheme (unset segments keep the sidecar defaults)
+	queueNaming := r.TransportRegistry.QueueNaming
+	for _, e := range []corev1.EnvVar{
+		{Name: naming.EnvPrefix, Value: queueNaming.Prefix},
+		{Name: naming.EnvCluster, Value: queueNaming.Cluster},
+		{Name: naming.EnvSuffix, Value: queueNaming.Suffix},
+	} {
+		if e.Value != "" {
+			env = append(env, e)
+		}
+	}
+
 	// Get transport config from registry and build env vars
 	transport, err := r.TransportRegistry.GetTransport(asya.Spec.Transport)
 	if err != nil {
@@ -1116,6 +1441,38 @@ func (r *AsyncActorReconciler) buildSidecarEnv(asya *asyav1alpha1.AsyncActor) []
 				},
 			})
 		}
+
+	case transportTypePostgres:
+		postgresConfig, ok := transport.Config.(*asyaconfig.PostgresConfig)
+		if ok && postgresConfig.PasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{
+				Name: "ASYA_POSTGRES_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: actorSecretName,
+						},
+						Key: postgresConfig.PasswordSecretRef.Key,
+					},
+				},
+			})
+		}
+
+	case transportTypeRedis:
+		redisConfig, ok := transport.Config.(*asyaconfig.RedisConfig)
+		if ok && redisConfig.PasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{
+				Name: "ASYA_REDIS_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: actorSecretName,
+						},
+						Key: redisConfig.PasswordSecretRef.Key,
+					},
+				},
+			})
+		}
 	}
 
 	return env
@@ -1312,6 +1669,20 @@ func (r *AsyncActorReconciler) reconcileDeployment(ctx context.Context, asya *as
 			}
 		}
 
+		// Set ServiceAccount for Pub/Sub transport (only if using Workload Identity)
+		if asya.Spec.Transport == transportTypePubSub {
+			transport, err := r.TransportRegistry.GetTransport(transportTypePubSub)
+			if err == nil {
+				if pubsubConfig, ok := transport.Config.(*asyaconfig.PubSubConfig); ok && pubsubConfig.ActorServiceAccount != "" {
+					userProvidedSA := asya.Spec.Workload.Template.Spec.ServiceAccountName
+					if userProvidedSA != "" {
+						return fmt.Errorf("cannot use custom serviceAccountName %q when Workload Identity (actorServiceAccount) is configured: remove serviceAccountName from spec or disable Workload Identity", userProvidedSA)
+					}
+					podTemplate.Spec.ServiceAccountName = fmt.Sprintf("asya-%s-%s", asya.Namespace, asya.Name)
+				}
+			}
+		}
+
 		deployment.Spec.Template = podTemplate
 
 		return nil
@@ -1452,7 +1823,7 @@ func (r *AsyncActorReconciler) updateQueueMetrics(ctx context.Context, asya *asy
 		return
 	}
 
-	queueName := fmt.Sprintf("asya-%s", asya.Name)
+	queueName := r.TransportRegistry.QueueName(asya.Namespace, asya.Name)
 
 	var metrics *asyaconfig.QueueMetrics
 
@@ -1508,7 +1879,7 @@ func (r *AsyncActorReconciler) GetSQSQueueMetrics(ctx context.Context, asya *asy
 	}
 
 	urlResult, err := sqsClient.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName),
+		QueueName: aws.String(sqsConfig.QueueName(queueName)),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get queue URL: %w", err)
@@ -1603,12 +1974,73 @@ func (r *AsyncActorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&appsv1.StatefulSet{}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrent})
 
+	// Re-copy the signing keyring to all actors when the operator's signing secret changes (key rotation)
+	if r.SigningSecretName != "" {
+		bldr = bldr.Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.actorsForSigningSecret),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetNamespace() == r.OperatorNamespace && obj.GetName() == r.SigningSecretName
+			})))
+	}
+
+	// Re-resolve cross-namespace routing (sidecar targets, SQS queue policies) when a namespace opts in or out
+	crossNamespaceOptInChanged := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetAnnotations()[transports.AllowedSourceNamespacesAnnotation] != ""
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[transports.AllowedSourceNamespacesAnnotation] !=
+				e.ObjectNew.GetAnnotations()[transports.AllowedSourceNamespacesAnnotation]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetAnnotations()[transports.AllowedSourceNamespacesAnnotation] != ""
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	bldr = bldr.Watches(&corev1.Namespace{},
+		handler.EnqueueRequestsFromMapFunc(r.actorsForNamespace),
+		builder.WithPredicates(crossNamespaceOptInChanged))
+
+	// Rewrite the namespace's actor versions ConfigMap when a version is added, changed or removed,
+	// including changes the generation predicate above filters out
+	bldr = bldr.Watches(&asyav1alpha1.AsyncActor{},
+		handler.EnqueueRequestsFromMapFunc(actorVersionsRequest),
+		builder.WithPredicates(logicalActorChanged))
+
 	// Start periodic queue health check
 	go r.startPeriodicQueueHealthCheck(mgr)
 
 	return bldr.Complete(r)
 }
 
+// actorsForSigningSecret returns reconcile requests for all AsyncActors
+func (r *AsyncActorReconciler) actorsForSigningSecret(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.allActorRequests(ctx, "signing secret change")
+}
+
+// actorsForNamespace returns reconcile requests for all AsyncActors, since a namespace's opt-in
+// affects its own queues and the sidecars of every namespace it accepts envelopes from
+func (r *AsyncActorReconciler) actorsForNamespace(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.allActorRequests(ctx, "namespace opt-in change")
+}
+
+// allActorRequests returns reconcile requests for all AsyncActors
+func (r *AsyncActorReconciler) allActorRequests(ctx context.Context, reason string) []reconcile.Request {
+	asyncActors := &asyav1alpha1.AsyncActorList{}
+	if err := r.List(ctx, asyncActors); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AsyncActors for "+reason)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(asyncActors.Items))
+	for _, actor := range asyncActors.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: actor.Name, Namespace: actor.Namespace},
+		})
+	}
+	return requests
+}
+
 // startPeriodicQueueHealthCheck triggers periodic reconciliation for queue health monitoring
 func (r *AsyncActorReconciler) startPeriodicQueueHealthCheck(mgr ctrl.Manager) {
 	logger := mgr.GetLogger().WithName("queue-health-checker")
@@ -1647,7 +2079,7 @@ func (r *AsyncActorReconciler) startPeriodicQueueHealthCheck(mgr ctrl.Manager) {
 				continue
 			}
 
-			queueName := fmt.Sprintf("asya-%s", actor.Name)
+			queueName := r.TransportRegistry.QueueName(actor.Namespace, actor.Name)
 
 			// Get queue reconciler for the actor's transport
 			queueReconciler, err := r.TransportFactory.GetQueueReconciler(actor.Spec.Transport)
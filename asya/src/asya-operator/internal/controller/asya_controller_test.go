@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/deliveryhero/asya/asya-naming"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -598,6 +599,116 @@ func TestBuildSidecarEnv(t *testing.T) {
 		}
 	})
 
+	t.Run("with route policy", func(t *testing.T) {
+		asya := &asyav1alpha1.AsyncActor{
+			Spec: asyav1alpha1.AsyncActorSpec{
+				Transport: testTransportRabbitMQ,
+				RoutePolicy: &asyav1alpha1.RoutePolicyConfig{
+					AllowedNext:         []string{"scorer", "reviewer"},
+					MaxRouteLength:      8,
+					ForbiddenInsertions: []string{"billing"},
+				},
+			},
+		}
+
+		env := r.buildSidecarEnv(asya)
+
+		envMap := make(map[string]string)
+		for _, e := range env {
+			envMap[e.Name] = e.Value
+		}
+
+		expected := `{"allowedNext":["scorer","reviewer"],"maxRouteLength":8,"forbiddenInsertions":["billing"]}`
+		if envMap["ASYA_ROUTE_POLICY"] != expected {
+			t.Errorf("Expected ASYA_ROUTE_POLICY=%s, got %q", expected, envMap["ASYA_ROUTE_POLICY"])
+		}
+	})
+
+	t.Run("without route policy", func(t *testing.T) {
+		asya := &asyav1alpha1.AsyncActor{
+			Spec: asyav1alpha1.AsyncActorSpec{
+				Transport: testTransportRabbitMQ,
+			},
+		}
+
+		for _, e := range r.buildSidecarEnv(asya) {
+			if e.Name == "ASYA_ROUTE_POLICY" {
+				t.Errorf("Expected no ASYA_ROUTE_POLICY, got %q", e.Value)
+			}
+		}
+	})
+
+	t.Run("with retry policy", func(t *testing.T) {
+		asya := &asyav1alpha1.AsyncActor{
+			Spec: asyav1alpha1.AsyncActorSpec{
+				Transport: testTransportRabbitMQ,
+				RetryPolicy: &asyav1alpha1.RetryPolicyConfig{
+					MaxAttempts:           5,
+					RetryOn:               []string{"TimeoutError"},
+					Permanent:             []string{"ValueError"},
+					InitialBackoffSeconds: 2,
+				},
+			},
+		}
+
+		envMap := make(map[string]string)
+		for _, e := range r.buildSidecarEnv(asya) {
+			envMap[e.Name] = e.Value
+		}
+
+		expected := `{"maxAttempts":5,"retryOn":["TimeoutError"],"permanent":["ValueError"],"initialBackoffSeconds":2}`
+		if envMap["ASYA_RETRY_POLICY"] != expected {
+			t.Errorf("Expected ASYA_RETRY_POLICY=%s, got %q", expected, envMap["ASYA_RETRY_POLICY"])
+		}
+	})
+
+	t.Run("with shadow actor", func(t *testing.T) {
+		percent := int32(0)
+		asya := &asyav1alpha1.AsyncActor{
+			Spec: asyav1alpha1.AsyncActorSpec{
+				Transport: testTransportRabbitMQ,
+				Shadow: &asyav1alpha1.ShadowConfig{
+					Actor:   "scorer-v2",
+					Percent: &percent,
+				},
+			},
+		}
+
+		envMap := make(map[string]string)
+		for _, e := range r.buildSidecarEnv(asya) {
+			envMap[e.Name] = e.Value
+		}
+		if envMap["ASYA_SHADOW_ACTOR"] != "scorer-v2" {
+			t.Errorf("Expected ASYA_SHADOW_ACTOR=scorer-v2, got %q", envMap["ASYA_SHADOW_ACTOR"])
+		}
+		if envMap["ASYA_SHADOW_PERCENT"] != "0" {
+			t.Errorf("Expected ASYA_SHADOW_PERCENT=0, got %q", envMap["ASYA_SHADOW_PERCENT"])
+		}
+	})
+
+	t.Run("queue naming scheme", func(t *testing.T) {
+		r := &AsyncActorReconciler{TransportRegistry: &asyaconfig.TransportRegistry{
+			Transports:  r.TransportRegistry.Transports,
+			QueueNaming: naming.Scheme{Cluster: "eu1", Suffix: "q"},
+		}}
+		asya := &asyav1alpha1.AsyncActor{
+			Spec: asyav1alpha1.AsyncActorSpec{
+				Transport: testTransportRabbitMQ,
+			},
+		}
+
+		envMap := make(map[string]string)
+		for _, e := range r.buildSidecarEnv(asya) {
+			envMap[e.Name] = e.Value
+		}
+		if envMap["ASYA_CLUSTER_NAME"] != "eu1" || envMap["ASYA_QUEUE_SUFFIX"] != "q" {
+			t.Errorf("Expected ASYA_CLUSTER_NAME=eu1 and ASYA_QUEUE_SUFFIX=q, got %q and %q", envMap["ASYA_CLUSTER_NAME"], envMap["ASYA_QUEUE_SUFFIX"])
+		}
+		if _, exists := envMap["ASYA_QUEUE_PREFIX"]; exists {
+			t.Errorf("Expected ASYA_QUEUE_PREFIX to not be set for the default prefix")
+		}
+	})
+
 	t.Run("invalid transport returns basic env", func(t *testing.T) {
 		asya := &asyav1alpha1.AsyncActor{
 			Spec: asyav1alpha1.AsyncActorSpec{
@@ -1102,6 +1213,211 @@ func TestReconcileDeployment_SQSServiceAccount(t *testing.T) {
 	}
 }
 
+func TestSetAllowedTargetNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = asyav1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	namespaces := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-b",
+			Annotations: map[string]string{transports.AllowedSourceNamespacesAnnotation: "default"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-c",
+			Annotations: map[string]string{transports.AllowedSourceNamespacesAnnotation: "team-b"},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		namespace  string
+		sidecarEnv []corev1.EnvVar
+		expected   string
+	}{
+		{name: "opted-in target namespaces", namespace: "default", expected: "team-b"},
+		{name: "no opted-in target namespaces", namespace: "team-c", expected: ""},
+		{
+			name:       "sidecar env takes precedence",
+			namespace:  "default",
+			sidecarEnv: []corev1.EnvVar{{Name: "ASYA_ALLOWED_TARGET_NAMESPACES", Value: "team-x"}},
+			expected:   "team-x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &AsyncActorReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespaces...).Build(),
+				Scheme: scheme,
+				TransportRegistry: &asyaconfig.TransportRegistry{
+					Transports: map[string]*asyaconfig.TransportConfig{
+						testTransportRabbitMQ: {
+							Type:    testTransportRabbitMQ,
+							Enabled: true,
+							Config:  &asyaconfig.RabbitMQConfig{Host: "localhost", Port: 5672, Username: "guest"},
+						},
+					},
+				},
+			}
+
+			asya := &asyav1alpha1.AsyncActor{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-actor", Namespace: tt.namespace},
+				Spec: asyav1alpha1.AsyncActorSpec{
+					Transport: testTransportRabbitMQ,
+					Sidecar:   asyav1alpha1.SidecarConfig{Env: tt.sidecarEnv},
+					Workload: asyav1alpha1.WorkloadConfig{
+						Template: asyav1alpha1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "asya-runtime", Image: "python:3.13-slim"}},
+							},
+						},
+					},
+				},
+			}
+
+			podTemplate := r.injectSidecar(asya)
+			if err := r.setAllowedTargetNamespaces(context.Background(), asya, &podTemplate); err != nil {
+				t.Fatalf("setAllowedTargetNamespaces failed: %v", err)
+			}
+
+			var values []string
+			for _, container := range podTemplate.Spec.Containers {
+				if container.Name != sidecarName {
+					continue
+				}
+				for _, e := range container.Env {
+					if e.Name == "ASYA_ALLOWED_TARGET_NAMESPACES" {
+						values = append(values, e.Value)
+					}
+				}
+			}
+			if tt.expected == "" {
+				if len(values) != 0 {
+					t.Errorf("Expected no ASYA_ALLOWED_TARGET_NAMESPACES, got %v", values)
+				}
+				return
+			}
+			if len(values) != 1 || values[0] != tt.expected {
+				t.Errorf("Expected ASYA_ALLOWED_TARGET_NAMESPACES=%s once, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestReconcilePubSubServiceAccount_WorkloadIdentity(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = asyav1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	r := &AsyncActorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+		TransportRegistry: &asyaconfig.TransportRegistry{
+			Transports: map[string]*asyaconfig.TransportConfig{
+				transportTypePubSub: {
+					Type:    transportTypePubSub,
+					Enabled: true,
+					Config: &asyaconfig.PubSubConfig{
+						ProjectID:           "my-project",
+						ActorServiceAccount: "asya-actors@my-project.iam.gserviceaccount.com",
+						AckDeadline:         60,
+					},
+				},
+			},
+		},
+	}
+
+	asya := &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-actor",
+			Namespace: "default",
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypePubSub,
+			Workload: asyav1alpha1.WorkloadConfig{
+				Template: asyav1alpha1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "asya-runtime",
+								Image: "python:3.13-slim",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := r.reconcilePubSubServiceAccount(context.Background(), asya); err != nil {
+		t.Fatalf("reconcilePubSubServiceAccount failed: %v", err)
+	}
+
+	sa := &corev1.ServiceAccount{}
+	if err := r.Get(context.Background(), client.ObjectKey{Name: "asya-default-test-actor", Namespace: "default"}, sa); err != nil {
+		t.Fatalf("Failed to get ServiceAccount: %v", err)
+	}
+	if sa.Annotations["iam.gke.io/gcp-service-account"] != "asya-actors@my-project.iam.gserviceaccount.com" {
+		t.Errorf("Expected Workload Identity annotation, got %v", sa.Annotations)
+	}
+
+	podTemplate := r.injectSidecar(asya)
+	if err := r.reconcileDeployment(context.Background(), asya, podTemplate); err != nil {
+		t.Fatalf("reconcileDeployment failed: %v", err)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(context.Background(), client.ObjectKey{Name: asya.Name, Namespace: asya.Namespace}, deployment); err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if deployment.Spec.Template.Spec.ServiceAccountName != sa.Name {
+		t.Errorf("Expected ServiceAccountName %q, got %q", sa.Name, deployment.Spec.Template.Spec.ServiceAccountName)
+	}
+}
+
+func TestReconcilePubSubServiceAccount_SkipsWhenNoActorServiceAccount(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = asyav1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &AsyncActorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+		TransportRegistry: &asyaconfig.TransportRegistry{
+			Transports: map[string]*asyaconfig.TransportConfig{
+				transportTypePubSub: {
+					Type:    transportTypePubSub,
+					Enabled: true,
+					Config:  &asyaconfig.PubSubConfig{ProjectID: "my-project", EmulatorHost: "pubsub-emulator:8085"},
+				},
+			},
+		},
+	}
+
+	asya := &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-actor",
+			Namespace: "default",
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypePubSub,
+		},
+	}
+
+	if err := r.reconcilePubSubServiceAccount(context.Background(), asya); err != nil {
+		t.Fatalf("Expected no error when actorServiceAccount is empty, got: %v", err)
+	}
+
+	sa := &corev1.ServiceAccount{}
+	err := r.Get(context.Background(), client.ObjectKey{Name: "asya-default-test-actor", Namespace: "default"}, sa)
+	if err == nil {
+		t.Error("Expected ServiceAccount to NOT be created when actorServiceAccount is empty")
+	}
+}
+
 func TestReconcileDeployment_RabbitMQNoServiceAccount(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = asyav1alpha1.AddToScheme(scheme)
@@ -3406,3 +3722,247 @@ func TestReconcileTransportCredentials_NoCredentialsConfigured(t *testing.T) {
 		t.Errorf("Expected no secret to be created for IRSA transport, but found one")
 	}
 }
+
+func TestInjectSidecar_FileTransportVolume(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *asyaconfig.FileConfig
+		mountPath  string
+		checkMount func(t *testing.T, volume corev1.Volume)
+	}{
+		{
+			name:      "hostPath",
+			config:    &asyaconfig.FileConfig{HostPath: "/mnt/asya-queues"},
+			mountPath: asyaconfig.DefaultFileRoot,
+			checkMount: func(t *testing.T, volume corev1.Volume) {
+				if volume.HostPath == nil || volume.HostPath.Path != "/mnt/asya-queues" {
+					t.Errorf("Expected hostPath /mnt/asya-queues, got %+v", volume.VolumeSource)
+				}
+			},
+		},
+		{
+			name:      "persistentVolumeClaim",
+			config:    &asyaconfig.FileConfig{Root: "/queues", ClaimName: "asya-queues"},
+			mountPath: "/queues",
+			checkMount: func(t *testing.T, volume corev1.Volume) {
+				if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "asya-queues" {
+					t.Errorf("Expected PVC asya-queues, got %+v", volume.VolumeSource)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &AsyncActorReconciler{
+				TransportRegistry: &asyaconfig.TransportRegistry{
+					Transports: map[string]*asyaconfig.TransportConfig{
+						"file": {Type: "file", Enabled: true, Config: tt.config},
+					},
+				},
+			}
+
+			asya := &asyav1alpha1.AsyncActor{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-actor", Namespace: "default"},
+				Spec: asyav1alpha1.AsyncActorSpec{
+					Transport: "file",
+					Workload: asyav1alpha1.WorkloadConfig{
+						Template: asyav1alpha1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: testContainerRuntime, Image: "python:3.13-slim"}},
+							},
+						},
+					},
+				},
+			}
+
+			result := r.injectSidecar(asya)
+
+			var queueVolume *corev1.Volume
+			for i := range result.Spec.Volumes {
+				if result.Spec.Volumes[i].Name == fileQueueVolume {
+					queueVolume = &result.Spec.Volumes[i]
+				}
+			}
+			if queueVolume == nil {
+				t.Fatal("Queue volume not found")
+			}
+			tt.checkMount(t, *queueVolume)
+
+			for _, c := range result.Spec.Containers {
+				mounted := false
+				for _, m := range c.VolumeMounts {
+					if m.Name == fileQueueVolume {
+						mounted = true
+						if m.MountPath != tt.mountPath {
+							t.Errorf("Expected mount path %s, got %s", tt.mountPath, m.MountPath)
+						}
+					}
+				}
+				if c.Name == sidecarName && !mounted {
+					t.Error("Queue volume not mounted in sidecar")
+				}
+				if c.Name == testContainerRuntime && mounted {
+					t.Error("Queue volume should not be mounted in runtime container")
+				}
+			}
+
+			env := map[string]string{}
+			for _, c := range result.Spec.Containers {
+				if c.Name == sidecarName {
+					for _, e := range c.Env {
+						env[e.Name] = e.Value
+					}
+				}
+			}
+			if env["ASYA_TRANSPORT"] != "file" || env["ASYA_FILE_ROOT"] != tt.mountPath {
+				t.Errorf("Unexpected sidecar transport env: ASYA_TRANSPORT=%q ASYA_FILE_ROOT=%q", env["ASYA_TRANSPORT"], env["ASYA_FILE_ROOT"])
+			}
+		})
+	}
+}
+
+func TestReconcileSigningKeys(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = asyav1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	signingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "asya-signing", Namespace: "asya-system"},
+		Data:       map[string][]byte{"keyring.json": []byte(`{"active":"k1","keys":{"k1":"..."}}`)},
+	}
+	invalidSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "asya-signing-invalid", Namespace: "asya-system"},
+		Data:       map[string][]byte{"other": []byte("x")},
+	}
+
+	tests := []struct {
+		name        string
+		secretName  string
+		wantErr     bool
+		wantKeyring string
+	}{
+		{name: "disabled", secretName: ""},
+		{name: "copies keyring", secretName: "asya-signing", wantKeyring: `{"active":"k1","keys":{"k1":"..."}}`},
+		{name: "missing keyring key", secretName: "asya-signing-invalid", wantErr: true},
+		{name: "missing secret", secretName: "does-not-exist", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(signingSecret, invalidSecret).Build()
+			r := &AsyncActorReconciler{
+				Client:            fakeClient,
+				Scheme:            scheme,
+				OperatorNamespace: "asya-system",
+				SigningSecretName: tt.secretName,
+			}
+
+			asya := &asyav1alpha1.AsyncActor{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-actor", Namespace: "asya-e2e", UID: "test-uid-12345"},
+			}
+			if err := fakeClient.Create(context.Background(), asya); err != nil {
+				t.Fatalf("Failed to create AsyncActor: %v", err)
+			}
+
+			err := r.reconcileSigningKeys(context.Background(), asya)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reconcileSigningKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			actorSecret := &corev1.Secret{}
+			getErr := fakeClient.Get(context.Background(),
+				client.ObjectKey{Name: "test-actor-signing-keys", Namespace: "asya-e2e"}, actorSecret)
+			if tt.wantKeyring == "" {
+				if getErr == nil {
+					t.Error("Expected no signing keys secret to be created")
+				}
+				return
+			}
+			if getErr != nil {
+				t.Fatalf("Failed to get actor signing keys secret: %v", getErr)
+			}
+			if string(actorSecret.Data["keyring.json"]) != tt.wantKeyring {
+				t.Errorf("Expected keyring %q, got %q", tt.wantKeyring, actorSecret.Data["keyring.json"])
+			}
+			if len(actorSecret.OwnerReferences) != 1 || actorSecret.OwnerReferences[0].Name != "test-actor" {
+				t.Errorf("Expected owner reference to test-actor, got %+v", actorSecret.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestInjectSidecar_SigningKeys(t *testing.T) {
+	for _, secretName := range []string{"", "asya-signing"} {
+		t.Run("secret="+secretName, func(t *testing.T) {
+			r := &AsyncActorReconciler{
+				TransportRegistry: &asyaconfig.TransportRegistry{
+					Transports: map[string]*asyaconfig.TransportConfig{
+						testTransportRabbitMQ: {Type: testTransportRabbitMQ, Enabled: true, Config: &asyaconfig.RabbitMQConfig{Host: "rabbitmq"}},
+					},
+				},
+				SigningSecretName: secretName,
+			}
+
+			asya := &asyav1alpha1.AsyncActor{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-actor", Namespace: "default"},
+				Spec: asyav1alpha1.AsyncActorSpec{
+					Transport: testTransportRabbitMQ,
+					Workload: asyav1alpha1.WorkloadConfig{
+						Template: asyav1alpha1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: testContainerRuntime, Image: "python:3.13-slim"}},
+							},
+						},
+					},
+				},
+			}
+
+			result := r.injectSidecar(asya)
+			enabled := secretName != ""
+
+			var volume *corev1.Volume
+			for i := range result.Spec.Volumes {
+				if result.Spec.Volumes[i].Name == signingKeysVolume {
+					volume = &result.Spec.Volumes[i]
+				}
+			}
+			if (volume != nil) != enabled {
+				t.Fatalf("Signing keys volume present = %v, want %v", volume != nil, enabled)
+			}
+			if enabled && (volume.Secret == nil || volume.Secret.SecretName != "test-actor-signing-keys") {
+				t.Errorf("Expected volume from secret test-actor-signing-keys, got %+v", volume.VolumeSource)
+			}
+
+			for _, c := range result.Spec.Containers {
+				mounted := false
+				for _, m := range c.VolumeMounts {
+					if m.Name == signingKeysVolume {
+						mounted = m.ReadOnly && m.MountPath == signingKeysMountPath
+					}
+				}
+				keyringPath := ""
+				for _, e := range c.Env {
+					if e.Name == "ASYA_SIGNING_KEYRING_PATH" {
+						keyringPath = e.Value
+					}
+				}
+
+				if c.Name == sidecarName {
+					if mounted != enabled {
+						t.Errorf("Sidecar signing keys mounted = %v, want %v", mounted, enabled)
+					}
+					if enabled && keyringPath != "/etc/asya/signing/keyring.json" {
+						t.Errorf("Expected ASYA_SIGNING_KEYRING_PATH=/etc/asya/signing/keyring.json, got %q", keyringPath)
+					}
+					if !enabled && keyringPath != "" {
+						t.Errorf("Expected no ASYA_SIGNING_KEYRING_PATH, got %q", keyringPath)
+					}
+				}
+				if c.Name == testContainerRuntime && mounted {
+					t.Error("Signing keys should not be mounted in runtime container")
+				}
+			}
+		})
+	}
+}
@@ -262,10 +262,8 @@ func (r *AsyncActorReconciler) reconcileScaledObject(ctx context.Context, asya *
 // resolveQueueIdentifier resolves actor name to queue identifier based on transport
 func (r *AsyncActorReconciler) resolveQueueIdentifier(asya *asyav1alpha1.AsyncActor, transport *asyaconfig.TransportConfig) (string, error) {
 	switch transport.Type {
-	case transportTypeRabbitMQ:
-		return fmt.Sprintf("asya-%s-%s", asya.Namespace, asya.Name), nil
-	case transportTypeSQS:
-		return fmt.Sprintf("asya-%s-%s", asya.Namespace, asya.Name), nil
+	case transportTypeRabbitMQ, transportTypeSQS, transportTypeFile, transportTypePostgres, transportTypeRedis, transportTypePubSub:
+		return r.TransportRegistry.QueueName(asya.Namespace, asya.Name), nil
 	default:
 		return asya.Name, nil
 	}
@@ -289,6 +287,15 @@ func (r *AsyncActorReconciler) buildKEDATriggers(ctx context.Context, asya *asya
 		return r.buildSQSTrigger(asya, transport, queueLength)
 	case transportTypeRabbitMQ:
 		return r.buildRabbitMQTrigger(ctx, asya, transport, queueLength)
+	case transportTypePostgres:
+		return r.buildPostgresTrigger(ctx, asya, transport, queueLength)
+	case transportTypeRedis:
+		return r.buildRedisStreamsTrigger(ctx, asya, transport, queueLength)
+	case transportTypePubSub:
+		return r.buildGCPPubSubTrigger(ctx, asya, transport, queueLength)
+	case transportTypeFile:
+		// KEDA has no scaler that can observe a shared directory
+		return nil, fmt.Errorf("transport type %s does not support KEDA scaling, set spec.scaling.enabled=false", transport.Type)
 	default:
 		return nil, fmt.Errorf("unsupported transport type: %s", transport.Type)
 	}
@@ -309,7 +316,7 @@ func (r *AsyncActorReconciler) buildSQSTrigger(asya *asyav1alpha1.AsyncActor, tr
 		return nil, fmt.Errorf("SQS accountId is required in operator transport config")
 	}
 
-	queueName := fmt.Sprintf("asya-%s-%s", asya.Namespace, asya.Name)
+	queueName := config.QueueName(r.TransportRegistry.QueueName(asya.Namespace, asya.Name))
 
 	metadata := map[string]string{
 		"queueLength": queueLength,
@@ -409,6 +416,143 @@ func (r *AsyncActorReconciler) buildRabbitMQTrigger(ctx context.Context, asya *a
 	return []kedav1alpha1.ScaleTriggers{trigger}, nil
 }
 
+// buildPostgresTrigger builds a PostgreSQL KEDA trigger counting every row in the actor queue.
+// Leased rows (in flight in a sidecar) and delayed retries count too, so an actor with work in
+// progress is not scaled to zero.
+func (r *AsyncActorReconciler) buildPostgresTrigger(ctx context.Context, asya *asyav1alpha1.AsyncActor, transport *asyaconfig.TransportConfig, queueLength string) ([]kedav1alpha1.ScaleTriggers, error) {
+	logger := log.FromContext(ctx)
+
+	config, ok := transport.Config.(*asyaconfig.PostgresConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for postgres transport")
+	}
+
+	if config.Host == "" {
+		return nil, fmt.Errorf("postgres host is required in operator transport config")
+	}
+
+	queueName, err := r.resolveQueueIdentifier(asya, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue names are derived from Kubernetes object names, so they are safe to inline
+	triggerMetadata := map[string]string{
+		"host":             config.Host,
+		"port":             fmt.Sprintf("%d", config.Port),
+		"userName":         config.Username,
+		"dbName":           config.Database,
+		"sslmode":          config.SSLMode,
+		"query":            fmt.Sprintf("SELECT COUNT(*) FROM asya_queue_messages WHERE queue_name = '%s'", queueName),
+		"targetQueryValue": queueLength,
+	}
+
+	trigger := kedav1alpha1.ScaleTriggers{
+		Type:     "postgresql",
+		Metadata: triggerMetadata,
+	}
+
+	if config.PasswordSecretRef != nil {
+		trigger.AuthenticationRef = &kedav1alpha1.AuthenticationRef{
+			Name: fmt.Sprintf("%s-trigger-auth", asya.Name),
+		}
+
+		if err := r.reconcileTriggerAuthentication(context.Background(), asya, transport); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.V(1).Info("No postgres password secret configured, KEDA trigger has no credentials", "actor", asya.Name)
+	}
+
+	return []kedav1alpha1.ScaleTriggers{trigger}, nil
+}
+
+// buildRedisStreamsTrigger builds a Redis Streams KEDA trigger on the consumer group's pending entries
+func (r *AsyncActorReconciler) buildRedisStreamsTrigger(ctx context.Context, asya *asyav1alpha1.AsyncActor, transport *asyaconfig.TransportConfig, queueLength string) ([]kedav1alpha1.ScaleTriggers, error) {
+	logger := log.FromContext(ctx)
+
+	config, ok := transport.Config.(*asyaconfig.RedisConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for redis transport")
+	}
+
+	if config.Host == "" {
+		return nil, fmt.Errorf("redis host is required in operator transport config")
+	}
+
+	queueName, err := r.resolveQueueIdentifier(asya, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerMetadata := map[string]string{
+		"address":             config.Address(),
+		"stream":              queueName,
+		"consumerGroup":       config.Group,
+		"pendingEntriesCount": queueLength,
+		"databaseIndex":       fmt.Sprintf("%d", config.DB),
+	}
+	if config.TLS {
+		triggerMetadata["enableTLS"] = "true"
+	}
+
+	trigger := kedav1alpha1.ScaleTriggers{
+		Type:     "redis-streams",
+		Metadata: triggerMetadata,
+	}
+
+	if config.PasswordSecretRef != nil {
+		trigger.AuthenticationRef = &kedav1alpha1.AuthenticationRef{
+			Name: fmt.Sprintf("%s-trigger-auth", asya.Name),
+		}
+
+		if err := r.reconcileTriggerAuthentication(context.Background(), asya, transport); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.V(1).Info("No redis password secret configured, KEDA trigger has no credentials", "actor", asya.Name)
+	}
+
+	return []kedav1alpha1.ScaleTriggers{trigger}, nil
+}
+
+// buildGCPPubSubTrigger builds a gcp-pubsub KEDA trigger on subscription backlog.
+// KEDA reads the backlog from Cloud Monitoring using its own Workload Identity.
+func (r *AsyncActorReconciler) buildGCPPubSubTrigger(ctx context.Context, asya *asyav1alpha1.AsyncActor, transport *asyaconfig.TransportConfig, queueLength string) ([]kedav1alpha1.ScaleTriggers, error) {
+	config, ok := transport.Config.(*asyaconfig.PubSubConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for pubsub transport")
+	}
+
+	if config.EmulatorHost != "" {
+		// The emulator has no Cloud Monitoring API for KEDA to query
+		return nil, fmt.Errorf("pubsub emulator does not support KEDA scaling, set spec.scaling.enabled=false")
+	}
+
+	queueName, err := r.resolveQueueIdentifier(asya, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	trigger := kedav1alpha1.ScaleTriggers{
+		Type: "gcp-pubsub",
+		Metadata: map[string]string{
+			"subscriptionName": fmt.Sprintf("projects/%s/subscriptions/%s", config.ProjectID, queueName),
+			"mode":             "SubscriptionSize",
+			"value":            queueLength,
+		},
+		AuthenticationRef: &kedav1alpha1.AuthenticationRef{
+			Name: fmt.Sprintf("%s-trigger-auth", asya.Name),
+		},
+	}
+
+	if err := r.reconcileTriggerAuthentication(ctx, asya, transport); err != nil {
+		return nil, err
+	}
+
+	return []kedav1alpha1.ScaleTriggers{trigger}, nil
+}
+
 // reconcileTriggerAuthentication creates or updates a KEDA TriggerAuthentication
 func (r *AsyncActorReconciler) reconcileTriggerAuthentication(ctx context.Context, asya *asyav1alpha1.AsyncActor, transport *asyaconfig.TransportConfig) error {
 	logger := log.FromContext(ctx)
@@ -506,6 +650,55 @@ func (r *AsyncActorReconciler) reconcileTriggerAuthentication(ctx context.Contex
 
 				triggerAuth.Spec.SecretTargetRef = secretTargetRef
 			}
+
+		case transportTypePostgres:
+			config, ok := transport.Config.(*asyaconfig.PostgresConfig)
+			if !ok {
+				return fmt.Errorf("invalid config type for postgres transport")
+			}
+
+			if config.PasswordSecretRef != nil {
+				actorSecretName := asya.Name + transportCredentialsSecretSuffix
+				logger.V(1).Info("Configuring postgres TriggerAuthentication with password from secret",
+					"secret", actorSecretName,
+					"passwordKey", config.PasswordSecretRef.Key,
+					"actor", asya.Name)
+
+				triggerAuth.Spec.SecretTargetRef = []kedav1alpha1.AuthSecretTargetRef{
+					{
+						Parameter: "password",
+						Name:      actorSecretName,
+						Key:       config.PasswordSecretRef.Key,
+					},
+				}
+			}
+
+		case transportTypeRedis:
+			config, ok := transport.Config.(*asyaconfig.RedisConfig)
+			if !ok {
+				return fmt.Errorf("invalid config type for redis transport")
+			}
+
+			if config.PasswordSecretRef != nil {
+				actorSecretName := asya.Name + transportCredentialsSecretSuffix
+				logger.V(1).Info("Configuring redis TriggerAuthentication with password from secret",
+					"secret", actorSecretName,
+					"passwordKey", config.PasswordSecretRef.Key,
+					"actor", asya.Name)
+
+				triggerAuth.Spec.SecretTargetRef = []kedav1alpha1.AuthSecretTargetRef{
+					{
+						Parameter: "password",
+						Name:      actorSecretName,
+						Key:       config.PasswordSecretRef.Key,
+					},
+				}
+			}
+
+		case transportTypePubSub:
+			triggerAuth.Spec.PodIdentity = &kedav1alpha1.AuthPodIdentity{
+				Provider: "gcp",
+			}
 		}
 
 		return nil
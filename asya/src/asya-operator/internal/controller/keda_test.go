@@ -6,6 +6,7 @@ import (
 
 	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
 	asyaconfig "github.com/asya/operator/internal/config"
+	"github.com/deliveryhero/asya/asya-naming"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,6 +47,26 @@ func TestResolveQueueIdentifier(t *testing.T) {
 		}
 	})
 
+	t.Run("Postgres uses asya- prefix + namespace + actor name", func(t *testing.T) {
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testActorName,
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type: "postgres",
+		}
+
+		queueID, err := r.resolveQueueIdentifier(asya, transport)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if queueID != "asya-default-"+testActorName {
+			t.Errorf("Expected queue ID 'asya-default-test-actor', got %q", queueID)
+		}
+	})
+
 	t.Run("SQS uses asya- prefix + actor name", func(t *testing.T) {
 		asya := &asyav1alpha1.AsyncActor{
 			ObjectMeta: metav1.ObjectMeta{
@@ -93,6 +114,26 @@ func TestResolveQueueIdentifier(t *testing.T) {
 		}
 	})
 
+	t.Run("file uses asya- prefix + actor name", func(t *testing.T) {
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testActorName,
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type: "file",
+		}
+
+		queueID, err := r.resolveQueueIdentifier(asya, transport)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if queueID != testSQSQueueName {
+			t.Errorf("Expected queue ID %q, got %q", testSQSQueueName, queueID)
+		}
+	})
+
 	t.Run("unknown transport uses actor name", func(t *testing.T) {
 		asya := &asyav1alpha1.AsyncActor{
 			ObjectMeta: metav1.ObjectMeta{
@@ -112,6 +153,29 @@ func TestResolveQueueIdentifier(t *testing.T) {
 			t.Errorf("Expected queue ID 'test-actor', got %q", queueID)
 		}
 	})
+
+	t.Run("custom naming scheme", func(t *testing.T) {
+		r := &AsyncActorReconciler{TransportRegistry: &asyaconfig.TransportRegistry{
+			QueueNaming: naming.Scheme{Prefix: "acme", Cluster: "eu1", Suffix: "q"},
+		}}
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testActorName,
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type: "sqs",
+		}
+
+		queueID, err := r.resolveQueueIdentifier(asya, transport)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if queueID != "acme-eu1-default-test-actor-q" {
+			t.Errorf("Expected queue ID 'acme-eu1-default-test-actor-q', got %q", queueID)
+		}
+	})
 }
 
 func TestBuildKEDATriggers(t *testing.T) {
@@ -603,6 +667,352 @@ func TestBuildRabbitMQTrigger(t *testing.T) {
 	})
 }
 
+func TestBuildPostgresTrigger(t *testing.T) {
+	t.Run("valid postgres config without password", func(t *testing.T) {
+		r := &AsyncActorReconciler{}
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testActorName,
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type: "postgres",
+			Config: &asyaconfig.PostgresConfig{
+				Host:     "postgres.default.svc",
+				Port:     5432,
+				Database: "asya",
+				Username: "asya",
+				SSLMode:  "disable",
+			},
+		}
+
+		triggers, err := r.buildPostgresTrigger(context.Background(), asya, transport, "7")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(triggers) != 1 {
+			t.Fatalf("Expected 1 trigger, got %d", len(triggers))
+		}
+
+		trigger := triggers[0]
+		if trigger.Type != "postgresql" {
+			t.Errorf("Expected type 'postgresql', got %q", trigger.Type)
+		}
+		expected := map[string]string{
+			"host":             "postgres.default.svc",
+			"port":             "5432",
+			"userName":         "asya",
+			"dbName":           "asya",
+			"sslmode":          "disable",
+			"query":            "SELECT COUNT(*) FROM asya_queue_messages WHERE queue_name = 'asya-default-test-actor'",
+			"targetQueryValue": "7",
+		}
+		for key, want := range expected {
+			if trigger.Metadata[key] != want {
+				t.Errorf("Expected metadata %s=%q, got %q", key, want, trigger.Metadata[key])
+			}
+		}
+		if trigger.AuthenticationRef != nil {
+			t.Error("Expected no AuthenticationRef without password secret")
+		}
+	})
+
+	t.Run("invalid config type returns error", func(t *testing.T) {
+		r := &AsyncActorReconciler{}
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testActorName,
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type:   "postgres",
+			Config: &asyaconfig.SQSConfig{}, // Wrong type
+		}
+
+		_, err := r.buildPostgresTrigger(context.Background(), asya, transport, "5")
+		if err == nil {
+			t.Error("Expected error for invalid config type")
+		}
+	})
+
+	t.Run("with password secret ref creates trigger authentication", func(t *testing.T) {
+		schemeBuilder := runtime.NewSchemeBuilder(
+			scheme.AddToScheme,
+			asyav1alpha1.AddToScheme,
+			kedav1alpha1.AddToScheme,
+		)
+		testScheme := runtime.NewScheme()
+		if err := schemeBuilder.AddToScheme(testScheme); err != nil {
+			t.Fatalf("Failed to build scheme: %v", err)
+		}
+
+		asya := &asyav1alpha1.AsyncActor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-actor",
+				Namespace: "default",
+			},
+		}
+		transport := &asyaconfig.TransportConfig{
+			Type: "postgres",
+			Config: &asyaconfig.PostgresConfig{
+				Host:     "postgres.default.svc",
+				Port:     5432,
+				Database: "asya",
+				Username: "asya",
+				SSLMode:  "disable",
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: "postgres-secret",
+					},
+					Key: "password",
+				},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(testScheme).
+			Build()
+
+		r := &AsyncActorReconciler{
+			Client: fakeClient,
+			Scheme: testScheme,
+		}
+
+		triggers, err := r.buildPostgresTrigger(context.Background(), asya, transport, "5")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if triggers[0].AuthenticationRef == nil || triggers[0].AuthenticationRef.Name != "test-actor-trigger-auth" {
+			t.Fatalf("Expected AuthenticationRef 'test-actor-trigger-auth', got %+v", triggers[0].AuthenticationRef)
+		}
+
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{}
+		err = fakeClient.Get(context.Background(),
+			client.ObjectKey{Name: "test-actor-trigger-auth", Namespace: "default"},
+			triggerAuth)
+		if err != nil {
+			t.Fatalf("Failed to get TriggerAuthentication: %v", err)
+		}
+		if len(triggerAuth.Spec.SecretTargetRef) != 1 {
+			t.Fatalf("Expected 1 SecretTargetRef (password), got %d", len(triggerAuth.Spec.SecretTargetRef))
+		}
+		ref := triggerAuth.Spec.SecretTargetRef[0]
+		if ref.Parameter != testSecretPassword || ref.Name != "test-actor-transport-creds" || ref.Key != testSecretPassword {
+			t.Errorf("Unexpected SecretTargetRef: %+v", ref)
+		}
+	})
+}
+
+func TestBuildRedisStreamsTrigger(t *testing.T) {
+	asya := &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: "default",
+		},
+	}
+
+	t.Run("pending entries trigger without password", func(t *testing.T) {
+		r := &AsyncActorReconciler{}
+		transport := &asyaconfig.TransportConfig{
+			Type: "redis",
+			Config: &asyaconfig.RedisConfig{
+				Host:  "redis.default.svc",
+				Port:  6379,
+				DB:    1,
+				Group: "asya",
+			},
+		}
+
+		triggers, err := r.buildRedisStreamsTrigger(context.Background(), asya, transport, "10")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(triggers) != 1 {
+			t.Fatalf("Expected 1 trigger, got %d", len(triggers))
+		}
+
+		trigger := triggers[0]
+		if trigger.Type != "redis-streams" {
+			t.Errorf("Expected type 'redis-streams', got %q", trigger.Type)
+		}
+		expected := map[string]string{
+			"address":             "redis.default.svc:6379",
+			"stream":              "asya-default-test-actor",
+			"consumerGroup":       "asya",
+			"pendingEntriesCount": "10",
+			"databaseIndex":       "1",
+		}
+		for key, want := range expected {
+			if trigger.Metadata[key] != want {
+				t.Errorf("Expected metadata %s=%q, got %q", key, want, trigger.Metadata[key])
+			}
+		}
+		if _, ok := trigger.Metadata["enableTLS"]; ok {
+			t.Error("Expected enableTLS to be omitted when TLS is disabled")
+		}
+		if trigger.AuthenticationRef != nil {
+			t.Error("Expected no AuthenticationRef without password secret")
+		}
+	})
+
+	t.Run("missing host returns error", func(t *testing.T) {
+		r := &AsyncActorReconciler{}
+		transport := &asyaconfig.TransportConfig{
+			Type:   "redis",
+			Config: &asyaconfig.RedisConfig{Port: 6379},
+		}
+
+		if _, err := r.buildRedisStreamsTrigger(context.Background(), asya, transport, "5"); err == nil {
+			t.Error("Expected error for missing host")
+		}
+	})
+
+	t.Run("with password secret ref creates trigger authentication", func(t *testing.T) {
+		schemeBuilder := runtime.NewSchemeBuilder(
+			scheme.AddToScheme,
+			asyav1alpha1.AddToScheme,
+			kedav1alpha1.AddToScheme,
+		)
+		testScheme := runtime.NewScheme()
+		if err := schemeBuilder.AddToScheme(testScheme); err != nil {
+			t.Fatalf("Failed to build scheme: %v", err)
+		}
+
+		transport := &asyaconfig.TransportConfig{
+			Type: "redis",
+			Config: &asyaconfig.RedisConfig{
+				Host:  "redis.default.svc",
+				Port:  6379,
+				Group: "asya",
+				TLS:   true,
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: "redis-secret",
+					},
+					Key: "password",
+				},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(testScheme).
+			Build()
+
+		r := &AsyncActorReconciler{
+			Client: fakeClient,
+			Scheme: testScheme,
+		}
+
+		triggers, err := r.buildRedisStreamsTrigger(context.Background(), asya, transport, "5")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if triggers[0].Metadata["enableTLS"] != "true" {
+			t.Errorf("Expected enableTLS=true, got %q", triggers[0].Metadata["enableTLS"])
+		}
+		if triggers[0].AuthenticationRef == nil || triggers[0].AuthenticationRef.Name != "test-actor-trigger-auth" {
+			t.Fatalf("Expected AuthenticationRef 'test-actor-trigger-auth', got %+v", triggers[0].AuthenticationRef)
+		}
+
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{}
+		err = fakeClient.Get(context.Background(),
+			client.ObjectKey{Name: "test-actor-trigger-auth", Namespace: "default"},
+			triggerAuth)
+		if err != nil {
+			t.Fatalf("Failed to get TriggerAuthentication: %v", err)
+		}
+		if len(triggerAuth.Spec.SecretTargetRef) != 1 || triggerAuth.Spec.SecretTargetRef[0].Parameter != testSecretPassword {
+			t.Errorf("Expected password SecretTargetRef, got %+v", triggerAuth.Spec.SecretTargetRef)
+		}
+	})
+}
+
+func TestBuildGCPPubSubTrigger(t *testing.T) {
+	asya := &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: "default",
+		},
+	}
+
+	t.Run("subscription size trigger with gcp pod identity", func(t *testing.T) {
+		schemeBuilder := runtime.NewSchemeBuilder(
+			scheme.AddToScheme,
+			asyav1alpha1.AddToScheme,
+			kedav1alpha1.AddToScheme,
+		)
+		testScheme := runtime.NewScheme()
+		if err := schemeBuilder.AddToScheme(testScheme); err != nil {
+			t.Fatalf("Failed to build scheme: %v", err)
+		}
+
+		transport := &asyaconfig.TransportConfig{
+			Type:   "pubsub",
+			Config: &asyaconfig.PubSubConfig{ProjectID: "my-project"},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(testScheme).
+			Build()
+
+		r := &AsyncActorReconciler{
+			Client: fakeClient,
+			Scheme: testScheme,
+		}
+
+		triggers, err := r.buildGCPPubSubTrigger(context.Background(), asya, transport, "10")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(triggers) != 1 {
+			t.Fatalf("Expected 1 trigger, got %d", len(triggers))
+		}
+
+		trigger := triggers[0]
+		if trigger.Type != "gcp-pubsub" {
+			t.Errorf("Expected type 'gcp-pubsub', got %q", trigger.Type)
+		}
+		expected := map[string]string{
+			"subscriptionName": "projects/my-project/subscriptions/asya-default-test-actor",
+			"mode":             "SubscriptionSize",
+			"value":            "10",
+		}
+		for key, want := range expected {
+			if trigger.Metadata[key] != want {
+				t.Errorf("Expected metadata %s=%q, got %q", key, want, trigger.Metadata[key])
+			}
+		}
+		if trigger.AuthenticationRef == nil || trigger.AuthenticationRef.Name != "test-actor-trigger-auth" {
+			t.Fatalf("Expected AuthenticationRef 'test-actor-trigger-auth', got %+v", trigger.AuthenticationRef)
+		}
+
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{}
+		err = fakeClient.Get(context.Background(),
+			client.ObjectKey{Name: "test-actor-trigger-auth", Namespace: "default"},
+			triggerAuth)
+		if err != nil {
+			t.Fatalf("Failed to get TriggerAuthentication: %v", err)
+		}
+		if triggerAuth.Spec.PodIdentity == nil || triggerAuth.Spec.PodIdentity.Provider != "gcp" {
+			t.Errorf("Expected PodIdentity provider 'gcp', got %+v", triggerAuth.Spec.PodIdentity)
+		}
+	})
+
+	t.Run("emulator returns error", func(t *testing.T) {
+		r := &AsyncActorReconciler{}
+		transport := &asyaconfig.TransportConfig{
+			Type:   "pubsub",
+			Config: &asyaconfig.PubSubConfig{ProjectID: "my-project", EmulatorHost: "pubsub-emulator:8085"},
+		}
+
+		if _, err := r.buildGCPPubSubTrigger(context.Background(), asya, transport, "5"); err == nil {
+			t.Error("Expected error for emulator")
+		}
+	})
+}
+
 func TestReconcileTriggerAuthentication(t *testing.T) {
 	schemeBuilder := runtime.NewSchemeBuilder(
 		scheme.AddToScheme,
@@ -1031,3 +1441,32 @@ func TestReconcileScaledObject_KEDAStabilization(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildKEDATriggers_FileTransportUnsupported(t *testing.T) {
+	r := &AsyncActorReconciler{
+		TransportRegistry: &asyaconfig.TransportRegistry{
+			Transports: map[string]*asyaconfig.TransportConfig{
+				"file": {
+					Type:    "file",
+					Enabled: true,
+					Config:  &asyaconfig.FileConfig{HostPath: "/mnt/asya-queues"},
+				},
+			},
+		},
+	}
+
+	asya := &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: "default",
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: "file",
+		},
+	}
+
+	_, err := r.buildKEDATriggers(context.Background(), asya)
+	if err == nil {
+		t.Fatal("Expected error for file transport KEDA triggers")
+	}
+}
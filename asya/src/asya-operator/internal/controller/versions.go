@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+)
+
+const (
+	// actorVersionsEnv holds a fixed version table set through spec.sidecar.env, overriding the ConfigMap
+	actorVersionsEnv = "ASYA_ACTOR_VERSIONS"
+	// actorVersionsPathEnv points the sidecar at the mounted version table, which it reloads when it changes
+	actorVersionsPathEnv = "ASYA_ACTOR_VERSIONS_PATH"
+
+	// The weighted versions of a namespace's logical actors, shared by its sidecars and the gateway
+	actorVersionsConfigMap = "asya-actor-versions"
+	actorVersionsVolume    = "asya-actor-versions"
+	actorVersionsMountPath = "/etc/asya/versions"
+	actorVersionsKey       = "versions.json"
+)
+
+// actorVersion and actorVersionSet mirror canary.Version and canary.Set in asya-sidecar
+type actorVersion struct {
+	Actor  string `json:"actor"`
+	Weight int32  `json:"weight"`
+}
+
+type actorVersionSet struct {
+	Versions []actorVersion `json:"versions"`
+	Sticky   bool           `json:"sticky,omitempty"`
+}
+
+// buildActorVersions groups actors by their spec.logicalActor name. Actors being deleted are skipped,
+// and so are logical actors without a version of positive weight, which the sidecar would reject.
+func buildActorVersions(actors []asyav1alpha1.AsyncActor) map[string]actorVersionSet {
+	sets := make(map[string]actorVersionSet)
+	for _, actor := range actors {
+		logical := actor.Spec.LogicalActor
+		if logical == nil || actor.DeletionTimestamp != nil {
+			continue
+		}
+		weight := int32(1)
+		if logical.Weight != nil {
+			weight = *logical.Weight
+		}
+		set := sets[logical.Name]
+		set.Versions = append(set.Versions, actorVersion{Actor: actor.Name, Weight: weight})
+		set.Sticky = set.Sticky || logical.Sticky
+		sets[logical.Name] = set
+	}
+
+	for name, set := range sets {
+		total := int32(0)
+		for _, version := range set.Versions {
+			total += version.Weight
+		}
+		if total <= 0 {
+			delete(sets, name)
+			continue
+		}
+		// Keep the rendered value stable so listing order does not roll out the workload
+		slices.SortFunc(set.Versions, func(a, b actorVersion) int { return strings.Compare(a.Actor, b.Actor) })
+	}
+	return sets
+}
+
+// reconcileActorVersions writes the logical actors of a namespace to its actor versions ConfigMap.
+// Sidecars and the gateway watch the mounted file, so changing a version's weight does not restart them.
+func (r *AsyncActorReconciler) reconcileActorVersions(ctx context.Context, namespace string) error {
+	actors := &asyav1alpha1.AsyncActorList{}
+	if err := r.List(ctx, actors, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list AsyncActors for logical actor versions: %w", err)
+	}
+	versions, err := json.Marshal(buildActorVersions(actors.Items))
+	if err != nil {
+		return fmt.Errorf("failed to marshal logical actor versions: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      actorVersionsConfigMap,
+			Namespace: namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		// Do not set controller ownership - the versions span all AsyncActors in the namespace
+		if configMap.Labels == nil {
+			configMap.Labels = make(map[string]string)
+		}
+		configMap.Labels["app.kubernetes.io/name"] = actorVersionsConfigMap
+		configMap.Labels["app.kubernetes.io/part-of"] = "asya"
+		configMap.Labels["app.kubernetes.io/managed-by"] = "asya-operator"
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[actorVersionsKey] = string(versions)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile actor versions ConfigMap: %w", err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.FromContext(ctx).Info("Actor versions ConfigMap reconciled", "result", result, "namespace", namespace)
+	}
+	return nil
+}
+
+// mountActorVersions mounts the namespace's actor versions ConfigMap into the sidecar, so the sidecar
+// sends envelopes routed to a logical actor name to one of its versions. The volume is optional because
+// the ConfigMap may not exist yet when the pod starts; the kubelet fills it in once it does.
+func mountActorVersions(sidecar *corev1.Container, podSpec *corev1.PodSpec) {
+	// A fixed table from spec.sidecar.env takes precedence
+	for _, e := range sidecar.Env {
+		if e.Name == actorVersionsEnv {
+			return
+		}
+	}
+
+	optional := true
+	sidecar.Env = append(sidecar.Env, corev1.EnvVar{
+		Name:  actorVersionsPathEnv,
+		Value: actorVersionsMountPath + "/" + actorVersionsKey,
+	})
+	sidecar.VolumeMounts = append(sidecar.VolumeMounts, corev1.VolumeMount{
+		Name:      actorVersionsVolume,
+		MountPath: actorVersionsMountPath,
+		ReadOnly:  true,
+	})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: actorVersionsVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: actorVersionsConfigMap},
+				Optional:             &optional,
+			},
+		},
+	})
+}
+
+// logicalActorChanged passes AsyncActor events that change the logical actor versions of a namespace
+var logicalActorChanged = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return hasLogicalActor(e.Object)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldActor, oldOk := e.ObjectOld.(*asyav1alpha1.AsyncActor)
+		newActor, newOk := e.ObjectNew.(*asyav1alpha1.AsyncActor)
+		if !oldOk || !newOk {
+			return false
+		}
+		if !equality.Semantic.DeepEqual(oldActor.Spec.LogicalActor, newActor.Spec.LogicalActor) {
+			return true
+		}
+		// A version being deleted stops receiving envelopes
+		return newActor.Spec.LogicalActor != nil && oldActor.DeletionTimestamp.IsZero() != newActor.DeletionTimestamp.IsZero()
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return hasLogicalActor(e.Object)
+	},
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+func hasLogicalActor(obj client.Object) bool {
+	actor, ok := obj.(*asyav1alpha1.AsyncActor)
+	return ok && actor.Spec.LogicalActor != nil
+}
+
+// actorVersionsRequest reconciles a changed logical actor version itself, which rewrites the actor versions
+// ConfigMap of its namespace. Deleted versions reconcile as not found, which rewrites it as well.
+func actorVersionsRequest(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Name: obj.GetName(), Namespace: obj.GetNamespace()},
+	}}
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+func versionActor(name, namespace string, logical *asyav1alpha1.LogicalActorConfig) *asyav1alpha1.AsyncActor {
+	return &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport:    testTransportRabbitMQ,
+			LogicalActor: logical,
+			Workload: asyav1alpha1.WorkloadConfig{
+				Template: asyav1alpha1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "asya-runtime", Image: "python:3.13-slim"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildActorVersions(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
+	deleting := versionActor("classifier-v0", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier"})
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	actors := []asyav1alpha1.AsyncActor{
+		*versionActor("classifier-v2", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier", Weight: weight(10), Sticky: true}),
+		*versionActor("classifier-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier", Weight: weight(90)}),
+		*deleting,
+		*versionActor("scorer-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "scorer"}),
+		*versionActor("drained-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "drained", Weight: weight(0)}),
+		*versionActor("ocr", "default", nil),
+	}
+
+	expected := map[string]actorVersionSet{
+		"classifier": {
+			Versions: []actorVersion{{Actor: "classifier-v1", Weight: 90}, {Actor: "classifier-v2", Weight: 10}},
+			Sticky:   true,
+		},
+		"scorer": {Versions: []actorVersion{{Actor: "scorer-v1", Weight: 1}}},
+	}
+	if got := buildActorVersions(actors); !reflect.DeepEqual(got, expected) {
+		t.Errorf("buildActorVersions() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestReconcileActorVersions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = asyav1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	weight := int32(90)
+	objects := []client.Object{
+		versionActor("classifier-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier", Weight: &weight}),
+		versionActor("classifier-v2", "team-b", &asyav1alpha1.LogicalActorConfig{Name: "classifier"}),
+		versionActor("ocr", "default", nil),
+		versionActor("ocr", "team-c", nil),
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &AsyncActorReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	versionsOf := func(namespace string) string {
+		t.Helper()
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, client.ObjectKey{Name: actorVersionsConfigMap, Namespace: namespace}, configMap); err != nil {
+			t.Fatalf("Failed to get actor versions ConfigMap in %s: %v", namespace, err)
+		}
+		return configMap.Data[actorVersionsKey]
+	}
+
+	for _, namespace := range []string{"default", "team-c"} {
+		if err := r.reconcileActorVersions(ctx, namespace); err != nil {
+			t.Fatalf("reconcileActorVersions(%s) failed: %v", namespace, err)
+		}
+	}
+	if got, want := versionsOf("default"), `{"classifier":{"versions":[{"actor":"classifier-v1","weight":90}]}}`; got != want {
+		t.Errorf("Expected default versions %s, got %s", want, got)
+	}
+	if got := versionsOf("team-c"); got != "{}" {
+		t.Errorf("Expected empty versions for a namespace without logical actors, got %s", got)
+	}
+
+	// A reweighted version rewrites the ConfigMap in place
+	actor := &asyav1alpha1.AsyncActor{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "classifier-v1", Namespace: "default"}, actor); err != nil {
+		t.Fatalf("Failed to get actor: %v", err)
+	}
+	weight = 50
+	actor.Spec.LogicalActor.Weight = &weight
+	if err := c.Update(ctx, actor); err != nil {
+		t.Fatalf("Failed to update actor: %v", err)
+	}
+	if err := r.reconcileActorVersions(ctx, "default"); err != nil {
+		t.Fatalf("reconcileActorVersions failed: %v", err)
+	}
+	if got, want := versionsOf("default"), `{"classifier":{"versions":[{"actor":"classifier-v1","weight":50}]}}`; got != want {
+		t.Errorf("Expected reweighted versions %s, got %s", want, got)
+	}
+}
+
+func TestMountActorVersions(t *testing.T) {
+	r := &AsyncActorReconciler{
+		TransportRegistry: &asyaconfig.TransportRegistry{
+			Transports: map[string]*asyaconfig.TransportConfig{
+				testTransportRabbitMQ: {
+					Type:    testTransportRabbitMQ,
+					Enabled: true,
+					Config:  &asyaconfig.RabbitMQConfig{Host: "localhost", Port: 5672, Username: "guest"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		sidecarEnv []corev1.EnvVar
+		mounted    bool
+	}{
+		{name: "mounts the namespace ConfigMap", mounted: true},
+		{
+			name:       "sidecar env takes precedence",
+			sidecarEnv: []corev1.EnvVar{{Name: actorVersionsEnv, Value: "{}"}},
+			mounted:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asya := versionActor("ocr", "default", nil)
+			asya.Spec.Sidecar.Env = tt.sidecarEnv
+			podTemplate := r.injectSidecar(asya)
+
+			var path string
+			var mounted bool
+			for _, container := range podTemplate.Spec.Containers {
+				if container.Name != sidecarName {
+					continue
+				}
+				for _, e := range container.Env {
+					if e.Name == actorVersionsPathEnv {
+						path = e.Value
+					}
+				}
+				for _, m := range container.VolumeMounts {
+					if m.Name == actorVersionsVolume && m.MountPath == actorVersionsMountPath {
+						mounted = true
+					}
+				}
+			}
+			var volume *corev1.Volume
+			for i := range podTemplate.Spec.Volumes {
+				if podTemplate.Spec.Volumes[i].Name == actorVersionsVolume {
+					volume = &podTemplate.Spec.Volumes[i]
+				}
+			}
+
+			if !tt.mounted {
+				if path != "" || mounted || volume != nil {
+					t.Errorf("Expected no actor versions mount, got path=%q mounted=%v volume=%v", path, mounted, volume)
+				}
+				return
+			}
+			if path != actorVersionsMountPath+"/"+actorVersionsKey {
+				t.Errorf("Expected %s=%s/%s, got %q", actorVersionsPathEnv, actorVersionsMountPath, actorVersionsKey, path)
+			}
+			if !mounted {
+				t.Error("Expected the sidecar to mount the actor versions volume")
+			}
+			if volume == nil || volume.ConfigMap == nil || volume.ConfigMap.Name != actorVersionsConfigMap {
+				t.Fatalf("Expected a volume from ConfigMap %s, got %+v", actorVersionsConfigMap, volume)
+			}
+			if volume.ConfigMap.Optional == nil || !*volume.ConfigMap.Optional {
+				t.Error("Expected the actor versions volume to be optional")
+			}
+		})
+	}
+}
+
+func TestLogicalActorChanged(t *testing.T) {
+	version := versionActor("classifier-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier"})
+	plain := versionActor("ocr", "default", nil)
+
+	reweighted := version.DeepCopy()
+	weight := int32(5)
+	reweighted.Spec.LogicalActor.Weight = &weight
+
+	deleting := version.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	relabeled := version.DeepCopy()
+	relabeled.Labels = map[string]string{"team": "ml"}
+
+	if !logicalActorChanged.Create(event.CreateEvent{Object: version}) {
+		t.Error("Expected creating a version to pass")
+	}
+	if logicalActorChanged.Create(event.CreateEvent{Object: plain}) {
+		t.Error("Expected creating a plain actor to be filtered")
+	}
+	if !logicalActorChanged.Update(event.UpdateEvent{ObjectOld: version, ObjectNew: reweighted}) {
+		t.Error("Expected a weight change to pass")
+	}
+	if !logicalActorChanged.Update(event.UpdateEvent{ObjectOld: version, ObjectNew: deleting}) {
+		t.Error("Expected a version being deleted to pass")
+	}
+	if logicalActorChanged.Update(event.UpdateEvent{ObjectOld: version, ObjectNew: relabeled}) {
+		t.Error("Expected unrelated changes to be filtered")
+	}
+	if !logicalActorChanged.Delete(event.DeleteEvent{Object: version}) {
+		t.Error("Expected deleting a version to pass")
+	}
+}
+
+func TestActorVersionsRequest(t *testing.T) {
+	version := versionActor("classifier-v1", "default", &asyav1alpha1.LogicalActorConfig{Name: "classifier"})
+	requests := actorVersionsRequest(context.Background(), version)
+	if len(requests) != 1 || requests[0].Name != "classifier-v1" || requests[0].Namespace != "default" {
+		t.Errorf("Expected only the changed version to be reconciled, got %v", requests)
+	}
+}
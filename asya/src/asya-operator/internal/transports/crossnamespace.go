@@ -0,0 +1,69 @@
+package transports
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AllowedSourceNamespacesAnnotation on a Namespace opts its actors in to receiving envelopes routed from
+// other namespaces with "namespace/actor" references. The value is "*" or a comma-separated list of namespaces.
+const AllowedSourceNamespacesAnnotation = "asya.sh/allowed-source-namespaces"
+
+// AllowedSourceNamespaces returns the other namespaces whose actors may route to actors in namespace,
+// as declared by its AllowedSourceNamespacesAnnotation. "*" allows every namespace.
+func AllowedSourceNamespaces(ctx context.Context, c client.Reader, namespace string) ([]string, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+
+	var sources []string
+	for _, source := range parseAllowedSourceNamespaces(ns.Annotations[AllowedSourceNamespacesAnnotation]) {
+		if source != namespace {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// AllowedTargetNamespaces returns the other namespaces that accept envelopes from actors in namespace
+func AllowedTargetNamespaces(ctx context.Context, c client.Reader, namespace string) ([]string, error) {
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var targets []string
+	for _, ns := range namespaces.Items {
+		if ns.Name == namespace {
+			continue
+		}
+		sources := parseAllowedSourceNamespaces(ns.Annotations[AllowedSourceNamespacesAnnotation])
+		if slices.Contains(sources, "*") || slices.Contains(sources, namespace) {
+			targets = append(targets, ns.Name)
+		}
+	}
+	slices.Sort(targets)
+	return targets, nil
+}
+
+// parseAllowedSourceNamespaces splits the annotation value, skipping empty entries
+func parseAllowedSourceNamespaces(value string) []string {
+	var sources []string
+	for _, source := range strings.Split(value, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			sources = append(sources, source)
+		}
+	}
+	slices.Sort(sources)
+	return slices.Compact(sources)
+}
@@ -0,0 +1,77 @@
+package transports
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestNamespace(name, allowedSources string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if allowedSources != "" {
+		ns.Annotations = map[string]string{AllowedSourceNamespacesAnnotation: allowedSources}
+	}
+	return ns
+}
+
+func TestAllowedSourceNamespaces(t *testing.T) {
+	fakeClient := newTransportTestClient().WithObjects(
+		newTestNamespace("team-b", "team-a, team-c,team-b,"),
+		newTestNamespace("team-c", ""),
+	).Build()
+
+	tests := []struct {
+		namespace string
+		expected  []string
+	}{
+		{namespace: "team-b", expected: []string{"team-a", "team-c"}},
+		{namespace: "team-c", expected: nil},
+		{namespace: "missing", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			sources, err := AllowedSourceNamespaces(context.Background(), fakeClient, tt.namespace)
+			if err != nil {
+				t.Fatalf("AllowedSourceNamespaces failed: %v", err)
+			}
+			if !reflect.DeepEqual(sources, tt.expected) {
+				t.Errorf("AllowedSourceNamespaces(%q) = %v, want %v", tt.namespace, sources, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllowedTargetNamespaces(t *testing.T) {
+	fakeClient := newTransportTestClient().WithObjects(
+		newTestNamespace("team-a", "team-b"),
+		newTestNamespace("team-b", "team-a"),
+		newTestNamespace("team-c", "*"),
+		newTestNamespace("team-d", "team-c"),
+		newTestNamespace("team-e", ""),
+	).Build()
+
+	tests := []struct {
+		namespace string
+		expected  []string
+	}{
+		{namespace: "team-a", expected: []string{"team-b", "team-c"}},
+		{namespace: "team-c", expected: []string{"team-d"}},
+		{namespace: "team-e", expected: []string{"team-c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			targets, err := AllowedTargetNamespaces(context.Background(), fakeClient, tt.namespace)
+			if err != nil {
+				t.Fatalf("AllowedTargetNamespaces failed: %v", err)
+			}
+			if !reflect.DeepEqual(targets, tt.expected) {
+				t.Errorf("AllowedTargetNamespaces(%q) = %v, want %v", tt.namespace, targets, tt.expected)
+			}
+		})
+	}
+}
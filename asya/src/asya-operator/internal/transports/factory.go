@@ -11,6 +11,10 @@ import (
 const (
 	transportTypeSQS      = "sqs"
 	transportTypeRabbitMQ = "rabbitmq"
+	transportTypeFile     = "file"
+	transportTypePostgres = "postgres"
+	transportTypeRedis    = "redis"
+	transportTypePubSub   = "pubsub"
 )
 
 // Factory creates transport-specific reconcilers
@@ -36,6 +40,14 @@ func (f *Factory) GetQueueReconciler(transportType string) (QueueReconciler, err
 		return NewSQSTransport(f.k8sClient, f.transportRegistry, f.credentialsNamespace), nil
 	case transportTypeRabbitMQ:
 		return NewRabbitMQTransport(f.k8sClient, f.transportRegistry, f.credentialsNamespace), nil
+	case transportTypeFile:
+		return NewFileTransport(f.transportRegistry), nil
+	case transportTypePostgres:
+		return NewPostgresTransport(f.k8sClient, f.transportRegistry, f.credentialsNamespace), nil
+	case transportTypeRedis:
+		return NewRedisTransport(f.k8sClient, f.transportRegistry, f.credentialsNamespace), nil
+	case transportTypePubSub:
+		return NewPubSubTransport(f.transportRegistry), nil
 	default:
 		return nil, fmt.Errorf("unsupported transport type: %s", transportType)
 	}
@@ -46,7 +58,7 @@ func (f *Factory) GetServiceAccountReconciler(transportType string) (ServiceAcco
 	switch transportType {
 	case transportTypeSQS:
 		return NewSQSTransport(f.k8sClient, f.transportRegistry, f.credentialsNamespace), nil
-	case transportTypeRabbitMQ:
+	case transportTypeRabbitMQ, transportTypeFile, transportTypePostgres, transportTypeRedis, transportTypePubSub:
 		return nil, nil
 	default:
 		return nil, fmt.Errorf("unsupported transport type: %s", transportType)
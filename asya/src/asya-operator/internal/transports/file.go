@@ -0,0 +1,129 @@
+package transports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+const errInvalidFileConfig = "invalid file config type"
+
+// fileQueueSubdirs mirrors the directory layout used by the sidecar and gateway file transports
+var fileQueueSubdirs = []string{"tmp", "ready", "processing", "dead"}
+
+// FileTransport implements queue reconciliation for the file transport.
+// Sidecars create queue directories lazily, so the operator only manages them when the
+// queue volume is also mounted into the operator pod at the configured root; otherwise
+// reconciliation is a no-op.
+type FileTransport struct {
+	transportRegistry *asyaconfig.TransportRegistry
+}
+
+// NewFileTransport creates a new file transport reconciler
+func NewFileTransport(registry *asyaconfig.TransportRegistry) *FileTransport {
+	return &FileTransport{
+		transportRegistry: registry,
+	}
+}
+
+// loadConfig returns the file transport config
+func (t *FileTransport) loadConfig() (*asyaconfig.FileConfig, error) {
+	transport, err := t.transportRegistry.GetTransport(transportTypeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	fileConfig, ok := transport.Config.(*asyaconfig.FileConfig)
+	if !ok {
+		return nil, errors.New(errInvalidFileConfig)
+	}
+	return fileConfig, nil
+}
+
+// rootAccessible reports whether the queue volume is mounted into the operator pod
+func rootAccessible(root string) bool {
+	info, err := os.Stat(root)
+	return err == nil && info.IsDir()
+}
+
+// ReconcileQueue creates the queue directories for an actor
+func (t *FileTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	fileConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+	root := fileConfig.MountPath()
+
+	if !rootAccessible(root) {
+		logger.V(1).Info("File queue volume not mounted in operator, sidecar will create queue", "queue", queueName, "root", root)
+		return nil
+	}
+
+	for _, sub := range fileQueueSubdirs {
+		if err := os.MkdirAll(filepath.Join(root, queueName, sub), 0o755); err != nil {
+			return fmt.Errorf("failed to create file queue %s: %w", queueName, err)
+		}
+	}
+
+	logger.Info("File queue reconciled", "queue", queueName, "root", root)
+	return nil
+}
+
+// DeleteQueue removes the queue directory for an actor
+func (t *FileTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	fileConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+	root := fileConfig.MountPath()
+
+	if !rootAccessible(root) {
+		logger.V(1).Info("File queue volume not mounted in operator, skipping queue deletion", "queue", queueName)
+		return nil
+	}
+
+	if err := os.RemoveAll(filepath.Join(root, queueName)); err != nil {
+		return fmt.Errorf("failed to delete file queue %s: %w", queueName, err)
+	}
+
+	logger.Info("File queue deleted", "queue", queueName)
+	return nil
+}
+
+// QueueExists checks if a queue directory exists.
+// Reports true when the volume is not mounted in the operator, since sidecars create queues on demand.
+func (t *FileTransport) QueueExists(ctx context.Context, queueName, namespace string) (bool, error) {
+	fileConfig, err := t.loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	root := fileConfig.MountPath()
+	if !rootAccessible(root) {
+		return true, nil
+	}
+
+	info, err := os.Stat(filepath.Join(root, queueName, "ready"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check file queue %s: %w", queueName, err)
+	}
+	return info.IsDir(), nil
+}
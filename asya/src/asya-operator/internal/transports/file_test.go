@@ -0,0 +1,103 @@
+package transports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+func newFileTestRegistry(root string) *asyaconfig.TransportRegistry {
+	return &asyaconfig.TransportRegistry{
+		Transports: map[string]*asyaconfig.TransportConfig{
+			transportTypeFile: {
+				Type:    transportTypeFile,
+				Enabled: true,
+				Config: &asyaconfig.FileConfig{
+					Root:     root,
+					HostPath: "/mnt/asya-queues",
+				},
+			},
+		},
+	}
+}
+
+func newFileTestActor() *asyav1alpha1.AsyncActor {
+	return &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-actor",
+			Namespace: "default",
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypeFile,
+		},
+	}
+}
+
+func TestFileTransport_ReconcileAndDeleteQueue(t *testing.T) {
+	root := t.TempDir()
+	transport := NewFileTransport(newFileTestRegistry(root))
+	actor := newFileTestActor()
+	ctx := context.Background()
+
+	exists, err := transport.QueueExists(ctx, "asya-default-test-actor", "default")
+	if err != nil {
+		t.Fatalf("QueueExists failed: %v", err)
+	}
+	if exists {
+		t.Fatal("Expected queue not to exist before reconcile")
+	}
+
+	if err := transport.ReconcileQueue(ctx, actor); err != nil {
+		t.Fatalf("ReconcileQueue failed: %v", err)
+	}
+	for _, sub := range fileQueueSubdirs {
+		if _, err := os.Stat(filepath.Join(root, "asya-default-test-actor", sub)); err != nil {
+			t.Errorf("Expected %s directory to exist: %v", sub, err)
+		}
+	}
+
+	exists, err = transport.QueueExists(ctx, "asya-default-test-actor", "default")
+	if err != nil || !exists {
+		t.Fatalf("Expected queue to exist after reconcile, got exists=%v err=%v", exists, err)
+	}
+
+	if err := transport.DeleteQueue(ctx, actor); err != nil {
+		t.Fatalf("DeleteQueue failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "asya-default-test-actor")); !os.IsNotExist(err) {
+		t.Errorf("Expected queue directory to be removed, got %v", err)
+	}
+}
+
+func TestFileTransport_VolumeNotMounted(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "not-mounted")
+	transport := NewFileTransport(newFileTestRegistry(root))
+	ctx := context.Background()
+
+	if err := transport.ReconcileQueue(ctx, newFileTestActor()); err != nil {
+		t.Fatalf("Expected no-op reconcile, got %v", err)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("Expected operator not to create root, got %v", err)
+	}
+
+	exists, err := transport.QueueExists(ctx, "asya-default-test-actor", "default")
+	if err != nil || !exists {
+		t.Errorf("Expected queue to be reported as existing, got exists=%v err=%v", exists, err)
+	}
+}
+
+func TestFileTransport_TransportNotFound(t *testing.T) {
+	transport := NewFileTransport(&asyaconfig.TransportRegistry{Transports: map[string]*asyaconfig.TransportConfig{}})
+
+	err := transport.ReconcileQueue(context.Background(), newFileTestActor())
+	if err == nil || err.Error() != "transport 'file' not found in operator configuration" {
+		t.Fatalf("Expected transport not found error, got %v", err)
+	}
+}
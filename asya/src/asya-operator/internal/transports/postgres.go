@@ -0,0 +1,220 @@
+package transports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+const errInvalidPostgresConfig = "invalid postgres config type"
+
+// postgresSchema mirrors the gateway migration db/deploy/005_add_queue_tables.sql
+// and the sidecar auto-create schema (asya-sidecar/internal/transport/postgres.go)
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS asya_queues (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS asya_queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL DEFAULT 0,
+    visible_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_messages_visible ON asya_queue_messages(queue_name, visible_at, id);
+
+CREATE TABLE IF NOT EXISTS asya_queue_dead_letters (
+    id BIGINT PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    dead_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_dead_letters_queue ON asya_queue_dead_letters(queue_name, dead_at DESC);
+`
+
+const (
+	postgresRegisterQueueSQL = `INSERT INTO asya_queues (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`
+	postgresPurgeQueueSQL    = `DELETE FROM asya_queue_messages WHERE queue_name = $1`
+	postgresDeleteQueueSQL   = `DELETE FROM asya_queues WHERE name = $1`
+	postgresQueueExistsSQL   = `SELECT EXISTS (SELECT 1 FROM asya_queues WHERE name = $1)`
+)
+
+// PostgresTransport implements queue reconciliation for the postgres transport.
+// Queues are rows in the shared asya_queues table; messages live in asya_queue_messages.
+type PostgresTransport struct {
+	k8sClient            client.Client
+	transportRegistry    *asyaconfig.TransportRegistry
+	credentialsNamespace string // Namespace to look up transport credential secrets
+}
+
+// NewPostgresTransport creates a new postgres transport reconciler
+func NewPostgresTransport(k8sClient client.Client, registry *asyaconfig.TransportRegistry, credentialsNamespace string) *PostgresTransport {
+	return &PostgresTransport{
+		k8sClient:            k8sClient,
+		transportRegistry:    registry,
+		credentialsNamespace: credentialsNamespace,
+	}
+}
+
+// loadConfig returns the postgres transport config
+func (t *PostgresTransport) loadConfig() (*asyaconfig.PostgresConfig, error) {
+	transport, err := t.transportRegistry.GetTransport(transportTypePostgres)
+	if err != nil {
+		return nil, err
+	}
+
+	postgresConfig, ok := transport.Config.(*asyaconfig.PostgresConfig)
+	if !ok {
+		return nil, errors.New(errInvalidPostgresConfig)
+	}
+	return postgresConfig, nil
+}
+
+// connect opens a connection using the configured credentials
+func (t *PostgresTransport) connect(ctx context.Context, postgresConfig *asyaconfig.PostgresConfig) (*pgx.Conn, error) {
+	password := postgresConfig.Password
+	if postgresConfig.PasswordSecretRef != nil {
+		var err error
+		password, err = t.loadPassword(ctx, postgresConfig, t.credentialsNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load postgres password: %w", err)
+		}
+	}
+
+	conn, err := pgx.Connect(ctx, postgresConfig.ConnString(password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	return conn, nil
+}
+
+// ReconcileQueue ensures the queue tables exist and registers the actor queue
+func (t *PostgresTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	postgresConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	conn, err := t.connect(ctx, postgresConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	if !postgresConfig.Queues.AutoCreate {
+		var exists bool
+		if err := conn.QueryRow(ctx, postgresQueueExistsSQL, queueName).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check postgres queue %s: %w", queueName, err)
+		}
+		if !exists {
+			return fmt.Errorf("postgres queue %s does not exist and autoCreate is disabled", queueName)
+		}
+		logger.V(1).Info("Postgres queue exists", "queue", queueName)
+		return nil
+	}
+
+	if _, err := conn.Exec(ctx, postgresSchema); err != nil {
+		return fmt.Errorf("failed to create postgres queue tables: %w", err)
+	}
+	if _, err := conn.Exec(ctx, postgresRegisterQueueSQL, queueName); err != nil {
+		return fmt.Errorf("failed to register postgres queue %s: %w", queueName, err)
+	}
+
+	logger.Info("Postgres queue reconciled", "queue", queueName)
+	return nil
+}
+
+// DeleteQueue removes pending messages and the queue registration for an actor.
+// Dead letters are preserved for inspection.
+func (t *PostgresTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	postgresConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	conn, err := t.connect(ctx, postgresConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	if _, err := conn.Exec(ctx, postgresPurgeQueueSQL, queueName); err != nil {
+		return fmt.Errorf("failed to purge postgres queue %s: %w", queueName, err)
+	}
+	if _, err := conn.Exec(ctx, postgresDeleteQueueSQL, queueName); err != nil {
+		return fmt.Errorf("failed to delete postgres queue %s: %w", queueName, err)
+	}
+
+	logger.Info("Postgres queue deleted", "queue", queueName)
+	return nil
+}
+
+// loadPassword loads postgres password from Kubernetes secret
+func (t *PostgresTransport) loadPassword(ctx context.Context, postgresConfig *asyaconfig.PostgresConfig, namespace string) (string, error) {
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{
+		Name:      postgresConfig.PasswordSecretRef.Name,
+		Namespace: namespace,
+	}
+
+	if err := t.k8sClient.Get(ctx, secretKey, secret); err != nil {
+		return "", fmt.Errorf("failed to get postgres password secret: %w", err)
+	}
+
+	passwordBytes, ok := secret.Data[postgresConfig.PasswordSecretRef.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", postgresConfig.PasswordSecretRef.Key, postgresConfig.PasswordSecretRef.Name)
+	}
+
+	return string(passwordBytes), nil
+}
+
+// QueueExists checks if a queue is registered in asya_queues
+func (t *PostgresTransport) QueueExists(ctx context.Context, queueName, namespace string) (bool, error) {
+	postgresConfig, err := t.loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := t.connect(ctx, postgresConfig)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, postgresQueueExistsSQL, queueName).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check postgres queue %s: %w", queueName, err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,146 @@
+package transports
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+func newPostgresTestRegistry(config asyaconfig.TransportSpecificConfig) *asyaconfig.TransportRegistry {
+	return &asyaconfig.TransportRegistry{
+		Transports: map[string]*asyaconfig.TransportConfig{
+			transportTypePostgres: {
+				Type:    transportTypePostgres,
+				Enabled: true,
+				Config:  config,
+			},
+		},
+	}
+}
+
+func newPostgresTestActor() *asyav1alpha1.AsyncActor {
+	return &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: testActorNamespace,
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypePostgres,
+		},
+	}
+}
+
+func newTransportTestClient() *fake.ClientBuilder {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = asyav1alpha1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme)
+}
+
+// unreachablePostgresConfig points at a closed local port so connections fail fast
+func unreachablePostgresConfig() *asyaconfig.PostgresConfig {
+	return &asyaconfig.PostgresConfig{
+		Host:     "127.0.0.1",
+		Port:     1,
+		Database: "asya",
+		Username: "postgres",
+		Password: "secret",
+		SSLMode:  "disable",
+		Queues:   asyaconfig.QueueManagementConfig{AutoCreate: true},
+	}
+}
+
+func TestPostgresTransport_ConnectionFailure(t *testing.T) {
+	transport := NewPostgresTransport(newTransportTestClient().Build(), newPostgresTestRegistry(unreachablePostgresConfig()), testActorNamespace)
+	ctx := context.Background()
+
+	err := transport.ReconcileQueue(ctx, newPostgresTestActor())
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to postgres") {
+		t.Errorf("ReconcileQueue: expected connection error, got %v", err)
+	}
+
+	err = transport.DeleteQueue(ctx, newPostgresTestActor())
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to postgres") {
+		t.Errorf("DeleteQueue: expected connection error, got %v", err)
+	}
+
+	_, err = transport.QueueExists(ctx, "asya-default-test-actor", testActorNamespace)
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to postgres") {
+		t.Errorf("QueueExists: expected connection error, got %v", err)
+	}
+}
+
+func TestPostgresTransport_TransportNotFound(t *testing.T) {
+	registry := &asyaconfig.TransportRegistry{Transports: make(map[string]*asyaconfig.TransportConfig)}
+	transport := NewPostgresTransport(newTransportTestClient().Build(), registry, testActorNamespace)
+
+	err := transport.ReconcileQueue(context.Background(), newPostgresTestActor())
+	if err == nil || !strings.Contains(err.Error(), "transport 'postgres' not found") {
+		t.Errorf("Expected transport not found error, got %v", err)
+	}
+}
+
+func TestPostgresTransport_InvalidConfigType(t *testing.T) {
+	registry := newPostgresTestRegistry(&asyaconfig.FileConfig{HostPath: "/tmp"})
+	transport := NewPostgresTransport(newTransportTestClient().Build(), registry, testActorNamespace)
+
+	err := transport.ReconcileQueue(context.Background(), newPostgresTestActor())
+	if err == nil || err.Error() != errInvalidPostgresConfig {
+		t.Errorf("Expected %q, got %v", errInvalidPostgresConfig, err)
+	}
+}
+
+func TestPostgresTransport_LoadPasswordFromSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres-creds", Namespace: testActorNamespace},
+		Data:       map[string][]byte{"password": []byte("from-secret")},
+	}
+	config := unreachablePostgresConfig()
+	config.PasswordSecretRef = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "postgres-creds"},
+		Key:                  "password",
+	}
+	transport := NewPostgresTransport(newTransportTestClient().WithObjects(secret).Build(), newPostgresTestRegistry(config), testActorNamespace)
+
+	password, err := transport.loadPassword(context.Background(), config, testActorNamespace)
+	if err != nil {
+		t.Fatalf("loadPassword failed: %v", err)
+	}
+	if password != "from-secret" {
+		t.Errorf("Expected password from secret, got %q", password)
+	}
+
+	config.PasswordSecretRef.Key = "missing"
+	_, err = transport.loadPassword(context.Background(), config, testActorNamespace)
+	if err == nil || !strings.Contains(err.Error(), "key missing not found") {
+		t.Errorf("Expected missing key error, got %v", err)
+	}
+}
+
+func TestFactory_PostgresReconcilers(t *testing.T) {
+	factory := NewFactory(newTransportTestClient().Build(), newPostgresTestRegistry(unreachablePostgresConfig()), testActorNamespace)
+
+	reconciler, err := factory.GetQueueReconciler(transportTypePostgres)
+	if err != nil {
+		t.Fatalf("GetQueueReconciler failed: %v", err)
+	}
+	if _, ok := reconciler.(*PostgresTransport); !ok {
+		t.Errorf("Expected *PostgresTransport, got %T", reconciler)
+	}
+
+	saReconciler, err := factory.GetServiceAccountReconciler(transportTypePostgres)
+	if err != nil {
+		t.Fatalf("GetServiceAccountReconciler failed: %v", err)
+	}
+	if saReconciler != nil {
+		t.Errorf("Expected no ServiceAccount reconciler for postgres, got %T", saReconciler)
+	}
+}
@@ -0,0 +1,292 @@
+package transports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+const (
+	errInvalidPubSubConfig = "invalid pubsub config type"
+	defaultPubSubEndpoint  = "pubsub.googleapis.com:443"
+	pubsubScope            = "https://www.googleapis.com/auth/pubsub"
+)
+
+// PubSubTransport implements queue reconciliation for Google Cloud Pub/Sub.
+// Each queue is a topic with a subscription of the same name; the layout matches the sidecar
+// (asya-sidecar/internal/transport/pubsub.go). Dead letters go to a shared "asya-{namespace}-dlq"
+// topic whose subscription retains them for inspection.
+type PubSubTransport struct {
+	transportRegistry *asyaconfig.TransportRegistry
+}
+
+// NewPubSubTransport creates a new Pub/Sub transport reconciler
+func NewPubSubTransport(registry *asyaconfig.TransportRegistry) *PubSubTransport {
+	return &PubSubTransport{
+		transportRegistry: registry,
+	}
+}
+
+// pubsubClients bundles the admin clients sharing one connection
+type pubsubClients struct {
+	conn       *grpc.ClientConn
+	publisher  pubsubpb.PublisherClient
+	subscriber pubsubpb.SubscriberClient
+	projectID  string
+}
+
+func (c *pubsubClients) topicPath(name string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", c.projectID, name)
+}
+
+func (c *pubsubClients) subscriptionPath(name string) string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", c.projectID, name)
+}
+
+// loadConfig returns the pubsub transport config
+func (t *PubSubTransport) loadConfig() (*asyaconfig.PubSubConfig, error) {
+	transport, err := t.transportRegistry.GetTransport(transportTypePubSub)
+	if err != nil {
+		return nil, err
+	}
+
+	pubsubConfig, ok := transport.Config.(*asyaconfig.PubSubConfig)
+	if !ok {
+		return nil, errors.New(errInvalidPubSubConfig)
+	}
+	return pubsubConfig, nil
+}
+
+// connect dials Pub/Sub. Outside the emulator the operator authenticates with
+// Application Default Credentials (GKE Workload Identity in cluster).
+func (t *PubSubTransport) connect(ctx context.Context, pubsubConfig *asyaconfig.PubSubConfig) (*pubsubClients, error) {
+	var conn *grpc.ClientConn
+	var err error
+	if pubsubConfig.EmulatorHost != "" {
+		conn, err = grpc.NewClient(pubsubConfig.EmulatorHost, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		tokenSource, tsErr := google.DefaultTokenSource(ctx, pubsubScope)
+		if tsErr != nil {
+			return nil, fmt.Errorf("failed to load Google credentials: %w", tsErr)
+		}
+		conn, err = grpc.NewClient(defaultPubSubEndpoint,
+			grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")),
+			grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokenSource}),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Pub/Sub: %w", err)
+	}
+
+	return &pubsubClients{
+		conn:       conn,
+		publisher:  pubsubpb.NewPublisherClient(conn),
+		subscriber: pubsubpb.NewSubscriberClient(conn),
+		projectID:  pubsubConfig.ProjectID,
+	}, nil
+}
+
+// ReconcileQueue creates the topic and subscription for an actor
+func (t *PubSubTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	pubsubConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	clients, err := t.connect(ctx, pubsubConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = clients.conn.Close()
+	}()
+
+	if !pubsubConfig.Queues.AutoCreate {
+		exists, err := t.subscriptionExists(ctx, clients, queueName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("pubsub subscription %s does not exist and autoCreate is disabled", queueName)
+		}
+		logger.V(1).Info("Pub/Sub subscription exists", "queue", queueName)
+		return nil
+	}
+
+	if pubsubConfig.Queues.ForceRecreate {
+		if err := t.deleteQueue(ctx, clients, queueName); err != nil {
+			return err
+		}
+		logger.Info("Pub/Sub topic and subscription deleted for recreation", "queue", queueName)
+	}
+
+	// Create shared DLQ first if enabled
+	var deadLetterPolicy *pubsubpb.DeadLetterPolicy
+	if pubsubConfig.Queues.DLQ.Enabled {
+		dlqTopic, err := t.ensureDLQ(ctx, clients, pubsubConfig, actor)
+		if err != nil {
+			return fmt.Errorf("failed to ensure shared DLQ: %w", err)
+		}
+		deadLetterPolicy = &pubsubpb.DeadLetterPolicy{
+			DeadLetterTopic:     dlqTopic,
+			MaxDeliveryAttempts: int32(pubsubConfig.Queues.DLQ.MaxRetryCount), // #nosec G115 - validated to 5-100
+		}
+	}
+
+	if err := t.ensureTopic(ctx, clients, queueName); err != nil {
+		return err
+	}
+
+	subscription := &pubsubpb.Subscription{
+		Name:               clients.subscriptionPath(queueName),
+		Topic:              clients.topicPath(queueName),
+		AckDeadlineSeconds: int32(pubsubConfig.AckDeadline), // #nosec G115 - validated to 10-600
+		DeadLetterPolicy:   deadLetterPolicy,
+	}
+	_, err = clients.subscriber.CreateSubscription(ctx, subscription)
+	if status.Code(err) == codes.AlreadyExists {
+		// Keep ack deadline and dead-letter policy in sync with operator config
+		_, err = clients.subscriber.UpdateSubscription(ctx, &pubsubpb.UpdateSubscriptionRequest{
+			Subscription: subscription,
+			UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"ack_deadline_seconds", "dead_letter_policy"}},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile pubsub subscription %s: %w", queueName, err)
+	}
+
+	logger.Info("Pub/Sub queue reconciled", "queue", queueName, "dlq", deadLetterPolicy != nil)
+	return nil
+}
+
+// ensureTopic creates a topic if it does not exist
+func (t *PubSubTransport) ensureTopic(ctx context.Context, clients *pubsubClients, name string) error {
+	_, err := clients.publisher.CreateTopic(ctx, &pubsubpb.Topic{Name: clients.topicPath(name)})
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return fmt.Errorf("failed to create pubsub topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureDLQ creates the shared dead-letter topic and its subscription, returning the topic path.
+// Pub/Sub drops messages published to a topic without subscriptions, so the subscription is what
+// retains dead letters.
+func (t *PubSubTransport) ensureDLQ(ctx context.Context, clients *pubsubClients, pubsubConfig *asyaconfig.PubSubConfig, actor *asyav1alpha1.AsyncActor) (string, error) {
+	logger := log.FromContext(ctx)
+	dlqName := t.transportRegistry.QueueName(actor.Namespace, "dlq")
+
+	if err := t.ensureTopic(ctx, clients, dlqName); err != nil {
+		return "", err
+	}
+
+	retention := time.Duration(pubsubConfig.Queues.DLQ.RetentionDays) * 24 * time.Hour
+	_, err := clients.subscriber.CreateSubscription(ctx, &pubsubpb.Subscription{
+		Name:                     clients.subscriptionPath(dlqName),
+		Topic:                    clients.topicPath(dlqName),
+		MessageRetentionDuration: durationpb.New(retention),
+	})
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return "", fmt.Errorf("failed to create pubsub DLQ subscription %s: %w", dlqName, err)
+	}
+
+	logger.V(1).Info("Shared DLQ ensured", "dlq", dlqName)
+	return clients.topicPath(dlqName), nil
+}
+
+// DeleteQueue deletes the topic and subscription for an actor.
+// The shared DLQ is preserved for inspection.
+func (t *PubSubTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	pubsubConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	clients, err := t.connect(ctx, pubsubConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = clients.conn.Close()
+	}()
+
+	if err := t.deleteQueue(ctx, clients, queueName); err != nil {
+		return err
+	}
+
+	logger.Info("Pub/Sub queue deleted", "queue", queueName)
+	return nil
+}
+
+// deleteQueue deletes the subscription and topic, ignoring ones that are already gone
+func (t *PubSubTransport) deleteQueue(ctx context.Context, clients *pubsubClients, queueName string) error {
+	_, err := clients.subscriber.DeleteSubscription(ctx, &pubsubpb.DeleteSubscriptionRequest{
+		Subscription: clients.subscriptionPath(queueName),
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to delete pubsub subscription %s: %w", queueName, err)
+	}
+
+	_, err = clients.publisher.DeleteTopic(ctx, &pubsubpb.DeleteTopicRequest{
+		Topic: clients.topicPath(queueName),
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to delete pubsub topic %s: %w", queueName, err)
+	}
+	return nil
+}
+
+// QueueExists checks if the queue's subscription exists
+func (t *PubSubTransport) QueueExists(ctx context.Context, queueName, namespace string) (bool, error) {
+	pubsubConfig, err := t.loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	clients, err := t.connect(ctx, pubsubConfig)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = clients.conn.Close()
+	}()
+
+	return t.subscriptionExists(ctx, clients, queueName)
+}
+
+// subscriptionExists reports whether the subscription exists
+func (t *PubSubTransport) subscriptionExists(ctx context.Context, clients *pubsubClients, queueName string) (bool, error) {
+	_, err := clients.subscriber.GetSubscription(ctx, &pubsubpb.GetSubscriptionRequest{
+		Subscription: clients.subscriptionPath(queueName),
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check pubsub subscription %s: %w", queueName, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,203 @@
+package transports
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+// newPubSubTestConfig points at PUBSUB_EMULATOR_HOST when set, otherwise at an in-process fake server
+func newPubSubTestConfig(t *testing.T) *asyaconfig.PubSubConfig {
+	t.Helper()
+
+	addr := os.Getenv("PUBSUB_EMULATOR_HOST")
+	if addr == "" {
+		srv := pstest.NewServer()
+		t.Cleanup(func() { _ = srv.Close() })
+		addr = srv.Addr
+	}
+
+	return &asyaconfig.PubSubConfig{
+		ProjectID:    "asya-test",
+		EmulatorHost: addr,
+		AckDeadline:  60,
+		Queues: asyaconfig.QueueManagementConfig{
+			AutoCreate: true,
+			DLQ:        asyaconfig.DLQConfig{MaxRetryCount: 5, RetentionDays: 7},
+		},
+	}
+}
+
+func newPubSubTestRegistry(config asyaconfig.TransportSpecificConfig) *asyaconfig.TransportRegistry {
+	return &asyaconfig.TransportRegistry{
+		Transports: map[string]*asyaconfig.TransportConfig{
+			transportTypePubSub: {
+				Type:    transportTypePubSub,
+				Enabled: true,
+				Config:  config,
+			},
+		},
+	}
+}
+
+func newPubSubTestActor() *asyav1alpha1.AsyncActor {
+	return &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: testActorNamespace,
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypePubSub,
+		},
+	}
+}
+
+// getTestSubscription reads a subscription back from the emulator
+func getTestSubscription(t *testing.T, transport *PubSubTransport, config *asyaconfig.PubSubConfig, name string) *pubsubpb.Subscription {
+	t.Helper()
+	ctx := context.Background()
+
+	clients, err := transport.connect(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = clients.conn.Close() }()
+
+	sub, err := clients.subscriber.GetSubscription(ctx, &pubsubpb.GetSubscriptionRequest{Subscription: clients.subscriptionPath(name)})
+	if err != nil {
+		t.Fatalf("GetSubscription %s failed: %v", name, err)
+	}
+	return sub
+}
+
+func TestPubSubTransport_ReconcileAndDeleteQueue(t *testing.T) {
+	config := newPubSubTestConfig(t)
+	transport := NewPubSubTransport(newPubSubTestRegistry(config))
+	ctx := context.Background()
+	queueName := "asya-default-test-actor"
+
+	exists, err := transport.QueueExists(ctx, queueName, testActorNamespace)
+	if err != nil {
+		t.Fatalf("QueueExists failed: %v", err)
+	}
+	if exists {
+		t.Fatal("Expected subscription not to exist before reconcile")
+	}
+
+	if err := transport.ReconcileQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("ReconcileQueue failed: %v", err)
+	}
+	// Reconcile is idempotent (existing topic and subscription are updated)
+	if err := transport.ReconcileQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("Second ReconcileQueue failed: %v", err)
+	}
+
+	sub := getTestSubscription(t, transport, config, queueName)
+	if sub.Topic != "projects/asya-test/topics/asya-default-test-actor" {
+		t.Errorf("Subscription topic = %s", sub.Topic)
+	}
+	if sub.AckDeadlineSeconds != 60 {
+		t.Errorf("AckDeadlineSeconds = %d, want 60", sub.AckDeadlineSeconds)
+	}
+	if sub.DeadLetterPolicy != nil {
+		t.Errorf("Expected no dead-letter policy when DLQ disabled, got %+v", sub.DeadLetterPolicy)
+	}
+
+	if err := transport.DeleteQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("DeleteQueue failed: %v", err)
+	}
+	exists, err = transport.QueueExists(ctx, queueName, testActorNamespace)
+	if err != nil {
+		t.Fatalf("QueueExists failed: %v", err)
+	}
+	if exists {
+		t.Error("Expected subscription to be deleted")
+	}
+
+	// Deleting a missing queue is not an error
+	if err := transport.DeleteQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Errorf("Second DeleteQueue failed: %v", err)
+	}
+}
+
+func TestPubSubTransport_ReconcileQueue_DeadLetterPolicy(t *testing.T) {
+	config := newPubSubTestConfig(t)
+	config.Queues.DLQ.Enabled = true
+	config.Queues.DLQ.MaxRetryCount = 10
+	transport := NewPubSubTransport(newPubSubTestRegistry(config))
+	ctx := context.Background()
+
+	if err := transport.ReconcileQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("ReconcileQueue failed: %v", err)
+	}
+
+	sub := getTestSubscription(t, transport, config, "asya-default-test-actor")
+	if sub.DeadLetterPolicy == nil {
+		t.Fatal("Expected dead-letter policy")
+	}
+	if sub.DeadLetterPolicy.DeadLetterTopic != "projects/asya-test/topics/asya-default-dlq" {
+		t.Errorf("DeadLetterTopic = %s", sub.DeadLetterPolicy.DeadLetterTopic)
+	}
+	if sub.DeadLetterPolicy.MaxDeliveryAttempts != 10 {
+		t.Errorf("MaxDeliveryAttempts = %d, want 10", sub.DeadLetterPolicy.MaxDeliveryAttempts)
+	}
+
+	// Shared DLQ subscription retains dead letters and survives actor deletion
+	if err := transport.DeleteQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("DeleteQueue failed: %v", err)
+	}
+	dlq := getTestSubscription(t, transport, config, "asya-default-dlq")
+	if dlq.MessageRetentionDuration.AsDuration().Hours() != 7*24 {
+		t.Errorf("DLQ retention = %s, want 168h", dlq.MessageRetentionDuration.AsDuration())
+	}
+}
+
+func TestPubSubTransport_ReconcileQueue_ForceRecreate(t *testing.T) {
+	config := newPubSubTestConfig(t)
+	transport := NewPubSubTransport(newPubSubTestRegistry(config))
+	ctx := context.Background()
+
+	if err := transport.ReconcileQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("ReconcileQueue failed: %v", err)
+	}
+
+	config.Queues.ForceRecreate = true
+	config.AckDeadline = 120
+	if err := transport.ReconcileQueue(ctx, newPubSubTestActor()); err != nil {
+		t.Fatalf("ReconcileQueue with forceRecreate failed: %v", err)
+	}
+
+	sub := getTestSubscription(t, transport, config, "asya-default-test-actor")
+	if sub.AckDeadlineSeconds != 120 {
+		t.Errorf("AckDeadlineSeconds = %d, want 120", sub.AckDeadlineSeconds)
+	}
+}
+
+func TestPubSubTransport_ReconcileQueue_AutoCreateDisabled(t *testing.T) {
+	config := newPubSubTestConfig(t)
+	config.Queues.AutoCreate = false
+	transport := NewPubSubTransport(newPubSubTestRegistry(config))
+
+	err := transport.ReconcileQueue(context.Background(), newPubSubTestActor())
+	if err == nil || !strings.Contains(err.Error(), "autoCreate is disabled") {
+		t.Fatalf("Expected missing subscription error, got %v", err)
+	}
+}
+
+func TestPubSubTransport_InvalidConfigType(t *testing.T) {
+	registry := newPubSubTestRegistry(&asyaconfig.FileConfig{HostPath: "/tmp"})
+	transport := NewPubSubTransport(registry)
+
+	err := transport.ReconcileQueue(context.Background(), newPubSubTestActor())
+	if err == nil || err.Error() != errInvalidPubSubConfig {
+		t.Errorf("Expected %q, got %v", errInvalidPubSubConfig, err)
+	}
+}
@@ -50,7 +50,7 @@ func (t *RabbitMQTransport) ReconcileQueue(ctx context.Context, actor *asyav1alp
 		return errors.New(errInvalidRabbitMQConfig)
 	}
 
-	queueName := fmt.Sprintf("asya-%s-%s", actor.Namespace, actor.Name)
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
 
 	// Get RabbitMQ password from secret if configured
 	password := rabbitmqConfig.Password
@@ -128,7 +128,7 @@ func (t *RabbitMQTransport) ReconcileQueue(ctx context.Context, actor *asyav1alp
 	// Create DLQ if enabled
 	var dlqName string
 	if rabbitmqConfig.Queues.DLQ.Enabled {
-		dlqName = fmt.Sprintf("asya-%s-dlq", actor.Namespace)
+		dlqName = t.transportRegistry.QueueName(actor.Namespace, "dlq")
 		_, err = ch.QueueDeclare(
 			dlqName,
 			true,
@@ -217,7 +217,7 @@ func (t *RabbitMQTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1
 		return errors.New(errInvalidRabbitMQConfig)
 	}
 
-	queueName := fmt.Sprintf("asya-%s-%s", actor.Namespace, actor.Name)
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
 
 	// Get RabbitMQ password from secret if configured
 	password := rabbitmqConfig.Password
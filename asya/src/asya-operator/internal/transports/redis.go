@@ -0,0 +1,201 @@
+package transports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+const errInvalidRedisConfig = "invalid redis config type"
+
+// RedisTransport implements queue reconciliation for Redis Streams.
+// Each queue is a stream with one consumer group; the layout matches the sidecar
+// (asya-sidecar/internal/transport/redis.go).
+type RedisTransport struct {
+	k8sClient            client.Client
+	transportRegistry    *asyaconfig.TransportRegistry
+	credentialsNamespace string // Namespace to look up transport credential secrets
+}
+
+// NewRedisTransport creates a new Redis Streams transport reconciler
+func NewRedisTransport(k8sClient client.Client, registry *asyaconfig.TransportRegistry, credentialsNamespace string) *RedisTransport {
+	return &RedisTransport{
+		k8sClient:            k8sClient,
+		transportRegistry:    registry,
+		credentialsNamespace: credentialsNamespace,
+	}
+}
+
+// loadConfig returns the redis transport config
+func (t *RedisTransport) loadConfig() (*asyaconfig.RedisConfig, error) {
+	transport, err := t.transportRegistry.GetTransport(transportTypeRedis)
+	if err != nil {
+		return nil, err
+	}
+
+	redisConfig, ok := transport.Config.(*asyaconfig.RedisConfig)
+	if !ok {
+		return nil, errors.New(errInvalidRedisConfig)
+	}
+	return redisConfig, nil
+}
+
+// connect creates a client using the configured credentials
+func (t *RedisTransport) connect(ctx context.Context, redisConfig *asyaconfig.RedisConfig) (*redis.Client, error) {
+	password := redisConfig.Password
+	if redisConfig.PasswordSecretRef != nil {
+		var err error
+		password, err = t.loadPassword(ctx, redisConfig, t.credentialsNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis password: %w", err)
+		}
+	}
+
+	rdb := redis.NewClient(redisConfig.Options(password))
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return rdb, nil
+}
+
+// ReconcileQueue creates the stream and consumer group for an actor
+func (t *RedisTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	redisConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	rdb, err := t.connect(ctx, redisConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rdb.Close()
+	}()
+
+	if !redisConfig.Queues.AutoCreate {
+		exists, err := t.groupExists(ctx, rdb, queueName, redisConfig.Group)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("redis stream %s with group %s does not exist and autoCreate is disabled", queueName, redisConfig.Group)
+		}
+		logger.V(1).Info("Redis stream exists", "queue", queueName)
+		return nil
+	}
+
+	if redisConfig.Queues.ForceRecreate {
+		if err := rdb.Del(ctx, queueName).Err(); err != nil {
+			return fmt.Errorf("failed to delete redis stream %s: %w", queueName, err)
+		}
+		logger.Info("Redis stream deleted for recreation", "queue", queueName)
+	}
+
+	// Start the group at "0" so messages sent before the actor existed are delivered
+	err = rdb.XGroupCreateMkStream(ctx, queueName, redisConfig.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on %s: %w", redisConfig.Group, queueName, err)
+	}
+
+	logger.Info("Redis stream reconciled", "queue", queueName, "group", redisConfig.Group)
+	return nil
+}
+
+// DeleteQueue deletes the stream for an actor.
+// The dead-letter stream "{queue}:dead" is preserved for inspection.
+func (t *RedisTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
+	logger := log.FromContext(ctx)
+
+	redisConfig, err := t.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queueName := t.transportRegistry.QueueName(actor.Namespace, actor.Name)
+
+	rdb, err := t.connect(ctx, redisConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rdb.Close()
+	}()
+
+	if err := rdb.Del(ctx, queueName).Err(); err != nil {
+		return fmt.Errorf("failed to delete redis stream %s: %w", queueName, err)
+	}
+
+	logger.Info("Redis stream deleted", "queue", queueName)
+	return nil
+}
+
+// loadPassword loads redis password from Kubernetes secret
+func (t *RedisTransport) loadPassword(ctx context.Context, redisConfig *asyaconfig.RedisConfig, namespace string) (string, error) {
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{
+		Name:      redisConfig.PasswordSecretRef.Name,
+		Namespace: namespace,
+	}
+
+	if err := t.k8sClient.Get(ctx, secretKey, secret); err != nil {
+		return "", fmt.Errorf("failed to get redis password secret: %w", err)
+	}
+
+	passwordBytes, ok := secret.Data[redisConfig.PasswordSecretRef.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", redisConfig.PasswordSecretRef.Key, redisConfig.PasswordSecretRef.Name)
+	}
+
+	return string(passwordBytes), nil
+}
+
+// QueueExists checks if the stream and its consumer group exist
+func (t *RedisTransport) QueueExists(ctx context.Context, queueName, namespace string) (bool, error) {
+	redisConfig, err := t.loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	rdb, err := t.connect(ctx, redisConfig)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = rdb.Close()
+	}()
+
+	return t.groupExists(ctx, rdb, queueName, redisConfig.Group)
+}
+
+// groupExists reports whether the stream exists and has the consumer group
+func (t *RedisTransport) groupExists(ctx context.Context, rdb *redis.Client, queueName, group string) (bool, error) {
+	groups, err := rdb.XInfoGroups(ctx, queueName).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check redis stream %s: %w", queueName, err)
+	}
+
+	for _, g := range groups {
+		if g.Name == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,122 @@
+package transports
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asyav1alpha1 "github.com/asya/operator/api/v1alpha1"
+	asyaconfig "github.com/asya/operator/internal/config"
+)
+
+func newRedisTestConfig(t *testing.T, mr *miniredis.Miniredis) *asyaconfig.RedisConfig {
+	t.Helper()
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &asyaconfig.RedisConfig{
+		Host:   mr.Host(),
+		Port:   port,
+		Group:  "asya",
+		Queues: asyaconfig.QueueManagementConfig{AutoCreate: true},
+	}
+}
+
+func newRedisTestRegistry(config asyaconfig.TransportSpecificConfig) *asyaconfig.TransportRegistry {
+	return &asyaconfig.TransportRegistry{
+		Transports: map[string]*asyaconfig.TransportConfig{
+			transportTypeRedis: {
+				Type:    transportTypeRedis,
+				Enabled: true,
+				Config:  config,
+			},
+		},
+	}
+}
+
+func newRedisTestActor() *asyav1alpha1.AsyncActor {
+	return &asyav1alpha1.AsyncActor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testActorName,
+			Namespace: testActorNamespace,
+		},
+		Spec: asyav1alpha1.AsyncActorSpec{
+			Transport: transportTypeRedis,
+		},
+	}
+}
+
+func TestRedisTransport_ReconcileAndDeleteQueue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	transport := NewRedisTransport(newTransportTestClient().Build(), newRedisTestRegistry(newRedisTestConfig(t, mr)), testActorNamespace)
+	ctx := context.Background()
+	queueName := "asya-default-test-actor"
+
+	exists, err := transport.QueueExists(ctx, queueName, testActorNamespace)
+	if err != nil {
+		t.Fatalf("QueueExists failed: %v", err)
+	}
+	if exists {
+		t.Fatal("Expected stream not to exist before reconcile")
+	}
+
+	if err := transport.ReconcileQueue(ctx, newRedisTestActor()); err != nil {
+		t.Fatalf("ReconcileQueue failed: %v", err)
+	}
+	// Reconcile is idempotent (BUSYGROUP ignored)
+	if err := transport.ReconcileQueue(ctx, newRedisTestActor()); err != nil {
+		t.Fatalf("Second ReconcileQueue failed: %v", err)
+	}
+
+	exists, err = transport.QueueExists(ctx, queueName, testActorNamespace)
+	if err != nil {
+		t.Fatalf("QueueExists failed: %v", err)
+	}
+	if !exists {
+		t.Fatal("Expected stream and group to exist after reconcile")
+	}
+
+	if err := transport.DeleteQueue(ctx, newRedisTestActor()); err != nil {
+		t.Fatalf("DeleteQueue failed: %v", err)
+	}
+	if mr.Exists(queueName) {
+		t.Error("Expected stream to be deleted")
+	}
+}
+
+func TestRedisTransport_ReconcileQueue_AutoCreateDisabled(t *testing.T) {
+	mr := miniredis.RunT(t)
+	config := newRedisTestConfig(t, mr)
+	config.Queues.AutoCreate = false
+	transport := NewRedisTransport(newTransportTestClient().Build(), newRedisTestRegistry(config), testActorNamespace)
+
+	err := transport.ReconcileQueue(context.Background(), newRedisTestActor())
+	if err == nil || !strings.Contains(err.Error(), "autoCreate is disabled") {
+		t.Fatalf("Expected missing stream error, got %v", err)
+	}
+}
+
+func TestRedisTransport_ConnectionFailure(t *testing.T) {
+	config := &asyaconfig.RedisConfig{Host: "127.0.0.1", Port: 1, Group: "asya"}
+	transport := NewRedisTransport(newTransportTestClient().Build(), newRedisTestRegistry(config), testActorNamespace)
+
+	err := transport.ReconcileQueue(context.Background(), newRedisTestActor())
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to redis") {
+		t.Errorf("Expected connection error, got %v", err)
+	}
+}
+
+func TestRedisTransport_InvalidConfigType(t *testing.T) {
+	registry := newRedisTestRegistry(&asyaconfig.FileConfig{HostPath: "/tmp"})
+	transport := NewRedisTransport(newTransportTestClient().Build(), registry, testActorNamespace)
+
+	err := transport.ReconcileQueue(context.Background(), newRedisTestActor())
+	if err == nil || err.Error() != errInvalidRedisConfig {
+		t.Errorf("Expected %q, got %v", errInvalidRedisConfig, err)
+	}
+}
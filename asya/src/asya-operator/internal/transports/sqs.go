@@ -2,6 +2,7 @@ package transports
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -53,7 +54,7 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 
 	logger.V(1).Info("SQS config loaded", "configuredTags", sqsConfig.Tags, "autoCreate", sqsConfig.Queues.AutoCreate)
 
-	queueName := fmt.Sprintf("asya-%s-%s", actor.Namespace, actor.Name)
+	queueName := sqsConfig.QueueName(t.transportRegistry.QueueName(actor.Namespace, actor.Name))
 
 	sqsClient, err := t.createSQSClient(ctx, sqsConfig, t.credentialsNamespace)
 	if err != nil {
@@ -80,7 +81,7 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 			existingTimeout := attrsResult.Attributes[string(types.QueueAttributeNameVisibilityTimeout)]
 			if existingTimeout == visibilityTimeout {
 				logger.Info("SQS queue already exists with matching configuration", "queue", queueName)
-				return nil
+				return t.reconcileSendPolicy(ctx, sqsClient, aws.ToString(urlResult.QueueUrl), queueName, actor, sqsConfig)
 			}
 
 			// Configuration mismatch
@@ -124,7 +125,7 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 		if err != nil {
 			return fmt.Errorf("failed to ensure shared DLQ: %w", err)
 		}
-		logger.Info("Shared DLQ ensured", "dlq", "asya-dlq", "arn", dlqArn)
+		logger.Info("Shared DLQ ensured", "dlq", sqsConfig.QueueName(t.transportRegistry.QueueName(actor.Namespace, "dlq")), "arn", dlqArn)
 	}
 
 	// Merge configured tags with default tags
@@ -148,6 +149,13 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 		"MessageRetentionPeriod": "345600",
 	}
 
+	// FIFO queues deliver in order within a message group; senders set MessageDeduplicationId,
+	// content-based deduplication covers messages sent without one
+	if sqsConfig.FIFO {
+		queueAttributes["FifoQueue"] = "true"
+		queueAttributes["ContentBasedDeduplication"] = "true"
+	}
+
 	// Add RedrivePolicy if DLQ is enabled
 	if sqsConfig.Queues.DLQ.Enabled && dlqArn != "" {
 		redrivePolicy := fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":%d}`,
@@ -157,7 +165,7 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 	}
 
 	// Create queue (either doesn't exist, or was deleted for recreation)
-	_, err = sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
+	createResult, err := sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
 		QueueName:  aws.String(queueName),
 		Attributes: queueAttributes,
 		Tags:       tags,
@@ -173,9 +181,123 @@ func (t *SQSTransport) ReconcileQueue(ctx context.Context, actor *asyav1alpha1.A
 	}
 
 	logger.Info("SQS queue created successfully", "queue", queueName, "appliedTags", tags)
+	return t.reconcileSendPolicy(ctx, sqsClient, aws.ToString(createResult.QueueUrl), queueName, actor, sqsConfig)
+}
+
+// reconcileSendPolicy maintains the queue policy statement granting sqs:SendMessage to the actor roles of
+// namespaces the actor's namespace accepts envelopes from (see AllowedSourceNamespacesAnnotation), and removes
+// it once none do. Other statements of the policy are kept. It only applies to operator-managed queues with a per-namespace actorRoleArn: a shared
+// role already reaches every queue, so cross-namespace routing is then enforced by the sidecars alone.
+func (t *SQSTransport) reconcileSendPolicy(ctx context.Context, sqsClient *sqs.Client, queueURL, queueName string, actor *asyav1alpha1.AsyncActor, sqsConfig *asyaconfig.SQSConfig) error {
+	logger := log.FromContext(ctx)
+
+	if !sqsConfig.Queues.AutoCreate || !sqsConfig.HasPerNamespaceRoles() {
+		return nil
+	}
+
+	sources, err := AllowedSourceNamespaces(ctx, t.k8sClient, actor.Namespace)
+	if err != nil {
+		return err
+	}
+
+	attrsResult, err := sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeNameQueueArn,
+			types.QueueAttributeNamePolicy,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get attributes of queue %s: %w", queueName, err)
+	}
+
+	current := attrsResult.Attributes[string(types.QueueAttributeNamePolicy)]
+	policy, err := buildSendPolicy(current, attrsResult.Attributes[string(types.QueueAttributeNameQueueArn)], sqsConfig, sources)
+	if err != nil {
+		return fmt.Errorf("failed to build policy of queue %s: %w", queueName, err)
+	}
+	if current == policy {
+		return nil
+	}
+
+	_, err = sqsClient.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(queueURL),
+		Attributes: map[string]string{string(types.QueueAttributeNamePolicy): policy},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set policy of queue %s: %w", queueName, err)
+	}
+
+	logger.Info("SQS queue cross-namespace send policy reconciled", "queue", queueName, "sourceNamespaces", sources)
 	return nil
 }
 
+// sendPolicySid identifies the queue policy statement managed by the operator
+const sendPolicySid = "AsyaCrossNamespaceSend"
+
+// buildSendPolicy returns the queue policy current with its sendPolicySid statement replaced by one allowing
+// the source namespaces' actor roles to send to queueArn, or removed if there are no source namespaces.
+// A "*" source matches the role of every namespace. Returns "" when the policy is left without statements.
+func buildSendPolicy(current, queueArn string, sqsConfig *asyaconfig.SQSConfig, sources []string) (string, error) {
+	policy := map[string]interface{}{}
+	if current != "" {
+		if err := json.Unmarshal([]byte(current), &policy); err != nil {
+			return "", fmt.Errorf("failed to parse queue policy: %w", err)
+		}
+	}
+
+	// IAM allows a single statement object in place of the list
+	var statements []interface{}
+	switch existing := policy["Statement"].(type) {
+	case nil:
+	case []interface{}:
+		statements = existing
+	case map[string]interface{}:
+		statements = []interface{}{existing}
+	default:
+		return "", fmt.Errorf("unexpected queue policy Statement %v", existing)
+	}
+
+	kept := make([]interface{}, 0, len(statements)+1)
+	for _, statement := range statements {
+		if fields, ok := statement.(map[string]interface{}); ok && fields["Sid"] == sendPolicySid {
+			continue
+		}
+		kept = append(kept, statement)
+	}
+
+	if len(sources) > 0 {
+		principals := make([]string, 0, len(sources))
+		for _, source := range sources {
+			principals = append(principals, sqsConfig.ActorRoleArnFor(source))
+		}
+		kept = append(kept, map[string]interface{}{
+			"Sid":       sendPolicySid,
+			"Effect":    "Allow",
+			"Principal": map[string]string{"AWS": "*"},
+			"Action":    "sqs:SendMessage",
+			"Resource":  queueArn,
+			"Condition": map[string]interface{}{
+				"ArnLike": map[string][]string{"aws:PrincipalArn": principals},
+			},
+		})
+	}
+
+	if len(kept) == 0 {
+		return "", nil
+	}
+	if _, ok := policy["Version"]; !ok {
+		policy["Version"] = "2012-10-17"
+	}
+	policy["Statement"] = kept
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal queue policy: %w", err)
+	}
+	return string(data), nil
+}
+
 // DeleteQueue deletes the SQS queue for an actor
 func (t *SQSTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.AsyncActor) error {
 	logger := log.FromContext(ctx)
@@ -190,7 +312,7 @@ func (t *SQSTransport) DeleteQueue(ctx context.Context, actor *asyav1alpha1.Asyn
 		return fmt.Errorf("invalid SQS config type")
 	}
 
-	queueName := fmt.Sprintf("asya-%s-%s", actor.Namespace, actor.Name)
+	queueName := sqsConfig.QueueName(t.transportRegistry.QueueName(actor.Namespace, actor.Name))
 
 	sqsClient, err := t.createSQSClient(ctx, sqsConfig, t.credentialsNamespace)
 	if err != nil {
@@ -330,7 +452,8 @@ func (t *SQSTransport) forceDeleteQueue(ctx context.Context, sqsClient *sqs.Clie
 // ensureDLQ creates or retrieves the shared DLQ and returns its ARN
 func (t *SQSTransport) ensureDLQ(ctx context.Context, sqsClient *sqs.Client, mainQueueName string, sqsConfig *asyaconfig.SQSConfig, actor *asyav1alpha1.AsyncActor) (string, error) {
 	logger := log.FromContext(ctx)
-	dlqName := fmt.Sprintf("asya-%s-dlq", actor.Namespace)
+	// The DLQ of a FIFO queue must itself be a FIFO queue
+	dlqName := sqsConfig.QueueName(t.transportRegistry.QueueName(actor.Namespace, "dlq"))
 
 	// Check if DLQ already exists
 	urlResult, err := sqsClient.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
@@ -370,12 +493,18 @@ func (t *SQSTransport) ensureDLQ(ctx context.Context, sqsClient *sqs.Client, mai
 		tags[k] = v
 	}
 
+	dlqAttributes := map[string]string{
+		"MessageRetentionPeriod": retentionPeriod,
+	}
+	if sqsConfig.FIFO {
+		dlqAttributes["FifoQueue"] = "true"
+		dlqAttributes["ContentBasedDeduplication"] = "true"
+	}
+
 	createResult, err := sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
-		QueueName: aws.String(dlqName),
-		Attributes: map[string]string{
-			"MessageRetentionPeriod": retentionPeriod,
-		},
-		Tags: tags,
+		QueueName:  aws.String(dlqName),
+		Attributes: dlqAttributes,
+		Tags:       tags,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create DLQ: %w", err)
@@ -416,7 +545,7 @@ func (t *SQSTransport) QueueExists(ctx context.Context, queueName, namespace str
 
 	// Try to get queue URL
 	_, err = sqsClient.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName),
+		QueueName: aws.String(sqsConfig.QueueName(queueName)),
 	})
 
 	if err != nil {
@@ -2,6 +2,8 @@ package transports
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -421,6 +423,102 @@ func TestSQSConfig_TagsMerging(t *testing.T) {
 	}
 }
 
+func TestBuildSendPolicy(t *testing.T) {
+	sqsConfig := &asyaconfig.SQSConfig{ActorRoleArn: "arn:aws:iam::123456789012:role/asya-{namespace}-actors"}
+	queueArn := "arn:aws:sqs:us-east-1:123456789012:asya-team-b-ocr"
+
+	type statement struct {
+		Sid       string
+		Effect    string
+		Action    string
+		Resource  string
+		Condition map[string]map[string][]string
+	}
+	parse := func(t *testing.T, policy string) []statement {
+		t.Helper()
+		var parsed struct {
+			Version   string
+			Statement []statement
+		}
+		if err := json.Unmarshal([]byte(policy), &parsed); err != nil {
+			t.Fatalf("Policy is not valid JSON: %v", err)
+		}
+		if parsed.Version != "2012-10-17" {
+			t.Errorf("Expected policy version 2012-10-17, got %q", parsed.Version)
+		}
+		return parsed.Statement
+	}
+
+	policy, err := buildSendPolicy("", queueArn, sqsConfig, nil)
+	if err != nil {
+		t.Fatalf("buildSendPolicy failed: %v", err)
+	}
+	if policy != "" {
+		t.Errorf("Expected empty policy without source namespaces, got %s", policy)
+	}
+
+	policy, err = buildSendPolicy("", queueArn, sqsConfig, []string{"team-a", "*"})
+	if err != nil {
+		t.Fatalf("buildSendPolicy failed: %v", err)
+	}
+	statements := parse(t, policy)
+	if len(statements) != 1 {
+		t.Fatalf("Expected 1 statement, got %d", len(statements))
+	}
+	managed := statements[0]
+	if managed.Sid != sendPolicySid || managed.Effect != "Allow" || managed.Action != "sqs:SendMessage" || managed.Resource != queueArn {
+		t.Errorf("Unexpected statement: %+v", managed)
+	}
+	expected := []string{"arn:aws:iam::123456789012:role/asya-team-a-actors", "arn:aws:iam::123456789012:role/asya-*-actors"}
+	if got := managed.Condition["ArnLike"]["aws:PrincipalArn"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected principals %v, got %v", expected, got)
+	}
+
+	// Statements added outside the operator are kept; the managed statement is replaced
+	external := `{"Version":"2012-10-17","Id":"custom","Statement":[` +
+		`{"Sid":"AllowSNS","Effect":"Allow","Principal":{"Service":"sns.amazonaws.com"},"Action":"sqs:SendMessage","Resource":"` + queueArn + `"},` +
+		`{"Sid":"` + sendPolicySid + `","Effect":"Allow","Principal":{"AWS":"*"},"Action":"sqs:SendMessage","Resource":"` + queueArn + `",` +
+		`"Condition":{"ArnLike":{"aws:PrincipalArn":["arn:aws:iam::123456789012:role/asya-old-actors"]}}}]}`
+	policy, err = buildSendPolicy(external, queueArn, sqsConfig, []string{"team-a"})
+	if err != nil {
+		t.Fatalf("buildSendPolicy failed: %v", err)
+	}
+	if !strings.Contains(policy, `"Id":"custom"`) {
+		t.Errorf("Expected policy Id to be kept, got %s", policy)
+	}
+	statements = parse(t, policy)
+	if len(statements) != 2 || statements[0].Sid != "AllowSNS" || statements[1].Sid != sendPolicySid {
+		t.Fatalf("Expected AllowSNS and %s statements, got %+v", sendPolicySid, statements)
+	}
+	if got := statements[1].Condition["ArnLike"]["aws:PrincipalArn"]; !reflect.DeepEqual(got, []string{"arn:aws:iam::123456789012:role/asya-team-a-actors"}) {
+		t.Errorf("Expected managed statement to be replaced, got principals %v", got)
+	}
+
+	// Without source namespaces only the managed statement is removed
+	policy, err = buildSendPolicy(external, queueArn, sqsConfig, nil)
+	if err != nil {
+		t.Fatalf("buildSendPolicy failed: %v", err)
+	}
+	statements = parse(t, policy)
+	if len(statements) != 1 || statements[0].Sid != "AllowSNS" {
+		t.Errorf("Expected only the AllowSNS statement, got %+v", statements)
+	}
+
+	// A single statement object is accepted in place of the list
+	single := `{"Version":"2012-10-17","Statement":{"Sid":"AllowSNS","Effect":"Allow","Action":"sqs:SendMessage","Resource":"` + queueArn + `"}}`
+	policy, err = buildSendPolicy(single, queueArn, sqsConfig, []string{"team-a"})
+	if err != nil {
+		t.Fatalf("buildSendPolicy failed: %v", err)
+	}
+	if statements = parse(t, policy); len(statements) != 2 {
+		t.Errorf("Expected 2 statements, got %+v", statements)
+	}
+
+	if _, err := buildSendPolicy("not json", queueArn, sqsConfig, nil); err == nil {
+		t.Error("Expected error for an unparsable policy")
+	}
+}
+
 func TestSQSTransport_QueueExists_TransportNotFound(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -28,6 +28,16 @@ Environment Variables:
     ASYA_ENABLE_VALIDATION: Enable envelope validation ("true" or "false", default: "true")
     ASYA_LOG_LEVEL: Logging level (DEBUG, INFO, WARNING, ERROR, default: INFO)
 
+Custom Metrics:
+    Handlers can report metrics declared in the sidecar's ASYA_CUSTOM_METRICS:
+        from asya_runtime import emit_metric
+
+        def process(payload: dict) -> dict:
+            emit_metric("tokens_total", len(tokens), {"model": "gpt-4"})
+            ...
+
+    Counters are incremented by value, gauges are set to value and histograms observe value.
+
 Socket Configuration:
     The socket path defaults to /var/run/asya/asya-runtime.sock and is managed by the operator.
     ASYA_SOCKET_DIR and ASYA_SOCKET_NAME are for internal testing only - DO NOT set in production.
@@ -78,6 +88,24 @@ SOCKET_PATH = os.path.join(SOCKET_DIR, SOCKET_NAME)
 
 VALID_ASYA_HANDLER_MODES = ("payload", "envelope")
 
+# Make `import asya_runtime` in handlers resolve to this module when it runs as a script
+sys.modules.setdefault("asya_runtime", sys.modules[__name__])
+
+# Metric observations emitted by the handler for the current request
+_metrics: list[dict[str, Any]] = []
+
+
+def emit_metric(name: str, value: float, labels: dict[str, str] | None = None) -> None:
+    """Report a custom metric observation to the sidecar with the current response.
+
+    The metric must be declared in ASYA_CUSTOM_METRICS and labels must match its declared
+    labels; the sidecar drops invalid observations.
+    """
+    observation: dict[str, Any] = {"name": name, "value": float(value)}
+    if labels:
+        observation["labels"] = {str(k): str(v) for k, v in labels.items()}
+    _metrics.append(observation)
+
 
 def _instantiate_class_handler(handler_class):
     """Instantiate class handler.
@@ -340,6 +368,20 @@ def _validate_envelope(
     if "id" in e and not isinstance(e["id"], str):
         raise ValueError("Field 'id' must be a string")
 
+    # Validate sub-route call if present (sidecar resumes 'route' once the called route finishes).
+    # A call lists its actors or names a route from the sidecar's ASYA_NAMED_ROUTES.
+    if "call" in e:
+        call = e["call"]
+        if not isinstance(call, dict):
+            raise ValueError("Field 'call' must be a dict")
+        if "name" in call:
+            if "actors" in call:
+                raise ValueError("Field 'call' must set either 'name' or 'actors', not both")
+            if not isinstance(call["name"], str) or not call["name"]:
+                raise ValueError("Field 'call.name' must be a non-empty string")
+        elif not isinstance(call.get("actors"), list) or len(call["actors"]) == 0:
+            raise ValueError("Field 'call.actors' must be a non-empty list")
+
     result = {
         "payload": e["payload"],
         "route": e["route"],
@@ -350,6 +392,8 @@ def _validate_envelope(
         result["parent_id"] = e["parent_id"]
     if "headers" in e:
         result["headers"] = e["headers"]
+    if "call" in e:
+        result["call"] = e["call"]
 
     return result
 
@@ -373,6 +417,16 @@ def _error_response(code: str, exc: Exception | None = None) -> list[dict[str, A
 
 
 def _handle_request(conn: socket.socket, user_func: Any) -> list[dict[str, Any]]:
+    """Handle a single request, appending a metrics-only response if the handler emitted metrics."""
+    _metrics.clear()
+    responses = _process_request(conn, user_func)
+    if _metrics:
+        responses.append({"metrics": list(_metrics)})
+        _metrics.clear()
+    return responses
+
+
+def _process_request(conn: socket.socket, user_func: Any) -> list[dict[str, Any]]:
     """Handle a single request with length-prefix framing."""
     # Read envelope from socket
     try:
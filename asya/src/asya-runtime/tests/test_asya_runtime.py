@@ -283,6 +283,59 @@ class TestEnvelopeFieldPreservation:
         assert validated["route"] == {"actors": ["a", "b"], "current": 0}
         assert validated["headers"] == {"trace_id": "trace-123", "priority": "high"}
 
+    def test_validate_envelope_preserves_call_field(self):
+        """Test that sub-route call is preserved through validation."""
+        envelope = {
+            "payload": {"test": "data"},
+            "route": {"actors": ["a", "b"], "current": 1},
+            "call": {"actors": ["ocr", "translate"]},
+        }
+        validated = asya_runtime._validate_envelope(envelope)
+
+        assert validated["call"] == {"actors": ["ocr", "translate"]}
+        assert validated["route"] == {"actors": ["a", "b"], "current": 1}
+
+    def test_validate_envelope_call_without_actors(self):
+        """Test that a call without actors fails validation."""
+        envelope = {
+            "payload": {"test": "data"},
+            "route": {"actors": ["a"], "current": 1},
+            "call": {"actors": []},
+        }
+        with pytest.raises(ValueError, match="Field 'call.actors' must be a non-empty list"):
+            asya_runtime._validate_envelope(envelope)
+
+    def test_validate_envelope_preserves_named_call(self):
+        """Test that a call naming a route from ASYA_NAMED_ROUTES is preserved through validation."""
+        envelope = {
+            "payload": {"test": "data"},
+            "route": {"actors": ["a", "b"], "current": 1},
+            "call": {"name": "document"},
+        }
+        validated = asya_runtime._validate_envelope(envelope)
+
+        assert validated["call"] == {"name": "document"}
+
+    def test_validate_envelope_call_with_name_and_actors(self):
+        """Test that a call cannot both name a route and list actors."""
+        envelope = {
+            "payload": {"test": "data"},
+            "route": {"actors": ["a"], "current": 1},
+            "call": {"name": "document", "actors": ["ocr"]},
+        }
+        with pytest.raises(ValueError, match="either 'name' or 'actors'"):
+            asya_runtime._validate_envelope(envelope)
+
+    def test_validate_envelope_call_with_empty_name(self):
+        """Test that a call with an empty route name fails validation."""
+        envelope = {
+            "payload": {"test": "data"},
+            "route": {"actors": ["a"], "current": 1},
+            "call": {"name": ""},
+        }
+        with pytest.raises(ValueError, match="Field 'call.name' must be a non-empty string"):
+            asya_runtime._validate_envelope(envelope)
+
     def test_validate_envelope_without_id_field(self):
         """Test that envelope without id field still validates (id is optional)."""
         envelope = {
@@ -1205,6 +1258,81 @@ class TestHandleRequestEnvelopeMode:
             assert "envelope[1/2]" in responses[0]["details"]["message"]
 
 
+class TestEmitMetric:
+    """Test custom metrics emitted by handlers via emit_metric."""
+
+    def _call(self, socket_pair, handler, envelope=None):
+        server_sock, client_sock = socket_pair
+        envelope = envelope or {
+            "route": {"actors": ["actor1", "actor2"], "current": 0},
+            "payload": {"value": 1},
+        }
+        asya_runtime._send_envelope(client_sock, json.dumps(envelope).encode("utf-8"))
+        return asya_runtime._handle_request(server_sock, handler)
+
+    def test_metrics_appended_after_outputs(self, socket_pair):
+        """Test emitted metrics are sent as a separate metrics-only response."""
+
+        def handler(payload):
+            asya_runtime.emit_metric("tokens_total", 12, {"model": "gpt-4"})
+            asya_runtime.emit_metric("confidence", 0.9)
+            return {"ok": True}
+
+        responses = self._call(socket_pair, handler)
+
+        assert len(responses) == 2
+        assert responses[0]["payload"] == {"ok": True}
+        assert responses[1] == {
+            "metrics": [
+                {"name": "tokens_total", "value": 12.0, "labels": {"model": "gpt-4"}},
+                {"name": "confidence", "value": 0.9},
+            ]
+        }
+
+    def test_metrics_kept_on_handler_error(self, socket_pair):
+        """Test metrics emitted before an exception are still reported."""
+
+        def handler(payload):
+            asya_runtime.emit_metric("attempts_total", 1)
+            raise RuntimeError("model unavailable")
+
+        responses = self._call(socket_pair, handler)
+
+        assert len(responses) == 2
+        assert responses[0]["error"] == "processing_error"
+        assert responses[1] == {"metrics": [{"name": "attempts_total", "value": 1.0}]}
+
+    def test_metrics_with_empty_output(self, socket_pair):
+        """Test a handler returning None can still report metrics."""
+
+        def handler(payload):
+            asya_runtime.emit_metric("skipped_total", 1)
+            return None
+
+        responses = self._call(socket_pair, handler)
+
+        assert responses == [{"metrics": [{"name": "skipped_total", "value": 1.0}]}]
+
+    def test_metrics_reset_between_requests(self):
+        """Test metrics do not leak into the next request."""
+
+        def emitting(payload):
+            asya_runtime.emit_metric("tokens_total", 3)
+            return {"ok": True}
+
+        def silent(payload):
+            return {"ok": True}
+
+        for handler, expected in ((emitting, 2), (silent, 1)):
+            server_sock, client_sock = socket.socketpair(socket.AF_UNIX, socket.SOCK_STREAM)
+            try:
+                responses = self._call((server_sock, client_sock), handler)
+                assert len(responses) == expected
+            finally:
+                server_sock.close()
+                client_sock.close()
+
+
 class TestHandleRequestErrorCases:
     """Test error handling in _handle_request."""
 
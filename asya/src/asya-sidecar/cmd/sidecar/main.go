@@ -13,9 +13,11 @@ import (
 	"time"
 
 	"github.com/deliveryhero/asya/asya-sidecar/internal/config"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/encryption"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/metrics"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/router"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/runtime"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/signing"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/transport"
 )
 
@@ -134,7 +136,6 @@ func main() {
 			URL:           cfg.RabbitMQURL,
 			Exchange:      cfg.RabbitMQExchange,
 			PrefetchCount: cfg.RabbitMQPrefetch,
-			Namespace:     cfg.Namespace,
 		})
 		if err != nil {
 			slog.Error("Failed to create RabbitMQ transport", "error", err)
@@ -152,6 +153,9 @@ func main() {
 			BaseURL:           cfg.SQSBaseURL,
 			VisibilityTimeout: visibilityTimeout,
 			WaitTimeSeconds:   cfg.SQSWaitTimeSeconds,
+			MaxMessages:       cfg.SQSMaxMessages,
+			FIFO:              cfg.SQSFIFO,
+			MessageGroup:      cfg.SQSMessageGroup,
 		})
 		if err != nil {
 			slog.Error("Failed to create SQS transport", "error", err)
@@ -161,7 +165,97 @@ func main() {
 			"region", cfg.SQSRegion,
 			"baseURL", cfg.SQSBaseURL,
 			"visibilityTimeout", visibilityTimeout,
-			"waitTimeSeconds", cfg.SQSWaitTimeSeconds)
+			"waitTimeSeconds", cfg.SQSWaitTimeSeconds,
+			"maxMessages", cfg.SQSMaxMessages,
+			"fifo", cfg.SQSFIFO,
+			"messageGroup", cfg.SQSMessageGroup)
+	case "file":
+		visibilityTimeout := cfg.FileVisibilityTimeout
+		if visibilityTimeout == 0 {
+			visibilityTimeout = cfg.Timeout * 2
+		}
+		tp, err = transport.NewFileTransport(transport.FileConfig{
+			Root:              cfg.FileRoot,
+			VisibilityTimeout: visibilityTimeout,
+			PollInterval:      cfg.FilePollInterval,
+			MaxDeliveries:     cfg.FileMaxDeliveries,
+		})
+		if err != nil {
+			slog.Error("Failed to create file transport", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("File transport initialized",
+			"root", cfg.FileRoot,
+			"visibilityTimeout", visibilityTimeout,
+			"pollInterval", cfg.FilePollInterval,
+			"maxDeliveries", cfg.FileMaxDeliveries)
+	case "postgres":
+		visibilityTimeout := cfg.PostgresVisibilityTimeout
+		if visibilityTimeout == 0 {
+			visibilityTimeout = cfg.Timeout * 2
+		}
+		tp, err = transport.NewPostgresTransport(context.Background(), transport.PostgresConfig{
+			URL:               cfg.PostgresURL,
+			VisibilityTimeout: visibilityTimeout,
+			PollInterval:      cfg.PostgresPollInterval,
+			MaxDeliveries:     cfg.PostgresMaxDeliveries,
+			AutoCreate:        cfg.PostgresAutoCreate,
+		})
+		if err != nil {
+			slog.Error("Failed to create Postgres transport", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Postgres transport initialized",
+			"visibilityTimeout", visibilityTimeout,
+			"pollInterval", cfg.PostgresPollInterval,
+			"maxDeliveries", cfg.PostgresMaxDeliveries)
+	case "redis":
+		visibilityTimeout := cfg.RedisVisibilityTimeout
+		if visibilityTimeout == 0 {
+			visibilityTimeout = cfg.Timeout * 2
+		}
+		tp, err = transport.NewRedisTransport(context.Background(), transport.RedisConfig{
+			URL:               cfg.RedisURL,
+			Group:             cfg.RedisGroup,
+			Consumer:          cfg.RedisConsumer,
+			VisibilityTimeout: visibilityTimeout,
+			BlockTimeout:      cfg.RedisBlockTimeout,
+			MaxDeliveries:     cfg.RedisMaxDeliveries,
+			AutoCreate:        cfg.RedisAutoCreate,
+		})
+		if err != nil {
+			slog.Error("Failed to create Redis transport", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Redis transport initialized",
+			"group", cfg.RedisGroup,
+			"consumer", cfg.RedisConsumer,
+			"visibilityTimeout", visibilityTimeout,
+			"maxDeliveries", cfg.RedisMaxDeliveries)
+	case "pubsub":
+		visibilityTimeout := cfg.PubSubVisibilityTimeout
+		if visibilityTimeout == 0 {
+			visibilityTimeout = cfg.Timeout * 2
+		}
+		tp, err = transport.NewPubSubTransport(context.Background(), transport.PubSubConfig{
+			ProjectID:         cfg.PubSubProjectID,
+			EmulatorHost:      cfg.PubSubEmulatorHost,
+			Endpoint:          cfg.PubSubEndpoint,
+			ClientID:          cfg.PubSubClientID,
+			AckDeadline:       cfg.PubSubAckDeadline,
+			VisibilityTimeout: visibilityTimeout,
+			MaxOutstanding:    cfg.PubSubMaxOutstanding,
+		})
+		if err != nil {
+			slog.Error("Failed to create Pub/Sub transport", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Pub/Sub transport initialized",
+			"project", cfg.PubSubProjectID,
+			"emulatorHost", cfg.PubSubEmulatorHost,
+			"ackDeadline", cfg.PubSubAckDeadline,
+			"visibilityTimeout", visibilityTimeout,
+			"maxOutstanding", cfg.PubSubMaxOutstanding)
 	default:
 		slog.Error("Unsupported transport type", "transport", cfg.TransportType)
 		os.Exit(1)
@@ -185,6 +279,33 @@ func main() {
 	// Create router
 	r := router.NewRouter(cfg, tp, runtimeClient, m)
 
+	encryptor, err := encryption.New(context.Background(), encryption.Config{
+		Provider:    cfg.EncryptionProvider,
+		KeyringPath: cfg.EncryptionKeyringPath,
+		KMSKeyID:    cfg.EncryptionKMSKeyID,
+		KMSRegion:   cfg.SQSRegion,
+		KMSEndpoint: cfg.EncryptionKMSEndpoint,
+		DataKeyTTL:  cfg.EncryptionDataKeyTTL,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize payload encryption", "error", err)
+		os.Exit(1)
+	}
+	if encryptor != nil {
+		r.SetEncryptor(encryptor)
+		slog.Info("Payload encryption enabled", "provider", cfg.EncryptionProvider)
+	}
+
+	if cfg.SigningKeyringPath != "" {
+		signingKeys, err := encryption.NewKeyringProvider(cfg.SigningKeyringPath)
+		if err != nil {
+			slog.Error("Failed to load signing keyring", "error", err)
+			os.Exit(1)
+		}
+		r.SetSigner(signing.NewSigner(signingKeys))
+		slog.Info("Envelope signing enabled", "keyring", cfg.SigningKeyringPath)
+	}
+
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -240,8 +361,17 @@ func main() {
 
 	// Run router
 	slog.Info("Starting message processing")
-	if err := r.Run(ctx); err != nil && err != context.Canceled {
-		slog.Error("Router error", "error", err)
+	runErr := r.Run(ctx)
+
+	// Flush buffered progress updates before exiting
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer closeCancel()
+	if err := r.Close(closeCtx); err != nil {
+		slog.Warn("Failed to flush progress updates", "error", err)
+	}
+
+	if runErr != nil && runErr != context.Canceled {
+		slog.Error("Router error", "error", runErr)
 		os.Exit(1)
 	}
 
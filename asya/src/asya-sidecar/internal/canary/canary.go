@@ -0,0 +1,120 @@
+// Package canary splits traffic for a logical actor name between weighted actor versions.
+//
+// The version table maps logical actor names used in routes to concrete actors, for example
+// {"classifier": {"versions": [{"actor": "classifier-v1", "weight": 90}, {"actor": "classifier-v2", "weight": 10}]}}.
+// The operator renders it from the spec.logicalActor field of the namespace's AsyncActors into the
+// namespace's asya-actor-versions ConfigMap, which the sidecar mounts and reloads when it changes.
+//
+// The gateway (asya-gateway/internal/canary) carries a copy of this package and must pick the same
+// versions for sticky sets; keep them in sync.
+package canary
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+)
+
+// EnvVersions is the environment variable holding the version table as JSON
+const EnvVersions = "ASYA_ACTOR_VERSIONS"
+
+// Version is a concrete actor receiving a share of a logical actor's envelopes
+type Version struct {
+	Actor  string `json:"actor"`
+	Weight int    `json:"weight"`
+}
+
+// Set is the versions of a logical actor
+type Set struct {
+	Versions []Version `json:"versions"`
+	Sticky   bool      `json:"sticky,omitempty"` // Pick by envelope ID hash instead of at random
+}
+
+// Table maps logical actor names to their versions
+type Table map[string]Set
+
+// Parse parses a JSON version table. An empty string yields an empty table.
+func Parse(raw string) (Table, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var table Table
+	if err := json.Unmarshal([]byte(raw), &table); err != nil {
+		return nil, fmt.Errorf("invalid actor versions: %w", err)
+	}
+
+	logicalNames := make(map[string]string)
+	for name, set := range table {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("logical actor name %q must be a bare actor name", name)
+		}
+		for _, version := range set.Versions {
+			if version.Actor == "" || strings.Contains(version.Actor, "/") {
+				return nil, fmt.Errorf("version %q of logical actor %q must be a bare actor name", version.Actor, name)
+			}
+			if version.Weight < 0 {
+				return nil, fmt.Errorf("version %q of logical actor %q has negative weight %d", version.Actor, name, version.Weight)
+			}
+			if other, ok := logicalNames[version.Actor]; ok {
+				return nil, fmt.Errorf("actor %q is a version of both %q and %q", version.Actor, other, name)
+			}
+			logicalNames[version.Actor] = name
+		}
+		if set.totalWeight() <= 0 {
+			return nil, fmt.Errorf("logical actor %q has no version with a positive weight", name)
+		}
+	}
+	return table, nil
+}
+
+// Resolve returns the version an envelope routed to actor is sent to, or actor itself if it is not
+// a logical actor name. Sticky sets send every delivery of an envelope ID to the same version.
+func (t Table) Resolve(actor, envelopeID string) string {
+	set, ok := t[actor]
+	if !ok {
+		return actor
+	}
+	total := set.totalWeight()
+	if total <= 0 {
+		return actor
+	}
+
+	var n int
+	if set.Sticky {
+		h := fnv.New64a()
+		h.Write([]byte(envelopeID))
+		n = int(h.Sum64() % uint64(total))
+	} else {
+		n = rand.IntN(total)
+	}
+	for _, version := range set.Versions {
+		if n < version.Weight {
+			return version.Actor
+		}
+		n -= version.Weight
+	}
+	return actor
+}
+
+// LogicalName returns the logical actor name that actor is a version of, or "" if it is none
+func (t Table) LogicalName(actor string) string {
+	for name, set := range t {
+		for _, version := range set.Versions {
+			if version.Actor == actor {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func (s Set) totalWeight() int {
+	total := 0
+	for _, version := range s.Versions {
+		total += version.Weight
+	}
+	return total
+}
@@ -0,0 +1,80 @@
+package canary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeVersions(t *testing.T, path, raw string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+// The same cases are tested in the gateway copy of this package
+func TestLoadVersions_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+	now := time.Now()
+
+	// The operator has not created the ConfigMap yet
+	versions, err := LoadVersions(path)
+	if err != nil {
+		t.Fatalf("LoadVersions failed: %v", err)
+	}
+	if got := versions.Resolve("classifier", "env-1"); got != "classifier" {
+		t.Errorf("Resolve before the file exists = %q, want classifier", got)
+	}
+
+	writeVersions(t, path, `{"classifier":{"versions":[{"actor":"classifier-v1","weight":1}]}}`, now)
+	if got := versions.Resolve("classifier", "env-1"); got != "classifier-v1" {
+		t.Errorf("Resolve after the file appears = %q, want classifier-v1", got)
+	}
+
+	// Shifting all weight to v2 takes effect without reloading the sidecar
+	writeVersions(t, path, `{"classifier":{"versions":[{"actor":"classifier-v1","weight":0},{"actor":"classifier-v2","weight":1}]}}`, now.Add(time.Minute))
+	if got := versions.Resolve("classifier", "env-1"); got != "classifier-v2" {
+		t.Errorf("Resolve after the weight shift = %q, want classifier-v2", got)
+	}
+	if got := versions.LogicalName("classifier-v1"); got != "classifier" {
+		t.Errorf("LogicalName = %q, want classifier", got)
+	}
+
+	// An invalid update keeps the previous table
+	writeVersions(t, path, `{`, now.Add(2*time.Minute))
+	if got := versions.Resolve("classifier", "env-1"); got != "classifier-v2" {
+		t.Errorf("Resolve after an invalid update = %q, want classifier-v2", got)
+	}
+}
+
+func TestLoadVersions_Invalid(t *testing.T) {
+	if _, err := LoadVersions(""); err == nil {
+		t.Error("Expected error for an empty path")
+	}
+
+	path := filepath.Join(t.TempDir(), "versions.json")
+	writeVersions(t, path, `{"classifier":{"versions":[]}}`, time.Now())
+	if _, err := LoadVersions(path); err == nil {
+		t.Error("Expected error for an invalid version table")
+	}
+}
+
+func TestVersions_Nil(t *testing.T) {
+	var versions *Versions
+	if got := versions.Resolve("classifier", "env-1"); got != "classifier" {
+		t.Errorf("Resolve = %q, want classifier", got)
+	}
+	if got := versions.LogicalName("classifier-v1"); got != "" {
+		t.Errorf("LogicalName = %q, want empty", got)
+	}
+
+	fixed := NewVersions(Table{"classifier": {Versions: []Version{{Actor: "classifier-v1", Weight: 1}}}})
+	if got := fixed.Resolve("classifier", "env-1"); got != "classifier-v1" {
+		t.Errorf("Resolve = %q, want classifier-v1", got)
+	}
+}
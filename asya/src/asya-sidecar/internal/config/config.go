@@ -3,10 +3,15 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/canary"
 )
 
 type Config struct {
@@ -23,6 +28,40 @@ type Config struct {
 	SQSRegion            string
 	SQSVisibilityTimeout int32 // seconds
 	SQSWaitTimeSeconds   int32
+	SQSMaxMessages       int32  // Messages received per call and processed concurrently (1-10)
+	SQSFIFO              bool   // FIFO queues (names end in ".fifo")
+	SQSMessageGroup      string // Envelope field FIFO messages are grouped by ("headers.<key>" or "payload.<path>")
+
+	// File configuration
+	FileRoot              string
+	FileVisibilityTimeout time.Duration
+	FilePollInterval      time.Duration
+	FileMaxDeliveries     int
+
+	// Postgres configuration
+	PostgresURL               string
+	PostgresVisibilityTimeout time.Duration
+	PostgresPollInterval      time.Duration
+	PostgresMaxDeliveries     int
+	PostgresAutoCreate        bool
+
+	// Redis Streams configuration
+	RedisURL               string
+	RedisGroup             string
+	RedisConsumer          string
+	RedisVisibilityTimeout time.Duration
+	RedisBlockTimeout      time.Duration
+	RedisMaxDeliveries     int
+	RedisAutoCreate        bool
+
+	// Pub/Sub configuration
+	PubSubProjectID         string
+	PubSubEmulatorHost      string
+	PubSubEndpoint          string
+	PubSubClientID          string
+	PubSubAckDeadline       time.Duration
+	PubSubVisibilityTimeout time.Duration
+	PubSubMaxOutstanding    int
 
 	// Runtime communication
 	SocketPath string
@@ -32,6 +71,9 @@ type Config struct {
 	HappyEndQueue string
 	ErrorEndQueue string
 
+	// Route step parked in the gateway until approved or rejected instead of being sent to a queue
+	ApprovalActor string
+
 	// End actor mode
 	// When true, the sidecar will NOT route responses from the runtime.
 	// This is used for end actors (happy-end, error-end) that consume
@@ -42,6 +84,53 @@ type Config struct {
 	GatewayURL string
 	ActorName  string
 	Namespace  string
+	PodName    string // Recorded in envelope hop records
+
+	// Queue naming scheme shared with the gateway and operator
+	QueueNaming naming.Scheme
+
+	// Asynchronous progress reporting (buffered, coalesced per envelope, sent in batches)
+	ProgressAsync         bool
+	ProgressBufferSize    int
+	ProgressBatchSize     int
+	ProgressFlushInterval time.Duration
+
+	// Status queue: publish progress and final status over the transport instead of HTTP
+	StatusQueue string
+
+	// Payload encryption ("" disables, "keyring" or "kms")
+	EncryptionProvider    string
+	EncryptionKeyringPath string
+	EncryptionKMSKeyID    string
+	EncryptionKMSEndpoint string
+	EncryptionDataKeyTTL  time.Duration
+
+	// Envelope signing: keyring file with HMAC keys ("" disables signing and verification)
+	SigningKeyringPath string
+
+	// Route policy applied to routes returned by the runtime (nil disables enforcement)
+	RoutePolicy *RoutePolicyConfig
+
+	// Shared pipelines handlers can call by name instead of listing their actors
+	NamedRoutes map[string][]string
+
+	// Retry policy applied to runtime errors before sending them to error-end (nil disables retries)
+	RetryPolicy *RetryPolicyConfig
+
+	// Namespaces other than Namespace that routes may send to via "namespace/actor" references,
+	// rendered by the operator from target namespaces that opt in
+	AllowedTargetNamespaces []string
+
+	// Maximum number of actors that may process an envelope, unless the envelope sets max_hops (0 disables)
+	MaxHops int
+
+	// Weighted versions of logical actor names used in routes, read from the operator's actor versions
+	// ConfigMap (nil disables)
+	ActorVersions *canary.Versions
+
+	// Shadow traffic: percentage of envelopes mirrored to ShadowActor after the runtime call ("" disables)
+	ShadowActor   string
+	ShadowPercent int
 
 	// Metrics configuration
 	MetricsEnabled   bool
@@ -50,6 +139,89 @@ type Config struct {
 	CustomMetrics    []CustomMetricConfig
 }
 
+// RoutePolicyConfig restricts how an actor's handler may rewrite the route.
+// Empty fields impose no restriction.
+type RoutePolicyConfig struct {
+	AllowedNext         []string `json:"allowedNext,omitempty"`         // actors the envelope may be routed to next
+	MaxRouteLength      int      `json:"maxRouteLength,omitempty"`      // upper bound on len(route.actors)
+	ForbiddenInsertions []string `json:"forbiddenInsertions,omitempty"` // actors that may not be added to the route ("*" forbids any insertion)
+}
+
+// RetryPolicyConfig retries runtime errors by exception type (details.type of the error response)
+// with exponential backoff. Unset fields take their defaults when loaded.
+type RetryPolicyConfig struct {
+	MaxAttempts           int      `json:"maxAttempts,omitempty"`           // runtime attempts per envelope, including the first
+	RetryOn               []string `json:"retryOn,omitempty"`               // types to retry (empty retries every type not in Permanent)
+	Permanent             []string `json:"permanent,omitempty"`             // types sent to error-end without retrying
+	InitialBackoffSeconds int      `json:"initialBackoffSeconds,omitempty"` // delay before the first retry
+	MaxBackoffSeconds     int      `json:"maxBackoffSeconds,omitempty"`     // upper bound on the delay between retries
+	BackoffMultiplier     int      `json:"backoffMultiplier,omitempty"`     // factor the delay grows by after each retry
+}
+
+// Retries reports whether errors of errorType are retried
+func (p *RetryPolicyConfig) Retries(errorType string) bool {
+	if slices.Contains(p.Permanent, errorType) {
+		return false
+	}
+	return len(p.RetryOn) == 0 || slices.Contains(p.RetryOn, errorType)
+}
+
+// Backoff returns the delay before retrying an envelope whose attempt failed (1 for the first attempt)
+func (p *RetryPolicyConfig) Backoff(attempt int) time.Duration {
+	delay := time.Duration(p.InitialBackoffSeconds) * time.Second
+	maxDelay := time.Duration(p.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= time.Duration(p.BackoffMultiplier)
+	}
+	return min(delay, maxDelay)
+}
+
+// MaxDelay returns the longest delay before a retry, or 0 if envelopes are never retried
+func (p *RetryPolicyConfig) MaxDelay() time.Duration {
+	if p.MaxAttempts < 2 {
+		return 0
+	}
+	return p.Backoff(p.MaxAttempts - 1)
+}
+
+// maxHeldRetryDelay bounds retry delays on transports without delayed send, where the sidecar holds the
+// received message and blocks one of its workers for the whole delay
+const maxHeldRetryDelay = time.Minute
+
+// holdsRetries reports whether the transport has no delayed send, so the sidecar waits out retry delays
+// itself. SQS FIFO queues have no per-message delay.
+func (c *Config) holdsRetries() bool {
+	switch c.TransportType {
+	case "file", "redis", "pubsub":
+		return true
+	case "sqs":
+		return c.SQSFIFO
+	}
+	return false
+}
+
+// retryLease returns how long a received message stays leased on transports that hold it in the sidecar
+// during a retry delay (no delayed send) without extending the lease, or 0 if no lease bounds the delay.
+// An unset visibility timeout defaults to twice the runtime timeout, as in cmd/sidecar.
+func (c *Config) retryLease() (string, time.Duration) {
+	var env string
+	var lease time.Duration
+	switch c.TransportType {
+	case "file":
+		env, lease = "ASYA_FILE_VISIBILITY_TIMEOUT", c.FileVisibilityTimeout
+	case "redis":
+		env, lease = "ASYA_REDIS_VISIBILITY_TIMEOUT", c.RedisVisibilityTimeout
+	case "pubsub":
+		env, lease = "ASYA_PUBSUB_VISIBILITY_TIMEOUT", c.PubSubVisibilityTimeout
+	default:
+		return "", 0
+	}
+	if lease == 0 {
+		lease = c.Timeout * 2
+	}
+	return env, lease
+}
+
 // CustomMetricConfig defines configuration for a custom metric
 type CustomMetricConfig struct {
 	Name    string    `json:"name"`
@@ -74,6 +246,40 @@ func LoadFromEnv() (*Config, error) {
 		SQSRegion:            getEnv("ASYA_AWS_REGION", "us-east-1"),
 		SQSVisibilityTimeout: getEnvInt32("ASYA_SQS_VISIBILITY_TIMEOUT", 0),
 		SQSWaitTimeSeconds:   getEnvInt32("ASYA_SQS_WAIT_TIME_SECONDS", 20),
+		SQSMaxMessages:       getEnvInt32("ASYA_SQS_MAX_MESSAGES", 1),
+		SQSFIFO:              getEnvBool("ASYA_SQS_FIFO", false),
+		SQSMessageGroup:      getEnv("ASYA_SQS_MESSAGE_GROUP", ""),
+
+		// File configuration
+		FileRoot:              getEnv("ASYA_FILE_ROOT", "/var/lib/asya/queues"),
+		FileVisibilityTimeout: getEnvDuration("ASYA_FILE_VISIBILITY_TIMEOUT", 0),
+		FilePollInterval:      getEnvDuration("ASYA_FILE_POLL_INTERVAL", 200*time.Millisecond),
+		FileMaxDeliveries:     getEnvInt("ASYA_FILE_MAX_DELIVERIES", 3),
+
+		// Postgres configuration
+		PostgresURL:               buildPostgresURL(),
+		PostgresVisibilityTimeout: getEnvDuration("ASYA_POSTGRES_VISIBILITY_TIMEOUT", 0),
+		PostgresPollInterval:      getEnvDuration("ASYA_POSTGRES_POLL_INTERVAL", 500*time.Millisecond),
+		PostgresMaxDeliveries:     getEnvInt("ASYA_POSTGRES_MAX_DELIVERIES", 3),
+		PostgresAutoCreate:        getEnvBool("ASYA_QUEUE_AUTO_CREATE", false),
+
+		// Redis Streams configuration
+		RedisURL:               buildRedisURL(),
+		RedisGroup:             getEnv("ASYA_REDIS_GROUP", "asya"),
+		RedisConsumer:          getEnv("ASYA_REDIS_CONSUMER", defaultHostname()),
+		RedisVisibilityTimeout: getEnvDuration("ASYA_REDIS_VISIBILITY_TIMEOUT", 0),
+		RedisBlockTimeout:      getEnvDuration("ASYA_REDIS_BLOCK_TIMEOUT", time.Second),
+		RedisMaxDeliveries:     getEnvInt("ASYA_REDIS_MAX_DELIVERIES", 3),
+		RedisAutoCreate:        getEnvBool("ASYA_QUEUE_AUTO_CREATE", false),
+
+		// Pub/Sub configuration
+		PubSubProjectID:         getEnv("ASYA_PUBSUB_PROJECT_ID", ""),
+		PubSubEmulatorHost:      getEnv("ASYA_PUBSUB_EMULATOR_HOST", os.Getenv("PUBSUB_EMULATOR_HOST")),
+		PubSubEndpoint:          getEnv("ASYA_PUBSUB_ENDPOINT", ""),
+		PubSubClientID:          getEnv("ASYA_PUBSUB_CLIENT_ID", defaultHostname()),
+		PubSubAckDeadline:       getEnvDuration("ASYA_PUBSUB_ACK_DEADLINE", 60*time.Second),
+		PubSubVisibilityTimeout: getEnvDuration("ASYA_PUBSUB_VISIBILITY_TIMEOUT", 0),
+		PubSubMaxOutstanding:    getEnvInt("ASYA_PUBSUB_MAX_OUTSTANDING", 1),
 
 		// Runtime communication - hard-coded, managed by operator
 		// ASYA_SOCKET_DIR is for internal testing only - DO NOT set in production
@@ -84,11 +290,35 @@ func LoadFromEnv() (*Config, error) {
 		HappyEndQueue: getEnv("ASYA_ACTOR_HAPPY_END", "happy-end"),
 		ErrorEndQueue: getEnv("ASYA_ACTOR_ERROR_END", "error-end"),
 		IsEndActor:    getEnvBool("ASYA_IS_END_ACTOR", false),
+		ApprovalActor: getEnv("ASYA_ACTOR_APPROVAL", ""), // Opt-in, so existing actors named like the step keep their queue
 
 		// Progress reporting
 		GatewayURL: getEnv("ASYA_GATEWAY_URL", ""),
 		ActorName:  getEnv("ASYA_ACTOR_NAME", ""),
 		Namespace:  getEnv("ASYA_NAMESPACE", ""),
+		PodName:    defaultHostname(),
+
+		ProgressAsync:         getEnvBool("ASYA_PROGRESS_ASYNC", true),
+		ProgressBufferSize:    getEnvInt("ASYA_PROGRESS_BUFFER_SIZE", 1024),
+		ProgressBatchSize:     getEnvInt("ASYA_PROGRESS_BATCH_SIZE", 100),
+		ProgressFlushInterval: getEnvDuration("ASYA_PROGRESS_FLUSH_INTERVAL", 250*time.Millisecond),
+
+		StatusQueue: getEnv("ASYA_STATUS_QUEUE", ""),
+
+		EncryptionProvider:    getEnv("ASYA_ENCRYPTION_PROVIDER", ""),
+		EncryptionKeyringPath: getEnv("ASYA_ENCRYPTION_KEYRING_PATH", "/etc/asya/keyring/keyring.json"),
+		EncryptionKMSKeyID:    getEnv("ASYA_ENCRYPTION_KMS_KEY_ID", ""),
+		EncryptionKMSEndpoint: getEnv("ASYA_ENCRYPTION_KMS_ENDPOINT", ""),
+		EncryptionDataKeyTTL:  getEnvDuration("ASYA_ENCRYPTION_DATA_KEY_TTL", time.Hour),
+
+		SigningKeyringPath: getEnv("ASYA_SIGNING_KEYRING_PATH", ""),
+
+		AllowedTargetNamespaces: getEnvList("ASYA_ALLOWED_TARGET_NAMESPACES"),
+
+		MaxHops: getEnvInt("ASYA_MAX_HOPS", 100),
+
+		ShadowActor:   getEnv("ASYA_SHADOW_ACTOR", ""),
+		ShadowPercent: getEnvInt("ASYA_SHADOW_PERCENT", 100),
 
 		// Metrics defaults
 		MetricsEnabled:   getEnvBool("ASYA_METRICS_ENABLED", true),
@@ -100,6 +330,16 @@ func LoadFromEnv() (*Config, error) {
 	socketDir := getEnv("ASYA_SOCKET_DIR", "/var/run/asya")
 	cfg.SocketPath = socketDir + "/asya-runtime.sock"
 
+	queueNaming, err := naming.New(
+		getEnv(naming.EnvPrefix, naming.DefaultPrefix),
+		getEnv(naming.EnvCluster, ""),
+		getEnv(naming.EnvSuffix, ""),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cfg.QueueNaming = queueNaming
+
 	// Load custom metrics configuration
 	if customMetricsJSON := getEnv("ASYA_CUSTOM_METRICS", ""); customMetricsJSON != "" {
 		var customMetrics []CustomMetricConfig
@@ -109,6 +349,56 @@ func LoadFromEnv() (*Config, error) {
 		cfg.CustomMetrics = customMetrics
 	}
 
+	// Load logical actor versions; a fixed table takes precedence over the mounted ConfigMap
+	if raw := getEnv(canary.EnvVersions, ""); raw != "" {
+		actorVersions, err := canary.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", canary.EnvVersions, err)
+		}
+		cfg.ActorVersions = canary.NewVersions(actorVersions)
+	} else if path := getEnv(canary.EnvVersionsPath, ""); path != "" {
+		actorVersions, err := canary.LoadVersions(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", canary.EnvVersionsPath, err)
+		}
+		cfg.ActorVersions = actorVersions
+	}
+
+	// Load route policy
+	if routePolicyJSON := getEnv("ASYA_ROUTE_POLICY", ""); routePolicyJSON != "" {
+		var routePolicy RoutePolicyConfig
+		if err := json.Unmarshal([]byte(routePolicyJSON), &routePolicy); err != nil {
+			return nil, fmt.Errorf("failed to parse ASYA_ROUTE_POLICY: %w", err)
+		}
+		if routePolicy.MaxRouteLength < 0 {
+			return nil, fmt.Errorf("ASYA_ROUTE_POLICY maxRouteLength must not be negative")
+		}
+		cfg.RoutePolicy = &routePolicy
+	}
+
+	// Load named routes
+	if namedRoutesJSON := getEnv("ASYA_NAMED_ROUTES", ""); namedRoutesJSON != "" {
+		var namedRoutes map[string][]string
+		if err := json.Unmarshal([]byte(namedRoutesJSON), &namedRoutes); err != nil {
+			return nil, fmt.Errorf("failed to parse ASYA_NAMED_ROUTES: %w", err)
+		}
+		for name, actors := range namedRoutes {
+			if name == "" || len(actors) == 0 || slices.Contains(actors, "") {
+				return nil, fmt.Errorf("ASYA_NAMED_ROUTES route %q must be a non-empty name with a non-empty list of actors", name)
+			}
+		}
+		cfg.NamedRoutes = namedRoutes
+	}
+
+	// Load retry policy
+	if retryPolicyJSON := getEnv("ASYA_RETRY_POLICY", ""); retryPolicyJSON != "" {
+		retryPolicy, err := parseRetryPolicy(retryPolicyJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ASYA_RETRY_POLICY: %w", err)
+		}
+		cfg.RetryPolicy = retryPolicy
+	}
+
 	// Validate
 	if cfg.ActorName == "" {
 		return nil, fmt.Errorf("ASYA_ACTOR_NAME is required")
@@ -116,10 +406,68 @@ func LoadFromEnv() (*Config, error) {
 	if cfg.Namespace == "" {
 		return nil, fmt.Errorf("ASYA_NAMESPACE is required")
 	}
+	queueTransport := cfg.TransportType
+	if queueTransport == "sqs" && cfg.SQSFIFO {
+		queueTransport = naming.TransportSQSFIFO
+	}
+	if err := naming.ValidateQueueName(queueTransport, cfg.QueueNaming.QueueName(cfg.Namespace, cfg.ActorName)); err != nil {
+		return nil, err
+	}
+	if cfg.SQSMaxMessages < 1 || cfg.SQSMaxMessages > 10 {
+		return nil, fmt.Errorf("ASYA_SQS_MAX_MESSAGES must be between 1 and 10, got %d", cfg.SQSMaxMessages)
+	}
+	// Concurrent workers would process messages of one FIFO group out of order
+	if cfg.SQSFIFO && cfg.SQSMaxMessages > 1 {
+		return nil, fmt.Errorf("ASYA_SQS_MAX_MESSAGES must be 1 with ASYA_SQS_FIFO, got %d", cfg.SQSMaxMessages)
+	}
+	if cfg.RetryPolicy != nil && cfg.holdsRetries() && cfg.RetryPolicy.MaxDelay() > maxHeldRetryDelay {
+		return nil, fmt.Errorf("ASYA_RETRY_POLICY delays up to %s exceed %s; the %s transport has no delayed send, "+
+			"so the sidecar holds the message while waiting to retry it", cfg.RetryPolicy.MaxDelay(), maxHeldRetryDelay, cfg.TransportType)
+	}
+	// The message is redelivered if the runtime call plus the retry delay outlive its lease
+	if env, lease := cfg.retryLease(); cfg.RetryPolicy != nil && lease > 0 {
+		if held := cfg.Timeout + cfg.RetryPolicy.MaxDelay(); held > lease {
+			return nil, fmt.Errorf("ASYA_RETRY_POLICY delays up to %s plus ASYA_RUNTIME_TIMEOUT %s exceed %s %s; "+
+				"the %s transport holds the message while waiting to retry it", cfg.RetryPolicy.MaxDelay(), cfg.Timeout, env, lease, cfg.TransportType)
+		}
+	}
+	// The transport cannot read encrypted payloads, so every envelope would be grouped by its ID
+	if cfg.SQSFIFO && strings.HasPrefix(cfg.SQSMessageGroup, "payload.") && cfg.EncryptionProvider != "" {
+		return nil, fmt.Errorf("ASYA_SQS_MESSAGE_GROUP %q cannot be read from encrypted payloads, use a headers.<key> field with ASYA_ENCRYPTION_PROVIDER", cfg.SQSMessageGroup)
+	}
+	if cfg.ShadowActor != "" && (cfg.ShadowPercent < 0 || cfg.ShadowPercent > 100) {
+		return nil, fmt.Errorf("ASYA_SHADOW_PERCENT must be between 0 and 100, got %d", cfg.ShadowPercent)
+	}
+	if cfg.EncryptionProvider == "kms" && cfg.EncryptionKMSKeyID == "" {
+		return nil, fmt.Errorf("ASYA_ENCRYPTION_KMS_KEY_ID is required for the kms encryption provider")
+	}
 
 	return cfg, nil
 }
 
+// parseRetryPolicy parses a retry policy, applying the defaults of the AsyncActor's spec.retryPolicy
+func parseRetryPolicy(raw string) (*RetryPolicyConfig, error) {
+	policy := RetryPolicyConfig{
+		MaxAttempts:           3,
+		InitialBackoffSeconds: 1,
+		MaxBackoffSeconds:     300,
+		BackoffMultiplier:     2,
+	}
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		return nil, err
+	}
+	if policy.MaxAttempts < 1 {
+		return nil, fmt.Errorf("maxAttempts must be at least 1")
+	}
+	if policy.InitialBackoffSeconds < 1 || policy.MaxBackoffSeconds < 1 {
+		return nil, fmt.Errorf("initialBackoffSeconds and maxBackoffSeconds must be at least 1")
+	}
+	if policy.BackoffMultiplier < 1 {
+		return nil, fmt.Errorf("backoffMultiplier must be at least 1")
+	}
+	return &policy, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -154,6 +502,17 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvList parses a comma-separated list, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		switch strings.ToLower(value) {
@@ -178,3 +537,63 @@ func buildRabbitMQURL() string {
 
 	return fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
 }
+
+func buildPostgresURL() string {
+	if url := os.Getenv("ASYA_POSTGRES_URL"); url != "" {
+		return url
+	}
+
+	host := getEnv("ASYA_POSTGRES_HOST", "localhost")
+	port := getEnv("ASYA_POSTGRES_PORT", "5432")
+	database := getEnv("ASYA_POSTGRES_DATABASE", "asya")
+	username := getEnv("ASYA_POSTGRES_USERNAME", "postgres")
+	password := getEnv("ASYA_POSTGRES_PASSWORD", "")
+	sslMode := getEnv("ASYA_POSTGRES_SSLMODE", "disable")
+
+	u := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(username, password),
+		Host:     host + ":" + port,
+		Path:     "/" + database,
+		RawQuery: "sslmode=" + url.QueryEscape(sslMode),
+	}
+	return u.String()
+}
+
+func buildRedisURL() string {
+	if url := os.Getenv("ASYA_REDIS_URL"); url != "" {
+		return url
+	}
+
+	host := getEnv("ASYA_REDIS_HOST", "localhost")
+	port := getEnv("ASYA_REDIS_PORT", "6379")
+	db := getEnv("ASYA_REDIS_DB", "0")
+	username := getEnv("ASYA_REDIS_USERNAME", "")
+	password := getEnv("ASYA_REDIS_PASSWORD", "")
+
+	scheme := "redis"
+	if getEnvBool("ASYA_REDIS_TLS", false) {
+		scheme = "rediss"
+	}
+
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   host + ":" + port,
+		Path:   "/" + db,
+	}
+	if password != "" {
+		u.User = url.UserPassword(username, password)
+	} else if username != "" {
+		u.User = url.User(username)
+	}
+	return u.String()
+}
+
+// defaultHostname returns the pod name, used as a unique consumer name
+func defaultHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "asya-sidecar"
+	}
+	return hostname
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,6 +71,12 @@ func TestLoadFromEnv(t *testing.T) {
 				if cfg.ErrorEndQueue != "error-end" {
 					t.Errorf("Default ErrorEndQueue = %v, want error-end", cfg.ErrorEndQueue)
 				}
+				if cfg.ApprovalActor != "" {
+					t.Errorf("Default ApprovalActor = %v, want disabled", cfg.ApprovalActor)
+				}
+				if cfg.MaxHops != 100 {
+					t.Errorf("Default MaxHops = %v, want 100", cfg.MaxHops)
+				}
 			},
 		},
 		{
@@ -96,6 +104,411 @@ func TestLoadFromEnv(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "route policy configuration",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_ROUTE_POLICY": `{"allowedNext":["scorer"],"maxRouteLength":5,"forbiddenInsertions":["*"]}`,
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if cfg.RoutePolicy == nil {
+					t.Fatal("RoutePolicy should be set")
+				}
+				if len(cfg.RoutePolicy.AllowedNext) != 1 || cfg.RoutePolicy.AllowedNext[0] != "scorer" {
+					t.Errorf("RoutePolicy.AllowedNext = %v, want [scorer]", cfg.RoutePolicy.AllowedNext)
+				}
+				if cfg.RoutePolicy.MaxRouteLength != 5 {
+					t.Errorf("RoutePolicy.MaxRouteLength = %v, want 5", cfg.RoutePolicy.MaxRouteLength)
+				}
+			},
+		},
+		{
+			name: "invalid route policy JSON",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_ROUTE_POLICY": `{"maxRouteLength":"five"}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "retry policy configuration",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":5,"retryOn":["TimeoutError"],"permanent":["ValueError"],"initialBackoffSeconds":2}`,
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				policy := cfg.RetryPolicy
+				if policy == nil {
+					t.Fatal("RetryPolicy should be set")
+				}
+				if policy.MaxAttempts != 5 || policy.InitialBackoffSeconds != 2 {
+					t.Errorf("RetryPolicy = %+v, want maxAttempts 5 and initialBackoffSeconds 2", policy)
+				}
+				if policy.MaxBackoffSeconds != 300 || policy.BackoffMultiplier != 2 {
+					t.Errorf("RetryPolicy = %+v, want default maxBackoffSeconds 300 and backoffMultiplier 2", policy)
+				}
+				if !policy.Retries("TimeoutError") || policy.Retries("ValueError") || policy.Retries("KeyError") {
+					t.Errorf("RetryPolicy should retry only TimeoutError")
+				}
+			},
+		},
+		{
+			name: "retry policy defaults",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_RETRY_POLICY": `{"permanent":["ValueError"]}`,
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				policy := cfg.RetryPolicy
+				if policy == nil {
+					t.Fatal("RetryPolicy should be set")
+				}
+				if policy.MaxAttempts != 3 || policy.InitialBackoffSeconds != 1 {
+					t.Errorf("RetryPolicy = %+v, want default maxAttempts 3 and initialBackoffSeconds 1", policy)
+				}
+				if !policy.Retries("KeyError") || policy.Retries("ValueError") {
+					t.Errorf("RetryPolicy without retryOn should retry every type except permanent ones")
+				}
+			},
+		},
+		{
+			name: "invalid retry policy",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":-1}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "retry delay within file visibility timeout",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":      "test-actor",
+				"ASYA_NAMESPACE":       "default",
+				"ASYA_TRANSPORT":       "file",
+				"ASYA_RUNTIME_TIMEOUT": "1m",
+				"ASYA_RETRY_POLICY":    `{"maxAttempts":3,"initialBackoffSeconds":30}`, // waits up to 60s, lease defaults to 2m
+			},
+			expectError: false,
+		},
+		{
+			name: "retry delay exceeds file visibility timeout",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":      "test-actor",
+				"ASYA_NAMESPACE":       "default",
+				"ASYA_TRANSPORT":       "file",
+				"ASYA_RUNTIME_TIMEOUT": "1m",
+				"ASYA_RETRY_POLICY":    `{"maxAttempts":4,"initialBackoffSeconds":30}`, // waits up to 120s
+			},
+			expectError: true,
+		},
+		{
+			name: "retry delay exceeds redis visibility timeout",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":               "test-actor",
+				"ASYA_NAMESPACE":                "default",
+				"ASYA_TRANSPORT":                "redis",
+				"ASYA_REDIS_VISIBILITY_TIMEOUT": "10m",
+				"ASYA_RETRY_POLICY":             `{"maxAttempts":10,"initialBackoffSeconds":60,"maxBackoffSeconds":600}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "retry delay exceeds held retry bound",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":                "test-actor",
+				"ASYA_NAMESPACE":                 "default",
+				"ASYA_TRANSPORT":                 "pubsub",
+				"ASYA_RUNTIME_TIMEOUT":           "1m",
+				"ASYA_PUBSUB_VISIBILITY_TIMEOUT": "1h",
+				"ASYA_RETRY_POLICY":              `{"maxAttempts":3,"initialBackoffSeconds":60}`, // waits up to 120s
+			},
+			expectError: true,
+		},
+		{
+			name: "retry delay exceeds held retry bound with SQS FIFO",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_TRANSPORT":    "sqs",
+				"ASYA_SQS_FIFO":     "true",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":3,"initialBackoffSeconds":60}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "long retry delay with SQS delayed send",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_TRANSPORT":    "sqs",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":3,"initialBackoffSeconds":60}`,
+			},
+			expectError: false,
+		},
+		{
+			name: "long retry delay with rabbitmq delayed send",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_TRANSPORT":    "rabbitmq",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":10,"initialBackoffSeconds":60,"maxBackoffSeconds":3600}`,
+			},
+			expectError: false,
+		},
+		{
+			name: "long retry delay with postgres delayed send",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_TRANSPORT":    "postgres",
+				"ASYA_RETRY_POLICY": `{"maxAttempts":10,"initialBackoffSeconds":60,"maxBackoffSeconds":3600}`,
+			},
+			expectError: false,
+		},
+		{
+			name: "allowed target namespaces",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":                "test-actor",
+				"ASYA_NAMESPACE":                 "default",
+				"ASYA_ALLOWED_TARGET_NAMESPACES": "team-b, team-c,,",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if len(cfg.AllowedTargetNamespaces) != 2 || cfg.AllowedTargetNamespaces[0] != "team-b" || cfg.AllowedTargetNamespaces[1] != "team-c" {
+					t.Errorf("AllowedTargetNamespaces = %v, want [team-b team-c]", cfg.AllowedTargetNamespaces)
+				}
+			},
+		},
+		{
+			name: "queue naming scheme",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_QUEUE_PREFIX": "acme",
+				"ASYA_CLUSTER_NAME": "eu1",
+				"ASYA_QUEUE_SUFFIX": "q",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if got := cfg.QueueNaming.QueueName(cfg.Namespace, cfg.ActorName); got != "acme-eu1-default-test-actor-q" {
+					t.Errorf("QueueName = %v, want acme-eu1-default-test-actor-q", got)
+				}
+			},
+		},
+		{
+			name: "invalid queue prefix",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_QUEUE_PREFIX": "acme.corp",
+			},
+			expectError: true,
+		},
+		{
+			name: "queue name exceeds SQS limit",
+			env: map[string]string{
+				"ASYA_TRANSPORT":    "sqs",
+				"ASYA_ACTOR_NAME":   "an-actor-with-a-rather-long-descriptive-name",
+				"ASYA_NAMESPACE":    "a-namespace-with-a-long-name",
+				"ASYA_CLUSTER_NAME": "eu-central-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "FIFO queue name exceeds SQS limit",
+			env: map[string]string{
+				"ASYA_TRANSPORT":  "sqs",
+				"ASYA_SQS_FIFO":   "true",
+				"ASYA_ACTOR_NAME": strings.Repeat("a", 63), // asya-default-{actor}.fifo is 81 characters
+				"ASYA_NAMESPACE":  "default",
+			},
+			expectError: true,
+		},
+		{
+			name: "SQS FIFO configuration",
+			env: map[string]string{
+				"ASYA_TRANSPORT":         "sqs",
+				"ASYA_SQS_FIFO":          "true",
+				"ASYA_SQS_MESSAGE_GROUP": "headers.customer_id",
+				"ASYA_ACTOR_NAME":        strings.Repeat("a", 62),
+				"ASYA_NAMESPACE":         "default",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if !cfg.SQSFIFO || cfg.SQSMessageGroup != "headers.customer_id" {
+					t.Errorf("SQSFIFO = %v, SQSMessageGroup = %q, want FIFO grouped by headers.customer_id", cfg.SQSFIFO, cfg.SQSMessageGroup)
+				}
+			},
+		},
+		{
+			name: "SQS max messages",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":       "test-actor",
+				"ASYA_NAMESPACE":        "default",
+				"ASYA_TRANSPORT":        "sqs",
+				"ASYA_SQS_MAX_MESSAGES": "10",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if cfg.SQSMaxMessages != 10 {
+					t.Errorf("SQSMaxMessages = %d, want 10", cfg.SQSMaxMessages)
+				}
+			},
+		},
+		{
+			name: "SQS max messages above the SQS limit",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":       "test-actor",
+				"ASYA_NAMESPACE":        "default",
+				"ASYA_TRANSPORT":        "sqs",
+				"ASYA_SQS_MAX_MESSAGES": "11",
+			},
+			expectError: true,
+		},
+		{
+			name: "SQS max messages with FIFO",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":       "test-actor",
+				"ASYA_NAMESPACE":        "default",
+				"ASYA_TRANSPORT":        "sqs",
+				"ASYA_SQS_FIFO":         "true",
+				"ASYA_SQS_MAX_MESSAGES": "2",
+			},
+			expectError: true,
+		},
+		{
+			name: "SQS FIFO payload message group with encryption",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":          "test-actor",
+				"ASYA_NAMESPACE":           "default",
+				"ASYA_TRANSPORT":           "sqs",
+				"ASYA_SQS_FIFO":            "true",
+				"ASYA_SQS_MESSAGE_GROUP":   "payload.customer.id",
+				"ASYA_ENCRYPTION_PROVIDER": "keyring",
+			},
+			expectError: true,
+		},
+		{
+			name: "SQS FIFO header message group with encryption",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":          "test-actor",
+				"ASYA_NAMESPACE":           "default",
+				"ASYA_TRANSPORT":           "sqs",
+				"ASYA_SQS_FIFO":            "true",
+				"ASYA_SQS_MESSAGE_GROUP":   "headers.customer_id",
+				"ASYA_ENCRYPTION_PROVIDER": "keyring",
+			},
+			expectError: false,
+		},
+		{
+			name: "actor versions",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":     "test-actor",
+				"ASYA_NAMESPACE":      "default",
+				"ASYA_ACTOR_VERSIONS": `{"classifier":{"versions":[{"actor":"classifier-v1","weight":90},{"actor":"classifier-v2","weight":10}],"sticky":true}}`,
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				set, ok := cfg.ActorVersions.Table()["classifier"]
+				if !ok || len(set.Versions) != 2 || !set.Sticky {
+					t.Errorf("ActorVersions = %+v, want two sticky classifier versions", cfg.ActorVersions)
+				}
+			},
+		},
+		{
+			name: "invalid actor versions",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":     "test-actor",
+				"ASYA_NAMESPACE":      "default",
+				"ASYA_ACTOR_VERSIONS": `{"classifier":{"versions":[{"actor":"classifier-v1","weight":0}]}}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "actor versions path before the ConfigMap exists",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":          "test-actor",
+				"ASYA_NAMESPACE":           "default",
+				"ASYA_ACTOR_VERSIONS_PATH": "/nonexistent/versions.json",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if cfg.ActorVersions == nil || len(cfg.ActorVersions.Table()) != 0 {
+					t.Errorf("ActorVersions = %+v, want an empty reloadable table", cfg.ActorVersions)
+				}
+			},
+		},
+		{
+			name: "actor versions env takes precedence over the path",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":          "test-actor",
+				"ASYA_NAMESPACE":           "default",
+				"ASYA_ACTOR_VERSIONS":      `{"classifier":{"versions":[{"actor":"classifier-v1","weight":1}]}}`,
+				"ASYA_ACTOR_VERSIONS_PATH": "/nonexistent/versions.json",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if got := cfg.ActorVersions.Resolve("classifier", "env-1"); got != "classifier-v1" {
+					t.Errorf("Resolve = %q, want classifier-v1", got)
+				}
+			},
+		},
+		{
+			name: "named routes",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_NAMED_ROUTES": `{"document":["ocr","translate"]}`,
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if !reflect.DeepEqual(cfg.NamedRoutes, map[string][]string{"document": {"ocr", "translate"}}) {
+					t.Errorf("NamedRoutes = %v, want document: [ocr translate]", cfg.NamedRoutes)
+				}
+			},
+		},
+		{
+			name: "named route without actors",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":   "test-actor",
+				"ASYA_NAMESPACE":    "default",
+				"ASYA_NAMED_ROUTES": `{"document":[]}`,
+			},
+			expectError: true,
+		},
+		{
+			name: "shadow traffic",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":     "test-actor",
+				"ASYA_NAMESPACE":      "default",
+				"ASYA_SHADOW_ACTOR":   "test-actor-v2",
+				"ASYA_SHADOW_PERCENT": "10",
+			},
+			expectError: false,
+			validate: func(t *testing.T, cfg *Config) {
+				if cfg.ShadowActor != "test-actor-v2" || cfg.ShadowPercent != 10 {
+					t.Errorf("Shadow = %s %d%%, want test-actor-v2 10%%", cfg.ShadowActor, cfg.ShadowPercent)
+				}
+			},
+		},
+		{
+			name: "shadow percent out of range",
+			env: map[string]string{
+				"ASYA_ACTOR_NAME":     "test-actor",
+				"ASYA_NAMESPACE":      "default",
+				"ASYA_SHADOW_ACTOR":   "test-actor-v2",
+				"ASYA_SHADOW_PERCENT": "150",
+			},
+			expectError: true,
+		},
 		{
 			name: "end actor configuration",
 			env: map[string]string{
@@ -166,6 +579,7 @@ func TestLoadFromEnv(t *testing.T) {
 				"ASYA_SOCKET_DIR":      "/custom/path",
 				"ASYA_ACTOR_HAPPY_END": "custom-happy",
 				"ASYA_ACTOR_ERROR_END": "custom-error",
+				"ASYA_ACTOR_APPROVAL":  "human-review",
 			},
 			expectError: false,
 			validate: func(t *testing.T, cfg *Config) {
@@ -178,6 +592,9 @@ func TestLoadFromEnv(t *testing.T) {
 				if cfg.ErrorEndQueue != "custom-error" {
 					t.Errorf("ErrorEndQueue = %v, want custom-error", cfg.ErrorEndQueue)
 				}
+				if cfg.ApprovalActor != "human-review" {
+					t.Errorf("ApprovalActor = %v, want human-review", cfg.ApprovalActor)
+				}
 			},
 		},
 		{
@@ -269,3 +686,41 @@ func splitEnv(s string) [2]string {
 	}
 	return [2]string{s, ""}
 }
+
+func TestRetryPolicyConfig_MaxDelay(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicyConfig
+		want   time.Duration
+	}{
+		{RetryPolicyConfig{MaxAttempts: 1, InitialBackoffSeconds: 5, MaxBackoffSeconds: 60, BackoffMultiplier: 2}, 0},
+		{RetryPolicyConfig{MaxAttempts: 2, InitialBackoffSeconds: 5, MaxBackoffSeconds: 60, BackoffMultiplier: 2}, 5 * time.Second},
+		{RetryPolicyConfig{MaxAttempts: 4, InitialBackoffSeconds: 5, MaxBackoffSeconds: 60, BackoffMultiplier: 2}, 20 * time.Second},
+		{RetryPolicyConfig{MaxAttempts: 50, InitialBackoffSeconds: 5, MaxBackoffSeconds: 60, BackoffMultiplier: 2}, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.MaxDelay(); got != tt.want {
+			t.Errorf("MaxDelay(%+v) = %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyConfig_Backoff(t *testing.T) {
+	policy := RetryPolicyConfig{InitialBackoffSeconds: 1, MaxBackoffSeconds: 10, BackoffMultiplier: 3}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 1 * time.Second},
+		{attempt: 2, want: 3 * time.Second},
+		{attempt: 3, want: 9 * time.Second},
+		{attempt: 4, want: 10 * time.Second},
+		{attempt: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, KeySize))
+}
+
+func writeKeyring(t *testing.T, path, active string, keys map[string]string) {
+	t.Helper()
+	data, _ := json.Marshal(keyringFile{Active: active, Keys: keys})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+}
+
+func newTestEncryptor(t *testing.T) (*Encryptor, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	writeKeyring(t, path, "k1", map[string]string{"k1": testKey(1)})
+	provider, err := NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return NewEncryptor(provider), path
+}
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	enc, _ := newTestEncryptor(t)
+	ctx := context.Background()
+	payload := json.RawMessage(`{"ssn":"123-45-6789"}`)
+
+	token, keyID, err := enc.EncryptPayload(ctx, payload)
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	if keyID != "k1" {
+		t.Errorf("keyID = %q, want k1", keyID)
+	}
+	if strings.Contains(string(token), "123-45-6789") {
+		t.Error("Encrypted payload contains plaintext")
+	}
+	if !IsEncrypted(token) || IsEncrypted(payload) {
+		t.Error("IsEncrypted should only match encrypted tokens")
+	}
+
+	plaintext, err := enc.DecryptPayload(ctx, token)
+	if err != nil {
+		t.Fatalf("DecryptPayload failed: %v", err)
+	}
+	if string(plaintext) != string(payload) {
+		t.Errorf("DecryptPayload = %s, want %s", plaintext, payload)
+	}
+}
+
+func TestEncryptor_DecryptErrors(t *testing.T) {
+	enc, _ := newTestEncryptor(t)
+	ctx := context.Background()
+
+	token, _, err := enc.EncryptPayload(ctx, json.RawMessage(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	var s string
+	_ = json.Unmarshal(token, &s)
+	tampered, _ := json.Marshal(s[:len(s)-4] + "AAA=")
+	unknown, _ := json.Marshal(strings.Replace(s, ":k1:", ":k9:", 1))
+
+	tests := []struct {
+		name    string
+		payload json.RawMessage
+	}{
+		{"plaintext", json.RawMessage(`{"a":1}`)},
+		{"tampered", tampered},
+		{"unknown key", unknown},
+		{"malformed", json.RawMessage(`"asya-enc:v1:k1"`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := enc.DecryptPayload(ctx, tt.payload); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := enc.DecryptPayload(ctx, unknown); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestKeyringProvider_Rotation(t *testing.T) {
+	enc, path := newTestEncryptor(t)
+	ctx := context.Background()
+
+	oldToken, _, err := enc.EncryptPayload(ctx, json.RawMessage(`"before"`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+
+	// Rotate: add k2 as the active key, keep k1 for decryption
+	writeKeyring(t, path, "k2", map[string]string{"k1": testKey(1), "k2": testKey(2)})
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	newToken, keyID, err := enc.EncryptPayload(ctx, json.RawMessage(`"after"`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	if keyID != "k2" {
+		t.Errorf("keyID after rotation = %q, want k2", keyID)
+	}
+
+	for token, want := range map[string]string{string(oldToken): `"before"`, string(newToken): `"after"`} {
+		plaintext, err := enc.DecryptPayload(ctx, json.RawMessage(token))
+		if err != nil {
+			t.Fatalf("DecryptPayload failed: %v", err)
+		}
+		if string(plaintext) != want {
+			t.Errorf("DecryptPayload = %s, want %s", plaintext, want)
+		}
+	}
+}
+
+func TestNewKeyringProvider_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		active string
+		keys   map[string]string
+	}{
+		{"missing active key", "k2", map[string]string{"k1": testKey(1)}},
+		{"short key", "k1", map[string]string{"k1": base64.StdEncoding.EncodeToString([]byte("short"))}},
+		{"colon in key id", "a:b", map[string]string{"a:b": testKey(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			writeKeyring(t, path, tt.active, tt.keys)
+			if _, err := NewKeyringProvider(path); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := NewKeyringProvider(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for missing keyring")
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	enc, err := New(context.Background(), Config{})
+	if err != nil || enc != nil {
+		t.Errorf("New(disabled) = %v, %v; want nil, nil", enc, err)
+	}
+	if _, err := New(context.Background(), Config{Provider: "vault"}); err == nil {
+		t.Error("Expected error for unsupported provider")
+	}
+}
@@ -0,0 +1,131 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyringFile is the keyring file layout:
+//
+//	{"active": "2024-06", "keys": {"2024-01": "<base64 key>", "2024-06": "<base64 key>"}}
+//
+// New payloads are encrypted with the active key; the others only decrypt.
+type keyringFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// KeyringProvider serves keys from a JSON keyring file, typically a Kubernetes secret
+// mounted as a volume. The file is reloaded when it changes, so keys can be rotated
+// by updating the secret without restarting.
+type KeyringProvider struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	active  string
+	keys    map[string][]byte
+}
+
+// NewKeyringProvider loads the keyring file at path
+func NewKeyringProvider(path string) (*KeyringProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("keyring path is required")
+	}
+	p := &KeyringProvider{path: path}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CurrentKey returns the active key
+func (p *KeyringProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	p.reloadIfChanged()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.active, p.keys[p.active], nil
+}
+
+// Key returns the key with the given ID
+func (p *KeyringProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	p.reloadIfChanged()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// reloadIfChanged reloads the keyring when the file was modified, keeping the current keys on error
+func (p *KeyringProvider) reloadIfChanged() {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return
+	}
+
+	p.mu.RLock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	if err := p.load(); err != nil {
+		slog.Warn("Failed to reload keyring, keeping previous keys", "path", p.path, "error", err)
+	}
+}
+
+func (p *KeyringProvider) load() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read keyring: %w", err)
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse keyring %s: %w", p.path, err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return fmt.Errorf("invalid key ID %q in keyring %s", id, p.path)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid key %q in keyring %s: %w", id, p.path, err)
+		}
+		if len(key) != KeySize {
+			return fmt.Errorf("key %q in keyring %s is %d bytes, want %d", id, p.path, len(key), KeySize)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[file.Active]; !ok {
+		return fmt.Errorf("active key %q not found in keyring %s", file.Active, p.path)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.modTime = info.ModTime()
+	p.active = file.Active
+	p.keys = keys
+
+	slog.Info("Loaded encryption keyring", "path", p.path, "active", file.Active, "keys", len(keys))
+	return nil
+}
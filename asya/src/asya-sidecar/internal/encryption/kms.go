@@ -0,0 +1,231 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	// kmsKeyIDPrefix marks key IDs that are KMS-encrypted data keys
+	kmsKeyIDPrefix = "kms-"
+
+	defaultDataKeyTTL = time.Hour
+
+	// maxCachedDataKeys bounds the decrypted data key cache
+	maxCachedDataKeys = 1024
+)
+
+// KMSClient is the part of the AWS KMS API used for envelope encryption
+type KMSClient interface {
+	// GenerateDataKey returns a new AES-256 data key in plaintext and encrypted under keyID
+	GenerateDataKey(ctx context.Context, keyID string) (plaintext, ciphertextBlob []byte, err error)
+	// Decrypt returns the plaintext of a data key encrypted by GenerateDataKey
+	Decrypt(ctx context.Context, ciphertextBlob []byte) ([]byte, error)
+}
+
+// KMSProvider encrypts payloads with data keys generated by AWS KMS.
+// A data key is used for DataKeyTTL before a new one is generated. The key ID is the
+// KMS-encrypted data key itself, so any actor allowed to kms:Decrypt can read the payload
+// and rotating the KMS key needs no coordination.
+type KMSProvider struct {
+	client   KMSClient
+	kmsKeyID string
+	ttl      time.Duration
+
+	mu         sync.Mutex
+	currentID  string
+	currentKey []byte
+	expiresAt  time.Time
+	cache      map[string][]byte
+}
+
+// NewKMSProvider creates a provider generating data keys under the given KMS key
+func NewKMSProvider(client KMSClient, kmsKeyID string, ttl time.Duration) *KMSProvider {
+	if ttl <= 0 {
+		ttl = defaultDataKeyTTL
+	}
+	return &KMSProvider{
+		client:   client,
+		kmsKeyID: kmsKeyID,
+		ttl:      ttl,
+		cache:    make(map[string][]byte),
+	}
+}
+
+// CurrentKey returns the current data key, generating a new one when it expired
+func (p *KMSProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.currentKey != nil && time.Now().Before(p.expiresAt) {
+		return p.currentID, p.currentKey, nil
+	}
+
+	plaintext, blob, err := p.client.GenerateDataKey(ctx, p.kmsKeyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if len(plaintext) != KeySize {
+		return "", nil, fmt.Errorf("data key is %d bytes, want %d", len(plaintext), KeySize)
+	}
+
+	p.currentID = kmsKeyIDPrefix + base64.RawURLEncoding.EncodeToString(blob)
+	p.currentKey = plaintext
+	p.expiresAt = time.Now().Add(p.ttl)
+	p.cacheKey(p.currentID, plaintext)
+	return p.currentID, p.currentKey, nil
+}
+
+// Key decrypts the data key named by keyID, caching the result
+func (p *KMSProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	p.mu.Lock()
+	key, ok := p.cache[keyID]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	encoded, ok := strings.CutPrefix(keyID, kmsKeyIDPrefix)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(blob) == 0 {
+		return nil, ErrUnknownKey
+	}
+
+	key, err = p.client.Decrypt(ctx, blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	p.mu.Lock()
+	p.cacheKey(keyID, key)
+	p.mu.Unlock()
+	return key, nil
+}
+
+// cacheKey stores a decrypted data key; callers hold p.mu
+func (p *KMSProvider) cacheKey(keyID string, key []byte) {
+	if len(p.cache) >= maxCachedDataKeys {
+		p.cache = make(map[string][]byte)
+	}
+	p.cache[keyID] = key
+}
+
+// kmsHTTPClient calls the KMS JSON API with SigV4-signed requests
+type kmsHTTPClient struct {
+	httpClient  *http.Client
+	endpoint    string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+}
+
+// NewKMSClient creates a KMS client using the default AWS credential chain (including IRSA).
+// endpoint overrides the regional KMS endpoint, e.g. http://localstack:4566 for a local stand-in.
+func NewKMSClient(ctx context.Context, region, endpoint string) (KMSClient, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", region)
+	}
+
+	return &kmsHTTPClient{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		endpoint:    endpoint,
+		region:      region,
+		credentials: awsCfg.Credentials,
+		signer:      v4.NewSigner(),
+	}, nil
+}
+
+// GenerateDataKey calls KMS GenerateDataKey for an AES-256 key
+func (c *kmsHTTPClient) GenerateDataKey(ctx context.Context, keyID string) ([]byte, []byte, error) {
+	var out struct {
+		Plaintext      []byte `json:"Plaintext"`
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	in := map[string]string{"KeyId": keyID, "KeySpec": "AES_256"}
+	if err := c.call(ctx, "GenerateDataKey", in, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+// Decrypt calls KMS Decrypt
+func (c *kmsHTTPClient) Decrypt(ctx context.Context, ciphertextBlob []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	in := map[string][]byte{"CiphertextBlob": ciphertextBlob}
+	if err := c.call(ctx, "Decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// call sends a signed KMS JSON API request; []byte fields are base64-encoded as KMS expects
+func (c *kmsHTTPClient) call(ctx context.Context, operation string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal KMS %s request: %w", operation, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create KMS %s request: %w", operation, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+operation)
+
+	creds, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "kms", c.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign KMS %s request: %w", operation, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s request failed: %w", operation, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read KMS %s response: %w", operation, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return fmt.Errorf("KMS %s failed with status %d: %s %s", operation, resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse KMS %s response: %w", operation, err)
+	}
+	return nil
+}
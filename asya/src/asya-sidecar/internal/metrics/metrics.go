@@ -27,11 +27,17 @@ type Metrics struct {
 	messageSize          *prometheus.HistogramVec
 	activeMessages       prometheus.Gauge
 	runtimeErrors        *prometheus.CounterVec
+	progressDropped      *prometheus.CounterVec
+	progressBatchSize    prometheus.Histogram
+	progressDuration     *prometheus.HistogramVec
+	shadowComparisons    *prometheus.CounterVec
+	shadowLatencyDelta   *prometheus.HistogramVec
 
 	// Custom metrics (dynamically registered)
 	customCounters   map[string]*prometheus.CounterVec
 	customGauges     map[string]*prometheus.GaugeVec
 	customHistograms map[string]*prometheus.HistogramVec
+	customLabels     map[string][]string
 
 	registry *prometheus.Registry
 }
@@ -45,6 +51,7 @@ func NewMetrics(namespace string, customMetricsConfig []config.CustomMetricConfi
 		customCounters:   make(map[string]*prometheus.CounterVec),
 		customGauges:     make(map[string]*prometheus.GaugeVec),
 		customHistograms: make(map[string]*prometheus.HistogramVec),
+		customLabels:     make(map[string][]string),
 	}
 
 	// Standard metrics
@@ -151,6 +158,53 @@ func NewMetrics(namespace string, customMetricsConfig []config.CustomMetricConfi
 		[]string{"queue", "error_type"},
 	)
 
+	m.progressDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "progress_updates_dropped_total",
+			Help:      "Total number of progress updates dropped by the async reporter",
+		},
+		[]string{"reason"}, // reason: buffer_full, shed
+	)
+
+	m.progressBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "progress_batch_size",
+			Help:      "Number of progress updates per batch sent to the gateway",
+			Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500},
+		},
+	)
+
+	m.progressDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "progress_report_duration_seconds",
+			Help:      "Time spent sending a progress batch to the gateway",
+			Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2, 5},
+		},
+		[]string{"status"}, // status: success, error
+	)
+
+	m.shadowComparisons = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shadow_comparisons_total",
+			Help:      "Total number of mirrored envelopes whose shadow result was compared with the primary actor's",
+		},
+		[]string{"primary", "result"}, // result: match, diff, error
+	)
+
+	m.shadowLatencyDelta = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "shadow_latency_delta_seconds",
+			Help:      "Shadow runtime duration minus the primary actor's runtime duration for the same envelope",
+			Buckets:   []float64{-10, -5, -1, -.5, -.1, -.05, -.01, 0, .01, .05, .1, .5, 1, 5, 10},
+		},
+		[]string{"primary"},
+	)
+
 	// Register standard metrics
 	registry.MustRegister(
 		m.messagesReceived,
@@ -164,6 +218,11 @@ func NewMetrics(namespace string, customMetricsConfig []config.CustomMetricConfi
 		m.messageSize,
 		m.activeMessages,
 		m.runtimeErrors,
+		m.progressDropped,
+		m.progressBatchSize,
+		m.progressDuration,
+		m.shadowComparisons,
+		m.shadowLatencyDelta,
 	)
 
 	// Register custom metrics
@@ -178,6 +237,8 @@ func NewMetrics(namespace string, customMetricsConfig []config.CustomMetricConfi
 func (m *Metrics) registerCustomMetric(config config.CustomMetricConfig) {
 	name := sanitizeMetricName(config.Name)
 
+	m.customLabels[name] = config.Labels
+
 	switch strings.ToLower(config.Type) {
 	case "counter":
 		counter := prometheus.NewCounterVec(
@@ -293,6 +354,27 @@ func (m *Metrics) RecordRuntimeError(queue, errorType string) {
 	m.runtimeErrors.WithLabelValues(queue, errorType).Inc()
 }
 
+func (m *Metrics) RecordProgressDropped(reason string) {
+	m.progressDropped.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) RecordProgressBatch(size int, duration time.Duration, success bool) {
+	status := "success"
+	if !success {
+		status = "error"
+	}
+	m.progressBatchSize.Observe(float64(size))
+	m.progressDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordShadowComparison records the comparison of a shadow result with the primary actor's result
+func (m *Metrics) RecordShadowComparison(primary, result string, latencyDelta time.Duration) {
+	m.shadowComparisons.WithLabelValues(primary, result).Inc()
+	if result != "error" {
+		m.shadowLatencyDelta.WithLabelValues(primary).Observe(latencyDelta.Seconds())
+	}
+}
+
 // Custom metric recording methods
 
 // IncrementCustomCounter increments a custom counter
@@ -355,6 +437,41 @@ func (m *Metrics) ObserveCustomHistogram(name string, value float64, labelValues
 	return nil
 }
 
+// RecordRuntimeMetric records a custom metric observation emitted by the runtime.
+// The metric type comes from its declaration: counters are incremented by value,
+// gauges are set to value and histograms observe value. Labels must match the
+// declared labels exactly.
+func (m *Metrics) RecordRuntimeMetric(name string, value float64, labels map[string]string) error {
+	name = sanitizeMetricName(name)
+	declared, exists := m.customLabels[name]
+	if !exists {
+		return fmt.Errorf("custom metric '%s' not declared", name)
+	}
+
+	if len(labels) != len(declared) {
+		return fmt.Errorf("custom metric '%s' expects labels %v, got %d", name, declared, len(labels))
+	}
+	labelValues := make([]string, len(declared))
+	for i, label := range declared {
+		v, ok := labels[label]
+		if !ok {
+			return fmt.Errorf("custom metric '%s' missing label '%s'", name, label)
+		}
+		labelValues[i] = v
+	}
+
+	if _, ok := m.customCounters[name]; ok {
+		if value < 0 {
+			return fmt.Errorf("custom counter '%s' cannot decrease (value %v)", name, value)
+		}
+		return m.AddCustomCounter(name, value, labelValues...)
+	}
+	if _, ok := m.customGauges[name]; ok {
+		return m.SetCustomGauge(name, value, labelValues...)
+	}
+	return m.ObserveCustomHistogram(name, value, labelValues...)
+}
+
 // Handler returns an HTTP handler for Prometheus metrics endpoint
 func (m *Metrics) Handler() http.Handler {
 	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
@@ -230,6 +230,42 @@ func TestMetrics_RecordRuntimeError(t *testing.T) {
 	}
 }
 
+func TestMetrics_RecordProgress(t *testing.T) {
+	m := NewMetrics("test", []config.CustomMetricConfig{})
+
+	m.RecordProgressDropped("shed")
+	m.RecordProgressDropped("shed")
+	m.RecordProgressBatch(10, 20*time.Millisecond, true)
+	m.RecordProgressBatch(5, time.Second, false)
+
+	if value := testutil.ToFloat64(m.progressDropped.WithLabelValues("shed")); value != 2.0 {
+		t.Errorf("Expected dropped 2.0, got %f", value)
+	}
+	if testutil.CollectAndCount(m.progressBatchSize) == 0 {
+		t.Error("progressBatchSize has no observations")
+	}
+	if count := testutil.CollectAndCount(m.progressDuration); count != 2 {
+		t.Errorf("Expected progressDuration series for success and error, got %d", count)
+	}
+}
+
+func TestMetrics_RecordShadowComparison(t *testing.T) {
+	m := NewMetrics("test", nil)
+
+	m.RecordShadowComparison("ocr", "match", 50*time.Millisecond)
+	m.RecordShadowComparison("ocr", "diff", -20*time.Millisecond)
+	m.RecordShadowComparison("ocr", "error", 0)
+
+	for result, want := range map[string]float64{"match": 1, "diff": 1, "error": 1} {
+		if value := testutil.ToFloat64(m.shadowComparisons.WithLabelValues("ocr", result)); value != want {
+			t.Errorf("Expected %s comparisons %f, got %f", result, want, value)
+		}
+	}
+	if count := testutil.CollectAndCount(m.shadowLatencyDelta); count != 1 {
+		t.Errorf("Expected one shadowLatencyDelta series, got %d", count)
+	}
+}
+
 func TestMetrics_CustomCounter(t *testing.T) {
 	customConfig := []config.CustomMetricConfig{
 		{
@@ -346,3 +382,53 @@ func TestSanitizeMetricName(t *testing.T) {
 		})
 	}
 }
+
+func TestMetrics_RecordRuntimeMetric(t *testing.T) {
+	customConfig := []config.CustomMetricConfig{
+		{Name: "tokens_total", Type: "counter", Help: "Tokens", Labels: []string{"model"}},
+		{Name: "confidence", Type: "gauge", Help: "Confidence", Labels: []string{}},
+		{Name: "latency", Type: "histogram", Help: "Latency", Labels: []string{"model"}},
+	}
+
+	m := NewMetrics("test", customConfig)
+
+	if err := m.RecordRuntimeMetric("tokens_total", 7, map[string]string{"model": "gpt"}); err != nil {
+		t.Fatalf("RecordRuntimeMetric(counter) failed: %v", err)
+	}
+	if err := m.RecordRuntimeMetric("tokens_total", 3, map[string]string{"model": "gpt"}); err != nil {
+		t.Fatalf("RecordRuntimeMetric(counter) failed: %v", err)
+	}
+	if err := m.RecordRuntimeMetric("confidence", 0.9, nil); err != nil {
+		t.Fatalf("RecordRuntimeMetric(gauge) failed: %v", err)
+	}
+	if err := m.RecordRuntimeMetric("latency", 0.2, map[string]string{"model": "gpt"}); err != nil {
+		t.Fatalf("RecordRuntimeMetric(histogram) failed: %v", err)
+	}
+
+	if value := testutil.ToFloat64(m.customCounters["tokens_total"].With(prometheus.Labels{"model": "gpt"})); value != 10.0 {
+		t.Errorf("Expected counter value 10.0, got %f", value)
+	}
+	if value := testutil.ToFloat64(m.customGauges["confidence"].With(prometheus.Labels{})); value != 0.9 {
+		t.Errorf("Expected gauge value 0.9, got %f", value)
+	}
+
+	invalid := []struct {
+		name   string
+		metric string
+		value  float64
+		labels map[string]string
+	}{
+		{"undeclared metric", "unknown", 1, nil},
+		{"missing label", "tokens_total", 1, nil},
+		{"unexpected label", "tokens_total", 1, map[string]string{"model": "gpt", "user": "u1"}},
+		{"wrong label", "tokens_total", 1, map[string]string{"region": "eu"}},
+		{"negative counter", "tokens_total", -1, map[string]string{"model": "gpt"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.RecordRuntimeMetric(tt.metric, tt.value, tt.labels); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBufferSize    = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = 250 * time.Millisecond
+	maxRetryBackoff      = 5 * time.Second
+)
+
+// Drop reasons reported to the Recorder
+const (
+	DropBufferFull = "buffer_full" // Buffer holds BufferSize envelopes
+	DropShed       = "shed"        // Intermediate status shed while buffer is above the high watermark
+)
+
+// Recorder receives asynchronous reporter metrics (implemented by metrics.Metrics)
+type Recorder interface {
+	RecordProgressDropped(reason string)
+	RecordProgressBatch(size int, duration time.Duration, success bool)
+}
+
+// AsyncConfig configures the asynchronous progress reporter
+type AsyncConfig struct {
+	BufferSize    int           // Max envelopes with a pending update (default 1024)
+	BatchSize     int           // Max updates per batch request (default 100)
+	FlushInterval time.Duration // Max delay before a pending update is sent (default 250ms)
+}
+
+// errBatchUnsupported is returned when the gateway predates POST /envelopes/progress/batch
+var errBatchUnsupported = errors.New("gateway does not support progress batches")
+
+// batchUpdate is one entry of POST /envelopes/progress/batch
+type batchUpdate struct {
+	ID string `json:"id"`
+	ProgressUpdate
+}
+
+// AsyncReporter reports progress in the background so a slow gateway never stalls message processing.
+// Updates are coalesced per envelope (only the latest is sent) and posted in batches.
+// Under pressure it sheds intermediate statuses first and drops new envelopes once the buffer is full;
+// "completed" updates are only dropped when the buffer is full.
+// Against a gateway without the batch endpoint (404) it falls back to
+// per-envelope updates for the rest of its lifetime.
+type AsyncReporter struct {
+	reporter *Reporter
+	cfg      AsyncConfig
+	recorder Recorder
+	single   atomic.Bool // Batch endpoint unsupported; send updates one by one
+
+	mu      sync.Mutex
+	pending map[string]ProgressUpdate
+	order   []string // Envelope IDs in arrival order
+
+	flush  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAsyncReporter starts a background reporter posting to the reporter's gateway.
+// recorder may be nil.
+func NewAsyncReporter(reporter *Reporter, cfg AsyncConfig, recorder Recorder) *AsyncReporter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AsyncReporter{
+		reporter: reporter,
+		cfg:      cfg,
+		recorder: recorder,
+		pending:  make(map[string]ProgressUpdate),
+		flush:    make(chan struct{}, 1),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go a.run()
+	return a
+}
+
+// ReportProgress queues a progress update without blocking. It never returns an error;
+// the signature matches Reporter.ReportProgress.
+func (a *AsyncReporter) ReportProgress(ctx context.Context, id string, update ProgressUpdate) error {
+	if id == "" {
+		// No id in message, skip progress reporting
+		return nil
+	}
+
+	a.mu.Lock()
+	reason := a.enqueueLocked(id, update)
+	full := len(a.pending) >= a.cfg.BatchSize
+	a.mu.Unlock()
+
+	if reason != "" {
+		slog.Debug("Dropping progress update", "envelope_id", id, "status", update.Status, "reason", reason)
+		if a.recorder != nil {
+			a.recorder.RecordProgressDropped(reason)
+		}
+	}
+
+	if full {
+		select {
+		case a.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// enqueueLocked stores the update and returns the drop reason if it was not stored (must hold lock)
+func (a *AsyncReporter) enqueueLocked(id string, update ProgressUpdate) string {
+	if _, exists := a.pending[id]; exists {
+		// Coalesce: the newer update supersedes the unsent one
+		a.pending[id] = update
+		return ""
+	}
+
+	size := len(a.pending)
+	if size >= a.cfg.BufferSize {
+		return DropBufferFull
+	}
+	if size >= a.highWatermark() && update.Status != StatusCompleted {
+		return DropShed
+	}
+
+	a.pending[id] = update
+	a.order = append(a.order, id)
+	return ""
+}
+
+// highWatermark is the buffer size above which intermediate statuses are shed
+func (a *AsyncReporter) highWatermark() int {
+	return a.cfg.BufferSize * 3 / 4
+}
+
+// Pending returns the number of envelopes with an unsent update
+func (a *AsyncReporter) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// Close stops the background loop and sends what is still buffered until ctx expires
+func (a *AsyncReporter) Close(ctx context.Context) error {
+	a.cancel()
+	<-a.done
+
+	for a.Pending() > 0 {
+		if ctx.Err() != nil {
+			return fmt.Errorf("progress reporter closed with %d pending updates: %w", a.Pending(), ctx.Err())
+		}
+		if err := a.sendBatch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run flushes pending updates every FlushInterval or when a batch fills up,
+// backing off while the gateway is failing
+func (a *AsyncReporter) run() {
+	defer close(a.done)
+
+	backoff := time.Duration(0)
+	timer := time.NewTimer(a.cfg.FlushInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-timer.C:
+		case <-a.flush:
+			if backoff > 0 {
+				// Keep waiting out the backoff; the timer is still armed
+				continue
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		var err error
+		for a.Pending() > 0 && a.ctx.Err() == nil {
+			if err = a.sendBatch(a.ctx); err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			backoff = min(max(2*backoff, a.cfg.FlushInterval), maxRetryBackoff)
+			slog.Warn("Failed to send progress batch, will retry", "error", err, "backoff", backoff, "pending", a.Pending())
+			timer.Reset(backoff)
+		} else {
+			backoff = 0
+			timer.Reset(a.cfg.FlushInterval)
+		}
+	}
+}
+
+// takeBatch removes up to BatchSize pending updates in arrival order
+func (a *AsyncReporter) takeBatch() []batchUpdate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	n := min(len(a.order), a.cfg.BatchSize)
+	batch := make([]batchUpdate, 0, n)
+	for _, id := range a.order[:n] {
+		batch = append(batch, batchUpdate{ID: id, ProgressUpdate: a.pending[id]})
+		delete(a.pending, id)
+	}
+	a.order = a.order[n:]
+	return batch
+}
+
+// requeue puts back updates of a failed batch unless a newer update arrived meanwhile
+func (a *AsyncReporter) requeue(batch []batchUpdate) {
+	a.mu.Lock()
+	var dropped int
+	for _, u := range batch {
+		if _, superseded := a.pending[u.ID]; superseded {
+			continue
+		}
+		if len(a.pending) >= a.cfg.BufferSize {
+			dropped++
+			continue
+		}
+		a.pending[u.ID] = u.ProgressUpdate
+		a.order = append(a.order, u.ID)
+	}
+	a.mu.Unlock()
+
+	if a.recorder != nil {
+		for i := 0; i < dropped; i++ {
+			a.recorder.RecordProgressDropped(DropBufferFull)
+		}
+	}
+}
+
+// sendBatch posts one batch; on failure the updates are requeued
+func (a *AsyncReporter) sendBatch(ctx context.Context) error {
+	batch := a.takeBatch()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	var err error
+	if !a.single.Load() {
+		err = a.post(ctx, batch)
+		if errors.Is(err, errBatchUnsupported) {
+			slog.Warn("Gateway does not support progress batches, falling back to per-envelope updates",
+				"gateway", a.reporter.gatewayURL)
+			a.single.Store(true)
+		}
+	}
+	if a.single.Load() {
+		err = a.postSingle(ctx, batch)
+	}
+	if a.recorder != nil {
+		a.recorder.RecordProgressBatch(len(batch), time.Since(start), err == nil)
+	}
+
+	if err != nil {
+		a.requeue(batch)
+		return err
+	}
+
+	slog.Debug("Progress batch sent", "updates", len(batch), "duration", time.Since(start))
+	return nil
+}
+
+// post sends a batch to the gateway bulk endpoint
+func (a *AsyncReporter) post(ctx context.Context, batch []batchUpdate) error {
+	payload, err := json.Marshal(map[string][]batchUpdate{"updates": batch})
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress batch: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/envelopes/progress/batch", a.reporter.gatewayURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.reporter.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send progress batch: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errBatchUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("progress batch returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// postSingle sends a batch as individual POST /envelopes/{id}/progress requests
+func (a *AsyncReporter) postSingle(ctx context.Context, batch []batchUpdate) error {
+	for _, u := range batch {
+		if err := a.reporter.ReportProgress(ctx, u.ID, u.ProgressUpdate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
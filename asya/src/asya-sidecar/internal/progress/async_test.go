@@ -0,0 +1,285 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRecorder collects async reporter metrics
+type fakeRecorder struct {
+	mu      sync.Mutex
+	dropped map[string]int
+	batches []bool
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{dropped: make(map[string]int)}
+}
+
+func (r *fakeRecorder) RecordProgressDropped(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped[reason]++
+}
+
+func (r *fakeRecorder) RecordProgressBatch(size int, duration time.Duration, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, success)
+}
+
+// batchServer records batches posted to /envelopes/progress/batch
+type batchServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	batches [][]batchUpdate
+}
+
+func newBatchServer(t *testing.T, status func(call int) int) *batchServer {
+	t.Helper()
+	s := &batchServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/envelopes/progress/batch" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Updates []batchUpdate `json:"updates"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		s.mu.Lock()
+		call := len(s.batches)
+		s.batches = append(s.batches, body.Updates)
+		s.mu.Unlock()
+
+		code := http.StatusOK
+		if status != nil {
+			code = status(call)
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *batchServer) received() [][]batchUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]batchUpdate(nil), s.batches...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met within 2s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncReporter_CoalescesAndFlushesOnClose(t *testing.T) {
+	server := newBatchServer(t, nil)
+	// Long interval: only Close flushes
+	a := NewAsyncReporter(NewReporter(server.URL, "test-actor"), AsyncConfig{FlushInterval: time.Hour}, nil)
+
+	actors := []string{"a", "b"}
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Actors: actors, Status: StatusReceived})
+	_ = a.ReportProgress(context.Background(), "env-2", ProgressUpdate{Actors: actors, Status: StatusReceived})
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Actors: actors, Status: StatusProcessing})
+	_ = a.ReportProgress(context.Background(), "", ProgressUpdate{Actors: actors, Status: StatusProcessing})
+
+	if a.Pending() != 2 {
+		t.Fatalf("Pending = %d, want 2", a.Pending())
+	}
+
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	batches := server.received()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("Expected one batch of 2 updates, got %+v", batches)
+	}
+	if batches[0][0].ID != "env-1" || batches[0][0].Status != StatusProcessing {
+		t.Errorf("First update = %+v, want env-1 processing (latest update, first arrival)", batches[0][0])
+	}
+	if batches[0][1].ID != "env-2" || batches[0][1].Status != StatusReceived {
+		t.Errorf("Second update = %+v, want env-2 received", batches[0][1])
+	}
+}
+
+func TestAsyncReporter_FlushesFullBatch(t *testing.T) {
+	server := newBatchServer(t, nil)
+	a := NewAsyncReporter(NewReporter(server.URL, "test-actor"), AsyncConfig{BatchSize: 2, FlushInterval: time.Hour}, nil)
+	defer func() { _ = a.Close(context.Background()) }()
+
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusReceived})
+	_ = a.ReportProgress(context.Background(), "env-2", ProgressUpdate{Status: StatusReceived})
+
+	waitFor(t, func() bool { return len(server.received()) == 1 })
+	if got := len(server.received()[0]); got != 2 {
+		t.Errorf("Batch size = %d, want 2", got)
+	}
+}
+
+func TestAsyncReporter_DropsUnderPressure(t *testing.T) {
+	recorder := newFakeRecorder()
+	a := NewAsyncReporter(NewReporter("http://gateway.invalid", "test-actor"), AsyncConfig{BufferSize: 4, FlushInterval: time.Hour}, recorder)
+
+	for _, id := range []string{"env-1", "env-2", "env-3"} {
+		_ = a.ReportProgress(context.Background(), id, ProgressUpdate{Status: StatusProcessing})
+	}
+	// Above the high watermark intermediate statuses are shed, completed ones still queued
+	_ = a.ReportProgress(context.Background(), "env-4", ProgressUpdate{Status: StatusProcessing})
+	_ = a.ReportProgress(context.Background(), "env-4", ProgressUpdate{Status: StatusCompleted})
+	// Buffer full
+	_ = a.ReportProgress(context.Background(), "env-5", ProgressUpdate{Status: StatusCompleted})
+	// Updates for queued envelopes are always coalesced
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusCompleted})
+
+	if a.Pending() != 4 {
+		t.Errorf("Pending = %d, want 4", a.Pending())
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.dropped[DropShed] != 1 {
+		t.Errorf("Shed drops = %d, want 1", recorder.dropped[DropShed])
+	}
+	if recorder.dropped[DropBufferFull] != 1 {
+		t.Errorf("Buffer full drops = %d, want 1", recorder.dropped[DropBufferFull])
+	}
+}
+
+func TestAsyncReporter_RetriesFailedBatch(t *testing.T) {
+	recorder := newFakeRecorder()
+	server := newBatchServer(t, func(call int) int {
+		if call == 0 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	a := NewAsyncReporter(NewReporter(server.URL, "test-actor"), AsyncConfig{FlushInterval: 10 * time.Millisecond}, recorder)
+	defer func() { _ = a.Close(context.Background()) }()
+
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusCompleted})
+
+	waitFor(t, func() bool { return len(server.received()) == 2 })
+	if retried := server.received()[1]; len(retried) != 1 || retried[0].ID != "env-1" {
+		t.Errorf("Retried batch = %+v, want env-1", retried)
+	}
+
+	waitFor(t, func() bool {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return len(recorder.batches) == 2
+	})
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.batches[0] || !recorder.batches[1] {
+		t.Errorf("Batch results = %v, want [false true]", recorder.batches)
+	}
+}
+
+func TestAsyncReporter_SlowGatewayDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	a := NewAsyncReporter(NewReporter(server.URL, "test-actor"), AsyncConfig{FlushInterval: time.Millisecond}, nil)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusProcessing, CurrentActorIdx: i})
+		time.Sleep(100 * time.Microsecond)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ReportProgress blocked on slow gateway for %v", elapsed)
+	}
+
+	// Close gives up when its context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := a.Close(ctx); err == nil {
+		t.Error("Expected Close to fail while gateway hangs")
+	}
+}
+
+func TestAsyncReporter_FallsBackToSingleUpdatesOn404(t *testing.T) {
+	var mu sync.Mutex
+	var batchCalls int
+	var singles []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/envelopes/progress/batch" {
+			batchCalls++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var update ProgressUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		singles = append(singles, r.URL.Path+" "+string(update.Status))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := newFakeRecorder()
+	a := NewAsyncReporter(NewReporter(server.URL, "test-actor"), AsyncConfig{FlushInterval: time.Hour}, recorder)
+
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusReceived})
+	_ = a.ReportProgress(context.Background(), "env-2", ProgressUpdate{Status: StatusReceived})
+	if err := a.sendBatch(context.Background()); err != nil {
+		t.Fatalf("sendBatch failed: %v", err)
+	}
+
+	_ = a.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: StatusCompleted})
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if batchCalls != 1 {
+		t.Errorf("Batch endpoint called %d times, want 1 (fallback is remembered)", batchCalls)
+	}
+	want := []string{
+		"/envelopes/env-1/progress received",
+		"/envelopes/env-2/progress received",
+		"/envelopes/env-1/progress completed",
+	}
+	if len(singles) != len(want) {
+		t.Fatalf("Single updates = %v, want %v", singles, want)
+	}
+	for i := range want {
+		if singles[i] != want[i] {
+			t.Errorf("Single update %d = %q, want %q", i, singles[i], want[i])
+		}
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for i, ok := range recorder.batches {
+		if !ok {
+			t.Errorf("Batch %d recorded as failed, want success after fallback", i)
+		}
+	}
+}
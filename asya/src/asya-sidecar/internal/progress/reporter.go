@@ -45,6 +45,9 @@ type ProgressUpdate struct {
 	Message         string         `json:"message,omitempty"`
 	DurationMs      *int64         `json:"duration_ms,omitempty"`     // Processing duration in milliseconds
 	MessageSizeKB   *float64       `json:"message_size_kb,omitempty"` // Message size in KB
+	HopCount        int            `json:"hop_count"`                 // Actors that processed the envelope before the current one
+	Compensating    bool           `json:"compensating,omitempty"`    // Set by compensating actors; Actors is then the compensation route
+	Attempt         int            `json:"attempt,omitempty"`         // Attempt of the current actor when retrying runtime errors (1 for the first)
 }
 
 // ReportProgress sends a progress update to the gateway
@@ -191,6 +194,31 @@ func (r *Reporter) CreateEnvelope(ctx context.Context, id, parentID string, acto
 	return nil
 }
 
+// RequestApproval parks an envelope at an approval step in the gateway until it is approved or rejected.
+// request is the envelope the gateway sends to the next actor once approved.
+func (r *Reporter) RequestApproval(ctx context.Context, id string, request json.RawMessage) error {
+	url := fmt.Sprintf("%s/envelopes/%s/approval", r.gatewayURL, id)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(request))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send approval request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("approval request returned status %d", resp.StatusCode)
+	}
+
+	slog.Debug("Parked envelope for approval in gateway", "id", id)
+	return nil
+}
+
 // ReportFinalError reports a final error status to the gateway
 // Used by end actors when they encounter unrecoverable errors (e.g., timeout)
 func (r *Reporter) ReportFinalError(ctx context.Context, envelopeID, errorMsg string) error {
@@ -636,6 +636,47 @@ func contains(s, substr string) bool {
 	return false
 }
 
+func TestRequestApproval_Success(t *testing.T) {
+	var receivedPath string
+	var receivedBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reporter := NewReporter(server.URL, "test-actor")
+
+	request := json.RawMessage(`{"id":"env-1","step":"approval","route":{"actors":["a","approval","b"],"current":2},"payload":{"x":1}}`)
+	if err := reporter.RequestApproval(context.Background(), "env-1", request); err != nil {
+		t.Fatalf("RequestApproval returned error: %v", err)
+	}
+
+	if receivedPath != "/envelopes/env-1/approval" {
+		t.Errorf("Path = %v, want /envelopes/env-1/approval", receivedPath)
+	}
+	if receivedBody["step"] != "approval" {
+		t.Errorf("Body = %v, want the approval request", receivedBody)
+	}
+}
+
+func TestRequestApproval_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	reporter := NewReporter(server.URL, "test-actor")
+
+	if err := reporter.RequestApproval(context.Background(), "env-1", json.RawMessage(`{}`)); err == nil {
+		t.Error("Expected error for non-OK status")
+	}
+}
+
 func TestCheckHealth_Success(t *testing.T) {
 	requestReceived := false
 
@@ -0,0 +1,114 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Status event types (mirrors types.StatusEvent in asya-gateway)
+const (
+	EventProgress = "progress"
+	EventFinal    = "final"
+	EventCreate   = "create"
+	EventApproval = "approval"
+)
+
+// StatusEvent is published to the gateway's status queue instead of calling the gateway over HTTP.
+// The field matching Type carries the same payload as the corresponding HTTP endpoint.
+type StatusEvent struct {
+	Type      string                 `json:"type"` // "progress" | "final" | "create" | "approval"
+	ID        string                 `json:"id"`
+	Actor     string                 `json:"actor,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Progress  *ProgressUpdate        `json:"progress,omitempty"`
+	Final     map[string]interface{} `json:"final,omitempty"`
+	Create    *CreateEnvelopePayload `json:"create,omitempty"`
+	Approval  json.RawMessage        `json:"approval,omitempty"`
+}
+
+// Sender publishes a message to a queue (implemented by transport.Transport)
+type Sender interface {
+	Send(ctx context.Context, queueName string, body []byte) error
+}
+
+// StatusPublisher publishes progress, final status, fanout and parked envelopes to the gateway's
+// status queue over the actor's transport, so actors do not need to reach the gateway
+type StatusPublisher struct {
+	sender    Sender
+	queueName string
+	actorName string
+}
+
+// NewStatusPublisher creates a publisher sending to the given (resolved) queue name
+func NewStatusPublisher(sender Sender, queueName, actorName string) *StatusPublisher {
+	return &StatusPublisher{
+		sender:    sender,
+		queueName: queueName,
+		actorName: actorName,
+	}
+}
+
+// ReportProgress publishes a progress update
+func (p *StatusPublisher) ReportProgress(ctx context.Context, id string, update ProgressUpdate) error {
+	if id == "" {
+		// No id in message, skip progress reporting
+		return nil
+	}
+	return p.publish(ctx, StatusEvent{Type: EventProgress, ID: id, Progress: &update})
+}
+
+// ReportFinal publishes the final status payload of an end actor
+func (p *StatusPublisher) ReportFinal(ctx context.Context, id string, final map[string]interface{}) error {
+	return p.publish(ctx, StatusEvent{Type: EventFinal, ID: id, Final: final})
+}
+
+// ReportFinalError publishes a final error status
+func (p *StatusPublisher) ReportFinalError(ctx context.Context, envelopeID, errorMsg string) error {
+	return p.ReportFinal(ctx, envelopeID, map[string]interface{}{
+		"id":        envelopeID,
+		"status":    "failed",
+		"error":     errorMsg,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// CreateEnvelope publishes a fanout child envelope
+func (p *StatusPublisher) CreateEnvelope(ctx context.Context, id, parentID string, actors []string, current int) error {
+	return p.publish(ctx, StatusEvent{
+		Type: EventCreate,
+		ID:   id,
+		Create: &CreateEnvelopePayload{
+			ID:       id,
+			ParentID: parentID,
+			Actors:   actors,
+			Current:  current,
+		},
+	})
+}
+
+// RequestApproval publishes an envelope to park at an approval step
+func (p *StatusPublisher) RequestApproval(ctx context.Context, id string, request json.RawMessage) error {
+	return p.publish(ctx, StatusEvent{Type: EventApproval, ID: id, Approval: request})
+}
+
+// publish stamps and sends an event to the status queue
+func (p *StatusPublisher) publish(ctx context.Context, event StatusEvent) error {
+	event.Actor = p.actorName
+	event.Timestamp = time.Now()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+	}
+
+	if err := p.sender.Send(ctx, p.queueName, body); err != nil {
+		slog.Warn("Failed to publish status event", "id", event.ID, "type", event.Type, "queue", p.queueName, "error", err)
+		return fmt.Errorf("failed to publish %s event: %w", event.Type, err)
+	}
+
+	slog.Debug("Status event published", "id", event.ID, "type", event.Type, "queue", p.queueName)
+	return nil
+}
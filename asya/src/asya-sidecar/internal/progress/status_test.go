@@ -0,0 +1,130 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fakeSender records published messages
+type fakeSender struct {
+	queues []string
+	bodies [][]byte
+	err    error
+}
+
+func (s *fakeSender) Send(ctx context.Context, queueName string, body []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.queues = append(s.queues, queueName)
+	s.bodies = append(s.bodies, body)
+	return nil
+}
+
+func (s *fakeSender) event(t *testing.T, i int) StatusEvent {
+	t.Helper()
+	var event StatusEvent
+	if err := json.Unmarshal(s.bodies[i], &event); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	return event
+}
+
+func TestStatusPublisher_ReportProgress(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewStatusPublisher(sender, "asya-default-gateway-status", "test-actor")
+
+	update := ProgressUpdate{Actors: []string{"a", "b"}, CurrentActorIdx: 1, Status: "processing"}
+	if err := publisher.ReportProgress(context.Background(), "env-1", update); err != nil {
+		t.Fatalf("ReportProgress failed: %v", err)
+	}
+
+	if len(sender.queues) != 1 || sender.queues[0] != "asya-default-gateway-status" {
+		t.Fatalf("Sent to %v, want [asya-default-gateway-status]", sender.queues)
+	}
+	event := sender.event(t, 0)
+	if event.Type != EventProgress || event.ID != "env-1" || event.Actor != "test-actor" {
+		t.Errorf("Event = %+v", event)
+	}
+	if event.Timestamp.IsZero() {
+		t.Error("Expected event timestamp to be set")
+	}
+	if event.Progress == nil || event.Progress.Status != "processing" || event.Progress.CurrentActorIdx != 1 {
+		t.Errorf("Progress = %+v", event.Progress)
+	}
+}
+
+func TestStatusPublisher_ReportProgress_EmptyID(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewStatusPublisher(sender, "status", "test-actor")
+
+	if err := publisher.ReportProgress(context.Background(), "", ProgressUpdate{Status: "received"}); err != nil {
+		t.Fatalf("ReportProgress failed: %v", err)
+	}
+	if len(sender.bodies) != 0 {
+		t.Errorf("Expected no event for empty id, got %d", len(sender.bodies))
+	}
+}
+
+func TestStatusPublisher_ReportFinalError(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewStatusPublisher(sender, "status", "test-actor")
+
+	if err := publisher.ReportFinalError(context.Background(), "env-1", "boom"); err != nil {
+		t.Fatalf("ReportFinalError failed: %v", err)
+	}
+
+	event := sender.event(t, 0)
+	if event.Type != EventFinal || event.ID != "env-1" {
+		t.Errorf("Event = %+v", event)
+	}
+	if event.Final["status"] != "failed" || event.Final["error"] != "boom" {
+		t.Errorf("Final = %v", event.Final)
+	}
+}
+
+func TestStatusPublisher_CreateEnvelope(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewStatusPublisher(sender, "status", "test-actor")
+
+	if err := publisher.CreateEnvelope(context.Background(), "env-1-1", "env-1", []string{"a", "b"}, 1); err != nil {
+		t.Fatalf("CreateEnvelope failed: %v", err)
+	}
+
+	event := sender.event(t, 0)
+	if event.Type != EventCreate || event.ID != "env-1-1" {
+		t.Errorf("Event = %+v", event)
+	}
+	if event.Create == nil || event.Create.ParentID != "env-1" || event.Create.Current != 1 {
+		t.Errorf("Create = %+v", event.Create)
+	}
+}
+
+func TestStatusPublisher_RequestApproval(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewStatusPublisher(sender, "asya-default-gateway-status", "test-actor")
+
+	request := json.RawMessage(`{"id":"env-1","step":"approval","route":{"actors":["a","approval","b"],"current":2},"payload":{}}`)
+	if err := publisher.RequestApproval(context.Background(), "env-1", request); err != nil {
+		t.Fatalf("RequestApproval failed: %v", err)
+	}
+
+	event := sender.event(t, 0)
+	if event.Type != EventApproval || event.ID != "env-1" {
+		t.Errorf("Event = %+v", event)
+	}
+	if string(event.Approval) != string(request) {
+		t.Errorf("Approval = %s, want %s", event.Approval, request)
+	}
+}
+
+func TestStatusPublisher_SendError(t *testing.T) {
+	sender := &fakeSender{err: errors.New("broker down")}
+	publisher := NewStatusPublisher(sender, "status", "test-actor")
+
+	if err := publisher.ReportProgress(context.Background(), "env-1", ProgressUpdate{Status: "received"}); err == nil {
+		t.Error("Expected error when send fails")
+	}
+}
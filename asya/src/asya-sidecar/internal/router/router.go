@@ -7,14 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/deliveryhero/asya/asya-sidecar/internal/config"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/encryption"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/metrics"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/progress"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/runtime"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/signing"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/transport"
 	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
 )
@@ -24,6 +30,34 @@ const (
 	statusFailed    = "failed"
 )
 
+// routeRejectedError marks a response that was not routed because its route was rejected.
+// The original envelope has already been sent to error-end.
+type routeRejectedError struct {
+	reason string // metrics failure reason
+}
+
+func (e *routeRejectedError) Error() string {
+	return "route rejected: " + e.reason
+}
+
+// hopKey is the context key for the hop record of the message being processed
+type hopKey struct{}
+
+// sendBatchKey is the context key for the sendBatch collecting fan-out envelopes
+type sendBatchKey struct{}
+
+// sendBatch collects the envelopes routed from one runtime call, sent with one BatchSender call per queue
+type sendBatch struct {
+	sends []batchedSend
+}
+
+// batchedSend is an envelope waiting in a sendBatch
+type batchedSend struct {
+	queue        string
+	envelopeType string
+	body         []byte
+}
+
 // Router handles message routing between queues and runtime client
 type Router struct {
 	cfg              *config.Config
@@ -34,17 +68,34 @@ type Router struct {
 	errorEndQueue    string
 	metrics          *metrics.Metrics
 	progressReporter *progress.Reporter
+	asyncProgress    *progress.AsyncReporter   // Buffers progressReporter updates when cfg.ProgressAsync
+	statusPublisher  *progress.StatusPublisher // Replaces gateway HTTP calls when cfg.StatusQueue is set
+	encryptor        *encryption.Encryptor     // Decrypts payloads for the runtime and encrypts routed ones when set
+	signer           *signing.Signer           // Verifies received envelopes and signs sent ones when set
 	gatewayURL       string
 }
 
 // NewRouter creates a new router instance
 func NewRouter(cfg *config.Config, transport transport.Transport, runtimeClient *runtime.Client, m *metrics.Metrics) *Router {
 	var progressReporter *progress.Reporter
+	var asyncProgress *progress.AsyncReporter
 	if cfg.GatewayURL != "" {
 		progressReporter = progress.NewReporter(cfg.GatewayURL, cfg.ActorName)
+
+		if cfg.ProgressAsync && cfg.StatusQueue == "" {
+			var recorder progress.Recorder
+			if m != nil {
+				recorder = m
+			}
+			asyncProgress = progress.NewAsyncReporter(progressReporter, progress.AsyncConfig{
+				BufferSize:    cfg.ProgressBufferSize,
+				BatchSize:     cfg.ProgressBatchSize,
+				FlushInterval: cfg.ProgressFlushInterval,
+			}, recorder)
+		}
 	}
 
-	return &Router{
+	r := &Router{
 		cfg:              cfg,
 		transport:        transport,
 		runtimeClient:    runtimeClient,
@@ -53,8 +104,15 @@ func NewRouter(cfg *config.Config, transport transport.Transport, runtimeClient
 		errorEndQueue:    cfg.ErrorEndQueue,
 		metrics:          m,
 		progressReporter: progressReporter,
+		asyncProgress:    asyncProgress,
 		gatewayURL:       cfg.GatewayURL,
 	}
+
+	if cfg.StatusQueue != "" {
+		r.statusPublisher = progress.NewStatusPublisher(transport, r.resolveQueueName(cfg.StatusQueue), cfg.ActorName)
+	}
+
+	return r
 }
 
 // processEndActorEnvelope handles envelope processing for end actors (happy-end, error-end)
@@ -73,9 +131,19 @@ func (r *Router) processEndActorEnvelope(ctx context.Context, envelope envelopes
 	// End actors run in envelope mode with validation disabled.
 	// They typically return empty dict {}, which is ignored by the sidecar.
 
+	runtimeBody, err := r.decryptForRuntime(ctx, envelope, msgBody)
+	if err != nil {
+		slog.Error("Failed to decrypt end actor envelope", "id", envelope.ID, "error", err)
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "decryption_error")
+			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+		}
+		return fmt.Errorf("failed to decrypt payload in end actor: %w", err)
+	}
+
 	// Send to runtime without route validation
 	runtimeStart := time.Now()
-	responses, err := r.runtimeClient.CallRuntime(ctx, msgBody)
+	responses, err := r.runtimeClient.CallRuntime(ctx, runtimeBody)
 	runtimeDuration := time.Since(runtimeStart)
 
 	if r.metrics != nil {
@@ -94,10 +162,10 @@ func (r *Router) processEndActorEnvelope(ctx context.Context, envelope envelopes
 			slog.Error("End actor runtime timeout exceeded - crashing pod to recover",
 				"timeout", r.cfg.Timeout, "envelope", envelope.ID)
 
-			if r.progressReporter != nil {
+			if r.reportsStatus() {
 				errorCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 				defer cancel()
-				_ = r.progressReporter.ReportFinalError(errorCtx, envelope.ID, "Runtime timeout exceeded")
+				_ = r.reportFinalError(errorCtx, envelope.ID, "Runtime timeout exceeded")
 			}
 
 			slog.Error("Exiting to prevent zombie processing (runtime may still be working)")
@@ -107,6 +175,8 @@ func (r *Router) processEndActorEnvelope(ctx context.Context, envelope envelopes
 		return fmt.Errorf("runtime error in end actor: %w", err)
 	}
 
+	r.recordRuntimeMetrics(envelope.ID, responses)
+
 	// Record success metrics
 	if r.metrics != nil {
 		r.metrics.RecordMessageProcessed(r.actorName, "end_consumed")
@@ -117,15 +187,15 @@ func (r *Router) processEndActorEnvelope(ctx context.Context, envelope envelopes
 	// End handlers should return empty dict, so we use the original envelope payload as result
 	var resultPayload json.RawMessage
 	if len(responses) > 0 && len(responses[0].Payload) > 0 {
-		// Runtime returned a payload, use it
-		resultPayload = responses[0].Payload
+		// Runtime returned a payload, use it (encrypted, so the gateway stores ciphertext)
+		resultPayload = r.encryptResult(ctx, envelope.ID, responses[0].Payload)
 	} else {
 		// Runtime returned empty/null, use original envelope payload as result
 		resultPayload = envelope.Payload
 	}
 
 	// Report final status to gateway if configured
-	if r.progressReporter != nil {
+	if r.reportsStatus() {
 		if err := r.reportFinalStatusWithEnvelope(ctx, &envelope, resultPayload, runtimeDuration); err != nil {
 			slog.Warn("Failed to report final status to gateway", "id", envelope.ID, "error", err)
 		}
@@ -146,7 +216,7 @@ func (r *Router) parseAndValidateEnvelope(ctx context.Context, msgBody []byte, s
 			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
 		}
 
-		_ = r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Failed to parse message: %v", err))
+		_ = r.rejectEnvelope(ctx, msgBody, fmt.Sprintf("Failed to parse message: %v", err), false)
 		return nil, err
 	}
 
@@ -158,16 +228,32 @@ func (r *Router) parseAndValidateEnvelope(ctx context.Context, msgBody []byte, s
 			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
 		}
 
-		_ = r.sendToErrorQueue(ctx, msgBody, "Envelope missing required 'id' field")
+		_ = r.rejectEnvelope(ctx, msgBody, "Envelope missing required 'id' field", false)
 		return nil, fmt.Errorf("envelope missing required 'id' field")
 	}
 
+	if r.signer != nil {
+		if err := r.signer.Verify(ctx, envelope); err != nil {
+			slog.Error("Envelope signature verification failed", "id", envelope.ID, "error", err)
+
+			if r.metrics != nil {
+				r.metrics.RecordMessageFailed(r.actorName, "signature_error")
+				r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+			}
+
+			_ = r.rejectEnvelope(ctx, msgBody, fmt.Sprintf("Envelope signature verification failed: %v", err), false)
+			return nil, fmt.Errorf("envelope signature verification failed: %w", err)
+		}
+	}
+
 	slog.Info("Envelope parsed and validated", "id", envelope.ID, "route", envelope.Route)
 	return &envelope, nil
 }
 
 // handleRuntimeResponses processes runtime responses and routes them to appropriate destinations
 func (r *Router) handleRuntimeResponses(ctx context.Context, envelope *envelopes.Envelope, responses []runtime.RuntimeResponse, msgBody []byte, runtimeDuration time.Duration, startTime time.Time) error {
+	responses = r.recordRuntimeMetrics(envelope.ID, responses)
+
 	if len(responses) == 0 {
 		slog.Info("Empty response from runtime, routing to happy-end", "id", envelope.ID)
 
@@ -179,14 +265,34 @@ func (r *Router) handleRuntimeResponses(ctx context.Context, envelope *envelopes
 		return r.sendToHappyQueue(ctx, *envelope)
 	}
 
+	// Fan-out envelopes are sent together once all responses are routed
+	if _, ok := r.transport.(transport.BatchSender); ok && len(responses) > 1 {
+		ctx = context.WithValue(ctx, sendBatchKey{}, &sendBatch{})
+	}
+
 	for i, response := range responses {
 		slog.Debug("Processing response", "index", i+1, "total", len(responses))
 
 		if response.IsError() {
-			return r.handleErrorResponse(ctx, msgBody, response, startTime)
+			if err := r.flushSendBatch(ctx); err != nil {
+				if r.metrics != nil {
+					r.metrics.RecordMessageFailed(r.actorName, "routing_error")
+					r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+				}
+				return err
+			}
+			return r.handleErrorResponse(ctx, envelope, msgBody, response, startTime)
 		}
 
-		if err := r.handleSuccessResponse(ctx, envelope, response, i, len(responses), runtimeDuration); err != nil {
+		if err := r.handleSuccessResponse(ctx, envelope, response, msgBody, i, len(responses), runtimeDuration); err != nil {
+			var rejected *routeRejectedError
+			if errors.As(err, &rejected) {
+				if r.metrics != nil {
+					r.metrics.RecordMessageFailed(r.actorName, rejected.reason)
+					r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+				}
+				return r.flushSendBatch(ctx)
+			}
 			if r.metrics != nil {
 				r.metrics.RecordMessageFailed(r.actorName, "routing_error")
 				r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
@@ -195,6 +301,14 @@ func (r *Router) handleRuntimeResponses(ctx context.Context, envelope *envelopes
 		}
 	}
 
+	if err := r.flushSendBatch(ctx); err != nil {
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "routing_error")
+			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+		}
+		return err
+	}
+
 	if r.metrics != nil {
 		r.metrics.RecordMessageProcessed(r.actorName, "success")
 		r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
@@ -203,8 +317,26 @@ func (r *Router) handleRuntimeResponses(ctx context.Context, envelope *envelopes
 	return nil
 }
 
-// handleErrorResponse handles error responses from runtime
-func (r *Router) handleErrorResponse(ctx context.Context, msgBody []byte, response runtime.RuntimeResponse, startTime time.Time) error {
+// handleErrorResponse handles error responses from runtime. Errors retried by the retry policy are
+// requeued to this actor; permanent errors and exhausted retries go to error-end.
+func (r *Router) handleErrorResponse(ctx context.Context, envelope *envelopes.Envelope, msgBody []byte, response runtime.RuntimeResponse, startTime time.Time) error {
+	retried, err := r.retryError(ctx, envelope, response)
+	if err != nil {
+		slog.Error("Failed to requeue envelope for retry - will NACK for redelivery", "id", envelope.ID, "error", err)
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "retry_send_failed")
+			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+		}
+		return fmt.Errorf("failed to requeue envelope for retry: %w", err)
+	}
+	if retried {
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "runtime_error_retried")
+			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+		}
+		return nil
+	}
+
 	if r.metrics != nil {
 		r.metrics.RecordMessageFailed(r.actorName, "runtime_error")
 		r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
@@ -220,19 +352,179 @@ func (r *Router) handleErrorResponse(ctx context.Context, msgBody []byte, respon
 	return nil
 }
 
+// retryError requeues the envelope to this actor after the retry policy's backoff when the policy retries
+// the runtime error's type and attempts remain, counting the attempt in the AttemptHeader.
+// It returns false when the error should go to error-end.
+func (r *Router) retryError(ctx context.Context, envelope *envelopes.Envelope, response runtime.RuntimeResponse) (bool, error) {
+	policy := r.cfg.RetryPolicy
+	if policy == nil {
+		return false, nil
+	}
+
+	errorType := response.Details.Type
+	attempt := envelope.Attempt()
+	if !policy.Retries(errorType) {
+		slog.Info("Runtime error is not retried", "id", envelope.ID, "type", errorType, "attempt", attempt)
+		return false, nil
+	}
+	if attempt >= policy.MaxAttempts {
+		slog.Warn("Retry attempts exhausted", "id", envelope.ID, "type", errorType, "attempts", attempt)
+		return false, nil
+	}
+
+	// The envelope is requeued as received: the runtime error does not count as a hop
+	retry := *envelope
+	retry.Headers = make(map[string]interface{}, len(envelope.Headers)+1)
+	for k, v := range envelope.Headers {
+		retry.Headers[k] = v
+	}
+	next := attempt + 1
+	retry.Headers[envelopes.AttemptHeader] = next
+	delay := policy.Backoff(attempt)
+
+	if err := r.signEnvelope(ctx, &retry); err != nil {
+		return false, err
+	}
+	envelopeBody, err := json.Marshal(retry)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	slog.Info("Retrying runtime error", "id", envelope.ID, "type", errorType, "attempt", next, "max_attempts", policy.MaxAttempts, "delay", delay)
+
+	if r.reportsStatus() {
+		label := errorType
+		if label == "" {
+			label = "runtime error"
+		}
+		_ = r.reportProgress(ctx, envelope.ID, progress.ProgressUpdate{
+			Actors:          envelope.Route.Actors,
+			CurrentActorIdx: envelope.Route.Current,
+			Status:          progress.StatusProcessing,
+			HopCount:        envelope.HopCount,
+			Attempt:         next,
+			Message:         fmt.Sprintf("Retrying %s in %s after %s (attempt %d of %d)", r.cfg.ActorName, delay, label, next, policy.MaxAttempts),
+		})
+	}
+
+	actorRef := envelope.Route.GetCurrentActor()
+	queueName, err := r.targetQueueName(actorRef)
+	if err != nil {
+		return false, err
+	}
+	if r.metrics != nil {
+		r.metrics.RecordMessageSize("sent", len(envelopeBody))
+	}
+	sendStart := time.Now()
+	err = r.sendDelayed(ctx, queueName, envelopeBody, delay)
+	if r.metrics != nil {
+		r.metrics.RecordQueueSendDuration(actorRef, r.cfg.TransportType, time.Since(sendStart))
+		if err == nil {
+			r.metrics.RecordMessageSent(actorRef, "retry")
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sendDelayed sends a message to become visible after delay. Transports without delayed delivery
+// hold the received message in the sidecar for the delay before sending; config.Load bounds the retry
+// delays of such transports and rejects delays that would outlive their lease.
+func (r *Router) sendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error {
+	if sender, ok := r.transport.(transport.DelayedSender); ok {
+		return sender.SendDelayed(ctx, queueName, body, delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+	return r.transport.Send(ctx, queueName, body)
+}
+
 // handleSuccessResponse handles successful responses from runtime
-func (r *Router) handleSuccessResponse(ctx context.Context, envelope *envelopes.Envelope, response runtime.RuntimeResponse, index, totalResponses int, runtimeDuration time.Duration) error {
+func (r *Router) handleSuccessResponse(ctx context.Context, envelope *envelopes.Envelope, response runtime.RuntimeResponse, msgBody []byte, index, totalResponses int, runtimeDuration time.Duration) error {
 	// Runtime is responsible for incrementing route.current:
 	// - In payload mode: runtime auto-increments
 	// - In envelope mode: user handler manually increments
 	outputRoute := response.Route
 
-	if index == 0 && r.progressReporter != nil {
+	call, err := r.resolveCall(response.Call)
+	if err != nil {
+		slog.Error("Invalid sub-route call", "id", envelope.ID, "actor", r.actorName, "call", response.Call, "error", err)
+
+		if err := r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Invalid call: %v", err)); err != nil {
+			return fmt.Errorf("failed to send invalid call to error queue: %w", err)
+		}
+		return &routeRejectedError{reason: "invalid_call"}
+	}
+
+	// A call is checked as if the called actors were inserted into the route
+	returnedRoutes := []envelopes.Route{outputRoute}
+	if call != nil {
+		returnedRoutes = append(returnedRoutes, *call)
+	}
+	for _, route := range returnedRoutes {
+		if err := r.checkRoutePolicy(envelope.Route, route); err != nil {
+			slog.Error("Route policy violation", "id", envelope.ID, "actor", r.actorName, "route", route, "error", err)
+
+			if err := r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Route policy violation: %v", err)); err != nil {
+				return fmt.Errorf("failed to send route policy violation to error queue: %w", err)
+			}
+			return &routeRejectedError{reason: "route_policy_violation"}
+		}
+	}
+
+	outputRoute, callStack, err := r.resolveCallStack(envelope.CallStack, outputRoute, call)
+	if err != nil {
+		slog.Error("Invalid sub-route call", "id", envelope.ID, "actor", r.actorName, "call", call, "error", err)
+
+		if err := r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Invalid call: %v", err)); err != nil {
+			return fmt.Errorf("failed to send invalid call to error queue: %w", err)
+		}
+		return &routeRejectedError{reason: "invalid_call"}
+	}
+
+	if err := r.checkTargetNamespace(outputRoute.GetCurrentActor()); err != nil {
+		slog.Error("Cross-namespace route rejected", "id", envelope.ID, "actor", r.actorName, "route", outputRoute, "error", err)
+
+		if err := r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Cross-namespace route rejected: %v", err)); err != nil {
+			return fmt.Errorf("failed to send cross-namespace route rejection to error queue: %w", err)
+		}
+		return &routeRejectedError{reason: "namespace_not_allowed"}
+	}
+
+	if err := r.checkHopLimit(envelope, outputRoute); err != nil {
+		slog.Error("Loop detected", "id", envelope.ID, "actor", r.actorName, "hop_count", envelope.HopCount, "visited", envelope.Visited, "error", err)
+
+		details := runtime.ErrorDetails{Message: err.Error(), Type: "MaxHopsExceeded"}
+		if err := r.sendToErrorQueue(ctx, msgBody, "loop_detected", details); err != nil {
+			return fmt.Errorf("failed to send loop detection error to error queue: %w", err)
+		}
+		return &routeRejectedError{reason: "loop_detected"}
+	}
+
+	if err := r.checkApprovalStep(outputRoute, callStack); err != nil {
+		slog.Error("Invalid approval step", "id", envelope.ID, "actor", r.actorName, "route", outputRoute, "error", err)
+
+		if err := r.sendToErrorQueue(ctx, msgBody, fmt.Sprintf("Invalid approval step: %v", err)); err != nil {
+			return fmt.Errorf("failed to send invalid approval step to error queue: %w", err)
+		}
+		return &routeRejectedError{reason: "invalid_approval_step"}
+	}
+
+	if index == 0 && r.reportsStatus() {
 		durationMs := runtimeDuration.Milliseconds()
-		_ = r.progressReporter.ReportProgress(ctx, envelope.ID, progress.ProgressUpdate{
+		_ = r.reportProgress(ctx, envelope.ID, progress.ProgressUpdate{
 			Actors:          outputRoute.Actors,
 			CurrentActorIdx: outputRoute.Current,
 			Status:          progress.StatusCompleted,
+			HopCount:        envelope.HopCount,
 			Message:         fmt.Sprintf("Completed processing in %dms", durationMs),
 			DurationMs:      &durationMs,
 		})
@@ -245,19 +537,23 @@ func (r *Router) handleSuccessResponse(ctx context.Context, envelope *envelopes.
 		parentID = &envelope.ID
 		slog.Debug("Fan-out: generated unique envelope ID", "original", envelope.ID, "fanout", envelopeID, "index", index)
 
-		if r.progressReporter != nil {
+		if r.reportsStatus() {
 			if err := r.createFanoutEnvelope(ctx, envelopeID, *parentID, outputRoute); err != nil {
 				slog.Warn("Failed to create fanout envelope in gateway", "id", envelopeID, "error", err)
 			}
 		}
 	}
 
-	return r.routeResponse(ctx, envelopeID, parentID, outputRoute, response.Payload)
+	if r.isApprovalStep(outputRoute) {
+		return r.requestApproval(ctx, envelope, envelopeID, parentID, outputRoute, callStack, response.Payload)
+	}
+	return r.routeResponse(ctx, envelope, envelopeID, parentID, outputRoute, callStack, response.Payload)
 }
 
 // ProcessEnvelope handles a single envelope from the queue
 func (r *Router) ProcessEnvelope(ctx context.Context, msg transport.QueueMessage) error {
 	startTime := time.Now()
+	ctx, hop := r.startHop(ctx, msg, startTime)
 
 	if r.metrics != nil {
 		r.metrics.IncrementActiveEnvelopes()
@@ -274,23 +570,38 @@ func (r *Router) ProcessEnvelope(ctx context.Context, msg transport.QueueMessage
 		return nil
 	}
 
+	if envelope.Shadow != nil {
+		return r.processShadowEnvelope(ctx, *envelope, msg.Body)
+	}
+
 	if r.cfg.IsEndActor {
 		return r.processEndActorEnvelope(ctx, *envelope, msg.Body, startTime)
 	}
 
-	if r.progressReporter != nil {
+	if envelope.Compensation != nil {
+		return r.processCompensationEnvelope(ctx, *envelope, msg.Body, hop, startTime)
+	}
+
+	if attempt := envelope.Attempt(); attempt > hop.Attempt {
+		hop.Attempt = attempt
+	}
+
+	if r.reportsStatus() {
 		envelopeSizeKB := float64(len(msg.Body)) / 1024.0
-		_ = r.progressReporter.ReportProgress(ctx, envelope.ID, progress.ProgressUpdate{
+		_ = r.reportProgress(ctx, envelope.ID, progress.ProgressUpdate{
 			Actors:          envelope.Route.Actors,
 			CurrentActorIdx: envelope.Route.Current,
 			Status:          progress.StatusReceived,
+			HopCount:        envelope.HopCount,
+			Attempt:         envelope.Attempt(),
 			Message:         fmt.Sprintf("Received message (%.2f KB)", envelopeSizeKB),
 			MessageSizeKB:   &envelopeSizeKB,
 		})
 	}
 
+	// The route may reference this actor by bare name or qualified with its namespace
 	currentActor := envelope.Route.GetCurrentActor()
-	if currentActor != r.cfg.ActorName {
+	if namespace, actor := envelopes.SplitActorRef(currentActor, r.cfg.Namespace); namespace != r.cfg.Namespace || actor != r.cfg.ActorName {
 		slog.Warn("Route mismatch: message routed to wrong actor",
 			"expected", r.cfg.ActorName, "actual", currentActor, "id", envelope.ID)
 
@@ -305,20 +616,46 @@ func (r *Router) ProcessEnvelope(ctx context.Context, msg transport.QueueMessage
 		return nil
 	}
 
-	if r.progressReporter != nil {
-		_ = r.progressReporter.ReportProgress(ctx, envelope.ID, progress.ProgressUpdate{
+	if r.reportsStatus() {
+		_ = r.reportProgress(ctx, envelope.ID, progress.ProgressUpdate{
 			Actors:          envelope.Route.Actors,
 			CurrentActorIdx: envelope.Route.Current,
 			Status:          progress.StatusProcessing,
+			HopCount:        envelope.HopCount,
+			Attempt:         envelope.Attempt(),
 			Message:         fmt.Sprintf("Processing in %s", r.cfg.ActorName),
 		})
 	}
 
-	slog.Info("Calling runtime", "id", envelope.ID, "actor", r.cfg.ActorName)
+	runtimeBody, err := r.decryptForRuntime(ctx, *envelope, msg.Body)
+	if err != nil {
+		slog.Error("Failed to decrypt envelope", "id", envelope.ID, "error", err)
+
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "decryption_error")
+			r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+		}
+
+		_ = r.rejectEnvelope(ctx, msg.Body, fmt.Sprintf("Failed to decrypt payload: %v", err), true)
+		return nil
+	}
+
+	// The hop timeout starts with the runtime call, not while waiting for another worker's call
+	timeout := r.cfg.Timeout
+	hopTimeout := r.hopTimeout(envelope)
+	if hopTimeout > 0 {
+		timeout = hopTimeout
+	}
+
+	slog.Info("Calling runtime", "id", envelope.ID, "actor", r.cfg.ActorName, "timeout", timeout)
 	runtimeStart := time.Now()
-	responses, err := r.runtimeClient.CallRuntime(ctx, msg.Body)
+	responses, err := r.runtimeClient.CallRuntimeWithTimeout(ctx, runtimeBody, hopTimeout)
 	runtimeDuration := time.Since(runtimeStart)
 
+	hopStart := runtimeStart.UTC()
+	hop.StartedAt = &hopStart
+	hop.RuntimeDurationMs = runtimeDuration.Milliseconds()
+
 	if err != nil {
 		slog.Info("Runtime call failed", "id", envelope.ID, "duration", runtimeDuration, "error", err)
 	} else {
@@ -343,8 +680,8 @@ func (r *Router) ProcessEnvelope(ctx context.Context, msg transport.QueueMessage
 		errorMsg := err.Error()
 		if isTimeout {
 			slog.Error("Runtime timeout exceeded - crashing pod to recover",
-				"timeout", r.cfg.Timeout, "envelope", envelope.ID)
-			errorMsg = fmt.Sprintf("Runtime timeout exceeded after %s", r.cfg.Timeout)
+				"timeout", timeout, "envelope", envelope.ID)
+			errorMsg = fmt.Sprintf("Runtime timeout exceeded after %s", timeout)
 
 			if err := r.sendToErrorQueue(ctx, msg.Body, errorMsg); err != nil {
 				slog.Error("Failed to send timeout error to error queue - exiting anyway", "error", err)
@@ -361,22 +698,145 @@ func (r *Router) ProcessEnvelope(ctx context.Context, msg transport.QueueMessage
 		return nil
 	}
 
+	r.mirrorToShadow(ctx, envelope, responses, runtimeDuration)
+
 	return r.handleRuntimeResponses(ctx, envelope, responses, msg.Body, runtimeDuration, startTime)
 }
 
+// mirrorToShadow sends a copy of the envelope to the shadow actor for ShadowPercent of envelopes,
+// carrying the primary's result for comparison. Failures are logged and never affect the primary.
+func (r *Router) mirrorToShadow(ctx context.Context, envelope *envelopes.Envelope, responses []runtime.RuntimeResponse, runtimeDuration time.Duration) {
+	if r.cfg.ShadowActor == "" || rand.IntN(100) >= r.cfg.ShadowPercent {
+		return
+	}
+
+	outcome, digest := shadowResult(responses)
+	shadowEnvelope := *envelope
+	shadowEnvelope.Shadow = &envelopes.Shadow{
+		Primary:           r.actorName,
+		Outcome:           outcome,
+		ResultDigest:      digest,
+		RuntimeDurationMs: runtimeDuration.Milliseconds(),
+	}
+	if err := r.signEnvelope(ctx, &shadowEnvelope); err != nil {
+		slog.Warn("Failed to sign shadow envelope", "id", envelope.ID, "error", err)
+		return
+	}
+	body, err := json.Marshal(shadowEnvelope)
+	if err != nil {
+		slog.Warn("Failed to marshal shadow envelope", "id", envelope.ID, "error", err)
+		return
+	}
+
+	shadowQueue, err := r.targetQueueName(r.cfg.ShadowActor)
+	if err != nil {
+		slog.Warn("Not mirroring envelope to shadow actor", "id", envelope.ID, "error", err)
+		return
+	}
+	if err := r.transport.Send(ctx, shadowQueue, body); err != nil {
+		slog.Warn("Failed to mirror envelope to shadow actor", "id", envelope.ID, "queue", shadowQueue, "error", err)
+		return
+	}
+	slog.Debug("Mirrored envelope to shadow actor", "id", envelope.ID, "queue", shadowQueue)
+	if r.metrics != nil {
+		r.metrics.RecordMessageSent(shadowQueue, "shadow")
+	}
+}
+
+// processShadowEnvelope runs an envelope mirrored from a primary actor through the runtime and compares
+// the result with the primary's. The result is dropped: it is never routed or reported to the gateway.
+func (r *Router) processShadowEnvelope(ctx context.Context, envelope envelopes.Envelope, msgBody []byte) error {
+	primary := envelope.Shadow.Primary
+
+	runtimeBody, err := r.decryptForRuntime(ctx, envelope, msgBody)
+	if err != nil {
+		slog.Warn("Failed to decrypt shadow envelope", "id", envelope.ID, "primary", primary, "error", err)
+		r.recordShadowComparison(primary, "error", 0)
+		return nil
+	}
+
+	runtimeStart := time.Now()
+	responses, err := r.runtimeClient.CallRuntime(ctx, runtimeBody)
+	runtimeDuration := time.Since(runtimeStart)
+
+	if r.metrics != nil {
+		r.metrics.RecordRuntimeDuration(r.actorName, runtimeDuration)
+	}
+
+	if err != nil {
+		slog.Warn("Shadow runtime call failed", "id", envelope.ID, "primary", primary, "error", err)
+		r.recordShadowComparison(primary, "error", 0)
+
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+			slog.Error("Runtime timeout exceeded - crashing pod to recover",
+				"timeout", r.cfg.Timeout, "envelope", envelope.ID)
+			os.Exit(1)
+		}
+		return nil
+	}
+
+	responses = r.recordRuntimeMetrics(envelope.ID, responses)
+	outcome, digest := shadowResult(responses)
+	result := "diff"
+	if outcome == envelope.Shadow.Outcome && digest == envelope.Shadow.ResultDigest {
+		result = "match"
+	}
+	latencyDelta := runtimeDuration - time.Duration(envelope.Shadow.RuntimeDurationMs)*time.Millisecond
+
+	slog.Info("Shadow result compared", "id", envelope.ID, "primary", primary, "result", result,
+		"outcome", outcome, "primaryOutcome", envelope.Shadow.Outcome, "latencyDelta", latencyDelta)
+	r.recordShadowComparison(primary, result, latencyDelta)
+	return nil
+}
+
+// shadowResult summarizes runtime responses for comparing primary and shadow actors: the outcome and
+// the digest of the response payloads (empty when the runtime returned an error)
+func shadowResult(responses []runtime.RuntimeResponse) (outcome, digest string) {
+	payloads := make([]json.RawMessage, 0, len(responses))
+	for _, response := range responses {
+		if response.IsError() {
+			return statusFailed, ""
+		}
+		if !response.IsMetricsOnly() {
+			payloads = append(payloads, response.Payload)
+		}
+	}
+	return statusSucceeded, envelopes.ResultDigest(payloads)
+}
+
+func (r *Router) recordShadowComparison(primary, result string, latencyDelta time.Duration) {
+	if r.metrics != nil {
+		r.metrics.RecordShadowComparison(primary, result, latencyDelta)
+	}
+}
+
 // routeResponse routes a single response to the appropriate queue
 // The route parameter should already have its Current index incremented by the caller
 // parentID should be set for fanout children (when index > 0 in fanout scenario)
-func (r *Router) routeResponse(ctx context.Context, id string, parentID *string, route envelopes.Route, payload json.RawMessage) error {
+// source is the envelope the response was produced from; its hop counters are advanced by recordHop
+// callStack is the call stack after resolveCallStack
+func (r *Router) routeResponse(ctx context.Context, source *envelopes.Envelope, id string, parentID *string, route envelopes.Route, callStack []envelopes.CallFrame, payload json.RawMessage) error {
 	// Determine destination queue
 	var destinationQueue string
 	var envelopeType string
 
 	actorToSend := route.GetCurrentActor()
 
+	// Send logical actor names to one of their versions and record the chosen version in the route
+	if version := r.cfg.ActorVersions.Resolve(actorToSend, id); version != actorToSend {
+		slog.Debug("Resolved logical actor to version", "id", id, "actor", actorToSend, "version", version)
+		route.Actors = slices.Clone(route.Actors)
+		route.Actors[route.Current] = version
+		actorToSend = version
+	}
+
 	if actorToSend != "" {
 		// There's a next actor in the route
-		destinationQueue = r.resolveQueueName(actorToSend)
+		queueName, err := r.targetQueueName(actorToSend)
+		if err != nil {
+			return err
+		}
+		destinationQueue = queueName
 		envelopeType = "routing"
 	} else {
 		// No more actors, route to happy-end automatically
@@ -390,6 +850,23 @@ func (r *Router) routeResponse(ctx context.Context, id string, parentID *string,
 		ParentID: parentID,
 		Route:    route,
 		Payload:  payload,
+		HopCount: source.HopCount,
+		Visited:  source.Visited,
+		MaxHops:  source.MaxHops,
+		Hops:     source.Hops,
+		Headers:  forwardedHeaders(source.Headers),
+
+		CallStack: callStack,
+	}
+	r.recordHop(ctx, &newEnvelope, statusSucceeded)
+
+	if err := r.encryptForRouting(ctx, &newEnvelope); err != nil {
+		return err
+	}
+
+	if err := r.signEnvelope(ctx, &newEnvelope); err != nil {
+		slog.Error("Failed to sign envelope for routing", "id", id, "error", err)
+		return err
 	}
 
 	// Marshal message
@@ -404,6 +881,12 @@ func (r *Router) routeResponse(ctx context.Context, id string, parentID *string,
 		r.metrics.RecordMessageSize("sent", len(envelopeBody))
 	}
 
+	if batch, ok := ctx.Value(sendBatchKey{}).(*sendBatch); ok {
+		slog.Debug("Batching envelope for queue", "id", id, "queue", destinationQueue, "type", envelopeType)
+		batch.sends = append(batch.sends, batchedSend{queue: destinationQueue, envelopeType: envelopeType, body: envelopeBody})
+		return nil
+	}
+
 	// Send to destination queue
 	sendStart := time.Now()
 	slog.Info("Sending envelope to queue", "id", id, "queue", destinationQueue, "type", envelopeType)
@@ -427,86 +910,495 @@ func (r *Router) routeResponse(ctx context.Context, id string, parentID *string,
 	return err
 }
 
-// sendToHappyQueue sends the original message to the happy-end queue
-func (r *Router) sendToHappyQueue(ctx context.Context, message envelopes.Envelope) error {
-	envelopeBody, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal envelope for happy-end: %w", err)
-	}
-
-	// Record envelope size
-	if r.metrics != nil {
-		r.metrics.RecordMessageSize("sent", len(envelopeBody))
+// flushSendBatch sends the envelopes collected in ctx's sendBatch, one BatchSender call per destination queue
+func (r *Router) flushSendBatch(ctx context.Context) error {
+	batch, ok := ctx.Value(sendBatchKey{}).(*sendBatch)
+	if !ok || len(batch.sends) == 0 {
+		return nil
 	}
+	sender := r.transport.(transport.BatchSender)
+	sends := batch.sends
+	batch.sends = nil
 
-	// Send to happy-end queue
-	sendStart := time.Now()
-	happyQueueName := r.resolveQueueName(r.happyEndQueue)
-	err = r.transport.Send(ctx, happyQueueName, envelopeBody)
-	sendDuration := time.Since(sendStart)
-
-	// Record metrics
-	if r.metrics != nil {
-		r.metrics.RecordQueueSendDuration(r.happyEndQueue, r.cfg.TransportType, sendDuration)
-		if err == nil {
-			r.metrics.RecordMessageSent(r.happyEndQueue, "happy_end")
+	var queues []string
+	byQueue := make(map[string][]batchedSend)
+	for _, send := range sends {
+		if _, ok := byQueue[send.queue]; !ok {
+			queues = append(queues, send.queue)
 		}
+		byQueue[send.queue] = append(byQueue[send.queue], send)
 	}
 
-	return err
-}
-
-// sendToErrorQueue sends an error message to the error-end queue
-func (r *Router) sendToErrorQueue(ctx context.Context, originalBody []byte, errorMsg string, errorDetails ...runtime.ErrorDetails) error {
-	// Parse original message to extract id, parent_id, and route
-	var originalMsg envelopes.Envelope
-	id := ""
-	var parentID *string
-	route := map[string]any{
-		"actors":  []string{"error-end"},
-		"current": 0,
-	}
-	if err := json.Unmarshal(originalBody, &originalMsg); err == nil {
-		id = originalMsg.ID
-		parentID = originalMsg.ParentID
-		// Preserve original route for traceability
-		if originalMsg.Route.Actors != nil {
-			route["actors"] = originalMsg.Route.Actors
-			route["current"] = originalMsg.Route.Current
+	for _, queue := range queues {
+		bodies := make([][]byte, 0, len(byQueue[queue]))
+		for _, send := range byQueue[queue] {
+			bodies = append(bodies, send.body)
 		}
-	}
 
-	// Build proper envelope structure with error in payload
-	errorPayload := map[string]any{
-		"error": errorMsg,
-	}
+		sendStart := time.Now()
+		slog.Info("Sending envelope batch to queue", "queue", queue, "envelopes", len(bodies))
+		err := sender.SendBatch(ctx, queue, bodies)
+		sendDuration := time.Since(sendStart)
 
-	// Add error details to payload
-	if len(errorDetails) > 0 {
-		errorPayload["details"] = errorDetails[0]
-	}
+		if r.metrics != nil {
+			r.metrics.RecordQueueSendDuration(queue, r.cfg.TransportType, sendDuration)
+			if err == nil {
+				for _, send := range byQueue[queue] {
+					r.metrics.RecordMessageSent(queue, send.envelopeType)
+				}
+			}
+		}
 
-	// Preserve original payload if available
-	// Unmarshal json.RawMessage to actual object so it serializes correctly
-	if originalMsg.Payload != nil {
-		var originalPayload any
-		if err := json.Unmarshal(originalMsg.Payload, &originalPayload); err == nil {
-			errorPayload["original_payload"] = originalPayload
+		if err != nil {
+			slog.Error("Failed to send envelope batch to queue", "queue", queue, "envelopes", len(bodies), "error", err)
+			return fmt.Errorf("failed to send %d envelopes to %s: %w", len(bodies), queue, err)
 		}
+		slog.Info("Successfully sent envelope batch to queue", "queue", queue, "envelopes", len(bodies), "duration", sendDuration)
 	}
+	return nil
+}
 
-	errorMessage := map[string]any{
-		"id":      id,
-		"route":   route,
-		"payload": errorPayload,
-	}
-	if parentID != nil {
-		errorMessage["parent_id"] = *parentID
+// encryptForRouting encrypts the payload of an envelope leaving this actor
+func (r *Router) encryptForRouting(ctx context.Context, envelope *envelopes.Envelope) error {
+	if r.encryptor == nil {
+		return nil
 	}
 
-	envelopeBody, err := json.Marshal(errorMessage)
+	encrypted, keyID, err := r.encryptor.EncryptPayload(ctx, envelope.Payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal error message: %w", err)
+		slog.Error("Failed to encrypt payload for routing", "id", envelope.ID, "error", err)
+		return fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+	headers := make(map[string]interface{}, len(envelope.Headers)+1)
+	for k, v := range envelope.Headers {
+		headers[k] = v
+	}
+	headers[encryption.KeyIDHeader] = keyID
+	envelope.Payload = encrypted
+	envelope.Headers = headers
+	return nil
+}
+
+// forwardedHeaders returns the headers an envelope carries to the next actor: the incoming headers
+// without those the platform sets per hop (signature, key ID and retry attempt)
+func forwardedHeaders(headers map[string]interface{}) map[string]interface{} {
+	forwarded := make(map[string]interface{}, len(headers))
+	for k, v := range headers {
+		switch k {
+		case signing.SignatureHeader, encryption.KeyIDHeader, envelopes.AttemptHeader:
+		default:
+			forwarded[k] = v
+		}
+	}
+	if len(forwarded) == 0 {
+		return nil
+	}
+	return forwarded
+}
+
+// approvalRequest parks an envelope at an approval step in the gateway (mirrors types.ApprovalRequest
+// in asya-gateway). The envelope is the one the gateway sends once approved, so its route points at
+// the actor after the step.
+type approvalRequest struct {
+	envelopes.Envelope
+	Step string `json:"step"`
+}
+
+// isApprovalStep reports whether a route's current actor is the approval step
+func (r *Router) isApprovalStep(route envelopes.Route) bool {
+	return r.cfg.ApprovalActor != "" && route.GetCurrentActor() == r.cfg.ApprovalActor
+}
+
+// resumeAfterApproval returns the route and call stack an envelope continues with once approved:
+// the actor after the approval step, returning to the caller when the step ends a sub-route
+func (r *Router) resumeAfterApproval(route envelopes.Route, callStack []envelopes.CallFrame) (envelopes.Route, []envelopes.CallFrame, error) {
+	resumeRoute, resumeStack, err := r.resolveCallStack(callStack, route.IncrementCurrent(), nil)
+	if err != nil {
+		return envelopes.Route{}, nil, err
+	}
+	if next := resumeRoute.GetCurrentActor(); next == "" || next == r.cfg.ApprovalActor {
+		return envelopes.Route{}, nil, fmt.Errorf("%s must be followed by an actor", r.cfg.ApprovalActor)
+	}
+	return resumeRoute, resumeStack, nil
+}
+
+// checkApprovalStep rejects routing to an approval step that cannot be parked: the gateway parks
+// envelopes, so status reporting must be enabled, and it resumes them at the next actor
+func (r *Router) checkApprovalStep(route envelopes.Route, callStack []envelopes.CallFrame) error {
+	if !r.isApprovalStep(route) {
+		return nil
+	}
+	if !r.reportsStatus() {
+		return fmt.Errorf("%s requires ASYA_GATEWAY_URL or ASYA_STATUS_QUEUE", r.cfg.ApprovalActor)
+	}
+	_, _, err := r.resumeAfterApproval(route, callStack)
+	return err
+}
+
+// requestApproval parks an envelope routed to the approval step in the gateway instead of sending it
+// to a queue. The gateway sends it to the next actor once approved, signing it then.
+func (r *Router) requestApproval(ctx context.Context, source *envelopes.Envelope, id string, parentID *string, route envelopes.Route, callStack []envelopes.CallFrame, payload json.RawMessage) error {
+	resumeRoute, resumeStack, err := r.resumeAfterApproval(route, callStack)
+	if err != nil {
+		return err
+	}
+
+	parked := envelopes.Envelope{
+		ID:       id,
+		ParentID: parentID,
+		Route:    resumeRoute,
+		Payload:  payload,
+		HopCount: source.HopCount,
+		Visited:  source.Visited,
+		MaxHops:  source.MaxHops,
+		Hops:     source.Hops,
+
+		CallStack: resumeStack,
+	}
+	r.recordHop(ctx, &parked, statusSucceeded)
+
+	if err := r.encryptForRouting(ctx, &parked); err != nil {
+		return err
+	}
+	// The gateway verifies the parked envelope before parking and resuming it
+	if err := r.signEnvelope(ctx, &parked); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(approvalRequest{Envelope: parked, Step: route.GetCurrentActor()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval request: %w", err)
+	}
+
+	slog.Info("Parking envelope for approval", "id", id, "step", route.GetCurrentActor(), "next_actor", resumeRoute.GetCurrentActor())
+	if err := r.reportApproval(ctx, id, body); err != nil {
+		slog.Error("Failed to park envelope for approval", "id", id, "error", err)
+		return fmt.Errorf("failed to request approval: %w", err)
+	}
+	return nil
+}
+
+// sendToHappyQueue sends the original message to the happy-end queue
+func (r *Router) sendToHappyQueue(ctx context.Context, message envelopes.Envelope) error {
+	r.recordHop(ctx, &message, statusSucceeded)
+
+	if err := r.signEnvelope(ctx, &message); err != nil {
+		return err
+	}
+
+	envelopeBody, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope for happy-end: %w", err)
+	}
+
+	// Record envelope size
+	if r.metrics != nil {
+		r.metrics.RecordMessageSize("sent", len(envelopeBody))
+	}
+
+	// Send to happy-end queue
+	sendStart := time.Now()
+	happyQueueName := r.resolveQueueName(r.happyEndQueue)
+	err = r.transport.Send(ctx, happyQueueName, envelopeBody)
+	sendDuration := time.Since(sendStart)
+
+	// Record metrics
+	if r.metrics != nil {
+		r.metrics.RecordQueueSendDuration(r.happyEndQueue, r.cfg.TransportType, sendDuration)
+		if err == nil {
+			r.metrics.RecordMessageSent(r.happyEndQueue, "happy_end")
+		}
+	}
+
+	return err
+}
+
+// sendToErrorQueue sends an error message to the error-end queue. If actors that completed before the
+// failure declare compensating actors, the error goes to the first of them instead (see processCompensationEnvelope).
+func (r *Router) sendToErrorQueue(ctx context.Context, originalBody []byte, errorMsg string, errorDetails ...runtime.ErrorDetails) error {
+	// Parse original message to extract id, parent_id, and route
+	var originalMsg envelopes.Envelope
+	errorEnvelope := envelopes.Envelope{
+		Route: envelopes.Route{Actors: []string{"error-end"}, Current: 0},
+	}
+	if err := json.Unmarshal(originalBody, &originalMsg); err == nil {
+		errorEnvelope.ID = originalMsg.ID
+		errorEnvelope.ParentID = originalMsg.ParentID
+		// Preserve original route for traceability
+		if originalMsg.Route.Actors != nil {
+			errorEnvelope.Route = envelopes.Route{Actors: originalMsg.Route.Actors, Current: originalMsg.Route.Current}
+		}
+		errorEnvelope.HopCount = originalMsg.HopCount
+		errorEnvelope.Visited = originalMsg.Visited
+		errorEnvelope.MaxHops = originalMsg.MaxHops
+		errorEnvelope.Hops = originalMsg.Hops
+		errorEnvelope.Headers = forwardedHeaders(originalMsg.Headers)
+	}
+
+	if originalMsg.Compensation != nil {
+		// A compensating actor could not process the envelope: skip it, keeping the original error
+		slog.Warn("Skipping compensating actor", "id", originalMsg.ID, "actor", r.actorName, "error", errorMsg)
+		return r.continueCompensation(ctx, originalMsg, false)
+	}
+
+	r.recordHop(ctx, &errorEnvelope, statusFailed)
+
+	// Build proper envelope structure with error in payload
+	errorPayload := map[string]any{
+		"error": errorMsg,
+	}
+
+	// Add error details to payload
+	if len(errorDetails) > 0 {
+		errorPayload["details"] = errorDetails[0]
+	}
+
+	// Preserve original payload if available, decrypted so compensating actors can read it
+	// (the error payload is encrypted as a whole below)
+	// Unmarshal json.RawMessage to actual object so it serializes correctly
+	if originalMsg.Payload != nil {
+		originalPayloadJSON := originalMsg.Payload
+		if _, ok := originalMsg.Headers[encryption.KeyIDHeader]; ok && r.encryptor != nil {
+			decrypted, err := r.encryptor.DecryptPayload(ctx, originalMsg.Payload)
+			if err != nil {
+				slog.Warn("Failed to decrypt original payload for error envelope, keeping it encrypted", "id", originalMsg.ID, "error", err)
+			} else {
+				originalPayloadJSON = decrypted
+			}
+		}
+		var originalPayload any
+		if err := json.Unmarshal(originalPayloadJSON, &originalPayload); err == nil {
+			errorPayload["original_payload"] = originalPayload
+		}
+	}
+
+	payload, err := json.Marshal(errorPayload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal error payload: %w", err)
+	}
+	errorEnvelope.Payload = payload
+
+	// Compensating actors and error-end decrypt it with the key ID this sets
+	if err := r.encryptForRouting(ctx, &errorEnvelope); err != nil {
+		return err
+	}
+
+	if compensators := r.compensators(&originalMsg); len(compensators) > 0 {
+		slog.Info("Running compensating actors before error-end", "id", originalMsg.ID, "compensators", compensators)
+		errorEnvelope.Compensation = &envelopes.Compensation{Route: errorEnvelope.Route}
+		errorEnvelope.Route = envelopes.Route{Actors: compensators, Current: 0, Metadata: originalMsg.Route.Metadata}
+		return r.sendEnvelopeTo(ctx, &errorEnvelope, compensators[0], "compensation")
+	}
+
+	return r.sendEnvelopeTo(ctx, &errorEnvelope, r.errorEndQueue, "error_end")
+}
+
+// rejectEnvelope sends an envelope that could not be parsed, verified or decrypted to error-end as a plain
+// error envelope. Its route metadata and markers are not trusted, so it never starts or continues compensation.
+// It is signed only when verified is set (the envelope passed signature verification); unverified envelopes go
+// to error-end unsigned, where they are logged and dropped without reporting status. End actors drop them
+// directly, since sending them to error-end would loop.
+func (r *Router) rejectEnvelope(ctx context.Context, originalBody []byte, errorMsg string, verified bool) error {
+	var originalMsg envelopes.Envelope
+	_ = json.Unmarshal(originalBody, &originalMsg)
+
+	if r.cfg.IsEndActor {
+		slog.Warn("Dropping rejected envelope in end actor", "id", originalMsg.ID, "actor", r.actorName, "error", errorMsg)
+		return nil
+	}
+
+	errorPayload := map[string]any{"error": errorMsg}
+	var originalPayload any
+	if originalMsg.Payload != nil && json.Unmarshal(originalMsg.Payload, &originalPayload) == nil {
+		errorPayload["original_payload"] = originalPayload
+	}
+	payload, err := json.Marshal(errorPayload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal error payload: %w", err)
+	}
+
+	errorEnvelope := envelopes.Envelope{
+		ID:       originalMsg.ID,
+		ParentID: originalMsg.ParentID,
+		Route:    envelopes.Route{Actors: []string{r.errorEndQueue}, Current: 0},
+		Payload:  payload,
+	}
+	if verified {
+		errorEnvelope.Route = envelopes.Route{Actors: originalMsg.Route.Actors, Current: originalMsg.Route.Current}
+		errorEnvelope.HopCount = originalMsg.HopCount
+		errorEnvelope.Visited = originalMsg.Visited
+		errorEnvelope.MaxHops = originalMsg.MaxHops
+		errorEnvelope.Hops = originalMsg.Hops
+		r.recordHop(ctx, &errorEnvelope, statusFailed)
+		return r.sendEnvelopeTo(ctx, &errorEnvelope, r.errorEndQueue, "error_end")
+	}
+
+	envelopeBody, err := json.Marshal(errorEnvelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal error_end message: %w", err)
+	}
+	if r.metrics != nil {
+		r.metrics.RecordMessageSize("sent", len(envelopeBody))
+	}
+	err = r.transport.Send(ctx, r.resolveQueueName(r.errorEndQueue), envelopeBody)
+	if r.metrics != nil && err == nil {
+		r.metrics.RecordMessageSent(r.errorEndQueue, "error_end")
+	}
+	return err
+}
+
+// compensators returns the compensating actors of the actors that completed before envelope failed, most
+// recent first, as set in its route metadata. Versions of a logical actor fall back to the logical actor's.
+func (r *Router) compensators(envelope *envelopes.Envelope) []string {
+	var compensators []string
+	for i := len(envelope.Visited) - 1; i >= 0; i-- {
+		actor := envelope.Visited[i]
+		compensator := envelope.Route.GetCompensator(actor)
+		if compensator == "" {
+			if logicalName := r.cfg.ActorVersions.LogicalName(actor); logicalName != "" {
+				compensator = envelope.Route.GetCompensator(logicalName)
+			}
+		}
+		if compensator == "" {
+			continue
+		}
+		if err := r.checkTargetNamespace(compensator); err != nil {
+			slog.Warn("Skipping compensating actor", "id", envelope.ID, "actor", actor, "compensator", compensator, "error", err)
+			continue
+		}
+		compensators = append(compensators, compensator)
+	}
+	return compensators
+}
+
+// processCompensationEnvelope runs this actor as a compensating actor of a failed envelope. The runtime
+// gets the error payload and its response is dropped. Compensation is best effort: a failed runtime is
+// recorded in the envelope and the remaining compensating actors still run.
+func (r *Router) processCompensationEnvelope(ctx context.Context, envelope envelopes.Envelope, msgBody []byte, hop *envelopes.Hop, startTime time.Time) error {
+	r.reportCompensation(ctx, &envelope, progress.StatusProcessing, fmt.Sprintf("Compensating in %s", r.actorName))
+
+	runtimeBody, err := r.decryptForRuntime(ctx, envelope, msgBody)
+	if err != nil {
+		err = fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+
+	timeout := r.cfg.Timeout
+	isTimeout := false
+	if err == nil {
+		hopTimeout := r.hopTimeout(&envelope)
+		if hopTimeout > 0 {
+			timeout = hopTimeout
+		}
+
+		slog.Info("Calling runtime to compensate", "id", envelope.ID, "actor", r.cfg.ActorName, "timeout", timeout)
+		runtimeStart := time.Now()
+		var responses []runtime.RuntimeResponse
+		responses, err = r.runtimeClient.CallRuntimeWithTimeout(ctx, runtimeBody, hopTimeout)
+		runtimeDuration := time.Since(runtimeStart)
+
+		hopStart := runtimeStart.UTC()
+		hop.StartedAt = &hopStart
+		hop.RuntimeDurationMs = runtimeDuration.Milliseconds()
+
+		if r.metrics != nil {
+			r.metrics.RecordRuntimeDuration(r.actorName, runtimeDuration)
+		}
+
+		isTimeout = errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded)
+		if isTimeout {
+			err = fmt.Errorf("runtime timeout exceeded after %s", timeout)
+		}
+		for _, response := range r.recordRuntimeMetrics(envelope.ID, responses) {
+			if err == nil && response.IsError() {
+				err = errors.New(response.Error)
+			}
+		}
+	}
+
+	if err != nil {
+		slog.Error("Compensating actor failed", "id", envelope.ID, "actor", r.actorName, "error", err)
+		if r.metrics != nil {
+			r.metrics.RecordMessageFailed(r.actorName, "compensation_error")
+		}
+		r.reportCompensation(ctx, &envelope, progress.StatusCompleted, fmt.Sprintf("Compensation failed in %s: %v", r.actorName, err))
+	} else {
+		if r.metrics != nil {
+			r.metrics.RecordMessageProcessed(r.actorName, "compensated")
+		}
+		r.reportCompensation(ctx, &envelope, progress.StatusCompleted, fmt.Sprintf("Compensated in %s", r.actorName))
+	}
+	if r.metrics != nil {
+		r.metrics.RecordProcessingDuration(r.actorName, time.Since(startTime))
+	}
+
+	sendErr := r.continueCompensation(ctx, envelope, err == nil)
+	if isTimeout {
+		if sendErr != nil {
+			slog.Error("Failed to send compensation envelope - exiting anyway", "error", sendErr)
+		}
+		slog.Error("Exiting to prevent zombie processing (runtime may still be working)")
+		os.Exit(1)
+	}
+	return sendErr
+}
+
+// continueCompensation sends envelope on from this compensating actor: to the next actor of the compensation
+// route, or to error-end with the route the envelope failed on once the compensation route is exhausted
+func (r *Router) continueCompensation(ctx context.Context, envelope envelopes.Envelope, succeeded bool) error {
+	outcome := statusSucceeded
+	next := *envelope.Compensation
+	if !succeeded {
+		outcome = statusFailed
+		next.Failed = append(slices.Clone(next.Failed), r.actorName)
+	}
+	envelope.Compensation = &next
+	r.recordHop(ctx, &envelope, outcome)
+
+	envelope.Route = envelope.Route.IncrementCurrent()
+	for nextActor := envelope.Route.GetCurrentActor(); nextActor != ""; nextActor = envelope.Route.GetCurrentActor() {
+		// The compensation route was filtered by the failed actor's sidecar, whose allowed namespaces may differ
+		if err := r.checkTargetNamespace(nextActor); err != nil {
+			slog.Warn("Skipping compensating actor", "id", envelope.ID, "compensator", nextActor, "error", err)
+			next.Failed = append(slices.Clone(next.Failed), nextActor)
+			envelope.Route = envelope.Route.IncrementCurrent()
+			continue
+		}
+		return r.sendEnvelopeTo(ctx, &envelope, nextActor, "compensation")
+	}
+
+	slog.Info("Compensation finished, routing to error-end", "id", envelope.ID, "failed_compensations", next.Failed)
+	envelope.Route = next.Route
+	return r.sendEnvelopeTo(ctx, &envelope, r.errorEndQueue, "error_end")
+}
+
+// reportCompensation reports progress of this compensating actor to the gateway
+func (r *Router) reportCompensation(ctx context.Context, envelope *envelopes.Envelope, status progress.ProgressStatus, message string) {
+	if !r.reportsStatus() {
+		return
+	}
+	_ = r.reportProgress(ctx, envelope.ID, progress.ProgressUpdate{
+		Actors:          envelope.Route.Actors,
+		CurrentActorIdx: envelope.Route.Current,
+		Status:          status,
+		HopCount:        envelope.HopCount,
+		Message:         message,
+		Compensating:    true,
+	})
+}
+
+// sendEnvelopeTo signs envelope and sends it to the queue of actorRef, recording it as messageType
+func (r *Router) sendEnvelopeTo(ctx context.Context, envelope *envelopes.Envelope, actorRef, messageType string) error {
+	queueName, err := r.targetQueueName(actorRef)
+	if err != nil {
+		return err
+	}
+	if err := r.signEnvelope(ctx, envelope); err != nil {
+		return err
+	}
+
+	envelopeBody, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message: %w", messageType, err)
 	}
 
 	// Record envelope size
@@ -514,17 +1406,15 @@ func (r *Router) sendToErrorQueue(ctx context.Context, originalBody []byte, erro
 		r.metrics.RecordMessageSize("sent", len(envelopeBody))
 	}
 
-	// Send to error queue
 	sendStart := time.Now()
-	errorQueueName := r.resolveQueueName(r.errorEndQueue)
-	err = r.transport.Send(ctx, errorQueueName, envelopeBody)
+	err = r.transport.Send(ctx, queueName, envelopeBody)
 	sendDuration := time.Since(sendStart)
 
 	// Record metrics
 	if r.metrics != nil {
-		r.metrics.RecordQueueSendDuration(r.errorEndQueue, r.cfg.TransportType, sendDuration)
+		r.metrics.RecordQueueSendDuration(actorRef, r.cfg.TransportType, sendDuration)
 		if err == nil {
-			r.metrics.RecordMessageSent(r.errorEndQueue, "error_end")
+			r.metrics.RecordMessageSent(actorRef, messageType)
 		}
 	}
 
@@ -535,7 +1425,7 @@ func (r *Router) sendToErrorQueue(ctx context.Context, originalBody []byte, erro
 // This is called by end actors (happy-end, error-end) after processing
 // It has access to both the envelope (with route) and the result payload
 func (r *Router) reportFinalStatusWithEnvelope(ctx context.Context, envelope *envelopes.Envelope, resultPayload json.RawMessage, duration time.Duration) error {
-	if r.progressReporter == nil {
+	if !r.reportsStatus() {
 		return nil
 	}
 
@@ -561,9 +1451,17 @@ func (r *Router) reportFinalStatusWithEnvelope(ctx context.Context, envelope *en
 	case r.errorEndQueue:
 		status = statusFailed
 		// For error-end, extract error info from envelope.Payload (not result)
-		// The envelope.Payload contains error details set by sendToErrorQueue
+		// The envelope.Payload contains error details set by sendToErrorQueue, encrypted if encryption is on
+		errorPayload := envelope.Payload
+		if _, ok := envelope.Headers[encryption.KeyIDHeader]; ok && r.encryptor != nil {
+			if decrypted, err := r.encryptor.DecryptPayload(ctx, envelope.Payload); err == nil {
+				errorPayload = decrypted
+			} else {
+				slog.Warn("Failed to decrypt error payload, reporting without error details", "id", envelope.ID, "error", err)
+			}
+		}
 		var envelopePayload interface{}
-		if err := json.Unmarshal(envelope.Payload, &envelopePayload); err == nil {
+		if err := json.Unmarshal(errorPayload, &envelopePayload); err == nil {
 			if payloadMap, ok := envelopePayload.(map[string]interface{}); ok {
 				if err, ok := payloadMap["error"].(string); ok {
 					errorMsg = err
@@ -593,6 +1491,12 @@ func (r *Router) reportFinalStatusWithEnvelope(ctx context.Context, envelope *en
 		"status":    status,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
+	if len(envelope.Hops) > 0 {
+		finalPayload["hops"] = envelope.Hops
+	}
+	if envelope.Compensation != nil && len(envelope.Compensation.Failed) > 0 {
+		finalPayload["compensation_failed"] = envelope.Compensation.Failed
+	}
 
 	if status == statusSucceeded {
 		finalPayload["progress"] = 1.0
@@ -618,6 +1522,14 @@ func (r *Router) reportFinalStatusWithEnvelope(ctx context.Context, envelope *en
 		}
 	}
 
+	if r.statusPublisher != nil {
+		if err := r.statusPublisher.ReportFinal(ctx, envelope.ID, finalPayload); err != nil {
+			return err
+		}
+		slog.Info("Published final status to status queue", "id", envelope.ID, "status", status)
+		return nil
+	}
+
 	// Send to gateway
 	payloadBytes, err := json.Marshal(finalPayload)
 	if err != nil {
@@ -655,7 +1567,7 @@ func (r *Router) reportFinalStatusWithEnvelope(ctx context.Context, envelope *en
 // reportFinalStatus reports final envelope status to gateway (legacy version without envelope context)
 // Deprecated: Use reportFinalStatusWithEnvelope instead
 func (r *Router) reportFinalStatus(ctx context.Context, envelopeID string, resultPayload json.RawMessage, duration time.Duration) error {
-	if r.progressReporter == nil {
+	if !r.reportsStatus() {
 		return nil
 	}
 
@@ -748,6 +1660,14 @@ func (r *Router) reportFinalStatus(ctx context.Context, envelopeID string, resul
 		}
 	}
 
+	if r.statusPublisher != nil {
+		if err := r.statusPublisher.ReportFinal(ctx, envelopeID, finalPayload); err != nil {
+			return err
+		}
+		slog.Info("Published final status to status queue", "id", envelopeID, "status", status)
+		return nil
+	}
+
 	// Send to gateway
 	payloadBytes, err := json.Marshal(finalPayload)
 	if err != nil {
@@ -782,20 +1702,35 @@ func (r *Router) reportFinalStatus(ctx context.Context, envelopeID string, resul
 	return nil
 }
 
-// resolveQueueName resolves an actor name to a queue name based on transport type
-func (r *Router) resolveQueueName(actorName string) string {
+// resolveQueueName resolves an actor reference to a queue name based on transport type.
+// Qualified references ("namespace/actor") resolve to the queue in that namespace.
+func (r *Router) resolveQueueName(actorRef string) string {
 	switch r.cfg.TransportType {
-	case "rabbitmq", "sqs":
-		// Both RabbitMQ and SQS use asya-{namespace}-{actor} naming convention
-		return fmt.Sprintf("asya-%s-%s", r.cfg.Namespace, actorName)
+	case "rabbitmq", "sqs", "file", "postgres", "redis", "pubsub":
+		// All built-in transports name queues with the configured scheme (asya-{namespace}-{actor} by default)
+		namespace, actorName := envelopes.SplitActorRef(actorRef, r.cfg.Namespace)
+		return r.cfg.QueueNaming.QueueName(namespace, actorName)
 	default:
-		return actorName
+		return actorRef
+	}
+}
+
+// targetQueueName resolves the queue of an actor the sidecar sends an envelope to. Every send to an
+// actor reference taken from a route or configuration goes through it, so actors in namespaces that
+// do not accept envelopes from this one are never reached (see checkTargetNamespace).
+func (r *Router) targetQueueName(actorRef string) (string, error) {
+	if err := r.checkTargetNamespace(actorRef); err != nil {
+		return "", err
 	}
+	return r.resolveQueueName(actorRef), nil
 }
 
 // createFanoutEnvelope creates a fanout child envelope in the gateway
 // Fanout children use the same route state as the parent after runtime processing
 func (r *Router) createFanoutEnvelope(ctx context.Context, id, parentID string, route envelopes.Route) error {
+	if r.statusPublisher != nil {
+		return r.statusPublisher.CreateEnvelope(ctx, id, parentID, route.Actors, route.Current)
+	}
 	return r.progressReporter.CreateEnvelope(ctx, id, parentID, route.Actors, route.Current)
 }
 
@@ -809,79 +1744,488 @@ func (r *Router) CheckGatewayHealth(ctx context.Context) error {
 	return r.progressReporter.CheckHealth(ctx)
 }
 
-// Run starts the message processing loop
+// SetEncryptor enables payload encryption
+func (r *Router) SetEncryptor(encryptor *encryption.Encryptor) {
+	r.encryptor = encryptor
+}
+
+// SetSigner enables envelope signature verification on receive and signing on send
+func (r *Router) SetSigner(signer *signing.Signer) {
+	r.signer = signer
+}
+
+// signEnvelope signs an envelope before it is sent when signing is enabled
+func (r *Router) signEnvelope(ctx context.Context, envelope *envelopes.Envelope) error {
+	if r.signer == nil {
+		return nil
+	}
+	if err := r.signer.Sign(ctx, envelope); err != nil {
+		return fmt.Errorf("failed to sign envelope: %w", err)
+	}
+	return nil
+}
+
+// checkRoutePolicy validates the route returned by the runtime against the actor's route policy.
+// inputRoute is the route the envelope arrived with and is used to detect inserted actors.
+func (r *Router) checkRoutePolicy(inputRoute, outputRoute envelopes.Route) error {
+	policy := r.cfg.RoutePolicy
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MaxRouteLength > 0 && len(outputRoute.Actors) > policy.MaxRouteLength {
+		return fmt.Errorf("route length %d exceeds maximum %d", len(outputRoute.Actors), policy.MaxRouteLength)
+	}
+
+	// An exhausted route goes to happy-end, which is always allowed
+	if next := outputRoute.GetCurrentActor(); next != "" && len(policy.AllowedNext) > 0 && !slices.Contains(policy.AllowedNext, next) {
+		return fmt.Errorf("next actor %q is not in allowed next actors %v", next, policy.AllowedNext)
+	}
+
+	if len(policy.ForbiddenInsertions) > 0 {
+		inputCounts := make(map[string]int, len(inputRoute.Actors))
+		for _, actor := range inputRoute.Actors {
+			inputCounts[actor]++
+		}
+		outputCounts := make(map[string]int, len(outputRoute.Actors))
+		for _, actor := range outputRoute.Actors {
+			outputCounts[actor]++
+			if outputCounts[actor] <= inputCounts[actor] {
+				continue
+			}
+			if slices.Contains(policy.ForbiddenInsertions, "*") || slices.Contains(policy.ForbiddenInsertions, actor) {
+				return fmt.Errorf("insertion of actor %q is forbidden", actor)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveCall returns the route a handler calls, looking up named routes in ASYA_NAMED_ROUTES
+func (r *Router) resolveCall(call *envelopes.Call) (*envelopes.Route, error) {
+	if call == nil {
+		return nil, nil
+	}
+	if call.Name == "" {
+		return &envelopes.Route{Actors: call.Actors, Current: call.Current}, nil
+	}
+	if len(call.Actors) > 0 {
+		return nil, fmt.Errorf("call sets both name %q and actors %v", call.Name, call.Actors)
+	}
+	actors, ok := r.cfg.NamedRoutes[call.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown named route %q", call.Name)
+	}
+	return &envelopes.Route{Actors: slices.Clone(actors), Current: call.Current}, nil
+}
+
+// resolveCallStack applies sub-route call/return semantics to a handler's output route. A call pushes
+// the output route as the caller's continuation and routes to the called actors. An exhausted route
+// returns to the innermost continuation instead of going to happy-end.
+func (r *Router) resolveCallStack(callStack []envelopes.CallFrame, route envelopes.Route, call *envelopes.Route) (envelopes.Route, []envelopes.CallFrame, error) {
+	if call != nil {
+		if call.GetCurrentActor() == "" {
+			return envelopes.Route{}, nil, fmt.Errorf("called route %v has no actor at index %d", call.Actors, call.Current)
+		}
+		callStack = append(slices.Clone(callStack), envelopes.CallFrame{Caller: r.actorName, Route: route})
+		route = *call
+	}
+
+	for route.GetCurrentActor() == "" && len(callStack) > 0 {
+		frame := callStack[len(callStack)-1]
+		callStack = callStack[:len(callStack)-1]
+		slog.Debug("Sub-route finished, returning to caller", "caller", frame.Caller, "route", frame.Route)
+		route = frame.Route
+	}
+	if len(callStack) == 0 {
+		callStack = nil
+	}
+	return route, callStack, nil
+}
+
+// startHop begins the hop record for a received message and stores it in the returned context,
+// so every envelope sent while processing the message carries it (see recordHop)
+func (r *Router) startHop(ctx context.Context, msg transport.QueueMessage, receivedAt time.Time) (context.Context, *envelopes.Hop) {
+	attempt := 1
+	if deliveries, err := strconv.Atoi(msg.Headers["Deliveries"]); err == nil && deliveries > 0 {
+		attempt = deliveries
+	}
+
+	hop := &envelopes.Hop{
+		Actor:      r.actorName,
+		Pod:        r.cfg.PodName,
+		ReceivedAt: receivedAt.UTC(),
+		Attempt:    attempt,
+	}
+	return context.WithValue(ctx, hopKey{}, hop), hop
+}
+
+// recordHop advances the hop counters of an envelope leaving this actor and appends the hop record
+// of the message being processed with the given outcome
+func (r *Router) recordHop(ctx context.Context, envelope *envelopes.Envelope, outcome string) {
+	now := time.Now().UTC()
+	hop := envelopes.Hop{Actor: r.actorName, Pod: r.cfg.PodName, ReceivedAt: now, Attempt: 1}
+	if started, ok := ctx.Value(hopKey{}).(*envelopes.Hop); ok {
+		hop = *started
+	}
+	hop.FinishedAt = now
+	hop.Outcome = outcome
+
+	envelope.HopCount++
+	envelope.Visited = append(slices.Clone(envelope.Visited), r.actorName)
+	envelope.Hops = append(slices.Clone(envelope.Hops), hop)
+}
+
+// checkHopLimit rejects routing to another actor once the envelope has been processed by the maximum
+// number of actors. The limit is the envelope's max_hops (set per tool by the gateway), falling back to
+// ASYA_MAX_HOPS. Routing to happy-end is always allowed.
+func (r *Router) checkHopLimit(envelope *envelopes.Envelope, outputRoute envelopes.Route) error {
+	maxHops := envelope.MaxHops
+	if maxHops <= 0 {
+		maxHops = r.cfg.MaxHops
+	}
+	if maxHops <= 0 || outputRoute.GetCurrentActor() == "" {
+		return nil
+	}
+
+	if hops := envelope.HopCount + 1; hops >= maxHops {
+		return fmt.Errorf("envelope processed by %d actors, routing to %q would exceed max hops %d (visited: %v)",
+			hops, outputRoute.GetCurrentActor(), maxHops, append(slices.Clone(envelope.Visited), r.actorName))
+	}
+	return nil
+}
+
+// checkTargetNamespace rejects sending to an actor in another namespace unless that namespace is in
+// ASYA_ALLOWED_TARGET_NAMESPACES, which the operator renders from target namespaces that opted in.
+// The actor's own namespace and an empty reference (the end of a route, i.e. happy-end) are always allowed.
+func (r *Router) checkTargetNamespace(actorRef string) error {
+	if actorRef == "" {
+		return nil
+	}
+
+	namespace, _ := envelopes.SplitActorRef(actorRef, r.cfg.Namespace)
+	if namespace == r.cfg.Namespace || slices.Contains(r.cfg.AllowedTargetNamespaces, namespace) {
+		return nil
+	}
+	return fmt.Errorf("namespace %q of actor %q does not accept envelopes from namespace %q", namespace, actorRef, r.cfg.Namespace)
+}
+
+// hopTimeout returns the current actor's runtime timeout from the route metadata, or 0 if the route
+// sets none. A version of a logical actor falls back to the logical actor's timeout. Longer timeouts
+// are capped by the runtime client's ASYA_RUNTIME_TIMEOUT (the operator's spec.timeout.processing),
+// so they return 0 as well.
+func (r *Router) hopTimeout(envelope *envelopes.Envelope) time.Duration {
+	timeout, err := envelope.Route.GetCurrentTimeout()
+	if err == nil && timeout == 0 {
+		if logicalName := r.cfg.ActorVersions.LogicalName(r.actorName); logicalName != "" {
+			timeout, err = envelope.Route.GetTimeout(logicalName)
+		}
+	}
+	if err != nil {
+		slog.Warn("Ignoring invalid per-hop timeout", "id", envelope.ID, "error", err)
+		return 0
+	}
+	if r.cfg.Timeout > 0 && timeout >= r.cfg.Timeout {
+		return 0
+	}
+	return timeout
+}
+
+// decryptForRuntime returns the message body to send to the runtime: unchanged for plaintext
+// envelopes, otherwise with the payload decrypted and the key ID header removed
+func (r *Router) decryptForRuntime(ctx context.Context, envelope envelopes.Envelope, msgBody []byte) ([]byte, error) {
+	if _, ok := envelope.Headers[encryption.KeyIDHeader]; !ok {
+		return msgBody, nil
+	}
+	if r.encryptor == nil {
+		return nil, fmt.Errorf("payload is encrypted but encryption is not configured")
+	}
+
+	payload, err := r.encryptor.DecryptPayload(ctx, envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Patch the original message so fields unknown to the sidecar reach the runtime unchanged
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(msgBody, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	message["payload"] = payload
+
+	headers := make(map[string]interface{}, len(envelope.Headers))
+	for k, v := range envelope.Headers {
+		if k != encryption.KeyIDHeader {
+			headers[k] = v
+		}
+	}
+	if len(headers) == 0 {
+		delete(message, "headers")
+	} else {
+		encoded, err := json.Marshal(headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal headers: %w", err)
+		}
+		message["headers"] = encoded
+	}
+
+	return json.Marshal(message)
+}
+
+// encryptResult encrypts an end actor result before it is reported to the gateway.
+// A result that cannot be encrypted is dropped rather than reported in plaintext.
+func (r *Router) encryptResult(ctx context.Context, envelopeID string, result json.RawMessage) json.RawMessage {
+	if r.encryptor == nil || encryption.IsEncrypted(result) {
+		return result
+	}
+	encrypted, _, err := r.encryptor.EncryptPayload(ctx, result)
+	if err != nil {
+		slog.Error("Failed to encrypt result, reporting without it", "id", envelopeID, "error", err)
+		return nil
+	}
+	return encrypted
+}
+
+// recordRuntimeMetrics records custom metrics emitted by the runtime and returns
+// the responses without metrics-only entries. Invalid observations are logged and skipped.
+func (r *Router) recordRuntimeMetrics(envelopeID string, responses []runtime.RuntimeResponse) []runtime.RuntimeResponse {
+	routed := responses[:0:0]
+	for _, response := range responses {
+		if r.metrics != nil {
+			for _, obs := range response.Metrics {
+				if err := r.metrics.RecordRuntimeMetric(obs.Name, obs.Value, obs.Labels); err != nil {
+					slog.Warn("Ignoring invalid runtime metric", "id", envelopeID, "metric", obs.Name, "error", err)
+				}
+			}
+		}
+		if !response.IsMetricsOnly() {
+			routed = append(routed, response)
+		}
+	}
+	return routed
+}
+
+// reportsStatus reports whether progress and final status are sent to the gateway, over HTTP or the status queue
+func (r *Router) reportsStatus() bool {
+	return r.progressReporter != nil || r.statusPublisher != nil
+}
+
+// reportProgress sends an intermediate progress update, in the background when async reporting is enabled
+func (r *Router) reportProgress(ctx context.Context, id string, update progress.ProgressUpdate) error {
+	if r.statusPublisher != nil {
+		return r.statusPublisher.ReportProgress(ctx, id, update)
+	}
+	if r.asyncProgress != nil {
+		return r.asyncProgress.ReportProgress(ctx, id, update)
+	}
+	return r.progressReporter.ReportProgress(ctx, id, update)
+}
+
+// reportApproval parks an envelope at an approval step in the gateway
+func (r *Router) reportApproval(ctx context.Context, id string, request json.RawMessage) error {
+	if r.statusPublisher != nil {
+		return r.statusPublisher.RequestApproval(ctx, id, request)
+	}
+	return r.progressReporter.RequestApproval(ctx, id, request)
+}
+
+// reportFinalError reports a final error status for an envelope
+func (r *Router) reportFinalError(ctx context.Context, envelopeID, errorMsg string) error {
+	if r.statusPublisher != nil {
+		return r.statusPublisher.ReportFinalError(ctx, envelopeID, errorMsg)
+	}
+	return r.progressReporter.ReportFinalError(ctx, envelopeID, errorMsg)
+}
+
+// Close sends progress updates still buffered by the asynchronous reporter
+func (r *Router) Close(ctx context.Context) error {
+	if r.asyncProgress == nil {
+		return nil
+	}
+	return r.asyncProgress.Close(ctx)
+}
+
+// Run starts the message processing loop. Transports that receive in batches feed up to
+// MaxBatchSize concurrent workers, receiving only as many messages as there are idle workers.
 func (r *Router) Run(ctx context.Context) error {
 	queueName := r.resolveQueueName(r.actorName)
-	slog.Info("Starting router", "queue", queueName)
+
+	workers := 1
+	if batchReceiver, ok := r.transport.(transport.BatchReceiver); ok {
+		workers = max(batchReceiver.MaxBatchSize(), 1)
+	}
+	slog.Info("Starting router", "queue", queueName, "workers", workers)
+
+	idle := make(chan struct{}, workers)
+	for range workers {
+		idle <- struct{}{}
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
 
 	var consecutiveFailures int
 	const maxBackoff = 30 * time.Second
 
 	for {
+		if ctx.Err() != nil {
+			slog.Info("Router shutting down", "reason", ctx.Err())
+			return ctx.Err()
+		}
+
+		// Wait for an idle worker, then claim all idle workers for the next receive
 		select {
 		case <-ctx.Done():
 			slog.Info("Router shutting down", "reason", ctx.Err())
 			return ctx.Err()
-		default:
-			// Receive message from queue
-			receiveStart := time.Now()
-			queueName := r.resolveQueueName(r.actorName)
-			msg, err := r.transport.Receive(ctx, queueName)
-			receiveDuration := time.Since(receiveStart)
+		case <-idle:
+		}
+		claimed := 1
+	claim:
+		for claimed < workers {
+			select {
+			case <-idle:
+				claimed++
+			default:
+				break claim
+			}
+		}
 
-			if err != nil {
-				consecutiveFailures++
-				exponent := min(consecutiveFailures-1, 5)
-				if exponent < 0 {
-					exponent = 0
-				}
-				var shift uint
-				if exponent >= 0 {
-					shift = uint(exponent)
-				}
-				backoff := time.Duration(1<<shift) * time.Second
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
+		// Receive messages from queue
+		receiveStart := time.Now()
+		msgs, err := r.receive(ctx, queueName, claimed)
+		receiveDuration := time.Since(receiveStart)
 
-				slog.Error("Failed to receive message",
-					"error", err,
-					"consecutiveFailures", consecutiveFailures,
-					"backoffSeconds", backoff.Seconds())
+		for range claimed - len(msgs) {
+			idle <- struct{}{}
+		}
 
-				select {
-				case <-time.After(backoff):
-					continue
-				case <-ctx.Done():
-					return ctx.Err()
-				}
+		if err != nil {
+			consecutiveFailures++
+			exponent := min(consecutiveFailures-1, 5)
+			if exponent < 0 {
+				exponent = 0
+			}
+			var shift uint
+			if exponent >= 0 {
+				shift = uint(exponent)
+			}
+			backoff := time.Duration(1<<shift) * time.Second
+			if backoff > maxBackoff {
+				backoff = maxBackoff
 			}
 
-			consecutiveFailures = 0
+			slog.Error("Failed to receive message",
+				"error", err,
+				"consecutiveFailures", consecutiveFailures,
+				"backoffSeconds", backoff.Seconds())
 
-			slog.Info("Message received from queue", "msgID", msg.ID, "receiveDuration", receiveDuration)
+			select {
+			case <-time.After(backoff):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		consecutiveFailures = 0
 
-			// Record receive metrics
+		// Record receive metrics
+		if r.metrics != nil {
+			r.metrics.RecordQueueReceiveDuration(r.actorName, r.cfg.TransportType, receiveDuration)
+		}
+
+		for _, msg := range msgs {
+			slog.Info("Message received from queue", "msgID", msg.ID, "receiveDuration", receiveDuration)
 			if r.metrics != nil {
 				r.metrics.RecordMessageReceived(r.actorName, r.cfg.TransportType)
-				r.metrics.RecordQueueReceiveDuration(r.actorName, r.cfg.TransportType, receiveDuration)
 			}
 
-			// Process envelope
-			slog.Info("Processing envelope", "msgID", msg.ID)
-			if err := r.ProcessEnvelope(ctx, msg); err != nil {
-				slog.Error("Envelope processing failed", "msgID", msg.ID, "error", err)
-				// NACK the envelope for retry
-				if nackErr := r.transport.Nack(ctx, msg); nackErr != nil {
-					slog.Error("Failed to NACK envelope", "msgID", msg.ID, "error", nackErr)
-				}
-				continue
-			}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					idle <- struct{}{}
+					wg.Done()
+				}()
+				r.handleMessage(ctx, msg)
+			}()
+		}
+	}
+}
+
+// receive receives up to limit messages, in one call if the transport receives in batches
+func (r *Router) receive(ctx context.Context, queueName string, limit int) ([]transport.QueueMessage, error) {
+	if batchReceiver, ok := r.transport.(transport.BatchReceiver); ok {
+		return batchReceiver.ReceiveBatch(ctx, queueName, limit)
+	}
+	msg, err := r.transport.Receive(ctx, queueName)
+	if err != nil {
+		return nil, err
+	}
+	return []transport.QueueMessage{msg}, nil
+}
+
+// handleMessage processes a received message and acks it, or nacks it for redelivery if processing failed
+func (r *Router) handleMessage(ctx context.Context, msg transport.QueueMessage) {
+	stopHeartbeat := r.startHeartbeat(ctx, msg)
+
+	slog.Info("Processing envelope", "msgID", msg.ID)
+	err := r.ProcessEnvelope(ctx, msg)
+	stopHeartbeat()
 
-			// ACK the envelope on success
-			if err := r.transport.Ack(ctx, msg); err != nil {
-				slog.Error("Failed to ACK envelope", "msgID", msg.ID, "error", err)
+	if err != nil {
+		slog.Error("Envelope processing failed", "msgID", msg.ID, "error", err)
+		// NACK the envelope for retry
+		if nackErr := r.transport.Nack(ctx, msg); nackErr != nil {
+			slog.Error("Failed to NACK envelope", "msgID", msg.ID, "error", nackErr)
+		}
+		return
+	}
+
+	// ACK the envelope on success
+	if err := r.transport.Ack(ctx, msg); err != nil {
+		slog.Error("Failed to ACK envelope", "msgID", msg.ID, "error", err)
+	}
+}
+
+// startHeartbeat extends the visibility timeout of msg every half timeout until the returned stop
+// function is called, so a message processed for longer than the timeout is not redelivered to
+// another consumer. stop waits for an in-flight extension, so it cannot undo a later Nack.
+func (r *Router) startHeartbeat(ctx context.Context, msg transport.QueueMessage) (stop func()) {
+	extender, ok := r.transport.(transport.VisibilityExtender)
+	if !ok {
+		return func() {}
+	}
+	timeout := extender.VisibilityTimeout()
+	if timeout <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := extender.ExtendVisibility(ctx, msg, timeout); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					slog.Warn("Failed to extend message visibility", "msgID", msg.ID, "error", err)
+					continue
+				}
+				slog.Debug("Extended message visibility", "msgID", msg.ID, "timeout", timeout)
 			}
 		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
 	}
 }
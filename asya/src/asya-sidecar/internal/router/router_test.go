@@ -3,7 +3,9 @@ package router
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,15 +13,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/canary"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/config"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/encryption"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/metrics"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/progress"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/runtime"
+	"github.com/deliveryhero/asya/asya-sidecar/internal/signing"
 	"github.com/deliveryhero/asya/asya-sidecar/internal/transport"
 	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
 	"golang.org/x/net/nettest"
@@ -142,6 +151,29 @@ func TestRouter_RouteValidation(t *testing.T) {
 			shouldCallRuntime:    true,
 			expectedDestQueue:    "asya-default-next-actor",
 		},
+		{
+			name:      "route qualified with sidecar namespace - processes normally",
+			actorName: "test-actor",
+			inputRoute: envelopes.Route{
+				Actors:  []string{"default/test-actor", "next-actor"},
+				Current: 0,
+			},
+			shouldRejectAndError: false,
+			shouldCallRuntime:    true,
+			expectedDestQueue:    "asya-default-next-actor",
+		},
+		{
+			name:      "route qualified with other namespace - sends to error queue",
+			actorName: "test-actor",
+			inputRoute: envelopes.Route{
+				Actors:  []string{"team-b/test-actor", "next-actor"},
+				Current: 0,
+			},
+			expectedWarnContains: "Route mismatch: message routed to wrong actor",
+			shouldRejectAndError: true,
+			shouldCallRuntime:    false,
+			expectedDestQueue:    "asya-default-error-end",
+		},
 		{
 			name:      "route does not match sidecar queue - sends to error queue",
 			actorName: "test-actor",
@@ -351,6 +383,77 @@ func TestRouter_ResolveQueueName(t *testing.T) {
 			actorName: "image-processor",
 			expected:  "asya-default-image-processor",
 		},
+		{
+			name:          "file - namespaced directory",
+			transportType: "file",
+			config: &config.Config{
+				TransportType: "file",
+				Namespace:     "default",
+			},
+			actorName: "image-processor",
+			expected:  "asya-default-image-processor",
+		},
+		{
+			name:          "postgres - namespaced queue",
+			transportType: "postgres",
+			config: &config.Config{
+				TransportType: "postgres",
+				Namespace:     "default",
+			},
+			actorName: "image-processor",
+			expected:  "asya-default-image-processor",
+		},
+		{
+			name:          "redis - namespaced queue",
+			transportType: "redis",
+			config: &config.Config{
+				TransportType: "redis",
+				Namespace:     "default",
+			},
+			actorName: "image-processor",
+			expected:  "asya-default-image-processor",
+		},
+		{
+			name:          "pubsub - namespaced queue",
+			transportType: "pubsub",
+			config: &config.Config{
+				TransportType: "pubsub",
+				Namespace:     "default",
+			},
+			actorName: "image-processor",
+			expected:  "asya-default-image-processor",
+		},
+		{
+			name:          "qualified actor in another namespace",
+			transportType: "sqs",
+			config: &config.Config{
+				TransportType: "sqs",
+				Namespace:     "default",
+			},
+			actorName: "team-b/ocr",
+			expected:  "asya-team-b-ocr",
+		},
+		{
+			name:          "qualified actor in own namespace",
+			transportType: "rabbitmq",
+			config: &config.Config{
+				TransportType: "rabbitmq",
+				Namespace:     "default",
+			},
+			actorName: "default/ocr",
+			expected:  "asya-default-ocr",
+		},
+		{
+			name:          "custom naming scheme",
+			transportType: "sqs",
+			config: &config.Config{
+				TransportType: "sqs",
+				Namespace:     "default",
+				QueueNaming:   naming.Scheme{Prefix: "acme", Cluster: "eu1", Suffix: "q"},
+			},
+			actorName: "team-b/ocr",
+			expected:  "acme-eu1-team-b-ocr-q",
+		},
 		{
 			name:          "unknown transport - fallback to identity",
 			transportType: "unknown",
@@ -803,7 +906,7 @@ func TestRouter_SendToHappyQueue(t *testing.T) {
 	}
 }
 
-func TestRouter_SendToErrorQueue(t *testing.T) {
+func TestRouter_HandleRuntimeResponses_CustomMetrics(t *testing.T) {
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
@@ -813,7 +916,9 @@ func TestRouter_SendToErrorQueue(t *testing.T) {
 	}
 
 	mockTransport := &mockTransport{}
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{
+		{Name: "tokens_total", Type: "counter", Help: "Tokens", Labels: []string{"model"}},
+	})
 
 	router := &Router{
 		cfg:           cfg,
@@ -824,171 +929,653 @@ func TestRouter_SendToErrorQueue(t *testing.T) {
 		metrics:       m,
 	}
 
-	originalEnvelope := envelopes.Envelope{
-		ID: "test-envelope-456",
-		Route: envelopes.Route{
-			Actors:  []string{"actor1"},
-			Current: 0,
+	envelope := &envelopes.Envelope{
+		ID:      "test-metrics-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Payload: json.RawMessage(`{}`),
+	}
+	responses := []runtime.RuntimeResponse{
+		{
+			Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1},
+			Payload: json.RawMessage(`{"done": true}`),
+			Metrics: []runtime.MetricObservation{{Name: "tokens_total", Value: 5, Labels: map[string]string{"model": "gpt"}}},
 		},
-		Payload: json.RawMessage(`{"data": "test"}`),
+		// Metrics-only entry is recorded but not routed
+		{Metrics: []runtime.MetricObservation{
+			{Name: "tokens_total", Value: 2, Labels: map[string]string{"model": "gpt"}},
+			{Name: "undeclared", Value: 1},
+		}},
 	}
 
-	originalBody, _ := json.Marshal(originalEnvelope)
-
-	ctx := context.Background()
-	err := router.sendToErrorQueue(ctx, originalBody, "Runtime processing failed")
-	if err != nil {
-		t.Fatalf("sendToErrorQueue failed: %v", err)
+	if err := router.handleRuntimeResponses(context.Background(), envelope, responses, nil, time.Millisecond, time.Now()); err != nil {
+		t.Fatalf("handleRuntimeResponses failed: %v", err)
 	}
 
-	if len(mockTransport.sentMessages) != 1 {
-		t.Fatalf("Expected 1 message sent, got %d", len(mockTransport.sentMessages))
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-next-actor" {
+		t.Fatalf("Expected 1 message to asya-default-next-actor, got %d", len(mockTransport.sentMessages))
 	}
-
-	if mockTransport.sentMessages[0].queue != "asya-default-"+testQueueErrorEnd {
-		t.Errorf("Envelope sent to queue %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+	var sent envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+		t.Fatalf("Failed to unmarshal sent message: %v", err)
 	}
-
-	var errorMsg map[string]any
-	err = json.Unmarshal(mockTransport.sentMessages[0].body, &errorMsg)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal sent error message: %v", err)
+	if sent.ID != "test-metrics-1" {
+		t.Errorf("Expected envelope ID 'test-metrics-1', got %q", sent.ID)
 	}
 
-	if errorMsg["id"] != "test-envelope-456" {
-		t.Errorf("Expected error message ID 'test-envelope-456', got %v", errorMsg["id"])
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `tokens_total{model="gpt"} 7`) {
+		t.Errorf("Expected tokens_total{model=\"gpt\"} 7 in metrics output:\n%s", rec.Body.String())
 	}
+}
 
-	// Error should be inside payload (nested format)
-	payload, ok := errorMsg["payload"].(map[string]any)
-	if !ok {
-		t.Fatalf("Expected payload to be a map, got %T", errorMsg["payload"])
+func TestRouter_HopTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfgTimeout time.Duration
+		timeouts   map[string]interface{}
+		expected   time.Duration
+	}{
+		{
+			name:       "route timeout below runtime timeout",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"test-actor": "30s"},
+			expected:   30 * time.Second,
+		},
+		{
+			name:       "route timeout capped by runtime timeout",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"test-actor": "10m"},
+		},
+		{
+			name:       "no route timeout for this actor",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"other-actor": "30s"},
+		},
+		{
+			name:       "invalid route timeout ignored",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"test-actor": "soon"},
+		},
+		{
+			name:       "version falls back to logical actor timeout",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"logical-actor": "45s"},
+			expected:   45 * time.Second,
+		},
+		{
+			name:       "version timeout takes precedence",
+			cfgTimeout: 5 * time.Minute,
+			timeouts:   map[string]interface{}{"test-actor": "20s", "logical-actor": "45s"},
+			expected:   20 * time.Second,
+		},
 	}
 
-	if payload["error"] != "Runtime processing failed" {
-		t.Errorf("Expected error message 'Runtime processing failed', got %v", payload["error"])
-	}
+	versions := canary.NewVersions(canary.Table{"logical-actor": {Versions: []canary.Version{{Actor: "test-actor", Weight: 1}}}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := &Router{cfg: &config.Config{ActorName: "test-actor", Timeout: tt.cfgTimeout, ActorVersions: versions}, actorName: "test-actor"}
+			envelope := &envelopes.Envelope{
+				ID: "test-timeout-1",
+				Route: envelopes.Route{
+					Actors:   []string{"test-actor", "other-actor"},
+					Metadata: map[string]interface{}{envelopes.TimeoutsMetadataKey: tt.timeouts},
+				},
+			}
 
-	// Original payload should be preserved inside payload
-	originalPayloadBytes, err := json.Marshal(payload["original_payload"])
-	if err != nil {
-		t.Fatalf("Failed to marshal original_payload: %v", err)
+			if got := router.hopTimeout(envelope); got != tt.expected {
+				t.Errorf("hopTimeout() = %v, want %v", got, tt.expected)
+			}
+		})
 	}
-	expectedPayload := `{"data":"test"}`
-	if string(originalPayloadBytes) != expectedPayload {
-		t.Errorf("Expected original_payload %q, got %q", expectedPayload, string(originalPayloadBytes))
+}
+
+func TestRouter_CheckRoutePolicy(t *testing.T) {
+	input := envelopes.Route{Actors: []string{"ingest", "test-actor", "score"}, Current: 1}
+
+	tests := []struct {
+		name    string
+		policy  *config.RoutePolicyConfig
+		output  envelopes.Route
+		wantErr string
+	}{
+		{
+			name:   "no policy",
+			output: envelopes.Route{Actors: []string{"ingest", "test-actor", "billing"}, Current: 2},
+		},
+		{
+			name:   "allowed next actor",
+			policy: &config.RoutePolicyConfig{AllowedNext: []string{"score", "review"}},
+			output: envelopes.Route{Actors: []string{"ingest", "test-actor", "review"}, Current: 2},
+		},
+		{
+			name:    "next actor not allowed",
+			policy:  &config.RoutePolicyConfig{AllowedNext: []string{"score"}},
+			output:  envelopes.Route{Actors: []string{"ingest", "test-actor", "billing"}, Current: 2},
+			wantErr: `next actor "billing" is not in allowed next actors`,
+		},
+		{
+			name:   "exhausted route goes to happy-end",
+			policy: &config.RoutePolicyConfig{AllowedNext: []string{"score"}},
+			output: envelopes.Route{Actors: []string{"ingest", "test-actor"}, Current: 2},
+		},
+		{
+			name:    "route too long",
+			policy:  &config.RoutePolicyConfig{MaxRouteLength: 3},
+			output:  envelopes.Route{Actors: []string{"ingest", "test-actor", "score", "review"}, Current: 2},
+			wantErr: "route length 4 exceeds maximum 3",
+		},
+		{
+			name:    "forbidden insertion",
+			policy:  &config.RoutePolicyConfig{ForbiddenInsertions: []string{"billing"}},
+			output:  envelopes.Route{Actors: []string{"ingest", "test-actor", "score", "billing"}, Current: 2},
+			wantErr: `insertion of actor "billing" is forbidden`,
+		},
+		{
+			name:   "insertion of other actor",
+			policy: &config.RoutePolicyConfig{ForbiddenInsertions: []string{"billing"}},
+			output: envelopes.Route{Actors: []string{"ingest", "test-actor", "review", "score"}, Current: 2},
+		},
+		{
+			name:    "repeated actor counts as insertion",
+			policy:  &config.RoutePolicyConfig{ForbiddenInsertions: []string{"score"}},
+			output:  envelopes.Route{Actors: []string{"ingest", "test-actor", "score", "score"}, Current: 2},
+			wantErr: `insertion of actor "score" is forbidden`,
+		},
+		{
+			name:    "wildcard forbids any insertion",
+			policy:  &config.RoutePolicyConfig{ForbiddenInsertions: []string{"*"}},
+			output:  envelopes.Route{Actors: []string{"ingest", "test-actor", "review", "score"}, Current: 2},
+			wantErr: `insertion of actor "review" is forbidden`,
+		},
+		{
+			name:   "wildcard allows removal",
+			policy: &config.RoutePolicyConfig{ForbiddenInsertions: []string{"*"}},
+			output: envelopes.Route{Actors: []string{"ingest", "test-actor"}, Current: 2},
+		},
 	}
 
-	// Verify route field exists
-	if errorMsg["route"] == nil {
-		t.Error("Expected route field in error envelope")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := &Router{cfg: &config.Config{ActorName: "test-actor", RoutePolicy: tt.policy}}
+
+			err := router.checkRoutePolicy(input, tt.output)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
 	}
 }
 
-func TestRouter_SendToErrorQueue_WithInvalidOriginalMessage(t *testing.T) {
+func TestRouter_HandleRuntimeResponses_RoutePolicyViolation(t *testing.T) {
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
 		HappyEndQueue: "happy-end",
 		ErrorEndQueue: "error-end",
 		TransportType: "rabbitmq",
+		RoutePolicy:   &config.RoutePolicyConfig{AllowedNext: []string{"next-actor"}},
 	}
 
 	mockTransport := &mockTransport{}
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
-
 	router := &Router{
 		cfg:           cfg,
 		transport:     mockTransport,
 		actorName:     cfg.ActorName,
 		happyEndQueue: cfg.HappyEndQueue,
 		errorEndQueue: cfg.ErrorEndQueue,
-		metrics:       m,
 	}
 
-	invalidJSON := []byte(`{invalid json`)
-
-	ctx := context.Background()
-	err := router.sendToErrorQueue(ctx, invalidJSON, "Parse error")
-	if err != nil {
-		t.Fatalf("sendToErrorQueue failed: %v", err)
+	envelope := &envelopes.Envelope{
+		ID:      "test-policy-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Payload: json.RawMessage(`{"value": 1}`),
 	}
-
-	if len(mockTransport.sentMessages) != 1 {
-		t.Fatalf("Expected 1 message sent, got %d", len(mockTransport.sentMessages))
+	msgBody, _ := json.Marshal(envelope)
+	responses := []runtime.RuntimeResponse{
+		{
+			Route:   envelopes.Route{Actors: []string{"test-actor", "other-team-actor"}, Current: 1},
+			Payload: json.RawMessage(`{"value": 2}`),
+		},
 	}
 
-	var errorMsg map[string]any
-	err = json.Unmarshal(mockTransport.sentMessages[0].body, &errorMsg)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal sent error message: %v", err)
+	if err := router.handleRuntimeResponses(context.Background(), envelope, responses, msgBody, time.Millisecond, time.Now()); err != nil {
+		t.Fatalf("handleRuntimeResponses failed: %v", err)
 	}
 
-	if errorMsg["id"] != "" {
-		t.Errorf("Expected empty ID for invalid JSON, got %v", errorMsg["id"])
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-error-end" {
+		t.Fatalf("Expected 1 message to asya-default-error-end, got %+v", mockTransport.sentMessages)
 	}
-
-	// Error should be inside payload (nested format)
-	payload, ok := errorMsg["payload"].(map[string]any)
-	if !ok {
-		t.Fatalf("Expected payload to be a map, got %T", errorMsg["payload"])
+	var sent envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+		t.Fatalf("Failed to unmarshal sent message: %v", err)
 	}
-
-	if payload["error"] != "Parse error" {
-		t.Errorf("Expected error message 'Parse error', got %v", payload["error"])
+	var errorPayload map[string]interface{}
+	if err := json.Unmarshal(sent.Payload, &errorPayload); err != nil {
+		t.Fatalf("Failed to unmarshal error payload: %v", err)
 	}
-
-	// original_payload should be nil when original message is invalid JSON
-	if payload["original_payload"] != nil {
-		t.Errorf("Expected nil original_payload for invalid JSON, got %T", payload["original_payload"])
+	if msg, _ := errorPayload["error"].(string); !strings.Contains(msg, "Route policy violation") {
+		t.Errorf("Expected route policy violation error, got %q", msg)
 	}
 }
 
-func TestRouter_Run(t *testing.T) {
-	mockTransport := &mockTransport{}
-
-	cfg := &config.Config{
-		ActorName:     "test-actor",
-		Namespace:     "default",
-		HappyEndQueue: "happy-end",
-		ErrorEndQueue: "error-end",
-		TransportType: "rabbitmq",
+func TestRouter_HandleRuntimeResponses_CrossNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   []string
+		next      string
+		wantQueue string
+	}{
+		{
+			name:      "allowed target namespace",
+			allowed:   []string{"team-b"},
+			next:      "team-b/ocr",
+			wantQueue: "asya-team-b-ocr",
+		},
+		{
+			name:      "target namespace not opted in",
+			allowed:   []string{"team-c"},
+			next:      "team-b/ocr",
+			wantQueue: "asya-default-error-end",
+		},
+		{
+			name:      "own namespace always allowed",
+			next:      "default/ocr",
+			wantQueue: "asya-default-ocr",
+		},
 	}
 
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:               "test-actor",
+				Namespace:               "default",
+				HappyEndQueue:           "happy-end",
+				ErrorEndQueue:           "error-end",
+				TransportType:           "sqs",
+				AllowedTargetNamespaces: tt.allowed,
+			}
+			mockTransport := &mockTransport{}
+			router := &Router{
+				cfg:           cfg,
+				transport:     mockTransport,
+				actorName:     cfg.ActorName,
+				happyEndQueue: cfg.HappyEndQueue,
+				errorEndQueue: cfg.ErrorEndQueue,
+			}
 
-	socketPath := fmt.Sprintf("/tmp/test-router-run-%d.sock", time.Now().UnixNano())
-	defer func() { _ = os.Remove(socketPath) }()
+			envelope := &envelopes.Envelope{
+				ID:      "test-cross-ns-1",
+				Route:   envelopes.Route{Actors: []string{"test-actor", tt.next}, Current: 0},
+				Payload: json.RawMessage(`{"value": 1}`),
+			}
+			msgBody, _ := json.Marshal(envelope)
+			responses := []runtime.RuntimeResponse{
+				{
+					Route:   envelopes.Route{Actors: []string{"test-actor", tt.next}, Current: 1},
+					Payload: json.RawMessage(`{"value": 2}`),
+				},
+			}
 
-	listener, err := net.Listen("unix", socketPath)
-	if err != nil {
-		t.Fatalf("Failed to create socket: %v", err)
+			if err := router.handleRuntimeResponses(context.Background(), envelope, responses, msgBody, time.Millisecond, time.Now()); err != nil {
+				t.Fatalf("handleRuntimeResponses failed: %v", err)
+			}
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != tt.wantQueue {
+				t.Fatalf("Expected 1 message to %s, got %+v", tt.wantQueue, mockTransport.sentMessages)
+			}
+		})
 	}
-	defer func() { _ = listener.Close() }()
+}
 
-	runtimeClient := runtime.NewClient(socketPath, 2*time.Second)
+func TestRouter_TargetNamespaceOnEverySend(t *testing.T) {
+	newRouter := func() (*Router, *mockTransport) {
+		cfg := &config.Config{
+			ActorName:               "test-actor",
+			Namespace:               "default",
+			HappyEndQueue:           "happy-end",
+			ErrorEndQueue:           "error-end",
+			TransportType:           "sqs",
+			AllowedTargetNamespaces: []string{"team-c"},
+		}
+		mockTransport := &mockTransport{}
+		return &Router{
+			cfg:           cfg,
+			transport:     mockTransport,
+			actorName:     cfg.ActorName,
+			happyEndQueue: cfg.HappyEndQueue,
+			errorEndQueue: cfg.ErrorEndQueue,
+		}, mockTransport
+	}
+
+	t.Run("called sub-route", func(t *testing.T) {
+		router, mockTransport := newRouter()
+		envelope := &envelopes.Envelope{
+			ID:      "test-cross-ns-call",
+			Route:   envelopes.Route{Actors: []string{"test-actor", "publish"}, Current: 0},
+			Payload: json.RawMessage(`{"value": 1}`),
+		}
+		msgBody, _ := json.Marshal(envelope)
+		responses := []runtime.RuntimeResponse{{
+			Route:   envelopes.Route{Actors: []string{"test-actor", "publish"}, Current: 1},
+			Call:    &envelopes.Call{Actors: []string{"team-b/ocr"}},
+			Payload: json.RawMessage(`{"value": 2}`),
+		}}
+
+		if err := router.handleRuntimeResponses(context.Background(), envelope, responses, msgBody, time.Millisecond, time.Now()); err != nil {
+			t.Fatalf("handleRuntimeResponses failed: %v", err)
+		}
+		if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-error-end" {
+			t.Fatalf("Expected 1 message to asya-default-error-end, got %+v", mockTransport.sentMessages)
+		}
+	})
+
+	t.Run("next compensating actor", func(t *testing.T) {
+		router, mockTransport := newRouter()
+		envelope := envelopes.Envelope{
+			ID:           "test-cross-ns-compensation",
+			Route:        envelopes.Route{Actors: []string{"test-actor", "team-b/release", "team-c/refund"}, Current: 0},
+			Payload:      json.RawMessage(`{"error": "card declined"}`),
+			Compensation: &envelopes.Compensation{Route: envelopes.Route{Actors: []string{"charge"}, Current: 0}},
+		}
 
-	router := &Router{
-		cfg:           cfg,
-		transport:     mockTransport,
-		runtimeClient: runtimeClient,
-		actorName:     cfg.ActorName,
-		happyEndQueue: cfg.HappyEndQueue,
-		errorEndQueue: cfg.ErrorEndQueue,
-		metrics:       m,
+		if err := router.continueCompensation(context.Background(), envelope, true); err != nil {
+			t.Fatalf("continueCompensation failed: %v", err)
+		}
+		if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-team-c-refund" {
+			t.Fatalf("Expected 1 message to asya-team-c-refund, got %+v", mockTransport.sentMessages)
+		}
+		var sent envelopes.Envelope
+		if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+			t.Fatalf("Failed to unmarshal sent message: %v", err)
+		}
+		if sent.Route.Current != 2 || !reflect.DeepEqual(sent.Compensation.Failed, []string{"team-b/release"}) {
+			t.Errorf("Expected the skipped compensator to be recorded as failed, got route %+v compensation %+v", sent.Route, sent.Compensation)
+		}
+	})
+
+	t.Run("direct send", func(t *testing.T) {
+		router, mockTransport := newRouter()
+		envelope := &envelopes.Envelope{ID: "test-cross-ns-direct", Route: envelopes.Route{Actors: []string{"team-b/ocr"}}}
+		if err := router.sendEnvelopeTo(context.Background(), envelope, "team-b/ocr", "routing"); err == nil || !strings.Contains(err.Error(), "does not accept envelopes") {
+			t.Errorf("Expected a namespace error, got %v", err)
+		}
+		if len(mockTransport.sentMessages) != 0 {
+			t.Errorf("Expected nothing sent, got %+v", mockTransport.sentMessages)
+		}
+	})
+}
+
+func TestRouter_HandleRuntimeResponses_ApprovalStep(t *testing.T) {
+	tests := []struct {
+		name          string
+		reportStatus  bool
+		callStack     []envelopes.CallFrame
+		outputRoute   envelopes.Route
+		wantRoute     envelopes.Route
+		wantCallStack []envelopes.CallFrame
+		wantRejected  bool
+		disabled      bool
+	}{
+		{
+			name:         "parks envelope resuming at the next actor",
+			reportStatus: true,
+			outputRoute:  envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 1},
+			wantRoute:    envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 2},
+		},
+		{
+			name:         "approval ending a sub-route resumes at the caller",
+			reportStatus: true,
+			callStack:    []envelopes.CallFrame{{Caller: "order", Route: envelopes.Route{Actors: []string{"order", "ship"}, Current: 1}}},
+			outputRoute:  envelopes.Route{Actors: []string{"test-actor", "approval"}, Current: 1},
+			wantRoute:    envelopes.Route{Actors: []string{"order", "ship"}, Current: 1},
+		},
+		{
+			name:          "approval inside a sub-route keeps the call stack",
+			reportStatus:  true,
+			callStack:     []envelopes.CallFrame{{Caller: "order", Route: envelopes.Route{Actors: []string{"order", "ship"}, Current: 1}}},
+			outputRoute:   envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 1},
+			wantRoute:     envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 2},
+			wantCallStack: []envelopes.CallFrame{{Caller: "order", Route: envelopes.Route{Actors: []string{"order", "ship"}, Current: 1}}},
+		},
+		{
+			name:         "approval as last step is rejected",
+			reportStatus: true,
+			outputRoute:  envelopes.Route{Actors: []string{"test-actor", "approval"}, Current: 1},
+			wantRejected: true,
+		},
+		{
+			name:         "approval without gateway reporting is rejected",
+			outputRoute:  envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 1},
+			wantRejected: true,
+		},
+		{
+			name:         "actor named approval without the step enabled",
+			reportStatus: true,
+			outputRoute:  envelopes.Route{Actors: []string{"test-actor", "approval", "publish"}, Current: 1},
+			disabled:     true,
+		},
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	signer := newTestSigner(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: "happy-end",
+				ErrorEndQueue: "error-end",
+				TransportType: "sqs",
+				ApprovalActor: "approval",
+			}
+			mockTransport := &mockTransport{}
+			router := &Router{
+				cfg:           cfg,
+				transport:     mockTransport,
+				actorName:     cfg.ActorName,
+				happyEndQueue: cfg.HappyEndQueue,
+				errorEndQueue: cfg.ErrorEndQueue,
+			}
+			if tt.reportStatus {
+				router.statusPublisher = progress.NewStatusPublisher(mockTransport, "gateway-status", cfg.ActorName)
+			}
+			if tt.disabled {
+				cfg.ApprovalActor = ""
+			}
+			router.SetSigner(signer)
 
-	cancel()
+			envelope := &envelopes.Envelope{
+				ID:        "test-approval-1",
+				Route:     envelopes.Route{Actors: tt.outputRoute.Actors, Current: 0},
+				Payload:   json.RawMessage(`{"text": "draft"}`),
+				CallStack: tt.callStack,
+			}
+			msgBody, _ := json.Marshal(envelope)
+			responses := []runtime.RuntimeResponse{{Route: tt.outputRoute, Payload: json.RawMessage(`{"text":"reviewed"}`)}}
 
-	err = router.Run(ctx)
-	if err != context.Canceled {
-		t.Errorf("Expected context.Canceled error, got: %v", err)
+			if err := router.handleRuntimeResponses(context.Background(), envelope, responses, msgBody, time.Millisecond, time.Now()); err != nil {
+				t.Fatalf("handleRuntimeResponses failed: %v", err)
+			}
+
+			var approvals []progress.StatusEvent
+			var routed []string
+			for _, sent := range mockTransport.sentMessages {
+				var event progress.StatusEvent
+				if sent.queue == "gateway-status" && json.Unmarshal(sent.body, &event) == nil && event.Type == progress.EventApproval {
+					approvals = append(approvals, event)
+					continue
+				}
+				if sent.queue != "gateway-status" {
+					routed = append(routed, sent.queue)
+				}
+			}
+
+			if tt.wantRejected {
+				if len(approvals) != 0 || len(routed) != 1 || routed[0] != "asya-default-error-end" {
+					t.Errorf("Expected only a message to error-end, got approvals %d, routed %v", len(approvals), routed)
+				}
+				return
+			}
+			if tt.disabled {
+				if len(approvals) != 0 || len(routed) != 1 || routed[0] != "asya-default-approval" {
+					t.Errorf("Expected only a message to the approval actor's queue, got approvals %d, routed %v", len(approvals), routed)
+				}
+				return
+			}
+
+			if len(routed) != 0 {
+				t.Errorf("Parked envelope was sent to %v", routed)
+			}
+			if len(approvals) != 1 {
+				t.Fatalf("Expected 1 approval event, got %d", len(approvals))
+			}
+			var parked struct {
+				envelopes.Envelope
+				Step string `json:"step"`
+			}
+			if err := json.Unmarshal(approvals[0].Approval, &parked); err != nil {
+				t.Fatalf("Failed to decode approval request: %v", err)
+			}
+			if parked.Step != "approval" || parked.ID != "test-approval-1" {
+				t.Errorf("Approval request step = %q id = %q", parked.Step, parked.ID)
+			}
+			if !reflect.DeepEqual(parked.Route, tt.wantRoute) {
+				t.Errorf("Resume route = %+v, want %+v", parked.Route, tt.wantRoute)
+			}
+			if !reflect.DeepEqual(parked.CallStack, tt.wantCallStack) {
+				t.Errorf("Resume call stack = %+v, want %+v", parked.CallStack, tt.wantCallStack)
+			}
+			if string(parked.Payload) != `{"text":"reviewed"}` {
+				t.Errorf("Payload = %s, want the runtime response", parked.Payload)
+			}
+			if err := signer.Verify(context.Background(), parked.Envelope); err != nil {
+				t.Errorf("Parked envelope signature: %v", err)
+			}
+			if parked.HopCount != 1 || !reflect.DeepEqual(parked.Visited, []string{"test-actor"}) {
+				t.Errorf("Hop counters = %d %v, want this actor's hop recorded", parked.HopCount, parked.Visited)
+			}
+		})
 	}
 }
 
-func TestRouter_ProcessMessage_ParseError(t *testing.T) {
+func TestRouter_HandleRuntimeResponses_HopLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfgMaxHops  int
+		envelope    envelopes.Envelope
+		outputRoute envelopes.Route
+		wantQueue   string
+	}{
+		{
+			name:        "routes and advances hop counters",
+			cfgMaxHops:  10,
+			envelope:    envelopes.Envelope{HopCount: 1, Visited: []string{"ingest"}, MaxHops: 5},
+			outputRoute: envelopes.Route{Actors: []string{"ingest", "test-actor", "next-actor"}, Current: 2},
+			wantQueue:   "asya-default-next-actor",
+		},
+		{
+			name:        "envelope max hops exceeded",
+			cfgMaxHops:  10,
+			envelope:    envelopes.Envelope{HopCount: 2, Visited: []string{"test-actor", "test-actor"}, MaxHops: 3},
+			outputRoute: envelopes.Route{Actors: []string{"test-actor", "test-actor", "test-actor", "test-actor"}, Current: 3},
+			wantQueue:   "asya-default-error-end",
+		},
+		{
+			name:        "sidecar default applies without envelope max hops",
+			cfgMaxHops:  2,
+			envelope:    envelopes.Envelope{HopCount: 1, Visited: []string{"test-actor"}},
+			outputRoute: envelopes.Route{Actors: []string{"test-actor", "test-actor", "test-actor"}, Current: 2},
+			wantQueue:   "asya-default-error-end",
+		},
+		{
+			name:        "envelope max hops overrides sidecar default",
+			cfgMaxHops:  2,
+			envelope:    envelopes.Envelope{HopCount: 1, Visited: []string{"test-actor"}, MaxHops: 20},
+			outputRoute: envelopes.Route{Actors: []string{"test-actor", "test-actor", "test-actor"}, Current: 2},
+			wantQueue:   "asya-default-test-actor",
+		},
+		{
+			name:        "happy-end allowed at limit",
+			cfgMaxHops:  2,
+			envelope:    envelopes.Envelope{HopCount: 1, Visited: []string{"ingest"}},
+			outputRoute: envelopes.Route{Actors: []string{"ingest", "test-actor"}, Current: 2},
+			wantQueue:   "asya-default-happy-end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: "happy-end",
+				ErrorEndQueue: "error-end",
+				TransportType: "rabbitmq",
+				MaxHops:       tt.cfgMaxHops,
+			}
+			mockTransport := &mockTransport{}
+			router := &Router{
+				cfg:           cfg,
+				transport:     mockTransport,
+				actorName:     cfg.ActorName,
+				happyEndQueue: cfg.HappyEndQueue,
+				errorEndQueue: cfg.ErrorEndQueue,
+			}
+
+			envelope := tt.envelope
+			envelope.ID = "test-hops-1"
+			envelope.Route = envelopes.Route{Actors: tt.outputRoute.Actors, Current: tt.outputRoute.Current - 1}
+			envelope.Payload = json.RawMessage(`{"value": 1}`)
+			msgBody, _ := json.Marshal(envelope)
+			responses := []runtime.RuntimeResponse{{Route: tt.outputRoute, Payload: json.RawMessage(`{"value": 2}`)}}
+
+			if err := router.handleRuntimeResponses(context.Background(), &envelope, responses, msgBody, time.Millisecond, time.Now()); err != nil {
+				t.Fatalf("handleRuntimeResponses failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != tt.wantQueue {
+				t.Fatalf("Expected 1 message to %s, got %+v", tt.wantQueue, mockTransport.sentMessages)
+			}
+			var sent envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+				t.Fatalf("Failed to unmarshal sent message: %v", err)
+			}
+
+			if tt.wantQueue == "asya-default-error-end" {
+				var errorPayload map[string]interface{}
+				if err := json.Unmarshal(sent.Payload, &errorPayload); err != nil {
+					t.Fatalf("Failed to unmarshal error payload: %v", err)
+				}
+				if errorPayload["error"] != "loop_detected" {
+					t.Errorf("Expected loop_detected error, got %v", errorPayload["error"])
+				}
+				// The rejecting actor's hop is recorded on the error envelope
+				if sent.HopCount != envelope.HopCount+1 || len(sent.Visited) != len(envelope.Visited)+1 {
+					t.Errorf("Error envelope hop counters = %d %v, want %d", sent.HopCount, sent.Visited, envelope.HopCount+1)
+				}
+				return
+			}
+
+			if sent.HopCount != envelope.HopCount+1 {
+				t.Errorf("HopCount = %d, want %d", sent.HopCount, envelope.HopCount+1)
+			}
+			wantVisited := append(append([]string{}, envelope.Visited...), "test-actor")
+			if strings.Join(sent.Visited, ",") != strings.Join(wantVisited, ",") {
+				t.Errorf("Visited = %v, want %v", sent.Visited, wantVisited)
+			}
+			if sent.MaxHops != envelope.MaxHops {
+				t.Errorf("MaxHops = %d, want %d", sent.MaxHops, envelope.MaxHops)
+			}
+		})
+	}
+}
+
+func TestRouter_SendToErrorQueue(t *testing.T) {
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
@@ -1009,28 +1596,68 @@ func TestRouter_ProcessMessage_ParseError(t *testing.T) {
 		metrics:       m,
 	}
 
-	invalidJSON := []byte(`{invalid json`)
-	queueMsg := transport.QueueMessage{
-		ID:   "msg-1",
-		Body: invalidJSON,
+	originalEnvelope := envelopes.Envelope{
+		ID: "test-envelope-456",
+		Route: envelopes.Route{
+			Actors:  []string{"actor1"},
+			Current: 0,
+		},
+		Payload: json.RawMessage(`{"data": "test"}`),
 	}
 
+	originalBody, _ := json.Marshal(originalEnvelope)
+
 	ctx := context.Background()
-	err := router.ProcessEnvelope(ctx, queueMsg)
+	err := router.sendToErrorQueue(ctx, originalBody, "Runtime processing failed")
 	if err != nil {
-		t.Fatalf("ProcessMessage should not return error (sends to error queue): %v", err)
+		t.Fatalf("sendToErrorQueue failed: %v", err)
 	}
 
 	if len(mockTransport.sentMessages) != 1 {
-		t.Fatalf("Expected 1 message sent to error queue, got %d", len(mockTransport.sentMessages))
+		t.Fatalf("Expected 1 message sent, got %d", len(mockTransport.sentMessages))
 	}
 
 	if mockTransport.sentMessages[0].queue != "asya-default-"+testQueueErrorEnd {
-		t.Errorf("Envelope sent to %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+		t.Errorf("Envelope sent to queue %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+	}
+
+	var errorMsg map[string]any
+	err = json.Unmarshal(mockTransport.sentMessages[0].body, &errorMsg)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal sent error message: %v", err)
+	}
+
+	if errorMsg["id"] != "test-envelope-456" {
+		t.Errorf("Expected error message ID 'test-envelope-456', got %v", errorMsg["id"])
+	}
+
+	// Error should be inside payload (nested format)
+	payload, ok := errorMsg["payload"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected payload to be a map, got %T", errorMsg["payload"])
+	}
+
+	if payload["error"] != "Runtime processing failed" {
+		t.Errorf("Expected error message 'Runtime processing failed', got %v", payload["error"])
+	}
+
+	// Original payload should be preserved inside payload
+	originalPayloadBytes, err := json.Marshal(payload["original_payload"])
+	if err != nil {
+		t.Fatalf("Failed to marshal original_payload: %v", err)
+	}
+	expectedPayload := `{"data":"test"}`
+	if string(originalPayloadBytes) != expectedPayload {
+		t.Errorf("Expected original_payload %q, got %q", expectedPayload, string(originalPayloadBytes))
+	}
+
+	// Verify route field exists
+	if errorMsg["route"] == nil {
+		t.Error("Expected route field in error envelope")
 	}
 }
 
-func TestRouter_ProcessMessage_MissingEnvelopeID(t *testing.T) {
+func TestRouter_SendToErrorQueue_WithInvalidOriginalMessage(t *testing.T) {
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
@@ -1051,68 +1678,46 @@ func TestRouter_ProcessMessage_MissingEnvelopeID(t *testing.T) {
 		metrics:       m,
 	}
 
-	envelopeWithoutID := []byte(`{"route": {"actors": ["test-actor"], "current": 0}, "payload": {"test": "data"}}`)
-	queueMsg := transport.QueueMessage{
-		ID:   "msg-1",
-		Body: envelopeWithoutID,
-	}
+	invalidJSON := []byte(`{invalid json`)
 
 	ctx := context.Background()
-	err := router.ProcessEnvelope(ctx, queueMsg)
+	err := router.sendToErrorQueue(ctx, invalidJSON, "Parse error")
 	if err != nil {
-		t.Fatalf("ProcessMessage should not return error (sends to error queue): %v", err)
+		t.Fatalf("sendToErrorQueue failed: %v", err)
 	}
 
 	if len(mockTransport.sentMessages) != 1 {
-		t.Fatalf("Expected 1 message sent to error queue, got %d", len(mockTransport.sentMessages))
+		t.Fatalf("Expected 1 message sent, got %d", len(mockTransport.sentMessages))
 	}
 
-	if mockTransport.sentMessages[0].queue != "asya-default-"+testQueueErrorEnd {
-		t.Errorf("Envelope sent to %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+	var errorMsg map[string]any
+	err = json.Unmarshal(mockTransport.sentMessages[0].body, &errorMsg)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal sent error message: %v", err)
 	}
 
-	var errorEnvelope map[string]interface{}
-	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &errorEnvelope); err != nil {
-		t.Fatalf("Failed to parse error envelope: %v", err)
+	if errorMsg["id"] != "" {
+		t.Errorf("Expected empty ID for invalid JSON, got %v", errorMsg["id"])
 	}
 
 	// Error should be inside payload (nested format)
-	payload, ok := errorEnvelope["payload"].(map[string]interface{})
+	payload, ok := errorMsg["payload"].(map[string]any)
 	if !ok {
-		t.Fatalf("Expected payload to be a map, got %T", errorEnvelope["payload"])
+		t.Fatalf("Expected payload to be a map, got %T", errorMsg["payload"])
 	}
 
-	if errorMsg, ok := payload["error"].(string); !ok || !strings.Contains(errorMsg, "missing required 'id' field") {
-		t.Errorf("Error message should contain 'missing required 'id' field', got: %v", payload["error"])
+	if payload["error"] != "Parse error" {
+		t.Errorf("Expected error message 'Parse error', got %v", payload["error"])
 	}
-}
-
-func TestRouter_ProcessMessage_EmptyResponse(t *testing.T) {
-	socketPath := fmt.Sprintf("/tmp/test-empty-response-%d.sock", time.Now().UnixNano())
-	defer func() { _ = os.Remove(socketPath) }()
 
-	listener, err := net.Listen("unix", socketPath)
-	if err != nil {
-		t.Fatalf("Failed to create socket: %v", err)
+	// original_payload should be nil when original message is invalid JSON
+	if payload["original_payload"] != nil {
+		t.Errorf("Expected nil original_payload for invalid JSON, got %T", payload["original_payload"])
 	}
-	defer func() { _ = listener.Close() }()
-
-	go func() {
-		conn, err := listener.Accept()
-		if err != nil {
-			return
-		}
-		defer func() { _ = conn.Close() }()
-
-		_, err = runtime.RecvSocketData(conn)
-		if err != nil {
-			return
-		}
+}
 
-		emptyResponse := []runtime.RuntimeResponse{}
-		data, _ := json.Marshal(emptyResponse)
-		_ = runtime.SendSocketData(conn, data)
-	}()
+func TestRouter_Run(t *testing.T) {
+	mockTransport := &mockTransport{}
 
 	cfg := &config.Config{
 		ActorName:     "test-actor",
@@ -1122,16 +1727,183 @@ func TestRouter_ProcessMessage_EmptyResponse(t *testing.T) {
 		TransportType: "rabbitmq",
 	}
 
-	mockTransport := &mockTransport{}
-	runtimeClient := runtime.NewClient(socketPath, 2*time.Second)
 	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
 
-	router := &Router{
-		cfg:           cfg,
-		transport:     mockTransport,
-		runtimeClient: runtimeClient,
-		actorName:     cfg.ActorName,
-		happyEndQueue: cfg.HappyEndQueue,
+	socketPath := fmt.Sprintf("/tmp/test-router-run-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	runtimeClient := runtime.NewClient(socketPath, 2*time.Second)
+
+	router := &Router{
+		cfg:           cfg,
+		transport:     mockTransport,
+		runtimeClient: runtimeClient,
+		actorName:     cfg.ActorName,
+		happyEndQueue: cfg.HappyEndQueue,
+		errorEndQueue: cfg.ErrorEndQueue,
+		metrics:       m,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	err = router.Run(ctx)
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled error, got: %v", err)
+	}
+}
+
+func TestRouter_ProcessMessage_ParseError(t *testing.T) {
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+	}
+
+	mockTransport := &mockTransport{}
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := &Router{
+		cfg:           cfg,
+		transport:     mockTransport,
+		actorName:     cfg.ActorName,
+		happyEndQueue: cfg.HappyEndQueue,
+		errorEndQueue: cfg.ErrorEndQueue,
+		metrics:       m,
+	}
+
+	invalidJSON := []byte(`{invalid json`)
+	queueMsg := transport.QueueMessage{
+		ID:   "msg-1",
+		Body: invalidJSON,
+	}
+
+	ctx := context.Background()
+	err := router.ProcessEnvelope(ctx, queueMsg)
+	if err != nil {
+		t.Fatalf("ProcessMessage should not return error (sends to error queue): %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 1 {
+		t.Fatalf("Expected 1 message sent to error queue, got %d", len(mockTransport.sentMessages))
+	}
+
+	if mockTransport.sentMessages[0].queue != "asya-default-"+testQueueErrorEnd {
+		t.Errorf("Envelope sent to %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+	}
+}
+
+func TestRouter_ProcessMessage_MissingEnvelopeID(t *testing.T) {
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+	}
+
+	mockTransport := &mockTransport{}
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := &Router{
+		cfg:           cfg,
+		transport:     mockTransport,
+		actorName:     cfg.ActorName,
+		happyEndQueue: cfg.HappyEndQueue,
+		errorEndQueue: cfg.ErrorEndQueue,
+		metrics:       m,
+	}
+
+	envelopeWithoutID := []byte(`{"route": {"actors": ["test-actor"], "current": 0}, "payload": {"test": "data"}}`)
+	queueMsg := transport.QueueMessage{
+		ID:   "msg-1",
+		Body: envelopeWithoutID,
+	}
+
+	ctx := context.Background()
+	err := router.ProcessEnvelope(ctx, queueMsg)
+	if err != nil {
+		t.Fatalf("ProcessMessage should not return error (sends to error queue): %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 1 {
+		t.Fatalf("Expected 1 message sent to error queue, got %d", len(mockTransport.sentMessages))
+	}
+
+	if mockTransport.sentMessages[0].queue != "asya-default-"+testQueueErrorEnd {
+		t.Errorf("Envelope sent to %q, expected %q", mockTransport.sentMessages[0].queue, "asya-default-"+testQueueErrorEnd)
+	}
+
+	var errorEnvelope map[string]interface{}
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &errorEnvelope); err != nil {
+		t.Fatalf("Failed to parse error envelope: %v", err)
+	}
+
+	// Error should be inside payload (nested format)
+	payload, ok := errorEnvelope["payload"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected payload to be a map, got %T", errorEnvelope["payload"])
+	}
+
+	if errorMsg, ok := payload["error"].(string); !ok || !strings.Contains(errorMsg, "missing required 'id' field") {
+		t.Errorf("Error message should contain 'missing required 'id' field', got: %v", payload["error"])
+	}
+}
+
+func TestRouter_ProcessMessage_EmptyResponse(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-empty-response-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		_, err = runtime.RecvSocketData(conn)
+		if err != nil {
+			return
+		}
+
+		emptyResponse := []runtime.RuntimeResponse{}
+		data, _ := json.Marshal(emptyResponse)
+		_ = runtime.SendSocketData(conn, data)
+	}()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+	}
+
+	mockTransport := &mockTransport{}
+	runtimeClient := runtime.NewClient(socketPath, 2*time.Second)
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := &Router{
+		cfg:           cfg,
+		transport:     mockTransport,
+		runtimeClient: runtimeClient,
+		actorName:     cfg.ActorName,
+		happyEndQueue: cfg.HappyEndQueue,
 		errorEndQueue: cfg.ErrorEndQueue,
 		metrics:       m,
 	}
@@ -1981,6 +2753,10 @@ func TestRouter_ReportFinalStatusWithEnvelope_ErrorEnd_ExtractsErrorDetails(t *t
 			Current: 1,
 		},
 		Payload: json.RawMessage(errorPayloadBytes),
+		Hops: []envelopes.Hop{
+			{Actor: "actor1", Attempt: 1, Outcome: statusSucceeded},
+			{Actor: "actor2", Attempt: 1, Outcome: statusFailed},
+		},
 	}
 	msgBody, _ := json.Marshal(inputEnvelope)
 
@@ -2038,6 +2814,14 @@ func TestRouter_ReportFinalStatusWithEnvelope_ErrorEnd_ExtractsErrorDetails(t *t
 	if finalPayload["current_actor_name"] != "actor2" {
 		t.Errorf("Expected current_actor_name 'actor2', got %v", finalPayload["current_actor_name"])
 	}
+
+	hops, ok := finalPayload["hops"].([]interface{})
+	if !ok || len(hops) != 2 {
+		t.Fatalf("Expected 2 hops in final status, got %v", finalPayload["hops"])
+	}
+	if last, _ := hops[1].(map[string]interface{}); last["actor"] != "actor2" || last["outcome"] != statusFailed {
+		t.Errorf("Expected failed hop at actor2, got %v", hops[1])
+	}
 }
 
 func TestRouter_ReportFinalStatusWithEnvelope_ErrorEnd_NoErrorDetails(t *testing.T) {
@@ -2450,126 +3234,2004 @@ func TestRouter_ProcessMessage_FanOut_CreatesGatewayEnvelopes(t *testing.T) {
 	}
 }
 
-func TestRouter_CheckGatewayHealth_Success(t *testing.T) {
-	healthCheckCalled := false
+func TestRouter_ProcessMessage_AsyncProgress(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-async-progress-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
 
-	// Mock HTTP server for gateway
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		if _, err := runtime.RecvSocketData(conn); err != nil {
+			return
+		}
+		responses := []runtime.RuntimeResponse{
+			{
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1},
+				Payload: json.RawMessage(`{"done": true}`),
+			},
+		}
+		data, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, data)
+	}()
+
+	var mu sync.Mutex
+	var paths []string
+	var statuses []string
 	gatewayServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/health" && r.Method == http.MethodGet {
-			healthCheckCalled = true
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write([]byte("OK"))
-		} else {
-			w.WriteHeader(http.StatusNotFound)
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/envelopes/progress/batch" {
+			var batch struct {
+				Updates []struct {
+					ID     string `json:"id"`
+					Status string `json:"status"`
+				} `json:"updates"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				t.Errorf("Failed to decode batch: %v", err)
+			}
+			for _, u := range batch.Updates {
+				statuses = append(statuses, u.ID+":"+u.Status)
+			}
 		}
+		w.WriteHeader(http.StatusOK)
 	}))
 	defer gatewayServer.Close()
 
 	cfg := &config.Config{
-		ActorName:     "test-actor",
-		Namespace:     "default",
-		HappyEndQueue: "happy-end",
-		ErrorEndQueue: "error-end",
-		TransportType: "rabbitmq",
-		GatewayURL:    gatewayServer.URL,
+		ActorName:             "test-actor",
+		Namespace:             "default",
+		HappyEndQueue:         "happy-end",
+		ErrorEndQueue:         "error-end",
+		TransportType:         "rabbitmq",
+		GatewayURL:            gatewayServer.URL,
+		ProgressAsync:         true,
+		ProgressFlushInterval: time.Hour,
 	}
 
-	mockTransport := &mockTransport{}
-	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
-
-	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+	router := NewRouter(cfg, &mockTransport{}, runtime.NewClient(socketPath, 2*time.Second), nil)
 
-	ctx := context.Background()
-	err := router.CheckGatewayHealth(ctx)
+	inputEnvelope := envelopes.Envelope{
+		ID:      "test-async-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Payload: json.RawMessage(`{}`),
+	}
+	msgBody, _ := json.Marshal(inputEnvelope)
 
-	if err != nil {
-		t.Errorf("CheckGatewayHealth returned error: %v", err)
+	if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
 	}
 
-	if !healthCheckCalled {
-		t.Error("Health check was not called")
+	mu.Lock()
+	if len(paths) != 0 {
+		t.Errorf("Expected no gateway calls while processing, got %v", paths)
 	}
-}
+	mu.Unlock()
 
-func TestRouter_CheckGatewayHealth_Failure(t *testing.T) {
-	// Mock HTTP server that returns 500
-	gatewayServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte("Internal server error"))
-	}))
-	defer gatewayServer.Close()
+	if err := router.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
 
-	cfg := &config.Config{
-		ActorName:     "test-actor",
-		Namespace:     "default",
-		HappyEndQueue: "happy-end",
-		ErrorEndQueue: "error-end",
-		TransportType: "rabbitmq",
-		GatewayURL:    gatewayServer.URL,
+	mu.Lock()
+	defer mu.Unlock()
+	// received, processing and completed coalesce into the latest update
+	if len(statuses) != 1 || statuses[0] != "test-async-1:completed" {
+		t.Errorf("Batched updates = %v, want [test-async-1:completed]", statuses)
 	}
+}
 
-	mockTransport := &mockTransport{}
-	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+func TestRouter_ProcessMessage_StatusQueue(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-status-queue-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
 
-	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
 
-	ctx := context.Background()
-	err := router.CheckGatewayHealth(ctx)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
 
-	// Should return error
-	if err == nil {
-		t.Error("CheckGatewayHealth should return error when gateway is unhealthy")
+		if _, err := runtime.RecvSocketData(conn); err != nil {
+			return
+		}
+		responses := []runtime.RuntimeResponse{
+			{
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1},
+				Payload: json.RawMessage(`{"done": true}`),
+			},
+		}
+		data, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, data)
+	}()
+
+	// No gateway URL: all status goes over the transport
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+		StatusQueue:   "gateway-status",
+	}
+
+	mockTransport := &mockTransport{}
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+	inputEnvelope := envelopes.Envelope{
+		ID:      "test-status-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Payload: json.RawMessage(`{}`),
+	}
+	msgBody, _ := json.Marshal(inputEnvelope)
+
+	if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
+
+	var statuses []string
+	var routed []string
+	for _, sent := range mockTransport.sentMessages {
+		if sent.queue != "asya-default-gateway-status" {
+			routed = append(routed, sent.queue)
+			continue
+		}
+		var event progress.StatusEvent
+		if err := json.Unmarshal(sent.body, &event); err != nil {
+			t.Fatalf("Failed to decode status event: %v", err)
+		}
+		if event.Type != progress.EventProgress || event.ID != "test-status-1" || event.Progress == nil {
+			t.Fatalf("Unexpected status event: %+v", event)
+		}
+		statuses = append(statuses, string(event.Progress.Status))
+	}
+
+	if got, want := strings.Join(statuses, ","), "received,processing,completed"; got != want {
+		t.Errorf("Status events = %s, want %s", got, want)
+	}
+	if len(routed) != 1 || routed[0] != "asya-default-next-actor" {
+		t.Errorf("Routed to %v, want [asya-default-next-actor]", routed)
 	}
 }
 
-func TestRouter_CheckGatewayHealth_NoGatewayConfigured(t *testing.T) {
+func TestRouter_HandleRuntimeResponses_CallStack(t *testing.T) {
+	caller := envelopes.Route{Actors: []string{"ingest", "caller", "publish"}, Current: 1}
+
+	tests := []struct {
+		name          string
+		actor         string
+		callStack     []envelopes.CallFrame
+		response      runtime.RuntimeResponse
+		wantQueue     string
+		wantRoute     envelopes.Route
+		wantCallStack []envelopes.CallFrame
+		wantError     string
+	}{
+		{
+			name:  "call pushes continuation and routes to called actors",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: caller.Actors, Current: 2},
+				Call:  &envelopes.Call{Actors: []string{"ocr", "translate"}},
+			},
+			wantQueue:     "asya-default-ocr",
+			wantRoute:     envelopes.Route{Actors: []string{"ocr", "translate"}, Current: 0},
+			wantCallStack: []envelopes.CallFrame{{Caller: "caller", Route: envelopes.Route{Actors: caller.Actors, Current: 2}}},
+		},
+		{
+			name:  "call resumes caller when current is not advanced",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: caller,
+				Call:  &envelopes.Call{Actors: []string{"ocr"}},
+			},
+			wantQueue:     "asya-default-ocr",
+			wantRoute:     envelopes.Route{Actors: []string{"ocr"}, Current: 0},
+			wantCallStack: []envelopes.CallFrame{{Caller: "caller", Route: caller}},
+		},
+		{
+			name:  "call by name resolves the named route",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: caller.Actors, Current: 2},
+				Call:  &envelopes.Call{Name: "document"},
+			},
+			wantQueue:     "asya-default-ocr",
+			wantRoute:     envelopes.Route{Actors: []string{"ocr", "translate"}, Current: 0},
+			wantCallStack: []envelopes.CallFrame{{Caller: "caller", Route: envelopes.Route{Actors: caller.Actors, Current: 2}}},
+		},
+		{
+			name:  "unknown named route is rejected",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: caller.Actors, Current: 2},
+				Call:  &envelopes.Call{Name: "missing"},
+			},
+			wantQueue: "asya-default-error-end",
+			wantError: "unknown named route",
+		},
+		{
+			name:  "call with name and actors is rejected",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: caller.Actors, Current: 2},
+				Call:  &envelopes.Call{Name: "document", Actors: []string{"ocr"}},
+			},
+			wantQueue: "asya-default-error-end",
+			wantError: "sets both name",
+		},
+		{
+			name:      "exhausted sub-route returns to caller",
+			actor:     "translate",
+			callStack: []envelopes.CallFrame{{Caller: "caller", Route: caller}},
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: []string{"ocr", "translate"}, Current: 2},
+			},
+			wantQueue: "asya-default-caller",
+			wantRoute: caller,
+		},
+		{
+			name:  "nested return skips exhausted continuations",
+			actor: "translate",
+			callStack: []envelopes.CallFrame{
+				{Caller: "caller", Route: envelopes.Route{Actors: caller.Actors, Current: 2}},
+				{Caller: "ocr", Route: envelopes.Route{Actors: []string{"ocr"}, Current: 1}},
+			},
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: []string{"translate"}, Current: 1},
+			},
+			wantQueue: "asya-default-publish",
+			wantRoute: envelopes.Route{Actors: caller.Actors, Current: 2},
+		},
+		{
+			name:      "exhausted continuation goes to happy-end",
+			actor:     "translate",
+			callStack: []envelopes.CallFrame{{Caller: "caller", Route: envelopes.Route{Actors: caller.Actors, Current: 3}}},
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: []string{"translate"}, Current: 1},
+			},
+			wantQueue: "asya-default-happy-end",
+			wantRoute: envelopes.Route{Actors: caller.Actors, Current: 3},
+		},
+		{
+			name:  "empty call is rejected",
+			actor: "caller",
+			response: runtime.RuntimeResponse{
+				Route: envelopes.Route{Actors: caller.Actors, Current: 2},
+				Call:  &envelopes.Call{},
+			},
+			wantQueue: "asya-default-error-end",
+			wantError: "Invalid call",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     tt.actor,
+				Namespace:     "default",
+				HappyEndQueue: "happy-end",
+				ErrorEndQueue: "error-end",
+				TransportType: "rabbitmq",
+				NamedRoutes:   map[string][]string{"document": {"ocr", "translate"}},
+			}
+			mockTransport := &mockTransport{}
+			router := &Router{
+				cfg:           cfg,
+				transport:     mockTransport,
+				actorName:     cfg.ActorName,
+				happyEndQueue: cfg.HappyEndQueue,
+				errorEndQueue: cfg.ErrorEndQueue,
+			}
+
+			inputRoute := tt.response.Route
+			inputRoute.Current = slices.Index(inputRoute.Actors, tt.actor)
+			envelope := &envelopes.Envelope{
+				ID:        "test-call-1",
+				Route:     inputRoute,
+				Payload:   json.RawMessage(`{"value": 1}`),
+				CallStack: tt.callStack,
+			}
+			msgBody, _ := json.Marshal(envelope)
+			tt.response.Payload = json.RawMessage(`{"value":2}`)
+
+			if err := router.handleRuntimeResponses(context.Background(), envelope, []runtime.RuntimeResponse{tt.response}, msgBody, time.Millisecond, time.Now()); err != nil {
+				t.Fatalf("handleRuntimeResponses failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != tt.wantQueue {
+				t.Fatalf("Expected 1 message to %s, got %+v", tt.wantQueue, mockTransport.sentMessages)
+			}
+			var sent envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+				t.Fatalf("Failed to unmarshal sent message: %v", err)
+			}
+
+			if tt.wantError != "" {
+				var errorPayload map[string]interface{}
+				if err := json.Unmarshal(sent.Payload, &errorPayload); err != nil {
+					t.Fatalf("Failed to unmarshal error payload: %v", err)
+				}
+				if msg, _ := errorPayload["error"].(string); !strings.Contains(msg, tt.wantError) {
+					t.Errorf("Expected %q error, got %q", tt.wantError, msg)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(sent.Route, tt.wantRoute) {
+				t.Errorf("Route = %+v, want %+v", sent.Route, tt.wantRoute)
+			}
+			if !reflect.DeepEqual(sent.CallStack, tt.wantCallStack) {
+				t.Errorf("CallStack = %+v, want %+v", sent.CallStack, tt.wantCallStack)
+			}
+			if string(sent.Payload) != `{"value":2}` {
+				t.Errorf("Payload = %s, want the handler's result", sent.Payload)
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessMessage_HopRecords(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-hops-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		if _, err := runtime.RecvSocketData(conn); err != nil {
+			return
+		}
+		responses := []runtime.RuntimeResponse{
+			{
+				Route:   envelopes.Route{Actors: []string{"ingest", "test-actor", "next-actor"}, Current: 2},
+				Payload: json.RawMessage(`{"value": 2}`),
+			},
+		}
+		resp, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, resp)
+	}()
+
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
+		PodName:       "test-actor-7f9c-abcde",
 		HappyEndQueue: "happy-end",
 		ErrorEndQueue: "error-end",
 		TransportType: "rabbitmq",
-		GatewayURL:    "", // No gateway configured
 	}
+	mockTransport := &mockTransport{}
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+	previous := envelopes.Hop{Actor: "ingest", Pod: "ingest-0", Attempt: 1, Outcome: statusSucceeded}
+	envelope := envelopes.Envelope{
+		ID:       "test-hops-2",
+		Route:    envelopes.Route{Actors: []string{"ingest", "test-actor", "next-actor"}, Current: 1},
+		Payload:  json.RawMessage(`{"value": 1}`),
+		HopCount: 1,
+		Visited:  []string{"ingest"},
+		Hops:     []envelopes.Hop{previous},
+	}
+	msgBody, _ := json.Marshal(envelope)
+
+	before := time.Now().UTC()
+	msg := transport.QueueMessage{ID: "msg-1", Body: msgBody, Headers: map[string]string{"Deliveries": "2"}}
+	if err := router.ProcessEnvelope(context.Background(), msg); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-next-actor" {
+		t.Fatalf("Expected envelope routed to next-actor, got %+v", mockTransport.sentMessages)
+	}
+	var routed envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &routed); err != nil {
+		t.Fatalf("Failed to parse routed envelope: %v", err)
+	}
+
+	if len(routed.Hops) != 2 || routed.Hops[0].Actor != "ingest" {
+		t.Fatalf("Expected previous hop followed by this actor's hop, got %+v", routed.Hops)
+	}
+	hop := routed.Hops[1]
+	if hop.Actor != "test-actor" || hop.Pod != "test-actor-7f9c-abcde" {
+		t.Errorf("Hop actor/pod = %q/%q, want test-actor/test-actor-7f9c-abcde", hop.Actor, hop.Pod)
+	}
+	if hop.Attempt != 2 {
+		t.Errorf("Hop attempt = %d, want 2", hop.Attempt)
+	}
+	if hop.Outcome != statusSucceeded {
+		t.Errorf("Hop outcome = %q, want %q", hop.Outcome, statusSucceeded)
+	}
+	if hop.StartedAt == nil {
+		t.Fatal("Hop started_at not set")
+	}
+	if hop.ReceivedAt.Before(before) || hop.StartedAt.Before(hop.ReceivedAt) || hop.FinishedAt.Before(*hop.StartedAt) {
+		t.Errorf("Hop timestamps out of order: received=%v started=%v finished=%v", hop.ReceivedAt, hop.StartedAt, hop.FinishedAt)
+	}
+	if routed.HopCount != 2 || len(routed.Visited) != 2 {
+		t.Errorf("Hop counters = %d %v, want 2 [ingest test-actor]", routed.HopCount, routed.Visited)
+	}
+}
+
+func newTestEncryptor(t *testing.T) *encryption.Encryptor {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	keyring := fmt.Sprintf(`{"active":"k1","keys":{"k1":%q}}`, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, encryption.KeySize)))
+	if err := os.WriteFile(path, []byte(keyring), 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	provider, err := encryption.NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return encryption.NewEncryptor(provider)
+}
+
+func TestRouter_ProcessMessage_Encryption(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-encryption-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	runtimeInput := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		data, err := runtime.RecvSocketData(conn)
+		if err != nil {
+			return
+		}
+		runtimeInput <- data
+		responses := []runtime.RuntimeResponse{
+			{
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1},
+				Payload: json.RawMessage(`{"name":"Jane Doe","score":0.9}`),
+			},
+		}
+		resp, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, resp)
+	}()
 
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+	}
 	mockTransport := &mockTransport{}
-	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+	enc := newTestEncryptor(t)
+	router.SetEncryptor(enc)
 
-	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+	ctx := context.Background()
+	payload, keyID, err := enc.EncryptPayload(ctx, json.RawMessage(`{"name":"Jane Doe"}`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	msgBody, _ := json.Marshal(envelopes.Envelope{
+		ID:      "test-enc-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Headers: map[string]interface{}{encryption.KeyIDHeader: keyID, "trace_id": "abc"},
+		Payload: payload,
+	})
+
+	if err := router.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
+
+	// Runtime sees plaintext and no key header
+	var received envelopes.Envelope
+	if err := json.Unmarshal(<-runtimeInput, &received); err != nil {
+		t.Fatalf("Failed to parse runtime input: %v", err)
+	}
+	if string(received.Payload) != `{"name":"Jane Doe"}` {
+		t.Errorf("Runtime payload = %s, want plaintext", received.Payload)
+	}
+	if _, ok := received.Headers[encryption.KeyIDHeader]; ok || received.Headers["trace_id"] != "abc" {
+		t.Errorf("Runtime headers = %v, want trace_id only", received.Headers)
+	}
+
+	// Routed envelope is encrypted again
+	if len(mockTransport.sentMessages) != 1 {
+		t.Fatalf("Expected 1 routed message, got %d", len(mockTransport.sentMessages))
+	}
+	if strings.Contains(string(mockTransport.sentMessages[0].body), "Jane Doe") {
+		t.Errorf("Routed envelope contains plaintext: %s", mockTransport.sentMessages[0].body)
+	}
+	var routed envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &routed); err != nil {
+		t.Fatalf("Failed to parse routed envelope: %v", err)
+	}
+	if routed.Headers[encryption.KeyIDHeader] != "k1" {
+		t.Errorf("Routed headers = %v, want key ID k1", routed.Headers)
+	}
+	plaintext, err := enc.DecryptPayload(ctx, routed.Payload)
+	if err != nil {
+		t.Fatalf("DecryptPayload failed: %v", err)
+	}
+	if string(plaintext) != `{"name":"Jane Doe","score":0.9}` {
+		t.Errorf("Routed payload = %s", plaintext)
+	}
+}
 
+func TestRouter_ProcessMessage_EncryptedFIFOKeepsHeadersAcrossHops(t *testing.T) {
 	ctx := context.Background()
-	err := router.CheckGatewayHealth(ctx)
+	enc := newTestEncryptor(t)
+	route := []string{"actor-a", "actor-b", "actor-c"}
 
-	// Should not return error when gateway is not configured
+	payload, keyID, err := enc.EncryptPayload(ctx, json.RawMessage(`{"order":1}`))
 	if err != nil {
-		t.Errorf("CheckGatewayHealth should not return error when gateway is not configured, got: %v", err)
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	msgBody, _ := json.Marshal(envelopes.Envelope{
+		ID:    "test-fifo-1",
+		Route: envelopes.Route{Actors: route, Current: 0},
+		Headers: map[string]interface{}{
+			encryption.KeyIDHeader:  keyID,
+			envelopes.AttemptHeader: 2,
+			"tenant":                "acme",
+		},
+		Payload: payload,
+	})
+
+	// Each actor receives the envelope routed by the previous one
+	for hop, actor := range route[:2] {
+		cfg := &config.Config{
+			ActorName:       actor,
+			Namespace:       "default",
+			HappyEndQueue:   "happy-end",
+			ErrorEndQueue:   "error-end",
+			TransportType:   "sqs",
+			SQSFIFO:         true,
+			SQSMessageGroup: "headers.tenant",
+		}
+		socketPath := serveRuntimeOnce(t, []runtime.RuntimeResponse{
+			{Route: envelopes.Route{Actors: route, Current: hop + 1}, Payload: json.RawMessage(`{"order":1}`)},
+		})
+		mockTransport := &mockTransport{}
+		router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+		router.SetEncryptor(enc)
+
+		if err := router.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+			t.Fatalf("%s: ProcessEnvelope failed: %v", actor, err)
+		}
+		if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-"+route[hop+1] {
+			t.Fatalf("%s: expected envelope routed to %s, got %+v", actor, route[hop+1], mockTransport.sentMessages)
+		}
+		msgBody = mockTransport.sentMessages[0].body
+
+		var routed envelopes.Envelope
+		if err := json.Unmarshal(msgBody, &routed); err != nil {
+			t.Fatalf("%s: failed to parse routed envelope: %v", actor, err)
+		}
+		if routed.Headers["tenant"] != "acme" {
+			t.Errorf("%s: routed headers = %v, want the tenant message group kept", actor, routed.Headers)
+		}
+		if routed.Headers[encryption.KeyIDHeader] != "k1" {
+			t.Errorf("%s: routed headers = %v, want key ID k1", actor, routed.Headers)
+		}
+		if _, ok := routed.Headers[envelopes.AttemptHeader]; ok {
+			t.Errorf("%s: routed headers = %v, want no retry attempt", actor, routed.Headers)
+		}
 	}
 }
 
-func TestRouter_CheckGatewayHealth_NetworkError(t *testing.T) {
+func TestRouter_ProcessMessage_EncryptedWithoutKeys(t *testing.T) {
 	cfg := &config.Config{
 		ActorName:     "test-actor",
 		Namespace:     "default",
 		HappyEndQueue: "happy-end",
 		ErrorEndQueue: "error-end",
 		TransportType: "rabbitmq",
-		GatewayURL:    "http://invalid-host-that-does-not-exist:99999",
 	}
-
 	mockTransport := &mockTransport{}
-	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
-	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+	router := NewRouter(cfg, mockTransport, runtime.NewClient("/tmp/nonexistent.sock", time.Second), nil)
 
-	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+	payload, keyID, err := newTestEncryptor(t).EncryptPayload(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	msgBody, _ := json.Marshal(envelopes.Envelope{
+		ID:      "test-enc-2",
+		Route:   envelopes.Route{Actors: []string{"test-actor"}, Current: 0},
+		Headers: map[string]interface{}{encryption.KeyIDHeader: keyID},
+		Payload: payload,
+	})
 
-	ctx := context.Background()
-	err := router.CheckGatewayHealth(ctx)
+	if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-2", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
 
-	// Should return error for network failure
-	if err == nil {
-		t.Error("CheckGatewayHealth should return error for network failure")
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-error-end" {
+		t.Fatalf("Expected envelope to be sent to error-end, got %+v", mockTransport.sentMessages)
+	}
+	if !strings.Contains(string(mockTransport.sentMessages[0].body), "encryption is not configured") {
+		t.Errorf("Error envelope = %s", mockTransport.sentMessages[0].body)
+	}
+}
+
+func newTestSigner(t *testing.T) *signing.Signer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "signing.json")
+	keyring := fmt.Sprintf(`{"active":"s1","keys":{"s1":%q}}`, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, encryption.KeySize)))
+	if err := os.WriteFile(path, []byte(keyring), 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	provider, err := encryption.NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return signing.NewSigner(provider)
+}
+
+func TestRouter_ProcessMessage_Signing(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-signing-%d.sock", time.Now().UnixNano())
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		if _, err := runtime.RecvSocketData(conn); err != nil {
+			return
+		}
+		responses := []runtime.RuntimeResponse{
+			{
+				Route:   envelopes.Route{Actors: []string{"validate", "test-actor", "charge"}, Current: 2},
+				Payload: json.RawMessage(`{"amount": 10, "note": "<ok>"}`),
+			},
+		}
+		resp, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, resp)
+	}()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+	}
+	mockTransport := &mockTransport{}
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+	signer := newTestSigner(t)
+	router.SetSigner(signer)
+
+	ctx := context.Background()
+	envelope := envelopes.Envelope{
+		ID:      "test-sig-1",
+		Route:   envelopes.Route{Actors: []string{"validate", "test-actor", "charge"}, Current: 1},
+		Payload: json.RawMessage(`{"amount":10}`),
+	}
+	if err := signer.Sign(ctx, &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	msgBody, _ := json.Marshal(envelope)
+
+	if err := router.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-charge" {
+		t.Fatalf("Expected envelope routed to charge, got %+v", mockTransport.sentMessages)
+	}
+	var routed envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &routed); err != nil {
+		t.Fatalf("Failed to parse routed envelope: %v", err)
+	}
+	if err := signer.Verify(ctx, routed); err != nil {
+		t.Errorf("Routed envelope signature invalid: %v", err)
+	}
+}
+
+func TestRouter_ProcessMessage_SignatureRejected(t *testing.T) {
+	signer := newTestSigner(t)
+	ctx := context.Background()
+
+	signed := envelopes.Envelope{
+		ID:      "test-sig-2",
+		Route:   envelopes.Route{Actors: []string{"validate", "test-actor"}, Current: 0},
+		Payload: json.RawMessage(`{"amount":10}`),
+	}
+	if err := signer.Sign(ctx, &signed); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	// Skip the validate actor by rewriting the route
+	skipped := signed
+	skipped.Route.Current = 1
+
+	tests := []struct {
+		name     string
+		envelope envelopes.Envelope
+		wantErr  string
+	}{
+		{
+			name: "unsigned",
+			envelope: envelopes.Envelope{
+				ID:      "test-sig-2",
+				Route:   envelopes.Route{Actors: []string{"test-actor"}, Current: 0},
+				Payload: json.RawMessage(`{"amount":10}`),
+			},
+			wantErr: "not signed",
+		},
+		{
+			name:     "tampered route",
+			envelope: skipped,
+			wantErr:  "signature mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: "happy-end",
+				ErrorEndQueue: "error-end",
+				TransportType: "rabbitmq",
+			}
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, runtime.NewClient("/tmp/nonexistent.sock", time.Second), nil)
+			router.SetSigner(signer)
+
+			msgBody, _ := json.Marshal(tt.envelope)
+			if err := router.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-2", Body: msgBody}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-error-end" {
+				t.Fatalf("Expected envelope to be sent to error-end, got %+v", mockTransport.sentMessages)
+			}
+			var errorEnvelope envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &errorEnvelope); err != nil {
+				t.Fatalf("Failed to parse error envelope: %v", err)
+			}
+			if !strings.Contains(string(errorEnvelope.Payload), tt.wantErr) {
+				t.Errorf("Error payload = %s, want %q", errorEnvelope.Payload, tt.wantErr)
+			}
+			// The rejection is not trusted, so it must not be signed on its behalf
+			if err := signer.Verify(ctx, errorEnvelope); !errors.Is(err, signing.ErrUnsigned) {
+				t.Errorf("Verify(error envelope) = %v, want ErrUnsigned", err)
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessMessage_ForgedEnvelopeDoesNotCompensate(t *testing.T) {
+	signer := newTestSigner(t)
+	ctx := context.Background()
+
+	compensations := map[string]interface{}{"reserve": "charge-refund", "test-actor": "delete-all"}
+	tests := []struct {
+		name     string
+		envelope envelopes.Envelope
+	}{
+		{
+			name: "compensations in route metadata",
+			envelope: envelopes.Envelope{
+				ID: "forged-1",
+				Route: envelopes.Route{
+					Actors:   []string{"reserve", "test-actor"},
+					Current:  1,
+					Metadata: map[string]interface{}{envelopes.CompensationsMetadataKey: compensations},
+				},
+				Visited:  []string{"reserve"},
+				HopCount: 1,
+				Payload:  json.RawMessage(`{"drop":"everything"}`),
+			},
+		},
+		{
+			name: "compensation marker",
+			envelope: envelopes.Envelope{
+				ID: "forged-2",
+				Route: envelopes.Route{
+					Actors:   []string{"test-actor", "charge-refund", "delete-all"},
+					Current:  0,
+					Metadata: map[string]interface{}{envelopes.CompensationsMetadataKey: compensations},
+				},
+				Compensation: &envelopes.Compensation{Route: envelopes.Route{Actors: []string{"reserve"}}},
+				Payload:      json.RawMessage(`{"drop":"everything"}`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: testQueueHappyEnd,
+				ErrorEndQueue: testQueueErrorEnd,
+				TransportType: "rabbitmq",
+				StatusQueue:   "status", // No status is reported for envelopes that fail verification
+			}
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, runtime.NewClient("/tmp/nonexistent.sock", time.Second), nil)
+			router.SetSigner(signer)
+
+			msgBody, _ := json.Marshal(tt.envelope)
+			if err := router.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-forged", Body: msgBody}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-error-end" {
+				t.Fatalf("Expected only an error-end message, got %+v", mockTransport.sentMessages)
+			}
+			var sent envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+				t.Fatalf("Failed to parse error envelope: %v", err)
+			}
+			if sent.Compensation != nil || sent.Route.Metadata != nil {
+				t.Errorf("Error envelope kept compensation %+v or route metadata %v", sent.Compensation, sent.Route.Metadata)
+			}
+			if err := signer.Verify(ctx, sent); !errors.Is(err, signing.ErrUnsigned) {
+				t.Errorf("Verify(error envelope) = %v, want ErrUnsigned", err)
+			}
+		})
+	}
+}
+
+func TestRouter_EndActor_DropsRejectedEnvelopes(t *testing.T) {
+	signer := newTestSigner(t)
+	// Status would be published to the status queue: a forged envelope under the ID of an in-flight
+	// envelope must not fail it in the gateway
+	cfg := &config.Config{
+		ActorName:     testQueueErrorEnd,
+		Namespace:     "default",
+		HappyEndQueue: testQueueHappyEnd,
+		ErrorEndQueue: testQueueErrorEnd,
+		TransportType: "rabbitmq",
+		IsEndActor:    true,
+		StatusQueue:   "status",
+	}
+
+	for name, body := range map[string][]byte{
+		"unsigned": []byte(`{"id":"forged-3","route":{"actors":["error-end"],"current":0},"payload":{"error":"x"}}`),
+		"invalid":  []byte(`not json`),
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, runtime.NewClient("/tmp/nonexistent.sock", time.Second), nil)
+			router.SetSigner(signer)
+
+			if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-end", Body: body}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+			if len(mockTransport.sentMessages) != 0 {
+				t.Errorf("Expected the rejected envelope to be dropped, got %+v", mockTransport.sentMessages)
+			}
+		})
+	}
+}
+
+func TestRouter_CheckGatewayHealth_Success(t *testing.T) {
+	healthCheckCalled := false
+
+	// Mock HTTP server for gateway
+	gatewayServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" && r.Method == http.MethodGet {
+			healthCheckCalled = true
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("OK"))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer gatewayServer.Close()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+		GatewayURL:    gatewayServer.URL,
+	}
+
+	mockTransport := &mockTransport{}
+	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+
+	ctx := context.Background()
+	err := router.CheckGatewayHealth(ctx)
+
+	if err != nil {
+		t.Errorf("CheckGatewayHealth returned error: %v", err)
+	}
+
+	if !healthCheckCalled {
+		t.Error("Health check was not called")
+	}
+}
+
+func TestRouter_CheckGatewayHealth_Failure(t *testing.T) {
+	// Mock HTTP server that returns 500
+	gatewayServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("Internal server error"))
+	}))
+	defer gatewayServer.Close()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+		GatewayURL:    gatewayServer.URL,
+	}
+
+	mockTransport := &mockTransport{}
+	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+
+	ctx := context.Background()
+	err := router.CheckGatewayHealth(ctx)
+
+	// Should return error
+	if err == nil {
+		t.Error("CheckGatewayHealth should return error when gateway is unhealthy")
+	}
+}
+
+func TestRouter_CheckGatewayHealth_NoGatewayConfigured(t *testing.T) {
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+		GatewayURL:    "", // No gateway configured
+	}
+
+	mockTransport := &mockTransport{}
+	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+
+	ctx := context.Background()
+	err := router.CheckGatewayHealth(ctx)
+
+	// Should not return error when gateway is not configured
+	if err != nil {
+		t.Errorf("CheckGatewayHealth should not return error when gateway is not configured, got: %v", err)
+	}
+}
+
+func TestRouter_CheckGatewayHealth_NetworkError(t *testing.T) {
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "rabbitmq",
+		GatewayURL:    "http://invalid-host-that-does-not-exist:99999",
+	}
+
+	mockTransport := &mockTransport{}
+	runtimeClient := runtime.NewClient("/tmp/test.sock", 2*time.Second)
+	m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+
+	router := NewRouter(cfg, mockTransport, runtimeClient, m)
+
+	ctx := context.Background()
+	err := router.CheckGatewayHealth(ctx)
+
+	// Should return error for network failure
+	if err == nil {
+		t.Error("CheckGatewayHealth should return error for network failure")
+	}
+}
+
+// serveRuntimeOnce serves a single runtime call on a new Unix socket with the given responses
+func serveRuntimeOnce(t *testing.T, responses []runtime.RuntimeResponse) string {
+	t.Helper()
+	socketPath, _ := serveRuntimeOnceCapturing(t, responses)
+	return socketPath
+}
+
+// serveRuntimeOnceCapturing is serveRuntimeOnce that also delivers the runtime input on the returned channel
+func serveRuntimeOnceCapturing(t *testing.T, responses []runtime.RuntimeResponse) (string, <-chan []byte) {
+	t.Helper()
+	socketPath := fmt.Sprintf("/tmp/test-runtime-%d.sock", time.Now().UnixNano())
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+	})
+
+	input := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		data, err := runtime.RecvSocketData(conn)
+		if err != nil {
+			return
+		}
+		input <- data
+		resp, _ := json.Marshal(responses)
+		_ = runtime.SendSocketData(conn, resp)
+	}()
+	return socketPath, input
+}
+
+func TestRouter_ProcessEnvelope_MirrorsToShadow(t *testing.T) {
+	tests := []struct {
+		name          string
+		shadowActor   string
+		shadowPercent int
+		expectMirror  bool
+	}{
+		{name: "all envelopes mirrored", shadowActor: "test-actor-v2", shadowPercent: 100, expectMirror: true},
+		{name: "mirroring disabled at 0 percent", shadowActor: "test-actor-v2", shadowPercent: 0, expectMirror: false},
+		{name: "shadow in a namespace that does not accept envelopes", shadowActor: "team-b/test-actor-v2", shadowPercent: 100, expectMirror: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := json.RawMessage(`{"label":"cat"}`)
+			socketPath := serveRuntimeOnce(t, []runtime.RuntimeResponse{
+				{Route: envelopes.Route{Actors: []string{"test-actor", "next"}, Current: 1}, Payload: payload},
+			})
+
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: testQueueHappyEnd,
+				ErrorEndQueue: testQueueErrorEnd,
+				TransportType: "rabbitmq",
+				ShadowActor:   tt.shadowActor,
+				ShadowPercent: tt.shadowPercent,
+			}
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+			msgBody, _ := json.Marshal(envelopes.Envelope{
+				ID:      "test-shadow-1",
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next"}, Current: 0},
+				Payload: json.RawMessage(`{"image":"cat.png"}`),
+			})
+			if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+
+			queues := make([]string, 0, len(mockTransport.sentMessages))
+			for _, sent := range mockTransport.sentMessages {
+				queues = append(queues, sent.queue)
+			}
+			if !tt.expectMirror {
+				if !reflect.DeepEqual(queues, []string{"asya-default-next"}) {
+					t.Errorf("Expected only the routed envelope, got %v", queues)
+				}
+				return
+			}
+			if !reflect.DeepEqual(queues, []string{"asya-default-test-actor-v2", "asya-default-next"}) {
+				t.Fatalf("Expected shadow copy then routed envelope, got %v", queues)
+			}
+
+			var shadow envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &shadow); err != nil {
+				t.Fatalf("Failed to parse shadow envelope: %v", err)
+			}
+			if shadow.ID != "test-shadow-1" || shadow.Route.Current != 0 || string(shadow.Payload) != `{"image":"cat.png"}` {
+				t.Errorf("Expected shadow copy of the received envelope, got %+v", shadow)
+			}
+			if shadow.Shadow == nil || shadow.Shadow.Primary != "test-actor" || shadow.Shadow.Outcome != "succeeded" {
+				t.Fatalf("Expected shadow marker from test-actor, got %+v", shadow.Shadow)
+			}
+			if want := envelopes.ResultDigest([]json.RawMessage{payload}); shadow.Shadow.ResultDigest != want {
+				t.Errorf("ResultDigest = %s, want %s", shadow.Shadow.ResultDigest, want)
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessEnvelope_ShadowComparison(t *testing.T) {
+	tests := []struct {
+		name           string
+		primaryOutcome string
+		primaryPayload string
+		expectedResult string
+	}{
+		{name: "same result", primaryOutcome: "succeeded", primaryPayload: `{"score": 1, "label": "cat"}`, expectedResult: "match"},
+		{name: "different payload", primaryOutcome: "succeeded", primaryPayload: `{"label":"dog","score":1}`, expectedResult: "diff"},
+		{name: "primary failed", primaryOutcome: "failed", expectedResult: "diff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socketPath := serveRuntimeOnce(t, []runtime.RuntimeResponse{
+				{Route: envelopes.Route{Actors: []string{"test-actor", "next"}, Current: 1}, Payload: json.RawMessage(`{"label":"cat","score":1}`)},
+			})
+
+			cfg := &config.Config{
+				ActorName:     "test-actor-v2",
+				Namespace:     "default",
+				HappyEndQueue: testQueueHappyEnd,
+				ErrorEndQueue: testQueueErrorEnd,
+				TransportType: "rabbitmq",
+			}
+			mockTransport := &mockTransport{}
+			m := metrics.NewMetrics("test", []config.CustomMetricConfig{})
+			router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), m)
+
+			digest := ""
+			if tt.primaryPayload != "" {
+				digest = envelopes.ResultDigest([]json.RawMessage{json.RawMessage(tt.primaryPayload)})
+			}
+			msgBody, _ := json.Marshal(envelopes.Envelope{
+				ID:      "test-shadow-2",
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next"}, Current: 0},
+				Payload: json.RawMessage(`{"image":"cat.png"}`),
+				Shadow:  &envelopes.Shadow{Primary: "test-actor", Outcome: tt.primaryOutcome, ResultDigest: digest, RuntimeDurationMs: 5},
+			})
+			if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 0 {
+				t.Errorf("Expected shadow result to be dropped, got sends %+v", mockTransport.sentMessages)
+			}
+
+			rec := httptest.NewRecorder()
+			m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			want := fmt.Sprintf(`test_shadow_comparisons_total{primary="test-actor",result=%q} 1`, tt.expectedResult)
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("Expected %s in metrics output:\n%s", want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessEnvelope_ResolvesActorVersions(t *testing.T) {
+	socketPath := serveRuntimeOnce(t, []runtime.RuntimeResponse{
+		{Route: envelopes.Route{Actors: []string{"test-actor", "classifier", "next"}, Current: 1}, Payload: json.RawMessage(`{"label":"cat"}`)},
+	})
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: testQueueHappyEnd,
+		ErrorEndQueue: testQueueErrorEnd,
+		TransportType: "rabbitmq",
+		ActorVersions: canary.NewVersions(canary.Table{
+			"classifier": {Versions: []canary.Version{{Actor: "classifier-v1", Weight: 0}, {Actor: "classifier-v2", Weight: 1}}},
+		}),
+	}
+	mockTransport := &mockTransport{}
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+	msgBody, _ := json.Marshal(envelopes.Envelope{
+		ID:      "test-versions-1",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "classifier", "next"}, Current: 0},
+		Payload: json.RawMessage(`{"image":"cat.png"}`),
+	})
+	if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope failed: %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != "asya-default-classifier-v2" {
+		t.Fatalf("Expected 1 message to asya-default-classifier-v2, got %+v", mockTransport.sentMessages)
+	}
+	var sent envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+		t.Fatalf("Failed to unmarshal sent message: %v", err)
+	}
+	if want := []string{"test-actor", "classifier-v2", "next"}; !reflect.DeepEqual(sent.Route.Actors, want) {
+		t.Errorf("Expected route %v recording the chosen version, got %v", want, sent.Route.Actors)
+	}
+}
+
+func TestRouter_SendToErrorQueue_Compensation(t *testing.T) {
+	compensations := map[string]interface{}{"reserve": "release", "charge": "refund", "notify": "", "audit": "team-b/unaudit"}
+	tests := []struct {
+		name          string
+		visited       []string
+		wantQueue     string
+		wantActors    []string
+		wantCurrent   int
+		wantCompRoute bool
+	}{
+		{
+			name:          "compensates completed actors most recent first",
+			visited:       []string{"reserve", "lookup", "charge-v2"},
+			wantQueue:     "asya-default-refund",
+			wantActors:    []string{"refund", "release"},
+			wantCompRoute: true,
+		},
+		{
+			name:          "skips compensators in namespaces that do not accept envelopes",
+			visited:       []string{"reserve", "audit", "charge-v2"},
+			wantQueue:     "asya-default-refund",
+			wantActors:    []string{"refund", "release"},
+			wantCompRoute: true,
+		},
+		{
+			name:        "no completed actor declares a compensation",
+			visited:     []string{"lookup", "notify"},
+			wantQueue:   "asya-default-error-end",
+			wantActors:  []string{"reserve", "lookup", "charge", "test-actor"},
+			wantCurrent: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: testQueueHappyEnd,
+				ErrorEndQueue: testQueueErrorEnd,
+				TransportType: "rabbitmq",
+				ActorVersions: canary.NewVersions(canary.Table{
+					"charge": {Versions: []canary.Version{{Actor: "charge-v1", Weight: 0}, {Actor: "charge-v2", Weight: 1}}},
+				}),
+			}
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, nil, nil)
+
+			route := envelopes.Route{
+				Actors:   []string{"reserve", "lookup", "charge", "test-actor"},
+				Current:  3,
+				Metadata: map[string]interface{}{envelopes.CompensationsMetadataKey: compensations},
+			}
+			msgBody, _ := json.Marshal(envelopes.Envelope{
+				ID:       "test-compensation-1",
+				Route:    route,
+				Payload:  json.RawMessage(`{"order":42}`),
+				HopCount: len(tt.visited),
+				Visited:  tt.visited,
+			})
+
+			if err := router.sendToErrorQueue(context.Background(), msgBody, "card declined"); err != nil {
+				t.Fatalf("sendToErrorQueue failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != tt.wantQueue {
+				t.Fatalf("Expected 1 message to %s, got %+v", tt.wantQueue, mockTransport.sentMessages)
+			}
+			var sent envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+				t.Fatalf("Failed to unmarshal sent message: %v", err)
+			}
+			if !reflect.DeepEqual(sent.Route.Actors, tt.wantActors) || sent.Route.Current != tt.wantCurrent {
+				t.Errorf("Route = %+v, want actors %v", sent.Route, tt.wantActors)
+			}
+			if !strings.Contains(string(sent.Payload), "card declined") {
+				t.Errorf("Payload = %s, want the error", sent.Payload)
+			}
+			if !tt.wantCompRoute {
+				if sent.Compensation != nil {
+					t.Errorf("Compensation = %+v, want nil", sent.Compensation)
+				}
+				return
+			}
+			if sent.Compensation == nil || !reflect.DeepEqual(sent.Compensation.Route.Actors, route.Actors) || sent.Compensation.Route.Current != 3 {
+				t.Errorf("Compensation = %+v, want the route the envelope failed on", sent.Compensation)
+			}
+			if sent.Route.Metadata[envelopes.CompensationsMetadataKey] == nil {
+				t.Error("Expected compensation route to keep the route metadata")
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessEnvelope_Compensation(t *testing.T) {
+	failedRoute := envelopes.Route{Actors: []string{"reserve", "charge", "ship"}, Current: 2}
+	tests := []struct {
+		name          string
+		current       int
+		response      runtime.RuntimeResponse
+		wantQueue     string
+		wantFailed    []string
+		wantErrorEnd  bool
+		wantHopStatus string
+	}{
+		{
+			name:          "succeeded compensation continues to the next compensating actor",
+			current:       0,
+			response:      runtime.RuntimeResponse{Payload: json.RawMessage(`{"refunded":true}`)},
+			wantQueue:     "asya-default-release",
+			wantHopStatus: "succeeded",
+		},
+		{
+			name:          "failed compensation is recorded and compensation continues",
+			current:       0,
+			response:      runtime.RuntimeResponse{Error: "refund_failed"},
+			wantQueue:     "asya-default-release",
+			wantFailed:    []string{"refund"},
+			wantHopStatus: "failed",
+		},
+		{
+			name:          "last compensating actor routes to error-end with the failed route",
+			current:       1,
+			response:      runtime.RuntimeResponse{Payload: json.RawMessage(`{}`)},
+			wantQueue:     "asya-default-error-end",
+			wantErrorEnd:  true,
+			wantHopStatus: "succeeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socketPath := serveRuntimeOnce(t, []runtime.RuntimeResponse{tt.response})
+
+			cfg := &config.Config{
+				ActorName:     "refund",
+				Namespace:     "default",
+				HappyEndQueue: testQueueHappyEnd,
+				ErrorEndQueue: testQueueErrorEnd,
+				TransportType: "rabbitmq",
+			}
+			mockTransport := &mockTransport{}
+			router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+			errorPayload := `{"error":"ship failed"}`
+			actors := []string{"refund", "release"}
+			if tt.current == 1 {
+				actors = []string{"release", "refund"}
+			}
+			msgBody, _ := json.Marshal(envelopes.Envelope{
+				ID:           "test-compensation-2",
+				Route:        envelopes.Route{Actors: actors, Current: tt.current},
+				Payload:      json.RawMessage(errorPayload),
+				HopCount:     3,
+				Compensation: &envelopes.Compensation{Route: failedRoute},
+			})
+
+			if err := router.ProcessEnvelope(context.Background(), transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+				t.Fatalf("ProcessEnvelope failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 || mockTransport.sentMessages[0].queue != tt.wantQueue {
+				t.Fatalf("Expected 1 message to %s, got %+v", tt.wantQueue, mockTransport.sentMessages)
+			}
+			var sent envelopes.Envelope
+			if err := json.Unmarshal(mockTransport.sentMessages[0].body, &sent); err != nil {
+				t.Fatalf("Failed to unmarshal sent message: %v", err)
+			}
+			if string(sent.Payload) != errorPayload {
+				t.Errorf("Payload = %s, want the error payload unchanged", sent.Payload)
+			}
+			if sent.Compensation == nil || !reflect.DeepEqual(sent.Compensation.Failed, tt.wantFailed) {
+				t.Errorf("Compensation = %+v, want failed %v", sent.Compensation, tt.wantFailed)
+			}
+			wantRoute := envelopes.Route{Actors: actors, Current: tt.current + 1}
+			if tt.wantErrorEnd {
+				wantRoute = failedRoute
+			}
+			if !reflect.DeepEqual(sent.Route, wantRoute) {
+				t.Errorf("Route = %+v, want %+v", sent.Route, wantRoute)
+			}
+			if sent.HopCount != 4 || len(sent.Hops) != 1 || sent.Hops[0].Actor != "refund" || sent.Hops[0].Outcome != tt.wantHopStatus {
+				t.Errorf("Hops = %+v (count %d), want one %s hop by refund", sent.Hops, sent.HopCount, tt.wantHopStatus)
+			}
+		})
+	}
+}
+
+func TestRouter_ProcessEnvelope_CompensationWithEncryption(t *testing.T) {
+	ctx := context.Background()
+	enc := newTestEncryptor(t)
+	newEncryptingRouter := func(actorName string, responses []runtime.RuntimeResponse, gatewayURL string) (*Router, *mockTransport, <-chan []byte) {
+		socketPath, input := serveRuntimeOnceCapturing(t, responses)
+		cfg := &config.Config{
+			ActorName:     actorName,
+			Namespace:     "default",
+			HappyEndQueue: testQueueHappyEnd,
+			ErrorEndQueue: testQueueErrorEnd,
+			TransportType: "rabbitmq",
+			IsEndActor:    actorName == testQueueErrorEnd,
+			GatewayURL:    gatewayURL,
+		}
+		mockTransport := &mockTransport{}
+		router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+		router.SetEncryptor(enc)
+		return router, mockTransport, input
+	}
+	// sentEncrypted returns the single envelope a router sent, checking that it is encrypted under k1
+	sentEncrypted := func(mt *mockTransport, wantQueue string) envelopes.Envelope {
+		t.Helper()
+		if len(mt.sentMessages) != 1 || mt.sentMessages[0].queue != wantQueue {
+			t.Fatalf("Expected 1 message to %s, got %+v", wantQueue, mt.sentMessages)
+		}
+		if strings.Contains(string(mt.sentMessages[0].body), "card declined") {
+			t.Errorf("Envelope to %s contains plaintext: %s", wantQueue, mt.sentMessages[0].body)
+		}
+		var sent envelopes.Envelope
+		if err := json.Unmarshal(mt.sentMessages[0].body, &sent); err != nil {
+			t.Fatalf("Failed to unmarshal sent message: %v", err)
+		}
+		if sent.Headers[encryption.KeyIDHeader] != "k1" {
+			t.Errorf("Headers of envelope to %s = %v, want key ID k1", wantQueue, sent.Headers)
+		}
+		return sent
+	}
+
+	// charge fails after reserve completed, so release compensates it
+	charge, chargeTransport, _ := newEncryptingRouter("charge", []runtime.RuntimeResponse{{Error: "card declined"}}, "")
+	payload, keyID, err := enc.EncryptPayload(ctx, json.RawMessage(`{"order":42}`))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	msgBody, _ := json.Marshal(envelopes.Envelope{
+		ID: "test-compensation-enc",
+		Route: envelopes.Route{
+			Actors:   []string{"reserve", "charge"},
+			Current:  1,
+			Metadata: map[string]interface{}{envelopes.CompensationsMetadataKey: map[string]interface{}{"reserve": "release"}},
+		},
+		Headers:  map[string]interface{}{encryption.KeyIDHeader: keyID, "trace_id": "abc"},
+		Payload:  payload,
+		HopCount: 1,
+		Visited:  []string{"reserve"},
+	})
+	if err := charge.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-1", Body: msgBody}); err != nil {
+		t.Fatalf("ProcessEnvelope in charge failed: %v", err)
+	}
+	compensation := sentEncrypted(chargeTransport, "asya-default-release")
+	if compensation.Headers["trace_id"] != "abc" {
+		t.Errorf("Compensation headers = %v, want trace_id kept", compensation.Headers)
+	}
+
+	// release decrypts the error payload, including the original payload in plaintext
+	release, releaseTransport, releaseInput := newEncryptingRouter("release", []runtime.RuntimeResponse{{Payload: json.RawMessage(`{"released":true}`)}}, "")
+	compensationBody, _ := json.Marshal(compensation)
+	if err := release.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-2", Body: compensationBody}); err != nil {
+		t.Fatalf("ProcessEnvelope in release failed: %v", err)
+	}
+	var received envelopes.Envelope
+	if err := json.Unmarshal(<-releaseInput, &received); err != nil {
+		t.Fatalf("Failed to parse runtime input: %v", err)
+	}
+	var errorPayload map[string]interface{}
+	if err := json.Unmarshal(received.Payload, &errorPayload); err != nil {
+		t.Fatalf("Compensating actor received undecryptable payload %s: %v", received.Payload, err)
+	}
+	if errorPayload["error"] != "card declined" || !reflect.DeepEqual(errorPayload["original_payload"], map[string]interface{}{"order": float64(42)}) {
+		t.Errorf("Compensating actor payload = %v, want the error and the plaintext original payload", errorPayload)
+	}
+	errorEnd := sentEncrypted(releaseTransport, "asya-default-error-end")
+
+	// error-end reports the error from the encrypted error payload
+	mockServer := &mockHTTPServer{responses: make(map[string]mockHTTPResponse)}
+	mockServer.Start(t)
+	defer mockServer.Close()
+	end, _, _ := newEncryptingRouter(testQueueErrorEnd, []runtime.RuntimeResponse{{Payload: json.RawMessage(`{}`)}}, mockServer.URL)
+	errorEndBody, _ := json.Marshal(errorEnd)
+	if err := end.ProcessEnvelope(ctx, transport.QueueMessage{ID: "msg-3", Body: errorEndBody}); err != nil {
+		t.Fatalf("ProcessEnvelope in error-end failed: %v", err)
+	}
+	req := mockServer.GetRequest("/envelopes/test-compensation-enc/final")
+	if req == nil {
+		t.Fatal("Expected a final status report, none received")
+	}
+	var finalPayload map[string]interface{}
+	if err := json.Unmarshal(req.Body, &finalPayload); err != nil {
+		t.Fatalf("Failed to parse gateway request: %v", err)
+	}
+	if finalPayload["status"] != statusFailed || finalPayload["error"] != "card declined" {
+		t.Errorf("Final status = %v, want failed with the error", finalPayload)
+	}
+}
+
+// delayedTransport is a mockTransport that also implements transport.DelayedSender
+type delayedTransport struct {
+	mockTransport
+	delays []time.Duration
+}
+
+func (m *delayedTransport) SendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error {
+	m.delays = append(m.delays, delay)
+	return m.Send(ctx, queueName, body)
+}
+
+func TestRouter_HandleRuntimeResponses_RetryPolicy(t *testing.T) {
+	policy := &config.RetryPolicyConfig{
+		MaxAttempts:           3,
+		RetryOn:               []string{"TimeoutError"},
+		Permanent:             []string{"ValueError"},
+		InitialBackoffSeconds: 2,
+		MaxBackoffSeconds:     300,
+		BackoffMultiplier:     2,
+	}
+
+	tests := []struct {
+		name        string
+		policy      *config.RetryPolicyConfig
+		errorType   string
+		attempt     int // attempt header of the received envelope (0 for none)
+		wantQueue   string
+		wantAttempt int
+		wantDelay   time.Duration
+	}{
+		{
+			name:        "retryable error is requeued to the actor",
+			policy:      policy,
+			errorType:   "TimeoutError",
+			wantQueue:   "asya-default-test-actor",
+			wantAttempt: 2,
+			wantDelay:   2 * time.Second,
+		},
+		{
+			name:        "backoff grows with the attempt",
+			policy:      policy,
+			errorType:   "TimeoutError",
+			attempt:     2,
+			wantQueue:   "asya-default-test-actor",
+			wantAttempt: 3,
+			wantDelay:   4 * time.Second,
+		},
+		{
+			name:      "exhausted retries go to error-end",
+			policy:    policy,
+			errorType: "TimeoutError",
+			attempt:   3,
+			wantQueue: "asya-default-" + testQueueErrorEnd,
+		},
+		{
+			name:      "permanent error goes to error-end",
+			policy:    policy,
+			errorType: "ValueError",
+			wantQueue: "asya-default-" + testQueueErrorEnd,
+		},
+		{
+			name:      "unlisted error goes to error-end",
+			policy:    policy,
+			errorType: "KeyError",
+			wantQueue: "asya-default-" + testQueueErrorEnd,
+		},
+		{
+			name:      "no retry policy",
+			errorType: "TimeoutError",
+			wantQueue: "asya-default-" + testQueueErrorEnd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ActorName:     "test-actor",
+				Namespace:     "default",
+				HappyEndQueue: "happy-end",
+				ErrorEndQueue: "error-end",
+				TransportType: "sqs",
+				RetryPolicy:   tt.policy,
+			}
+			mockTransport := &delayedTransport{}
+			router := &Router{
+				cfg:           cfg,
+				transport:     mockTransport,
+				actorName:     cfg.ActorName,
+				happyEndQueue: cfg.HappyEndQueue,
+				errorEndQueue: cfg.ErrorEndQueue,
+				metrics:       metrics.NewMetrics("test", []config.CustomMetricConfig{}),
+			}
+
+			envelope := envelopes.Envelope{
+				ID:      "test-123",
+				Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+				Payload: json.RawMessage(`{"input":"test"}`),
+			}
+			if tt.attempt > 0 {
+				envelope.Headers = map[string]interface{}{envelopes.AttemptHeader: tt.attempt}
+			}
+			msgBody, _ := json.Marshal(envelope)
+
+			responses := []runtime.RuntimeResponse{{
+				Error:   "Processing failed",
+				Details: runtime.ErrorDetails{Type: tt.errorType, Message: "boom"},
+			}}
+			if err := router.handleRuntimeResponses(context.Background(), &envelope, responses, msgBody, 0, time.Now()); err != nil {
+				t.Fatalf("handleRuntimeResponses failed: %v", err)
+			}
+
+			if len(mockTransport.sentMessages) != 1 {
+				t.Fatalf("Expected 1 message sent, got %d", len(mockTransport.sentMessages))
+			}
+			sent := mockTransport.sentMessages[0]
+			if sent.queue != tt.wantQueue {
+				t.Errorf("Envelope sent to %q, expected %q", sent.queue, tt.wantQueue)
+			}
+			if tt.wantAttempt == 0 {
+				if len(mockTransport.delays) != 0 {
+					t.Errorf("Expected no delayed send, got %v", mockTransport.delays)
+				}
+				return
+			}
+
+			var retried envelopes.Envelope
+			if err := json.Unmarshal(sent.body, &retried); err != nil {
+				t.Fatalf("Failed to parse requeued envelope: %v", err)
+			}
+			if got := retried.Attempt(); got != tt.wantAttempt {
+				t.Errorf("Attempt() = %d, want %d", got, tt.wantAttempt)
+			}
+			if retried.Route.Current != 0 || retried.HopCount != 0 || len(retried.Hops) != 0 {
+				t.Errorf("Requeued envelope should be unchanged, got route %+v, hop_count %d, hops %d", retried.Route, retried.HopCount, len(retried.Hops))
+			}
+			if string(retried.Payload) != `{"input":"test"}` {
+				t.Errorf("Payload = %s, want the received payload", retried.Payload)
+			}
+			if len(mockTransport.delays) != 1 || mockTransport.delays[0] != tt.wantDelay {
+				t.Errorf("Delays = %v, want [%s]", mockTransport.delays, tt.wantDelay)
+			}
+		})
+	}
+
+	t.Run("transport without delayed delivery waits in the sidecar", func(t *testing.T) {
+		cfg := &config.Config{
+			ActorName:     "test-actor",
+			Namespace:     "default",
+			HappyEndQueue: "happy-end",
+			ErrorEndQueue: "error-end",
+			TransportType: "rabbitmq",
+			RetryPolicy:   policy,
+		}
+		mockTransport := &mockTransport{}
+		router := &Router{
+			cfg:           cfg,
+			transport:     mockTransport,
+			actorName:     cfg.ActorName,
+			happyEndQueue: cfg.HappyEndQueue,
+			errorEndQueue: cfg.ErrorEndQueue,
+		}
+
+		envelope := envelopes.Envelope{
+			ID:      "test-123",
+			Route:   envelopes.Route{Actors: []string{"test-actor"}, Current: 0},
+			Payload: json.RawMessage(`{}`),
+		}
+		msgBody, _ := json.Marshal(envelope)
+		responses := []runtime.RuntimeResponse{{Error: "Processing failed", Details: runtime.ErrorDetails{Type: "TimeoutError"}}}
+
+		// Shutting down during the backoff NACKs the envelope instead of sending it to error-end
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := router.handleRuntimeResponses(ctx, &envelope, responses, msgBody, 0, time.Now()); err == nil {
+			t.Fatal("Expected an error when the context is canceled during the backoff")
+		}
+		if len(mockTransport.sentMessages) != 0 {
+			t.Errorf("Expected no message sent, got %d", len(mockTransport.sentMessages))
+		}
+	})
+}
+
+// mockBatchTransport receives and sends in batches and extends message visibility
+type mockBatchTransport struct {
+	mockTransport
+	mu                sync.Mutex
+	pending           []transport.QueueMessage
+	receiveLimits     []int
+	batches           map[string][][]byte
+	extended          map[string]int
+	acked             []string
+	visibilityTimeout time.Duration
+}
+
+func (m *mockBatchTransport) ReceiveBatch(ctx context.Context, queueName string, limit int) ([]transport.QueueMessage, error) {
+	m.mu.Lock()
+	m.receiveLimits = append(m.receiveLimits, limit)
+	msgs := m.pending[:min(limit, len(m.pending))]
+	m.pending = m.pending[len(msgs):]
+	m.mu.Unlock()
+
+	if len(msgs) == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return msgs, nil
+}
+
+func (m *mockBatchTransport) MaxBatchSize() int {
+	return 2
+}
+
+func (m *mockBatchTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockTransport.Send(ctx, queueName, body)
+}
+
+func (m *mockBatchTransport) SendBatch(ctx context.Context, queueName string, bodies [][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.batches == nil {
+		m.batches = make(map[string][][]byte)
+	}
+	m.batches[queueName] = append(m.batches[queueName], bodies...)
+	return nil
+}
+
+func (m *mockBatchTransport) Ack(ctx context.Context, msg transport.QueueMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acked = append(m.acked, msg.ID)
+	return nil
+}
+
+func (m *mockBatchTransport) VisibilityTimeout() time.Duration {
+	return m.visibilityTimeout
+}
+
+func (m *mockBatchTransport) ExtendVisibility(ctx context.Context, msg transport.QueueMessage, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.extended == nil {
+		m.extended = make(map[string]int)
+	}
+	m.extended[msg.ID]++
+	return nil
+}
+
+func TestRouter_HandleRuntimeResponses_FanOutSendsBatch(t *testing.T) {
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "sqs",
+	}
+	mockTransport := &mockBatchTransport{}
+	router := &Router{
+		cfg:           cfg,
+		transport:     mockTransport,
+		actorName:     cfg.ActorName,
+		happyEndQueue: cfg.HappyEndQueue,
+		errorEndQueue: cfg.ErrorEndQueue,
+		metrics:       metrics.NewMetrics("test", []config.CustomMetricConfig{}),
+	}
+
+	envelope := envelopes.Envelope{
+		ID:      "test-fanout-123",
+		Route:   envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 0},
+		Payload: json.RawMessage(`{}`),
+	}
+	msgBody, _ := json.Marshal(envelope)
+	responses := []runtime.RuntimeResponse{
+		{Route: envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1}, Payload: json.RawMessage(`{"index":0}`)},
+		{Route: envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 1}, Payload: json.RawMessage(`{"index":1}`)},
+		{Route: envelopes.Route{Actors: []string{"test-actor", "next-actor"}, Current: 2}, Payload: json.RawMessage(`{"index":2}`)},
+	}
+
+	if err := router.handleRuntimeResponses(context.Background(), &envelope, responses, msgBody, 0, time.Now()); err != nil {
+		t.Fatalf("handleRuntimeResponses() error = %v", err)
+	}
+
+	if len(mockTransport.sentMessages) != 0 {
+		t.Errorf("Expected no individual sends, got %d", len(mockTransport.sentMessages))
+	}
+	if got := len(mockTransport.batches["asya-default-next-actor"]); got != 2 {
+		t.Errorf("Expected 2 envelopes batched to next-actor, got %d", got)
+	}
+	if got := len(mockTransport.batches["asya-default-happy-end"]); got != 1 {
+		t.Errorf("Expected 1 envelope batched to happy-end, got %d", got)
+	}
+
+	var second envelopes.Envelope
+	if err := json.Unmarshal(mockTransport.batches["asya-default-next-actor"][1], &second); err != nil {
+		t.Fatalf("Failed to parse batched envelope: %v", err)
+	}
+	if second.ID != "test-fanout-123-1" {
+		t.Errorf("Second envelope ID = %q, want %q", second.ID, "test-fanout-123-1")
+	}
+}
+
+func TestRouter_Run_ConcurrentWorkersWithHeartbeat(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-router-workers-%d.sock", time.Now().UnixNano())
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+	})
+
+	// Like the Python runtime, serve one call at a time, each longer than the visibility timeout
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if _, err := runtime.RecvSocketData(conn); err == nil {
+				time.Sleep(150 * time.Millisecond)
+				_ = runtime.SendSocketData(conn, []byte(`[]`))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "sqs",
+	}
+	mockTransport := &mockBatchTransport{visibilityTimeout: 100 * time.Millisecond}
+	for _, id := range []string{"msg-1", "msg-2"} {
+		body, _ := json.Marshal(envelopes.Envelope{
+			ID:      "envelope-" + id,
+			Route:   envelopes.Route{Actors: []string{"test-actor"}, Current: 0},
+			Payload: json.RawMessage(`{}`),
+		})
+		mockTransport.pending = append(mockTransport.pending, transport.QueueMessage{ID: id, Body: body})
+	}
+
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, 2*time.Second), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- router.Run(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mockTransport.mu.Lock()
+		acked := len(mockTransport.acked)
+		mockTransport.mu.Unlock()
+		if acked == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	mockTransport.mu.Lock()
+	defer mockTransport.mu.Unlock()
+	if len(mockTransport.acked) != 2 {
+		t.Fatalf("Expected 2 acked messages, got %v", mockTransport.acked)
+	}
+	if mockTransport.receiveLimits[0] != 2 {
+		t.Errorf("First receive limit = %d, want 2 for two idle workers", mockTransport.receiveLimits[0])
+	}
+	// msg-2 waits for msg-1's runtime call, so both outlive the visibility timeout
+	for _, id := range []string{"msg-1", "msg-2"} {
+		if mockTransport.extended[id] == 0 {
+			t.Errorf("Expected visibility of %s to be extended while processing", id)
+		}
+	}
+	if len(mockTransport.sentMessages) != 2 {
+		t.Errorf("Expected 2 envelopes sent to happy-end, got %d", len(mockTransport.sentMessages))
+	}
+}
+
+func TestRouter_Run_HopTimeoutExcludesRuntimeTurnWait(t *testing.T) {
+	socketPath := fmt.Sprintf("/tmp/test-router-hop-timeout-%d.sock", time.Now().UnixNano())
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+	})
+
+	// Like the Python runtime, serve one call at a time
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if _, err := runtime.RecvSocketData(conn); err == nil {
+				time.Sleep(150 * time.Millisecond)
+				_ = runtime.SendSocketData(conn, []byte(`[]`))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	cfg := &config.Config{
+		ActorName:     "test-actor",
+		Namespace:     "default",
+		HappyEndQueue: "happy-end",
+		ErrorEndQueue: "error-end",
+		TransportType: "sqs",
+		Timeout:       2 * time.Second,
+	}
+	mockTransport := &mockBatchTransport{}
+	// Each call fits the hop timeout, but not after waiting for the other call
+	for _, id := range []string{"msg-1", "msg-2"} {
+		body, _ := json.Marshal(envelopes.Envelope{
+			ID: "envelope-" + id,
+			Route: envelopes.Route{
+				Actors:   []string{"test-actor"},
+				Current:  0,
+				Metadata: map[string]interface{}{envelopes.TimeoutsMetadataKey: map[string]interface{}{"test-actor": "250ms"}},
+			},
+			Payload: json.RawMessage(`{}`),
+		})
+		mockTransport.pending = append(mockTransport.pending, transport.QueueMessage{ID: id, Body: body})
+	}
+
+	router := NewRouter(cfg, mockTransport, runtime.NewClient(socketPath, cfg.Timeout), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- router.Run(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mockTransport.mu.Lock()
+		acked := len(mockTransport.acked)
+		mockTransport.mu.Unlock()
+		if acked == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	mockTransport.mu.Lock()
+	defer mockTransport.mu.Unlock()
+	if len(mockTransport.acked) != 2 {
+		t.Fatalf("Expected 2 acked messages, got %v", mockTransport.acked)
+	}
+	for _, sent := range mockTransport.sentMessages {
+		if sent.queue != "asya-default-happy-end" {
+			t.Errorf("Expected envelopes sent to happy-end only, got %s", sent.queue)
+		}
 	}
 }
@@ -19,12 +19,22 @@ type ErrorDetails struct {
 	Traceback string `json:"traceback,omitempty"`
 }
 
+// MetricObservation is a custom metric update emitted by the handler.
+// Name must match a metric declared in ASYA_CUSTOM_METRICS and Labels its declared labels.
+type MetricObservation struct {
+	Name   string            `json:"name"`
+	Value  float64           `json:"value"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
 // RuntimeResponse represents the response from the actor runtime
 type RuntimeResponse struct {
-	Payload json.RawMessage `json:"payload,omitempty"` // payload output from handler
-	Route   envelopes.Route `json:"route,omitempty"`   // route output from handler
-	Error   string          `json:"error,omitempty"`
-	Details ErrorDetails    `json:"details,omitempty"`
+	Payload json.RawMessage     `json:"payload,omitempty"` // payload output from handler
+	Route   envelopes.Route     `json:"route,omitempty"`   // route output from handler
+	Call    *envelopes.Call     `json:"call,omitempty"`    // sub-route to call before resuming Route (envelope mode)
+	Error   string              `json:"error,omitempty"`
+	Details ErrorDetails        `json:"details,omitempty"`
+	Metrics []MetricObservation `json:"metrics,omitempty"` // custom metrics emitted by handler
 }
 
 // IsError returns true if the response indicates an error
@@ -32,10 +42,17 @@ func (r *RuntimeResponse) IsError() bool {
 	return r.Error != ""
 }
 
-// Client handles communication with the actor runtime via Unix socket
+// IsMetricsOnly returns true if the response only carries metric observations and is not routed
+func (r *RuntimeResponse) IsMetricsOnly() bool {
+	return len(r.Metrics) > 0 && r.Error == "" && r.Payload == nil && len(r.Route.Actors) == 0
+}
+
+// Client handles communication with the actor runtime via Unix socket.
+// The runtime serves one request at a time, so concurrent calls take turns.
 type Client struct {
 	socketPath string
 	timeout    time.Duration
+	turn       chan struct{} // Holds a token while a call is in progress
 }
 
 // NewClient creates a new runtime client
@@ -43,6 +60,7 @@ func NewClient(socketPath string, timeout time.Duration) *Client {
 	return &Client{
 		socketPath: socketPath,
 		timeout:    timeout,
+		turn:       make(chan struct{}, 1),
 	}
 }
 
@@ -83,10 +101,27 @@ func RecvSocketData(conn net.Conn) ([]byte, error) {
 }
 
 // CallRuntime sends a full message (with route and payload) to the runtime and waits for response(s)
-// Returns multiple responses for fan-out, empty slice for abort, or error
+// Returns multiple responses for fan-out, empty slice for abort, or error.
+// The timeout applies from the call's turn, not from waiting for a concurrent call to finish.
 func (c *Client) CallRuntime(ctx context.Context, data []byte) ([]RuntimeResponse, error) {
-	// Apply timeout
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	return c.CallRuntimeWithTimeout(ctx, data, 0)
+}
+
+// CallRuntimeWithTimeout is CallRuntime with a shorter timeout than the client's, e.g. a per-hop timeout.
+// A zero timeout, or one not shorter than the client's, uses the client's timeout.
+func (c *Client) CallRuntimeWithTimeout(ctx context.Context, data []byte, timeout time.Duration) ([]RuntimeResponse, error) {
+	select {
+	case c.turn <- struct{}{}:
+		defer func() { <-c.turn }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Apply timeout from our turn
+	if timeout <= 0 || timeout > c.timeout {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Connect to Unix socket
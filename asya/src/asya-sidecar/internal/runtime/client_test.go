@@ -163,6 +163,101 @@ func TestClient_CallRuntime_Timeout(t *testing.T) {
 	}
 }
 
+func TestClient_CallRuntime_ConcurrentCallsTakeTurns(t *testing.T) {
+	socketPath, err := nettest.LocalPath()
+	if err != nil {
+		t.Fatalf("Failed to get local path: %v", err)
+	}
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	// Like the Python runtime, serve one connection at a time
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if _, err := RecvSocketData(conn); err == nil {
+				time.Sleep(150 * time.Millisecond)
+				_ = SendSocketData(conn, []byte(`[{"payload":{}}]`))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// Each call fits the timeout, but not after waiting for the others
+	client := NewClient(socketPath, 250*time.Millisecond)
+	messageData := []byte(`{"route":{"actors":["test"],"current":0},"payload":{"data":"test"}}`)
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := client.CallRuntime(context.Background(), messageData)
+			errs <- err
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("CallRuntime failed: %v", err)
+		}
+	}
+}
+
+func TestClient_CallRuntimeWithTimeout(t *testing.T) {
+	socketPath, err := nettest.LocalPath()
+	if err != nil {
+		t.Fatalf("Failed to get local path: %v", err)
+	}
+	defer func() { _ = os.Remove(socketPath) }()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if _, err := RecvSocketData(conn); err == nil {
+				time.Sleep(150 * time.Millisecond)
+				_ = SendSocketData(conn, []byte(`[{"payload":{}}]`))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	client := NewClient(socketPath, 2*time.Second)
+	messageData := []byte(`{"route":{"actors":["test"],"current":0},"payload":{"data":"test"}}`)
+
+	// Both calls fit the timeout from their turn, though the second waits for the first
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := client.CallRuntimeWithTimeout(context.Background(), messageData, 250*time.Millisecond)
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("CallRuntimeWithTimeout failed: %v", err)
+		}
+	}
+
+	if _, err := client.CallRuntimeWithTimeout(context.Background(), messageData, 50*time.Millisecond); err == nil {
+		t.Error("Expected timeout error with a shorter timeout but got nil")
+	}
+}
+
 func TestClient_CallRuntime_FanOut(t *testing.T) {
 	socketPath, err := nettest.LocalPath()
 	if err != nil {
@@ -409,3 +504,41 @@ func TestResponse_IsError(t *testing.T) {
 		})
 	}
 }
+
+func TestResponse_IsMetricsOnly(t *testing.T) {
+	metrics := []MetricObservation{{Name: "tokens_total", Value: 3}}
+	tests := []struct {
+		name     string
+		response RuntimeResponse
+		expected bool
+	}{
+		{
+			name:     "metrics only",
+			response: RuntimeResponse{Metrics: metrics},
+			expected: true,
+		},
+		{
+			name:     "success response with metrics",
+			response: RuntimeResponse{Payload: json.RawMessage(`{"ok": true}`), Metrics: metrics},
+			expected: false,
+		},
+		{
+			name:     "error response with metrics",
+			response: RuntimeResponse{Error: "processing_error", Metrics: metrics},
+			expected: false,
+		},
+		{
+			name:     "empty response",
+			response: RuntimeResponse{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.response.IsMetricsOnly(); result != tt.expected {
+				t.Errorf("IsMetricsOnly() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package signing signs envelopes so actors can detect injected or tampered envelopes.
+//
+// The gateway and every sidecar sign the envelopes they send with HMAC-SHA256 over the envelope and parent IDs,
+// the headers the platform reads (SignedHeaders), the route (actors, current index and metadata such as timeouts and compensations), the hop counters, the call stack,
+// the shadow and compensation markers and a SHA-256 digest of the payload. The signature is carried in the
+// SignatureHeader header as "{key-id}:{base64(mac)}" and checked by the receiving sidecar.
+//
+// Keys come from an encryption.KeyProvider, normally a keyring file mounted from a secret managed by
+// the operator. The signature names its key, so keys rotate like encryption keys.
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deliveryhero/asya/asya-sidecar/internal/encryption"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+// SignatureHeader is the envelope header carrying the signature
+const SignatureHeader = "asya_signature"
+
+// SignedHeaders are the envelope headers covered by the signature: the headers the platform reads.
+// Other headers are user-defined and may change in transit.
+var SignedHeaders = []string{envelopes.AttemptHeader, encryption.KeyIDHeader}
+
+// ErrUnsigned is returned when an envelope has no signature
+var ErrUnsigned = errors.New("envelope is not signed")
+
+// Signer signs and verifies envelopes
+type Signer struct {
+	provider encryption.KeyProvider
+}
+
+// NewSigner creates a signer using keys from provider
+func NewSigner(provider encryption.KeyProvider) *Signer {
+	return &Signer{provider: provider}
+}
+
+// Sign sets the signature header on the envelope. The headers map is copied, not modified in place.
+func (s *Signer) Sign(ctx context.Context, envelope *envelopes.Envelope) error {
+	keyID, key, err := s.provider.CurrentKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	mac, err := computeMAC(key, envelope)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]interface{}, len(envelope.Headers)+1)
+	for k, v := range envelope.Headers {
+		headers[k] = v
+	}
+	headers[SignatureHeader] = keyID + ":" + base64.StdEncoding.EncodeToString(mac)
+	envelope.Headers = headers
+	return nil
+}
+
+// Verify checks the envelope signature
+func (s *Signer) Verify(ctx context.Context, envelope envelopes.Envelope) error {
+	signature, ok := envelope.Headers[SignatureHeader].(string)
+	if !ok || signature == "" {
+		return ErrUnsigned
+	}
+
+	keyID, encoded, ok := strings.Cut(signature, ":")
+	if !ok || keyID == "" {
+		return errors.New("malformed envelope signature")
+	}
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("malformed envelope signature: %w", err)
+	}
+
+	key, err := s.provider.Key(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to get signing key %q: %w", keyID, err)
+	}
+
+	mac, err := computeMAC(key, &envelope)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, expected) {
+		return errors.New("envelope signature mismatch")
+	}
+	return nil
+}
+
+// signedFields is the canonical form of the signed envelope fields
+type signedFields struct {
+	ID            string                 `json:"id"`
+	ParentID      *string                `json:"parent_id,omitempty"`
+	Headers       map[string]interface{} `json:"headers,omitempty"` // SignedHeaders present in the envelope
+	Actors        []string               `json:"actors"`
+	Current       int                    `json:"current"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"` // Whole route metadata; maps marshal with sorted keys
+	PayloadSHA256 string                 `json:"payload_sha256"`
+	HopCount      int                    `json:"hop_count,omitempty"`
+	Visited       []string               `json:"visited,omitempty"`
+	MaxHops       int                    `json:"max_hops,omitempty"`
+
+	CallStack    []envelopes.CallFrame   `json:"call_stack,omitempty"`
+	Shadow       *envelopes.Shadow       `json:"shadow,omitempty"`
+	Compensation *envelopes.Compensation `json:"compensation,omitempty"`
+}
+
+func computeMAC(key []byte, envelope *envelopes.Envelope) ([]byte, error) {
+	digest, err := payloadDigest(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	actors := envelope.Route.Actors
+	if actors == nil {
+		actors = []string{}
+	}
+	message, err := json.Marshal(signedFields{
+		ID:            envelope.ID,
+		ParentID:      envelope.ParentID,
+		Headers:       signedHeaders(envelope.Headers),
+		Actors:        actors,
+		Current:       envelope.Route.Current,
+		Metadata:      envelope.Route.Metadata,
+		PayloadSHA256: digest,
+		HopCount:      envelope.HopCount,
+		Visited:       envelope.Visited,
+		MaxHops:       envelope.MaxHops,
+		CallStack:     envelope.CallStack,
+		Shadow:        envelope.Shadow,
+		Compensation:  envelope.Compensation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signed fields: %w", err)
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// signedHeaders returns the SignedHeaders set in headers, or nil if there are none
+func signedHeaders(headers map[string]interface{}) map[string]interface{} {
+	var signed map[string]interface{}
+	for _, name := range SignedHeaders {
+		if value, ok := headers[name]; ok {
+			if signed == nil {
+				signed = make(map[string]interface{}, len(SignedHeaders))
+			}
+			signed[name] = value
+		}
+	}
+	return signed
+}
+
+// payloadDigest returns the hex SHA-256 digest of the payload in the form it is sent on the wire:
+// compacted, with HTML characters escaped as encoding/json does. A missing payload digests as null.
+func payloadDigest(payload json.RawMessage) (string, error) {
+	if len(bytes.TrimSpace(payload)) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, payload); err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+	var canonical bytes.Buffer
+	json.HTMLEscape(&canonical, compacted.Bytes())
+
+	sum := sha256.Sum256(canonical.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,369 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deliveryhero/asya/asya-sidecar/internal/encryption"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+func writeKeyring(t *testing.T, path, active string, keys map[string]byte) {
+	t.Helper()
+	encoded := make(map[string]string, len(keys))
+	for id, b := range keys {
+		encoded[id] = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, encryption.KeySize))
+	}
+	data, _ := json.Marshal(map[string]any{"active": active, "keys": encoded})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+}
+
+func newTestSigner(t *testing.T, path, active string, keys map[string]byte) *Signer {
+	t.Helper()
+	writeKeyring(t, path, active, keys)
+	provider, err := encryption.NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	return NewSigner(provider)
+}
+
+func testEnvelope() envelopes.Envelope {
+	return envelopes.Envelope{
+		ID:      "env-1",
+		Route:   envelopes.Route{Actors: []string{"validate", "charge"}, Current: 1},
+		Headers: map[string]interface{}{"trace_id": "abc"},
+		Payload: json.RawMessage(`{"amount":10}`),
+	}
+}
+
+func TestSigner_SignVerify(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+	ctx := context.Background()
+
+	original := testEnvelope()
+	signed := original
+	if err := signer.Sign(ctx, &signed); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if _, ok := original.Headers[SignatureHeader]; ok {
+		t.Error("Sign modified the original headers map")
+	}
+	if signed.Headers["trace_id"] != "abc" {
+		t.Errorf("Sign dropped existing headers: %v", signed.Headers)
+	}
+	if err := signer.Verify(ctx, signed); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// Signature survives a marshal/unmarshal round trip through a queue
+	body, _ := json.Marshal(signed)
+	var received envelopes.Envelope
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := signer.Verify(ctx, received); err != nil {
+		t.Errorf("Verify after round trip failed: %v", err)
+	}
+
+	tampered := map[string]func(e *envelopes.Envelope){
+		"id":      func(e *envelopes.Envelope) { e.ID = "env-2" },
+		"actors":  func(e *envelopes.Envelope) { e.Route.Actors = []string{"charge"}; e.Route.Current = 0 },
+		"current": func(e *envelopes.Envelope) { e.Route.Current = 0 },
+		"payload": func(e *envelopes.Envelope) { e.Payload = json.RawMessage(`{"amount":1000}`) },
+		"hops":    func(e *envelopes.Envelope) { e.HopCount = 7; e.Visited = []string{"validate"} },
+		"maxHops": func(e *envelopes.Envelope) { e.MaxHops = 1000 },
+		"callStack": func(e *envelopes.Envelope) {
+			e.CallStack = []envelopes.CallFrame{{Caller: "validate", Route: envelopes.Route{Actors: []string{"refund"}}}}
+		},
+		"shadow": func(e *envelopes.Envelope) { e.Shadow = &envelopes.Shadow{Primary: "validate", Outcome: "succeeded"} },
+		"parentID": func(e *envelopes.Envelope) {
+			parent := "env-0"
+			e.ParentID = &parent
+		},
+		"attempt": func(e *envelopes.Envelope) { e.Headers = withHeader(e.Headers, envelopes.AttemptHeader, 1) },
+		"keyID":   func(e *envelopes.Envelope) { e.Headers = withHeader(e.Headers, encryption.KeyIDHeader, "k0") },
+	}
+	for name, tamper := range tampered {
+		e := received
+		tamper(&e)
+		if err := signer.Verify(ctx, e); err == nil {
+			t.Errorf("Verify accepted envelope with tampered %s", name)
+		}
+	}
+
+	// Headers the platform does not read may change in transit
+	e := received
+	e.Headers = withHeader(e.Headers, "trace_id", "def")
+	if err := signer.Verify(ctx, e); err != nil {
+		t.Errorf("Verify rejected envelope with a changed user header: %v", err)
+	}
+}
+
+// withHeader returns a copy of headers with name set to value
+func withHeader(headers map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(headers)+1)
+	for k, v := range headers {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
+
+func TestSigner_SignedHeadersAndParentID(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+	ctx := context.Background()
+
+	envelope := retriedChildEnvelope()
+	if err := signer.Sign(ctx, &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	body, _ := json.Marshal(envelope)
+	var received envelopes.Envelope
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := signer.Verify(ctx, received); err != nil {
+		t.Fatalf("Verify after round trip failed: %v", err)
+	}
+
+	tampered := map[string]func(e *envelopes.Envelope){
+		"attempt reset":   func(e *envelopes.Envelope) { e.Headers = withHeader(e.Headers, envelopes.AttemptHeader, 1) },
+		"key ID swapped":  func(e *envelopes.Envelope) { e.Headers = withHeader(e.Headers, encryption.KeyIDHeader, "k2") },
+		"parent retarget": func(e *envelopes.Envelope) { other := "env-9"; e.ParentID = &other },
+		"parent removed":  func(e *envelopes.Envelope) { e.ParentID = nil },
+		"attempt removed": func(e *envelopes.Envelope) {
+			e.Headers = withHeader(e.Headers, envelopes.AttemptHeader, nil)
+			delete(e.Headers, envelopes.AttemptHeader)
+		},
+	}
+	for name, tamper := range tampered {
+		e := received
+		tamper(&e)
+		if err := signer.Verify(ctx, e); err == nil {
+			t.Errorf("Verify accepted envelope with %s", name)
+		}
+	}
+}
+
+// retriedChildEnvelope is a fan-out child on its third attempt with an encrypted payload
+func retriedChildEnvelope() envelopes.Envelope {
+	envelope := testEnvelope()
+	parent := "env-0"
+	envelope.ParentID = &parent
+	envelope.Headers = map[string]interface{}{"trace_id": "abc", envelopes.AttemptHeader: 3, encryption.KeyIDHeader: "k1"}
+	return envelope
+}
+
+// TestSigner_KnownSignatureWithParentAndHeaders pins the signature of an envelope with a parent ID and
+// signed headers; the gateway's signing tests use the same vector
+func TestSigner_KnownSignatureWithParentAndHeaders(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+
+	envelope := retriedChildEnvelope()
+	if err := signer.Sign(context.Background(), &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:o4JbBf/v3DPHJq67bfco0n1N5n9KLcu2rpvszMRwBwA="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+// TestSigner_KnownSignature pins the signature format; the gateway's signing tests use the same vector
+func TestSigner_KnownSignature(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+
+	envelope := testEnvelope()
+	if err := signer.Sign(context.Background(), &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:L27gWnOpjLx8xI/5zUghIZ7Y/ZPcu5A/tnRbwz5dFEc="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+// TestSigner_KnownSignatureWithCallStack pins the signature of an envelope parked at an approval step,
+// which the gateway signs when resuming it; the gateway's signing tests use the same vector
+func TestSigner_KnownSignatureWithCallStack(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+
+	envelope := testEnvelope()
+	envelope.HopCount = 2
+	envelope.Visited = []string{"validate", "review"}
+	envelope.CallStack = []envelopes.CallFrame{{Caller: "order", Route: envelopes.Route{Actors: []string{"order", "ship"}, Current: 1}}}
+	if err := signer.Sign(context.Background(), &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:1SYgNgRiE95qyKnokQihiIxcuYVRDj5/q6rt+KKB0Og="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+// metadataEnvelope is testEnvelope with the route metadata the gateway sets from a tool's route
+func metadataEnvelope() envelopes.Envelope {
+	envelope := testEnvelope()
+	envelope.Route.Metadata = map[string]interface{}{
+		"job_id":                           "env-1",
+		envelopes.TimeoutsMetadataKey:      map[string]interface{}{"charge": "30s"},
+		envelopes.CompensationsMetadataKey: map[string]interface{}{"charge": "refund"},
+	}
+	return envelope
+}
+
+func TestSigner_RouteMetadataTampering(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+	ctx := context.Background()
+
+	envelope := metadataEnvelope()
+	if err := signer.Sign(ctx, &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	body, _ := json.Marshal(envelope)
+	var received envelopes.Envelope
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := signer.Verify(ctx, received); err != nil {
+		t.Fatalf("Verify after round trip failed: %v", err)
+	}
+
+	tampered := map[string]func(m map[string]interface{}){
+		"compensation target": func(m map[string]interface{}) {
+			m[envelopes.CompensationsMetadataKey] = map[string]interface{}{"charge": "payout"}
+		},
+		"compensations removed": func(m map[string]interface{}) { delete(m, envelopes.CompensationsMetadataKey) },
+		"timeout": func(m map[string]interface{}) {
+			m[envelopes.TimeoutsMetadataKey] = map[string]interface{}{"charge": "24h"}
+		},
+		"timeouts removed": func(m map[string]interface{}) { delete(m, envelopes.TimeoutsMetadataKey) },
+		"key added":        func(m map[string]interface{}) { m["extra"] = true },
+	}
+	for name, tamper := range tampered {
+		e := received
+		e.Route.Metadata = make(map[string]interface{}, len(received.Route.Metadata))
+		for k, v := range received.Route.Metadata {
+			e.Route.Metadata[k] = v
+		}
+		tamper(e.Route.Metadata)
+		if err := signer.Verify(ctx, e); err == nil {
+			t.Errorf("Verify accepted envelope with tampered route metadata: %s", name)
+		}
+	}
+}
+
+// TestSigner_KnownSignatureWithMetadata pins the signature of an envelope with route metadata;
+// the gateway's signing tests use the same vector
+func TestSigner_KnownSignatureWithMetadata(t *testing.T) {
+	signer := newTestSigner(t, filepath.Join(t.TempDir(), "keyring.json"), "k1", map[string]byte{"k1": 1})
+
+	envelope := metadataEnvelope()
+	if err := signer.Sign(context.Background(), &envelope); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	const want = "k1:Sin5KeJP07ndrDBNaO41dU+qeKP7QE1kKgUsBtNc9Mw="
+	if got := envelope.Headers[SignatureHeader]; got != want {
+		t.Errorf("Signature = %v, want %s", got, want)
+	}
+}
+
+func TestSigner_VerifyErrors(t *testing.T) {
+	dir := t.TempDir()
+	signer := newTestSigner(t, filepath.Join(dir, "a.json"), "k1", map[string]byte{"k1": 1})
+	other := newTestSigner(t, filepath.Join(dir, "b.json"), "k1", map[string]byte{"k1": 2})
+	ctx := context.Background()
+
+	if err := signer.Verify(ctx, testEnvelope()); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Verify(unsigned) = %v, want ErrUnsigned", err)
+	}
+
+	forged := testEnvelope()
+	if err := other.Sign(ctx, &forged); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := signer.Verify(ctx, forged); err == nil {
+		t.Error("Verify accepted envelope signed with a different key")
+	}
+
+	for _, signature := range []string{"no-separator", ":abc", "k1:!!!", "unknown:AAAA"} {
+		e := testEnvelope()
+		e.Headers = map[string]interface{}{SignatureHeader: signature}
+		if err := signer.Verify(ctx, e); err == nil {
+			t.Errorf("Verify accepted signature %q", signature)
+		}
+	}
+}
+
+func TestSigner_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	signer := newTestSigner(t, path, "k1", map[string]byte{"k1": 1})
+	ctx := context.Background()
+
+	old := testEnvelope()
+	if err := signer.Sign(ctx, &old); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// Rotate: k2 becomes active, k1 is kept for in-flight envelopes
+	writeKeyring(t, path, "k2", map[string]byte{"k1": 1, "k2": 2})
+	provider, err := encryption.NewKeyringProvider(path)
+	if err != nil {
+		t.Fatalf("NewKeyringProvider failed: %v", err)
+	}
+	rotated := NewSigner(provider)
+
+	if err := rotated.Verify(ctx, old); err != nil {
+		t.Errorf("Verify of envelope signed before rotation failed: %v", err)
+	}
+
+	current := testEnvelope()
+	if err := rotated.Sign(ctx, &current); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if sig, _ := current.Headers[SignatureHeader].(string); sig[:3] != "k2:" {
+		t.Errorf("Signature %q not made with active key k2", sig)
+	}
+}
+
+func TestPayloadDigest_Canonical(t *testing.T) {
+	equivalent := []string{
+		`{"html":"<b>&</b>","n":1}`,
+		"{\n  \"html\": \"<b>&</b>\",\n  \"n\": 1\n}",
+		`{"html":"\u003cb\u003e\u0026\u003c/b\u003e","n":1}`, // as re-marshaled by encoding/json
+	}
+	want, err := payloadDigest(json.RawMessage(equivalent[0]))
+	if err != nil {
+		t.Fatalf("payloadDigest failed: %v", err)
+	}
+	for _, payload := range equivalent[1:] {
+		got, err := payloadDigest(json.RawMessage(payload))
+		if err != nil {
+			t.Fatalf("payloadDigest(%s) failed: %v", payload, err)
+		}
+		if got != want {
+			t.Errorf("payloadDigest(%s) differs from compact form", payload)
+		}
+	}
+
+	null, _ := payloadDigest(json.RawMessage("null"))
+	if missing, _ := payloadDigest(nil); missing != null {
+		t.Error("Missing payload should digest as null")
+	}
+	if _, err := payloadDigest(json.RawMessage(`{"broken"`)); err == nil {
+		t.Error("Expected error for invalid payload")
+	}
+}
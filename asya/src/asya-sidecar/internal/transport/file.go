@@ -0,0 +1,365 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filesystem Queue Layout:
+//
+// Each queue is a directory under the transport root, shared by every producer and consumer
+// (e.g. a hostPath or PVC volume mounted into all pods on a single node):
+//
+//	{root}/{queue}/tmp/         - messages being written (renamed into ready/ when complete)
+//	{root}/{queue}/ready/       - messages waiting for a consumer, consumed in name order
+//	{root}/{queue}/processing/  - claimed messages and their .lease files
+//	{root}/{queue}/dead/        - messages that exceeded the maximum delivery count
+//
+// Message files are named "{unix_nanos}-{random}.{deliveries}.msg". A consumer claims a message by
+// renaming it from ready/ into processing/ (atomic on POSIX filesystems, so exactly one consumer
+// wins) and writes a lease file holding the visibility deadline. Expired leases are returned to
+// ready/ by any consumer, which provides SQS-like redelivery after a crash.
+
+const (
+	fileDirTmp        = "tmp"
+	fileDirReady      = "ready"
+	fileDirProcessing = "processing"
+	fileDirDead       = "dead"
+
+	fileMessageExt = ".msg"
+	fileLeaseExt   = ".lease"
+
+	defaultFilePollInterval  = 200 * time.Millisecond
+	defaultFileVisibility    = 5 * time.Minute
+	defaultFileMaxDeliveries = 3
+)
+
+// FileTransport implements Transport interface on top of a shared directory tree
+type FileTransport struct {
+	root              string
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	maxDeliveries     int
+}
+
+// FileConfig holds file transport configuration
+type FileConfig struct {
+	Root              string
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+	MaxDeliveries     int // Deliveries before a message moves to dead/ (0 = default)
+}
+
+// fileLease is the content of a lease file
+type fileLease struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFileTransport creates a new file transport
+func NewFileTransport(cfg FileConfig) (*FileTransport, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("file transport root directory is required")
+	}
+	if err := os.MkdirAll(cfg.Root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create file transport root %s: %w", cfg.Root, err)
+	}
+
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = defaultFileVisibility
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultFilePollInterval
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = defaultFileMaxDeliveries
+	}
+
+	return &FileTransport{
+		root:              cfg.Root,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+		maxDeliveries:     maxDeliveries,
+	}, nil
+}
+
+// queueDir returns the directory for a queue subdirectory, rejecting names that escape the root
+func (t *FileTransport) queueDir(queueName, sub string) (string, error) {
+	if queueName == "" || strings.ContainsAny(queueName, `/\`) || queueName == "." || queueName == ".." {
+		return "", fmt.Errorf("invalid queue name %q", queueName)
+	}
+	return filepath.Join(t.root, queueName, sub), nil
+}
+
+// ensureQueue creates the queue directory layout if it does not exist
+func (t *FileTransport) ensureQueue(queueName string) error {
+	for _, sub := range []string{fileDirTmp, fileDirReady, fileDirProcessing, fileDirDead} {
+		dir, err := t.queueDir(queueName, sub)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create queue directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Receive claims the oldest ready message, polling until one arrives or context is cancelled
+func (t *FileTransport) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	if err := t.ensureQueue(queueName); err != nil {
+		return QueueMessage{}, err
+	}
+
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		t.reclaimExpired(queueName)
+
+		msg, ok, err := t.claimNext(queueName)
+		if err != nil {
+			return QueueMessage{}, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return QueueMessage{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimNext tries to claim ready messages in order; ok is false when the queue is empty
+func (t *FileTransport) claimNext(queueName string) (QueueMessage, bool, error) {
+	readyDir, _ := t.queueDir(queueName, fileDirReady)
+	processingDir, _ := t.queueDir(queueName, fileDirProcessing)
+	deadDir, _ := t.queueDir(queueName, fileDirDead)
+
+	names, err := listFiles(readyDir, fileMessageExt)
+	if err != nil {
+		return QueueMessage{}, false, fmt.Errorf("failed to list queue %s: %w", queueName, err)
+	}
+
+	for _, name := range names {
+		base, deliveries := parseMessageName(name)
+		deliveries++
+		claimedName := fmt.Sprintf("%s.%d%s", base, deliveries, fileMessageExt)
+		claimedPath := filepath.Join(processingDir, claimedName)
+
+		// Refresh mtime before claiming so an orphaned claim (no lease) is not reclaimed early
+		now := time.Now()
+		_ = os.Chtimes(filepath.Join(readyDir, name), now, now)
+
+		if err := os.Rename(filepath.Join(readyDir, name), claimedPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // Claimed by another consumer
+			}
+			return QueueMessage{}, false, fmt.Errorf("failed to claim message %s: %w", name, err)
+		}
+
+		if deliveries > t.maxDeliveries {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter directory",
+				"queue", queueName, "message", base, "deliveries", deliveries-1, "maxDeliveries", t.maxDeliveries)
+			if err := os.Rename(claimedPath, filepath.Join(deadDir, name)); err != nil {
+				slog.Error("Failed to dead-letter message", "queue", queueName, "message", base, "error", err)
+			}
+			continue
+		}
+
+		if err := t.writeLease(claimedPath, now.Add(t.visibilityTimeout)); err != nil {
+			_ = os.Rename(claimedPath, filepath.Join(readyDir, name))
+			return QueueMessage{}, false, err
+		}
+
+		body, err := os.ReadFile(claimedPath)
+		if err != nil {
+			return QueueMessage{}, false, fmt.Errorf("failed to read message %s: %w", claimedName, err)
+		}
+
+		return QueueMessage{
+			ID:            base,
+			Body:          body,
+			ReceiptHandle: claimedPath,
+			Headers: map[string]string{
+				"QueueName":  queueName,
+				"Deliveries": strconv.Itoa(deliveries),
+			},
+		}, true, nil
+	}
+
+	return QueueMessage{}, false, nil
+}
+
+// writeLease atomically writes the lease file for a claimed message
+func (t *FileTransport) writeLease(claimedPath string, expiresAt time.Time) error {
+	data, err := json.Marshal(fileLease{ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease: %w", err)
+	}
+	tmpPath := claimedPath + fileLeaseExt + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := os.Rename(tmpPath, claimedPath+fileLeaseExt); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
+
+// reclaimExpired returns claimed messages whose lease expired (or was never written) to ready/
+func (t *FileTransport) reclaimExpired(queueName string) {
+	readyDir, _ := t.queueDir(queueName, fileDirReady)
+	processingDir, _ := t.queueDir(queueName, fileDirProcessing)
+
+	names, err := listFiles(processingDir, fileMessageExt)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		claimedPath := filepath.Join(processingDir, name)
+
+		expired := false
+		data, err := os.ReadFile(claimedPath + fileLeaseExt)
+		switch {
+		case err == nil:
+			var lease fileLease
+			expired = json.Unmarshal(data, &lease) != nil || now.After(lease.ExpiresAt)
+		case errors.Is(err, os.ErrNotExist):
+			// Consumer crashed between claim and lease write
+			if info, statErr := os.Stat(claimedPath); statErr == nil {
+				expired = now.Sub(info.ModTime()) > t.visibilityTimeout
+			}
+		}
+		if !expired {
+			continue
+		}
+
+		if err := os.Rename(claimedPath, filepath.Join(readyDir, name)); err != nil {
+			continue // Acked or reclaimed concurrently
+		}
+		_ = os.Remove(claimedPath + fileLeaseExt)
+		slog.Info("Lease expired, message returned to queue", "queue", queueName, "message", name)
+	}
+}
+
+// Send atomically writes a message into the queue's ready directory
+func (t *FileTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	if err := t.ensureQueue(queueName); err != nil {
+		return err
+	}
+	tmpDir, _ := t.queueDir(queueName, fileDirTmp)
+	readyDir, _ := t.queueDir(queueName, fileDirReady)
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to generate message name: %w", err)
+	}
+	name := fmt.Sprintf("%020d-%s.0%s", time.Now().UnixNano(), hex.EncodeToString(suffix), fileMessageExt)
+	tmpPath := filepath.Join(tmpDir, name)
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create message file: %w", err)
+	}
+	if _, err := f.Write(body); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync message: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close message file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(readyDir, name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to publish message to queue %s: %w", queueName, err)
+	}
+	return nil
+}
+
+// Ack deletes a claimed message and its lease
+func (t *FileTransport) Ack(ctx context.Context, msg QueueMessage) error {
+	claimedPath, ok := msg.ReceiptHandle.(string)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+	if err := os.Remove(claimedPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	_ = os.Remove(claimedPath + fileLeaseExt)
+	return nil
+}
+
+// Nack returns a claimed message to the ready directory for immediate redelivery
+func (t *FileTransport) Nack(ctx context.Context, msg QueueMessage) error {
+	claimedPath, ok := msg.ReceiptHandle.(string)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+	readyPath := filepath.Join(filepath.Dir(filepath.Dir(claimedPath)), fileDirReady, filepath.Base(claimedPath))
+	if err := os.Rename(claimedPath, readyPath); err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	_ = os.Remove(claimedPath + fileLeaseExt)
+	return nil
+}
+
+// Close is a no-op for the file transport
+func (t *FileTransport) Close() error {
+	return nil
+}
+
+// listFiles returns sorted names of files in dir with the given extension
+func listFiles(dir, ext string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseMessageName splits "{base}.{deliveries}.msg" into base and delivery count
+func parseMessageName(name string) (string, int) {
+	trimmed := strings.TrimSuffix(name, fileMessageExt)
+	idx := strings.LastIndex(trimmed, ".")
+	if idx < 0 {
+		return trimmed, 0
+	}
+	deliveries, err := strconv.Atoi(trimmed[idx+1:])
+	if err != nil {
+		return trimmed, 0
+	}
+	return trimmed[:idx], deliveries
+}
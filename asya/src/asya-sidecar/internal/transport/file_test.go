@@ -0,0 +1,197 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileTransport(t *testing.T, cfg FileConfig) *FileTransport {
+	t.Helper()
+	cfg.Root = t.TempDir()
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 5 * time.Millisecond
+	}
+	tp, err := NewFileTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewFileTransport failed: %v", err)
+	}
+	return tp
+}
+
+func receiveWithTimeout(t *testing.T, tp *FileTransport, queue string) (QueueMessage, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	return tp.Receive(ctx, queue)
+}
+
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir %s failed: %v", dir, err)
+	}
+	return len(entries)
+}
+
+func TestNewFileTransport_RequiresRoot(t *testing.T) {
+	if _, err := NewFileTransport(FileConfig{}); err == nil {
+		t.Fatal("Expected error for empty root")
+	}
+}
+
+func TestFileTransport_SendReceiveAck(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{})
+	ctx := context.Background()
+
+	for _, body := range []string{`{"n":1}`, `{"n":2}`} {
+		if err := tp.Send(ctx, testQueueName, []byte(body)); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	// Messages are consumed in send order
+	for _, want := range []string{`{"n":1}`, `{"n":2}`} {
+		msg, err := receiveWithTimeout(t, tp, testQueueName)
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		if string(msg.Body) != want {
+			t.Errorf("Body = %s, want %s", msg.Body, want)
+		}
+		if msg.Headers["QueueName"] != testQueueName {
+			t.Errorf("QueueName header = %q, want %q", msg.Headers["QueueName"], testQueueName)
+		}
+		if msg.Headers["Deliveries"] != "1" {
+			t.Errorf("Deliveries header = %q, want 1", msg.Headers["Deliveries"])
+		}
+		if err := tp.Ack(ctx, msg); err != nil {
+			t.Fatalf("Ack failed: %v", err)
+		}
+	}
+
+	if n := countFiles(t, filepath.Join(tp.root, testQueueName, fileDirProcessing)); n != 0 {
+		t.Errorf("processing/ has %d files after ack, want 0", n)
+	}
+}
+
+func TestFileTransport_ReceiveCancelled(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{})
+
+	_, err := receiveWithTimeout(t, tp, testQueueName)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Receive error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestFileTransport_InvalidQueueName(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{})
+
+	for _, name := range []string{"", "..", "a/b"} {
+		if err := tp.Send(context.Background(), name, []byte("x")); err == nil {
+			t.Errorf("Send(%q) expected error", name)
+		}
+	}
+}
+
+func TestFileTransport_NackRedelivers(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{})
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, testQueueName, []byte("retry")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	msg, err := receiveWithTimeout(t, tp, testQueueName)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if err := tp.Nack(ctx, msg); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+
+	redelivered, err := receiveWithTimeout(t, tp, testQueueName)
+	if err != nil {
+		t.Fatalf("Receive after nack failed: %v", err)
+	}
+	if redelivered.ID != msg.ID {
+		t.Errorf("Redelivered ID = %q, want %q", redelivered.ID, msg.ID)
+	}
+	if redelivered.Headers["Deliveries"] != "2" {
+		t.Errorf("Deliveries header = %q, want 2", redelivered.Headers["Deliveries"])
+	}
+}
+
+func TestFileTransport_LeaseExpiry(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{VisibilityTimeout: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, testQueueName, []byte("slow")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	first, err := receiveWithTimeout(t, tp, testQueueName)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+
+	// No ack: the lease expires and the message becomes visible again
+	second, err := receiveWithTimeout(t, tp, testQueueName)
+	if err != nil {
+		t.Fatalf("Receive after lease expiry failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Redelivered ID = %q, want %q", second.ID, first.ID)
+	}
+
+	// Ack of the stale receipt handle must not fail
+	if err := tp.Ack(ctx, first); err != nil {
+		t.Errorf("Ack of stale handle failed: %v", err)
+	}
+}
+
+func TestFileTransport_DeadLetter(t *testing.T) {
+	tp := newTestFileTransport(t, FileConfig{MaxDeliveries: 2})
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, testQueueName, []byte("poison")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		msg, err := receiveWithTimeout(t, tp, testQueueName)
+		if err != nil {
+			t.Fatalf("Receive %d failed: %v", i+1, err)
+		}
+		if err := tp.Nack(ctx, msg); err != nil {
+			t.Fatalf("Nack failed: %v", err)
+		}
+	}
+
+	if _, err := receiveWithTimeout(t, tp, testQueueName); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Receive error = %v, want context.DeadlineExceeded after dead-lettering", err)
+	}
+	if n := countFiles(t, filepath.Join(tp.root, testQueueName, fileDirDead)); n != 1 {
+		t.Errorf("dead/ has %d files, want 1", n)
+	}
+}
+
+func TestParseMessageName(t *testing.T) {
+	tests := []struct {
+		name           string
+		wantBase       string
+		wantDeliveries int
+	}{
+		{"00000000000000000001-ab.0.msg", "00000000000000000001-ab", 0},
+		{"00000000000000000001-ab.3.msg", "00000000000000000001-ab", 3},
+		{"plain.msg", "plain", 0},
+	}
+	for _, tt := range tests {
+		base, deliveries := parseMessageName(tt.name)
+		if base != tt.wantBase || deliveries != tt.wantDeliveries {
+			t.Errorf("parseMessageName(%q) = (%q, %d), want (%q, %d)",
+				tt.name, base, deliveries, tt.wantBase, tt.wantDeliveries)
+		}
+	}
+}
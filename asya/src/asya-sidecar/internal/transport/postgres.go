@@ -0,0 +1,278 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres Queue Schema:
+//
+// All queues share one jobs table keyed by queue_name ("asya-{namespace}-{actor}").
+// A message is visible when visible_at <= NOW(). Claiming a message pushes visible_at
+// forward by the visibility timeout (the lease), so a consumer that dies without acking
+// releases the message automatically when the lease expires. Claims use
+// FOR UPDATE SKIP LOCKED so concurrent consumers never block on or double-claim a row.
+// Messages claimed more than MaxDeliveries times move to the dead-letter table.
+//
+// The schema matches the gateway migration 005_add_queue_tables.
+
+// postgresSchema creates the queue tables if they do not exist
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS asya_queues (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS asya_queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL DEFAULT 0,
+    visible_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_messages_visible ON asya_queue_messages(queue_name, visible_at, id);
+
+CREATE TABLE IF NOT EXISTS asya_queue_dead_letters (
+    id BIGINT PRIMARY KEY,
+    queue_name TEXT NOT NULL,
+    body BYTEA NOT NULL,
+    deliveries INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    dead_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_asya_queue_dead_letters_queue ON asya_queue_dead_letters(queue_name, dead_at DESC);
+`
+
+const (
+	postgresClaimSQL = `
+WITH next AS (
+    SELECT id FROM asya_queue_messages
+    WHERE queue_name = $1 AND visible_at <= NOW()
+    ORDER BY visible_at, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE asya_queue_messages m
+SET deliveries = m.deliveries + 1,
+    visible_at = NOW() + make_interval(secs => $2),
+    locked_at = NOW()
+FROM next
+WHERE m.id = next.id
+RETURNING m.id, m.body, m.deliveries`
+
+	postgresDeadLetterSQL = `
+WITH dead AS (
+    DELETE FROM asya_queue_messages WHERE id = $1
+    RETURNING id, queue_name, body, deliveries, created_at
+)
+INSERT INTO asya_queue_dead_letters (id, queue_name, body, deliveries, created_at)
+SELECT id, queue_name, body, deliveries - 1, created_at FROM dead`
+
+	postgresSendSQL = `INSERT INTO asya_queue_messages (queue_name, body, visible_at) VALUES ($1, $2, NOW() + make_interval(secs => $3))`
+	postgresAckSQL  = `DELETE FROM asya_queue_messages WHERE id = $1 AND deliveries = $2`
+	postgresNackSQL = `UPDATE asya_queue_messages SET visible_at = NOW(), locked_at = NULL WHERE id = $1 AND deliveries = $2`
+
+	defaultPostgresPollInterval  = 500 * time.Millisecond
+	defaultPostgresVisibility    = 5 * time.Minute
+	defaultPostgresMaxDeliveries = 3
+)
+
+// pgQuerier is the subset of pgxpool.Pool used by the transport
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Close()
+}
+
+// PostgresTransport implements Transport interface for PostgreSQL
+type PostgresTransport struct {
+	pool              pgQuerier
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	maxDeliveries     int
+}
+
+// PostgresConfig holds PostgreSQL transport configuration
+type PostgresConfig struct {
+	URL               string
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+	MaxDeliveries     int  // Deliveries before a message moves to the dead-letter table (0 = default)
+	AutoCreate        bool // Create queue tables on startup
+}
+
+// postgresReceipt identifies a claimed message; deliveries acts as the lease token
+type postgresReceipt struct {
+	id         int64
+	deliveries int
+}
+
+// NewPostgresTransport creates a new PostgreSQL transport
+func NewPostgresTransport(ctx context.Context, cfg PostgresConfig) (*PostgresTransport, error) {
+	pool, err := pgxpool.New(ctx, cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if cfg.AutoCreate {
+		if _, err := pool.Exec(ctx, postgresSchema); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to create queue tables: %w", err)
+		}
+		slog.Info("Postgres queue tables ensured")
+	}
+
+	return newPostgresTransport(pool, cfg), nil
+}
+
+func newPostgresTransport(pool pgQuerier, cfg PostgresConfig) *PostgresTransport {
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = defaultPostgresVisibility
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPostgresPollInterval
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = defaultPostgresMaxDeliveries
+	}
+
+	return &PostgresTransport{
+		pool:              pool,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+		maxDeliveries:     maxDeliveries,
+	}
+}
+
+// Receive claims the next visible message, polling until one arrives or context is cancelled
+func (t *PostgresTransport) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		msg, ok, err := t.claim(ctx, queueName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return QueueMessage{}, ctx.Err()
+			}
+			return QueueMessage{}, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return QueueMessage{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim leases the next visible message; ok is false when none is available.
+// Messages over the delivery limit are dead-lettered and the next one is tried.
+func (t *PostgresTransport) claim(ctx context.Context, queueName string) (QueueMessage, bool, error) {
+	for {
+		var (
+			id         int64
+			body       []byte
+			deliveries int
+		)
+		err := t.pool.QueryRow(ctx, postgresClaimSQL, queueName, t.visibilityTimeout.Seconds()).Scan(&id, &body, &deliveries)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return QueueMessage{}, false, nil
+		}
+		if err != nil {
+			return QueueMessage{}, false, fmt.Errorf("failed to claim message from %s: %w", queueName, err)
+		}
+
+		if deliveries > t.maxDeliveries {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter table",
+				"queue", queueName, "id", id, "deliveries", deliveries-1, "maxDeliveries", t.maxDeliveries)
+			if _, err := t.pool.Exec(ctx, postgresDeadLetterSQL, id); err != nil {
+				return QueueMessage{}, false, fmt.Errorf("failed to dead-letter message %d: %w", id, err)
+			}
+			continue
+		}
+
+		return QueueMessage{
+			ID:            strconv.FormatInt(id, 10),
+			Body:          body,
+			ReceiptHandle: postgresReceipt{id: id, deliveries: deliveries},
+			Headers: map[string]string{
+				"QueueName":  queueName,
+				"Deliveries": strconv.Itoa(deliveries),
+			},
+		}, true, nil
+	}
+}
+
+// Send inserts a message that is immediately visible
+func (t *PostgresTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	return t.SendDelayed(ctx, queueName, body, 0)
+}
+
+// SendDelayed inserts a message that becomes visible after delay
+func (t *PostgresTransport) SendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error {
+	if _, err := t.pool.Exec(ctx, postgresSendSQL, queueName, body, delay.Seconds()); err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", queueName, err)
+	}
+	return nil
+}
+
+// Ack deletes a claimed message.
+// If the lease already expired and the message was claimed again, the ack is a no-op.
+func (t *PostgresTransport) Ack(ctx context.Context, msg QueueMessage) error {
+	receipt, ok := msg.ReceiptHandle.(postgresReceipt)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+
+	tag, err := t.pool.Exec(ctx, postgresAckSQL, receipt.id, receipt.deliveries)
+	if err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		slog.Warn("Ack after lease expired, message may be redelivered", "id", receipt.id)
+	}
+	return nil
+}
+
+// Nack makes a claimed message visible again immediately
+func (t *PostgresTransport) Nack(ctx context.Context, msg QueueMessage) error {
+	receipt, ok := msg.ReceiptHandle.(postgresReceipt)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+
+	if _, err := t.pool.Exec(ctx, postgresNackSQL, receipt.id, receipt.deliveries); err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (t *PostgresTransport) Close() error {
+	t.pool.Close()
+	return nil
+}
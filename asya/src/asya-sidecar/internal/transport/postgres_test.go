@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRow implements pgx.Row for a claimed message
+type fakeRow struct {
+	id         int64
+	body       []byte
+	deliveries int
+	err        error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.id
+	*dest[1].(*[]byte) = r.body
+	*dest[2].(*int) = r.deliveries
+	return nil
+}
+
+// fakePgQuerier records statements and returns queued claim rows
+type fakePgQuerier struct {
+	rows     []fakeRow
+	execs    []string
+	execArgs [][]any
+	affected int64
+	closed   bool
+}
+
+func (q *fakePgQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.execs = append(q.execs, sql)
+	q.execArgs = append(q.execArgs, args)
+	return pgconn.NewCommandTag("DELETE " + strings.Repeat("1", int(q.affected))), nil
+}
+
+func (q *fakePgQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if len(q.rows) == 0 {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+	row := q.rows[0]
+	q.rows = q.rows[1:]
+	return row
+}
+
+func (q *fakePgQuerier) Close() {
+	q.closed = true
+}
+
+func TestPostgresTransport_ReceiveAck(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakeRow{{id: 42, body: []byte(`{"id":"env-1"}`), deliveries: 1}}, affected: 1}
+	tp := newPostgresTransport(q, PostgresConfig{PollInterval: time.Millisecond})
+
+	msg, err := tp.Receive(context.Background(), testQueueName)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.ID != "42" || string(msg.Body) != `{"id":"env-1"}` {
+		t.Errorf("Unexpected message: id=%s body=%s", msg.ID, msg.Body)
+	}
+	if msg.Headers["QueueName"] != testQueueName || msg.Headers["Deliveries"] != "1" {
+		t.Errorf("Unexpected headers: %v", msg.Headers)
+	}
+
+	if err := tp.Ack(context.Background(), msg); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if len(q.execs) != 1 || q.execs[0] != postgresAckSQL {
+		t.Fatalf("Expected ack statement, got %v", q.execs)
+	}
+	if q.execArgs[0][0] != int64(42) || q.execArgs[0][1] != 1 {
+		t.Errorf("Ack args = %v, want [42 1]", q.execArgs[0])
+	}
+}
+
+func TestPostgresTransport_DeadLetter(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakeRow{
+		{id: 1, body: []byte("poison"), deliveries: 4},
+		{id: 2, body: []byte("ok"), deliveries: 1},
+	}}
+	tp := newPostgresTransport(q, PostgresConfig{MaxDeliveries: 3, PollInterval: time.Millisecond})
+
+	msg, err := tp.Receive(context.Background(), testQueueName)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.ID != "2" {
+		t.Errorf("Expected message 2 after dead-lettering, got %s", msg.ID)
+	}
+	if len(q.execs) != 1 || q.execs[0] != postgresDeadLetterSQL || q.execArgs[0][0] != int64(1) {
+		t.Errorf("Expected message 1 dead-lettered, got %v %v", q.execs, q.execArgs)
+	}
+}
+
+func TestPostgresTransport_ReceiveCancelled(t *testing.T) {
+	tp := newPostgresTransport(&fakePgQuerier{}, PostgresConfig{PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := tp.Receive(ctx, testQueueName); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Receive error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPostgresTransport_ClaimError(t *testing.T) {
+	q := &fakePgQuerier{rows: []fakeRow{{err: errors.New("connection reset")}}}
+	tp := newPostgresTransport(q, PostgresConfig{})
+
+	if _, err := tp.Receive(context.Background(), testQueueName); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Fatalf("Expected claim error, got %v", err)
+	}
+}
+
+func TestPostgresTransport_SendDelayed(t *testing.T) {
+	q := &fakePgQuerier{}
+	tp := newPostgresTransport(q, PostgresConfig{})
+
+	if err := tp.SendDelayed(context.Background(), testQueueName, []byte("later"), 90*time.Second); err != nil {
+		t.Fatalf("SendDelayed failed: %v", err)
+	}
+	if err := tp.Send(context.Background(), testQueueName, []byte("now")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if q.execArgs[0][2] != float64(90) || q.execArgs[1][2] != float64(0) {
+		t.Errorf("Delay args = %v, %v, want 90 and 0", q.execArgs[0][2], q.execArgs[1][2])
+	}
+}
+
+func TestPostgresTransport_InvalidReceipt(t *testing.T) {
+	tp := newPostgresTransport(&fakePgQuerier{}, PostgresConfig{})
+
+	if err := tp.Ack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Ack error for invalid receipt")
+	}
+	if err := tp.Nack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Nack error for invalid receipt")
+	}
+}
+
+// TestPostgresTransport_Integration runs against a real database when ASYA_TEST_POSTGRES_URL is set
+func TestPostgresTransport_Integration(t *testing.T) {
+	url := os.Getenv("ASYA_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("ASYA_TEST_POSTGRES_URL not set")
+	}
+
+	ctx := context.Background()
+	tp, err := NewPostgresTransport(ctx, PostgresConfig{
+		URL:               url,
+		VisibilityTimeout: time.Second,
+		PollInterval:      10 * time.Millisecond,
+		MaxDeliveries:     2,
+		AutoCreate:        true,
+	})
+	if err != nil {
+		t.Fatalf("NewPostgresTransport failed: %v", err)
+	}
+	defer func() { _ = tp.Close() }()
+
+	queue := "asya-test-" + strings.ReplaceAll(t.Name(), "/", "-")
+	if _, err := tp.pool.Exec(ctx, "DELETE FROM asya_queue_messages WHERE queue_name = $1", queue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tp.Send(ctx, queue, []byte("a")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := tp.SendDelayed(ctx, queue, []byte("delayed"), time.Hour); err != nil {
+		t.Fatalf("SendDelayed failed: %v", err)
+	}
+
+	msg, err := tp.Receive(ctx, queue)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if string(msg.Body) != "a" {
+		t.Errorf("Body = %s, want a", msg.Body)
+	}
+
+	// Delayed message is not visible and the claimed one is leased
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := tp.Receive(shortCtx, queue); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected no visible messages, got %v", err)
+	}
+
+	if err := tp.Nack(ctx, msg); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+	redelivered, err := tp.Receive(ctx, queue)
+	if err != nil {
+		t.Fatalf("Receive after nack failed: %v", err)
+	}
+	if redelivered.Headers["Deliveries"] != "2" {
+		t.Errorf("Deliveries = %s, want 2", redelivered.Headers["Deliveries"])
+	}
+	if err := tp.Ack(ctx, redelivered); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+}
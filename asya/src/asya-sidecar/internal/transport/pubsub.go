@@ -0,0 +1,450 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/status"
+)
+
+// Pub/Sub Layout:
+//
+// Each queue "asya-{namespace}-{actor}" is a topic with a subscription of the same name,
+// created by the operator. Send publishes to the topic; Receive reads the subscription
+// over a streaming pull kept open per queue. While a message is held (buffered or being
+// processed) its ack deadline is extended in the background, up to VisibilityTimeout,
+// after which Pub/Sub redelivers it. Nack sets the ack deadline to 0 for immediate
+// redelivery. Dead-lettering is done by Pub/Sub through the subscription's
+// dead-letter policy; DeliveryAttempt is only populated when that policy is set.
+//
+// The layout matches the gateway client (asya-gateway/internal/queue/pubsub.go).
+
+const (
+	defaultPubSubEndpoint       = "pubsub.googleapis.com:443"
+	defaultPubSubAckDeadline    = 60 * time.Second
+	defaultPubSubVisibility     = 5 * time.Minute
+	defaultPubSubMaxOutstanding = 1
+	pubsubScope                 = "https://www.googleapis.com/auth/pubsub"
+	pubsubReconnectBackoff      = time.Second
+	pubsubMaxReconnectBackoff   = 30 * time.Second
+)
+
+// PubSubTransport implements Transport interface for Google Cloud Pub/Sub
+type PubSubTransport struct {
+	conn              *grpc.ClientConn
+	publisher         pubsubpb.PublisherClient
+	subscriber        pubsubpb.SubscriberClient
+	projectID         string
+	clientID          string
+	ackDeadline       time.Duration
+	visibilityTimeout time.Duration
+	maxOutstanding    int
+
+	ctx    context.Context // Lifetime of streaming pulls and lease extension
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]*pubsubStream
+	leases  map[string]*pubsubLease // keyed by ack ID
+}
+
+// PubSubConfig holds Pub/Sub transport configuration
+type PubSubConfig struct {
+	ProjectID         string
+	EmulatorHost      string        // host:port of the Pub/Sub emulator; disables TLS and credentials
+	Endpoint          string        // gRPC endpoint (default pubsub.googleapis.com:443)
+	ClientID          string        // Identifies this replica's streaming pulls (pod name)
+	AckDeadline       time.Duration // Ack deadline requested for each lease extension (10s-600s)
+	VisibilityTimeout time.Duration // Maximum time a message is held before Pub/Sub may redeliver it
+	MaxOutstanding    int           // Unacked messages the server may push to this replica (0 = default)
+}
+
+// pubsubStream is a streaming pull on one subscription
+type pubsubStream struct {
+	subscription string
+	msgs         chan *pubsubpb.ReceivedMessage
+	done         chan struct{} // closed when the stream stops for good
+	err          error         // set before done is closed
+}
+
+// pubsubLease tracks a held message whose ack deadline is being extended
+type pubsubLease struct {
+	subscription string
+	expires      time.Time // extension stops after this, letting Pub/Sub redeliver
+}
+
+// pubsubReceipt identifies a delivered message
+type pubsubReceipt struct {
+	subscription string
+	ackID        string
+}
+
+// NewPubSubTransport creates a new Pub/Sub transport.
+// Outside the emulator, credentials come from Application Default Credentials,
+// which resolve to the pod's Google service account under GKE Workload Identity.
+func NewPubSubTransport(ctx context.Context, cfg PubSubConfig) (*PubSubTransport, error) {
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("pubsub project ID is required")
+	}
+
+	conn, err := dialPubSub(ctx, cfg.Endpoint, cfg.EmulatorHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPubSubTransport(conn, cfg), nil
+}
+
+// dialPubSub opens a gRPC connection to Pub/Sub or its emulator
+func dialPubSub(ctx context.Context, endpoint, emulatorHost string) (*grpc.ClientConn, error) {
+	if emulatorHost != "" {
+		conn, err := grpc.NewClient(emulatorHost, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Pub/Sub emulator: %w", err)
+		}
+		return conn, nil
+	}
+
+	if endpoint == "" {
+		endpoint = defaultPubSubEndpoint
+	}
+
+	tokenSource, err := google.DefaultTokenSource(ctx, pubsubScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google credentials: %w", err)
+	}
+
+	conn, err := grpc.NewClient(endpoint,
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")),
+		grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokenSource}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Pub/Sub: %w", err)
+	}
+	return conn, nil
+}
+
+func newPubSubTransport(conn *grpc.ClientConn, cfg PubSubConfig) *PubSubTransport {
+	ackDeadline := cfg.AckDeadline
+	if ackDeadline == 0 {
+		ackDeadline = defaultPubSubAckDeadline
+	}
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = defaultPubSubVisibility
+	}
+	maxOutstanding := cfg.MaxOutstanding
+	if maxOutstanding == 0 {
+		maxOutstanding = defaultPubSubMaxOutstanding
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &PubSubTransport{
+		conn:              conn,
+		publisher:         pubsubpb.NewPublisherClient(conn),
+		subscriber:        pubsubpb.NewSubscriberClient(conn),
+		projectID:         cfg.ProjectID,
+		clientID:          cfg.ClientID,
+		ackDeadline:       ackDeadline,
+		visibilityTimeout: visibilityTimeout,
+		maxOutstanding:    maxOutstanding,
+		ctx:               ctx,
+		cancel:            cancel,
+		streams:           make(map[string]*pubsubStream),
+		leases:            make(map[string]*pubsubLease),
+	}
+
+	t.wg.Add(1)
+	go t.extendLeases()
+
+	return t
+}
+
+func (t *PubSubTransport) topicPath(queueName string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", t.projectID, queueName)
+}
+
+func (t *PubSubTransport) subscriptionPath(queueName string) string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", t.projectID, queueName)
+}
+
+// ackDeadlineSeconds returns the ack deadline in seconds, clamped to the range Pub/Sub accepts
+func (t *PubSubTransport) ackDeadlineSeconds() int32 {
+	secs := int32(t.ackDeadline / time.Second)
+	if secs < 10 {
+		return 10
+	}
+	if secs > 600 {
+		return 600
+	}
+	return secs
+}
+
+// stream returns the streaming pull for a queue, starting it on first use
+func (t *PubSubTransport) stream(queueName string) *pubsubStream {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s, ok := t.streams[queueName]; ok {
+		return s
+	}
+
+	s := &pubsubStream{
+		subscription: t.subscriptionPath(queueName),
+		msgs:         make(chan *pubsubpb.ReceivedMessage, t.maxOutstanding),
+		done:         make(chan struct{}),
+	}
+	t.streams[queueName] = s
+
+	t.wg.Add(1)
+	go t.pull(queueName, s)
+
+	return s
+}
+
+// pull keeps a streaming pull open, reconnecting on transient errors.
+// Permanent errors (e.g. missing subscription) stop the stream so the next Receive starts a new one.
+func (t *PubSubTransport) pull(queueName string, s *pubsubStream) {
+	defer t.wg.Done()
+
+	backoff := pubsubReconnectBackoff
+	for {
+		err := t.pullOnce(s)
+		if t.ctx.Err() != nil {
+			err = t.ctx.Err()
+		}
+
+		if isPermanentPubSubError(err) {
+			t.mu.Lock()
+			delete(t.streams, queueName)
+			t.mu.Unlock()
+			s.err = err
+			close(s.done)
+			return
+		}
+
+		slog.Warn("Pub/Sub streaming pull interrupted, reconnecting",
+			"subscription", s.subscription, "backoff", backoff, "error", err)
+		select {
+		case <-t.ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, pubsubMaxReconnectBackoff)
+	}
+}
+
+// isPermanentPubSubError reports whether reconnecting the streaming pull cannot help
+func isPermanentPubSubError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.NotFound, codes.PermissionDenied, codes.InvalidArgument, codes.Unauthenticated, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// pullOnce runs one streaming pull until it fails
+func (t *PubSubTransport) pullOnce(s *pubsubStream) error {
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+
+	stream, err := t.subscriber.StreamingPull(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pubsubpb.StreamingPullRequest{
+		Subscription:             s.subscription,
+		StreamAckDeadlineSeconds: t.ackDeadlineSeconds(),
+		ClientId:                 t.clientID,
+		MaxOutstandingMessages:   int64(t.maxOutstanding),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		for _, rm := range resp.ReceivedMessages {
+			// Lease starts on arrival: buffered messages must not expire before Receive picks them up
+			t.mu.Lock()
+			t.leases[rm.AckId] = &pubsubLease{
+				subscription: s.subscription,
+				expires:      time.Now().Add(t.visibilityTimeout),
+			}
+			t.mu.Unlock()
+
+			select {
+			case s.msgs <- rm:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// extendLeases periodically extends the ack deadline of all held messages
+func (t *PubSubTransport) extendLeases() {
+	defer t.wg.Done()
+
+	interval := t.ackDeadline / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		bySubscription := make(map[string][]string)
+		t.mu.Lock()
+		for ackID, lease := range t.leases {
+			if now.After(lease.expires) {
+				// Held past the visibility timeout: stop extending and let Pub/Sub redeliver
+				delete(t.leases, ackID)
+				continue
+			}
+			bySubscription[lease.subscription] = append(bySubscription[lease.subscription], ackID)
+		}
+		t.mu.Unlock()
+
+		for subscription, ackIDs := range bySubscription {
+			_, err := t.subscriber.ModifyAckDeadline(t.ctx, &pubsubpb.ModifyAckDeadlineRequest{
+				Subscription:       subscription,
+				AckIds:             ackIDs,
+				AckDeadlineSeconds: t.ackDeadlineSeconds(),
+			})
+			if err != nil && t.ctx.Err() == nil {
+				slog.Warn("Failed to extend Pub/Sub ack deadlines", "subscription", subscription, "count", len(ackIDs), "error", err)
+			}
+		}
+	}
+}
+
+// Receive returns the next message from the queue's subscription
+func (t *PubSubTransport) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	s := t.stream(queueName)
+
+	var rm *pubsubpb.ReceivedMessage
+	select {
+	case <-ctx.Done():
+		return QueueMessage{}, ctx.Err()
+	case <-s.done:
+		return QueueMessage{}, fmt.Errorf("failed to receive from Pub/Sub subscription %s: %w", s.subscription, s.err)
+	case rm = <-s.msgs:
+	}
+
+	headers := make(map[string]string)
+	for k, v := range rm.GetMessage().GetAttributes() {
+		headers[k] = v
+	}
+	headers["QueueName"] = queueName
+	if rm.DeliveryAttempt > 0 {
+		headers["Deliveries"] = strconv.Itoa(int(rm.DeliveryAttempt))
+	}
+
+	return QueueMessage{
+		ID:            rm.GetMessage().GetMessageId(),
+		Body:          rm.GetMessage().GetData(),
+		ReceiptHandle: pubsubReceipt{subscription: s.subscription, ackID: rm.AckId},
+		Headers:       headers,
+	}, nil
+}
+
+// Send publishes a message to the queue's topic
+func (t *PubSubTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	resp, err := t.publisher.Publish(ctx, &pubsubpb.PublishRequest{
+		Topic:    t.topicPath(queueName),
+		Messages: []*pubsubpb.PubsubMessage{{Data: body}},
+	})
+	if err != nil {
+		slog.Error("Pub/Sub Publish failed", "queueName", queueName, "error", err)
+		return fmt.Errorf("failed to publish to Pub/Sub topic %s: %w", queueName, err)
+	}
+
+	slog.Info("Pub/Sub message published successfully", "queueName", queueName, "messageId", resp.MessageIds[0])
+	return nil
+}
+
+// releaseLease stops ack deadline extension for a message
+func (t *PubSubTransport) releaseLease(msg QueueMessage) (pubsubReceipt, error) {
+	receipt, ok := msg.ReceiptHandle.(pubsubReceipt)
+	if !ok {
+		return pubsubReceipt{}, fmt.Errorf("invalid receipt handle type for Pub/Sub")
+	}
+
+	t.mu.Lock()
+	delete(t.leases, receipt.ackID)
+	t.mu.Unlock()
+
+	return receipt, nil
+}
+
+// Ack acknowledges a message so Pub/Sub does not redeliver it
+func (t *PubSubTransport) Ack(ctx context.Context, msg QueueMessage) error {
+	receipt, err := t.releaseLease(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.subscriber.Acknowledge(ctx, &pubsubpb.AcknowledgeRequest{
+		Subscription: receipt.subscription,
+		AckIds:       []string{receipt.ackID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack negatively acknowledges a message by setting its ack deadline to 0
+// This makes the message immediately available for redelivery
+func (t *PubSubTransport) Nack(ctx context.Context, msg QueueMessage) error {
+	receipt, err := t.releaseLease(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.subscriber.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+		Subscription:       receipt.subscription,
+		AckIds:             []string{receipt.ackID},
+		AckDeadlineSeconds: 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close stops streaming pulls and closes the connection.
+// Messages still held are not acked and are redelivered after their ack deadline.
+func (t *PubSubTransport) Close() error {
+	t.cancel()
+	t.wg.Wait()
+	return t.conn.Close()
+}
@@ -0,0 +1,258 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const testPubSubProject = "asya-test"
+
+// newTestPubSubConn connects to PUBSUB_EMULATOR_HOST when set (e.g. gcloud beta emulators pubsub start),
+// otherwise to an in-process fake server
+func newTestPubSubConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	addr := os.Getenv("PUBSUB_EMULATOR_HOST")
+	if addr == "" {
+		srv := pstest.NewServer()
+		t.Cleanup(func() { _ = srv.Close() })
+		addr = srv.Addr
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to Pub/Sub emulator: %v", err)
+	}
+	return conn
+}
+
+func newTestPubSubTransport(t *testing.T, cfg PubSubConfig) *PubSubTransport {
+	t.Helper()
+
+	cfg.ProjectID = testPubSubProject
+	if cfg.ClientID == "" {
+		cfg.ClientID = "test-client"
+	}
+	tp := newPubSubTransport(newTestPubSubConn(t), cfg)
+	t.Cleanup(func() { _ = tp.Close() })
+	return tp
+}
+
+// createTestPubSubQueue creates a topic and subscription unique to the test, like the operator does
+func createTestPubSubQueue(t *testing.T, tp *PubSubTransport, policy *pubsubpb.DeadLetterPolicy) string {
+	t.Helper()
+	ctx := context.Background()
+	queue := "asya-test-" + strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-"))
+
+	if _, err := tp.publisher.CreateTopic(ctx, &pubsubpb.Topic{Name: tp.topicPath(queue)}); err != nil {
+		t.Fatalf("CreateTopic failed: %v", err)
+	}
+	_, err := tp.subscriber.CreateSubscription(ctx, &pubsubpb.Subscription{
+		Name:               tp.subscriptionPath(queue),
+		Topic:              tp.topicPath(queue),
+		AckDeadlineSeconds: 10,
+		DeadLetterPolicy:   policy,
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = tp.subscriber.DeleteSubscription(context.Background(), &pubsubpb.DeleteSubscriptionRequest{Subscription: tp.subscriptionPath(queue)})
+		_, _ = tp.publisher.DeleteTopic(context.Background(), &pubsubpb.DeleteTopicRequest{Topic: tp.topicPath(queue)})
+	})
+	return queue
+}
+
+func receivePubSub(t *testing.T, tp *PubSubTransport, queue string) QueueMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, err := tp.Receive(ctx, queue)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	return msg
+}
+
+func TestPubSubTransport_SendReceiveAck(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+	queue := createTestPubSubQueue(t, tp, nil)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte(`{"id":"env-1"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg := receivePubSub(t, tp, queue)
+	if string(msg.Body) != `{"id":"env-1"}` {
+		t.Errorf("Body = %s, want envelope", msg.Body)
+	}
+	if msg.Headers["QueueName"] != queue {
+		t.Errorf("QueueName header = %q, want %q", msg.Headers["QueueName"], queue)
+	}
+
+	if err := tp.Ack(ctx, msg); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := tp.Receive(shortCtx, queue); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected no redelivery after ack, got %v", err)
+	}
+}
+
+func TestPubSubTransport_NackRedelivers(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+	queue := createTestPubSubQueue(t, tp, nil)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte("retry-me")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	msg := receivePubSub(t, tp, queue)
+
+	if err := tp.Nack(ctx, msg); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+
+	redelivered := receivePubSub(t, tp, queue)
+	if redelivered.ID != msg.ID {
+		t.Errorf("Redelivered ID = %s, want %s", redelivered.ID, msg.ID)
+	}
+	if err := tp.Ack(ctx, redelivered); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+}
+
+func TestPubSubTransport_ExtendsAckDeadline(t *testing.T) {
+	// A 1s deadline is clamped to 10s on the wire; the visibility timeout bounds extension
+	tp := newTestPubSubTransport(t, PubSubConfig{AckDeadline: time.Second, VisibilityTimeout: time.Minute})
+	queue := createTestPubSubQueue(t, tp, nil)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte("slow")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	msg := receivePubSub(t, tp, queue)
+
+	tp.mu.Lock()
+	_, held := tp.leases[msg.ReceiptHandle.(pubsubReceipt).ackID]
+	tp.mu.Unlock()
+	if !held {
+		t.Fatal("Expected lease to be tracked while message is held")
+	}
+
+	if err := tp.Ack(ctx, msg); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+
+	tp.mu.Lock()
+	remaining := len(tp.leases)
+	tp.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected lease released after ack, %d remaining", remaining)
+	}
+}
+
+func TestPubSubTransport_DeadLetterPolicy(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+	ctx := context.Background()
+
+	dlqTopic := tp.topicPath("asya-test-dlq")
+	if _, err := tp.publisher.CreateTopic(ctx, &pubsubpb.Topic{Name: dlqTopic}); err != nil {
+		t.Fatalf("CreateTopic failed: %v", err)
+	}
+	queue := createTestPubSubQueue(t, tp, &pubsubpb.DeadLetterPolicy{DeadLetterTopic: dlqTopic, MaxDeliveryAttempts: 5})
+
+	if err := tp.Send(ctx, queue, []byte("poison")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg := receivePubSub(t, tp, queue)
+	if msg.Headers["Deliveries"] != "1" {
+		t.Errorf("Deliveries = %q, want 1", msg.Headers["Deliveries"])
+	}
+	if err := tp.Nack(ctx, msg); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+
+	msg = receivePubSub(t, tp, queue)
+	if msg.Headers["Deliveries"] != "2" {
+		t.Errorf("Deliveries = %q, want 2", msg.Headers["Deliveries"])
+	}
+	if err := tp.Ack(ctx, msg); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+}
+
+func TestPubSubTransport_MissingSubscription(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := tp.Receive(ctx, "asya-test-missing")
+	if status.Code(errors.Unwrap(err)) != codes.NotFound {
+		t.Fatalf("Expected NotFound error, got %v", err)
+	}
+
+	// Stream is dropped so a later Receive retries once the operator creates the subscription
+	tp.mu.Lock()
+	_, ok := tp.streams["asya-test-missing"]
+	tp.mu.Unlock()
+	if ok {
+		t.Error("Expected failed stream to be removed")
+	}
+}
+
+func TestPubSubTransport_SendMissingTopic(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+
+	err := tp.Send(context.Background(), "asya-test-missing", []byte("x"))
+	if err == nil || !strings.Contains(err.Error(), "failed to publish") {
+		t.Fatalf("Expected publish error, got %v", err)
+	}
+}
+
+func TestPubSubTransport_ReceiveCancelled(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+	queue := createTestPubSubQueue(t, tp, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := tp.Receive(ctx, queue); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Receive error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPubSubTransport_InvalidReceipt(t *testing.T) {
+	tp := newTestPubSubTransport(t, PubSubConfig{})
+
+	if err := tp.Ack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Ack error for invalid receipt")
+	}
+	if err := tp.Nack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Nack error for invalid receipt")
+	}
+}
+
+func TestNewPubSubTransport_RequiresProject(t *testing.T) {
+	_, err := NewPubSubTransport(context.Background(), PubSubConfig{EmulatorHost: "localhost:8085"})
+	if err == nil || !strings.Contains(err.Error(), "project ID is required") {
+		t.Fatalf("Expected project ID error, got %v", err)
+	}
+}
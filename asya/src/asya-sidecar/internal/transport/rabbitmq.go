@@ -12,10 +12,11 @@ import (
 )
 
 const (
-	queuePrefix = "asya-"
-
 	defaultQueueRetryMaxAttempts = 10
 	defaultQueueRetryBackoff     = 1 * time.Second
+
+	// rabbitmqDelayQueueIdle is how long an unused delay queue outlives its message TTL
+	rabbitmqDelayQueueIdle = time.Minute
 )
 
 // getQueueRetryMaxAttempts returns configured max retry attempts from environment or default
@@ -63,7 +64,6 @@ type RabbitMQTransport struct {
 	conn          rabbitmqConnection
 	channel       rabbitmqChannel
 	exchange      string
-	namespace     string
 	prefetchCount int
 	consumer      <-chan amqp.Delivery // Single long-lived consumer
 	consumerQueue string               // Queue name for the consumer
@@ -77,7 +77,6 @@ type RabbitMQConfig struct {
 	URL           string
 	Exchange      string
 	PrefetchCount int
-	Namespace     string
 }
 
 // NewRabbitMQTransport creates a new RabbitMQ transport
@@ -154,7 +153,6 @@ func NewRabbitMQTransport(cfg RabbitMQConfig) (*RabbitMQTransport, error) {
 		conn:          conn,
 		channel:       channel,
 		exchange:      cfg.Exchange,
-		namespace:     cfg.Namespace,
 		prefetchCount: cfg.PrefetchCount,
 		amqpChannel:   channel,
 		amqpConn:      realConn,
@@ -356,6 +354,12 @@ func (t *RabbitMQTransport) Receive(ctx context.Context, queueName string) (Queu
 		for k, v := range msg.Headers {
 			headers[k] = fmt.Sprintf("%v", v)
 		}
+		// Quorum queues count previous deliveries in x-delivery-count; classic queues only flag redeliveries
+		if count, ok := msg.Headers["x-delivery-count"].(int64); ok {
+			headers["Deliveries"] = strconv.FormatInt(count+1, 10)
+		} else if msg.Redelivered {
+			headers["Deliveries"] = "2"
+		}
 
 		return QueueMessage{
 			ID:            msg.MessageId,
@@ -377,21 +381,13 @@ func (t *RabbitMQTransport) Send(ctx context.Context, queueName string, body []b
 		return err
 	}
 
-	// Derive routing key from queue name by stripping namespace prefix
-	// Queue names: asya-{namespace}-{actor} -> routing key: {actor}
-	namespacePrefix := fmt.Sprintf("%s%s-", queuePrefix, t.namespace)
-	routingKey := queueName
-	if len(queueName) > len(namespacePrefix) && queueName[:len(namespacePrefix)] == namespacePrefix {
-		routingKey = queueName[len(namespacePrefix):]
-	}
-
 	// Publish message
 	err := t.channel.PublishWithContext(
 		ctx,
 		t.exchange,
-		routingKey, // routing key (actor name without prefix)
-		false,      // mandatory
-		false,      // immediate
+		queueName, // routing key (the operator binds each queue with its name)
+		false,     // mandatory
+		false,     // immediate
 		amqp.Publishing{
 			DeliveryMode: amqp.Persistent,
 			ContentType:  "application/json",
@@ -406,6 +402,57 @@ func (t *RabbitMQTransport) Send(ctx context.Context, queueName string, body []b
 	return nil
 }
 
+// SendDelayed sends a message that reaches queueName after delay. The message waits in a delay queue
+// "{queueName}.delay.{ms}" whose message TTL is the delay and whose dead-letter exchange routes expired
+// messages back to queueName. Delay queues are redeclared on every send and expire once idle for
+// rabbitmqDelayQueueIdle after their TTL.
+func (t *RabbitMQTransport) SendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error {
+	if delay <= 0 {
+		return t.Send(ctx, queueName, body)
+	}
+	if err := t.ensureQueue(queueName); err != nil {
+		return err
+	}
+
+	delayQueue := fmt.Sprintf("%s.delay.%d", queueName, delay.Milliseconds())
+	_, err := t.channel.QueueDeclare(
+		delayQueue,
+		true,  // durable
+		false, // auto-delete
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-expires":                 (delay + rabbitmqDelayQueueIdle).Milliseconds(),
+			"x-dead-letter-exchange":    t.exchange,
+			"x-dead-letter-routing-key": queueName,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare delay queue %s: %w", delayQueue, err)
+	}
+
+	// Publish directly to the delay queue through the default exchange
+	err = t.channel.PublishWithContext(
+		ctx,
+		"",
+		delayQueue,
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish to RabbitMQ delay queue %s: %w", delayQueue, err)
+	}
+
+	return nil
+}
+
 // Ack acknowledges a message
 func (t *RabbitMQTransport) Ack(ctx context.Context, msg QueueMessage) error {
 	deliveryTag, ok := msg.ReceiptHandle.(uint64)
@@ -198,8 +198,9 @@ func TestRabbitMQTransport_Receive(t *testing.T) {
 			Body:        []byte(`{"test":"message"}`),
 			DeliveryTag: uint64(42),
 			Headers: amqp.Table{
-				"trace_id": "trace-xyz",
-				"priority": "high",
+				"trace_id":         "trace-xyz",
+				"priority":         "high",
+				"x-delivery-count": int64(1),
 			},
 		}
 
@@ -229,6 +230,9 @@ func TestRabbitMQTransport_Receive(t *testing.T) {
 		if msg.Headers["QueueName"] != queueName {
 			t.Errorf("Headers[QueueName] = %v, want %v", msg.Headers["QueueName"], queueName)
 		}
+		if msg.Headers["Deliveries"] != "2" {
+			t.Errorf("Headers[Deliveries] = %v, want 2", msg.Headers["Deliveries"])
+		}
 	})
 
 	t.Run("context cancellation", func(t *testing.T) {
@@ -324,6 +328,26 @@ func TestRabbitMQTransport_Send(t *testing.T) {
 		}
 	})
 
+	t.Run("routing key is the full queue name", func(t *testing.T) {
+		var routingKey string
+		mockChannel := &mockRabbitMQChannel{
+			publishWithContextFunc: func(ctx context.Context, ex, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+				routingKey = key
+				return nil
+			},
+		}
+
+		transport := createMockRabbitMQTransport(nil, mockChannel)
+
+		if err := transport.Send(ctx, "asya-default-next-actor", messageBody); err != nil {
+			t.Fatalf("Send() error = %v, want nil", err)
+		}
+		// The operator binds each queue with its full name, so the namespace prefix is kept
+		if routingKey != "asya-default-next-actor" {
+			t.Errorf("routing key = %q, want asya-default-next-actor", routingKey)
+		}
+	})
+
 	t.Run("publish failure", func(t *testing.T) {
 		mockChannel := &mockRabbitMQChannel{
 			publishWithContextFunc: func(ctx context.Context, ex, key string, mandatory, immediate bool, msg amqp.Publishing) error {
@@ -355,6 +379,100 @@ func TestRabbitMQTransport_Send(t *testing.T) {
 	})
 }
 
+func TestRabbitMQTransport_SendDelayed(t *testing.T) {
+	ctx := context.Background()
+	queueName := testQueueName
+	messageBody := []byte(`{"test":"message"}`)
+
+	t.Run("publishes to a TTL queue dead-lettered back to the target", func(t *testing.T) {
+		var declaredName string
+		var declaredArgs amqp.Table
+		var publishedExchange, publishedKey string
+
+		mockChannel := &mockRabbitMQChannel{
+			queueDeclareFunc: func(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+				if !durable {
+					t.Error("delay queue is not durable")
+				}
+				declaredName = name
+				declaredArgs = args
+				return amqp.Queue{Name: name}, nil
+			},
+			publishWithContextFunc: func(ctx context.Context, ex, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+				publishedExchange = ex
+				publishedKey = key
+				if string(msg.Body) != string(messageBody) {
+					t.Errorf("Body = %v, want %v", string(msg.Body), string(messageBody))
+				}
+				if msg.DeliveryMode != amqp.Persistent {
+					t.Error("DeliveryMode != Persistent")
+				}
+				return nil
+			},
+		}
+
+		transport := createMockRabbitMQTransport(nil, mockChannel)
+
+		if err := transport.SendDelayed(ctx, queueName, messageBody, 5*time.Second); err != nil {
+			t.Fatalf("SendDelayed() error = %v, want nil", err)
+		}
+
+		wantQueue := queueName + ".delay.5000"
+		if declaredName != wantQueue {
+			t.Errorf("declared queue = %q, want %q", declaredName, wantQueue)
+		}
+		if publishedExchange != "" || publishedKey != wantQueue {
+			t.Errorf("published to exchange %q key %q, want default exchange key %q", publishedExchange, publishedKey, wantQueue)
+		}
+		want := amqp.Table{
+			"x-message-ttl":             int64(5000),
+			"x-expires":                 int64(65000),
+			"x-dead-letter-exchange":    "test-exchange",
+			"x-dead-letter-routing-key": queueName,
+		}
+		for key, value := range want {
+			if declaredArgs[key] != value {
+				t.Errorf("delay queue arg %s = %v, want %v", key, declaredArgs[key], value)
+			}
+		}
+	})
+
+	t.Run("no delay sends directly", func(t *testing.T) {
+		mockChannel := &mockRabbitMQChannel{
+			queueDeclareFunc: func(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+				t.Errorf("unexpected delay queue %q", name)
+				return amqp.Queue{Name: name}, nil
+			},
+			publishWithContextFunc: func(ctx context.Context, ex, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+				if ex != "test-exchange" || key != queueName {
+					t.Errorf("published to exchange %q key %q, want test-exchange key %q", ex, key, queueName)
+				}
+				return nil
+			},
+		}
+
+		transport := createMockRabbitMQTransport(nil, mockChannel)
+
+		if err := transport.SendDelayed(ctx, queueName, messageBody, 0); err != nil {
+			t.Errorf("SendDelayed() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("delay queue declare failure", func(t *testing.T) {
+		mockChannel := &mockRabbitMQChannel{
+			queueDeclareFunc: func(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+				return amqp.Queue{}, errors.New("access refused")
+			},
+		}
+
+		transport := createMockRabbitMQTransport(nil, mockChannel)
+
+		if err := transport.SendDelayed(ctx, queueName, messageBody, time.Second); err == nil {
+			t.Error("SendDelayed() error = nil, want declare error")
+		}
+	})
+}
+
 func TestRabbitMQTransport_Ack(t *testing.T) {
 	ctx := context.Background()
 	deliveryTag := uint64(42)
@@ -0,0 +1,323 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Streams Layout:
+//
+// Each queue "asya-{namespace}-{actor}" is a stream with one consumer group shared by
+// all replicas of the actor. Messages carry the envelope in the "body" field.
+// XREADGROUP delivers new entries; entries left pending longer than the visibility
+// timeout (consumer crashed or stalled) are taken over with XAUTOCLAIM. Entries
+// delivered more than MaxDeliveries times are copied to "{queue}:dead" and removed.
+// Acked entries are deleted so the stream length equals the backlog.
+//
+// The layout matches the gateway client (asya-gateway/internal/queue/redis.go).
+
+const (
+	redisBodyField       = "body"
+	redisDeadSuffix      = ":dead"
+	defaultRedisGroup    = "asya"
+	defaultRedisBlock    = time.Second
+	defaultRedisVisible  = 5 * time.Minute
+	defaultRedisMaxDeliv = 3
+)
+
+// RedisTransport implements Transport interface for Redis Streams
+type RedisTransport struct {
+	client            redis.UniversalClient
+	group             string
+	consumer          string
+	visibilityTimeout time.Duration
+	blockTimeout      time.Duration
+	maxDeliveries     int
+	autoCreate        bool
+
+	mu      sync.Mutex
+	ensured map[string]bool
+}
+
+// RedisConfig holds Redis Streams transport configuration
+type RedisConfig struct {
+	URL               string
+	Group             string        // Consumer group name
+	Consumer          string        // Consumer name, unique per replica (pod name)
+	VisibilityTimeout time.Duration // Idle time before a pending entry is reclaimed
+	BlockTimeout      time.Duration // XREADGROUP block time per poll
+	MaxDeliveries     int           // Deliveries before an entry moves to the dead-letter stream (0 = default)
+	AutoCreate        bool          // Create stream and group on first receive
+}
+
+// redisReceipt identifies a delivered stream entry
+type redisReceipt struct {
+	stream     string
+	id         string
+	deliveries int64
+}
+
+// NewRedisTransport creates a new Redis Streams transport
+func NewRedisTransport(ctx context.Context, cfg RedisConfig) (*RedisTransport, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return newRedisTransport(client, cfg), nil
+}
+
+func newRedisTransport(client redis.UniversalClient, cfg RedisConfig) *RedisTransport {
+	group := cfg.Group
+	if group == "" {
+		group = defaultRedisGroup
+	}
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = defaultRedisVisible
+	}
+	blockTimeout := cfg.BlockTimeout
+	if blockTimeout == 0 {
+		blockTimeout = defaultRedisBlock
+	}
+	maxDeliveries := cfg.MaxDeliveries
+	if maxDeliveries == 0 {
+		maxDeliveries = defaultRedisMaxDeliv
+	}
+
+	return &RedisTransport{
+		client:            client,
+		group:             group,
+		consumer:          cfg.Consumer,
+		visibilityTimeout: visibilityTimeout,
+		blockTimeout:      blockTimeout,
+		maxDeliveries:     maxDeliveries,
+		autoCreate:        cfg.AutoCreate,
+		ensured:           make(map[string]bool),
+	}
+}
+
+// ensureGroup creates the stream and consumer group once per queue when auto-create is enabled
+func (t *RedisTransport) ensureGroup(ctx context.Context, stream string) error {
+	if !t.autoCreate {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ensured[stream] {
+		return nil
+	}
+
+	err := t.client.XGroupCreateMkStream(ctx, stream, t.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on %s: %w", t.group, stream, err)
+	}
+	t.ensured[stream] = true
+	return nil
+}
+
+// Receive returns the next entry for this consumer, reclaiming stalled entries first
+func (t *RedisTransport) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	if err := t.ensureGroup(ctx, queueName); err != nil {
+		return QueueMessage{}, err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return QueueMessage{}, ctx.Err()
+		}
+
+		msg, ok, err := t.reclaim(ctx, queueName)
+		if err != nil {
+			return QueueMessage{}, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		streams, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    t.group,
+			Consumer: t.consumer,
+			Streams:  []string{queueName, ">"},
+			Count:    1,
+			Block:    t.blockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return QueueMessage{}, ctx.Err()
+			}
+			return QueueMessage{}, fmt.Errorf("failed to read from stream %s: %w", queueName, err)
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				return t.toQueueMessage(queueName, entry, 1), nil
+			}
+		}
+	}
+}
+
+// reclaim takes over one entry that has been pending longer than the visibility timeout.
+// Entries over the delivery limit are dead-lettered and the next one is tried.
+func (t *RedisTransport) reclaim(ctx context.Context, queueName string) (QueueMessage, bool, error) {
+	for {
+		entries, _, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   queueName,
+			Group:    t.group,
+			Consumer: t.consumer,
+			MinIdle:  t.visibilityTimeout,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return QueueMessage{}, false, ctx.Err()
+			}
+			return QueueMessage{}, false, fmt.Errorf("failed to reclaim from stream %s: %w", queueName, err)
+		}
+		if len(entries) == 0 {
+			return QueueMessage{}, false, nil
+		}
+		entry := entries[0]
+
+		deliveries, err := t.deliveryCount(ctx, queueName, entry.ID)
+		if err != nil {
+			return QueueMessage{}, false, err
+		}
+
+		if _, ok := entry.Values[redisBodyField]; !ok || deliveries > int64(t.maxDeliveries) {
+			slog.Warn("Message exceeded max deliveries, moving to dead-letter stream",
+				"queue", queueName, "id", entry.ID, "deliveries", deliveries-1, "maxDeliveries", t.maxDeliveries)
+			if err := t.deadLetter(ctx, queueName, entry); err != nil {
+				return QueueMessage{}, false, err
+			}
+			continue
+		}
+
+		return t.toQueueMessage(queueName, entry, deliveries), true, nil
+	}
+}
+
+// deliveryCount returns how many times a pending entry has been delivered
+func (t *RedisTransport) deliveryCount(ctx context.Context, stream, id string) (int64, error) {
+	pending, err := t.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  t.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect pending entry %s: %w", id, err)
+	}
+	if len(pending) == 0 {
+		return 1, nil
+	}
+	return pending[0].RetryCount, nil
+}
+
+// deadLetter copies an entry to the dead-letter stream and removes it from the queue
+func (t *RedisTransport) deadLetter(ctx context.Context, stream string, entry redis.XMessage) error {
+	pipe := t.client.TxPipeline()
+	if body, ok := entry.Values[redisBodyField]; ok {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream + redisDeadSuffix,
+			Values: map[string]interface{}{redisBodyField: body, "source_id": entry.ID},
+		})
+	}
+	pipe.XAck(ctx, stream, t.group, entry.ID)
+	pipe.XDel(ctx, stream, entry.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to dead-letter entry %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+func (t *RedisTransport) toQueueMessage(queueName string, entry redis.XMessage, deliveries int64) QueueMessage {
+	var body []byte
+	switch v := entry.Values[redisBodyField].(type) {
+	case string:
+		body = []byte(v)
+	case []byte:
+		body = v
+	}
+
+	return QueueMessage{
+		ID:            entry.ID,
+		Body:          body,
+		ReceiptHandle: redisReceipt{stream: queueName, id: entry.ID, deliveries: deliveries},
+		Headers: map[string]string{
+			"QueueName":  queueName,
+			"Deliveries": strconv.FormatInt(deliveries, 10),
+		},
+	}
+}
+
+// Send appends a message to the stream
+func (t *RedisTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	err := t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: queueName,
+		Values: map[string]interface{}{redisBodyField: body},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", queueName, err)
+	}
+	return nil
+}
+
+// Ack acknowledges and deletes a delivered entry
+func (t *RedisTransport) Ack(ctx context.Context, msg QueueMessage) error {
+	receipt, ok := msg.ReceiptHandle.(redisReceipt)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+
+	pipe := t.client.TxPipeline()
+	pipe.XAck(ctx, receipt.stream, t.group, receipt.id)
+	pipe.XDel(ctx, receipt.stream, receipt.id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack marks a delivered entry as idle for the full visibility timeout so the next
+// XAUTOCLAIM picks it up immediately. Redis Streams have no native negative ack.
+func (t *RedisTransport) Nack(ctx context.Context, msg QueueMessage) error {
+	receipt, ok := msg.ReceiptHandle.(redisReceipt)
+	if !ok {
+		return fmt.Errorf("invalid receipt handle type")
+	}
+
+	err := t.client.Do(ctx, "XCLAIM", receipt.stream, t.group, t.consumer, 0, receipt.id,
+		"IDLE", t.visibilityTimeout.Milliseconds(),
+		"RETRYCOUNT", receipt.deliveries,
+		"JUSTID").Err()
+	if err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis client
+func (t *RedisTransport) Close() error {
+	return t.client.Close()
+}
@@ -0,0 +1,214 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisTransport connects to ASYA_TEST_REDIS_URL when set (e.g. a local redis-server),
+// otherwise to an in-process miniredis
+func newTestRedisTransport(t *testing.T, consumer string, visibility time.Duration) *RedisTransport {
+	t.Helper()
+
+	url := os.Getenv("ASYA_TEST_REDIS_URL")
+	if url == "" {
+		mr := miniredis.RunT(t)
+		url = "redis://" + mr.Addr() + "/0"
+	}
+
+	tp, err := NewRedisTransport(context.Background(), RedisConfig{
+		URL:               url,
+		Group:             "asya-test",
+		Consumer:          consumer,
+		VisibilityTimeout: visibility,
+		BlockTimeout:      20 * time.Millisecond,
+		MaxDeliveries:     2,
+		AutoCreate:        true,
+	})
+	if err != nil {
+		t.Fatalf("NewRedisTransport failed: %v", err)
+	}
+	t.Cleanup(func() { _ = tp.Close() })
+	return tp
+}
+
+// testRedisQueue returns a stream name unique to the test and removes it afterwards
+func testRedisQueue(t *testing.T, tp *RedisTransport) string {
+	t.Helper()
+	queue := "asya-test-" + strings.ReplaceAll(t.Name(), "/", "-")
+	ctx := context.Background()
+	_ = tp.client.Del(ctx, queue, queue+redisDeadSuffix).Err()
+	t.Cleanup(func() { _ = tp.client.Del(context.Background(), queue, queue+redisDeadSuffix).Err() })
+	return queue
+}
+
+func receiveRedis(t *testing.T, tp *RedisTransport, queue string) QueueMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	msg, err := tp.Receive(ctx, queue)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	return msg
+}
+
+func TestRedisTransport_SendReceiveAck(t *testing.T) {
+	tp := newTestRedisTransport(t, "consumer-1", time.Minute)
+	queue := testRedisQueue(t, tp)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte(`{"id":"env-1"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg := receiveRedis(t, tp, queue)
+	if string(msg.Body) != `{"id":"env-1"}` {
+		t.Errorf("Body = %s, want envelope", msg.Body)
+	}
+	if msg.Headers["QueueName"] != queue || msg.Headers["Deliveries"] != "1" {
+		t.Errorf("Unexpected headers: %v", msg.Headers)
+	}
+
+	if err := tp.Ack(ctx, msg); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if n := tp.client.XLen(ctx, queue).Val(); n != 0 {
+		t.Errorf("Stream length after ack = %d, want 0", n)
+	}
+}
+
+func TestRedisTransport_ReclaimStalledMessage(t *testing.T) {
+	visibility := 100 * time.Millisecond
+	crashed := newTestRedisTransport(t, "consumer-crashed", visibility)
+	queue := testRedisQueue(t, crashed)
+	ctx := context.Background()
+
+	if err := crashed.Send(ctx, queue, []byte("work")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	first := receiveRedis(t, crashed, queue)
+
+	// Second consumer shares the same server and group
+	survivor := newRedisTransport(crashed.client, RedisConfig{
+		Group:             "asya-test",
+		Consumer:          "consumer-survivor",
+		VisibilityTimeout: visibility,
+		BlockTimeout:      20 * time.Millisecond,
+		MaxDeliveries:     2,
+		AutoCreate:        true,
+	})
+
+	time.Sleep(2 * visibility)
+	reclaimed := receiveRedis(t, survivor, queue)
+	if reclaimed.ID != first.ID {
+		t.Errorf("Reclaimed ID = %s, want %s", reclaimed.ID, first.ID)
+	}
+	if reclaimed.Headers["Deliveries"] != "2" {
+		t.Errorf("Deliveries = %s, want 2", reclaimed.Headers["Deliveries"])
+	}
+	if err := survivor.Ack(ctx, reclaimed); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+}
+
+func TestRedisTransport_NackRedelivers(t *testing.T) {
+	tp := newTestRedisTransport(t, "consumer-1", time.Minute)
+	queue := testRedisQueue(t, tp)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte("retry-me")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	msg := receiveRedis(t, tp, queue)
+
+	if err := tp.Nack(ctx, msg); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+
+	redelivered := receiveRedis(t, tp, queue)
+	if redelivered.ID != msg.ID || redelivered.Headers["Deliveries"] != "2" {
+		t.Errorf("Expected redelivery of %s with 2 deliveries, got %s with %s",
+			msg.ID, redelivered.ID, redelivered.Headers["Deliveries"])
+	}
+}
+
+func TestRedisTransport_DeadLetter(t *testing.T) {
+	tp := newTestRedisTransport(t, "consumer-1", time.Minute)
+	queue := testRedisQueue(t, tp)
+	ctx := context.Background()
+
+	if err := tp.Send(ctx, queue, []byte("poison")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	// MaxDeliveries is 2: deliveries 1 and 2 are nacked, the third attempt dead-letters
+	for i := 0; i < 2; i++ {
+		msg := receiveRedis(t, tp, queue)
+		if err := tp.Nack(ctx, msg); err != nil {
+			t.Fatalf("Nack failed: %v", err)
+		}
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := tp.Receive(shortCtx, queue); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected no deliverable messages, got %v", err)
+	}
+
+	dead, err := tp.client.XRange(ctx, queue+redisDeadSuffix, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange failed: %v", err)
+	}
+	if len(dead) != 1 || dead[0].Values[redisBodyField] != "poison" {
+		t.Errorf("Expected poison message in dead-letter stream, got %v", dead)
+	}
+	if n := tp.client.XLen(ctx, queue).Val(); n != 0 {
+		t.Errorf("Stream length after dead-letter = %d, want 0", n)
+	}
+}
+
+func TestRedisTransport_ReceiveCancelled(t *testing.T) {
+	tp := newTestRedisTransport(t, "consumer-1", time.Minute)
+	queue := testRedisQueue(t, tp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := tp.Receive(ctx, queue); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Receive error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRedisTransport_NoAutoCreateRequiresGroup(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tp := newRedisTransport(client, RedisConfig{Consumer: "c", BlockTimeout: 10 * time.Millisecond})
+	defer func() { _ = tp.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := tp.Receive(ctx, testQueueName)
+	if err == nil || !strings.Contains(err.Error(), "NOGROUP") {
+		t.Fatalf("Expected NOGROUP error without auto-create, got %v", err)
+	}
+}
+
+func TestRedisTransport_InvalidReceipt(t *testing.T) {
+	tp := newTestRedisTransport(t, "consumer-1", time.Minute)
+
+	if err := tp.Ack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Ack error for invalid receipt")
+	}
+	if err := tp.Nack(context.Background(), QueueMessage{ReceiptHandle: "x"}); err == nil {
+		t.Error("Expected Nack error for invalid receipt")
+	}
+}
@@ -2,26 +2,53 @@ package transport
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/deliveryhero/asya/asya-naming"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
 )
 
 // sqsClient defines the interface for SQS operations
 type sqsClient interface {
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
 	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
 }
 
+// sqsMaxDelay is the longest delivery delay SQS supports
+const sqsMaxDelay = 15 * time.Minute
+
+// sqsMaxBatchEntries is the most messages SQS accepts per ReceiveMessage or SendMessageBatch call
+const sqsMaxBatchEntries = 10
+
+// sqsMaxBatchBytes is the largest total message size SQS accepts per SendMessageBatch call
+const sqsMaxBatchBytes = 256 * 1024
+
+// sqsDefaultMessageGroup is the FIFO message group of messages that carry no envelope ID
+const sqsDefaultMessageGroup = "asya"
+
+// sqsMessageIDPattern matches values SQS accepts as FIFO message group and deduplication IDs
+var sqsMessageIDPattern = regexp.MustCompile(`^[!-~]{1,128}$`)
+
 // SQSTransport implements Transport interface for AWS SQS
 type SQSTransport struct {
 	client            sqsClient
@@ -29,6 +56,10 @@ type SQSTransport struct {
 	baseURL           string
 	visibilityTimeout int32
 	waitTimeSeconds   int32
+	maxMessages       int32
+	fifo              bool
+	messageGroup      string
+	queueURLMu        sync.RWMutex // Guards queueURLCache, which concurrent workers share
 	queueURLCache     map[string]string
 }
 
@@ -38,10 +69,20 @@ type SQSConfig struct {
 	BaseURL           string
 	VisibilityTimeout int32
 	WaitTimeSeconds   int32
+	MaxMessages       int32  // Messages received per call and processed concurrently (1-10, default 1)
+	FIFO              bool   // Use FIFO queues (queue names end in ".fifo")
+	MessageGroup      string // Envelope field FIFO messages are grouped by: "headers.<key>" or "payload.<path>" (empty groups by envelope ID)
 }
 
 // NewSQSTransport creates a new SQS transport
 func NewSQSTransport(ctx context.Context, cfg SQSConfig) (*SQSTransport, error) {
+	if err := ValidateMessageGroup(cfg.MessageGroup); err != nil {
+		return nil, err
+	}
+	if cfg.MaxMessages < 0 || cfg.MaxMessages > sqsMaxBatchEntries {
+		return nil, fmt.Errorf("invalid max messages %d: must be between 1 and %d", cfg.MaxMessages, sqsMaxBatchEntries)
+	}
+
 	// Load AWS config with IRSA support (pod identity)
 	loadOptions := []func(*config.LoadOptions) error{
 		config.WithRegion(cfg.Region),
@@ -73,22 +114,45 @@ func NewSQSTransport(ctx context.Context, cfg SQSConfig) (*SQSTransport, error)
 		waitTimeSeconds = 20 // Long polling default
 	}
 
+	maxMessages := cfg.MaxMessages
+	if maxMessages == 0 {
+		maxMessages = 1
+	}
+
 	return &SQSTransport{
 		client:            client,
 		region:            cfg.Region,
 		baseURL:           cfg.BaseURL,
 		visibilityTimeout: visibilityTimeout,
 		waitTimeSeconds:   waitTimeSeconds,
+		maxMessages:       maxMessages,
+		fifo:              cfg.FIFO,
+		messageGroup:      cfg.MessageGroup,
 		queueURLCache:     make(map[string]string),
 	}, nil
 }
 
+// ValidateMessageGroup checks that a FIFO message group field is empty, "headers.<key>" or "payload.<path>"
+func ValidateMessageGroup(field string) error {
+	if field == "" {
+		return nil
+	}
+	source, path, _ := strings.Cut(field, ".")
+	if (source != "headers" && source != "payload") || path == "" || slices.Contains(strings.Split(path, "."), "") {
+		return fmt.Errorf("invalid message group %q: must be headers.<key> or payload.<path>", field)
+	}
+	return nil
+}
+
 // resolveQueueURL resolves the full queue URL from queue name using GetQueueUrl API
 // with retry logic to handle cases where queue is temporarily missing
 func (t *SQSTransport) resolveQueueURL(ctx context.Context, queueName string) (string, error) {
 	// Check cache first
-	if url, ok := t.queueURLCache[queueName]; ok {
-		return url, nil
+	t.queueURLMu.RLock()
+	cachedURL, ok := t.queueURLCache[queueName]
+	t.queueURLMu.RUnlock()
+	if ok {
+		return cachedURL, nil
 	}
 
 	// Use GetQueueUrl API with retry logic for resilience
@@ -100,9 +164,14 @@ func (t *SQSTransport) resolveQueueURL(ctx context.Context, queueName string) (s
 	var result *sqs.GetQueueUrlOutput
 	var err error
 
+	sqsQueueName := queueName
+	if t.fifo {
+		sqsQueueName = naming.FIFOQueueName(queueName)
+	}
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		result, err = t.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-			QueueName: aws.String(queueName),
+			QueueName: aws.String(sqsQueueName),
 		})
 		if err == nil {
 			break
@@ -143,7 +212,9 @@ func (t *SQSTransport) resolveQueueURL(ctx context.Context, queueName string) (s
 	}
 
 	// Cache it
+	t.queueURLMu.Lock()
 	t.queueURLCache[queueName] = queueURL
+	t.queueURLMu.Unlock()
 	return queueURL, nil
 }
 
@@ -164,72 +235,120 @@ func splitReceiptHandle(handle interface{}) (string, string, error) {
 
 // Receive receives a message from SQS with long polling
 func (t *SQSTransport) Receive(ctx context.Context, queueName string) (QueueMessage, error) {
+	msgs, err := t.ReceiveBatch(ctx, queueName, 1)
+	if err != nil {
+		return QueueMessage{}, err
+	}
+	return msgs[0], nil
+}
+
+// ReceiveBatch receives up to limit messages (at most 10) from SQS with long polling
+func (t *SQSTransport) ReceiveBatch(ctx context.Context, queueName string, limit int) ([]QueueMessage, error) {
 	queueURL, err := t.resolveQueueURL(ctx, queueName)
 	if err != nil {
-		return QueueMessage{}, fmt.Errorf("failed to resolve queue URL for %s: %w", queueName, err)
+		return nil, fmt.Errorf("failed to resolve queue URL for %s: %w", queueName, err)
 	}
 
-	// Long polling loop - blocks until message arrives or context cancelled
+	// Long polling loop - blocks until messages arrive or context cancelled
 	for {
 		select {
 		case <-ctx.Done():
-			return QueueMessage{}, ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		resp, err := t.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
 			QueueUrl:              aws.String(queueURL),
-			MaxNumberOfMessages:   1,
+			MaxNumberOfMessages:   int32(min(max(limit, 1), sqsMaxBatchEntries)),
 			WaitTimeSeconds:       t.waitTimeSeconds,
 			VisibilityTimeout:     t.visibilityTimeout,
 			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+				types.MessageSystemAttributeNameApproximateReceiveCount,
+			},
 		})
 		if err != nil {
 			// Invalidate cache if queue no longer exists
 			// This allows retry to fetch fresh queue URL after operator recreates it
+			t.queueURLMu.Lock()
 			delete(t.queueURLCache, queueName)
-			return QueueMessage{}, fmt.Errorf("failed to receive from SQS: %w", err)
+			t.queueURLMu.Unlock()
+			return nil, fmt.Errorf("failed to receive from SQS: %w", err)
 		}
 
 		if len(resp.Messages) == 0 {
 			continue
 		}
 
-		msg := resp.Messages[0]
-
-		// Convert SQS message attributes to headers
-		headers := make(map[string]string)
-		headers["QueueName"] = queueName
-		for k, v := range msg.MessageAttributes {
-			if v.StringValue != nil {
-				headers[k] = *v.StringValue
+		msgs := make([]QueueMessage, 0, len(resp.Messages))
+		for _, msg := range resp.Messages {
+			// Convert SQS message attributes to headers
+			headers := make(map[string]string)
+			headers["QueueName"] = queueName
+			for k, v := range msg.MessageAttributes {
+				if v.StringValue != nil {
+					headers[k] = *v.StringValue
+				}
+			}
+			if count, ok := msg.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]; ok {
+				headers["Deliveries"] = count
 			}
-		}
 
-		// Store receipt handle as "queueURL|receiptHandle"
-		receiptHandle := fmt.Sprintf("%s|%s", queueURL, aws.ToString(msg.ReceiptHandle))
+			// Store receipt handle as "queueURL|receiptHandle"
+			receiptHandle := fmt.Sprintf("%s|%s", queueURL, aws.ToString(msg.ReceiptHandle))
 
-		return QueueMessage{
-			ID:            aws.ToString(msg.MessageId),
-			Body:          []byte(aws.ToString(msg.Body)),
-			ReceiptHandle: receiptHandle,
-			Headers:       headers,
-		}, nil
+			msgs = append(msgs, QueueMessage{
+				ID:            aws.ToString(msg.MessageId),
+				Body:          []byte(aws.ToString(msg.Body)),
+				ReceiptHandle: receiptHandle,
+				Headers:       headers,
+			})
+		}
+		return msgs, nil
 	}
 }
 
+// MaxBatchSize returns the number of messages received per call
+func (t *SQSTransport) MaxBatchSize() int {
+	return int(t.maxMessages)
+}
+
 // Send sends a message to SQS
 func (t *SQSTransport) Send(ctx context.Context, queueName string, body []byte) error {
+	return t.SendDelayed(ctx, queueName, body, 0)
+}
+
+// SendDelayed sends a message that becomes visible after delay, capped at SQS's maximum of 15 minutes.
+// FIFO queues have no per-message delay, so the delay is waited out before sending.
+func (t *SQSTransport) SendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error {
 	queueURL, err := t.resolveQueueURL(ctx, queueName)
 	if err != nil {
 		slog.Error("Failed to resolve queue URL", "queueName", queueName, "error", err)
 		return fmt.Errorf("failed to resolve queue URL for %s: %w", queueName, err)
 	}
 
-	result, err := t.client.SendMessage(ctx, &sqs.SendMessageInput{
+	input := &sqs.SendMessageInput{
 		QueueUrl:    aws.String(queueURL),
 		MessageBody: aws.String(string(body)),
-	})
+	}
+	if t.fifo {
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		groupID, deduplicationID := fifoMessageIDs(body, t.messageGroup)
+		input.MessageGroupId = aws.String(groupID)
+		input.MessageDeduplicationId = aws.String(deduplicationID)
+	} else {
+		input.DelaySeconds = int32(min(delay, sqsMaxDelay) / time.Second)
+	}
+
+	result, err := t.client.SendMessage(ctx, input)
 	if err != nil {
 		slog.Error("SQS SendMessage failed", "queueName", queueName, "queueURL", queueURL, "error", err)
 		return fmt.Errorf("failed to send to SQS: %w", err)
@@ -239,6 +358,141 @@ func (t *SQSTransport) Send(ctx context.Context, queueName string, body []byte)
 	return nil
 }
 
+// SendBatch sends messages to SQS with SendMessageBatch, in chunks within SQS's limits of
+// 10 messages and 256 KiB per call. It fails if any message is not sent.
+func (t *SQSTransport) SendBatch(ctx context.Context, queueName string, bodies [][]byte) error {
+	queueURL, err := t.resolveQueueURL(ctx, queueName)
+	if err != nil {
+		slog.Error("Failed to resolve queue URL", "queueName", queueName, "error", err)
+		return fmt.Errorf("failed to resolve queue URL for %s: %w", queueName, err)
+	}
+
+	var entries []types.SendMessageBatchRequestEntry
+	var size int
+	for i, body := range bodies {
+		if len(entries) == sqsMaxBatchEntries || (len(entries) > 0 && size+len(body) > sqsMaxBatchBytes) {
+			if err := t.sendMessageBatch(ctx, queueName, queueURL, entries); err != nil {
+				return err
+			}
+			entries, size = nil, 0
+		}
+
+		entry := types.SendMessageBatchRequestEntry{
+			Id:          aws.String(strconv.Itoa(i)),
+			MessageBody: aws.String(string(body)),
+		}
+		if t.fifo {
+			groupID, deduplicationID := fifoMessageIDs(body, t.messageGroup)
+			entry.MessageGroupId = aws.String(groupID)
+			entry.MessageDeduplicationId = aws.String(deduplicationID)
+		}
+		entries = append(entries, entry)
+		size += len(body)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return t.sendMessageBatch(ctx, queueName, queueURL, entries)
+}
+
+// sendMessageBatch sends one SendMessageBatch call and reports failed entries as an error
+func (t *SQSTransport) sendMessageBatch(ctx context.Context, queueName, queueURL string, entries []types.SendMessageBatchRequestEntry) error {
+	result, err := t.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		slog.Error("SQS SendMessageBatch failed", "queueName", queueName, "queueURL", queueURL, "error", err)
+		return fmt.Errorf("failed to send batch to SQS: %w", err)
+	}
+
+	if len(result.Failed) > 0 {
+		failed := result.Failed[0]
+		slog.Error("SQS SendMessageBatch partially failed", "queueName", queueName, "failed", len(result.Failed), "total", len(entries),
+			"code", aws.ToString(failed.Code), "error", aws.ToString(failed.Message))
+		return fmt.Errorf("failed to send %d of %d messages to SQS: %s: %s", len(result.Failed), len(entries), aws.ToString(failed.Code), aws.ToString(failed.Message))
+	}
+
+	slog.Info("SQS message batch sent successfully", "queueName", queueName, "messages", len(entries))
+	return nil
+}
+
+// fifoMessageIDs returns the message group and deduplication IDs of a message sent to a FIFO queue.
+// Envelopes are grouped by the messageGroup field, falling back to the envelope ID, and deduplicated by
+// envelope ID, hop count and attempt, so a redelivered actor's resend is dropped but a retry is not.
+// Other messages (e.g. status events) are grouped by their ID and deduplicated by content.
+func fifoMessageIDs(body []byte, messageGroup string) (groupID, deduplicationID string) {
+	var envelope envelopes.Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.ID == "" {
+		return sqsDefaultMessageGroup, hashMessageID(string(body))
+	}
+
+	groupID = envelope.ID
+	if value, ok := envelopeField(envelope.Headers, envelope.Payload, messageGroup); ok {
+		groupID = value
+	} else if messageGroup != "" {
+		slog.Warn("FIFO message group field not found, grouping by envelope ID", "id", envelope.ID, "field", messageGroup)
+	}
+	if !sqsMessageIDPattern.MatchString(groupID) {
+		groupID = hashMessageID(groupID)
+	}
+
+	if len(envelope.Route.Actors) == 0 {
+		return groupID, hashMessageID(string(body))
+	}
+	return groupID, hashMessageID(fmt.Sprintf("%s/%d/%d", envelope.ID, envelope.HopCount, envelope.Attempt()))
+}
+
+// envelopeField returns the scalar value at a "headers.<key>" or "payload.<path>" field of an envelope
+func envelopeField(headers map[string]interface{}, payload json.RawMessage, field string) (string, bool) {
+	source, path, ok := strings.Cut(field, ".")
+	if !ok {
+		return "", false
+	}
+
+	var value interface{}
+	switch source {
+	case "headers":
+		value = headers
+	case "payload":
+		decoder := json.NewDecoder(strings.NewReader(string(payload)))
+		decoder.UseNumber() // Keep numbers as written
+		if err := decoder.Decode(&value); err != nil {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// hashMessageID returns a hex SHA-256 digest of value, valid as a FIFO message group or deduplication ID
+func hashMessageID(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 // Ack acknowledges a message by deleting it from the queue
 func (t *SQSTransport) Ack(ctx context.Context, msg QueueMessage) error {
 	queueURL, receiptHandle, err := splitReceiptHandle(msg.ReceiptHandle)
@@ -277,6 +531,30 @@ func (t *SQSTransport) Nack(ctx context.Context, msg QueueMessage) error {
 	return nil
 }
 
+// VisibilityTimeout returns the visibility timeout of received messages
+func (t *SQSTransport) VisibilityTimeout() time.Duration {
+	return time.Duration(t.visibilityTimeout) * time.Second
+}
+
+// ExtendVisibility hides a received message from other consumers for timeout from now
+func (t *SQSTransport) ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error {
+	queueURL, receiptHandle, err := splitReceiptHandle(msg.ReceiptHandle)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to extend message visibility: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the SQS transport (no-op for SQS client)
 func (t *SQSTransport) Close() error {
 	return nil
@@ -2,7 +2,11 @@ package transport
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -18,6 +22,7 @@ const (
 type mockSQSClient struct {
 	receiveMessageFunc          func(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	sendMessageFunc             func(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	sendMessageBatchFunc        func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 	deleteMessageFunc           func(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
 	changeMessageVisibilityFunc func(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 	getQueueUrlFunc             func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
@@ -37,6 +42,13 @@ func (m *mockSQSClient) SendMessage(ctx context.Context, params *sqs.SendMessage
 	return nil, nil
 }
 
+func (m *mockSQSClient) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	if m.sendMessageBatchFunc != nil {
+		return m.sendMessageBatchFunc(ctx, params, optFns...)
+	}
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+
 func (m *mockSQSClient) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
 	if m.deleteMessageFunc != nil {
 		return m.deleteMessageFunc(ctx, params, optFns...)
@@ -69,6 +81,7 @@ func createMockSQSTransportWithTimeout(mockClient *mockSQSClient, visibilityTime
 		region:            "us-east-1",
 		visibilityTimeout: visibilityTimeout,
 		waitTimeSeconds:   20,
+		maxMessages:       1,
 		queueURLCache:     make(map[string]string),
 	}
 }
@@ -202,6 +215,231 @@ func TestSQSTransport_Send(t *testing.T) {
 			t.Errorf("Send() error = %v, want nil", err)
 		}
 	})
+
+	t.Run("delayed send is capped at 15 minutes", func(t *testing.T) {
+		var delays []int32
+		mockClient := &mockSQSClient{
+			getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+				return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(queueURL)}, nil
+			},
+			sendMessageFunc: func(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+				delays = append(delays, params.DelaySeconds)
+				return &sqs.SendMessageOutput{MessageId: aws.String("msg-123")}, nil
+			},
+		}
+
+		transport := createMockSQSTransport(mockClient)
+		for _, delay := range []time.Duration{0, 30 * time.Second, time.Hour} {
+			if err := transport.SendDelayed(ctx, queueName, messageBody, delay); err != nil {
+				t.Fatalf("SendDelayed(%s) error = %v", delay, err)
+			}
+		}
+		if want := []int32{0, 30, 900}; !reflect.DeepEqual(delays, want) {
+			t.Errorf("DelaySeconds = %v, want %v", delays, want)
+		}
+	})
+}
+
+func TestSQSTransport_SendFIFO(t *testing.T) {
+	ctx := context.Background()
+
+	var sent []*sqs.SendMessageInput
+	mockClient := &mockSQSClient{
+		getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+			if *params.QueueName != testQueueName+".fifo" {
+				t.Errorf("QueueName = %v, want %v", *params.QueueName, testQueueName+".fifo")
+			}
+			return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(testQueueURL + ".fifo")}, nil
+		},
+		sendMessageFunc: func(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+			sent = append(sent, params)
+			return &sqs.SendMessageOutput{MessageId: aws.String("msg-123")}, nil
+		},
+	}
+	transport := createMockSQSTransport(mockClient)
+	transport.fifo = true
+	transport.messageGroup = "headers.customer_id"
+
+	bodies := []string{
+		`{"id":"env-1","route":{"actors":["a","b"],"current":0},"headers":{"customer_id":"c-42"},"payload":{}}`,
+		`{"id":"env-1","route":{"actors":["a","b"],"current":1},"hop_count":1,"headers":{"customer_id":"c-42"},"payload":{}}`,
+		`{"id":"env-1","route":{"actors":["a","b"],"current":1},"hop_count":1,"headers":{"customer_id":"c-42","asya_attempt":2},"payload":{}}`,
+		`{"id":"env-2","route":{"actors":["a"],"current":0},"payload":{}}`,
+	}
+	for _, body := range bodies {
+		if err := transport.SendDelayed(ctx, testQueueName, []byte(body), 0); err != nil {
+			t.Fatalf("SendDelayed() error = %v", err)
+		}
+	}
+
+	if len(sent) != len(bodies) {
+		t.Fatalf("Sent %d messages, want %d", len(sent), len(bodies))
+	}
+	for i, params := range sent {
+		if params.DelaySeconds != 0 {
+			t.Errorf("message %d: DelaySeconds = %d, FIFO queues take no per-message delay", i, params.DelaySeconds)
+		}
+	}
+	if got := aws.ToString(sent[0].MessageGroupId); got != "c-42" {
+		t.Errorf("MessageGroupId = %q, want the customer_id header", got)
+	}
+	if got := aws.ToString(sent[3].MessageGroupId); got != "env-2" {
+		t.Errorf("MessageGroupId = %q, want the envelope ID without the header", got)
+	}
+
+	deduplicationIDs := map[string]bool{}
+	for _, params := range sent {
+		deduplicationIDs[aws.ToString(params.MessageDeduplicationId)] = true
+	}
+	if len(deduplicationIDs) != len(bodies) {
+		t.Errorf("Expected a distinct deduplication ID per hop and attempt, got %d of %d", len(deduplicationIDs), len(bodies))
+	}
+
+	// A resend of the same hop has the same deduplication ID
+	if err := transport.Send(ctx, testQueueName, []byte(bodies[1])); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got, want := aws.ToString(sent[4].MessageDeduplicationId), aws.ToString(sent[1].MessageDeduplicationId); got != want {
+		t.Errorf("MessageDeduplicationId of resend = %q, want %q", got, want)
+	}
+}
+
+func TestSQSTransport_SendBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("chunks by entry count and size", func(t *testing.T) {
+		var batches [][]types.SendMessageBatchRequestEntry
+		mockClient := &mockSQSClient{
+			getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+				return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(testQueueURL)}, nil
+			},
+			sendMessageBatchFunc: func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+				if *params.QueueUrl != testQueueURL {
+					t.Errorf("QueueUrl = %v, want %v", *params.QueueUrl, testQueueURL)
+				}
+				batches = append(batches, params.Entries)
+				return &sqs.SendMessageBatchOutput{}, nil
+			},
+		}
+		transport := createMockSQSTransport(mockClient)
+
+		var bodies [][]byte
+		for i := 0; i < 12; i++ {
+			bodies = append(bodies, []byte(fmt.Sprintf(`{"index":%d}`, i)))
+		}
+		large := []byte(`"` + strings.Repeat("x", 200*1024) + `"`)
+		bodies = append(bodies, large, large)
+
+		if err := transport.SendBatch(ctx, testQueueName, bodies); err != nil {
+			t.Fatalf("SendBatch() error = %v", err)
+		}
+
+		var sizes []int
+		for _, batch := range batches {
+			sizes = append(sizes, len(batch))
+		}
+		if want := []int{10, 3, 1}; !reflect.DeepEqual(sizes, want) {
+			t.Errorf("batch sizes = %v, want %v", sizes, want)
+		}
+		if got := aws.ToString(batches[0][0].MessageBody); got != `{"index":0}` {
+			t.Errorf("first MessageBody = %q, want %q", got, `{"index":0}`)
+		}
+	})
+
+	t.Run("failed entries", func(t *testing.T) {
+		mockClient := &mockSQSClient{
+			getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+				return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(testQueueURL)}, nil
+			},
+			sendMessageBatchFunc: func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+				return &sqs.SendMessageBatchOutput{
+					Failed: []types.BatchResultErrorEntry{{Id: aws.String("1"), Code: aws.String("InternalError"), Message: aws.String("try again")}},
+				}, nil
+			},
+		}
+		transport := createMockSQSTransport(mockClient)
+
+		err := transport.SendBatch(ctx, testQueueName, [][]byte{[]byte(`{}`), []byte(`{}`)})
+		if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+			t.Errorf("SendBatch() error = %v, want 1 of 2 messages failed", err)
+		}
+	})
+
+	t.Run("FIFO entries carry message IDs", func(t *testing.T) {
+		var entries []types.SendMessageBatchRequestEntry
+		mockClient := &mockSQSClient{
+			getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+				return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(testQueueURL + ".fifo")}, nil
+			},
+			sendMessageBatchFunc: func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+				entries = append(entries, params.Entries...)
+				return &sqs.SendMessageBatchOutput{}, nil
+			},
+		}
+		transport := createMockSQSTransport(mockClient)
+		transport.fifo = true
+
+		bodies := [][]byte{
+			[]byte(`{"id":"env-1","route":{"actors":["a","b"],"current":1},"payload":{}}`),
+			[]byte(`{"id":"env-1-1","parent_id":"env-1","route":{"actors":["a","b"],"current":1},"payload":{}}`),
+		}
+		if err := transport.SendBatch(ctx, testQueueName, bodies); err != nil {
+			t.Fatalf("SendBatch() error = %v", err)
+		}
+
+		if len(entries) != 2 {
+			t.Fatalf("Sent %d entries, want 2", len(entries))
+		}
+		for i, entry := range entries {
+			if entry.MessageGroupId == nil || entry.MessageDeduplicationId == nil {
+				t.Errorf("entry %d: MessageGroupId = %v, MessageDeduplicationId = %v, want both set", i, entry.MessageGroupId, entry.MessageDeduplicationId)
+			}
+		}
+		if aws.ToString(entries[0].MessageDeduplicationId) == aws.ToString(entries[1].MessageDeduplicationId) {
+			t.Error("Fan-out children must have distinct deduplication IDs")
+		}
+	})
+}
+
+func TestEnvelopeField(t *testing.T) {
+	headers := map[string]interface{}{"customer_id": "c-42", "tenant": float64(7)}
+	payload := []byte(`{"customer":{"id":12345678901234567890,"name":"acme"},"tags":["a"]}`)
+
+	tests := []struct {
+		field  string
+		want   string
+		wantOK bool
+	}{
+		{field: "headers.customer_id", want: "c-42", wantOK: true},
+		{field: "headers.tenant", want: "7", wantOK: true},
+		{field: "payload.customer.id", want: "12345678901234567890", wantOK: true},
+		{field: "payload.customer.name", want: "acme", wantOK: true},
+		{field: "payload.customer", wantOK: false},
+		{field: "payload.tags", wantOK: false},
+		{field: "headers.missing", wantOK: false},
+		{field: "route.current", wantOK: false},
+		{field: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := envelopeField(headers, payload, tt.field)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("envelopeField(%q) = %q, %v, want %q, %v", tt.field, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestValidateMessageGroup(t *testing.T) {
+	for _, field := range []string{"", "headers.customer_id", "payload.customer.id"} {
+		if err := ValidateMessageGroup(field); err != nil {
+			t.Errorf("ValidateMessageGroup(%q) error = %v, want nil", field, err)
+		}
+	}
+	for _, field := range []string{"customer_id", "headers.", "payload.customer..id", "route.current"} {
+		if err := ValidateMessageGroup(field); err == nil {
+			t.Errorf("ValidateMessageGroup(%q) error = nil, want an error", field)
+		}
+	}
 }
 
 func TestSQSTransport_Receive(t *testing.T) {
@@ -229,6 +467,9 @@ func TestSQSTransport_Receive(t *testing.T) {
 				if params.VisibilityTimeout != 300 {
 					t.Errorf("VisibilityTimeout = %v, want 300", params.VisibilityTimeout)
 				}
+				if len(params.MessageSystemAttributeNames) != 1 || params.MessageSystemAttributeNames[0] != types.MessageSystemAttributeNameApproximateReceiveCount {
+					t.Errorf("MessageSystemAttributeNames = %v, want [ApproximateReceiveCount]", params.MessageSystemAttributeNames)
+				}
 
 				return &sqs.ReceiveMessageOutput{
 					Messages: []types.Message{
@@ -242,6 +483,9 @@ func TestSQSTransport_Receive(t *testing.T) {
 									StringValue: aws.String("trace-xyz"),
 								},
 							},
+							Attributes: map[string]string{
+								"ApproximateReceiveCount": "3",
+							},
 						},
 					},
 				}, nil
@@ -283,6 +527,9 @@ func TestSQSTransport_Receive(t *testing.T) {
 			if msg.Headers["QueueName"] != queueName {
 				t.Errorf("Headers[QueueName] = %v, want %v", msg.Headers["QueueName"], queueName)
 			}
+			if msg.Headers["Deliveries"] != "3" {
+				t.Errorf("Headers[Deliveries] = %v, want 3", msg.Headers["Deliveries"])
+			}
 		case err := <-errChan:
 			t.Errorf("Receive() error = %v, want nil", err)
 		case <-ctx.Done():
@@ -316,6 +563,76 @@ func TestSQSTransport_Receive(t *testing.T) {
 	})
 }
 
+func TestSQSTransport_ReceiveBatch(t *testing.T) {
+	ctx := context.Background()
+
+	var maxMessages []int32
+	mockClient := &mockSQSClient{
+		getQueueUrlFunc: func(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+			return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(testQueueURL)}, nil
+		},
+		receiveMessageFunc: func(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+			maxMessages = append(maxMessages, params.MaxNumberOfMessages)
+			var messages []types.Message
+			for i := int32(0); i < min(params.MaxNumberOfMessages, 3); i++ {
+				messages = append(messages, types.Message{
+					MessageId:     aws.String(fmt.Sprintf("msg-%d", i)),
+					Body:          aws.String(`{}`),
+					ReceiptHandle: aws.String(fmt.Sprintf("receipt-%d", i)),
+				})
+			}
+			return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+		},
+	}
+	transport := createMockSQSTransport(mockClient)
+
+	msgs, err := transport.ReceiveBatch(ctx, testQueueName, 5)
+	if err != nil {
+		t.Fatalf("ReceiveBatch() error = %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("Received %d messages, want 3", len(msgs))
+	}
+	for i, msg := range msgs {
+		if want := fmt.Sprintf("%s|receipt-%d", testQueueURL, i); msg.ReceiptHandle != want {
+			t.Errorf("message %d: ReceiptHandle = %v, want %v", i, msg.ReceiptHandle, want)
+		}
+	}
+
+	if _, err := transport.ReceiveBatch(ctx, testQueueName, 50); err != nil {
+		t.Fatalf("ReceiveBatch() error = %v", err)
+	}
+	if want := []int32{5, 10}; !reflect.DeepEqual(maxMessages, want) {
+		t.Errorf("MaxNumberOfMessages = %v, want %v (capped at 10)", maxMessages, want)
+	}
+}
+
+func TestSQSTransport_ExtendVisibility(t *testing.T) {
+	var timeouts []int32
+	mockClient := &mockSQSClient{
+		changeMessageVisibilityFunc: func(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+			if *params.ReceiptHandle != "receipt-handle-123" {
+				t.Errorf("ReceiptHandle = %v, want receipt-handle-123", *params.ReceiptHandle)
+			}
+			timeouts = append(timeouts, params.VisibilityTimeout)
+			return &sqs.ChangeMessageVisibilityOutput{}, nil
+		},
+	}
+	transport := createMockSQSTransportWithTimeout(mockClient, 60)
+
+	if got := transport.VisibilityTimeout(); got != time.Minute {
+		t.Errorf("VisibilityTimeout() = %v, want 1m", got)
+	}
+
+	msg := QueueMessage{ReceiptHandle: testQueueURL + "|receipt-handle-123"}
+	if err := transport.ExtendVisibility(context.Background(), msg, transport.VisibilityTimeout()); err != nil {
+		t.Fatalf("ExtendVisibility() error = %v", err)
+	}
+	if want := []int32{60}; !reflect.DeepEqual(timeouts, want) {
+		t.Errorf("VisibilityTimeout = %v, want %v", timeouts, want)
+	}
+}
+
 func TestSQSTransport_Ack(t *testing.T) {
 	ctx := context.Background()
 	queueURL := testQueueURL
@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"time"
 )
 
 // QueueMessage represents a message received from a queue
@@ -34,3 +35,32 @@ type Transport interface {
 	// Close closes the transport connection
 	Close() error
 }
+
+// DelayedSender is implemented by transports that can send a message that becomes visible only after a delay
+type DelayedSender interface {
+	SendDelayed(ctx context.Context, queueName string, body []byte, delay time.Duration) error
+}
+
+// BatchSender is implemented by transports that can send several messages to a queue in one call
+type BatchSender interface {
+	SendBatch(ctx context.Context, queueName string, bodies [][]byte) error
+}
+
+// BatchReceiver is implemented by transports that can receive several messages in one call
+type BatchReceiver interface {
+	// ReceiveBatch blocks until a message arrives and returns up to limit messages
+	ReceiveBatch(ctx context.Context, queueName string, limit int) ([]QueueMessage, error)
+
+	// MaxBatchSize returns the configured number of messages to receive per call, processed concurrently
+	MaxBatchSize() int
+}
+
+// VisibilityExtender is implemented by transports that redeliver a received message
+// when it is neither acked nor nacked within a visibility timeout
+type VisibilityExtender interface {
+	// VisibilityTimeout returns the visibility timeout of received messages
+	VisibilityTimeout() time.Duration
+
+	// ExtendVisibility hides msg from other consumers for timeout from now
+	ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error
+}
@@ -1,6 +1,35 @@
 package envelopes
 
-import "encoding/json"
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeoutsMetadataKey is the route metadata key holding per-actor runtime timeouts, set by the gateway
+// from the tool's route as actor name to Go duration string, e.g. {"ocr": "30s"}
+const TimeoutsMetadataKey = "timeouts"
+
+// CompensationsMetadataKey is the route metadata key holding per-actor compensating actors, set by the gateway
+// from the tool's route as actor name to compensating actor, e.g. {"charge-card": "refund-card"}
+const CompensationsMetadataKey = "compensations"
+
+// AttemptHeader is the envelope header counting the attempts of the current actor, set by the sidecar
+// when it requeues an envelope to retry a runtime error. Envelopes without it are on their first attempt.
+const AttemptHeader = "asya_attempt"
+
+// SplitActorRef splits an actor reference from a route into namespace and actor name.
+// References are either a bare actor name, resolved in defaultNamespace, or qualified as "namespace/actor".
+func SplitActorRef(ref, defaultNamespace string) (namespace, actor string) {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return ns, name
+	}
+	return defaultNamespace, ref
+}
 
 // Route represents the routing information for a message
 type Route struct {
@@ -28,6 +57,100 @@ type Envelope struct {
 	Route    Route                  `json:"route"`
 	Headers  map[string]interface{} `json:"headers,omitempty"`
 	Payload  json.RawMessage        `json:"payload"`
+	HopCount int                    `json:"hop_count,omitempty"` // Number of actors that have processed the envelope
+	Visited  []string               `json:"visited,omitempty"`   // Actors that have processed the envelope, in order
+	MaxHops  int                    `json:"max_hops,omitempty"`  // Per-envelope hop limit set by the gateway (0 uses the sidecar default)
+	Hops     []Hop                  `json:"hops,omitempty"`      // Execution record of each actor that processed the envelope, in order
+
+	CallStack []CallFrame `json:"call_stack,omitempty"` // Continuations of actors waiting for a called sub-route, innermost last
+
+	Shadow *Shadow `json:"shadow,omitempty"` // Set on copies mirrored to a shadow actor
+
+	Compensation *Compensation `json:"compensation,omitempty"` // Set while a failed envelope runs its compensating actors
+
+	// Sub-route to call before resuming Route, returned by envelope-mode handlers only.
+	// The sidecar resolves it into the call stack and never forwards it.
+	Call *Call `json:"call,omitempty"`
+}
+
+// Attempt returns the attempt of the current actor from the AttemptHeader, or 1 if it is not set
+func (e *Envelope) Attempt() int {
+	switch attempt := e.Headers[AttemptHeader].(type) {
+	case float64:
+		if attempt >= 1 {
+			return int(attempt)
+		}
+	case int:
+		if attempt >= 1 {
+			return attempt
+		}
+	}
+	return 1
+}
+
+// Compensation marks a failed envelope on its way through the compensating actors of the actors it completed.
+// Route is then the compensation route and Payload the error payload; once the compensation route is
+// exhausted, the envelope goes to error-end with the route it failed on.
+type Compensation struct {
+	Route  Route    `json:"route"`            // Route of the envelope when it failed
+	Failed []string `json:"failed,omitempty"` // Compensating actors whose runtime failed, in order
+}
+
+// Shadow marks a copy of an envelope mirrored to a shadow actor, carrying the primary actor's result.
+// The shadow's sidecar compares its own result with it and drops the envelope instead of routing it
+// or reporting it to the gateway.
+type Shadow struct {
+	Primary           string `json:"primary"`             // Actor the envelope was mirrored from
+	Outcome           string `json:"outcome"`             // Primary runtime outcome: "succeeded" | "failed"
+	ResultDigest      string `json:"result_digest"`       // ResultDigest of the primary's response payloads
+	RuntimeDurationMs int64  `json:"runtime_duration_ms"` // Primary runtime duration
+}
+
+// ResultDigest returns a hex SHA-256 digest of response payloads, in order. Payloads are normalized
+// (object keys sorted, insignificant whitespace removed) so equal JSON values give equal digests.
+func ResultDigest(payloads []json.RawMessage) string {
+	hash := sha256.New()
+	for _, payload := range payloads {
+		var value interface{}
+		normalized := []byte(payload)
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.UseNumber() // Keep numbers as written instead of rounding them to float64
+		if err := decoder.Decode(&value); err == nil {
+			if b, err := json.Marshal(value); err == nil {
+				normalized = b
+			}
+		}
+		_, _ = fmt.Fprintf(hash, "%d:", len(normalized))
+		hash.Write(normalized)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// CallFrame is pushed when an actor calls a sub-route. Once the sub-route is exhausted the sidecar
+// pops the frame and routes the result to Route's current actor instead of happy-end.
+type CallFrame struct {
+	Caller string `json:"caller"` // Actor that made the call
+	Route  Route  `json:"route"`  // Route to resume with the sub-route's result
+}
+
+// Call is a sub-route called by an actor, given either as its actors or as the name of a route
+// in the sidecar's ASYA_NAMED_ROUTES, so shared pipelines need not be listed in every handler
+type Call struct {
+	Name    string   `json:"name,omitempty"`
+	Actors  []string `json:"actors,omitempty"`
+	Current int      `json:"current,omitempty"`
+}
+
+// Hop records one actor's processing of an envelope, appended by the sidecar when the envelope leaves the actor
+type Hop struct {
+	Actor             string     `json:"actor"`
+	Pod               string     `json:"pod,omitempty"`
+	ReceivedAt        time.Time  `json:"received_at"`          // Message received from the queue
+	StartedAt         *time.Time `json:"started_at,omitempty"` // Runtime call started (nil if the runtime was not called)
+	FinishedAt        time.Time  `json:"finished_at"`          // Envelope sent on
+	RuntimeDurationMs int64      `json:"runtime_duration_ms"`
+	Attempt           int        `json:"attempt"` // Delivery attempt of the message (1 for the first delivery)
+	Outcome           string     `json:"outcome"` // "succeeded" | "failed"
 }
 
 // GetCurrentActor returns the current actor name from the route
@@ -38,6 +161,46 @@ func (r *Route) GetCurrentActor() string {
 	return ""
 }
 
+// GetCurrentTimeout returns the runtime timeout set in the route metadata for the current actor, or 0 if none
+func (r *Route) GetCurrentTimeout() (time.Duration, error) {
+	return r.GetTimeout(r.GetCurrentActor())
+}
+
+// GetTimeout returns the runtime timeout set in the route metadata for actor, or 0 if none
+func (r *Route) GetTimeout(actor string) (time.Duration, error) {
+	timeouts, ok := r.Metadata[TimeoutsMetadataKey].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	value, ok := timeouts[actor]
+	if !ok {
+		return 0, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("timeout for actor %q must be a duration string, got %v", actor, value)
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout for actor %q: %w", actor, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout for actor %q must be positive, got %s", actor, timeout)
+	}
+	return timeout, nil
+}
+
+// GetCompensator returns the compensating actor set in the route metadata for actor, or "" if none
+func (r *Route) GetCompensator(actor string) string {
+	compensations, ok := r.Metadata[CompensationsMetadataKey].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	compensator, _ := compensations[actor].(string)
+	return compensator
+}
+
 // GetNextActor returns the next actor name, or empty if at the end
 func (r *Route) GetNextActor() string {
 	nextIndex := r.Current + 1
@@ -3,8 +3,30 @@ package envelopes
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
+func TestSplitActorRef(t *testing.T) {
+	tests := []struct {
+		ref               string
+		expectedNamespace string
+		expectedActor     string
+	}{
+		{ref: "ocr", expectedNamespace: "team-a", expectedActor: "ocr"},
+		{ref: "team-b/ocr", expectedNamespace: "team-b", expectedActor: "ocr"},
+		{ref: "team-a/ocr", expectedNamespace: "team-a", expectedActor: "ocr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			namespace, actor := SplitActorRef(tt.ref, "team-a")
+			if namespace != tt.expectedNamespace || actor != tt.expectedActor {
+				t.Errorf("SplitActorRef(%q) = (%q, %q), want (%q, %q)", tt.ref, namespace, actor, tt.expectedNamespace, tt.expectedActor)
+			}
+		})
+	}
+}
+
 func TestRoute_GetCurrentActor(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -48,6 +70,58 @@ func TestRoute_GetCurrentActor(t *testing.T) {
 	}
 }
 
+func TestRoute_GetCurrentTimeout(t *testing.T) {
+	timeouts := map[string]interface{}{"ocr": "30s", "llm": "10m", "bad": "soon", "zero": "0s", "number": 30}
+
+	tests := []struct {
+		name     string
+		route    Route
+		expected time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "timeout for current actor",
+			route:    Route{Actors: []string{"ocr", "llm"}, Current: 1, Metadata: map[string]interface{}{TimeoutsMetadataKey: timeouts}},
+			expected: 10 * time.Minute,
+		},
+		{
+			name:  "no timeout for current actor",
+			route: Route{Actors: []string{"ocr", "prep"}, Current: 1, Metadata: map[string]interface{}{TimeoutsMetadataKey: timeouts}},
+		},
+		{
+			name:  "no metadata",
+			route: Route{Actors: []string{"ocr"}, Current: 0},
+		},
+		{
+			name:    "unparseable timeout",
+			route:   Route{Actors: []string{"bad"}, Current: 0, Metadata: map[string]interface{}{TimeoutsMetadataKey: timeouts}},
+			wantErr: true,
+		},
+		{
+			name:    "non-positive timeout",
+			route:   Route{Actors: []string{"zero"}, Current: 0, Metadata: map[string]interface{}{TimeoutsMetadataKey: timeouts}},
+			wantErr: true,
+		},
+		{
+			name:    "non-string timeout",
+			route:   Route{Actors: []string{"number"}, Current: 0, Metadata: map[string]interface{}{TimeoutsMetadataKey: timeouts}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.route.GetCurrentTimeout()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCurrentTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("GetCurrentTimeout() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestRoute_GetNextActor(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -249,6 +323,75 @@ func TestEnvelope_ParentID_Serialization(t *testing.T) {
 	}
 }
 
+func TestResultDigest(t *testing.T) {
+	payloads := func(values ...string) []json.RawMessage {
+		result := make([]json.RawMessage, len(values))
+		for i, v := range values {
+			result[i] = json.RawMessage(v)
+		}
+		return result
+	}
+
+	base := ResultDigest(payloads(`{"label": "cat", "score": 0.9}`))
+	if got := ResultDigest(payloads(`{"score":0.9,"label":"cat"}`)); got != base {
+		t.Errorf("Expected equal digests for reordered keys, got %s and %s", got, base)
+	}
+	if got := ResultDigest(payloads(`{"label":"dog","score":0.9}`)); got == base {
+		t.Error("Expected different digests for different payloads")
+	}
+	if ResultDigest(payloads(`12345678901234567890`)) == ResultDigest(payloads(`12345678901234567891`)) {
+		t.Error("Expected large integers to keep their precision")
+	}
+	if ResultDigest(payloads(`"ab"`, `"c"`)) == ResultDigest(payloads(`"a"`, `"bc"`)) {
+		t.Error("Expected payload boundaries to affect the digest")
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestRoute_GetCompensator(t *testing.T) {
+	route := Route{
+		Actors: []string{"reserve", "charge"},
+		Metadata: map[string]interface{}{
+			CompensationsMetadataKey: map[string]interface{}{"charge": "refund", "reserve": 3},
+		},
+	}
+
+	if got := route.GetCompensator("charge"); got != "refund" {
+		t.Errorf("GetCompensator(charge) = %q, want refund", got)
+	}
+	if got := route.GetCompensator("reserve"); got != "" {
+		t.Errorf("GetCompensator(reserve) = %q, want empty for a non-string entry", got)
+	}
+	if got := route.GetCompensator("ship"); got != "" {
+		t.Errorf("GetCompensator(ship) = %q, want empty", got)
+	}
+	if got := (&Route{Actors: []string{"charge"}}).GetCompensator("charge"); got != "" {
+		t.Errorf("GetCompensator without metadata = %q, want empty", got)
+	}
+}
+
+func TestEnvelope_Attempt(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]interface{}
+		want    int
+	}{
+		{name: "no headers", headers: nil, want: 1},
+		{name: "decoded from JSON", headers: map[string]interface{}{AttemptHeader: float64(3)}, want: 3},
+		{name: "set by the sidecar", headers: map[string]interface{}{AttemptHeader: 2}, want: 2},
+		{name: "invalid value", headers: map[string]interface{}{AttemptHeader: "2"}, want: 1},
+		{name: "zero", headers: map[string]interface{}{AttemptHeader: float64(0)}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope := Envelope{ID: "test", Headers: tt.headers}
+			if got := envelope.Attempt(); got != tt.want {
+				t.Errorf("Attempt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
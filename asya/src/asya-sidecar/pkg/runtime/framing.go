@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteFrame writes data with a length prefix (4-byte big-endian uint32)
+func WriteFrame(w io.Writer, data []byte) error {
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+
+	if _, err := w.Write(frame); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	return nil
+}
+
+// ReadFrame reads data with a length prefix (4-byte big-endian uint32)
+func ReadFrame(r io.Reader) ([]byte, error) {
+	length := make([]byte, 4)
+	if _, err := io.ReadFull(r, length); err != nil {
+		return nil, fmt.Errorf("failed to read length prefix: %w", err)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(length))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	return data, nil
+}
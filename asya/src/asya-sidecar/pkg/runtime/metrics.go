@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+)
+
+// MetricObservation is a custom metric update sent to the sidecar with the handler's reply
+type MetricObservation struct {
+	Name   string            `json:"name"`
+	Value  float64           `json:"value"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// metricsResponse is a reply item carrying only metric observations; the sidecar records it and does not route it
+type metricsResponse struct {
+	Metrics []MetricObservation `json:"metrics"`
+}
+
+type metricsKey struct{}
+
+// metricsCollector gathers the observations emitted during one request
+type metricsCollector struct {
+	mu           sync.Mutex
+	observations []MetricObservation
+}
+
+// EmitMetric records a custom metric observation for the current request.
+// The metric must be declared in the sidecar's ASYA_CUSTOM_METRICS and labels must match
+// its declared labels: counters are incremented by value, gauges set to value and
+// histograms observe value. Invalid observations are dropped by the sidecar.
+// Calls with a context that does not come from the runtime are ignored.
+func EmitMetric(ctx context.Context, name string, value float64, labels map[string]string) {
+	c, ok := ctx.Value(metricsKey{}).(*metricsCollector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observations = append(c.observations, MetricObservation{Name: name, Value: value, Labels: labels})
+}
+
+// withMetrics appends the collected observations to the reply as a metrics-only item
+func withMetrics[T any](items []T, c *metricsCollector) any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.observations) == 0 {
+		return items
+	}
+	reply := make([]any, 0, len(items)+1)
+	for _, item := range items {
+		reply = append(reply, item)
+	}
+	return append(reply, metricsResponse{Metrics: c.observations})
+}
@@ -0,0 +1,135 @@
+// Package runtime implements the actor side of the sidecar socket protocol,
+// allowing actors to be written in Go instead of Python.
+//
+// The sidecar connects to a Unix socket and sends the full envelope as JSON
+// with a 4-byte big-endian length prefix. The runtime replies with a JSON array
+// of output envelopes ({payload, route, headers}) or a single error object
+// ({error, details}). Once listening, the runtime writes a "runtime-ready" file
+// next to the socket so the sidecar knows it can start consuming messages.
+//
+// Payload mode (Handler) mirrors the Python runtime: the handler receives only
+// the payload, and the runtime increments route.current for every result.
+// Envelope mode (EnvelopeHandler) passes the whole envelope and leaves route
+// management to the handler. Setting Call on a returned envelope calls a sub-route,
+// by its actors or by a name from the sidecar's ASYA_NAMED_ROUTES, before resuming
+// the returned route.
+//
+// Handlers can report custom metrics declared in the sidecar's ASYA_CUSTOM_METRICS
+// with EmitMetric; they are appended to the reply as a {metrics} item.
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+// Error codes returned to the sidecar, shared with the Python runtime
+const (
+	ErrCodeProcessing = "processing_error"
+	ErrCodeParsing    = "msg_parsing_error"
+	ErrCodeConnection = "connection_error"
+)
+
+const (
+	defaultSocketDir  = "/var/run/asya"
+	defaultSocketName = "asya-runtime.sock"
+	readyFileName     = "runtime-ready"
+)
+
+// Handler processes a payload and returns zero or more output payloads.
+// Returning no results ends the envelope at happy-end, multiple results fan out.
+// Each result is marshaled to JSON; json.RawMessage values are sent as-is.
+type Handler func(ctx context.Context, payload json.RawMessage) ([]any, error)
+
+// EnvelopeHandler processes a full envelope and returns the output envelopes.
+// The handler is responsible for advancing route.current.
+type EnvelopeHandler func(ctx context.Context, envelope *envelopes.Envelope) ([]*envelopes.Envelope, error)
+
+// Config holds runtime server configuration
+type Config struct {
+	SocketDir        string
+	SocketName       string
+	SocketChmod      os.FileMode // Zero skips chmod
+	EnableValidation bool
+}
+
+// DefaultConfig returns the configuration used by the operator-managed pod layout
+func DefaultConfig() Config {
+	return Config{
+		SocketDir:        defaultSocketDir,
+		SocketName:       defaultSocketName,
+		SocketChmod:      0o666,
+		EnableValidation: true,
+	}
+}
+
+// ConfigFromEnv loads configuration from the same environment variables as the Python runtime.
+// ASYA_SOCKET_DIR and ASYA_SOCKET_NAME are for testing only and are not set by the operator.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if dir := os.Getenv("ASYA_SOCKET_DIR"); dir != "" {
+		cfg.SocketDir = dir
+	}
+	if name := os.Getenv("ASYA_SOCKET_NAME"); name != "" {
+		cfg.SocketName = name
+	}
+
+	if chmod, ok := os.LookupEnv("ASYA_SOCKET_CHMOD"); ok {
+		if chmod == "" {
+			cfg.SocketChmod = 0
+		} else {
+			mode, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(chmod, "0o"), "0O"), 8, 32)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid ASYA_SOCKET_CHMOD %q: %w", chmod, err)
+			}
+			cfg.SocketChmod = os.FileMode(mode)
+		}
+	}
+
+	if v := os.Getenv("ASYA_ENABLE_VALIDATION"); v != "" {
+		cfg.EnableValidation = strings.ToLower(v) == "true"
+	}
+
+	return cfg, nil
+}
+
+// SocketPath returns the full path of the runtime socket
+func (c Config) SocketPath() string {
+	return filepath.Join(c.SocketDir, c.SocketName)
+}
+
+// ReadyFilePath returns the path of the file signaling readiness to the sidecar
+func (c Config) ReadyFilePath() string {
+	return filepath.Join(c.SocketDir, readyFileName)
+}
+
+// ErrorDetails describes a failure returned to the sidecar
+type ErrorDetails struct {
+	Message   string `json:"message,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Traceback string `json:"traceback,omitempty"`
+}
+
+// errorResponse is the single-element error reply sent to the sidecar
+type errorResponse struct {
+	Error   string        `json:"error"`
+	Details *ErrorDetails `json:"details,omitempty"`
+}
+
+// outputEnvelope is a single successful reply item
+type outputEnvelope struct {
+	ID       string                 `json:"id,omitempty"`
+	ParentID *string                `json:"parent_id,omitempty"`
+	Route    envelopes.Route        `json:"route"`
+	Headers  map[string]interface{} `json:"headers,omitempty"`
+	Payload  json.RawMessage        `json:"payload"`
+	Call     *envelopes.Call        `json:"call,omitempty"`
+}
@@ -0,0 +1,155 @@
+// Package runtimetest runs a Go runtime handler behind a real sidecar router in-process,
+// so actors can be tested end-to-end without a queue, gateway or Python runtime.
+package runtimetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/runtime"
+	asyatesting "github.com/deliveryhero/asya/asya-sidecar/pkg/testing"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/transport"
+)
+
+// Actor name and end queues used by the test router
+const (
+	ActorName     = "test-actor"
+	HappyEndQueue = "happy-end"
+	ErrorEndQueue = "error-end"
+)
+
+const readyTimeout = 5 * time.Second
+
+// Harness wires a runtime server to a test router over a temporary Unix socket
+type Harness struct {
+	Transport *asyatesting.MockTransport
+
+	router    asyatesting.EnvelopeProcessor
+	socketDir string
+	cancel    context.CancelFunc
+	done      chan error
+}
+
+// New starts a payload mode handler behind a test router
+func New(handler runtime.Handler) (*Harness, error) {
+	return start(func(cfg runtime.Config) *runtime.Server {
+		return runtime.NewServer(cfg, handler)
+	})
+}
+
+// NewEnvelope starts an envelope mode handler behind a test router
+func NewEnvelope(handler runtime.EnvelopeHandler) (*Harness, error) {
+	return start(func(cfg runtime.Config) *runtime.Server {
+		return runtime.NewEnvelopeServer(cfg, handler)
+	})
+}
+
+func start(newServer func(runtime.Config) *runtime.Server) (*Harness, error) {
+	// Unix socket paths are limited to ~100 bytes, so avoid long test temp dirs
+	socketDir, err := os.MkdirTemp("", "asya-rt-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket dir: %w", err)
+	}
+
+	cfg := runtime.DefaultConfig()
+	cfg.SocketDir = socketDir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Harness{
+		Transport: asyatesting.NewMockTransport(),
+		socketDir: socketDir,
+		cancel:    cancel,
+		done:      make(chan error, 1),
+	}
+
+	server := newServer(cfg)
+	go func() {
+		h.done <- server.Serve(ctx)
+	}()
+
+	if err := waitForReady(cfg.ReadyFilePath(), h.done); err != nil {
+		cancel()
+		_ = os.RemoveAll(socketDir)
+		return nil, err
+	}
+
+	h.router = asyatesting.NewTestRouter(cfg.SocketPath(), readyTimeout, h.Transport)
+	return h, nil
+}
+
+func waitForReady(readyFile string, done <-chan error) error {
+	deadline := time.Now().Add(readyTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			return fmt.Errorf("runtime server exited before ready: %w", err)
+		default:
+		}
+		if _, err := os.Stat(readyFile); err == nil {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("runtime not ready after %v", readyTimeout)
+}
+
+// Process sends an envelope through the router and runtime.
+// Results are available via Messages on the next actor's queue or the end queues.
+func (h *Harness) Process(ctx context.Context, envelope envelopes.Envelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return h.router.ProcessEnvelope(ctx, transport.QueueMessage{
+		ID:   envelope.ID,
+		Body: body,
+	})
+}
+
+// ProcessPayload wraps payload in an envelope routed through the test actor and the given next actors
+func (h *Harness) ProcessPayload(ctx context.Context, id string, payload any, nextActors ...string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return h.Process(ctx, envelopes.Envelope{
+		ID: id,
+		Route: envelopes.Route{
+			Actors:  append([]string{ActorName}, nextActors...),
+			Current: 0,
+		},
+		Payload: data,
+	})
+}
+
+// Messages returns the envelopes sent to a queue, decoded
+func (h *Harness) Messages(queueName string) ([]envelopes.Envelope, error) {
+	queued := h.Transport.GetMessages(queueName)
+	result := make([]envelopes.Envelope, 0, len(queued))
+	for _, msg := range queued {
+		var envelope envelopes.Envelope
+		if err := json.Unmarshal(msg.Body, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode message %s: %w", msg.ID, err)
+		}
+		result = append(result, envelope)
+	}
+	return result, nil
+}
+
+// Close stops the runtime server and removes the socket directory
+func (h *Harness) Close() error {
+	h.cancel()
+	err := <-h.done
+	_ = os.RemoveAll(h.socketDir)
+	return err
+}
+
+// SocketPath returns the path of the runtime socket, useful for driving the server directly
+func (h *Harness) SocketPath() string {
+	return filepath.Join(h.socketDir, runtime.DefaultConfig().SocketName)
+}
@@ -0,0 +1,125 @@
+package runtimetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+func TestHarness_PayloadHandler(t *testing.T) {
+	h, err := New(func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		var in struct {
+			Values []int `json:"values"`
+		}
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		if len(in.Values) == 0 {
+			return nil, errors.New("no values")
+		}
+		out := make([]any, 0, len(in.Values))
+		for _, v := range in.Values {
+			out = append(out, map[string]int{"value": v * 2})
+		}
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to start harness: %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	ctx := context.Background()
+
+	t.Run("fan-out to next actor", func(t *testing.T) {
+		h.Transport.ClearAll()
+		if err := h.ProcessPayload(ctx, "env-1", map[string]any{"values": []int{1, 2}}, "next"); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+
+		msgs, err := h.Messages("next")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != 2 {
+			t.Fatalf("Got %d messages on next, want 2", len(msgs))
+		}
+		if msgs[0].ID != "env-1" || msgs[1].ID != "env-1-1" {
+			t.Errorf("IDs = %q, %q, want env-1, env-1-1", msgs[0].ID, msgs[1].ID)
+		}
+		if string(msgs[1].Payload) != `{"value":4}` {
+			t.Errorf("Payload = %s, want {\"value\":4}", msgs[1].Payload)
+		}
+		if msgs[0].Route.Current != 1 {
+			t.Errorf("Route.Current = %d, want 1", msgs[0].Route.Current)
+		}
+	})
+
+	t.Run("last actor goes to happy-end", func(t *testing.T) {
+		h.Transport.ClearAll()
+		if err := h.ProcessPayload(ctx, "env-2", map[string]any{"values": []int{3}}); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if got := h.Transport.GetMessageCount(HappyEndQueue); got != 1 {
+			t.Errorf("Got %d messages on happy-end, want 1", got)
+		}
+	})
+
+	t.Run("handler error goes to error-end", func(t *testing.T) {
+		h.Transport.ClearAll()
+		if err := h.ProcessPayload(ctx, "env-3", map[string]any{"values": []int{}}, "next"); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+
+		msgs := h.Transport.GetMessages(ErrorEndQueue)
+		if len(msgs) != 1 {
+			t.Fatalf("Got %d messages on error-end, want 1", len(msgs))
+		}
+		var errEnvelope struct {
+			Payload struct {
+				Error   string `json:"error"`
+				Details struct {
+					Message string `json:"message"`
+				} `json:"details"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(msgs[0].Body, &errEnvelope); err != nil {
+			t.Fatal(err)
+		}
+		if errEnvelope.Payload.Error != "processing_error" {
+			t.Errorf("Error = %q, want processing_error", errEnvelope.Payload.Error)
+		}
+		if errEnvelope.Payload.Details.Message != "no values" {
+			t.Errorf("Details.Message = %q, want %q", errEnvelope.Payload.Details.Message, "no values")
+		}
+	})
+}
+
+func TestHarness_EnvelopeHandler(t *testing.T) {
+	h, err := NewEnvelope(func(ctx context.Context, e *envelopes.Envelope) ([]*envelopes.Envelope, error) {
+		e.Route.Actors = append(e.Route.Actors, "reviewer")
+		e.Route.Current++
+		return []*envelopes.Envelope{e}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to start harness: %v", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	if err := h.ProcessPayload(context.Background(), "env-1", map[string]string{"doc": "x"}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	msgs, err := h.Messages("reviewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Got %d messages on reviewer, want 1", len(msgs))
+	}
+	if len(msgs[0].Route.Actors) != 2 || msgs[0].Route.Current != 1 {
+		t.Errorf("Route = %+v, want actors [test-actor reviewer] current 1", msgs[0].Route)
+	}
+}
@@ -0,0 +1,380 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+// Server accepts sidecar connections on a Unix socket and dispatches them to a handler
+type Server struct {
+	cfg             Config
+	handler         Handler
+	envelopeHandler EnvelopeHandler
+}
+
+// NewServer creates a runtime server for a payload mode handler
+func NewServer(cfg Config, handler Handler) *Server {
+	return &Server{cfg: cfg, handler: handler}
+}
+
+// NewEnvelopeServer creates a runtime server for an envelope mode handler
+func NewEnvelopeServer(cfg Config, handler EnvelopeHandler) *Server {
+	return &Server{cfg: cfg, envelopeHandler: handler}
+}
+
+// Run serves a payload mode handler using configuration from the environment until SIGTERM or SIGINT
+func Run(handler Handler) error {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return runWithSignals(NewServer(cfg, handler))
+}
+
+// RunEnvelope serves an envelope mode handler using configuration from the environment until SIGTERM or SIGINT
+func RunEnvelope(handler EnvelopeHandler) error {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return runWithSignals(NewEnvelopeServer(cfg, handler))
+}
+
+func runWithSignals(s *Server) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	return s.Serve(ctx)
+}
+
+// Serve listens on the runtime socket, signals readiness and handles requests until ctx is cancelled.
+// Connections are handled one at a time, matching the Python runtime.
+func (s *Server) Serve(ctx context.Context) error {
+	if s.handler == nil && s.envelopeHandler == nil {
+		return errors.New("runtime handler is not set")
+	}
+
+	socketPath := s.cfg.SocketPath()
+	readyFile := s.cfg.ReadyFilePath()
+
+	if err := os.MkdirAll(s.cfg.SocketDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	defer func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+		_ = os.Remove(readyFile)
+	}()
+
+	if s.cfg.SocketChmod != 0 {
+		if err := os.Chmod(socketPath, s.cfg.SocketChmod); err != nil {
+			return fmt.Errorf("failed to chmod socket: %w", err)
+		}
+	}
+
+	// Signal sidecar that runtime is ready to receive messages
+	if err := os.WriteFile(readyFile, []byte("ready"), 0o644); err != nil {
+		return fmt.Errorf("failed to create ready file %s: %w", readyFile, err)
+	}
+	slog.Info("Runtime listening", "socket", socketPath, "mode", s.mode(), "validation", s.cfg.EnableValidation)
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.Info("Runtime shutting down")
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		s.handleConn(ctx, conn)
+	}
+}
+
+func (s *Server) mode() string {
+	if s.envelopeHandler != nil {
+		return "envelope"
+	}
+	return "payload"
+}
+
+// handleConn reads one request frame, processes it and writes the response frame
+func (s *Server) handleConn(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var responses any
+	data, err := ReadFrame(conn)
+	if err != nil {
+		responses = newErrorResponse(ErrCodeConnection, err, "")
+	} else {
+		responses = s.HandleRequest(ctx, data)
+	}
+
+	body, err := json.Marshal(responses)
+	if err != nil {
+		slog.Error("Failed to marshal runtime response", "error", err)
+		body, _ = json.Marshal(newErrorResponse(ErrCodeProcessing, err, ""))
+	}
+
+	if err := WriteFrame(conn, body); err != nil {
+		slog.Warn("Failed to send response to sidecar", "error", err)
+	}
+}
+
+// HandleRequest processes a raw envelope and returns the value to send back to the sidecar:
+// either a list of output envelopes or a single-element error list, followed by a
+// metrics-only item when the handler called EmitMetric.
+func (s *Server) HandleRequest(ctx context.Context, data []byte) any {
+	envelope, err := s.parseEnvelope(data)
+	if err != nil {
+		return newErrorResponse(ErrCodeParsing, err, "")
+	}
+
+	var (
+		out       []outputEnvelope
+		stack     string
+		handleErr error
+	)
+	collector := &metricsCollector{}
+	ctx = context.WithValue(ctx, metricsKey{}, collector)
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				handleErr = fmt.Errorf("handler panic: %v", p)
+				stack = string(debug.Stack())
+			}
+		}()
+		if s.envelopeHandler != nil {
+			out, handleErr = s.callEnvelopeHandler(ctx, envelope)
+		} else {
+			out, handleErr = s.callPayloadHandler(ctx, envelope)
+		}
+	}()
+
+	if handleErr != nil {
+		slog.Error("Handler failed", "id", envelope.ID, "error", handleErr)
+		return withMetrics(newErrorResponse(ErrCodeProcessing, handleErr, stack), collector)
+	}
+
+	return withMetrics(out, collector)
+}
+
+// parseEnvelope decodes the incoming envelope and validates it when enabled
+func (s *Server) parseEnvelope(data []byte) (*envelopes.Envelope, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid envelope JSON: %w", err)
+	}
+
+	var envelope envelopes.Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid envelope JSON: %w", err)
+	}
+
+	if s.cfg.EnableValidation {
+		if _, ok := raw["payload"]; !ok {
+			return nil, errors.New("missing required field 'payload' in envelope")
+		}
+		if _, ok := raw["route"]; !ok {
+			return nil, errors.New("missing required field 'route' in envelope")
+		}
+		if err := validateRoute(envelope.Route); err != nil {
+			return nil, err
+		}
+	}
+
+	return &envelope, nil
+}
+
+// callPayloadHandler calls the payload handler and wraps results with the incremented route
+func (s *Server) callPayloadHandler(ctx context.Context, envelope *envelopes.Envelope) ([]outputEnvelope, error) {
+	results, err := s.handler(ctx, envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Runtime handles routing in payload mode
+	outputRoute := envelope.Route
+	outputRoute.Actors = append([]string(nil), envelope.Route.Actors...)
+	outputRoute.Current = envelope.Route.Current + 1
+
+	out := make([]outputEnvelope, 0, len(results))
+	for i, result := range results {
+		payload, err := marshalPayload(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result[%d/%d]: %w", i, len(results), err)
+		}
+		out = append(out, outputEnvelope{
+			Route:   outputRoute,
+			Headers: envelope.Headers,
+			Payload: payload,
+		})
+	}
+	return out, nil
+}
+
+// callEnvelopeHandler calls the envelope handler and validates the returned envelopes
+func (s *Server) callEnvelopeHandler(ctx context.Context, envelope *envelopes.Envelope) ([]outputEnvelope, error) {
+	inputRoute := envelope.Route
+	inputRoute.Actors = append([]string(nil), envelope.Route.Actors...)
+	currentActor := inputRoute.GetCurrentActor()
+
+	results, err := s.envelopeHandler(ctx, envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]outputEnvelope, 0, len(results))
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("invalid output envelope[%d/%d]: envelope is nil", i, len(results))
+		}
+		if s.cfg.EnableValidation {
+			if err := validateOutputRoute(result.Route, inputRoute, currentActor); err != nil {
+				return nil, fmt.Errorf("invalid output envelope[%d/%d]: %w", i, len(results), err)
+			}
+			if err := validateCall(result.Call); err != nil {
+				return nil, fmt.Errorf("invalid output envelope[%d/%d]: %w", i, len(results), err)
+			}
+		}
+		payload := result.Payload
+		if payload == nil {
+			payload = json.RawMessage("null")
+		}
+		out = append(out, outputEnvelope{
+			ID:       result.ID,
+			ParentID: result.ParentID,
+			Route:    result.Route,
+			Headers:  result.Headers,
+			Payload:  payload,
+			Call:     result.Call,
+		})
+	}
+	return out, nil
+}
+
+// validateRoute checks route structure; current may equal len(actors) to signal end-of-route
+func validateRoute(route envelopes.Route) error {
+	if len(route.Actors) == 0 {
+		return errors.New("field 'route.actors' cannot be empty")
+	}
+	if route.Current < 0 || route.Current > len(route.Actors) {
+		return fmt.Errorf("invalid route.current=%d: out of bounds for actors of length %d", route.Current, len(route.Actors))
+	}
+	return nil
+}
+
+// validateCall checks that a sub-route call names a route or lists actors, but not both
+func validateCall(call *envelopes.Call) error {
+	if call == nil {
+		return nil
+	}
+	if (call.Name == "") == (len(call.Actors) == 0) {
+		return errors.New("field 'call' must set exactly one of 'name' and 'actors'")
+	}
+	return nil
+}
+
+// validateOutputRoute checks that a handler kept every already-processed actor in place.
+// Handlers can add future actors but cannot remove or replace actors up to the input's current.
+func validateOutputRoute(output, input envelopes.Route, currentActor string) error {
+	if err := validateRoute(output); err != nil {
+		return err
+	}
+
+	processed := input.Actors
+	if input.Current+1 < len(processed) {
+		processed = processed[:input.Current+1]
+	}
+	for i, actor := range processed {
+		if i >= len(output.Actors) || output.Actors[i] != actor {
+			if i == input.Current && i < len(output.Actors) {
+				return fmt.Errorf("route mismatch: input route points to '%s' at position %d, but output route has '%s' at that position",
+					currentActor, i, output.Actors[i])
+			}
+			return fmt.Errorf("route modification error: already-processed actors cannot be erased: input route had %v (actors 0-%d), but output route is %v",
+				processed, input.Current, output.Actors)
+		}
+	}
+	return nil
+}
+
+// marshalPayload encodes a handler result, passing raw JSON through unchanged
+func marshalPayload(result any) (json.RawMessage, error) {
+	switch v := result.(type) {
+	case json.RawMessage:
+		if v == nil {
+			return json.RawMessage("null"), nil
+		}
+		return v, nil
+	case []byte:
+		if !json.Valid(v) {
+			return nil, errors.New("result bytes are not valid JSON")
+		}
+		return v, nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// newErrorResponse builds the standard error reply with details taken from err
+func newErrorResponse(code string, err error, stack string) []errorResponse {
+	resp := errorResponse{Error: code}
+	if err != nil {
+		traceback := stack
+		if traceback == "" {
+			traceback = errorChain(err)
+		}
+		resp.Details = &ErrorDetails{
+			Message:   err.Error(),
+			Type:      errorType(err),
+			Traceback: traceback,
+		}
+	}
+	return []errorResponse{resp}
+}
+
+// errorType returns the Go type name of err without package or pointer qualifiers
+func errorType(err error) string {
+	t := reflect.TypeOf(err)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// errorChain renders the wrapped error chain, one error per line
+func errorChain(err error) string {
+	var lines []string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		lines = append(lines, fmt.Sprintf("%s: %s", errorType(e), e.Error()))
+	}
+	return strings.Join(lines, "\n")
+}
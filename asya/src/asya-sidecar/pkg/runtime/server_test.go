@@ -0,0 +1,340 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+)
+
+type testResponse struct {
+	Payload json.RawMessage        `json:"payload"`
+	Route   envelopes.Route        `json:"route"`
+	Headers map[string]interface{} `json:"headers"`
+	Error   string                 `json:"error"`
+	Details ErrorDetails           `json:"details"`
+	Metrics []MetricObservation    `json:"metrics"`
+	Call    *envelopes.Call        `json:"call"`
+}
+
+func handle(t *testing.T, s *Server, input string) []testResponse {
+	t.Helper()
+	data, err := json.Marshal(s.HandleRequest(context.Background(), []byte(input)))
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	var responses []testResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		t.Fatalf("Failed to parse response %s: %v", data, err)
+	}
+	return responses
+}
+
+func TestFraming_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+	if got := buf.Bytes()[:4]; !bytes.Equal(got, []byte{0, 0, 0, 7}) {
+		t.Errorf("Length prefix = %v, want [0 0 0 7]", got)
+	}
+
+	data, err := ReadFrame(&buf)
+	if err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+	if string(data) != `{"a":1}` {
+		t.Errorf("ReadFrame = %s, want {\"a\":1}", data)
+	}
+
+	if _, err := ReadFrame(bytes.NewReader([]byte{0, 0, 0, 5, 'x'})); err == nil {
+		t.Error("Expected error for truncated frame")
+	}
+}
+
+func TestHandleRequest_PayloadMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []any
+		wantCount   int
+		wantPayload string
+	}{
+		{"single result", []any{map[string]int{"value": 2}}, 1, `{"value":2}`},
+		{"raw json result", []any{json.RawMessage(`{"raw":true}`)}, 1, `{"raw":true}`},
+		{"fan-out", []any{1, 2, 3}, 3, `1`},
+		{"no results", nil, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(DefaultConfig(), func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+				return tt.results, nil
+			})
+
+			responses := handle(t, s, `{"id":"e1","route":{"actors":["a","b"],"current":0},"headers":{"trace":"x"},"payload":{"value":1}}`)
+			if len(responses) != tt.wantCount {
+				t.Fatalf("Got %d responses, want %d", len(responses), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if string(responses[0].Payload) != tt.wantPayload {
+				t.Errorf("Payload = %s, want %s", responses[0].Payload, tt.wantPayload)
+			}
+			for _, r := range responses {
+				if r.Route.Current != 1 {
+					t.Errorf("Route.Current = %d, want 1", r.Route.Current)
+				}
+				if r.Headers["trace"] != "x" {
+					t.Errorf("Headers = %v, want trace=x", r.Headers)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRequest_Errors(t *testing.T) {
+	failing := NewServer(DefaultConfig(), func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		return nil, errors.New("model unavailable")
+	})
+	panicking := NewServer(DefaultConfig(), func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		panic("boom")
+	})
+	valid := `{"id":"e1","route":{"actors":["a"],"current":0},"payload":{}}`
+
+	tests := []struct {
+		name          string
+		server        *Server
+		input         string
+		wantCode      string
+		wantMessage   string
+		wantTraceback string
+	}{
+		{"handler error", failing, valid, ErrCodeProcessing, "model unavailable", "errorString: model unavailable"},
+		{"handler panic", panicking, valid, ErrCodeProcessing, "handler panic: boom", "goroutine"},
+		{"invalid json", failing, `not json`, ErrCodeParsing, "invalid envelope JSON", ""},
+		{"missing payload", failing, `{"route":{"actors":["a"]}}`, ErrCodeParsing, "missing required field 'payload'", ""},
+		{"missing route", failing, `{"payload":{}}`, ErrCodeParsing, "missing required field 'route'", ""},
+		{"empty actors", failing, `{"route":{"actors":[]},"payload":{}}`, ErrCodeParsing, "cannot be empty", ""},
+		{"current out of bounds", failing, `{"route":{"actors":["a"],"current":2},"payload":{}}`, ErrCodeParsing, "out of bounds", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := handle(t, tt.server, tt.input)
+			if len(responses) != 1 {
+				t.Fatalf("Got %d responses, want 1", len(responses))
+			}
+			if responses[0].Error != tt.wantCode {
+				t.Errorf("Error = %q, want %q", responses[0].Error, tt.wantCode)
+			}
+			if !strings.Contains(responses[0].Details.Message, tt.wantMessage) {
+				t.Errorf("Details.Message = %q, want to contain %q", responses[0].Details.Message, tt.wantMessage)
+			}
+			if responses[0].Details.Type == "" {
+				t.Error("Details.Type should be set")
+			}
+			if !strings.Contains(responses[0].Details.Traceback, tt.wantTraceback) {
+				t.Errorf("Details.Traceback = %q, want to contain %q", responses[0].Details.Traceback, tt.wantTraceback)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_Metrics(t *testing.T) {
+	emitting := NewServer(DefaultConfig(), func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		EmitMetric(ctx, "tokens_total", 12, map[string]string{"model": "gpt"})
+		return []any{map[string]any{"ok": true}}, nil
+	})
+	failing := NewServer(DefaultConfig(), func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		EmitMetric(ctx, "failures_total", 1, nil)
+		return nil, errors.New("model unavailable")
+	})
+	valid := `{"id":"e1","route":{"actors":["a","b"],"current":0},"payload":{}}`
+
+	responses := handle(t, emitting, valid)
+	if len(responses) != 2 {
+		t.Fatalf("Got %d responses, want output and metrics", len(responses))
+	}
+	if string(responses[0].Payload) != `{"ok":true}` || len(responses[0].Metrics) != 0 {
+		t.Errorf("First response = %+v, want output envelope", responses[0])
+	}
+	metrics := responses[1].Metrics
+	if len(metrics) != 1 || metrics[0].Name != "tokens_total" || metrics[0].Value != 12 || metrics[0].Labels["model"] != "gpt" {
+		t.Errorf("Metrics = %+v", metrics)
+	}
+	if responses[1].Payload != nil || len(responses[1].Route.Actors) != 0 {
+		t.Errorf("Metrics response should carry no payload or route: %+v", responses[1])
+	}
+
+	responses = handle(t, failing, valid)
+	if len(responses) != 2 || responses[0].Error != ErrCodeProcessing || len(responses[1].Metrics) != 1 {
+		t.Errorf("Responses = %+v, want error followed by metrics", responses)
+	}
+
+	// Outside a request EmitMetric is a no-op
+	EmitMetric(context.Background(), "tokens_total", 1, nil)
+}
+
+func TestHandleRequest_EnvelopeMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		actors    []string
+		current   int
+		wantError string
+	}{
+		{"advance route", []string{"a", "b"}, 1, ""},
+		{"append actors", []string{"a", "b", "c"}, 1, ""},
+		{"erase processed actor", []string{"b"}, 0, "route mismatch"},
+		{"replace current actor", []string{"x", "b"}, 1, "route mismatch"},
+		{"truncate route", []string{}, 0, "cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewEnvelopeServer(DefaultConfig(), func(ctx context.Context, e *envelopes.Envelope) ([]*envelopes.Envelope, error) {
+				e.Route.Actors = tt.actors
+				e.Route.Current = tt.current
+				return []*envelopes.Envelope{e}, nil
+			})
+
+			responses := handle(t, s, `{"id":"e1","route":{"actors":["a","b"],"current":0},"payload":{"v":1}}`)
+			if len(responses) != 1 {
+				t.Fatalf("Got %d responses, want 1", len(responses))
+			}
+			if tt.wantError == "" {
+				if responses[0].Error != "" {
+					t.Fatalf("Unexpected error: %+v", responses[0])
+				}
+				if responses[0].Route.Current != tt.current {
+					t.Errorf("Route.Current = %d, want %d", responses[0].Route.Current, tt.current)
+				}
+				return
+			}
+			if responses[0].Error != ErrCodeProcessing {
+				t.Fatalf("Error = %q, want %q", responses[0].Error, ErrCodeProcessing)
+			}
+			if !strings.Contains(responses[0].Details.Message, tt.wantError) {
+				t.Errorf("Details.Message = %q, want to contain %q", responses[0].Details.Message, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_EnvelopeModeCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      *envelopes.Call
+		wantError string
+	}{
+		{name: "no call"},
+		{name: "call by actors", call: &envelopes.Call{Actors: []string{"ocr", "translate"}}},
+		{name: "call by name", call: &envelopes.Call{Name: "ocr"}},
+		{name: "empty call", call: &envelopes.Call{}, wantError: "exactly one of 'name' and 'actors'"},
+		{name: "name and actors", call: &envelopes.Call{Name: "ocr", Actors: []string{"ocr"}}, wantError: "exactly one of 'name' and 'actors'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewEnvelopeServer(DefaultConfig(), func(ctx context.Context, e *envelopes.Envelope) ([]*envelopes.Envelope, error) {
+				e.Route.Current++
+				e.Call = tt.call
+				return []*envelopes.Envelope{e}, nil
+			})
+
+			responses := handle(t, s, `{"id":"e1","route":{"actors":["a","b"],"current":0},"payload":{"v":1}}`)
+			if len(responses) != 1 {
+				t.Fatalf("Got %d responses, want 1", len(responses))
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(responses[0].Details.Message, tt.wantError) {
+					t.Errorf("Details.Message = %q, want to contain %q", responses[0].Details.Message, tt.wantError)
+				}
+				return
+			}
+			if responses[0].Error != "" {
+				t.Fatalf("Unexpected error: %+v", responses[0])
+			}
+			if !reflect.DeepEqual(responses[0].Call, tt.call) {
+				t.Errorf("Call = %+v, want %+v", responses[0].Call, tt.call)
+			}
+		})
+	}
+}
+
+func TestServe_ReadyFileAndCleanup(t *testing.T) {
+	dir, err := os.MkdirTemp("", "asya-rt-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cfg := DefaultConfig()
+	cfg.SocketDir = dir
+	s := NewServer(cfg, func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		return []any{payload}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx) }()
+
+	readyFile := filepath.Join(dir, "runtime-ready")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if data, err := os.ReadFile(readyFile); err == nil {
+			if string(data) != "ready" {
+				t.Errorf("Ready file content = %q, want %q", data, "ready")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Ready file was not created")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+	if _, err := os.Stat(readyFile); !os.IsNotExist(err) {
+		t.Error("Ready file should be removed on shutdown")
+	}
+	if _, err := os.Stat(cfg.SocketPath()); !os.IsNotExist(err) {
+		t.Error("Socket should be removed on shutdown")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ASYA_SOCKET_DIR", "/tmp/asya-test")
+	t.Setenv("ASYA_SOCKET_CHMOD", "0o660")
+	t.Setenv("ASYA_ENABLE_VALIDATION", "false")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if cfg.SocketPath() != "/tmp/asya-test/asya-runtime.sock" {
+		t.Errorf("SocketPath = %q", cfg.SocketPath())
+	}
+	if cfg.SocketChmod != 0o660 {
+		t.Errorf("SocketChmod = %o, want 660", cfg.SocketChmod)
+	}
+	if cfg.EnableValidation {
+		t.Error("EnableValidation should be false")
+	}
+
+	t.Setenv("ASYA_SOCKET_CHMOD", "rw")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("Expected error for invalid ASYA_SOCKET_CHMOD")
+	}
+}
@@ -0,0 +1,400 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvelopeStatus mirrors the gateway envelope status values
+type EnvelopeStatus string
+
+const (
+	EnvelopeStatusPending   EnvelopeStatus = "pending"
+	EnvelopeStatusRunning   EnvelopeStatus = "running"
+	EnvelopeStatusSucceeded EnvelopeStatus = "succeeded"
+	EnvelopeStatusFailed    EnvelopeStatus = "failed"
+)
+
+// GatewayEnvelope is the envelope state tracked by the in-memory gateway
+type GatewayEnvelope struct {
+	ID               string
+	ParentID         string
+	Status           EnvelopeStatus
+	Actors           []string
+	CurrentActorIdx  int
+	CurrentActorName string
+	ProgressPercent  float64
+	Message          string
+	Result           any
+	Error            string
+}
+
+// EnvelopeUpdate mirrors the update events the gateway streams over SSE
+type EnvelopeUpdate struct {
+	ID              string         `json:"id"`
+	Status          EnvelopeStatus `json:"status"`
+	Message         string         `json:"message,omitempty"`
+	Result          any            `json:"result,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	ProgressPercent *float64       `json:"progress_percent,omitempty"`
+	Actor           string         `json:"actor,omitempty"`
+	Actors          []string       `json:"actors,omitempty"`
+	CurrentActorIdx *int           `json:"current_actor_idx,omitempty"`
+	EnvelopeState   *string        `json:"envelope_state,omitempty"`
+	Timestamp       time.Time      `json:"timestamp"`
+}
+
+// Gateway is an in-memory stand-in for asya-gateway that serves the endpoints used by sidecars
+// (/health, POST /envelopes, /envelopes/{id}/progress, /envelopes/progress/batch, /envelopes/{id}/final) and records every
+// update in the order the real gateway would stream it.
+type Gateway struct {
+	mu        sync.RWMutex
+	envelopes map[string]*GatewayEnvelope
+	updates   map[string][]EnvelopeUpdate
+	server    *httptest.Server
+}
+
+// NewGateway starts an in-memory gateway on a local HTTP port
+func NewGateway() *Gateway {
+	g := &Gateway{
+		envelopes: make(map[string]*GatewayEnvelope),
+		updates:   make(map[string][]EnvelopeUpdate),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/envelopes", g.handleCreate)
+	mux.HandleFunc("/envelopes/progress/batch", g.handleProgressBatch)
+	mux.HandleFunc("/envelopes/", g.handleEnvelope)
+	g.server = httptest.NewServer(mux)
+
+	return g
+}
+
+// URL returns the base URL to configure as the sidecar gateway URL
+func (g *Gateway) URL() string {
+	return g.server.URL
+}
+
+// Close stops the HTTP server
+func (g *Gateway) Close() {
+	g.server.Close()
+}
+
+// Create registers a new pending envelope, as the gateway does when a tool is called
+func (g *Gateway) Create(id, parentID string, actors []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.envelopes[id]; exists {
+		return fmt.Errorf("envelope %s already exists", id)
+	}
+	g.envelopes[id] = &GatewayEnvelope{
+		ID:       id,
+		ParentID: parentID,
+		Status:   EnvelopeStatusPending,
+		Actors:   append([]string(nil), actors...),
+	}
+	return nil
+}
+
+// Get returns a copy of the envelope state
+func (g *Gateway) Get(id string) (GatewayEnvelope, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	envelope, exists := g.envelopes[id]
+	if !exists {
+		return GatewayEnvelope{}, false
+	}
+	return *envelope, true
+}
+
+// Updates returns the recorded update sequence for an envelope
+func (g *Gateway) Updates(id string) []EnvelopeUpdate {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result := make([]EnvelopeUpdate, len(g.updates[id]))
+	copy(result, g.updates[id])
+	return result
+}
+
+// EnvelopeStates returns the update sequence in compact "actor:state" form
+// (e.g. "parse:received", "parse:processing", "parse:completed", "succeeded"),
+// which is convenient for asserting the SSE stream a client would observe.
+func (g *Gateway) EnvelopeStates(id string) []string {
+	updates := g.Updates(id)
+	states := make([]string, 0, len(updates))
+	for _, u := range updates {
+		if u.EnvelopeState == nil {
+			states = append(states, string(u.Status))
+			continue
+		}
+		actor := ""
+		if u.CurrentActorIdx != nil {
+			idx := *u.CurrentActorIdx
+			// Completed updates carry the route already advanced past the actor that finished
+			if *u.EnvelopeState == "completed" {
+				idx--
+			}
+			if idx >= 0 && idx < len(u.Actors) {
+				actor = u.Actors[idx]
+			}
+		}
+		states = append(states, fmt.Sprintf("%s:%s", actor, *u.EnvelopeState))
+	}
+	return states
+}
+
+// handleCreate handles POST /envelopes for fanout children.
+// The sidecar routes the child itself, so unlike the real gateway nothing is enqueued here.
+func (g *Gateway) handleCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID       string   `json:"id"`
+		ParentID string   `json:"parent_id"`
+		Actors   []string `json:"actors"`
+		Current  int      `json:"current"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := g.Create(req.ID, req.ParentID, req.Actors); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	g.apply(EnvelopeUpdate{
+		ID:        req.ID,
+		Status:    EnvelopeStatusRunning,
+		Message:   "Sending envelope to first actor",
+		Timestamp: time.Now(),
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "id": req.ID})
+}
+
+// handleEnvelope dispatches /envelopes/{id}[/progress|/final]
+func (g *Gateway) handleEnvelope(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/envelopes/")
+	switch {
+	case strings.HasSuffix(path, "/progress"):
+		g.handleProgress(w, r, strings.TrimSuffix(path, "/progress"))
+	case strings.HasSuffix(path, "/final"):
+		g.handleFinal(w, r, strings.TrimSuffix(path, "/final"))
+	default:
+		envelope, ok := g.Get(path)
+		if !ok {
+			http.Error(w, "Envelope not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(envelope)
+	}
+}
+
+// progressUpdate is the progress payload sent by sidecars
+type progressUpdate struct {
+	ID              string   `json:"id,omitempty"` // Set in batch requests only
+	Actors          []string `json:"actors"`
+	CurrentActorIdx int      `json:"current_actor_idx"`
+	Status          string   `json:"status"`
+	Message         string   `json:"message"`
+}
+
+// handleProgress handles POST /envelopes/{id}/progress
+func (g *Gateway) handleProgress(w http.ResponseWriter, r *http.Request, id string) {
+	var progress progressUpdate
+	if err := json.NewDecoder(r.Body).Decode(&progress); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	percent, ok := g.applyProgress(id, progress)
+	if !ok {
+		http.Error(w, "Envelope not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "progress_percent": percent})
+}
+
+// handleProgressBatch handles POST /envelopes/progress/batch from asynchronous reporters.
+// Updates are applied in order; unknown envelopes are reported per update.
+func (g *Gateway) handleProgressBatch(w http.ResponseWriter, r *http.Request) {
+	var batch struct {
+		Updates []progressUpdate `json:"updates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]map[string]interface{}, 0, len(batch.Updates))
+	for _, progress := range batch.Updates {
+		result := map[string]interface{}{"id": progress.ID}
+		if percent, ok := g.applyProgress(progress.ID, progress); ok {
+			result["progress_percent"] = percent
+		} else {
+			result["error"] = "envelope not found"
+		}
+		results = append(results, result)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "results": results})
+}
+
+// applyProgress applies the gateway's progress formula: (idx*100 + weight) / total,
+// monotonic and capped at 100. Updates for finished envelopes are ignored.
+func (g *Gateway) applyProgress(id string, progress progressUpdate) (float64, bool) {
+	envelope, ok := g.Get(id)
+	if !ok {
+		return 0, false
+	}
+	if envelope.Status == EnvelopeStatusSucceeded || envelope.Status == EnvelopeStatusFailed {
+		return envelope.ProgressPercent, true
+	}
+
+	weights := map[string]float64{"received": 10, "processing": 50, "completed": 100}
+
+	actors := envelope.Actors
+	if len(progress.Actors) > len(actors) {
+		actors = progress.Actors
+	}
+
+	percent := envelope.ProgressPercent
+	if len(actors) > 0 {
+		newPercent := (float64(progress.CurrentActorIdx)*100 + weights[progress.Status]) / float64(len(actors))
+		if newPercent > percent {
+			percent = newPercent
+		}
+	}
+	if percent > 100 {
+		percent = 100
+	}
+
+	state := progress.Status
+	idx := progress.CurrentActorIdx
+	g.apply(EnvelopeUpdate{
+		ID:              id,
+		Status:          EnvelopeStatusRunning,
+		Message:         progress.Message,
+		ProgressPercent: &percent,
+		Actors:          actors,
+		CurrentActorIdx: &idx,
+		EnvelopeState:   &state,
+		Timestamp:       time.Now(),
+	})
+	return percent, true
+}
+
+// handleFinal records the terminal status reported by happy-end or error-end
+func (g *Gateway) handleFinal(w http.ResponseWriter, r *http.Request, id string) {
+	var final struct {
+		Status           string   `json:"status"`
+		Result           any      `json:"result"`
+		Error            string   `json:"error"`
+		ErrorDetails     any      `json:"error_details"`
+		Actors           []string `json:"actors"`
+		CurrentActorIdx  *int     `json:"current_actor_idx"`
+		CurrentActorName string   `json:"current_actor_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&final); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	status := EnvelopeStatus(final.Status)
+	if status != EnvelopeStatusSucceeded && status != EnvelopeStatusFailed {
+		http.Error(w, "Invalid status: must be 'succeeded' or 'failed'", http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.Get(id); !ok {
+		http.Error(w, "Envelope not found", http.StatusNotFound)
+		return
+	}
+
+	percent := 100.0
+	update := EnvelopeUpdate{
+		ID:              id,
+		Status:          status,
+		Result:          final.Result,
+		ProgressPercent: &percent,
+		Actor:           final.CurrentActorName,
+		Actors:          final.Actors,
+		CurrentActorIdx: final.CurrentActorIdx,
+		Timestamp:       time.Now(),
+	}
+	if status == EnvelopeStatusSucceeded {
+		update.Message = "Envelope completed successfully"
+	} else {
+		update.Error = final.Error
+		update.Message = fmt.Sprintf("Envelope failed: %s", final.Error)
+		if final.CurrentActorName != "" {
+			update.Message = fmt.Sprintf("Envelope failed at actor '%s': %s", final.CurrentActorName, final.Error)
+		}
+		if final.ErrorDetails != nil {
+			update.Result = map[string]interface{}{
+				"error":        final.Error,
+				"details":      final.ErrorDetails,
+				"failed_actor": final.CurrentActorName,
+			}
+		}
+	}
+	g.apply(update)
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// apply updates envelope state and appends the update to its history
+func (g *Gateway) apply(update EnvelopeUpdate) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	envelope, exists := g.envelopes[update.ID]
+	if !exists {
+		return
+	}
+
+	envelope.Status = update.Status
+	if update.Message != "" {
+		envelope.Message = update.Message
+	}
+	if update.Result != nil {
+		envelope.Result = update.Result
+	}
+	if update.Error != "" {
+		envelope.Error = update.Error
+	}
+	if update.ProgressPercent != nil {
+		envelope.ProgressPercent = *update.ProgressPercent
+	}
+	if len(update.Actors) > 0 {
+		envelope.Actors = update.Actors
+	}
+	if update.CurrentActorIdx != nil {
+		envelope.CurrentActorIdx = *update.CurrentActorIdx
+		if *update.CurrentActorIdx >= 0 && *update.CurrentActorIdx < len(envelope.Actors) {
+			envelope.CurrentActorName = envelope.Actors[*update.CurrentActorIdx]
+		}
+	}
+
+	g.updates[update.ID] = append(g.updates[update.ID], update)
+}
@@ -3,6 +3,7 @@ package testing
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/deliveryhero/asya/asya-sidecar/pkg/transport"
@@ -63,6 +64,34 @@ func (m *MockTransport) Send(ctx context.Context, queueName string, body []byte)
 	return nil
 }
 
+// Pop removes and returns the oldest message in a queue
+func (m *MockTransport) Pop(queueName string) (QueuedMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := m.messages[queueName]
+	if len(messages) == 0 {
+		return QueuedMessage{}, false
+	}
+	m.messages[queueName] = messages[1:]
+	return messages[0], true
+}
+
+// Queues returns the names of all queues that currently hold messages
+func (m *MockTransport) Queues() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for name, messages := range m.messages {
+		if len(messages) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Ack acknowledges a message (no-op for mock)
 func (m *MockTransport) Ack(ctx context.Context, msg transport.QueueMessage) error {
 	return nil
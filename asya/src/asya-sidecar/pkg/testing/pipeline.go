@@ -0,0 +1,258 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/envelopes"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/runtime"
+	"github.com/deliveryhero/asya/asya-sidecar/pkg/transport"
+)
+
+const (
+	happyEndActor = "happy-end"
+	errorEndActor = "error-end"
+
+	defaultPipelineTimeout = 30 * time.Second
+	runtimeReadyTimeout    = 5 * time.Second
+
+	// maxPipelineMessages bounds Run so routing loops fail the test instead of hanging
+	maxPipelineMessages = 10000
+)
+
+// Pipeline runs several actor routers, their runtimes, in-memory queues and an in-memory
+// gateway in one process, so a multi-actor route can be executed without a cluster.
+// happy-end and error-end actors are registered automatically and report final status
+// to the gateway like their deployed counterparts.
+type Pipeline struct {
+	Transport *MockTransport
+	Gateway   *Gateway
+
+	timeout  time.Duration
+	routers  map[string]EnvelopeProcessor
+	runtimes []*localRuntime
+}
+
+// localRuntime is a Go runtime server started by the pipeline
+type localRuntime struct {
+	dir    string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// NewPipeline starts an in-memory gateway and the end actors
+func NewPipeline() (*Pipeline, error) {
+	p := &Pipeline{
+		Transport: NewMockTransport(),
+		Gateway:   NewGateway(),
+		timeout:   defaultPipelineTimeout,
+		routers:   make(map[string]EnvelopeProcessor),
+	}
+
+	// End actors run in envelope mode with validation disabled and return nothing,
+	// so the sidecar reports the incoming payload as the result
+	endHandler := func(ctx context.Context, e *envelopes.Envelope) ([]*envelopes.Envelope, error) {
+		return nil, nil
+	}
+	for _, name := range []string{happyEndActor, errorEndActor} {
+		if err := p.addServer(name, true, func(cfg runtime.Config) *runtime.Server {
+			cfg.EnableValidation = false
+			return runtime.NewEnvelopeServer(cfg, endHandler)
+		}); err != nil {
+			p.Close()
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// AddActor registers a payload mode Go handler under the given actor name
+func (p *Pipeline) AddActor(name string, handler runtime.Handler) error {
+	return p.addServer(name, false, func(cfg runtime.Config) *runtime.Server {
+		return runtime.NewServer(cfg, handler)
+	})
+}
+
+// AddEnvelopeActor registers an envelope mode Go handler under the given actor name
+func (p *Pipeline) AddEnvelopeActor(name string, handler runtime.EnvelopeHandler) error {
+	return p.addServer(name, false, func(cfg runtime.Config) *runtime.Server {
+		return runtime.NewEnvelopeServer(cfg, handler)
+	})
+}
+
+// AddExternalActor registers an actor whose runtime is already listening on socketPath,
+// e.g. a Python asya_runtime.py started by the test
+func (p *Pipeline) AddExternalActor(name, socketPath string) error {
+	if _, exists := p.routers[name]; exists {
+		return fmt.Errorf("actor %s already registered", name)
+	}
+	p.routers[name] = p.newRouter(name, socketPath, false)
+	return nil
+}
+
+func (p *Pipeline) addServer(name string, isEndActor bool, newServer func(runtime.Config) *runtime.Server) error {
+	if _, exists := p.routers[name]; exists {
+		return fmt.Errorf("actor %s already registered", name)
+	}
+
+	// Unix socket paths are limited to ~100 bytes, so avoid long test temp dirs
+	dir, err := os.MkdirTemp("", "asya-pl-")
+	if err != nil {
+		return fmt.Errorf("failed to create socket dir for %s: %w", name, err)
+	}
+
+	cfg := runtime.DefaultConfig()
+	cfg.SocketDir = dir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := &localRuntime{dir: dir, cancel: cancel, done: make(chan error, 1)}
+	server := newServer(cfg)
+	go func() {
+		rt.done <- server.Serve(ctx)
+	}()
+
+	if err := waitForRuntimeReady(cfg.ReadyFilePath(), rt.done); err != nil {
+		cancel()
+		_ = os.RemoveAll(dir)
+		return fmt.Errorf("actor %s: %w", name, err)
+	}
+
+	p.runtimes = append(p.runtimes, rt)
+	p.routers[name] = p.newRouter(name, cfg.SocketPath(), isEndActor)
+	return nil
+}
+
+func (p *Pipeline) newRouter(name, socketPath string, isEndActor bool) EnvelopeProcessor {
+	return NewRouter(RouterConfig{
+		ActorName:  name,
+		SocketPath: socketPath,
+		Timeout:    p.timeout,
+		GatewayURL: p.Gateway.URL(),
+		IsEndActor: isEndActor,
+	}, p.Transport)
+}
+
+func waitForRuntimeReady(readyFile string, done <-chan error) error {
+	deadline := time.Now().Add(runtimeReadyTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			return fmt.Errorf("runtime exited before ready: %w", err)
+		default:
+		}
+		if _, err := os.Stat(readyFile); err == nil {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("runtime not ready after %v", runtimeReadyTimeout)
+}
+
+// Submit creates the envelope in the gateway and sends it to the first actor, as a tool call does
+func (p *Pipeline) Submit(ctx context.Context, id string, actors []string, payload any) error {
+	if len(actors) == 0 {
+		return fmt.Errorf("route for %s has no actors", id)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	body, err := json.Marshal(envelopes.Envelope{
+		ID:      id,
+		Route:   envelopes.Route{Actors: actors, Current: 0},
+		Payload: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	if err := p.Gateway.Create(id, "", actors); err != nil {
+		return err
+	}
+	p.Gateway.apply(EnvelopeUpdate{
+		ID:        id,
+		Status:    EnvelopeStatusRunning,
+		Message:   "Sending envelope to first actor",
+		Timestamp: time.Now(),
+	})
+
+	return p.Transport.Send(ctx, actors[0], body)
+}
+
+// Run delivers queued messages to their actors until every queue is drained.
+// It fails if a message is left on a queue with no registered actor or the route never settles.
+func (p *Pipeline) Run(ctx context.Context) error {
+	for processed := 0; ; {
+		progressed := false
+		for _, queue := range p.Transport.Queues() {
+			r, ok := p.routers[queue]
+			if !ok {
+				continue
+			}
+			msg, ok := p.Transport.Pop(queue)
+			if !ok {
+				continue
+			}
+			if processed >= maxPipelineMessages {
+				return fmt.Errorf("pipeline did not settle after %d messages", maxPipelineMessages)
+			}
+			processed++
+			progressed = true
+
+			if err := r.ProcessEnvelope(ctx, transport.QueueMessage{ID: msg.ID, Body: msg.Body}); err != nil {
+				return fmt.Errorf("actor %s failed to process message %s: %w", queue, msg.ID, err)
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	if undelivered := p.Transport.Queues(); len(undelivered) > 0 {
+		return fmt.Errorf("messages left on queues without a registered actor: %v", undelivered)
+	}
+	return nil
+}
+
+// Execute submits an envelope, runs the pipeline to completion and returns the final gateway state
+func (p *Pipeline) Execute(ctx context.Context, id string, actors []string, payload any) (GatewayEnvelope, error) {
+	if err := p.Submit(ctx, id, actors, payload); err != nil {
+		return GatewayEnvelope{}, err
+	}
+	if err := p.Run(ctx); err != nil {
+		return GatewayEnvelope{}, err
+	}
+	envelope, ok := p.Gateway.Get(id)
+	if !ok {
+		return GatewayEnvelope{}, fmt.Errorf("envelope %s not found in gateway", id)
+	}
+	return envelope, nil
+}
+
+// Actors returns the registered actor names
+func (p *Pipeline) Actors() []string {
+	names := make([]string, 0, len(p.routers))
+	for name := range p.routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close stops all runtimes started by the pipeline and the gateway
+func (p *Pipeline) Close() {
+	for _, rt := range p.runtimes {
+		rt.cancel()
+		<-rt.done
+		_ = os.RemoveAll(rt.dir)
+	}
+	p.runtimes = nil
+	p.Gateway.Close()
+}
@@ -0,0 +1,156 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestPipeline(t *testing.T) *Pipeline {
+	t.Helper()
+
+	p, err := NewPipeline()
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	t.Cleanup(p.Close)
+
+	if err := p.AddActor("upper", func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		var in map[string]string
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		if in["text"] == "" {
+			return nil, errors.New("empty text")
+		}
+		return []any{map[string]string{"text": strings.ToUpper(in["text"])}}, nil
+	}); err != nil {
+		t.Fatalf("Failed to add actor: %v", err)
+	}
+
+	if err := p.AddActor("split", func(ctx context.Context, payload json.RawMessage) ([]any, error) {
+		var in map[string]string
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, word := range strings.Fields(in["text"]) {
+			out = append(out, map[string]string{"text": word})
+		}
+		return out, nil
+	}); err != nil {
+		t.Fatalf("Failed to add actor: %v", err)
+	}
+
+	return p
+}
+
+func TestPipeline_HappyPath(t *testing.T) {
+	p := newTestPipeline(t)
+
+	envelope, err := p.Execute(context.Background(), "env-1", []string{"upper", "split"}, map[string]string{"text": "hello"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if envelope.Status != EnvelopeStatusSucceeded {
+		t.Fatalf("Status = %s, want %s (error: %s)", envelope.Status, EnvelopeStatusSucceeded, envelope.Error)
+	}
+	if envelope.ProgressPercent != 100 {
+		t.Errorf("ProgressPercent = %v, want 100", envelope.ProgressPercent)
+	}
+	if result, _ := envelope.Result.(map[string]interface{}); result["text"] != "HELLO" {
+		t.Errorf("Result = %v, want text=HELLO", envelope.Result)
+	}
+
+	wantStates := []string{
+		"running",
+		"upper:received", "upper:processing", "upper:completed",
+		"split:received", "split:processing", "split:completed",
+		"succeeded",
+	}
+	if got := p.Gateway.EnvelopeStates("env-1"); !reflect.DeepEqual(got, wantStates) {
+		t.Errorf("EnvelopeStates = %v, want %v", got, wantStates)
+	}
+
+	var last float64
+	for _, u := range p.Gateway.Updates("env-1") {
+		if u.ProgressPercent == nil {
+			continue
+		}
+		if *u.ProgressPercent < last {
+			t.Errorf("Progress decreased from %v to %v", last, *u.ProgressPercent)
+		}
+		last = *u.ProgressPercent
+	}
+}
+
+func TestPipeline_ErrorEnd(t *testing.T) {
+	p := newTestPipeline(t)
+
+	envelope, err := p.Execute(context.Background(), "env-err", []string{"split", "upper"}, map[string]string{"text": ""})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// split returns no results for empty text, so the envelope ends at happy-end
+	if envelope.Status != EnvelopeStatusSucceeded {
+		t.Fatalf("Status = %s, want %s", envelope.Status, EnvelopeStatusSucceeded)
+	}
+
+	envelope, err = p.Execute(context.Background(), "env-err-2", []string{"upper"}, map[string]string{"text": ""})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if envelope.Status != EnvelopeStatusFailed {
+		t.Fatalf("Status = %s, want %s", envelope.Status, EnvelopeStatusFailed)
+	}
+	if envelope.Error != "processing_error" {
+		t.Errorf("Error = %q, want processing_error", envelope.Error)
+	}
+	if envelope.CurrentActorName != "upper" {
+		t.Errorf("CurrentActorName = %q, want upper", envelope.CurrentActorName)
+	}
+
+	states := p.Gateway.EnvelopeStates("env-err-2")
+	if states[len(states)-1] != "failed" {
+		t.Errorf("Last state = %q, want failed (states: %v)", states[len(states)-1], states)
+	}
+}
+
+func TestPipeline_FanOut(t *testing.T) {
+	p := newTestPipeline(t)
+
+	envelope, err := p.Execute(context.Background(), "env-fan", []string{"split", "upper"}, map[string]string{"text": "a b c"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if envelope.Status != EnvelopeStatusSucceeded {
+		t.Fatalf("Status = %s, want %s", envelope.Status, EnvelopeStatusSucceeded)
+	}
+
+	for _, id := range []string{"env-fan-1", "env-fan-2"} {
+		child, ok := p.Gateway.Get(id)
+		if !ok {
+			t.Fatalf("Fanout child %s not created in gateway", id)
+		}
+		if child.ParentID != "env-fan" {
+			t.Errorf("%s ParentID = %q, want env-fan", id, child.ParentID)
+		}
+		if child.Status != EnvelopeStatusSucceeded {
+			t.Errorf("%s Status = %s, want %s", id, child.Status, EnvelopeStatusSucceeded)
+		}
+	}
+}
+
+func TestPipeline_UnregisteredActor(t *testing.T) {
+	p := newTestPipeline(t)
+
+	_, err := p.Execute(context.Background(), "env-lost", []string{"upper", "missing"}, map[string]string{"text": "x"})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("Expected error about unregistered actor, got %v", err)
+	}
+}
@@ -16,16 +16,36 @@ type EnvelopeProcessor interface {
 	ProcessEnvelope(ctx context.Context, msg transport.QueueMessage) error
 }
 
+// RouterConfig configures a router for an arbitrary actor
+type RouterConfig struct {
+	ActorName  string
+	SocketPath string
+	Timeout    time.Duration
+	GatewayURL string // Empty disables progress and final status reporting
+	IsEndActor bool
+}
+
 // NewTestRouter creates a router for testing with the given configuration
 func NewTestRouter(socketPath string, timeout time.Duration, mockTransport *MockTransport) EnvelopeProcessor {
-	runtimeClient := runtime.NewClient(socketPath, timeout)
+	return NewRouter(RouterConfig{
+		ActorName:  "test-actor",
+		SocketPath: socketPath,
+		Timeout:    timeout,
+	}, mockTransport)
+}
+
+// NewRouter creates a router for the given actor that sends to the mock transport
+func NewRouter(rc RouterConfig, mockTransport *MockTransport) EnvelopeProcessor {
+	runtimeClient := runtime.NewClient(rc.SocketPath, rc.Timeout)
 
 	cfg := &config.Config{
-		ActorName:     "test-actor",
+		ActorName:     rc.ActorName,
 		HappyEndQueue: "happy-end",
 		ErrorEndQueue: "error-end",
-		SocketPath:    socketPath,
-		Timeout:       timeout,
+		IsEndActor:    rc.IsEndActor,
+		GatewayURL:    rc.GatewayURL,
+		SocketPath:    rc.SocketPath,
+		Timeout:       rc.Timeout,
 	}
 
 	adapter := &mockTransportAdapter{mock: mockTransport}
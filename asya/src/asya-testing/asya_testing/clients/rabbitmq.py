@@ -45,13 +45,11 @@ class RabbitMQClient(TransportClient):
         channel = connection.channel()
         channel.confirm_delivery()
 
-        # Derive routing key from queue name by stripping asya-{namespace}- prefix
-        routing_key = queue.removeprefix(f"asya-{self.namespace}-")
-
+        # Queues are bound with their full name as routing key
         body = json.dumps(message)
         channel.basic_publish(
             exchange=exchange,
-            routing_key=routing_key,
+            routing_key=queue,
             body=body,
             properties=pika.BasicProperties(delivery_mode=2, content_type="application/json"),
             mandatory=True,
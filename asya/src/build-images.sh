@@ -226,8 +226,9 @@ time {
     name="${IMAGES[$i]}"
     index=$((i + 1))
 
-    # Operator needs src/ as context to access asya-runtime symlink target
-    if [[ "$name" == "asya-operator" ]]; then
+    # Go images need src/ as context to access the shared asya-naming module
+    # (and the operator the asya-runtime symlink target)
+    if [[ "$name" == "asya-operator" || "$name" == "asya-gateway" || "$name" == "asya-sidecar" ]]; then
       build_image "$name" "src" "$name/Dockerfile" "$index" "$TOTAL_IMAGES" &
     else
       build_image "$name" "src/$name" "Dockerfile" "$index" "$TOTAL_IMAGES" &
@@ -47,7 +47,7 @@ class RabbitMQTestHelper:
         self, queue: str, message: dict, exchange: str = "asya"
     ) -> None:
         """Publish a message to RabbitMQ with delivery confirmation."""
-        routing_key = queue.removeprefix("asya-") if queue.startswith("asya-") else queue
+        routing_key = queue  # queues are bound with their full name
         logger.debug(f"Publishing to exchange='{exchange}', routing_key='{routing_key}'")
         credentials = pika.PlainCredentials(self.rabbitmq_user, self.rabbitmq_pass)
         parameters = pika.ConnectionParameters(
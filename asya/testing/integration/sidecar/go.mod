@@ -6,6 +6,8 @@ toolchain go1.24.1
 
 replace github.com/deliveryhero/asya/asya-sidecar => ../../../src/asya-sidecar
 
+replace github.com/deliveryhero/asya/asya-naming => ../../../src/asya-naming
+
 require (
 	github.com/deliveryhero/asya/asya-sidecar v0.0.0-00010101000000-000000000000
 	golang.org/x/net v0.47.0
@@ -28,6 +30,7 @@ require (
 	github.com/aws/smithy-go v1.23.1 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/deliveryhero/asya/asya-naming v0.0.0-00010101000000-000000000000 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
 	github.com/prometheus/client_golang v1.23.2 // indirect
 	github.com/prometheus/client_model v0.6.2 // indirect